@@ -0,0 +1,7 @@
+// Package finnetpb holds the protobuf messages and gRPC service of the FinNet
+// API defined in finnet.proto. The Go code is generated with protoc and the
+// protoc-gen-go and protoc-gen-go-grpc plugins; run go generate after
+// changing the definition.
+package finnetpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative finnet.proto
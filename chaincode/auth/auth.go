@@ -0,0 +1,103 @@
+// Package auth decides which client identities may act on an account. The
+// chaincode asks an Authorizer before every account-changing operation; the
+// default policy admits the owning customer, the account's signers and
+// operator roles, and can be replaced without touching the handlers.
+package auth
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+const (
+	// RoleAttribute is the client certificate attribute holding the caller's FinNet role
+	RoleAttribute = "finnet.role"
+	// CustomerAttribute is the client certificate attribute holding the customer ID the caller acts for
+	CustomerAttribute = "finnet.customer_id"
+)
+
+// Action names an account operation subject to authorization
+type Action string
+
+const (
+	// OpenAccount opens an account for a customer
+	OpenAccount Action = "open_account"
+	// CloseAccount closes an account
+	CloseAccount Action = "close_account"
+	// Transfer moves money out of an account
+	Transfer Action = "transfer"
+	// Topup credits an account
+	Topup Action = "topup"
+	// ManageBeneficiaries changes a customer's beneficiaries and beneficiary policy
+	ManageBeneficiaries Action = "manage_beneficiaries"
+	// RequestPayment asks another customer to pay into an account
+	RequestPayment Action = "request_payment"
+	// Dispute disputes a transfer made from an account
+	Dispute Action = "dispute"
+)
+
+// Caller is the identity of the invoking client
+type Caller struct {
+	ID         string
+	MSPID      string
+	CustomerID string   // empty if the certificate carries no customer attribute
+	Roles      []string // the certificate role attribute, plus any roles granted on the ledger
+}
+
+// HasRole returns true if the caller holds the role
+func (c *Caller) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Account is the account an action is performed on
+type Account struct {
+	CustomerID string
+	AccountID  string
+	Signers    []string // identities authorized to approve outgoing transfers
+}
+
+// Authorizer decides whether a caller may perform an action on an account
+type Authorizer interface {
+	Authorize(caller *Caller, action Action, account *Account) error
+}
+
+// GetCaller reads the identity and FinNet attributes of the invoking client certificate
+func GetCaller(stub shim.ChaincodeStubInterface) (*Caller, error) {
+	id, err := cid.GetID(stub)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading caller identity. Error: %s", err)
+	}
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading caller MSP ID. Error: %s", err)
+	}
+	caller := &Caller{ID: id, MSPID: mspID}
+	if caller.CustomerID, _, err = cid.GetAttributeValue(stub, CustomerAttribute); err != nil {
+		return nil, fmt.Errorf("Error reading caller attributes. Error: %s", err)
+	}
+	role, found, err := cid.GetAttributeValue(stub, RoleAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading caller attributes. Error: %s", err)
+	}
+	if found && role != "" {
+		caller.Roles = []string{role}
+	}
+	return caller, nil
+}
+
+// Check reads the invoking client identity and asks the authorizer whether it
+// may perform the action on the account
+func Check(stub shim.ChaincodeStubInterface, authorizer Authorizer, action Action, account *Account) error {
+	caller, err := GetCaller(stub)
+	if err != nil {
+		return err
+	}
+	return authorizer.Authorize(caller, action, account)
+}
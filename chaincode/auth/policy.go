@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"fmt"
+)
+
+// OwnerPolicy admits callers acting for the customer owning the account, the
+// signers of a multi-signature account for transfers, and callers holding one
+// of the operator roles for any action
+type OwnerPolicy struct {
+	OperatorRoles []string
+}
+
+// NewOwnerPolicy creates an owner policy admitting the given operator roles
+func NewOwnerPolicy(operatorRoles ...string) *OwnerPolicy {
+	return &OwnerPolicy{OperatorRoles: operatorRoles}
+}
+
+// Authorize implements Authorizer
+func (p *OwnerPolicy) Authorize(caller *Caller, action Action, account *Account) error {
+	for _, role := range p.OperatorRoles {
+		if caller.HasRole(role) {
+			return nil
+		}
+	}
+	if caller.CustomerID != "" && caller.CustomerID == account.CustomerID {
+		return nil
+	}
+	if action == Transfer {
+		for _, signer := range account.Signers {
+			if signer == caller.ID {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("Caller is not authorized to %s for customer %s", action, account.CustomerID)
+}
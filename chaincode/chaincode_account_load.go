@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Account book migration handler functions
+//------------------------------
+
+// LoadAccounts opens the accounts of a chunk of a migrated account book with
+// their opening balances. Each row registers its customer if a profile is
+// given and the customer is not registered yet, opens the account through the
+// checks of OpenAccount and credits the opening balance as an opening_balance
+// transaction. A failing row, e.g. of an account that already exists, is
+// rejected without state and reported in the result, so a chunk can be
+// loaded again after a partial failure. Restricted to account operators.
+func (cc *Chaincode) LoadAccounts(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required account load JSON")
+	}
+	load, err := model.CreateAccountLoad([]byte(args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating account load. Error: %s", err)
+	}
+	result := model.CreateAccountLoadResult(len(load.Rows))
+	for _, row := range load.Rows {
+		item := &model.AccountLoadItem{Row: row.Row, Status: "loaded", OpeningBalance: row.OpeningBalance}
+		var account *model.Account
+		err := atomically(stub, func() error {
+			var err error
+			account, err = cc.loadAccount(stub, row)
+			return err
+		})
+		if account != nil {
+			item.CustomerID, item.AccountID = account.CustomerID, account.ID
+		}
+		if err != nil {
+			loggerFor(stub).Warningf("Row %d of account load rejected. Error: %s", row.Row, err)
+			item.Status = "rejected"
+			item.Error = err.Error()
+			result.Rejected++
+			result.Items = append(result.Items, item)
+			continue
+		}
+		result.Loaded++
+		result.Balances[account.CurrencyCode] += row.OpeningBalance
+		result.Items = append(result.Items, item)
+	}
+	return json.Marshal(result)
+}
+
+// loadAccount applies a row of an account load and returns its account, as
+// far as it was parsed when the row fails
+func (cc *Chaincode) loadAccount(stub shim.ChaincodeStubInterface, row *model.AccountLoadRow) (*model.Account, error) {
+	if len(row.Customer) > 0 {
+		if err := cc.loadCustomer(stub, row.Customer); err != nil {
+			return nil, err
+		}
+	}
+	account, err := model.CreateAccount(row.Account, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating new account. Error: %s", err)
+	}
+	if row.OpeningBalance != 0 {
+		if err := model.ValidateAmount(row.OpeningBalance); err != nil {
+			return account, err
+		}
+	}
+	existing, err := cc.GetAccount(stub, []string{account.CustomerID, account.ID})
+	if err != nil {
+		return account, err
+	}
+	if existing != nil {
+		return account, fmt.Errorf("Account %s of customer %s already exists", account.ID, account.CustomerID)
+	}
+	if err := cc.authorize(stub, auth.OpenAccount, account); err != nil {
+		return account, err
+	}
+	if err := cc.requireCustomer(stub, account.CustomerID); err != nil {
+		return account, err
+	}
+	if err := cc.admitToProduct(stub, account); err != nil {
+		return account, err
+	}
+	if err := cc.requireKYC(stub, account.CustomerID); err != nil {
+		return account, err
+	}
+	if err := cc.requireBankMSP(stub, account); err != nil {
+		return account, err
+	}
+	if _, err := cc.putAccount(stub, account); err != nil {
+		return account, err
+	}
+	if row.OpeningBalance > 0 {
+		t := &model.Transfer{
+			ToCustomerID: account.CustomerID,
+			ToAccountID:  account.ID,
+			Amount:       row.OpeningBalance,
+			CurrencyCode: account.CurrencyCode,
+			Description:  "Opening balance",
+			Params:       map[string]string{"initiated_by": "system", "source_row": strconv.Itoa(row.Row)},
+			Type:         model.TxTypeOpeningBalance,
+		}
+		if err := cc.creditAccount(stub, account, t.Money()); err != nil {
+			return account, err
+		}
+		if err := cc.recordTransaction(stub, account.CustomerID, account.ID, t, "", model.Credited); err != nil {
+			return account, err
+		}
+	}
+	return account, emitAccountEvent(stub, model.EventAccountOpened, account)
+}
+
+// loadCustomer registers the customer of a load row unless it is registered
+func (cc *Chaincode) loadCustomer(stub shim.ChaincodeStubInterface, customerData []byte) error {
+	registeredBy, err := callerID(stub)
+	if err != nil {
+		return err
+	}
+	customer, err := model.CreateCustomer(customerData, registeredBy, txContext(stub))
+	if err != nil {
+		return fmt.Errorf("Error creating customer. Error: %s", err)
+	}
+	existing, err := cc.getCustomer(stub, customer.ID)
+	if err != nil || existing != nil {
+		return err
+	}
+	_, err = cc.putCustomer(stub, customer)
+	return err
+}
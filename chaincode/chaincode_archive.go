@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/utils"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Transaction archival handler functions
+//------------------------------
+
+// PreviewArchive query the transaction details of an account created before the
+// horizon date, with their Merkle root, so they can be exported off-chain
+func (cc *Chaincode) PreviewArchive(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, account ID and / or horizon date")
+	}
+	batch, _, err := cc.archiveBatch(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(batch)
+}
+
+// ArchiveTransactions replaces the transaction details of an account created
+// before the horizon date with a summary and the Merkle root of the batch. The
+// caller passes the root of the details it exported; archiving fails if the
+// batch on the ledger differs, so no detail is pruned without an off-chain copy.
+func (cc *Chaincode) ArchiveTransactions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 {
+		return nil, errors.New("Missing required customer ID, account ID, horizon date and / or Merkle root")
+	}
+	batch, txns, err := cc.archiveBatch(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	if len(txns) == 0 {
+		return nil, fmt.Errorf("No transactions of account %s before %s to archive", args[1], args[2])
+	}
+	if batch.MerkleRoot != args[3] {
+		return nil, fmt.Errorf("Merkle root %s does not match the archive batch root %s", args[3], batch.MerkleRoot)
+	}
+	archive := &model.TransactionArchive{
+		Entity:      model.Entity{ObjectType: model.TransactionArchiveObjectType},
+		ID:          stub.GetTxID(),
+		CustomerID:  batch.CustomerID,
+		AccountID:   batch.AccountID,
+		Horizon:     batch.Horizon,
+		MerkleRoot:  batch.MerkleRoot,
+		Archived:    txContext(stub).Time.Unix(),
+		ArchiveTxID: stub.GetTxID(),
+	}
+	for i, txn := range txns {
+		archive.Add(txn)
+		if err := stub.DelState(batch.Transactions[i].Key); err != nil {
+			return nil, err
+		}
+		if err := cc.unlinkTransaction(stub, txn); err != nil {
+			return nil, err
+		}
+	}
+	archiveData, _ := json.Marshal(archive)
+	key, _ := cc.createCompositeKey(stub, archive.GetObjectType(), []string{archive.CustomerID, archive.AccountID, archive.ID})
+	if err := stub.PutState(key, archiveData); err != nil {
+		return nil, err
+	}
+	return archiveData, nil
+}
+
+// GetTransactionArchives query the archive summaries of an account
+func (cc *Chaincode) GetTransactionArchives(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TransactionArchiveObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction archives. Error: %s", err)
+		return nil, err
+	}
+	list := model.TransactionArchiveList{Archives: []*model.TransactionArchive{}}
+	for keysIter.HasNext() {
+		archiveBytes := nextValue(keysIter)
+		archive := new(model.TransactionArchive)
+		if err := json.Unmarshal(archiveBytes, archive); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction archive details. Error: %s", err)
+			continue
+		}
+		list.Archives = append(list.Archives, archive)
+	}
+	return json.Marshal(list)
+}
+
+// ArchiveClosedAccounts purges the accounts closed longer ago than the
+// configured retention period. The account record, its transaction details
+// and their reference index entries are deleted and a tombstone holding the
+// hash of the account and the Merkle root of the details is kept in their place.
+func (cc *Chaincode) ArchiveClosedAccounts(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	config, err := cc.getConfig(stub)
+	if err != nil {
+		return nil, err
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.AccountObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get account list. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	tx := txContext(stub)
+	var expired []*model.StateRecord
+	for keysIter.HasNext() {
+		key, accountBytes, err := nextKeyValue(keysIter)
+		if err != nil {
+			return nil, err
+		}
+		account := new(model.Account)
+		if err := json.Unmarshal(accountBytes, account); err != nil {
+			loggerFor(stub).Errorf("Failed to get account details. Error: %s", err)
+			continue
+		}
+		if account.RetentionExpired(config.ClosedRetention(), tx.Time) {
+			expired = append(expired, &model.StateRecord{Key: key, Value: accountBytes})
+		}
+	}
+
+	list := model.AccountTombstoneList{Tombstones: []*model.AccountTombstone{}}
+	for _, record := range expired {
+		tombstone, err := cc.purgeAccount(stub, record)
+		if err != nil {
+			return nil, err
+		}
+		list.Tombstones = append(list.Tombstones, tombstone)
+	}
+	return json.Marshal(list)
+}
+
+// GetAccountTombstone query the tombstone left in place of a purged account
+func (cc *Chaincode) GetAccountTombstone(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.AccountTombstoneObjectType, args)
+	tombstoneBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get account tombstone details. Error: %s", err)
+		return nil, err
+	}
+	if tombstoneBytes == nil {
+		return nil, fmt.Errorf("Account %s was not purged", args[1])
+	}
+	return tombstoneBytes, nil
+}
+
+// purgeAccount deletes a closed account with its transaction details and
+// stores its tombstone
+func (cc *Chaincode) purgeAccount(stub shim.ChaincodeStubInterface, record *model.StateRecord) (*model.AccountTombstone, error) {
+	account := new(model.Account)
+	if err := json.Unmarshal(record.Value, account); err != nil {
+		return nil, err
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TransactionObjectType, []string{account.CustomerID, account.ID})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction list. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	var leaves [][]byte
+	for keysIter.HasNext() {
+		key, txnBytes, err := nextKeyValue(keysIter)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, txnBytes)
+		if err := stub.DelState(key); err != nil {
+			return nil, err
+		}
+		txn := new(model.Transaction)
+		if err := json.Unmarshal(txnBytes, txn); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			continue
+		}
+		if err := cc.unlinkTransaction(stub, txn); err != nil {
+			return nil, err
+		}
+	}
+	snapshotsIter, err := cc.partialCompositeKeyQuery(stub, model.BalanceSnapshotObjectType, []string{account.CustomerID, account.ID})
+	if err != nil {
+		return nil, err
+	}
+	defer snapshotsIter.Close()
+	for snapshotsIter.HasNext() {
+		key, _, err := nextKeyValue(snapshotsIter)
+		if err != nil {
+			return nil, err
+		}
+		if err := stub.DelState(key); err != nil {
+			return nil, err
+		}
+	}
+	if err := stub.DelState(record.Key); err != nil {
+		return nil, err
+	}
+	tombstone := model.CreateAccountTombstone(account, utils.MerkleRoot([][]byte{record.Value}), len(leaves), utils.MerkleRoot(leaves), txContext(stub))
+	tombstoneData, _ := json.Marshal(tombstone)
+	key, _ := cc.createCompositeKey(stub, tombstone.GetObjectType(), []string{tombstone.CustomerID, tombstone.AccountID})
+	if err := stub.PutState(key, tombstoneData); err != nil {
+		return nil, err
+	}
+	return tombstone, nil
+}
+
+// archiveBatch collects the transaction details of an account created before
+// the horizon in key order, the order their Merkle root is computed in
+func (cc *Chaincode) archiveBatch(stub shim.ChaincodeStubInterface, customerID string, accountID string, horizon string) (*model.ArchiveBatch, []*model.Transaction, error) {
+	cutoff, ok := model.RetentionHorizon(horizon, txContext(stub).Time)
+	if !ok {
+		return nil, nil, fmt.Errorf("Horizon %s must be a date at least %d days ago", horizon, model.MinRetentionDays)
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TransactionObjectType, []string{customerID, accountID})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction list. Error: %s", err)
+		return nil, nil, err
+	}
+	batch := &model.ArchiveBatch{CustomerID: customerID, AccountID: accountID, Horizon: horizon, Transactions: []*model.StateRecord{}}
+	var txns []*model.Transaction
+	var leaves [][]byte
+	for keysIter.HasNext() {
+		key, txnBytes, err := nextKeyValue(keysIter)
+		if err != nil {
+			return nil, nil, err
+		}
+		txn := new(model.Transaction)
+		if err := json.Unmarshal(txnBytes, txn); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			continue
+		}
+		if txn.Created >= cutoff.Unix() {
+			continue
+		}
+		batch.Transactions = append(batch.Transactions, &model.StateRecord{Key: key, Value: txnBytes})
+		txns = append(txns, txn)
+		leaves = append(leaves, txnBytes)
+	}
+	batch.MerkleRoot = utils.MerkleRoot(leaves)
+	return batch, txns, nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Asset token and DvP handler functions
+//------------------------------
+
+// CreateAssetToken registers a tokenized asset and assigns all units to its issuer
+func (cc *Chaincode) CreateAssetToken(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required asset token data JSON")
+	}
+	token, err := model.CreateAssetToken([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating asset token. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, token.GetObjectType(), []string{token.ID})
+	existing, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Asset token %s already exists", token.ID)
+	}
+	holding := model.CreateAssetHolding(token.ID, token.IssuerCustomerID)
+	holding.Units = token.TotalUnits
+	if err := cc.putAssetHolding(stub, holding); err != nil {
+		return nil, err
+	}
+	tokenData, _ := json.Marshal(token)
+	if err := stub.PutState(key, tokenData); err != nil {
+		return nil, err
+	}
+	return tokenData, nil
+}
+
+// GetAssetToken query an asset token by ID
+func (cc *Chaincode) GetAssetToken(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required token ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.AssetTokenObjectType, args)
+	return stub.GetState(key)
+}
+
+// GetAssetHolding query the units of an asset token held by a customer
+func (cc *Chaincode) GetAssetHolding(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required token ID and / or customer ID")
+	}
+	holding, err := cc.getAssetHolding(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(holding)
+}
+
+// TransferAsset moves asset units between customers free of payment. The
+// caller must be authorized to transfer for the customer delivering the units.
+func (cc *Chaincode) TransferAsset(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 {
+		return nil, errors.New("Missing required token ID, from customer ID, to customer ID and / or units")
+	}
+	units, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil || units <= 0 {
+		return nil, fmt.Errorf("Error parsing units value %s", args[3])
+	}
+	if err := cc.authorize(stub, auth.Transfer, &model.Account{CustomerID: args[1]}); err != nil {
+		return nil, err
+	}
+	if err := cc.moveAssetUnits(stub, args[0], args[1], args[2], units); err != nil {
+		return nil, err
+	}
+	return cc.GetAssetHolding(stub, []string{args[0], args[2]})
+}
+
+// AtomicDvP delivers asset units from seller to buyer against payment from
+// buyer to seller in a single transaction, so neither leg settles without the
+// other. The caller must be authorized to transfer for both the seller and the buyer.
+func (cc *Chaincode) AtomicDvP(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required DvP instruction JSON")
+	}
+	dvp := new(model.DvPInstruction)
+	if err := bytesToStruct([]byte(args[0]), dvp); err != nil {
+		return nil, err
+	}
+	if err := dvp.Validate(); err != nil {
+		return nil, err
+	}
+	// the seller delivers the asset units
+	if err := cc.authorize(stub, auth.Transfer, &model.Account{CustomerID: dvp.SellerCustomerID}); err != nil {
+		return nil, err
+	}
+	if err := cc.settleDvP(stub, dvp, "DvP settlement"); err != nil {
+		return nil, err
+	}
+	return json.Marshal(dvp)
+}
+
+// settleDvP moves the asset leg from seller to buyer and the cash leg from
+// buyer to seller, failing before any write if either leg cannot settle. The
+// caller must be authorized to pay from the buyer's account.
+func (cc *Chaincode) settleDvP(stub shim.ChaincodeStubInterface, dvp *model.DvPInstruction, description string) error {
+	buyer, err := cc.getAccountStruct(stub, dvp.BuyerCustomerID, dvp.BuyerAccountID)
+	if err != nil {
+		return err
+	}
+	if err := cc.authorize(stub, auth.Transfer, buyer); err != nil {
+		return err
+	}
+	seller, err := cc.getAccountStruct(stub, dvp.SellerCustomerID, dvp.SellerAccountID)
+	if err != nil {
+		return err
+	}
+	if !buyer.CanSend() {
+		return fmt.Errorf("Cannot transfer money from %s account %s", buyer.Status, buyer.ID)
+	}
+	if !seller.CanReceive() {
+		return fmt.Errorf("Cannot transfer money into %s account %s", seller.Status, seller.ID)
+	}
+	if buyer.CurrencyCode != dvp.CurrencyCode || seller.CurrencyCode != dvp.CurrencyCode {
+		return fmt.Errorf("Cash accounts must be held in %s", dvp.CurrencyCode)
+	}
+	if buyer.Unheld()-dvp.Price < 0 {
+		return fmt.Errorf("Insufficient funds available in account %s", buyer.ID)
+	}
+	if err := cc.moveAssetUnits(stub, dvp.TokenID, dvp.SellerCustomerID, dvp.BuyerCustomerID, dvp.Units); err != nil {
+		return err
+	}
+	t := &model.Transfer{
+		FromCustomerID: buyer.CustomerID,
+		FromAccountID:  buyer.ID,
+		ToCustomerID:   seller.CustomerID,
+		ToAccountID:    seller.ID,
+		Amount:         dvp.Price,
+		CurrencyCode:   dvp.CurrencyCode,
+		Description:    description,
+		Params: map[string]string{
+			"token_id":  dvp.TokenID,
+			"units":     strconv.FormatInt(dvp.Units, 10),
+			"reference": dvp.SettlementReference,
+		},
+	}
+	if err := cc.debitAccount(stub, buyer, buyer.Money(dvp.Price)); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, buyer.CustomerID, buyer.ID, t, "", model.Debited); err != nil {
+		return err
+	}
+	if err := cc.creditAccount(stub, seller, seller.Money(dvp.Price)); err != nil {
+		return err
+	}
+	return cc.recordTransaction(stub, seller.CustomerID, seller.ID, t, "", model.Credited)
+}
+
+// moveAssetUnits transfers unpledged asset units between two customers
+func (cc *Chaincode) moveAssetUnits(stub shim.ChaincodeStubInterface, tokenID string, fromCustomerID string, toCustomerID string, units int64) error {
+	if fromCustomerID == toCustomerID {
+		return errors.New("Cannot transfer asset units to the same customer")
+	}
+	from, err := cc.getAssetHolding(stub, tokenID, fromCustomerID)
+	if err != nil {
+		return err
+	}
+	if from.Available() < units {
+		return fmt.Errorf("Customer %s holds insufficient unpledged units of %s", fromCustomerID, tokenID)
+	}
+	to, err := cc.getAssetHolding(stub, tokenID, toCustomerID)
+	if err != nil {
+		return err
+	}
+	from.Units -= units
+	to.Units += units
+	if err := cc.putAssetHolding(stub, from); err != nil {
+		return err
+	}
+	return cc.putAssetHolding(stub, to)
+}
+
+func (cc *Chaincode) getAssetHolding(stub shim.ChaincodeStubInterface, tokenID string, customerID string) (*model.AssetHolding, error) {
+	key, _ := cc.createCompositeKey(stub, model.AssetHoldingObjectType, []string{tokenID, customerID})
+	holdingBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if holdingBytes == nil {
+		return model.CreateAssetHolding(tokenID, customerID), nil
+	}
+	holding := new(model.AssetHolding)
+	if err := bytesToStruct(holdingBytes, holding); err != nil {
+		return nil, err
+	}
+	return holding, nil
+}
+
+func (cc *Chaincode) putAssetHolding(stub shim.ChaincodeStubInterface, holding *model.AssetHolding) error {
+	holdingData, err := json.Marshal(holding)
+	if err != nil {
+		return fmt.Errorf("Error marshalling asset holding data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, holding.GetObjectType(), []string{holding.TokenID, holding.CustomerID})
+	return stub.PutState(key, holdingData)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// holdingOf returns the units of an asset token a customer holds
+func holdingOf(t *testing.T, stub *testsupport.Stub, tokenID string, customerID string) int64 {
+	t.Helper()
+	holding := new(model.AssetHolding)
+	if err := json.Unmarshal(stub.MustCall(t, "GetAssetHolding", tokenID, customerID), holding); err != nil {
+		t.Fatal(err)
+	}
+	return holding.Units
+}
+
+func TestAtomicDvPSettlesBothLegsOrNeither(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1002", "1", 10000)
+	stub.As(testsupport.Operator(t, RoleIssuer)).MustCall(t, "CreateAssetToken", `{"id":"BOND1","name":"Bond","type":"bond","issuer_customer":"1001","total_units":10}`)
+
+	stub.As(testsupport.Customer(t, "1001")).MustCall(t, "TransferAsset", "BOND1", "1001", "1002", "2")
+	if seller, buyer := holdingOf(t, stub, "BOND1", "1001"), holdingOf(t, stub, "BOND1", "1002"); seller != 8 || buyer != 2 {
+		t.Errorf("Expected 2 units delivered free of payment, got holdings of %d and %d", seller, buyer)
+	}
+
+	stub.As(testsupport.Operator(t, RoleTeller))
+	stub.MustCall(t, "AtomicDvP", `{"token_id":"BOND1","units":3,"seller_customer":"1001","seller_account":"1","buyer_customer":"1002","buyer_account":"1","price":3000,"currency":"AUD"}`)
+	if seller, buyer := holdingOf(t, stub, "BOND1", "1001"), holdingOf(t, stub, "BOND1", "1002"); seller != 5 || buyer != 5 {
+		t.Errorf("Expected 3 units delivered against payment, got holdings of %d and %d", seller, buyer)
+	}
+	if seller, buyer := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); seller != 3000 || buyer != 7000 {
+		t.Errorf("Expected the price paid, got balances of %d and %d", seller, buyer)
+	}
+
+	before := committedState(stub)
+	if _, err := stub.Call("AtomicDvP", `{"token_id":"BOND1","units":5,"seller_customer":"1001","seller_account":"1","buyer_customer":"1002","buyer_account":"1","price":7001,"currency":"AUD"}`); err == nil || !strings.Contains(err.Error(), "Insufficient funds") {
+		t.Errorf("Expected the unfunded cash leg refused, got %v", err)
+	}
+	if _, err := stub.Call("AtomicDvP", `{"token_id":"BOND1","units":6,"seller_customer":"1001","seller_account":"1","buyer_customer":"1002","buyer_account":"1","price":100,"currency":"AUD"}`); err == nil || !strings.Contains(err.Error(), "insufficient unpledged units") {
+		t.Errorf("Expected the undeliverable asset leg refused, got %v", err)
+	}
+	if !reflect.DeepEqual(committedState(stub), before) {
+		t.Errorf("Expected neither leg of a failed DvP settled")
+	}
+}
+
+func TestRepoClosesOrFailsOnRepurchase(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1002", "1", 100000)
+	stub.As(testsupport.Operator(t, RoleIssuer)).MustCall(t, "CreateAssetToken", `{"id":"BOND1","name":"Bond","type":"bond","issuer_customer":"1001","total_units":10}`)
+
+	// 10 days at 3.65% accrue 100 of interest on 100000
+	stub.As(testsupport.Operator(t, RoleTeller))
+	stub.MustCall(t, "OpenRepo", `{"id":"repo1","borrower_customer":"1001","borrower_account":"1","lender_customer":"1002","lender_account":"1","token_id":"BOND1","units":5,"cash_amount":100000,"currency":"AUD","rate":365,"repurchase_date":"2021-03-11"}`)
+	if borrower, lender := holdingOf(t, stub, "BOND1", "1001"), holdingOf(t, stub, "BOND1", "1002"); borrower != 5 || lender != 5 {
+		t.Errorf("Expected the collateral delivered to the lender, got holdings of %d and %d", borrower, lender)
+	}
+	stub.Topup(t, "1001", "1", 100)
+	stub.As(testsupport.Customer(t, "1001")).MustCall(t, "CloseRepo", "repo1")
+	if borrower, lender := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); borrower != 0 || lender != 100100 {
+		t.Errorf("Expected the repurchase price of 100100 repaid, got balances of %d and %d", borrower, lender)
+	}
+	if borrower := holdingOf(t, stub, "BOND1", "1001"); borrower != 10 {
+		t.Errorf("Expected the collateral returned, got %d units", borrower)
+	}
+
+	stub.As(testsupport.Operator(t, RoleTeller))
+	stub.MustCall(t, "OpenRepo", `{"id":"repo2","borrower_customer":"1001","borrower_account":"1","lender_customer":"1002","lender_account":"1","token_id":"BOND1","units":5,"cash_amount":1000,"currency":"AUD","rate":365,"repurchase_date":"2021-03-11"}`)
+	stub.As(testsupport.Customer(t, "1001"))
+	repo := new(model.Repo)
+	if err := json.Unmarshal(stub.MustCall(t, "CloseRepo", "repo2"), repo); err != nil {
+		t.Fatal(err)
+	}
+	if repo.Status != model.RepoFailed || holdingOf(t, stub, "BOND1", "1002") != 5 || balanceOf(t, stub, "1001", "1") != 1000 {
+		t.Errorf("Expected the unfunded repurchase failed with the lender keeping the collateral, got %+v", repo)
+	}
+	list := new(model.TransactionList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetTransactionList", "1001", "1"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Transactions) == 0 || list.Transactions[0].Status != model.Failed || list.Transactions[0].Amount != 1001 {
+		t.Errorf("Expected the failed closing leg recorded against the borrower, got %+v", list.Transactions)
+	}
+}
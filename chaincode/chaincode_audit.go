@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Audit trail handler functions
+//------------------------------
+
+// QueryAuditLog query the audit entries of state-changing invocations, oldest
+// first, optionally filtered by caller identity, function and date window
+// (YYYY-MM-DD, inclusive). Pass an empty string to skip a filter.
+func (cc *Chaincode) QueryAuditLog(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 4 {
+		return nil, errors.New("Too many arguments, expected optional caller, function, from date and to date")
+	}
+	from, to, err := model.ParseReportingPeriod(optionalArg(args, 2), optionalArg(args, 3))
+	if err != nil {
+		return nil, err
+	}
+	filter := &model.AuditFilter{Caller: optionalArg(args, 0), Function: optionalArg(args, 1), From: from, To: to}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.AuditEntryObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get audit log. Error: %s", err)
+		return nil, err
+	}
+	log := model.AuditLog{Entries: []*model.AuditEntry{}}
+	for keysIter.HasNext() {
+		entry := new(model.AuditEntry)
+		if err := json.Unmarshal(nextValue(keysIter), entry); err != nil {
+			loggerFor(stub).Errorf("Failed to get audit entry details. Error: %s", err)
+			continue
+		}
+		if filter.Matches(entry) {
+			log.Entries = append(log.Entries, entry)
+		}
+	}
+	sort.Stable(model.ByTimestamp(log.Entries))
+	return json.Marshal(log)
+}
+
+// recordAudit buffers the audit entry of an invocation that wrote state,
+// listing the keys it wrote. Invocations writing nothing, such as queries,
+// are not audited. The entry is written outside tenant namespaces.
+func (cc *Chaincode) recordAudit(tx *txStub, function string) error {
+	if len(tx.writes) == 0 && len(tx.privateWrites) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(tx.writes))
+	for key := range tx.writes {
+		keys = append(keys, key)
+	}
+	var private []string
+	for collection, writes := range tx.privateWrites {
+		for key := range writes {
+			private = append(private, collection+"/"+key)
+		}
+	}
+	failures := 0
+	for _, event := range tx.events {
+		if event.Type == model.EventTransferFailed {
+			failures++
+		}
+	}
+	caller, err := callerID(tx)
+	if err != nil {
+		return err
+	}
+	callerMSP, err := callerMSPID(tx)
+	if err != nil {
+		return err
+	}
+	entry := model.CreateAuditEntry(function, caller, callerMSP, keys, private, failures, tx.context)
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("Error marshalling audit entry data. Error: %s", err)
+	}
+	key, err := cc.createCompositeKey(tx, entry.GetObjectType(), []string{entry.Date, entry.TxID})
+	if err != nil {
+		return err
+	}
+	return tx.PutState(key, entryData)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Bank registry handler functions
+//------------------------------
+
+// RegisterBank onboards a participant bank
+func (cc *Chaincode) RegisterBank(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required bank data JSON")
+	}
+	bank, err := model.CreateBank([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating bank. Error: %s", err)
+	}
+	existing, err := cc.getBank(stub, bank.BIC)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Bank %s is already registered", bank.BIC)
+	}
+	return cc.putBank(stub, bank)
+}
+
+// SuspendBank bars a participant bank from opening accounts and transferring
+func (cc *Chaincode) SuspendBank(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return cc.setBankStatus(stub, args, model.BankSuspended)
+}
+
+// ReinstateBank lifts the suspension of a participant bank
+func (cc *Chaincode) ReinstateBank(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return cc.setBankStatus(stub, args, model.BankActive)
+}
+
+// GetBank query a participant bank by BIC
+func (cc *Chaincode) GetBank(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required BIC")
+	}
+	key, _ := cc.createCompositeKey(stub, model.BankObjectType, args)
+	return stub.GetState(key)
+}
+
+// GetBankList query all participant banks
+func (cc *Chaincode) GetBankList(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.BankObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get bank list. Error: %s", err)
+		return nil, err
+	}
+	list := model.BankList{Banks: []*model.Bank{}}
+	for keysIter.HasNext() {
+		bankBytes := nextValue(keysIter)
+		bank := new(model.Bank)
+		if err := json.Unmarshal(bankBytes, bank); err != nil {
+			loggerFor(stub).Errorf("Failed to get bank details. Error: %s", err)
+			continue
+		}
+		list.Banks = append(list.Banks, bank)
+	}
+	return json.Marshal(list)
+}
+
+// RegisterParticipant onboards a participant institution with its BIC, MSP ID,
+// supported currencies and settlement accounts. Participants are kept in the
+// bank registry, so the participant is registered as a bank and transfers
+// are validated and settled against it as such.
+func (cc *Chaincode) RegisterParticipant(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	bankData, err := cc.RegisterBank(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	bank := new(model.Bank)
+	if err := bytesToStruct(bankData, bank); err != nil {
+		return nil, err
+	}
+	return json.Marshal(bank.Participant())
+}
+
+// ListParticipants query all participant institutions with the currencies
+// they transact in and their settlement accounts
+func (cc *Chaincode) ListParticipants(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	banks, err := cc.GetBankList(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	list := new(model.BankList)
+	if err := bytesToStruct(banks, list); err != nil {
+		return nil, err
+	}
+	participants := model.ParticipantList{Participants: []*model.Participant{}}
+	for _, bank := range list.Banks {
+		participants.Participants = append(participants.Participants, bank.Participant())
+	}
+	return json.Marshal(participants)
+}
+
+func (cc *Chaincode) setBankStatus(stub shim.ChaincodeStubInterface, args []string, status model.BankStatus) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required BIC")
+	}
+	bank, err := cc.mustGetBank(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := bank.SetStatus(status, optionalArg(args, 1), txContext(stub)); err != nil {
+		return nil, err
+	}
+	return cc.putBank(stub, bank)
+}
+
+// activeBank returns the registered bank of an account and fails if it is
+// unknown, suspended or does not support the account's currency. Accounts
+// without a bank are not routed through the registry.
+func (cc *Chaincode) activeBank(stub shim.ChaincodeStubInterface, account *model.Account) (*model.Bank, error) {
+	if account.BankName == "" {
+		return nil, nil
+	}
+	bank, err := cc.mustGetBank(stub, account.BankName)
+	if err != nil {
+		return nil, err
+	}
+	if !bank.IsActive() {
+		return nil, fmt.Errorf("Bank %s of account %s is suspended", bank.BIC, account.ID)
+	}
+	if !bank.Supports(account.CurrencyCode) {
+		return nil, fmt.Errorf("Bank %s of account %s does not support %s", bank.BIC, account.ID, account.CurrencyCode)
+	}
+	return bank, nil
+}
+
+// requireBankMSP fails unless the caller belongs to the MSP of the account's registered bank
+func (cc *Chaincode) requireBankMSP(stub shim.ChaincodeStubInterface, account *model.Account) error {
+	bank, err := cc.activeBank(stub, account)
+	if err != nil || bank == nil {
+		return err
+	}
+	return requireBankCaller(stub, bank)
+}
+
+// requireBankCaller fails unless the caller belongs to the MSP of the bank
+func requireBankCaller(stub shim.ChaincodeStubInterface, bank *model.Bank) error {
+	mspID, err := callerMSPID(stub)
+	if err != nil {
+		return err
+	}
+	if mspID != bank.MSPID {
+		return fmt.Errorf("Caller MSP %s is not the MSP of bank %s", mspID, bank.BIC)
+	}
+	return nil
+}
+
+func (cc *Chaincode) getBank(stub shim.ChaincodeStubInterface, bic string) (*model.Bank, error) {
+	key, _ := cc.createCompositeKey(stub, model.BankObjectType, []string{bic})
+	bankBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get bank details. Error: %s", err)
+		return nil, err
+	}
+	if bankBytes == nil {
+		return nil, nil
+	}
+	bank := new(model.Bank)
+	if err := bytesToStruct(bankBytes, bank); err != nil {
+		return nil, err
+	}
+	return bank, nil
+}
+
+func (cc *Chaincode) mustGetBank(stub shim.ChaincodeStubInterface, bic string) (*model.Bank, error) {
+	bank, err := cc.getBank(stub, bic)
+	if err != nil {
+		return nil, err
+	}
+	if bank == nil {
+		return nil, fmt.Errorf("Bank %s not found.", bic)
+	}
+	return bank, nil
+}
+
+func (cc *Chaincode) putBank(stub shim.ChaincodeStubInterface, bank *model.Bank) ([]byte, error) {
+	bankData, err := json.Marshal(bank)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling bank data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, bank.GetObjectType(), []string{bank.BIC})
+	if err := stub.PutState(key, bankData); err != nil {
+		return nil, err
+	}
+	return bankData, nil
+}
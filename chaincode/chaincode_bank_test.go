@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestParticipantsAreRegisteredInTheBankRegistry(t *testing.T) {
+	stub := newTestStub()
+	participantJSON := `{"bic":"FINNAU2S","name":"FinNet Bank","msp_id":"` + testsupport.DefaultMSPID + `","currencies":["AUD"],"settlement_accounts":{"AUD":{"customer_id":"9001","account_id":"1"}}}`
+	if _, err := stub.As(testsupport.Customer(t, "1001")).Call("RegisterParticipant", participantJSON); err == nil || !strings.Contains(err.Error(), "Caller is not authorized as network_operator") {
+		t.Errorf("Expected a customer refused to register a participant, got %v", err)
+	}
+
+	stub.As(testsupport.Operator(t, RoleNetworkOperator))
+	participant := new(model.Participant)
+	if err := json.Unmarshal(stub.MustCall(t, "RegisterParticipant", participantJSON), participant); err != nil {
+		t.Fatal(err)
+	}
+	if participant.BIC != "FINNAU2S" || participant.Status != model.BankActive || participant.SettlementAccounts["AUD"].CustomerID != "9001" {
+		t.Errorf("Expected the participant registered, got %+v", participant)
+	}
+	if _, err := stub.Call("RegisterBank", participantJSON); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("Expected the participant registered as a bank, got %v", err)
+	}
+	stub.MustCall(t, "RegisterBank", settlementBank("OTHRAU2S", "9002"))
+	stub.MustCall(t, "SuspendBank", "OTHRAU2S", "Failed settlement obligations")
+
+	list := new(model.ParticipantList)
+	if err := json.Unmarshal(stub.MustCall(t, "ListParticipants"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Participants) != 2 || list.Participants[0].BIC != "FINNAU2S" || list.Participants[1].Status != model.BankSuspended {
+		t.Errorf("Expected both banks listed as participants, got %+v", list.Participants)
+	}
+
+	// accounts and transfers are validated against the participant's currencies
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.As(testsupport.Operator(t, RoleTeller))
+	if _, err := stub.Call("OpenAccount", testsupport.NewAccount("1001", "2").InCurrency("USD").AtBank("FINNAU2S").JSON()); err == nil || !strings.Contains(err.Error(), "does not support USD") {
+		t.Errorf("Expected an account in an unsupported currency refused, got %v", err)
+	}
+}
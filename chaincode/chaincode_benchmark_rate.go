@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Benchmark rate handler functions
+//------------------------------
+
+// PublishBenchmarkRate publishes a daily reference rate. Restricted to rate administrators.
+func (cc *Chaincode) PublishBenchmarkRate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required benchmark rate data JSON")
+	}
+	publisher, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := model.CreateBenchmarkRate([]byte(args[0]), publisher, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating benchmark rate. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, rate.GetObjectType(), []string{rate.Name, rate.Date})
+	existing, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Benchmark %s already published for %s", rate.Name, rate.Date)
+	}
+	rateData, _ := json.Marshal(rate)
+	if err := stub.PutState(key, rateData); err != nil {
+		return nil, err
+	}
+	return rateData, nil
+}
+
+// GetBenchmarkRate query a benchmark rate by name and date, or the latest rate if no date is given
+func (cc *Chaincode) GetBenchmarkRate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, errors.New("Missing required benchmark name")
+	}
+	if len(args) == 2 {
+		key, _ := cc.createCompositeKey(stub, model.BenchmarkRateObjectType, args)
+		return stub.GetState(key)
+	}
+	rate, err := cc.latestBenchmarkRate(stub, args[0])
+	if err != nil || rate == nil {
+		return nil, err
+	}
+	return json.Marshal(rate)
+}
+
+// GetBenchmarkRateHistory query all published rates of a benchmark, oldest first
+func (cc *Chaincode) GetBenchmarkRateHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required benchmark name")
+	}
+	history, err := cc.benchmarkRateHistory(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(history)
+}
+
+// latestBenchmarkRate returns the most recently dated rate of a benchmark, or nil if none was published
+func (cc *Chaincode) latestBenchmarkRate(stub shim.ChaincodeStubInterface, name string) (*model.BenchmarkRate, error) {
+	history, err := cc.benchmarkRateHistory(stub, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(history.Rates) == 0 {
+		return nil, nil
+	}
+	return history.Rates[len(history.Rates)-1], nil
+}
+
+func (cc *Chaincode) benchmarkRateHistory(stub shim.ChaincodeStubInterface, name string) (*model.BenchmarkRateList, error) {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.BenchmarkRateObjectType, []string{name})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get benchmark rates. Error: %s", err)
+		return nil, err
+	}
+	history := &model.BenchmarkRateList{}
+	for keysIter.HasNext() {
+		rateBytes := nextValue(keysIter)
+		rate := new(model.BenchmarkRate)
+		if err := json.Unmarshal(rateBytes, rate); err != nil {
+			loggerFor(stub).Errorf("Failed to get benchmark rate details. Error: %s", err)
+			continue
+		}
+		// keys are ordered by date within a benchmark
+		if rate.Name == name {
+			history.Rates = append(history.Rates, rate)
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Beneficiary handler functions
+//------------------------------
+
+// AddBeneficiary saves a payee in a customer's address book. The beneficiary
+// is verified if its account is found on the ledger in its currency.
+func (cc *Chaincode) AddBeneficiary(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required beneficiary data JSON")
+	}
+	addedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	beneficiary, err := model.CreateBeneficiary([]byte(args[0]), addedBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.ManageBeneficiaries, &model.Account{CustomerID: beneficiary.CustomerID}); err != nil {
+		return nil, err
+	}
+	key, _ := cc.createCompositeKey(stub, beneficiary.GetObjectType(), []string{beneficiary.CustomerID, beneficiary.ID})
+	existing, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Beneficiary %s already exists", beneficiary.ID)
+	}
+	payeeStub, err := cc.payeeScope(stub, beneficiary.ToTenant)
+	if err != nil {
+		return nil, err
+	}
+	accountData, err := cc.GetAccount(payeeStub, []string{beneficiary.ToCustomerID, beneficiary.ToAccountID})
+	if err != nil {
+		return nil, err
+	}
+	if accountData != nil {
+		payee := new(model.Account)
+		if err := bytesToStruct(accountData, payee); err != nil {
+			return nil, err
+		}
+		if err := beneficiary.Verify(payee); err != nil {
+			return nil, err
+		}
+	}
+	beneficiaryData, _ := json.Marshal(beneficiary)
+	if err := stub.PutState(key, beneficiaryData); err != nil {
+		return nil, err
+	}
+	return beneficiaryData, nil
+}
+
+// ListBeneficiaries query a customer's beneficiaries and beneficiary policy
+func (cc *Chaincode) ListBeneficiaries(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required customer ID")
+	}
+	beneficiaries, err := cc.getBeneficiaries(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	policy, err := cc.getBeneficiaryPolicy(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&model.BeneficiaryList{Beneficiaries: beneficiaries, Policy: policy})
+}
+
+// RemoveBeneficiary deletes a payee from a customer's address book
+func (cc *Chaincode) RemoveBeneficiary(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or beneficiary ID")
+	}
+	if err := cc.authorize(stub, auth.ManageBeneficiaries, &model.Account{CustomerID: args[0]}); err != nil {
+		return nil, err
+	}
+	key, _ := cc.createCompositeKey(stub, model.BeneficiaryObjectType, args)
+	beneficiaryData, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if beneficiaryData == nil {
+		return nil, fmt.Errorf("Beneficiary %s not found.", args[1])
+	}
+	return nil, stub.DelState(key)
+}
+
+// SetBeneficiaryPolicy restricts a customer's transfers to verified
+// beneficiaries added at least the given number of hours before, replacing
+// any existing policy
+func (cc *Chaincode) SetBeneficiaryPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required beneficiary policy data JSON")
+	}
+	setBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := model.CreateBeneficiaryPolicy([]byte(args[0]), setBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.ManageBeneficiaries, &model.Account{CustomerID: policy.CustomerID}); err != nil {
+		return nil, err
+	}
+	policyData, _ := json.Marshal(policy)
+	key, _ := cc.createCompositeKey(stub, policy.GetObjectType(), []string{policy.CustomerID})
+	if err := stub.PutState(key, policyData); err != nil {
+		return nil, err
+	}
+	return policyData, nil
+}
+
+// RemoveBeneficiaryPolicy lifts the beneficiary policy of a customer
+func (cc *Chaincode) RemoveBeneficiaryPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required customer ID")
+	}
+	if err := cc.authorize(stub, auth.ManageBeneficiaries, &model.Account{CustomerID: args[0]}); err != nil {
+		return nil, err
+	}
+	key, _ := cc.createCompositeKey(stub, model.BeneficiaryPolicyObjectType, args)
+	return nil, stub.DelState(key)
+}
+
+// checkBeneficiary fails a transfer to another customer unless the payer's
+// beneficiary policy, if any, allows its payee
+func (cc *Chaincode) checkBeneficiary(stub shim.ChaincodeStubInterface, from *model.Account, t *model.Transfer) error {
+	if t.ToCustomerID == from.CustomerID && t.ToTenant == "" {
+		return nil
+	}
+	policy, err := cc.getBeneficiaryPolicy(stub, from.CustomerID)
+	if err != nil || policy == nil {
+		return err
+	}
+	beneficiaries, err := cc.getBeneficiaries(stub, from.CustomerID)
+	if err != nil {
+		return err
+	}
+	now := txContext(stub).Time.Unix()
+	for _, b := range beneficiaries {
+		if b.Pays(t) && policy.Allows(b, now) {
+			return nil
+		}
+	}
+	return &transferFailure{
+		code: model.BeneficiaryNotAllowed,
+		err:  fmt.Errorf("Customer %s may only transfer to verified beneficiaries added at least %d hours ago", from.CustomerID, policy.MinAgeHours),
+	}
+}
+
+func (cc *Chaincode) getBeneficiaries(stub shim.ChaincodeStubInterface, customerID string) ([]*model.Beneficiary, error) {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.BeneficiaryObjectType, []string{customerID})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get beneficiaries. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	beneficiaries := []*model.Beneficiary{}
+	for keysIter.HasNext() {
+		beneficiaryBytes := nextValue(keysIter)
+		beneficiary := new(model.Beneficiary)
+		if err := json.Unmarshal(beneficiaryBytes, beneficiary); err != nil {
+			loggerFor(stub).Errorf("Failed to get beneficiary details. Error: %s", err)
+			continue
+		}
+		beneficiaries = append(beneficiaries, beneficiary)
+	}
+	return beneficiaries, nil
+}
+
+func (cc *Chaincode) getBeneficiaryPolicy(stub shim.ChaincodeStubInterface, customerID string) (*model.BeneficiaryPolicy, error) {
+	key, _ := cc.createCompositeKey(stub, model.BeneficiaryPolicyObjectType, []string{customerID})
+	policyBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get beneficiary policy details. Error: %s", err)
+		return nil, err
+	}
+	if policyBytes == nil {
+		return nil, nil
+	}
+	policy := new(model.BeneficiaryPolicy)
+	if err := bytesToStruct(policyBytes, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Category budget handler functions
+//------------------------------
+
+// SetBudget creates or replaces a customer's monthly budget for a category
+func (cc *Chaincode) SetBudget(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required budget data JSON")
+	}
+	budget, err := model.CreateBudget([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating budget. Error: %s", err)
+	}
+	budgetData, _ := json.Marshal(budget)
+	key, _ := cc.createCompositeKey(stub, budget.GetObjectType(), []string{budget.CustomerID, budget.Category})
+	if err := stub.PutState(key, budgetData); err != nil {
+		return nil, err
+	}
+	return budgetData, nil
+}
+
+// RemoveBudget deletes a customer's budget for a category
+func (cc *Chaincode) RemoveBudget(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or category")
+	}
+	key, _ := cc.createCompositeKey(stub, model.BudgetObjectType, args)
+	return nil, stub.DelState(key)
+}
+
+// GetBudgets query a customer's budgets with the current month's spend
+func (cc *Chaincode) GetBudgets(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required customer ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.BudgetObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get budgets. Error: %s", err)
+		return nil, err
+	}
+	month := txContext(stub).Time.UTC().Format(model.RoundUpMonthFormat)
+	list := model.BudgetStatusList{Budgets: []*model.BudgetStatus{}}
+	for keysIter.HasNext() {
+		budgetBytes := nextValue(keysIter)
+		budget := new(model.Budget)
+		if err := json.Unmarshal(budgetBytes, budget); err != nil {
+			loggerFor(stub).Errorf("Failed to get budget details. Error: %s", err)
+			continue
+		}
+		spend, err := cc.getBudgetSpend(stub, budget.CustomerID, budget.Category, month)
+		if err != nil {
+			return nil, err
+		}
+		list.Budgets = append(list.Budgets, budget.Status(spend))
+	}
+	return json.Marshal(list)
+}
+
+// trackBudget adds a categorised transfer to the paying customer's monthly
+// spend. A hard budget rejects the transfer when it would be exceeded; a soft
+// budget lets it settle and emits a BudgetWarning event.
+func (cc *Chaincode) trackBudget(stub shim.ChaincodeStubInterface, customerID string, t *model.Transfer) error {
+	if t.Category == "" {
+		return nil
+	}
+	key, _ := cc.createCompositeKey(stub, model.BudgetObjectType, []string{customerID, t.Category})
+	budgetBytes, err := stub.GetState(key)
+	if err != nil || budgetBytes == nil {
+		return err
+	}
+	budget := new(model.Budget)
+	if err := bytesToStruct(budgetBytes, budget); err != nil {
+		return err
+	}
+	if budget.CurrencyCode != t.CurrencyCode {
+		return nil
+	}
+	month := txContext(stub).Time.UTC().Format(model.RoundUpMonthFormat)
+	spend, err := cc.getBudgetSpend(stub, customerID, t.Category, month)
+	if err != nil {
+		return err
+	}
+	spend.Spent += t.Amount
+	if spend.Spent > budget.Limit {
+		if budget.Enforcement == model.BudgetHard {
+			return fmt.Errorf("Payment would exceed the %s budget of %d for %s", budget.Category, budget.Limit, month)
+		}
+		warning := &model.BudgetWarning{CustomerID: customerID, Category: budget.Category, Month: month, Limit: budget.Limit, Spent: spend.Spent}
+		if err := emitEvent(stub, model.BudgetWarningEvent, warning); err != nil {
+			return err
+		}
+	}
+	spendData, _ := json.Marshal(spend)
+	spendKey, _ := cc.createCompositeKey(stub, spend.GetObjectType(), []string{spend.CustomerID, spend.Category, spend.Month})
+	return stub.PutState(spendKey, spendData)
+}
+
+func (cc *Chaincode) getBudgetSpend(stub shim.ChaincodeStubInterface, customerID string, category string, month string) (*model.BudgetSpend, error) {
+	key, _ := cc.createCompositeKey(stub, model.BudgetSpendObjectType, []string{customerID, category, month})
+	spendBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	spend := model.CreateBudgetSpend(customerID, category, month)
+	if spendBytes != nil {
+		if err := bytesToStruct(spendBytes, spend); err != nil {
+			return nil, err
+		}
+	}
+	return spend, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestBudgetsCountCategorisedPayments(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 5000)
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "SetBudget", `{"customer_id":"1001","category":"groceries","currency":"AUD","limit":1000,"enforcement":"hard"}`)
+	stub.MustCall(t, "SetBudget", `{"customer_id":"1001","category":"dining","currency":"AUD","limit":300}`)
+	transfer := func(amount int64, category string) error {
+		_, err := stub.Call("TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", amount).InCategory(category).JSON())
+		return err
+	}
+
+	if err := transfer(600, "groceries"); err != nil {
+		t.Fatal(err)
+	}
+	if err := transfer(500, "groceries"); err == nil || !strings.Contains(err.Error(), "Payment would exceed the groceries budget of 1000 for 2021-03") {
+		t.Errorf("Expected a payment over the hard budget refused, got %v", err)
+	}
+	if err := transfer(500, ""); err != nil {
+		t.Errorf("Expected an uncategorised payment not counted, got %v", err)
+	}
+	if err := transfer(400, "dining"); err != nil {
+		t.Errorf("Expected a payment over the soft budget made, got %v", err)
+	}
+	if len(stub.Events) != 1 || !strings.Contains(string(stub.Events[0].Payload), model.BudgetWarningEvent) {
+		t.Errorf("Expected a budget warning, got %v", stub.Events)
+	}
+
+	list := new(model.BudgetStatusList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetBudgets", "1001"), list); err != nil {
+		t.Fatal(err)
+	}
+	spent := make(map[string]int64)
+	for _, status := range list.Budgets {
+		spent[status.Category] = status.Spent
+	}
+	if len(spent) != 2 || spent["groceries"] != 600 || spent["dining"] != 400 {
+		t.Errorf("Expected the settled payments counted per category, got %v", spent)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Holiday calendar handler functions
+//------------------------------
+
+// AddHoliday adds a non-business day to the settlement calendar of a
+// currency. Restricted to network operators.
+func (cc *Chaincode) AddHoliday(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required holiday data JSON")
+	}
+	addedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	holiday, err := model.CreateHoliday([]byte(args[0]), addedBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	holidayData, _ := json.Marshal(holiday)
+	key, _ := cc.createCompositeKey(stub, holiday.GetObjectType(), []string{holiday.CurrencyCode, holiday.Date})
+	if err := stub.PutState(key, holidayData); err != nil {
+		return nil, err
+	}
+	return holidayData, nil
+}
+
+// RemoveHoliday removes a day from the settlement calendar of a currency.
+// Restricted to network operators.
+func (cc *Chaincode) RemoveHoliday(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required currency and / or date")
+	}
+	key, _ := cc.createCompositeKey(stub, model.HolidayObjectType, args)
+	holidayData, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if holidayData == nil {
+		return nil, fmt.Errorf("No %s holiday on %s", args[0], args[1])
+	}
+	return nil, stub.DelState(key)
+}
+
+// ListHolidays query the holidays of a currency in date order
+func (cc *Chaincode) ListHolidays(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.HolidayObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get holidays. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	list := model.HolidayList{Holidays: []*model.Holiday{}}
+	for keysIter.HasNext() {
+		holidayBytes := nextValue(keysIter)
+		holiday := new(model.Holiday)
+		if err := json.Unmarshal(holidayBytes, holiday); err != nil {
+			loggerFor(stub).Errorf("Failed to get holiday details. Error: %s", err)
+			continue
+		}
+		list.Holidays = append(list.Holidays, holiday)
+	}
+	return json.Marshal(list)
+}
+
+// valueDate returns the business day a transfer in the currency submitted in
+// this transaction takes value: the transaction date, or the next day after
+// the configured cut-off time, rolled forward over weekends and the holidays
+// of the currency
+func (cc *Chaincode) valueDate(stub shim.ChaincodeStubInterface, currency string) (string, error) {
+	config, err := cc.getConfig(stub)
+	if err != nil {
+		return "", err
+	}
+	date := model.FirstValueDate(txContext(stub).Time, config.CutOffTime)
+	for i := 0; i < model.MaxValueDateDays; i++ {
+		day := date.Format(model.ValueDateFormat)
+		if !model.IsWeekend(date) {
+			key, _ := cc.createCompositeKey(stub, model.HolidayObjectType, []string{currency, day})
+			holidayBytes, err := stub.GetState(key)
+			if err != nil {
+				loggerFor(stub).Errorf("Failed to get holiday details. Error: %s", err)
+				return "", err
+			}
+			if holidayBytes == nil {
+				return day, nil
+			}
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+	return "", fmt.Errorf("No %s business day within %d days", currency, model.MaxValueDateDays)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Checkpoint handler functions
+//------------------------------
+
+// CreateCheckpoint records the per-currency supply, per-bank totals and a digest
+// of the account and transaction state as a known-good checkpoint
+func (cc *Chaincode) CreateCheckpoint(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	operator, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint, err := cc.computeCheckpoint(stub, stub.GetTxID())
+	if err != nil {
+		return nil, err
+	}
+	checkpoint.Created = txContext(stub).Time.Unix()
+	checkpoint.TxID = stub.GetTxID()
+	checkpoint.Operator = operator
+	checkpointData, err := json.Marshal(checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling checkpoint data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, checkpoint.GetObjectType(), []string{checkpoint.ID})
+	if err := stub.PutState(key, checkpointData); err != nil {
+		return nil, err
+	}
+	return checkpointData, nil
+}
+
+// VerifyAgainstCheckpoint query whether current state matches a checkpoint,
+// listing every difference in supply, bank totals, record counts and digests
+func (cc *Chaincode) VerifyAgainstCheckpoint(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required checkpoint ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.CheckpointObjectType, args)
+	checkpointBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if checkpointBytes == nil {
+		return nil, fmt.Errorf("Checkpoint %s not found.", args[0])
+	}
+	checkpoint := new(model.Checkpoint)
+	if err := bytesToStruct(checkpointBytes, checkpoint); err != nil {
+		return nil, err
+	}
+	current, err := cc.computeCheckpoint(stub, "")
+	if err != nil {
+		return nil, err
+	}
+	mismatches := checkpoint.Compare(current)
+	return json.Marshal(&model.CheckpointVerification{
+		CheckpointID: checkpoint.ID,
+		Matches:      len(mismatches) == 0,
+		Mismatches:   mismatches,
+		Current:      current,
+	})
+}
+
+// GetCheckpoints query all checkpoints
+func (cc *Chaincode) GetCheckpoints(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.CheckpointObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get checkpoints. Error: %s", err)
+		return nil, err
+	}
+	list := model.CheckpointList{Checkpoints: []*model.Checkpoint{}}
+	for keysIter.HasNext() {
+		checkpointBytes := nextValue(keysIter)
+		checkpoint := new(model.Checkpoint)
+		if err := json.Unmarshal(checkpointBytes, checkpoint); err != nil {
+			loggerFor(stub).Errorf("Failed to get checkpoint details. Error: %s", err)
+			continue
+		}
+		list.Checkpoints = append(list.Checkpoints, checkpoint)
+	}
+	return json.Marshal(list)
+}
+
+// computeCheckpoint scans the checkpointed object types in key order, digesting
+// every key and value and totalling account balances
+func (cc *Chaincode) computeCheckpoint(stub shim.ChaincodeStubInterface, id string) (*model.Checkpoint, error) {
+	checkpoint := model.CreateCheckpoint(id)
+	for _, objectType := range model.CheckpointObjectTypes {
+		keysIter, err := cc.partialCompositeKeyQuery(stub, objectType, []string{})
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		for keysIter.HasNext() {
+			key, value, err := nextKeyValue(keysIter)
+			if err != nil {
+				return nil, err
+			}
+			for _, field := range [][]byte{[]byte(key), value} {
+				var n [8]byte
+				binary.BigEndian.PutUint64(n[:], uint64(len(field)))
+				h.Write(n[:])
+				h.Write(field)
+			}
+			checkpoint.Counts[objectType]++
+			if objectType == model.AccountObjectType {
+				account := new(model.Account)
+				if err := json.Unmarshal(value, account); err != nil {
+					return nil, fmt.Errorf("Error reading account %s. Error: %s", key, err)
+				}
+				checkpoint.AddAccount(account)
+			}
+		}
+		checkpoint.Digests[objectType] = hex.EncodeToString(h.Sum(nil))
+	}
+	return checkpoint, nil
+}
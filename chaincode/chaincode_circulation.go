@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Currency metrics handler functions
+//------------------------------
+
+// GetCurrencyMetrics query the minted, burned and emitted supply of a currency
+// with the balances in circulation and held in frozen accounts
+func (cc *Chaincode) GetCurrencyMetrics(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	supply, err := cc.getSupply(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	circulation, err := cc.getCirculation(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(model.NewCurrencyMetrics(supply, circulation))
+}
+
+// RebuildCirculation recomputes the circulation of every currency from the
+// accounts of all tenants. It is only needed once, for accounts written before
+// circulation was tracked. Restricted to network operators.
+func (cc *Chaincode) RebuildCirculation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	tx := unwrapTxStub(stub)
+	if tx == nil {
+		return nil, errors.New("Circulation can only be rebuilt within a transaction")
+	}
+	totals := make(map[string]*model.Circulation)
+	keysIter, err := cc.partialCompositeKeyQuery(tx, model.CirculationObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer keysIter.Close()
+	for keysIter.HasNext() {
+		circulation := new(model.Circulation)
+		if err := json.Unmarshal(nextValue(keysIter), circulation); err != nil {
+			loggerFor(stub).Errorf("Failed to get circulation details. Error: %s", err)
+			continue
+		}
+		totals[circulation.CurrencyCode] = model.CreateCirculation(circulation.CurrencyCode)
+	}
+	prefixes, err := cc.accountKeyPrefixes(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, prefix := range prefixes {
+		accountsIter, err := compositeKeyRange(tx, prefix, prefix, prefix+string(utf8.MaxRune))
+		if err != nil {
+			return nil, err
+		}
+		for accountsIter.HasNext() {
+			account := new(model.Account)
+			if err := json.Unmarshal(nextValue(accountsIter), account); err != nil {
+				loggerFor(stub).Errorf("Failed to get account details. Error: %s", err)
+				continue
+			}
+			if _, ok := totals[account.CurrencyCode]; !ok {
+				totals[account.CurrencyCode] = model.CreateCirculation(account.CurrencyCode)
+			}
+			totals[account.CurrencyCode].Add(account, 1)
+		}
+		accountsIter.Close()
+	}
+	currencies := make([]string, 0, len(totals))
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	list := []*model.Circulation{}
+	for _, currency := range currencies {
+		circulation := model.CreateCirculation(currency)
+		circulation.Apply(totals[currency], tx.context)
+		if err := cc.putCirculation(tx, circulation); err != nil {
+			return nil, err
+		}
+		list = append(list, circulation)
+	}
+	return json.Marshal(list)
+}
+
+// recordCirculation updates the circulation of every currency whose account
+// balances the invocation changed, from the difference between the buffered
+// account writes and the committed accounts. Transfers within a currency
+// between accounts that are not frozen leave the totals unchanged and write nothing.
+func (cc *Chaincode) recordCirculation(tx *txStub) error {
+	var keys []string
+	for key := range tx.writes {
+		if isAccountKey(tx, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	changes := make(map[string]*model.Circulation)
+	for _, key := range keys {
+		committed, err := tx.ChaincodeStubInterface.GetState(key)
+		if err != nil {
+			return err
+		}
+		for sign, value := range map[int64][]byte{-1: committed, 1: tx.writes[key]} {
+			if value == nil {
+				continue
+			}
+			account := new(model.Account)
+			if err := json.Unmarshal(value, account); err != nil {
+				return err
+			}
+			if _, ok := changes[account.CurrencyCode]; !ok {
+				changes[account.CurrencyCode] = model.CreateCirculation(account.CurrencyCode)
+			}
+			changes[account.CurrencyCode].Add(account, sign)
+		}
+	}
+	currencies := make([]string, 0, len(changes))
+	for currency, change := range changes {
+		if !change.Empty() {
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		circulation, err := cc.getCirculation(tx, currency)
+		if err != nil {
+			return err
+		}
+		circulation.Apply(changes[currency], tx.context)
+		if err := cc.putCirculation(tx, circulation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isAccountKey returns true if the key is that of an account, in a tenant
+// namespace or not
+func isAccountKey(stub shim.ChaincodeStubInterface, key string) bool {
+	objectType, attributes, err := stub.SplitCompositeKey(key)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(objectType, "@") && len(attributes) > 0 {
+		objectType = attributes[0]
+	}
+	return objectType == model.AccountObjectType
+}
+
+// accountKeyPrefixes returns the key prefix of the accounts outside tenant
+// namespaces and of the accounts of every assigned tenant
+func (cc *Chaincode) accountKeyPrefixes(stub shim.ChaincodeStubInterface) ([]string, error) {
+	prefix, err := cc.createCompositeKey(stub, model.AccountObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	prefixes := []string{prefix}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TenantObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer keysIter.Close()
+	seen := make(map[string]bool)
+	for keysIter.HasNext() {
+		tenant := new(model.Tenant)
+		if err := json.Unmarshal(nextValue(keysIter), tenant); err != nil {
+			loggerFor(stub).Errorf("Failed to get tenant details. Error: %s", err)
+			continue
+		}
+		if !seen[tenant.TenantID] {
+			seen[tenant.TenantID] = true
+			prefixes = append(prefixes, tenantNamespace(tenant.TenantID)+strings.TrimPrefix(prefix, compositeKeyNamespace))
+		}
+	}
+	return prefixes, nil
+}
+
+// getCirculation returns the circulation of a currency, empty if no account
+// in the currency holds a balance yet
+func (cc *Chaincode) getCirculation(stub shim.ChaincodeStubInterface, currency string) (*model.Circulation, error) {
+	if err := model.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+	key, _ := cc.createCompositeKey(stub, model.CirculationObjectType, []string{currency})
+	circulationBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get circulation details. Error: %s", err)
+		return nil, err
+	}
+	circulation := model.CreateCirculation(currency)
+	if circulationBytes == nil {
+		return circulation, nil
+	}
+	if err := bytesToStruct(circulationBytes, circulation); err != nil {
+		return nil, err
+	}
+	return circulation, nil
+}
+
+func (cc *Chaincode) putCirculation(stub shim.ChaincodeStubInterface, circulation *model.Circulation) error {
+	circulationData, _ := json.Marshal(circulation)
+	key, _ := cc.createCompositeKey(stub, circulation.GetObjectType(), []string{circulation.CurrencyCode})
+	return stub.PutState(key, circulationData)
+}
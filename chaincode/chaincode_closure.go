@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Account closure handler functions
+//------------------------------
+
+// GetFinalStatement query the final statement generated when an account was closed
+func (cc *Chaincode) GetFinalStatement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.FinalStatementObjectType, args)
+	statementBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get final statement details. Error: %s", err)
+		return nil, err
+	}
+	if statementBytes == nil {
+		return nil, fmt.Errorf("No final statement for account %s", args[1])
+	}
+	return statementBytes, nil
+}
+
+// checkNoPendingTransfers fails if transfers from the account are pending
+// settlement or held for approval
+func (cc *Chaincode) checkNoPendingTransfers(stub shim.ChaincodeStubInterface, account *model.Account) error {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.PendingTransferObjectType, []string{account.CustomerID, account.ID})
+	if err != nil {
+		return err
+	}
+	defer keysIter.Close()
+	for keysIter.HasNext() {
+		pending := new(model.PendingTransfer)
+		if err := json.Unmarshal(nextValue(keysIter), pending); err != nil {
+			loggerFor(stub).Errorf("Failed to get pending transfer details. Error: %s", err)
+			continue
+		}
+		if pending.Status == model.TransferPending {
+			return fmt.Errorf("Cannot close account %s with pending transfer %s", account.ID, pending.ID)
+		}
+	}
+	approvalsIter, err := cc.partialCompositeKeyQuery(stub, model.TransferApprovalObjectType, []string{account.CustomerID, account.ID})
+	if err != nil {
+		return err
+	}
+	defer approvalsIter.Close()
+	for approvalsIter.HasNext() {
+		approval := new(model.TransferApproval)
+		if err := json.Unmarshal(nextValue(approvalsIter), approval); err != nil {
+			loggerFor(stub).Errorf("Failed to get transfer approval details. Error: %s", err)
+			continue
+		}
+		if approval.Status == model.PendingApproval {
+			return fmt.Errorf("Cannot close account %s with transfer %s awaiting approval", account.ID, approval.ID)
+		}
+	}
+	return nil
+}
+
+// sweepForClosure moves the balance of an account being closed into the sweep
+// account and records the sweep on its final statement. An account with a
+// balance cannot be closed without a sweep account, nor one with a negative
+// balance.
+func (cc *Chaincode) sweepForClosure(stub shim.ChaincodeStubInterface, account *model.Account, toCustomerID string, toAccountID string, final *model.FinalStatement) error {
+	if account.Balance < 0 {
+		return fmt.Errorf("Cannot close account %s with a negative balance", account.ID)
+	}
+	if account.Balance == 0 {
+		return nil
+	}
+	if toCustomerID == "" || toAccountID == "" {
+		return fmt.Errorf("Cannot close account %s with a balance, name an account to sweep it to", account.ID)
+	}
+	if toCustomerID == account.CustomerID && toAccountID == account.ID {
+		return errors.New("Sweep account must differ from the closed account")
+	}
+	to, err := cc.getAccountStruct(stub, toCustomerID, toAccountID)
+	if err != nil {
+		return err
+	}
+	if !to.CanReceive() || to.CurrencyCode != account.CurrencyCode {
+		return fmt.Errorf("Sweep account %s cannot be credited in %s", to.ID, account.CurrencyCode)
+	}
+	t := &model.Transfer{
+		FromCustomerID: account.CustomerID,
+		FromAccountID:  account.ID,
+		ToCustomerID:   to.CustomerID,
+		ToAccountID:    to.ID,
+		Amount:         account.Balance,
+		CurrencyCode:   account.CurrencyCode,
+		Description:    fmt.Sprintf("Closure of account %s", account.ID),
+		Params:         map[string]string{"initiated_by": "system", "closed_account": account.ID},
+		Initiated:      txContext(stub).Time.Unix(),
+	}
+	if err := cc.debitAccount(stub, account, t.Money()); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, account.CustomerID, account.ID, t, "", model.Debited); err != nil {
+		return err
+	}
+	if err := cc.creditAccount(stub, to, t.Money()); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, to.CustomerID, to.ID, t, "", model.Credited); err != nil {
+		return err
+	}
+	final.SweptAmount, final.SweptCustomerID, final.SweptAccountID = t.Amount, to.CustomerID, to.ID
+	return emitTransferEvent(stub, t, nil)
+}
+
+func (cc *Chaincode) putFinalStatement(stub shim.ChaincodeStubInterface, final *model.FinalStatement) error {
+	statementData, err := json.Marshal(final)
+	if err != nil {
+		return fmt.Errorf("Error marshalling final statement data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, final.GetObjectType(), []string{final.CustomerID, final.AccountID})
+	return stub.PutState(key, statementData)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Collateral handler functions
+//------------------------------
+
+// SetExposureLimit configures the uncollateralized limit a bank may owe a counterparty in a currency
+func (cc *Chaincode) SetExposureLimit(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 {
+		return nil, errors.New("Missing required bank ID, counterparty ID, currency and / or limit")
+	}
+	limit, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil || limit < 0 {
+		return nil, fmt.Errorf("Error parsing limit value %s", args[3])
+	}
+	exposure, err := cc.getExposure(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	if exposure == nil {
+		exposure = model.CreateBilateralExposure(args[0], args[1], args[2])
+	}
+	exposure.Limit = limit
+	return cc.putExposure(stub, exposure)
+}
+
+// GetBilateralExposure query the exposure of a bank to a counterparty in a currency
+func (cc *Chaincode) GetBilateralExposure(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required bank ID, counterparty ID and / or currency")
+	}
+	key, _ := cc.createCompositeKey(stub, model.ExposureObjectType, args)
+	return stub.GetState(key)
+}
+
+// SettleBilateralExposure reduces the amount a bank owes a counterparty once
+// settled outside the ledger. No funds move and the treasury positions are
+// left to the netting run, which settles the underlying obligations.
+func (cc *Chaincode) SettleBilateralExposure(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 {
+		return nil, errors.New("Missing required bank ID, counterparty ID, currency and / or amount")
+	}
+	amount, err := model.ParseAmount(args[3])
+	if err != nil {
+		return nil, err
+	}
+	exposure, err := cc.mustGetExposure(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	if amount > exposure.Exposure {
+		return nil, fmt.Errorf("Settlement of %d exceeds exposure of %d", amount, exposure.Exposure)
+	}
+	exposure.Exposure -= amount
+	return cc.putExposure(stub, exposure)
+}
+
+// PledgeCollateral registers collateral against a configured bilateral exposure
+func (cc *Chaincode) PledgeCollateral(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required collateral data JSON")
+	}
+	collateral, err := model.CreateCollateral([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating collateral. Error: %s", err)
+	}
+	existing, err := cc.getCollateral(stub, collateral.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Collateral %s already exists", collateral.ID)
+	}
+	exposure, err := cc.mustGetExposure(stub, collateral.BankID, collateral.CounterpartyID, collateral.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	exposure.AddCollateral(collateral)
+	if _, err := cc.putExposure(stub, exposure); err != nil {
+		return nil, err
+	}
+	return cc.putCollateral(stub, collateral)
+}
+
+// ReleaseCollateral returns pledged collateral, provided the remaining
+// uncollateralized exposure stays within the configured limit
+func (cc *Chaincode) ReleaseCollateral(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required collateral ID")
+	}
+	collateral, exposure, err := cc.pledgedCollateral(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	exposure.RemoveCollateral(collateral, collateral.Value())
+	if exposure.Uncollateralized() > exposure.Limit {
+		return nil, fmt.Errorf("Releasing collateral %s would leave uncollateralized exposure of %d above limit %d", collateral.ID, exposure.Uncollateralized(), exposure.Limit)
+	}
+	collateral.Status = model.CollateralReleased
+	if _, err := cc.putExposure(stub, exposure); err != nil {
+		return nil, err
+	}
+	return cc.putCollateral(stub, collateral)
+}
+
+// MarkCollateralToMarket updates the market value of pledged collateral and
+// revalues the exposure it is registered against
+func (cc *Chaincode) MarkCollateralToMarket(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required collateral ID and / or market value")
+	}
+	marketValue, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || marketValue < 0 {
+		return nil, fmt.Errorf("Error parsing market value %s", args[1])
+	}
+	collateral, exposure, err := cc.pledgedCollateral(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	oldValue := collateral.Value()
+	collateral.MarketValue = marketValue
+	collateral.LastMarked = txContext(stub).Time.Unix()
+	exposure.CollateralValue += collateral.Value() - oldValue
+	if exposure.Uncollateralized() > exposure.Limit {
+		loggerFor(stub).Warningf("Bank %s exposure to %s in %s exceeds uncollateralized limit after mark-to-market", exposure.BankID, exposure.CounterpartyID, exposure.CurrencyCode)
+	}
+	if _, err := cc.putExposure(stub, exposure); err != nil {
+		return nil, err
+	}
+	return cc.putCollateral(stub, collateral)
+}
+
+// GetCollateral query collateral by ID
+func (cc *Chaincode) GetCollateral(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required collateral ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.CollateralObjectType, []string{args[0]})
+	return stub.GetState(key)
+}
+
+// increaseExposure adds amount to the exposure of one bank to another when a
+// configured exposure exists, failing if it would breach the uncollateralized limit
+func (cc *Chaincode) increaseExposure(stub shim.ChaincodeStubInterface, bankID string, counterpartyID string, currency string, amount int64) error {
+	if bankID == "" || counterpartyID == "" || bankID == counterpartyID {
+		return nil
+	}
+	exposure, err := cc.getExposure(stub, bankID, counterpartyID, currency)
+	if err != nil || exposure == nil {
+		return err
+	}
+	if !exposure.CanIncrease(amount) {
+		return fmt.Errorf("Uncollateralized exposure of bank %s to %s would exceed limit %d", bankID, counterpartyID, exposure.Limit)
+	}
+	exposure.Exposure += amount
+	_, err = cc.putExposure(stub, exposure)
+	return err
+}
+
+func (cc *Chaincode) pledgedCollateral(stub shim.ChaincodeStubInterface, collateralID string) (*model.Collateral, *model.BilateralExposure, error) {
+	collateral, err := cc.getCollateral(stub, collateralID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if collateral == nil {
+		return nil, nil, fmt.Errorf("Collateral %s not found.", collateralID)
+	}
+	if collateral.Status != model.CollateralPledged {
+		return nil, nil, fmt.Errorf("Collateral %s is not pledged", collateralID)
+	}
+	exposure, err := cc.mustGetExposure(stub, collateral.BankID, collateral.CounterpartyID, collateral.CurrencyCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	return collateral, exposure, nil
+}
+
+func (cc *Chaincode) getCollateral(stub shim.ChaincodeStubInterface, collateralID string) (*model.Collateral, error) {
+	key, _ := cc.createCompositeKey(stub, model.CollateralObjectType, []string{collateralID})
+	collateralBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get collateral details. Error: %s", err)
+		return nil, err
+	}
+	if collateralBytes == nil {
+		return nil, nil
+	}
+	collateral := new(model.Collateral)
+	if err := bytesToStruct(collateralBytes, collateral); err != nil {
+		return nil, err
+	}
+	return collateral, nil
+}
+
+func (cc *Chaincode) putCollateral(stub shim.ChaincodeStubInterface, collateral *model.Collateral) ([]byte, error) {
+	collateralData, err := json.Marshal(collateral)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling collateral data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, collateral.GetObjectType(), []string{collateral.ID})
+	if err := stub.PutState(key, collateralData); err != nil {
+		return nil, err
+	}
+	return collateralData, nil
+}
+
+func (cc *Chaincode) getExposure(stub shim.ChaincodeStubInterface, bankID string, counterpartyID string, currency string) (*model.BilateralExposure, error) {
+	key, _ := cc.createCompositeKey(stub, model.ExposureObjectType, []string{bankID, counterpartyID, currency})
+	exposureBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get exposure details. Error: %s", err)
+		return nil, err
+	}
+	if exposureBytes == nil {
+		return nil, nil
+	}
+	exposure := new(model.BilateralExposure)
+	if err := bytesToStruct(exposureBytes, exposure); err != nil {
+		return nil, err
+	}
+	return exposure, nil
+}
+
+func (cc *Chaincode) mustGetExposure(stub shim.ChaincodeStubInterface, bankID string, counterpartyID string, currency string) (*model.BilateralExposure, error) {
+	exposure, err := cc.getExposure(stub, bankID, counterpartyID, currency)
+	if err != nil {
+		return nil, err
+	}
+	if exposure == nil {
+		return nil, fmt.Errorf("No exposure limit configured for bank %s to %s in %s", bankID, counterpartyID, currency)
+	}
+	return exposure, nil
+}
+
+func (cc *Chaincode) putExposure(stub shim.ChaincodeStubInterface, exposure *model.BilateralExposure) ([]byte, error) {
+	exposureData, err := json.Marshal(exposure)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling exposure data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, exposure.GetObjectType(), []string{exposure.BankID, exposure.CounterpartyID, exposure.CurrencyCode})
+	if err := stub.PutState(key, exposureData); err != nil {
+		return nil, err
+	}
+	return exposureData, nil
+}
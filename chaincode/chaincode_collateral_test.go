@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestCollateralRaisesTheExposureBanksMayRunUp(t *testing.T) {
+	stub := newTestStub()
+	stub.As(testsupport.Operator(t, RoleNetworkOperator))
+	stub.MustCall(t, "RegisterBank", settlementBank("FINNAU2S", "9001"))
+	stub.MustCall(t, "RegisterBank", settlementBank("OTHRAU2S", "9002"))
+	stub.OpenAccount(t, testsupport.NewAccount("9001", "1").AtBank("FINNAU2S"))
+	stub.OpenAccount(t, testsupport.NewAccount("9002", "1").AtBank("OTHRAU2S"))
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1").AtBank("FINNAU2S"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1").AtBank("OTHRAU2S"))
+	stub.Topup(t, "1001", "1", 100000)
+	riskOfficer, payer := testsupport.Operator(t, RoleRiskOfficer), testsupport.Customer(t, "1001")
+	transfer := func(amount int64) error {
+		_, err := stub.As(payer).Call("TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", amount).JSON())
+		return err
+	}
+
+	stub.As(riskOfficer).MustCall(t, "SetExposureLimit", "FINNAU2S", "OTHRAU2S", "AUD", "10000")
+	if err := transfer(8000); err != nil {
+		t.Fatal(err)
+	}
+	if err := transfer(5000); err == nil || !strings.Contains(err.Error(), "Uncollateralized exposure of bank FINNAU2S to OTHRAU2S would exceed limit 10000") {
+		t.Errorf("Expected the transfer above the exposure limit refused, got %v", err)
+	}
+
+	// 5000 pledged with a 20% haircut covers 4000 of exposure
+	stub.As(riskOfficer).MustCall(t, "PledgeCollateral", `{"id":"c1","bank_id":"FINNAU2S","counterparty_id":"OTHRAU2S","currency":"AUD","type":"government_bond","pledged_amount":5000,"haircut":2000}`)
+	if err := transfer(5000); err != nil {
+		t.Errorf("Expected the collateral to cover the transfer, got %v", err)
+	}
+	stub.As(riskOfficer)
+	if _, err := stub.Call("ReleaseCollateral", "c1"); err == nil || !strings.Contains(err.Error(), "would leave uncollateralized exposure of 13000 above limit 10000") {
+		t.Errorf("Expected the collateral held while the exposure needs it, got %v", err)
+	}
+
+	stub.MustCall(t, "SettleBilateralExposure", "FINNAU2S", "OTHRAU2S", "AUD", "5000")
+	stub.MustCall(t, "ReleaseCollateral", "c1")
+	exposure := new(model.BilateralExposure)
+	if err := json.Unmarshal(stub.MustCall(t, "GetBilateralExposure", "FINNAU2S", "OTHRAU2S", "AUD"), exposure); err != nil {
+		t.Fatal(err)
+	}
+	if exposure.Exposure != 8000 || exposure.CollateralValue != 0 || len(exposure.CollateralIDs) != 0 {
+		t.Errorf("Expected the settled exposure left without collateral, got %+v", exposure)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Configuration handler functions
+//------------------------------
+
+// Init stores the deployment configuration passed at instantiation. Without
+// a configuration JSON, e.g. when a chaincode upgrade requires initialization,
+// an existing configuration is kept and an empty one stored otherwise. Once a
+// configuration is stored it may only be changed with UpdateConfig. Contract
+// transactions cannot tell an initialization from a regular invocation, so
+// Init is restricted to network operators like UpdateConfig.
+//
+// Export pages of ExportState following the configuration JSON are imported
+// as by ImportState before the configuration is stored, so that a chaincode
+// deployed to migrate a channel is initialized with the exported state. An
+// imported configuration is kept unless one is passed as well, which fails.
+func (cc *Chaincode) Init(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 1 {
+		if _, err := cc.importPages(stub, args[1:]); err != nil {
+			return nil, err
+		}
+	}
+	configBytes, err := stub.GetState(cc.configKey(stub))
+	if err != nil {
+		return nil, err
+	}
+	configJSON := optionalArg(args, 0)
+	if configBytes != nil {
+		if configJSON != "" {
+			return nil, errors.New("Configuration already initialized, use UpdateConfig to change it")
+		}
+		return configBytes, nil
+	}
+	if configJSON == "" {
+		configJSON = "{}"
+	}
+	return cc.putConfig(stub, configJSON)
+}
+
+// GetConfig query the deployment configuration. Restricted to network operators.
+func (cc *Chaincode) GetConfig(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	config, err := cc.getConfig(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(config)
+}
+
+// UpdateConfig replaces the deployment configuration. Restricted to network operators.
+func (cc *Chaincode) UpdateConfig(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required configuration JSON")
+	}
+	return cc.putConfig(stub, args[0])
+}
+
+// EnableFeature turns a feature flag on, making the functions requiring it
+// available. Restricted to network operators.
+func (cc *Chaincode) EnableFeature(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return cc.setFeature(stub, args, true)
+}
+
+// DisableFeature turns a feature flag off. Restricted to network operators.
+func (cc *Chaincode) DisableFeature(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return cc.setFeature(stub, args, false)
+}
+
+// ListFeatures query the feature flags required by functions or set in the
+// configuration, and whether they are enabled
+func (cc *Chaincode) ListFeatures(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	config, err := cc.getConfig(stub)
+	if err != nil {
+		return nil, err
+	}
+	functions := handlerMap.Features()
+	for name := range config.Features {
+		if _, ok := functions[name]; !ok {
+			functions[name] = []string{}
+		}
+	}
+	features := []*model.Feature{}
+	for name, names := range functions {
+		features = append(features, &model.Feature{Name: name, Enabled: config.FeatureEnabled(name), Functions: names})
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i].Name < features[j].Name })
+	return json.Marshal(features)
+}
+
+func (cc *Chaincode) setFeature(stub shim.ChaincodeStubInterface, args []string, enabled bool) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required feature name")
+	}
+	if err := model.ValidateFeatureName(args[0]); err != nil {
+		return nil, err
+	}
+	setBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	config, err := cc.getConfig(stub)
+	if err != nil {
+		return nil, err
+	}
+	config.SetFeature(args[0], enabled, setBy, txContext(stub))
+	return cc.storeConfig(stub, config)
+}
+
+// requireFeature fails unless the feature flag is enabled in the configuration
+func (cc *Chaincode) requireFeature(stub shim.ChaincodeStubInterface, feature string) error {
+	config, err := cc.getConfig(stub)
+	if err != nil {
+		return err
+	}
+	if !config.FeatureEnabled(feature) {
+		return fmt.Errorf("Function not available, feature %s is disabled", feature)
+	}
+	return nil
+}
+
+func (cc *Chaincode) configKey(stub shim.ChaincodeStubInterface) string {
+	key, _ := cc.createCompositeKey(stub, model.ConfigObjectType, []string{})
+	return key
+}
+
+// putConfig validates and stores a configuration JSON. The fee account is not
+// looked up, as it may be opened after instantiation.
+func (cc *Chaincode) putConfig(stub shim.ChaincodeStubInterface, configJSON string) ([]byte, error) {
+	setBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	config, err := model.CreateConfig([]byte(configJSON), setBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	return cc.storeConfig(stub, config)
+}
+
+func (cc *Chaincode) storeConfig(stub shim.ChaincodeStubInterface, config *model.Config) ([]byte, error) {
+	configData, _ := json.Marshal(config)
+	if err := stub.PutState(cc.configKey(stub), configData); err != nil {
+		return nil, err
+	}
+	return configData, nil
+}
+
+// getConfig returns the deployment configuration, an empty one if none is stored
+func (cc *Chaincode) getConfig(stub shim.ChaincodeStubInterface) (*model.Config, error) {
+	config := &model.Config{Entity: model.Entity{ObjectType: model.ConfigObjectType}}
+	configBytes, err := stub.GetState(cc.configKey(stub))
+	if err != nil || configBytes == nil {
+		return config, err
+	}
+	if err := bytesToStruct(configBytes, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
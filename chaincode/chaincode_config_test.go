@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestInitRequiresNetworkOperator(t *testing.T) {
+	stub := newTestStub()
+	config := `{"base_currency":"AUD","emission_authority_msp":"Org1MSP"}`
+	if _, err := stub.As(testsupport.Customer(t, "1001")).Call("Init", config); err == nil || !strings.Contains(err.Error(), "Caller is not authorized as network_operator") {
+		t.Errorf("Expected a customer refused to initialize the configuration, got %v", err)
+	}
+	if len(stub.State) > 0 {
+		t.Errorf("Expected no configuration stored, got %d keys", len(stub.State))
+	}
+
+	stub.As(testsupport.Operator(t, RoleNetworkOperator)).MustCall(t, "Init", config)
+	if _, err := stub.Call("Init", config); err == nil || !strings.Contains(err.Error(), "already initialized") {
+		t.Errorf("Expected the configuration initialized once, got %v", err)
+	}
+	if !strings.Contains(string(stub.MustCall(t, "GetConfig")), `"emission_authority_msp":"Org1MSP"`) {
+		t.Errorf("Expected the configuration of the network operator stored")
+	}
+}
+
+func TestInitImportsExportPages(t *testing.T) {
+	source := newTestStub()
+	source.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	source.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	exported := source.As(testsupport.Operator(t, RoleNetworkOperator)).MustCall(t, "ExportState", model.AccountObjectType)
+
+	target := newTestStub()
+	target.As(testsupport.Operator(t, RoleNetworkOperator)).MustCall(t, "Init", `{"base_currency":"AUD"}`, string(exported))
+	sourcePage, targetPage := new(model.StateExportPage), new(model.StateExportPage)
+	if err := json.Unmarshal(exported, sourcePage); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(target.MustCall(t, "ExportState", model.AccountObjectType), targetPage); err != nil {
+		t.Fatal(err)
+	}
+	if len(sourcePage.Records) != 2 || !reflect.DeepEqual(sourcePage.Records, targetPage.Records) {
+		t.Errorf("Expected the 2 exported accounts imported, got %d records", len(targetPage.Records))
+	}
+	if !strings.Contains(string(target.MustCall(t, "GetConfig")), `"base_currency":"AUD"`) {
+		t.Errorf("Expected the configuration stored with the imported state")
+	}
+	if _, err := target.Call("ImportState", string(exported)); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected imported keys not overwritten, got %v", err)
+	}
+}
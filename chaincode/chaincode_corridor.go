@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Corridor analytics handler functions
+//------------------------------
+
+// GetCorridorStats query the volumes, settlement times, failure rate and fees
+// of a corridor in a currency, optionally within a date window (YYYY-MM-DD, inclusive)
+func (cc *Chaincode) GetCorridorStats(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 3 || len(args) > 5 {
+		return nil, errors.New("Missing required from country, to country and / or currency")
+	}
+	corridor := model.CorridorID(args[0], args[1])
+	stats := model.CreateCorridorStats(corridor, args[2], optionalArg(args, 3), optionalArg(args, 4))
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.CorridorBucketObjectType, []string{corridor, args[2]})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get corridor buckets. Error: %s", err)
+		return nil, err
+	}
+	for keysIter.HasNext() {
+		bucketBytes := nextValue(keysIter)
+		bucket := new(model.CorridorBucket)
+		if err := json.Unmarshal(bucketBytes, bucket); err != nil {
+			loggerFor(stub).Errorf("Failed to get corridor bucket details. Error: %s", err)
+			continue
+		}
+		stats.Add(bucket)
+	}
+	return json.Marshal(stats)
+}
+
+// recordCorridorFlow adds a settled or failed transfer to the daily bucket of its corridor
+func (cc *Chaincode) recordCorridorFlow(stub shim.ChaincodeStubInterface, from *model.Account, to *model.Account, t *model.Transfer, status model.TxStatus) error {
+	now := txContext(stub).Time.UTC()
+	corridor := model.CorridorID(from.CountryCode, to.CountryCode)
+	date := now.Format(model.PayDateFormat)
+	key, _ := cc.createCompositeKey(stub, model.CorridorBucketObjectType, []string{corridor, t.CurrencyCode, date})
+	bucketBytes, err := stub.GetState(key)
+	if err != nil {
+		return err
+	}
+	bucket := model.CreateCorridorBucket(corridor, t.CurrencyCode, date)
+	if bucketBytes != nil {
+		if err := bytesToStruct(bucketBytes, bucket); err != nil {
+			return err
+		}
+	}
+	if status == model.Failed {
+		bucket.Fail()
+	} else {
+		bucket.Settle(t, now)
+	}
+	bucketData, _ := json.Marshal(bucket)
+	return stub.PutState(key, bucketData)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Customer handler functions
+//------------------------------
+
+// RegisterCustomer registers a new customer profile. Restricted to tellers and
+// account operators.
+func (cc *Chaincode) RegisterCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required customer data JSON")
+	}
+	registeredBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := model.CreateCustomer([]byte(args[0]), registeredBy, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating customer. Error: %s", err)
+	}
+	existing, err := cc.getCustomer(stub, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Customer %s is already registered", customer.ID)
+	}
+	return cc.putCustomer(stub, customer)
+}
+
+// UpdateCustomer replaces a registered customer's profile. Restricted to
+// tellers and account operators.
+func (cc *Chaincode) UpdateCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or customer data JSON")
+	}
+	customer, err := cc.mustGetCustomer(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := customer.Update([]byte(args[1]), txContext(stub)); err != nil {
+		return nil, fmt.Errorf("Error updating customer. Error: %s", err)
+	}
+	return cc.putCustomer(stub, customer)
+}
+
+// GetCustomer query a registered customer's profile
+func (cc *Chaincode) GetCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required customer ID")
+	}
+	customer, err := cc.mustGetCustomer(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(customer)
+}
+
+// GetCustomerSummary query the balances per currency of all accounts of a
+// customer, the number of open and closed accounts and their last activity
+func (cc *Chaincode) GetCustomerSummary(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required customer ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.AccountObjectType, []string{args[0]})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get account list. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	accounts := []*model.Account{}
+	for keysIter.HasNext() {
+		accountBytes := nextValue(keysIter)
+		acc := new(model.Account)
+		if err := json.Unmarshal(accountBytes, acc); err != nil {
+			loggerFor(stub).Errorf("Failed to get account details. Error: %s", err)
+			continue
+		}
+		accounts = append(accounts, acc)
+	}
+	summary, err := model.SummarizeAccounts(args[0], accounts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(summary)
+}
+
+// requireCustomer fails unless the customer is registered
+func (cc *Chaincode) requireCustomer(stub shim.ChaincodeStubInterface, customerID string) error {
+	_, err := cc.mustGetCustomer(stub, customerID)
+	return err
+}
+
+func (cc *Chaincode) mustGetCustomer(stub shim.ChaincodeStubInterface, customerID string) (*model.Customer, error) {
+	customer, err := cc.getCustomer(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, fmt.Errorf("Customer %s is not registered", customerID)
+	}
+	return customer, nil
+}
+
+func (cc *Chaincode) getCustomer(stub shim.ChaincodeStubInterface, customerID string) (*model.Customer, error) {
+	key, _ := cc.createCompositeKey(stub, model.CustomerObjectType, []string{customerID})
+	customerBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get customer details. Error: %s", err)
+		return nil, err
+	}
+	if customerBytes == nil {
+		return nil, nil
+	}
+	customer := new(model.Customer)
+	if err := bytesToStruct(customerBytes, customer); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+func (cc *Chaincode) putCustomer(stub shim.ChaincodeStubInterface, customer *model.Customer) ([]byte, error) {
+	customerData, err := json.Marshal(customer)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling customer data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, customer.GetObjectType(), []string{customer.ID})
+	if err := stub.PutState(key, customerData); err != nil {
+		return nil, err
+	}
+	return customerData, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// disburse pays all items from one account in a single all-or-nothing write
+// set. Accounts are read once, updated in memory and written once at the end,
+// so a payee paid several times gets a single account write.
+func (cc *Chaincode) disburse(stub shim.ChaincodeStubInterface, from *model.Account, items []*model.Disbursement, params map[string]string) error {
+	if !from.CanSend() {
+		return fmt.Errorf("Cannot transfer money from %s account %s", from.Status, from.ID)
+	}
+	var total int64
+	for i, item := range items {
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("Invalid disbursement %d. Error: %s", i, err)
+		}
+		if item.CustomerID == from.CustomerID && item.AccountID == from.ID {
+			return fmt.Errorf("Invalid disbursement %d. Cannot pay the source account", i)
+		}
+		total += item.Amount
+	}
+	if from.Unheld()-total < 0 {
+		return fmt.Errorf("Insufficient funds available in account %s", from.ID)
+	}
+
+	payees := make(map[string]*model.Account)
+	var order []*model.Account
+	for _, item := range items {
+		payeeKey, _ := cc.createCompositeKey(stub, model.AccountObjectType, []string{item.CustomerID, item.AccountID})
+		payee, ok := payees[payeeKey]
+		if !ok {
+			var err error
+			if payee, err = cc.getAccountStruct(stub, item.CustomerID, item.AccountID); err != nil {
+				return err
+			}
+			if !payee.CanReceive() {
+				return fmt.Errorf("Cannot transfer money into closed account %s", payee.ID)
+			}
+			if payee.CurrencyCode != from.CurrencyCode {
+				return fmt.Errorf("Account %s currency %s does not match %s", payee.ID, payee.CurrencyCode, from.CurrencyCode)
+			}
+			payees[payeeKey] = payee
+			order = append(order, payee)
+		}
+		t := item.Transfer(from, params)
+		if err := from.Debit(from.Money(item.Amount), txContext(stub)); err != nil {
+			return err
+		}
+		if err := cc.recordTransaction(stub, from.CustomerID, from.ID, t, "", model.Debited); err != nil {
+			return err
+		}
+		if err := payee.Credit(payee.Money(item.Amount), txContext(stub)); err != nil {
+			return err
+		}
+		if err := cc.recordTransaction(stub, payee.CustomerID, payee.ID, t, "", model.Credited); err != nil {
+			return err
+		}
+	}
+	if _, err := cc.putAccount(stub, from); err != nil {
+		return err
+	}
+	for _, payee := range order {
+		if _, err := cc.putAccount(stub, payee); err != nil {
+			return err
+		}
+	}
+	return nil
+}
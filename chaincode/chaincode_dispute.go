@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Dispute handler functions
+//------------------------------
+
+// OpenDispute disputes a transfer debited from the payer's account and holds
+// the amount credited to the payee until the dispute is resolved. A
+// transaction can be disputed once.
+func (cc *Chaincode) OpenDispute(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 {
+		return nil, errors.New("Missing required customer ID, account ID, transaction ID and / or reason")
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Dispute, account); err != nil {
+		return nil, err
+	}
+	txnBytes, err := cc.GetTransaction(stub, args[:3])
+	if err != nil {
+		return nil, err
+	}
+	if txnBytes == nil {
+		return nil, fmt.Errorf("Transaction %s not found.", args[2])
+	}
+	txn := new(model.Transaction)
+	if err := bytesToStruct(txnBytes, txn); err != nil {
+		return nil, err
+	}
+	existing, err := cc.getDispute(stub, txn.CustomerID, txn.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Transaction %s is already disputed", txn.ID)
+	}
+	openedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	tx := txContext(stub)
+	dispute, err := model.CreateDispute(txn, args[3], stub.GetTxID(), openedBy, tx)
+	if err != nil {
+		return nil, err
+	}
+	payee, err := cc.getAccountStruct(stub, dispute.PayeeCustomerID, dispute.PayeeAccountID)
+	if err != nil {
+		return nil, err
+	}
+	// the amount is held even if the payee no longer has it available, so that
+	// the payee cannot move out money it may have to refund
+	hold, err := model.CreateHold(dispute.HoldID, payee, dispute.Amount, "dispute "+dispute.ID, openedBy, tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := payee.Hold(payee.Money(hold.Amount)); err != nil {
+		return nil, err
+	}
+	if _, err := cc.putAccount(stub, payee); err != nil {
+		return nil, err
+	}
+	if _, err := cc.putHold(stub, hold); err != nil {
+		return nil, err
+	}
+	return cc.putDispute(stub, dispute)
+}
+
+// ResolveDispute closes an open dispute. The "refund" outcome reverses the
+// disputed amount from the payee to the payer, "reject" releases it to the
+// payee. Restricted to dispute officers.
+func (cc *Chaincode) ResolveDispute(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, dispute ID and / or outcome")
+	}
+	dispute, err := cc.getDispute(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if dispute == nil {
+		return nil, fmt.Errorf("Dispute %s not found.", args[1])
+	}
+	if dispute.Status != model.DisputeOpen {
+		return nil, fmt.Errorf("Dispute %s is %s", dispute.ID, dispute.Status)
+	}
+	resolvedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	tx := txContext(stub)
+	outcome := model.DisputeOutcome(args[2])
+	if err := dispute.Resolve(outcome, resolvedBy, tx); err != nil {
+		return nil, err
+	}
+	hold, err := cc.mustGetHold(stub, dispute.PayeeCustomerID, dispute.PayeeAccountID, dispute.HoldID)
+	if err != nil {
+		return nil, err
+	}
+	payee, err := cc.getAccountStruct(stub, dispute.PayeeCustomerID, dispute.PayeeAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status == model.HoldActive {
+		if err := payee.Unhold(payee.Money(hold.Amount)); err != nil {
+			return nil, err
+		}
+		hold.Release(resolvedBy, tx)
+		if _, err := cc.putHold(stub, hold); err != nil {
+			return nil, err
+		}
+	}
+	if outcome == model.DisputeRefund {
+		if err := cc.reverseDisputed(stub, dispute, payee); err != nil {
+			return nil, err
+		}
+	} else if _, err := cc.putAccount(stub, payee); err != nil {
+		return nil, err
+	}
+	return cc.putDispute(stub, dispute)
+}
+
+// GetDisputes query the disputes opened by a customer
+func (cc *Chaincode) GetDisputes(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required customer ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.DisputeObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get disputes. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	list := model.DisputeList{Disputes: []*model.Dispute{}}
+	for keysIter.HasNext() {
+		disputeBytes := nextValue(keysIter)
+		dispute := new(model.Dispute)
+		if err := json.Unmarshal(disputeBytes, dispute); err != nil {
+			loggerFor(stub).Errorf("Failed to get dispute details. Error: %s", err)
+			continue
+		}
+		list.Disputes = append(list.Disputes, dispute)
+	}
+	return json.Marshal(list)
+}
+
+// reverseDisputed refunds the disputed amount from the payee to the payer. The
+// payee is debited even if this takes its balance below zero, fees and
+// withheld tax are not refunded.
+func (cc *Chaincode) reverseDisputed(stub shim.ChaincodeStubInterface, dispute *model.Dispute, payee *model.Account) error {
+	payer, err := cc.getAccountStruct(stub, dispute.CustomerID, dispute.AccountID)
+	if err != nil {
+		return err
+	}
+	if !payer.CanReceive() {
+		return fmt.Errorf("Cannot refund dispute %s into closed account %s", dispute.ID, payer.ID)
+	}
+	t := dispute.Reversal()
+	t.Initiated = txContext(stub).Time.Unix()
+	t.Overdraft = payee.Balance-t.Amount < 0
+	if err := cc.debitAccount(stub, payee, t.Money()); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, payee.CustomerID, payee.ID, t, "", model.Debited); err != nil {
+		return err
+	}
+	if err := cc.creditAccount(stub, payer, t.Money()); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, payer.CustomerID, payer.ID, t, "", model.Credited); err != nil {
+		return err
+	}
+	return emitTransferEvent(stub, t, nil)
+}
+
+func (cc *Chaincode) getDispute(stub shim.ChaincodeStubInterface, customerID string, disputeID string) (*model.Dispute, error) {
+	key, _ := cc.createCompositeKey(stub, model.DisputeObjectType, []string{customerID, disputeID})
+	disputeBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get dispute details. Error: %s", err)
+		return nil, err
+	}
+	if disputeBytes == nil {
+		return nil, nil
+	}
+	dispute := new(model.Dispute)
+	if err := bytesToStruct(disputeBytes, dispute); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+func (cc *Chaincode) putDispute(stub shim.ChaincodeStubInterface, dispute *model.Dispute) ([]byte, error) {
+	disputeData, err := json.Marshal(dispute)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling dispute data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, dispute.GetObjectType(), []string{dispute.CustomerID, dispute.ID})
+	if err := stub.PutState(key, disputeData); err != nil {
+		return nil, err
+	}
+	return disputeData, nil
+}
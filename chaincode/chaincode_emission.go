@@ -1,135 +1,399 @@
-/*
-This chaincode provides a very simplistic shared ledger view of cross border
-financial transactions. Its main purpose is to experiment with the hyperledger
-fabric blockchain service on IBM Bluemix.
-*/
 package main
 
 import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"sort"
-	"strconv"
-	"unicode/utf8"
 
+	"github.com/iShamSLam/chaincode/model"
 
-	"github.com/hyperledger/fabric/core/chaincode/shim" // v0.6
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 )
 
-var (
-	// passport chaincode application logger
-	logger = shim.NewLogger("passport-chaincode")
-	// mapping of chaincode handler functions
-	handlerMap = NewHandlerMap()
-)
+//------------------------------
+// Emission handler functions
+//------------------------------
 
-func main() {
-	initLogging()
-	cc := new(Chaincode)
-	cc.registerHandlers()
-	err := shim.Start(cc)
-	if err != nil {
-		logger.Errorf("Error starting chaincode: %s", err)
-	}
+// Mint issues new money into an account and increases the total supply of its
+// currency. Restricted to the emission authority.
+func (cc *Chaincode) Mint(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return cc.emit(stub, model.Mint, args)
 }
 
-// Chaincode Chaincode shim method receiver struct
-type Chaincode struct{}
-
-//------------------------
-// Chaincode API functions
-//------------------------
+// Burn removes money from an account and decreases the total supply of its
+// currency. Restricted to the emission authority.
+func (cc *Chaincode) Burn(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return cc.emit(stub, model.Burn, args)
+}
 
-// Init called to initialize the chaincode
-func (cc *Chaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	return nil, nil
+// TotalSupply query the total money issued in a currency
+func (cc *Chaincode) TotalSupply(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	supply, err := cc.getSupply(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(supply)
 }
 
-// Invoke chaincode interface implementation
-func (cc *Chaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	return cc.handleInvocation(stub, function, args)
+// GetEmissionRecords query the audit records of all mints and burns in a currency
+func (cc *Chaincode) GetEmissionRecords(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.EmissionRecordObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get emission records. Error: %s", err)
+		return nil, err
+	}
+	list := model.EmissionRecordList{Records: []*model.EmissionRecord{}}
+	for keysIter.HasNext() {
+		recordBytes := nextValue(keysIter)
+		record := new(model.EmissionRecord)
+		if err := json.Unmarshal(recordBytes, record); err != nil {
+			loggerFor(stub).Errorf("Failed to get emission record details. Error: %s", err)
+			continue
+		}
+		if record.CurrencyCode == args[0] {
+			list.Records = append(list.Records, record)
+		}
+	}
+	return json.Marshal(list)
 }
 
-// Query chaincode interface implementation
-func (cc *Chaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	return cc.handleInvocation(stub, function, args)
+// SetEmissionPolicy sets the threshold above which mints and burns in a
+// currency must be proposed and approved, and the number of approvals they
+// need, replacing any existing policy. Restricted to network operators.
+func (cc *Chaincode) SetEmissionPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required emission policy data JSON")
+	}
+	setBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := model.CreateEmissionPolicy([]byte(args[0]), setBy, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating emission policy. Error: %s", err)
+	}
+	policyData, _ := json.Marshal(policy)
+	key, _ := cc.createCompositeKey(stub, policy.GetObjectType(), []string{policy.CurrencyCode})
+	if err := stub.PutState(key, policyData); err != nil {
+		return nil, err
+	}
+	return policyData, nil
 }
 
-func (cc *Chaincode) handleInvocation(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	logger.Debugf("Invoking chaincode handler function %s with args %v", function, args)
+// GetEmissionPolicy query the emission policy of a currency
+func (cc *Chaincode) GetEmissionPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	key, _ := cc.createCompositeKey(stub, model.EmissionPolicyObjectType, args)
+	return stub.GetState(key)
+}
 
-	res, err := handlerMap.Handle(stub, function, args)
+// ProposeEmission proposes a mint or burn for approval by the number of
+// distinct emission authority identities the policy of the currency requires,
+// or one if no policy is set. Restricted to the emission authority.
+func (cc *Chaincode) ProposeEmission(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 4 || len(args) > 5 {
+		return nil, errors.New("Missing required operation, customer ID, account ID and / or amount")
+	}
+	proposedBy, err := callerID(stub)
 	if err != nil {
-		logger.Errorf("Error when calling handler for function %s. Error: %s", function, err)
+		return nil, err
 	}
-	return res, err
+	if err := cc.requireEmissionAuthorityMSP(stub); err != nil {
+		return nil, err
+	}
+	amount, err := model.ParseAmount(args[3])
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	policy, err := cc.getEmissionPolicy(stub, account.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	required := 1
+	if policy != nil {
+		required = policy.RequiredApprovals
+	}
+	proposal, err := model.CreateEmissionProposal(model.EmissionOperation(args[0]), account, amount, optionalArg(args, 4), required, proposedBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	return cc.putEmissionProposal(stub, proposal)
 }
 
-//------------------
-// Handler functions
-//------------------
+// ApproveEmission records the calling emission authority identity's approval
+// of a proposal. The proposal is approved once it has the approvals it
+// requires. Restricted to the emission authority.
+func (cc *Chaincode) ApproveEmission(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required currency and / or proposal ID")
+	}
+	approver, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.requireEmissionAuthorityMSP(stub); err != nil {
+		return nil, err
+	}
+	proposal, err := cc.getEmissionProposal(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if err := proposal.Approve(approver, txContext(stub)); err != nil {
+		return nil, err
+	}
+	return cc.putEmissionProposal(stub, proposal)
+}
 
+// ExecuteEmission mints or burns an approved proposal. The emission record
+// names the proposer and every approver. Restricted to the emission authority.
+func (cc *Chaincode) ExecuteEmission(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required currency and / or proposal ID")
+	}
+	authority, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.requireEmissionAuthorityMSP(stub); err != nil {
+		return nil, err
+	}
+	proposal, err := cc.getEmissionProposal(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	tx := txContext(stub)
+	if err := proposal.Execute(authority, tx); err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, proposal.CustomerID, proposal.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	recordData, err := cc.applyEmission(stub, account, proposal.Record(account, authority, tx))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cc.putEmissionProposal(stub, proposal); err != nil {
+		return nil, err
+	}
+	return recordData, nil
+}
 
-// Update emission balance
-func (cc *Chaincode) Emission(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering Emission with args %v", args)
+// GetEmissionProposals query the emission proposals of a currency
+func (cc *Chaincode) GetEmissionProposals(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.EmissionProposalObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get emission proposals. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	list := model.EmissionProposalList{Proposals: []*model.EmissionProposal{}}
+	for keysIter.HasNext() {
+		proposalBytes := nextValue(keysIter)
+		proposal := new(model.EmissionProposal)
+		if err := json.Unmarshal(proposalBytes, proposal); err != nil {
+			loggerFor(stub).Errorf("Failed to get emission proposal details. Error: %s", err)
+			continue
+		}
+		list.Proposals = append(list.Proposals, proposal)
+	}
+	return json.Marshal(list)
+}
 
-	if len(args) != 3 {
-		return nil, errors.New("Missing required input arguments")
+// emit applies a mint or burn of the amount to an account and its currency
+// supply, unless the emission policy of the currency requires approval of the amount
+func (cc *Chaincode) emit(stub shim.ChaincodeStubInterface, op model.EmissionOperation, args []string) ([]byte, error) {
+	if len(args) < 3 || len(args) > 4 {
+		return nil, errors.New("Missing required customer ID, account ID and / or amount")
+	}
+	authority, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.requireEmissionAuthorityMSP(stub); err != nil {
+		return nil, err
 	}
+	amount, err := model.ParseAmount(args[2])
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	policy, err := cc.getEmissionPolicy(stub, account.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil && policy.Requires(amount) {
+		return nil, fmt.Errorf("Emission of %d %s above the threshold of %d requires approval, use ProposeEmission", amount, account.CurrencyCode, policy.Threshold)
+	}
+	record := model.CreateEmissionRecord(op, account, amount, optionalArg(args, 3), authority, txContext(stub))
+	return cc.applyEmission(stub, account, record)
+}
 
-	privateCollection, err := cc.GetCollection(stub, args[:2])
+// applyEmission applies the mint or burn of an emission record to its account
+// and currency supply, storing the record under the ID of the ledger transaction
+func (cc *Chaincode) applyEmission(stub shim.ChaincodeStubInterface, account *model.Account, record *model.EmissionRecord) ([]byte, error) {
+	op, amount := record.Operation, record.Amount
+	supply, err := cc.getSupply(stub, account.CurrencyCode)
 	if err != nil {
 		return nil, err
 	}
-	if privateCollection == nil {
-		return nil, fmt.Errorf("No access to gossip collection", args[2])
+	if err := supply.Apply(op, amount, txContext(stub)); err != nil {
+		return nil, err
 	}
-	transaction := new(model.Transactions)
-	bytesToStruct([]byte(args[1]), transaction)
-	amount, err := strconv.ParseInt(args[3], 10, 64)
+	if op == model.Mint {
+		reserve, err := cc.getReserve(stub, supply.CurrencyCode)
+		if err != nil {
+			return nil, err
+		}
+		if reserve != nil {
+			if err := reserve.Backs(supply.Total); err != nil {
+				return nil, err
+			}
+		}
+	}
+	record.SupplyAfter = supply.Total
+	key, _ := cc.createCompositeKey(stub, record.GetObjectType(), []string{record.CurrencyCode, record.TxID})
+	existing, err := stub.GetState(key)
 	if err != nil {
-		return nil, fmt.Errorf("Error parsing amount value %s", args[2])
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Emission record of transaction %s already exists", record.TxID)
 	}
-	transaction.Write(amount)
-	key, _ := cc.createCompositeKey(transaction.GetObjectType(), []string{transaction.Amount, privateCollection})
-	privateCollection, _ = json.Marshal(transaction)
-	stub.PutState(key, transaction)
 
-	return transaction, nil
+	t := record.Transfer()
+	if op == model.Mint {
+		if !account.CanReceive() {
+			return nil, fmt.Errorf("Cannot mint into closed account %s", account.ID)
+		}
+		if err := cc.creditAccount(stub, account, account.Money(amount)); err != nil {
+			return nil, err
+		}
+		if err := cc.recordTransaction(stub, account.CustomerID, account.ID, t, "", model.Credited); err != nil {
+			return nil, err
+		}
+	} else {
+		if account.Unheld() < amount {
+			return nil, fmt.Errorf("Insufficient funds available in account %s", account.ID)
+		}
+		if err := cc.debitAccount(stub, account, account.Money(amount)); err != nil {
+			return nil, err
+		}
+		if err := cc.recordTransaction(stub, account.CustomerID, account.ID, t, "", model.Debited); err != nil {
+			return nil, err
+		}
+	}
+	supplyData, _ := json.Marshal(supply)
+	supplyKey, _ := cc.createCompositeKey(stub, supply.GetObjectType(), []string{supply.CurrencyCode})
+	if err := stub.PutState(supplyKey, supplyData); err != nil {
+		return nil, err
+	}
+	recordData, _ := json.Marshal(record)
+	if err := stub.PutState(key, recordData); err != nil {
+		return nil, err
+	}
+	return recordData, nil
 }
 
-// Get last amount
-func (cc *Chaincode) GetEmissionAmount(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering with args %v", args)
-
-	if len(args) != 2 {
-		return nil, errors.New("Missing required arguments")
+// getSupply returns the supply of a currency, empty if nothing was minted yet
+func (cc *Chaincode) getSupply(stub shim.ChaincodeStubInterface, currency string) (*model.Supply, error) {
+	if err := model.ValidateCurrency(currency); err != nil {
+		return nil, err
 	}
+	key, _ := cc.createCompositeKey(stub, model.SupplyObjectType, []string{currency})
+	supplyBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get supply details. Error: %s", err)
+		return nil, err
+	}
+	supply := model.CreateSupply(currency)
+	if supplyBytes == nil {
+		return supply, nil
+	}
+	if err := bytesToStruct(supplyBytes, supply); err != nil {
+		return nil, err
+	}
+	return supply, nil
+}
 
-	privateCollection := args[0]
+// getEmissionPolicy returns the emission policy of a currency, nil if none is set
+func (cc *Chaincode) getEmissionPolicy(stub shim.ChaincodeStubInterface, currency string) (*model.EmissionPolicy, error) {
+	key, _ := cc.createCompositeKey(stub, model.EmissionPolicyObjectType, []string{currency})
+	policyBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get emission policy details. Error: %s", err)
+		return nil, err
+	}
+	if policyBytes == nil {
+		return nil, nil
+	}
+	policy := new(model.EmissionPolicy)
+	if err := bytesToStruct(policyBytes, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
 
-	// Query state using partial keys
-	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TransactionObjectType, []string{ privateCollection })
+func (cc *Chaincode) getEmissionProposal(stub shim.ChaincodeStubInterface, currency string, proposalID string) (*model.EmissionProposal, error) {
+	key, _ := cc.createCompositeKey(stub, model.EmissionProposalObjectType, []string{currency, proposalID})
+	proposalBytes, err := stub.GetState(key)
 	if err != nil {
-		logger.Errorf("Failed to get collection. Error: %s", err)
+		loggerFor(stub).Errorf("Failed to get emission proposal details. Error: %s", err)
 		return nil, err
 	}
-	tranList := model.TransactionList{}
-	for keysIter.HasNext() {
-		_, txnBytes, _ := keysIter.Next()
-		txn := new(model.Transaction)
-		if err := json.Unmarshal(txnBytes, txn); err != nil {
-			logger.Errorf("Failed to get collection. Error: %s", err)
-			continue
-		}
-		tranList.Transactions = append(tranList.Transactions, txn)
+	if proposalBytes == nil {
+		return nil, fmt.Errorf("Emission proposal %s not found.", proposalID)
+	}
+	proposal := new(model.EmissionProposal)
+	if err := bytesToStruct(proposalBytes, proposal); err != nil {
+		return nil, err
 	}
-	sort.Sort(sort.Reverse(model.ByCreated(tranList.Transactions)))
-	jsonList, _ := json.Marshal(tranList)
-	logger.Debugf("Returning transaction list: %s", jsonList)
-	return jsonList, nil
+	return proposal, nil
 }
 
+func (cc *Chaincode) putEmissionProposal(stub shim.ChaincodeStubInterface, proposal *model.EmissionProposal) ([]byte, error) {
+	proposalData, err := json.Marshal(proposal)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling emission proposal data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, proposal.GetObjectType(), []string{proposal.CurrencyCode, proposal.ID})
+	if err := stub.PutState(key, proposalData); err != nil {
+		return nil, err
+	}
+	return proposalData, nil
+}
+
+// requireEmissionAuthorityMSP fails unless the caller belongs to the emission
+// authority MSP of the deployment configuration, if one is configured
+func (cc *Chaincode) requireEmissionAuthorityMSP(stub shim.ChaincodeStubInterface) error {
+	config, err := cc.getConfig(stub)
+	if err != nil || config.EmissionAuthorityMSP == "" {
+		return err
+	}
+	mspID, err := callerMSPID(stub)
+	if err != nil {
+		return err
+	}
+	if mspID != config.EmissionAuthorityMSP {
+		return fmt.Errorf("Caller of MSP %s is not the emission authority", mspID)
+	}
+	return nil
+}
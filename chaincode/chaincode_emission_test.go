@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// supplyOf returns the total supply of a currency
+func supplyOf(t *testing.T, stub *testsupport.Stub, currency string) *model.Supply {
+	t.Helper()
+	supply := new(model.Supply)
+	if err := json.Unmarshal(stub.MustCall(t, "TotalSupply", currency), supply); err != nil {
+		t.Fatal(err)
+	}
+	return supply
+}
+
+func TestMintAndBurnTrackTheTotalSupply(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.As(testsupport.Operator(t, RoleEmissionAuthority))
+
+	stub.MustCall(t, "Mint", "1001", "1", "5000", "initial issuance")
+	stub.MustCall(t, "Burn", "1001", "1", "2000")
+	if _, err := stub.Call("Burn", "1001", "1", "4000"); err == nil || !strings.Contains(err.Error(), "Burn of 4000 exceeds AUD supply of 3000") {
+		t.Errorf("Expected a burn above the supply refused, got %v", err)
+	}
+	if supply := supplyOf(t, stub, "AUD"); supply.Total != 3000 || supply.Minted != 5000 || supply.Burned != 2000 {
+		t.Errorf("Expected the supply minted less burned, got %+v", supply)
+	}
+	if balance := balanceOf(t, stub, "1001", "1"); balance != 3000 {
+		t.Errorf("Expected the emissions applied to the account, got a balance of %d", balance)
+	}
+
+	list := new(model.EmissionRecordList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetEmissionRecords", "AUD"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Records) != 2 || list.Records[0].SupplyAfter+list.Records[1].SupplyAfter != 8000 {
+		t.Errorf("Expected a record of each emission with the supply after it, got %+v", list.Records)
+	}
+}
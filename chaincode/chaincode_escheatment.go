@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Escheatment handler functions
+//------------------------------
+
+// SetEscheatmentPolicy configures the dormancy period and unclaimed-property account of a currency
+func (cc *Chaincode) SetEscheatmentPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required escheatment policy data JSON")
+	}
+	policy, err := model.CreateEscheatmentPolicy([]byte(args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating escheatment policy. Error: %s", err)
+	}
+	unclaimed, err := cc.getAccountStruct(stub, policy.UnclaimedCustomerID, policy.UnclaimedAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if unclaimed.CurrencyCode != policy.CurrencyCode {
+		return nil, fmt.Errorf("Unclaimed-property account currency %s does not match %s", unclaimed.CurrencyCode, policy.CurrencyCode)
+	}
+	policyData, _ := json.Marshal(policy)
+	key, _ := cc.createCompositeKey(stub, policy.GetObjectType(), []string{policy.CurrencyCode})
+	if err := stub.PutState(key, policyData); err != nil {
+		return nil, err
+	}
+	return policyData, nil
+}
+
+// Escheat moves the balances of all accounts dormant beyond the statutory period
+// of their currency to its unclaimed-property account, keeping an audit record of each
+func (cc *Chaincode) Escheat(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	officer, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.AccountObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get account list. Error: %s", err)
+		return nil, err
+	}
+	var dormant []*model.Account
+	policies := make(map[string]*model.EscheatmentPolicy)
+	now := txContext(stub).Time
+	for keysIter.HasNext() {
+		accountBytes := nextValue(keysIter)
+		account := new(model.Account)
+		if err := json.Unmarshal(accountBytes, account); err != nil {
+			loggerFor(stub).Errorf("Failed to get account details. Error: %s", err)
+			continue
+		}
+		if account.Balance <= 0 || account.Held > 0 {
+			continue
+		}
+		policy, ok := policies[account.CurrencyCode]
+		if !ok {
+			if policy, err = cc.getEscheatmentPolicy(stub, account.CurrencyCode); err != nil {
+				return nil, err
+			}
+			policies[account.CurrencyCode] = policy
+		}
+		if policy != nil && !policy.IsUnclaimedAccount(account) && policy.Dormant(account, now) {
+			dormant = append(dormant, account)
+		}
+	}
+
+	list := model.EscheatmentList{Escheatments: []*model.Escheatment{}}
+	for _, account := range dormant {
+		policy := policies[account.CurrencyCode]
+		unclaimed, err := cc.getAccountStruct(stub, policy.UnclaimedCustomerID, policy.UnclaimedAccountID)
+		if err != nil {
+			return nil, err
+		}
+		record := model.CreateEscheatment(account, policy, stub.GetTxID(), officer, now)
+		t := record.Transfer(false)
+		account.Status = model.AccountDormant
+		if err := cc.debitAccount(stub, account, account.Money(record.Amount)); err != nil {
+			return nil, err
+		}
+		if err := cc.recordTransaction(stub, account.CustomerID, account.ID, t, "", model.Debited); err != nil {
+			return nil, err
+		}
+		if err := cc.creditAccount(stub, unclaimed, unclaimed.Money(record.Amount)); err != nil {
+			return nil, err
+		}
+		if err := cc.recordTransaction(stub, unclaimed.CustomerID, unclaimed.ID, t, "", model.Credited); err != nil {
+			return nil, err
+		}
+		if err := emitAccountEvent(stub, model.EventAccountDormant, account); err != nil {
+			return nil, err
+		}
+		if _, err := cc.putEscheatment(stub, record); err != nil {
+			return nil, err
+		}
+		list.Escheatments = append(list.Escheatments, record)
+	}
+	return json.Marshal(list)
+}
+
+// ReclaimEscheated returns an escheated balance from the unclaimed-property
+// account to the customer's original account
+func (cc *Chaincode) ReclaimEscheated(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, account ID and / or escheatment ID")
+	}
+	record, err := cc.getEscheatment(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != model.Escheated {
+		return nil, fmt.Errorf("Escheatment %s is %s", record.ID, record.Status)
+	}
+	account, err := cc.getAccountStruct(stub, record.CustomerID, record.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.CanReceive() {
+		return nil, fmt.Errorf("Cannot reclaim into closed account %s", account.ID)
+	}
+	unclaimed, err := cc.getAccountStruct(stub, record.UnclaimedCustomerID, record.UnclaimedAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if unclaimed.Balance < record.Amount {
+		return nil, fmt.Errorf("Insufficient funds available in account %s", unclaimed.ID)
+	}
+	t := record.Transfer(true)
+	if err := cc.debitAccount(stub, unclaimed, unclaimed.Money(record.Amount)); err != nil {
+		return nil, err
+	}
+	if err := cc.recordTransaction(stub, unclaimed.CustomerID, unclaimed.ID, t, "", model.Debited); err != nil {
+		return nil, err
+	}
+	reactivated := account.Status == model.AccountDormant
+	if reactivated {
+		account.Status = model.AccountActive
+	}
+	if err := cc.creditAccount(stub, account, account.Money(record.Amount)); err != nil {
+		return nil, err
+	}
+	if reactivated {
+		if err := emitAccountEvent(stub, model.EventAccountUnfrozen, account); err != nil {
+			return nil, err
+		}
+	}
+	if err := cc.recordTransaction(stub, account.CustomerID, account.ID, t, "", model.Credited); err != nil {
+		return nil, err
+	}
+	record.Status = model.EscheatReclaimed
+	record.Reclaimed = txContext(stub).Time.Unix()
+	record.ReclaimTxID = stub.GetTxID()
+	return cc.putEscheatment(stub, record)
+}
+
+// GetEscheatments query the escheatment records of a customer, optionally for a single account
+func (cc *Chaincode) GetEscheatments(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, errors.New("Missing required customer ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.EscheatmentObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get escheatments. Error: %s", err)
+		return nil, err
+	}
+	list := model.EscheatmentList{Escheatments: []*model.Escheatment{}}
+	for keysIter.HasNext() {
+		recordBytes := nextValue(keysIter)
+		record := new(model.Escheatment)
+		if err := json.Unmarshal(recordBytes, record); err != nil {
+			loggerFor(stub).Errorf("Failed to get escheatment details. Error: %s", err)
+			continue
+		}
+		list.Escheatments = append(list.Escheatments, record)
+	}
+	return json.Marshal(list)
+}
+
+func (cc *Chaincode) getEscheatmentPolicy(stub shim.ChaincodeStubInterface, currency string) (*model.EscheatmentPolicy, error) {
+	key, _ := cc.createCompositeKey(stub, model.EscheatmentPolicyObjectType, []string{currency})
+	policyBytes, err := stub.GetState(key)
+	if err != nil || policyBytes == nil {
+		return nil, err
+	}
+	policy := new(model.EscheatmentPolicy)
+	if err := bytesToStruct(policyBytes, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (cc *Chaincode) getEscheatment(stub shim.ChaincodeStubInterface, customerID string, accountID string, recordID string) (*model.Escheatment, error) {
+	key, _ := cc.createCompositeKey(stub, model.EscheatmentObjectType, []string{customerID, accountID, recordID})
+	recordBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if recordBytes == nil {
+		return nil, fmt.Errorf("Escheatment %s not found.", recordID)
+	}
+	record := new(model.Escheatment)
+	if err := bytesToStruct(recordBytes, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (cc *Chaincode) putEscheatment(stub shim.ChaincodeStubInterface, record *model.Escheatment) ([]byte, error) {
+	recordData, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling escheatment data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, record.GetObjectType(), []string{record.CustomerID, record.AccountID, record.ID})
+	if err := stub.PutState(key, recordData); err != nil {
+		return nil, err
+	}
+	return recordData, nil
+}
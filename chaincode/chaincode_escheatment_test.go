@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestEscheatMovesOnlyDormantBalancesAndReclaimReturnsThem(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("9000", "1"))
+	stub.Topup(t, "1001", "1", 2000)
+	stub.Advance(200 * 24 * time.Hour)
+	stub.Topup(t, "1002", "1", 500)
+	stub.Advance(200 * 24 * time.Hour)
+
+	stub.As(testsupport.Operator(t, RoleEscheatmentOfficer))
+	stub.MustCall(t, "SetEscheatmentPolicy", `{"currency":"AUD","dormancy_days":365,"unclaimed_customer":"9000","unclaimed_account":"1"}`)
+	list := new(model.EscheatmentList)
+	if err := json.Unmarshal(stub.MustCall(t, "Escheat"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Escheatments) != 1 || list.Escheatments[0].CustomerID != "1001" || list.Escheatments[0].Amount != 2000 {
+		t.Fatalf("Expected only the balance dormant for a year escheated, got %+v", list.Escheatments)
+	}
+	if dormant, active, unclaimed := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"), balanceOf(t, stub, "9000", "1"); dormant != 0 || active != 500 || unclaimed != 2000 {
+		t.Errorf("Expected the dormant balance moved to unclaimed property, got balances of %d, %d and %d", dormant, active, unclaimed)
+	}
+	if _, err := stub.As(testsupport.Customer(t, "1001")).Call("TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 100).JSON()); err == nil {
+		t.Error("Expected the dormant account unable to send")
+	}
+
+	stub.As(testsupport.Operator(t, RoleEscheatmentOfficer))
+	record := list.Escheatments[0]
+	stub.MustCall(t, "ReclaimEscheated", "1001", "1", record.ID)
+	if _, err := stub.Call("ReclaimEscheated", "1001", "1", record.ID); err == nil || !strings.Contains(err.Error(), "is reclaimed") {
+		t.Errorf("Expected the balance not reclaimed twice, got %v", err)
+	}
+	if reclaimed, unclaimed := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "9000", "1"); reclaimed != 2000 || unclaimed != 0 {
+		t.Errorf("Expected the balance returned to the customer, got balances of %d and %d", reclaimed, unclaimed)
+	}
+	stub.As(testsupport.Customer(t, "1001")).MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 100).JSON())
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Exchange rate handler functions
+//------------------------------
+
+// SetExchangeRate sets the rate used to convert a base currency into a quote
+// currency. Restricted to rate administrators.
+func (cc *Chaincode) SetExchangeRate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required exchange rate data JSON")
+	}
+	publisher, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := model.CreateExchangeRate([]byte(args[0]), publisher, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating exchange rate. Error: %s", err)
+	}
+	rateData, _ := json.Marshal(rate)
+	key, _ := cc.createCompositeKey(stub, rate.GetObjectType(), []string{rate.Base, rate.Quote})
+	if err := stub.PutState(key, rateData); err != nil {
+		return nil, err
+	}
+	return rateData, nil
+}
+
+// PostRate records an exchange rate signed by a rate oracle and makes it the
+// current rate of its currency pair. The signature is verified against the
+// invoking certificate and a rate older than the current one is rejected.
+// Restricted to rate oracles.
+func (cc *Chaincode) PostRate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required rate attestation data JSON")
+	}
+	attestation, err := model.CreateRateAttestation([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating rate attestation. Error: %s", err)
+	}
+	if err := verifyCallerSignature(stub, attestation.SignedMessage(), attestation.Signature); err != nil {
+		return nil, err
+	}
+	if attestation.Oracle, err = callerID(stub); err != nil {
+		return nil, err
+	}
+	if attestation.OracleMSP, err = callerMSPID(stub); err != nil {
+		return nil, err
+	}
+	current, err := cc.getExchangeRate(stub, attestation.Base, attestation.Quote)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.Updated > attestation.Timestamp {
+		return nil, fmt.Errorf("Rate of %s to %s as of %d is older than the current rate", attestation.Base, attestation.Quote, attestation.Timestamp)
+	}
+	attestationData, _ := json.Marshal(attestation)
+	key, _ := cc.createCompositeKey(stub, attestation.GetObjectType(), []string{attestation.Base, attestation.Quote, attestation.ID})
+	if err := stub.PutState(key, attestationData); err != nil {
+		return nil, err
+	}
+	rate := attestation.ExchangeRate()
+	rateData, _ := json.Marshal(rate)
+	key, _ = cc.createCompositeKey(stub, rate.GetObjectType(), []string{rate.Base, rate.Quote})
+	if err := stub.PutState(key, rateData); err != nil {
+		return nil, err
+	}
+	return attestationData, nil
+}
+
+// GetRateAttestation query an oracle's attestation of a rate by currency pair and ID
+func (cc *Chaincode) GetRateAttestation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required base currency, quote currency and / or attestation ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.RateAttestationObjectType, args)
+	return stub.GetState(key)
+}
+
+// SetRateConfig sets the maximum age of the rates transfers may be converted
+// at. Restricted to rate administrators.
+func (cc *Chaincode) SetRateConfig(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required rate config data JSON")
+	}
+	setBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	config, err := model.CreateRateConfig([]byte(args[0]), setBy, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating rate config. Error: %s", err)
+	}
+	configData, _ := json.Marshal(config)
+	key, _ := cc.createCompositeKey(stub, config.GetObjectType(), []string{})
+	if err := stub.PutState(key, configData); err != nil {
+		return nil, err
+	}
+	return configData, nil
+}
+
+// GetExchangeRate query the exchange rate of a base and quote currency
+func (cc *Chaincode) GetExchangeRate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required base and / or quote currency")
+	}
+	key, _ := cc.createCompositeKey(stub, model.ExchangeRateObjectType, args)
+	return stub.GetState(key)
+}
+
+// convertTransfer converts the amount credited by a transfer into the payee
+// account currency at the stored rate, or the rate locked by its quote, and
+// records the conversion, with the rate attestation it used, on the transfer.
+func (cc *Chaincode) convertTransfer(stub shim.ChaincodeStubInterface, t *model.Transfer, currency string, amount int64) (int64, error) {
+	if t.Quote != nil {
+		t.Conversion = t.Quote.Convert(amount)
+		return t.Conversion.ConvertedAmount, nil
+	}
+	rate, err := cc.currentExchangeRate(stub, t.CurrencyCode, currency)
+	if err != nil {
+		return 0, err
+	}
+	t.Conversion = rate.Convert(amount)
+	return t.Conversion.ConvertedAmount, nil
+}
+
+// currentExchangeRate returns the rate of a currency pair. Missing rates and
+// rates older than the configured maximum age are rejected.
+func (cc *Chaincode) currentExchangeRate(stub shim.ChaincodeStubInterface, base string, quote string) (*model.ExchangeRate, error) {
+	rate, err := cc.getExchangeRate(stub, base, quote)
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil {
+		return nil, fmt.Errorf("No exchange rate set for %s to %s", base, quote)
+	}
+	key, _ := cc.createCompositeKey(stub, model.RateConfigObjectType, []string{})
+	configBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get rate config details. Error: %s", err)
+		return nil, err
+	}
+	if configBytes != nil {
+		config := new(model.RateConfig)
+		if err := bytesToStruct(configBytes, config); err != nil {
+			return nil, err
+		}
+		if config.IsStale(rate, txContext(stub)) {
+			return nil, fmt.Errorf("Exchange rate of %s to %s is older than %d seconds", base, quote, config.MaxAge)
+		}
+	}
+	return rate, nil
+}
+
+// getExchangeRate returns the current rate of a currency pair, or nil if none is set
+func (cc *Chaincode) getExchangeRate(stub shim.ChaincodeStubInterface, base string, quote string) (*model.ExchangeRate, error) {
+	key, _ := cc.createCompositeKey(stub, model.ExchangeRateObjectType, []string{base, quote})
+	rateBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get exchange rate details. Error: %s", err)
+		return nil, err
+	}
+	if rateBytes == nil {
+		return nil, nil
+	}
+	rate := new(model.ExchangeRate)
+	if err := bytesToStruct(rateBytes, rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestCrossCurrencyTransfersCreditTheConvertedAmount(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1").InCurrency("JPY"))
+	stub.Topup(t, "1001", "1", 5000)
+	payer := testsupport.Customer(t, "1001")
+	transfer := testsupport.NewTransfer("1001", "1", "1002", "1", 1001).JSON()
+
+	if _, err := stub.As(payer).Call("TransferMoney", transfer); err == nil || !strings.Contains(err.Error(), "No exchange rate set for AUD to JPY") {
+		t.Errorf("Expected the transfer refused without a rate, got %v", err)
+	}
+	stub.As(testsupport.Operator(t, RoleRateAdmin)).MustCall(t, "SetExchangeRate", `{"base":"AUD","quote":"JPY","rate":97500000}`)
+	stub.As(payer).MustCall(t, "TransferMoney", transfer)
+
+	// 10.01 AUD at 97.5 is 975.975 JPY, rounded half up to whole yen
+	if payer, payee := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); payer != 3999 || payee != 976 {
+		t.Errorf("Expected the amount debited in AUD and credited in JPY, got balances of %d and %d", payer, payee)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// State export and import functions
+//------------------------------
+
+// ExportState query one page of all records of an object type in a versioned,
+// hash-verified format. Pass the next_bookmark of a page to get the following page.
+func (cc *Chaincode) ExportState(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, errors.New("Missing required object type")
+	}
+	pageSize := model.MaxExportPageSize
+	if size := optionalArg(args, 2); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 || n > model.MaxExportPageSize {
+			return nil, fmt.Errorf("Invalid page size %s", size)
+		}
+		pageSize = n
+	}
+	prefix, err := cc.createCompositeKey(stub, args[0], []string{})
+	if err != nil {
+		return nil, err
+	}
+	page := &model.StateExportPage{
+		Version:    model.ExportFormatVersion,
+		ObjectType: args[0],
+		Bookmark:   optionalArg(args, 1),
+		Records:    []*model.StateRecord{},
+	}
+	start := prefix
+	if page.Bookmark != "" {
+		// the range start is inclusive, so start just after the bookmark
+		start = page.Bookmark + "\x00"
+	}
+	keysIter, err := compositeKeyRange(stub, prefix, start, prefix+string(utf8.MaxRune))
+	if err != nil {
+		return nil, err
+	}
+	defer keysIter.Close()
+	for keysIter.HasNext() {
+		key, value, err := nextKeyValue(keysIter)
+		if err != nil {
+			return nil, err
+		}
+		if len(page.Records) == pageSize {
+			page.NextBookmark = page.Records[pageSize-1].Key
+			break
+		}
+		page.Records = append(page.Records, &model.StateRecord{Key: key, Value: value})
+	}
+	page.Seal()
+	return json.Marshal(page)
+}
+
+// ImportState writes exported pages into a freshly deployed chaincode.
+// Restricted to network operators. It refuses to overwrite existing keys so
+// that state of a live channel cannot be replaced. Init imports the pages
+// passed with the configuration; ImportState imports the pages that do not
+// fit in that one proposal.
+func (cc *Chaincode) ImportState(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required export pages JSON")
+	}
+	imported, err := cc.importPages(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strconv.Itoa(imported)), nil
+}
+
+// importPages verifies the export pages and writes their records, returning
+// the number of records written
+func (cc *Chaincode) importPages(stub shim.ChaincodeStubInterface, pages []string) (int, error) {
+	imported := 0
+	for i, arg := range pages {
+		page := new(model.StateExportPage)
+		if err := json.Unmarshal([]byte(arg), page); err != nil {
+			return 0, fmt.Errorf("Error parsing export page %d. Error: %s", i, err)
+		}
+		prefix, err := cc.createCompositeKey(stub, page.ObjectType, []string{})
+		if err != nil {
+			return 0, err
+		}
+		if page.Version == model.LegacyExportFormatVersion {
+			prefix = legacyCompositeKey(page.ObjectType, []string{})
+		}
+		if err := page.Verify(prefix); err != nil {
+			return 0, fmt.Errorf("Export page %d failed verification. Error: %s", i, err)
+		}
+		for _, r := range page.Records {
+			existing, err := stub.GetState(r.Key)
+			if err != nil {
+				return 0, err
+			}
+			if existing != nil {
+				return 0, fmt.Errorf("Key %s of export page %d already exists", r.Key, i)
+			}
+			if err := stub.PutState(r.Key, r.Value); err != nil {
+				return 0, err
+			}
+		}
+		imported += len(page.Records)
+	}
+	loggerFor(stub).Infof("Imported %d records from %d export pages", imported, len(pages))
+	return imported, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Fee schedule handler functions
+//------------------------------
+
+// SetFeeSchedule sets the fees charged on transfers in a currency along a
+// corridor, replacing any existing schedule. A schedule without a collection
+// account collects into the fee account of the deployment configuration.
+// Restricted to fee administrators.
+func (cc *Chaincode) SetFeeSchedule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required fee schedule data JSON")
+	}
+	scheduleBytes := []byte(args[0])
+	requested := new(model.FeeSchedule)
+	if err := json.Unmarshal(scheduleBytes, requested); err == nil && requested.CollectionAccountID == "" {
+		config, err := cc.getConfig(stub)
+		if err != nil {
+			return nil, err
+		}
+		requested.CollectionCustomerID, requested.CollectionAccountID = config.FeeCustomerID, config.FeeAccountID
+		scheduleBytes, _ = json.Marshal(requested)
+	}
+	schedule, err := model.CreateFeeSchedule(scheduleBytes, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating fee schedule. Error: %s", err)
+	}
+	collector, err := cc.getAccountStruct(stub, schedule.CollectionCustomerID, schedule.CollectionAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if collector.CurrencyCode != schedule.CurrencyCode {
+		return nil, fmt.Errorf("Fee collection account currency %s does not match %s", collector.CurrencyCode, schedule.CurrencyCode)
+	}
+	scheduleData, _ := json.Marshal(schedule)
+	key, _ := cc.createCompositeKey(stub, schedule.GetObjectType(), []string{schedule.CurrencyCode, schedule.FromCountry, schedule.ToCountry})
+	if err := stub.PutState(key, scheduleData); err != nil {
+		return nil, err
+	}
+	return scheduleData, nil
+}
+
+// GetFeeSchedule query the fee schedule applied to transfers in a currency,
+// optionally along a corridor of payer and payee countries
+func (cc *Chaincode) GetFeeSchedule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 && len(args) != 3 {
+		return nil, errors.New("Missing required currency and / or corridor countries")
+	}
+	fromCountry, toCountry := model.AnyCountry, model.AnyCountry
+	if len(args) == 3 {
+		fromCountry, toCountry = args[1], args[2]
+	}
+	schedule, err := cc.feeScheduleFor(stub, args[0], fromCountry, toCountry)
+	if err != nil || schedule == nil {
+		return nil, err
+	}
+	return json.Marshal(schedule)
+}
+
+// feeScheduleFor returns the most specific fee schedule of a corridor, falling
+// back to schedules for any payer or payee country, or nil if none applies
+func (cc *Chaincode) feeScheduleFor(stub shim.ChaincodeStubInterface, currency string, fromCountry string, toCountry string) (*model.FeeSchedule, error) {
+	candidates := [][]string{
+		{currency, fromCountry, toCountry},
+		{currency, fromCountry, model.AnyCountry},
+		{currency, model.AnyCountry, toCountry},
+		{currency, model.AnyCountry, model.AnyCountry},
+	}
+	for _, attrs := range candidates {
+		key, _ := cc.createCompositeKey(stub, model.FeeScheduleObjectType, attrs)
+		scheduleBytes, err := stub.GetState(key)
+		if err != nil {
+			loggerFor(stub).Errorf("Failed to get fee schedule details. Error: %s", err)
+			return nil, err
+		}
+		if scheduleBytes == nil {
+			continue
+		}
+		schedule := new(model.FeeSchedule)
+		if err := bytesToStruct(scheduleBytes, schedule); err != nil {
+			return nil, err
+		}
+		return schedule, nil
+	}
+	return nil, nil
+}
+
+// transferFeeSchedule returns the fee schedule of the payer's product or else
+// of the corridor between the accounts, or nil if none applies
+func (cc *Chaincode) transferFeeSchedule(stub shim.ChaincodeStubInterface, from *model.Account, to *model.Account, currency string) (*model.FeeSchedule, error) {
+	schedule, err := cc.productFeeSchedule(stub, from, currency)
+	if err != nil || schedule != nil {
+		return schedule, err
+	}
+	return cc.feeScheduleFor(stub, currency, from.CountryCode, to.CountryCode)
+}
+
+// collectFee credits the fee of a transfer to the collection account of its
+// fee schedule. The account is read only now so that it observes the debit
+// and credit of the transfer should it be one of the transfer's accounts.
+func (cc *Chaincode) collectFee(stub shim.ChaincodeStubInterface, schedule *model.FeeSchedule, from *model.Account, t *model.Transfer) error {
+	collector, err := cc.getAccountStruct(stub, schedule.CollectionCustomerID, schedule.CollectionAccountID)
+	if err != nil {
+		return err
+	}
+	if !collector.CanReceive() || collector.CurrencyCode != t.CurrencyCode {
+		return fmt.Errorf("Fee collection account %s cannot be credited in %s", collector.ID, t.CurrencyCode)
+	}
+	fee := &model.Transfer{
+		FromCustomerID: from.CustomerID,
+		FromAccountID:  from.ID,
+		ToCustomerID:   collector.CustomerID,
+		ToAccountID:    collector.ID,
+		Amount:         t.Fee,
+		CurrencyCode:   t.CurrencyCode,
+		Description:    "Transfer fee",
+		Params:         map[string]string{"initiated_by": "system", "fee_type": string(schedule.Type)},
+	}
+	if err := cc.creditAccount(stub, collector, collector.Money(t.Fee)); err != nil {
+		return err
+	}
+	return cc.recordTransaction(stub, collector.CustomerID, collector.ID, fee, "", model.Credited)
+}
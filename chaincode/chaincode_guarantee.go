@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Bank guarantee handler functions
+//------------------------------
+
+// IssueGuarantee issues a bank guarantee in favour of a beneficiary
+func (cc *Chaincode) IssueGuarantee(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required guarantee data JSON")
+	}
+	guarantee, err := model.CreateGuarantee([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating guarantee. Error: %s", err)
+	}
+	if guarantee.Expired(txContext(stub).Time) {
+		return nil, fmt.Errorf("Guarantee expiry %s is in the past", guarantee.Expiry)
+	}
+	parties := [][]string{
+		{guarantee.IssuerCustomerID, guarantee.IssuerAccountID},
+		{guarantee.ApplicantCustomerID, guarantee.ApplicantAccountID},
+		{guarantee.BeneficiaryCustomerID, guarantee.BeneficiaryAccountID},
+	}
+	for _, party := range parties {
+		account, err := cc.getAccountStruct(stub, party[0], party[1])
+		if err != nil {
+			return nil, err
+		}
+		if account.CurrencyCode != guarantee.CurrencyCode {
+			return nil, fmt.Errorf("Account %s currency %s does not match guarantee currency %s", account.ID, account.CurrencyCode, guarantee.CurrencyCode)
+		}
+		// claims are paid from the applicant account first
+		if account.CustomerID == guarantee.ApplicantCustomerID && account.ID == guarantee.ApplicantAccountID {
+			if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+				return nil, err
+			}
+		}
+	}
+	existing, err := cc.getGuarantee(stub, guarantee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Guarantee %s already exists", guarantee.ID)
+	}
+	return cc.putGuarantee(stub, guarantee)
+}
+
+// ClaimGuarantee pays the beneficiary up to the remaining guaranteed amount,
+// from the applicant account first and from the issuing bank for any shortfall
+func (cc *Chaincode) ClaimGuarantee(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required guarantee ID and / or claim amount")
+	}
+	guarantee, err := cc.mustGetGuarantee(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	amount, err := model.ParseAmount(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if guarantee.Status != model.GuaranteeIssued {
+		return nil, fmt.Errorf("Guarantee %s is %s", guarantee.ID, guarantee.Status)
+	}
+	if guarantee.Expired(txContext(stub).Time) {
+		return nil, fmt.Errorf("Guarantee %s expired on %s", guarantee.ID, guarantee.Expiry)
+	}
+	if amount > guarantee.Remaining() {
+		return nil, fmt.Errorf("Claim of %d exceeds remaining guaranteed amount %d", amount, guarantee.Remaining())
+	}
+	applicant, err := cc.getAccountStruct(stub, guarantee.ApplicantCustomerID, guarantee.ApplicantAccountID)
+	if err != nil {
+		return nil, err
+	}
+	issuer, err := cc.getAccountStruct(stub, guarantee.IssuerCustomerID, guarantee.IssuerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	beneficiary, err := cc.getAccountStruct(stub, guarantee.BeneficiaryCustomerID, guarantee.BeneficiaryAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.RequestPayment, beneficiary); err != nil {
+		return nil, err
+	}
+	if !beneficiary.CanReceive() {
+		return nil, fmt.Errorf("Cannot transfer money into closed account %s", beneficiary.ID)
+	}
+
+	claim := &model.GuaranteeClaim{Amount: amount, TxID: stub.GetTxID(), Claimed: txContext(stub).Time.Unix()}
+	if applicant.CanSend() && applicant.Unheld() > 0 {
+		claim.FromApplicant = amount
+		if applicant.Unheld() < amount {
+			claim.FromApplicant = applicant.Unheld()
+		}
+	}
+	claim.FromIssuer = amount - claim.FromApplicant
+	if issuer.Unheld()-claim.FromIssuer < 0 {
+		return nil, fmt.Errorf("Insufficient funds available in issuing bank account %s", issuer.ID)
+	}
+	if claim.FromApplicant > 0 {
+		if err := cc.payGuarantee(stub, guarantee, applicant, beneficiary, claim.FromApplicant); err != nil {
+			return nil, err
+		}
+	}
+	if claim.FromIssuer > 0 {
+		if err := cc.payGuarantee(stub, guarantee, issuer, beneficiary, claim.FromIssuer); err != nil {
+			return nil, err
+		}
+	}
+	guarantee.Claim(claim)
+	return cc.putGuarantee(stub, guarantee)
+}
+
+// ExpireGuarantee marks a guarantee past its expiry date as expired
+func (cc *Chaincode) ExpireGuarantee(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required guarantee ID")
+	}
+	guarantee, err := cc.mustGetGuarantee(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if guarantee.Status != model.GuaranteeIssued {
+		return nil, fmt.Errorf("Guarantee %s is %s", guarantee.ID, guarantee.Status)
+	}
+	if !guarantee.Expired(txContext(stub).Time) {
+		return nil, fmt.Errorf("Guarantee %s does not expire before %s", guarantee.ID, guarantee.Expiry)
+	}
+	guarantee.Status = model.GuaranteeExpired
+	return cc.putGuarantee(stub, guarantee)
+}
+
+// GetGuarantee query a guarantee by ID
+func (cc *Chaincode) GetGuarantee(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required guarantee ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.GuaranteeObjectType, args)
+	return stub.GetState(key)
+}
+
+func (cc *Chaincode) payGuarantee(stub shim.ChaincodeStubInterface, g *model.Guarantee, from *model.Account, to *model.Account, amount int64) error {
+	t := &model.Transfer{
+		FromCustomerID: from.CustomerID,
+		FromAccountID:  from.ID,
+		ToCustomerID:   to.CustomerID,
+		ToAccountID:    to.ID,
+		Amount:         amount,
+		CurrencyCode:   g.CurrencyCode,
+		Description:    "Guarantee claim",
+		Params:         map[string]string{"guarantee": g.ID},
+	}
+	if err := cc.debitAccount(stub, from, from.Money(amount)); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, from.CustomerID, from.ID, t, "", model.Debited); err != nil {
+		return err
+	}
+	if err := cc.creditAccount(stub, to, to.Money(amount)); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, to.CustomerID, to.ID, t, "", model.Credited); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cc *Chaincode) getGuarantee(stub shim.ChaincodeStubInterface, guaranteeID string) (*model.Guarantee, error) {
+	key, _ := cc.createCompositeKey(stub, model.GuaranteeObjectType, []string{guaranteeID})
+	guaranteeBytes, err := stub.GetState(key)
+	if err != nil || guaranteeBytes == nil {
+		return nil, err
+	}
+	guarantee := new(model.Guarantee)
+	if err := bytesToStruct(guaranteeBytes, guarantee); err != nil {
+		return nil, err
+	}
+	return guarantee, nil
+}
+
+func (cc *Chaincode) mustGetGuarantee(stub shim.ChaincodeStubInterface, guaranteeID string) (*model.Guarantee, error) {
+	guarantee, err := cc.getGuarantee(stub, guaranteeID)
+	if err != nil {
+		return nil, err
+	}
+	if guarantee == nil {
+		return nil, fmt.Errorf("Guarantee %s not found.", guaranteeID)
+	}
+	return guarantee, nil
+}
+
+func (cc *Chaincode) putGuarantee(stub shim.ChaincodeStubInterface, guarantee *model.Guarantee) ([]byte, error) {
+	guaranteeData, err := json.Marshal(guarantee)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling guarantee data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, guarantee.GetObjectType(), []string{guarantee.ID})
+	if err := stub.PutState(key, guaranteeData); err != nil {
+		return nil, err
+	}
+	return guaranteeData, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// guaranteeJSON returns a guarantee issued by customer 9000 for customer 1001 in favour of customer 1002
+func guaranteeJSON(id string, amount string, expiry string) string {
+	return `{"id":"` + id + `","issuing_bank":"bank1","issuer_customer":"9000","issuer_account":"1","applicant_customer":"1001","applicant_account":"1","beneficiary_customer":"1002","beneficiary_account":"1","amount":` + amount + `,"currency":"AUD","expiry":"` + expiry + `","terms_hash":"` + testTermsHash + `"}`
+}
+
+func TestGuaranteeClaimsPayFromApplicantThenIssuer(t *testing.T) {
+	stub := newTestStub()
+	for _, customerID := range []string{"9000", "1001", "1002"} {
+		stub.OpenAccount(t, testsupport.NewAccount(customerID, "1"))
+	}
+	stub.Topup(t, "9000", "1", 100000)
+	stub.Topup(t, "1001", "1", 3000)
+	stub.As(testsupport.Operator(t, RoleAccountOperator)).MustCall(t, "IssueGuarantee", guaranteeJSON("g1", "10000", "2021-03-05"))
+
+	stub.As(testsupport.Customer(t, "1002"))
+	guarantee := new(model.Guarantee)
+	if err := json.Unmarshal(stub.MustCall(t, "ClaimGuarantee", "g1", "4000"), guarantee); err != nil {
+		t.Fatal(err)
+	}
+	if claim := guarantee.Claims[0]; claim.FromApplicant != 3000 || claim.FromIssuer != 1000 || guarantee.Status != model.GuaranteeIssued {
+		t.Errorf("Expected the applicant's funds claimed before the issuer's, got %+v", claim)
+	}
+	if _, err := stub.Call("ClaimGuarantee", "g1", "6001"); err == nil || !strings.Contains(err.Error(), "exceeds remaining guaranteed amount 6000") {
+		t.Errorf("Expected a claim beyond the remaining amount refused, got %v", err)
+	}
+	if err := json.Unmarshal(stub.MustCall(t, "ClaimGuarantee", "g1", "6000"), guarantee); err != nil {
+		t.Fatal(err)
+	}
+	if guarantee.Status != model.GuaranteeClaimed || guarantee.Claims[1].FromIssuer != 6000 {
+		t.Errorf("Expected the guarantee claimed in full from the issuer, got %+v", guarantee)
+	}
+	for customerID, expected := range map[string]int64{"9000": 93000, "1001": 0, "1002": 10000} {
+		if balance := balanceOf(t, stub, customerID, "1"); balance != expected {
+			t.Errorf("Expected customer %s at %d, got %d", customerID, expected, balance)
+		}
+	}
+}
+
+func TestGuaranteeExpires(t *testing.T) {
+	stub := newTestStub()
+	for _, customerID := range []string{"9000", "1001", "1002"} {
+		stub.OpenAccount(t, testsupport.NewAccount(customerID, "1"))
+	}
+	stub.Topup(t, "9000", "1", 100000)
+	stub.As(testsupport.Operator(t, RoleAccountOperator))
+	if _, err := stub.Call("IssueGuarantee", guaranteeJSON("g1", "5000", "2021-02-28")); err == nil || !strings.Contains(err.Error(), "is in the past") {
+		t.Errorf("Expected an expired guarantee refused, got %v", err)
+	}
+	stub.MustCall(t, "IssueGuarantee", guaranteeJSON("g1", "5000", "2021-03-01"))
+	if _, err := stub.Call("ExpireGuarantee", "g1"); err == nil || !strings.Contains(err.Error(), "does not expire before") {
+		t.Errorf("Expected the guarantee claimable on its expiry date, got %v", err)
+	}
+
+	stub.Advance(24 * time.Hour)
+	if _, err := stub.As(testsupport.Customer(t, "1002")).Call("ClaimGuarantee", "g1", "5000"); err == nil || !strings.Contains(err.Error(), "expired on 2021-03-01") {
+		t.Errorf("Expected a claim after expiry refused, got %v", err)
+	}
+	guarantee := new(model.Guarantee)
+	if err := json.Unmarshal(stub.MustCall(t, "ExpireGuarantee", "g1"), guarantee); err != nil {
+		t.Fatal(err)
+	}
+	if guarantee.Status != model.GuaranteeExpired || balanceOf(t, stub, "1002", "1") != 0 {
+		t.Errorf("Expected the guarantee expired unpaid, got %+v", guarantee)
+	}
+}
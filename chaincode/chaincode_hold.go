@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Balance hold handler functions
+//------------------------------
+
+// PlaceHold reserves part of an account balance without moving money. The held
+// amount is excluded from the available balance until the hold is released.
+// Restricted to tellers and account operators.
+func (cc *Chaincode) PlaceHold(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 {
+		return nil, errors.New("Missing required customer ID, account ID, amount and / or reference")
+	}
+	amount, err := model.ParseAmount(args[2])
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if !account.CanSend() {
+		return nil, fmt.Errorf("Cannot place hold on %s account %s", account.Status, account.ID)
+	}
+	placedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	hold, err := model.CreateHold(stub.GetTxID(), account, amount, args[3], placedBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	if account.Available() < amount {
+		return nil, fmt.Errorf("Insufficient funds available in account %s", account.ID)
+	}
+	if err := account.Hold(account.Money(amount)); err != nil {
+		return nil, err
+	}
+	if _, err := cc.putAccount(stub, account); err != nil {
+		return nil, err
+	}
+	return cc.putHold(stub, hold)
+}
+
+// ReleaseHold releases an active hold, returning its amount to the available
+// balance. Restricted to tellers and account operators.
+func (cc *Chaincode) ReleaseHold(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, account ID and / or hold ID")
+	}
+	hold, err := cc.mustGetHold(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != model.HoldActive {
+		return nil, fmt.Errorf("Hold %s is %s", hold.ID, hold.Status)
+	}
+	releasedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, hold.CustomerID, hold.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := account.Unhold(account.Money(hold.Amount)); err != nil {
+		return nil, err
+	}
+	if _, err := cc.putAccount(stub, account); err != nil {
+		return nil, err
+	}
+	hold.Release(releasedBy, txContext(stub))
+	return cc.putHold(stub, hold)
+}
+
+// GetHolds query the active holds of an account
+func (cc *Chaincode) GetHolds(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.HoldObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get holds. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	list := model.HoldList{Holds: []*model.Hold{}}
+	for keysIter.HasNext() {
+		holdBytes := nextValue(keysIter)
+		hold := new(model.Hold)
+		if err := json.Unmarshal(holdBytes, hold); err != nil {
+			loggerFor(stub).Errorf("Failed to get hold details. Error: %s", err)
+			continue
+		}
+		if hold.Status == model.HoldActive {
+			list.Holds = append(list.Holds, hold)
+		}
+	}
+	return json.Marshal(list)
+}
+
+// GetAvailableBalance query the amount that may be transferred out of an
+// account: its balance plus overdraft limit, less the amount held
+func (cc *Chaincode) GetAvailableBalance(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(model.NewAvailableBalance(account))
+}
+
+func (cc *Chaincode) mustGetHold(stub shim.ChaincodeStubInterface, customerID string, accountID string, holdID string) (*model.Hold, error) {
+	key, _ := cc.createCompositeKey(stub, model.HoldObjectType, []string{customerID, accountID, holdID})
+	holdBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get hold details. Error: %s", err)
+		return nil, err
+	}
+	if holdBytes == nil {
+		return nil, fmt.Errorf("Hold %s not found.", holdID)
+	}
+	hold := new(model.Hold)
+	if err := bytesToStruct(holdBytes, hold); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (cc *Chaincode) putHold(stub shim.ChaincodeStubInterface, hold *model.Hold) ([]byte, error) {
+	holdData, err := json.Marshal(hold)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling hold data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, hold.GetObjectType(), []string{hold.CustomerID, hold.AccountID, hold.ID})
+	if err := stub.PutState(key, holdData); err != nil {
+		return nil, err
+	}
+	return holdData, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// holdsOf returns the active holds of an account
+func holdsOf(t *testing.T, stub *testsupport.Stub, customerID string, accountID string) []*model.Hold {
+	t.Helper()
+	list := new(model.HoldList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetHolds", customerID, accountID), list); err != nil {
+		t.Fatal(err)
+	}
+	return list.Holds
+}
+
+func TestHeldAmountIsNotAvailableUntilReleased(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 5000)
+	teller, payer := testsupport.Operator(t, RoleTeller), testsupport.Customer(t, "1001")
+	transfer := testsupport.NewTransfer("1001", "1", "1002", "1", 2000).JSON()
+
+	hold := new(model.Hold)
+	if err := json.Unmarshal(stub.As(teller).MustCall(t, "PlaceHold", "1001", "1", "4000", "card authorization"), hold); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stub.Call("PlaceHold", "1001", "1", "1001", "second authorization"); err == nil || !strings.Contains(err.Error(), "Insufficient funds available in account 1") {
+		t.Errorf("Expected a hold over the available balance refused, got %v", err)
+	}
+	if _, err := stub.As(payer).Call("TransferMoney", transfer); err == nil || !strings.Contains(err.Error(), "Insufficient funds available in account 1") {
+		t.Errorf("Expected the held amount not transferable, got %v", err)
+	}
+	if holds := holdsOf(t, stub, "1001", "1"); len(holds) != 1 || holds[0].Amount != 4000 {
+		t.Errorf("Expected the hold listed, got %+v", holds)
+	}
+
+	stub.As(teller).MustCall(t, "ReleaseHold", "1001", "1", hold.ID)
+	if _, err := stub.Call("ReleaseHold", "1001", "1", hold.ID); err == nil || !strings.Contains(err.Error(), "is released") {
+		t.Errorf("Expected a released hold not released twice, got %v", err)
+	}
+	stub.As(payer).MustCall(t, "TransferMoney", transfer)
+	if holds := holdsOf(t, stub, "1001", "1"); len(holds) != 0 {
+		t.Errorf("Expected no active holds, got %+v", holds)
+	}
+	if balance := balanceOf(t, stub, "1001", "1"); balance != 3000 {
+		t.Errorf("Expected the transfer made once the hold was released, got a balance of %d", balance)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Interbank position handler functions
+//------------------------------
+
+// GetInterbankPosition query a bank's positions to its counterparty banks by
+// currency, optionally only those to one counterparty
+func (cc *Chaincode) GetInterbankPosition(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, errors.New("Missing required BIC and optional counterparty BIC")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.InterbankPositionObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get interbank positions. Error: %s", err)
+		return nil, err
+	}
+	report := model.InterbankPositionReport{BankID: args[0], Positions: []*model.InterbankPosition{}}
+	for keysIter.HasNext() {
+		position := new(model.InterbankPosition)
+		if err := json.Unmarshal(nextValue(keysIter), position); err != nil {
+			loggerFor(stub).Errorf("Failed to get interbank position details. Error: %s", err)
+			continue
+		}
+		report.Positions = append(report.Positions, position)
+	}
+	return json.Marshal(report)
+}
+
+// settleInterbank records a transfer between two banks in both banks'
+// positions and as an obligation for the netting run of its value date, which
+// settles it net through the banks' settlement accounts
+func (cc *Chaincode) settleInterbank(stub shim.ChaincodeStubInterface, fromBankID string, toBankID string, t *model.Transfer) error {
+	if fromBankID == "" || toBankID == "" || fromBankID == toBankID {
+		return nil
+	}
+	if _, err := cc.mustGetBank(stub, fromBankID); err != nil {
+		return err
+	}
+	if _, err := cc.mustGetBank(stub, toBankID); err != nil {
+		return err
+	}
+	if err := cc.recordObligation(stub, fromBankID, toBankID, t); err != nil {
+		return err
+	}
+	if err := cc.updateInterbankPosition(stub, fromBankID, toBankID, t.CurrencyCode, func(p *model.InterbankPosition) { p.Send(t.Amount, txContext(stub)) }); err != nil {
+		return err
+	}
+	return cc.updateInterbankPosition(stub, toBankID, fromBankID, t.CurrencyCode, func(p *model.InterbankPosition) { p.Receive(t.Amount, txContext(stub)) })
+}
+
+// moveSettlementFunds debits a batch's net amount in a currency from the paying
+// bank's settlement account and credits it to the receiving bank's, recording
+// a transaction on each
+func (cc *Chaincode) moveSettlementFunds(stub shim.ChaincodeStubInterface, from *model.SettlementAccount, to *model.SettlementAccount, batch *model.SettlementBatch, currency string, amount int64) error {
+	fromAccount, err := cc.getAccountStruct(stub, from.CustomerID, from.AccountID)
+	if err != nil {
+		return err
+	}
+	toAccount, err := cc.getAccountStruct(stub, to.CustomerID, to.AccountID)
+	if err != nil {
+		return err
+	}
+	settlement := &model.Transfer{
+		FromCustomerID: fromAccount.CustomerID,
+		FromAccountID:  fromAccount.ID,
+		ToCustomerID:   toAccount.CustomerID,
+		ToAccountID:    toAccount.ID,
+		Amount:         amount,
+		CurrencyCode:   currency,
+		Description:    fmt.Sprintf("Interbank net settlement for value date %s", batch.ValueDate),
+		Params:         map[string]string{"settlement_batch": batch.ID},
+	}
+	if err := cc.debitAccount(stub, fromAccount, settlement.Money()); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, fromAccount.CustomerID, fromAccount.ID, settlement, "", model.Debited); err != nil {
+		return err
+	}
+	if err := cc.creditAccount(stub, toAccount, settlement.Money()); err != nil {
+		return err
+	}
+	return cc.recordTransaction(stub, toAccount.CustomerID, toAccount.ID, settlement, "", model.Credited)
+}
+
+// updateInterbankPosition applies a change to a bank's position to a counterparty in a currency
+func (cc *Chaincode) updateInterbankPosition(stub shim.ChaincodeStubInterface, bankID string, counterpartyID string, currency string, update func(*model.InterbankPosition)) error {
+	key, _ := cc.createCompositeKey(stub, model.InterbankPositionObjectType, []string{bankID, counterpartyID, currency})
+	positionBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get interbank position details. Error: %s", err)
+		return err
+	}
+	position := model.CreateInterbankPosition(bankID, counterpartyID, currency)
+	if positionBytes != nil {
+		if err := bytesToStruct(positionBytes, position); err != nil {
+			return err
+		}
+	}
+	update(position)
+	positionData, err := json.Marshal(position)
+	if err != nil {
+		return fmt.Errorf("Error marshalling interbank position data. Error: %s", err)
+	}
+	return stub.PutState(key, positionData)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Interest handler functions
+//------------------------------
+
+// SetInterestConfig sets the interest paid on accounts of a type in a
+// currency, replacing any existing config. Restricted to interest administrators.
+func (cc *Chaincode) SetInterestConfig(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required interest config data JSON")
+	}
+	setBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	config, err := model.CreateInterestConfig([]byte(args[0]), setBy, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating interest config. Error: %s", err)
+	}
+	funding, err := cc.getAccountStruct(stub, config.FundingCustomerID, config.FundingAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if funding.CurrencyCode != config.CurrencyCode {
+		return nil, fmt.Errorf("Funding account currency %s does not match %s", funding.CurrencyCode, config.CurrencyCode)
+	}
+	configData, _ := json.Marshal(config)
+	key, _ := cc.createCompositeKey(stub, config.GetObjectType(), []string{config.AccountType, config.CurrencyCode})
+	if err := stub.PutState(key, configData); err != nil {
+		return nil, err
+	}
+	return configData, nil
+}
+
+// GetInterestConfig query the interest config of an account type and currency
+func (cc *Chaincode) GetInterestConfig(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required account type and / or currency")
+	}
+	key, _ := cc.createCompositeKey(stub, model.InterestConfigObjectType, args)
+	return stub.GetState(key)
+}
+
+// AccrueInterest credits one compounding period's interest to every eligible
+// account of a type and currency, paid from the config's funding account.
+// Each account accrues a period at most once. An account that cannot accrue
+// is reported, leaves no state behind and may be retried by invoking again
+// for the same period.
+// Restricted to interest administrators.
+func (cc *Chaincode) AccrueInterest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required account type, currency and / or period")
+	}
+	key, _ := cc.createCompositeKey(stub, model.InterestConfigObjectType, args[:2])
+	configBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get interest config details. Error: %s", err)
+		return nil, err
+	}
+	if configBytes == nil {
+		return nil, fmt.Errorf("No interest config set for %s accounts in %s", args[0], args[1])
+	}
+	config := new(model.InterestConfig)
+	if err := bytesToStruct(configBytes, config); err != nil {
+		return nil, err
+	}
+	period := args[2]
+	if err := config.ValidatePeriod(period, txContext(stub).Time); err != nil {
+		return nil, err
+	}
+
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.AccountObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get account list. Error: %s", err)
+		return nil, err
+	}
+	var accounts []*model.Account
+	for keysIter.HasNext() {
+		account := new(model.Account)
+		if err := json.Unmarshal(nextValue(keysIter), account); err != nil {
+			loggerFor(stub).Errorf("Failed to get account details. Error: %s", err)
+			continue
+		}
+		if account.ObjectType == model.AccountObjectType && config.Eligible(account) {
+			accounts = append(accounts, account)
+		}
+	}
+
+	report := &model.InterestReport{AccountType: config.AccountType, CurrencyCode: config.CurrencyCode, Period: period, Accruals: []*model.InterestResult{}}
+	for _, account := range accounts {
+		result := &model.InterestResult{CustomerID: account.CustomerID, AccountID: account.ID}
+		var amount int64
+		err := atomically(stub, func() (err error) {
+			amount, err = cc.accrueInterest(stub, config, account.CustomerID, account.ID, period)
+			return err
+		})
+		if err != nil {
+			loggerFor(stub).Warningf("Interest accrual of account %s failed. Error: %s", account.ID, err)
+			result.Error = err.Error()
+		} else {
+			result.Amount = amount
+			report.Total += amount
+		}
+		report.Accruals = append(report.Accruals, result)
+	}
+	return json.Marshal(report)
+}
+
+// GetAccruedInterest query the interest credited to an account and the last
+// period it was accrued for
+func (cc *Chaincode) GetAccruedInterest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	accrual, err := cc.getInterestAccrual(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(accrual)
+}
+
+// accrueInterest credits an account's interest for a period from the funding
+// account, writing interest transactions on both. The account is read afresh,
+// and again after the debit, since earlier accruals of the invocation may have
+// paid from it or, when it is the funding account, the debit just did.
+func (cc *Chaincode) accrueInterest(stub shim.ChaincodeStubInterface, config *model.InterestConfig, customerID string, accountID string, period string) (int64, error) {
+	account, err := cc.getAccountStruct(stub, customerID, accountID)
+	if err != nil {
+		return 0, err
+	}
+	accrual, err := cc.getInterestAccrual(stub, account.CustomerID, account.ID)
+	if err != nil {
+		return 0, err
+	}
+	amount := config.Interest(account.Balance)
+	if err := accrual.Accrue(period, amount, txContext(stub)); err != nil {
+		return 0, err
+	}
+	if amount > 0 {
+		funding, err := cc.getAccountStruct(stub, config.FundingCustomerID, config.FundingAccountID)
+		if err != nil {
+			return 0, err
+		}
+		if !funding.CanSend() || funding.Available() < amount {
+			return 0, fmt.Errorf("Insufficient funds available in funding account %s", funding.ID)
+		}
+		t := &model.Transfer{
+			FromCustomerID: funding.CustomerID,
+			FromAccountID:  funding.ID,
+			ToCustomerID:   account.CustomerID,
+			ToAccountID:    account.ID,
+			Amount:         amount,
+			CurrencyCode:   account.CurrencyCode,
+			Description:    fmt.Sprintf("Interest for %s", period),
+			Params:         map[string]string{"period": period},
+			Type:           model.TxTypeInterest,
+		}
+		if err := cc.debitAccount(stub, funding, t.Money()); err != nil {
+			return 0, err
+		}
+		if err := cc.recordTransaction(stub, funding.CustomerID, funding.ID, t, "", model.Debited); err != nil {
+			return 0, err
+		}
+		if account, err = cc.getAccountStruct(stub, customerID, accountID); err != nil {
+			return 0, err
+		}
+		if err := cc.creditAccount(stub, account, t.Money()); err != nil {
+			return 0, err
+		}
+		if err := cc.recordTransaction(stub, account.CustomerID, account.ID, t, "", model.Credited); err != nil {
+			return 0, err
+		}
+	}
+	accrualData, err := json.Marshal(accrual)
+	if err != nil {
+		return 0, fmt.Errorf("Error marshalling interest accrual data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, accrual.GetObjectType(), []string{accrual.CustomerID, accrual.AccountID})
+	return amount, stub.PutState(key, accrualData)
+}
+
+// getInterestAccrual returns the interest accrued to an account, empty if none was
+func (cc *Chaincode) getInterestAccrual(stub shim.ChaincodeStubInterface, customerID string, accountID string) (*model.InterestAccrual, error) {
+	key, _ := cc.createCompositeKey(stub, model.InterestAccrualObjectType, []string{customerID, accountID})
+	accrualBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get interest accrual details. Error: %s", err)
+		return nil, err
+	}
+	accrual := model.CreateInterestAccrual(customerID, accountID)
+	if accrualBytes == nil {
+		return accrual, nil
+	}
+	if err := bytesToStruct(accrualBytes, accrual); err != nil {
+		return nil, err
+	}
+	return accrual, nil
+}
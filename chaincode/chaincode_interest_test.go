@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// accrueInterest runs a daily savings accrual for the period and returns its report
+func accrueInterest(t *testing.T, stub *testsupport.Stub, period string) *model.InterestReport {
+	t.Helper()
+	report := new(model.InterestReport)
+	if err := json.Unmarshal(stub.MustCall(t, "AccrueInterest", "savings", "AUD", period), report); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestAccrueInterestLeavesNothingOfAFailedAccrual(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("9000", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1").OfType("savings"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1").OfType("savings"))
+	stub.Topup(t, "9000", "1", 150)
+	stub.Topup(t, "1001", "1", 100000)
+	stub.Topup(t, "1002", "1", 200000)
+
+	// 36.5% a year pays a thousandth of the balance a day
+	stub.As(testsupport.Operator(t, RoleInterestAdmin))
+	stub.MustCall(t, "SetInterestConfig", `{"account_type":"savings","currency":"AUD","rate":3650,"compounding":"daily","funding_customer":"9000","funding_account":"1"}`)
+	report := accrueInterest(t, stub, "2021-02-28")
+	if len(report.Accruals) != 2 || report.Accruals[0].Amount != 100 || !strings.Contains(report.Accruals[1].Error, "Insufficient funds") || report.Total != 100 {
+		t.Errorf("Expected the second accrual refused by the funding account, got %+v", report.Accruals)
+	}
+	for customerID, expected := range map[string]int64{"9000": 50, "1001": 100100, "1002": 200000} {
+		if balance := balanceOf(t, stub, customerID, "1"); balance != expected {
+			t.Errorf("Expected customer %s at %d, got %d", customerID, expected, balance)
+		}
+	}
+
+	stub.Topup(t, "9000", "1", 150)
+	report = accrueInterest(t, stub, "2021-02-28")
+	if !strings.Contains(report.Accruals[0].Error, "already accrued") || report.Accruals[1].Amount != 200 || report.Total != 200 {
+		t.Errorf("Expected only the failed accrual retried, got %+v", report.Accruals)
+	}
+	for customerID, expected := range map[string]int64{"9000": 0, "1001": 100100, "1002": 200200} {
+		if balance := balanceOf(t, stub, customerID, "1"); balance != expected {
+			t.Errorf("Expected customer %s at %d, got %d", customerID, expected, balance)
+		}
+	}
+}
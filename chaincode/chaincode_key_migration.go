@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// compositeKeyNamespace starts every key created by the shim's CreateCompositeKey
+// and separates its object type and attributes
+const compositeKeyNamespace = "\x00"
+
+// legacyKeySeparator separated the object type and attributes of keys written
+// before the shim composite key format was adopted
+const legacyKeySeparator = "0"
+
+//------------------------------
+// Key migration handler functions
+//------------------------------
+
+// MigrateKeys rewrites one page of the legacy keys of an object type, optionally
+// within a tenant namespace, into the shim composite key format. The attributes
+// of a legacy key are recovered by matching it against the values of its record;
+// keys that match no or several ways are reported unresolved and left in place
+// for MigrateKey. Restricted to network operators.
+func (cc *Chaincode) MigrateKeys(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 4 || args[0] == "" {
+		return nil, errors.New("Missing required object type")
+	}
+	pageSize, err := keyMigrationPageSize(optionalArg(args, 3))
+	if err != nil {
+		return nil, err
+	}
+	raw, target, err := cc.keyMigrationStubs(stub, optionalArg(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	report := &model.KeyMigrationReport{ObjectType: args[0], Tenant: optionalArg(args, 1), Unresolved: []string{}}
+	prefix := legacyTenantPrefix(report.Tenant) + legacyCompositeKey(report.ObjectType, []string{})
+	start := prefix
+	if bookmark := optionalArg(args, 2); bookmark != "" {
+		if !strings.HasPrefix(bookmark, prefix) {
+			return nil, fmt.Errorf("Invalid bookmark %s", bookmark)
+		}
+		// migrated keys are deleted, so only unresolved keys lie before the bookmark
+		start = bookmark + "\x00"
+	}
+	keysIter, err := raw.GetStateByRange(start, prefix+string(utf8.MaxRune))
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching rows: %s", err)
+	}
+	defer keysIter.Close()
+	examined := 0
+	lastKey := ""
+	for keysIter.HasNext() {
+		key, value, err := nextKeyValue(keysIter)
+		if err != nil {
+			return nil, err
+		}
+		if examined == pageSize {
+			report.NextBookmark = lastKey
+			break
+		}
+		examined++
+		lastKey = key
+		attributes, ok := resolveLegacyKey(strings.TrimPrefix(key, prefix), value)
+		if !ok {
+			report.Unresolved = append(report.Unresolved, key)
+			continue
+		}
+		if err := cc.rewriteLegacyKey(raw, target, key, report.ObjectType, attributes, value); err != nil {
+			loggerFor(stub).Warningf("Key %q was not migrated. Error: %s", key, err)
+			report.Unresolved = append(report.Unresolved, key)
+			continue
+		}
+		report.Migrated++
+	}
+	return json.Marshal(report)
+}
+
+// MigrateKey rewrites a single legacy key given its object type and attributes,
+// for keys MigrateKeys could not resolve. The attributes must recreate the
+// legacy key exactly. Restricted to network operators.
+func (cc *Chaincode) MigrateKey(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return nil, errors.New("Missing required legacy key, object type and / or attributes JSON")
+	}
+	var attributes []string
+	if err := json.Unmarshal([]byte(args[2]), &attributes); err != nil {
+		return nil, fmt.Errorf("Error parsing attributes. Error: %s", err)
+	}
+	report := &model.KeyMigrationReport{ObjectType: args[1], Tenant: optionalArg(args, 3), Unresolved: []string{}}
+	legacyKey := args[0]
+	if legacyKey != legacyTenantPrefix(report.Tenant)+legacyCompositeKey(report.ObjectType, attributes) {
+		return nil, fmt.Errorf("Attributes do not recreate legacy key %q", legacyKey)
+	}
+	raw, target, err := cc.keyMigrationStubs(stub, report.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	value, err := raw.GetState(legacyKey)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("Legacy key %q not found.", legacyKey)
+	}
+	if err := cc.rewriteLegacyKey(raw, target, legacyKey, report.ObjectType, attributes, value); err != nil {
+		return nil, err
+	}
+	report.Migrated = 1
+	return json.Marshal(report)
+}
+
+// MigrateTransactionKeys moves one page of the transactions stored before
+// transaction keys were bucketed by creation day, optionally within a tenant
+// namespace, to their bucketed key, together with their private data, and
+// points their ID, reference and index entries at the new key. Transactions
+// whose private data the endorsing peer cannot read are reported unresolved
+// and left in place. Restricted to network operators.
+func (cc *Chaincode) MigrateTransactionKeys(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 3 {
+		return nil, errors.New("Too many arguments, expected optional tenant ID, bookmark and page size")
+	}
+	pageSize, err := keyMigrationPageSize(optionalArg(args, 2))
+	if err != nil {
+		return nil, err
+	}
+	report := &model.KeyMigrationReport{ObjectType: model.TransactionObjectType, Tenant: optionalArg(args, 0), Unresolved: []string{}}
+	raw, target, err := cc.keyMigrationStubs(stub, report.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	// the records are moved under their raw keys, as private data is stored
+	// under the tenant namespace rather than the key the handlers see
+	prefix, err := cc.createCompositeKey(raw, model.TransactionObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	namespace := ""
+	if report.Tenant != "" {
+		namespace = tenantNamespace(report.Tenant)
+		prefix = namespace + strings.TrimPrefix(prefix, compositeKeyNamespace)
+	}
+	report.NextBookmark, err = scanKeyRangePage(raw, prefix, prefix, prefix+string(utf8.MaxRune), optionalArg(args, 1), pageSize, func(key string, value []byte) error {
+		_, _, attrs, err := splitScopedKey(raw, key)
+		if err != nil || len(attrs) != 3 {
+			// bucketed already
+			return nil
+		}
+		txn := new(model.Transaction)
+		if err := json.Unmarshal(value, txn); err != nil {
+			loggerFor(stub).Warningf("Key %q was not migrated. Error: %s", key, err)
+			report.Unresolved = append(report.Unresolved, key)
+			return nil
+		}
+		txnKey := cc.transactionKey(raw, txn)
+		newKey := txnKey
+		if namespace != "" {
+			newKey = namespace + strings.TrimPrefix(txnKey, compositeKeyNamespace)
+		}
+		moved, err := moveRecord(raw, key, newKey, value)
+		if err != nil {
+			return err
+		}
+		if !moved {
+			loggerFor(stub).Warningf("Key %q was not migrated, its private data is not readable", key)
+			report.Unresolved = append(report.Unresolved, key)
+			return nil
+		}
+		if err := cc.linkTransaction(target, txn, txnKey); err != nil {
+			return err
+		}
+		report.Migrated++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(report)
+}
+
+// moveRecord moves a public record and its private data from one raw key to
+// another. The record is left in place, and false returned, if its private
+// data cannot be read from every collection it is stored in.
+func moveRecord(raw shim.ChaincodeStubInterface, from string, to string, value []byte) (bool, error) {
+	ref := model.PrivateDataRefOf(value)
+	privateData := make(map[string][]byte)
+	if ref != nil {
+		for _, collection := range ref.Collections {
+			data, err := raw.GetPrivateData(collection, from)
+			if err != nil || data == nil {
+				return false, nil
+			}
+			privateData[collection] = data
+		}
+	}
+	for collection, data := range privateData {
+		if err := raw.PutPrivateData(collection, to, data); err != nil {
+			return false, err
+		}
+		if err := raw.DelPrivateData(collection, from); err != nil {
+			return false, err
+		}
+	}
+	if err := raw.PutState(to, value); err != nil {
+		return false, err
+	}
+	return true, raw.DelState(from)
+}
+
+// keyMigrationPageSize parses the optional page size of a key migration
+func keyMigrationPageSize(size string) (int, error) {
+	if size == "" {
+		return model.MaxKeyMigrationPageSize, nil
+	}
+	n, err := strconv.Atoi(size)
+	if err != nil || n <= 0 || n > model.MaxKeyMigrationPageSize {
+		return 0, fmt.Errorf("Invalid page size %s", size)
+	}
+	return n, nil
+}
+
+// keyMigrationStubs returns the unscoped stub legacy keys are read and deleted
+// through, and the stub their records are rewritten through, which is scoped
+// to the tenant if one is given
+func (cc *Chaincode) keyMigrationStubs(stub shim.ChaincodeStubInterface, tenant string) (shim.ChaincodeStubInterface, shim.ChaincodeStubInterface, error) {
+	var raw shim.ChaincodeStubInterface = stub
+	if tx := unwrapTxStub(stub); tx != nil {
+		raw = tx
+	}
+	if tenant == "" {
+		return raw, raw, nil
+	}
+	if err := model.ValidateTenantID(tenant); err != nil {
+		return nil, nil, err
+	}
+	return raw, &tenantStub{ChaincodeStubInterface: raw, tenant: tenant}, nil
+}
+
+// rewriteLegacyKey moves a record from its legacy key to its composite key,
+// refusing to overwrite a record already stored under the composite key
+func (cc *Chaincode) rewriteLegacyKey(raw shim.ChaincodeStubInterface, target shim.ChaincodeStubInterface, legacyKey string, objectType string, attributes []string, value []byte) error {
+	key, err := cc.createCompositeKey(target, objectType, attributes)
+	if err != nil {
+		return err
+	}
+	existing, err := target.GetState(key)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("A record is already stored under the composite key of %q", legacyKey)
+	}
+	if err := target.PutState(key, value); err != nil {
+		return err
+	}
+	return raw.DelState(legacyKey)
+}
+
+// legacyCompositeKey returns the key the object was stored under before the
+// shim composite key format was adopted
+func legacyCompositeKey(objectType string, attributes []string) string {
+	key := objectType + legacyKeySeparator
+	for _, att := range attributes {
+		key += att + legacyKeySeparator
+	}
+	return key
+}
+
+// legacyTenantPrefix returns the legacy key prefix of a tenant namespace, or
+// the empty string outside tenant namespaces
+func legacyTenantPrefix(tenant string) string {
+	if tenant == "" {
+		return ""
+	}
+	return "@" + tenant + legacyKeySeparator
+}
+
+// resolveLegacyKey recovers the attributes of a legacy key, given the key
+// without its object type prefix and the record stored under it. Since the
+// attributes may themselves contain the separator, the key is split only
+// where every part equals a string or integer value of the record, and only
+// if exactly one such split exists.
+func resolveLegacyKey(rest string, value []byte) ([]string, bool) {
+	var record interface{}
+	if err := json.Unmarshal(value, &record); err != nil {
+		return nil, false
+	}
+	candidates := make(map[string]bool)
+	collectKeyCandidates(record, candidates)
+
+	var found [][]string
+	var split func(rest string, attributes []string)
+	split = func(rest string, attributes []string) {
+		if len(found) > 1 {
+			return
+		}
+		if rest == "" {
+			found = append(found, append([]string{}, attributes...))
+			return
+		}
+		for candidate := range candidates {
+			if strings.HasPrefix(rest, candidate+legacyKeySeparator) {
+				split(rest[len(candidate)+len(legacyKeySeparator):], append(attributes, candidate))
+			}
+		}
+	}
+	split(rest, []string{})
+	if len(found) != 1 {
+		return nil, false
+	}
+	return found[0], true
+}
+
+// collectKeyCandidates gathers the non-empty string and integer values of a
+// decoded JSON record, at any depth
+func collectKeyCandidates(v interface{}, candidates map[string]bool) {
+	switch value := v.(type) {
+	case string:
+		if value != "" {
+			candidates[value] = true
+		}
+	case float64:
+		if value == float64(int64(value)) {
+			candidates[strconv.FormatInt(int64(value), 10)] = true
+		}
+	case map[string]interface{}:
+		for _, field := range value {
+			collectKeyCandidates(field, candidates)
+		}
+	case []interface{}:
+		for _, item := range value {
+			collectKeyCandidates(item, candidates)
+		}
+	}
+}
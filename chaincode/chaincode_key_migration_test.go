@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestMigrateTransactionKeysBucketsByDay(t *testing.T) {
+	stub := newTestStub()
+	stub.MockTransactionStart("seed")
+	var txns []*model.Transaction
+	var legacyKeys []string
+	for i := 1; i <= 4; i++ {
+		tx := model.CreateTxContext("seed"+strconv.Itoa(i), testsupport.Epoch.Add(time.Duration(i)*24*time.Hour))
+		transfer := &model.Transfer{FromCustomerID: "1001", FromAccountID: "1", ToCustomerID: "1002", ToAccountID: "1", Amount: int64(i), CurrencyCode: "AUD", EndToEndID: "E2E-" + strconv.Itoa(i)}
+		txn, _ := model.CreateTransaction("1001", "1", transfer, model.TxFailureCodeNone, model.Debited, tx)
+		key, _ := stub.CreateCompositeKey(model.TransactionObjectType, []string{txn.CustomerID, txn.AccountID, txn.ID})
+		data, _ := json.Marshal(txn)
+		if i == 4 {
+			// private data held by another bank's peers
+			data, _ = setPrivateDataRef(data, &model.PrivateDataRef{Collections: []string{implicitCollectionPrefix + "Bank2MSP"}, Hash: "0"})
+		}
+		if err := stub.PutState(key, data); err != nil {
+			t.Fatal(err)
+		}
+		txns = append(txns, txn)
+		legacyKeys = append(legacyKeys, key)
+	}
+	stub.MockTransactionEnd("seed")
+
+	stub.As(testsupport.Operator(t, RoleNetworkOperator))
+	if res := stub.MustCall(t, "GetTransaction", "1001", "1", txns[0].ID); len(res) == 0 {
+		t.Errorf("Expected the transaction found under its legacy key before the migration")
+	}
+	migrated, pages := 0, 0
+	var unresolved []string
+	for bookmark := ""; pages == 0 || bookmark != ""; pages++ {
+		report := new(model.KeyMigrationReport)
+		if err := json.Unmarshal(stub.MustCall(t, "MigrateTransactionKeys", "", bookmark, "2"), report); err != nil {
+			t.Fatal(err)
+		}
+		migrated += report.Migrated
+		unresolved = append(unresolved, report.Unresolved...)
+		bookmark = report.NextBookmark
+	}
+	if pages < 2 || migrated != 3 || len(unresolved) != 1 || unresolved[0] != legacyKeys[3] {
+		t.Errorf("Expected 3 transactions migrated in pages of 2 and the one with unreadable private data unresolved, got %d in %d pages and %q", migrated, pages, unresolved)
+	}
+
+	for i, txn := range txns[:3] {
+		if _, ok := stub.State[legacyKeys[i]]; ok {
+			t.Errorf("Expected legacy key of transaction %d deleted", i+1)
+		}
+		got := new(model.Transaction)
+		if err := json.Unmarshal(stub.MustCall(t, "GetTransaction", "1001", "1", txn.ID), got); err != nil || got.ID != txn.ID {
+			t.Errorf("Expected transaction %d found by ID after the migration, got %v", i+1, err)
+		}
+	}
+	if _, ok := stub.State[legacyKeys[3]]; !ok {
+		t.Errorf("Expected the unresolved transaction left under its legacy key")
+	}
+	list := new(model.TransactionList)
+	if err := json.Unmarshal(stub.As(testsupport.Operator(t, RoleTeller)).MustCall(t, "GetTransactionByReference", "E2E-2"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Transactions) != 1 || list.Transactions[0].ID != txns[1].ID {
+		t.Errorf("Expected the reference pointing to the migrated transaction, got %d transactions", len(list.Transactions))
+	}
+	if err := json.Unmarshal(stub.MustCall(t, "QueryTransactionIndex", `{"index":"date","from":"2021-03-03","to":"2021-03-03"}`), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Transactions) != 1 || list.Transactions[0].ID != txns[1].ID {
+		t.Errorf("Expected the date index pointing to the migrated transaction, got %d transactions", len(list.Transactions))
+	}
+}
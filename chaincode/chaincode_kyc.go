@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// KYC handler functions
+//------------------------------
+
+// SubmitKYC submits a customer's identity documents hash for verification,
+// replacing any earlier profile with a pending one
+func (cc *Chaincode) SubmitKYC(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required KYC profile JSON")
+	}
+	submittedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := model.CreateKYCProfile([]byte(args[0]), submittedBy, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating KYC profile. Error: %s", err)
+	}
+	return cc.putKYCProfile(stub, profile)
+}
+
+// ApproveKYC approves a customer's pending KYC profile with a risk rating and
+// expiry date. Restricted to compliance officers.
+func (cc *Chaincode) ApproveKYC(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, risk rating and / or expiry date")
+	}
+	profile, err := cc.mustGetPendingKYCProfile(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	reviewedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := profile.Approve(model.KYCRiskRating(args[1]), args[2], reviewedBy, txContext(stub)); err != nil {
+		return nil, err
+	}
+	return cc.putKYCProfile(stub, profile)
+}
+
+// RejectKYC rejects a customer's pending KYC profile. Restricted to compliance officers.
+func (cc *Chaincode) RejectKYC(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or reason")
+	}
+	profile, err := cc.mustGetPendingKYCProfile(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	reviewedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	profile.Reject(args[1], reviewedBy, txContext(stub))
+	return cc.putKYCProfile(stub, profile)
+}
+
+// GetKYCStatus query a customer's KYC profile and whether it is currently valid
+func (cc *Chaincode) GetKYCStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required customer ID")
+	}
+	profile, err := cc.getKYCProfile(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("KYC profile of customer %s not found.", args[0])
+	}
+	now := txContext(stub).Time
+	return json.Marshal(&model.KYCStatusReport{KYCProfile: profile, Expired: profile.IsExpired(now), Valid: profile.IsValid(now)})
+}
+
+// requireKYC fails unless the customer has an approved, unexpired KYC profile
+func (cc *Chaincode) requireKYC(stub shim.ChaincodeStubInterface, customerID string) error {
+	profile, err := cc.getKYCProfile(stub, customerID)
+	if err != nil {
+		return err
+	}
+	now := txContext(stub).Time
+	switch {
+	case profile == nil:
+		return fmt.Errorf("Customer %s has no KYC profile", customerID)
+	case profile.Status != model.KYCApproved:
+		return fmt.Errorf("KYC of customer %s is %s", customerID, profile.Status)
+	case profile.IsExpired(now):
+		return fmt.Errorf("KYC of customer %s expired on %s", customerID, profile.Expiry)
+	}
+	return nil
+}
+
+func (cc *Chaincode) mustGetPendingKYCProfile(stub shim.ChaincodeStubInterface, customerID string) (*model.KYCProfile, error) {
+	profile, err := cc.getKYCProfile(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("KYC profile of customer %s not found.", customerID)
+	}
+	if profile.Status != model.KYCPending {
+		return nil, fmt.Errorf("KYC profile of customer %s is %s", customerID, profile.Status)
+	}
+	return profile, nil
+}
+
+func (cc *Chaincode) getKYCProfile(stub shim.ChaincodeStubInterface, customerID string) (*model.KYCProfile, error) {
+	key, _ := cc.createCompositeKey(stub, model.KYCProfileObjectType, []string{customerID})
+	profileBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get KYC profile details. Error: %s", err)
+		return nil, err
+	}
+	if profileBytes == nil {
+		return nil, nil
+	}
+	profile := new(model.KYCProfile)
+	if err := bytesToStruct(profileBytes, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (cc *Chaincode) putKYCProfile(stub shim.ChaincodeStubInterface, profile *model.KYCProfile) ([]byte, error) {
+	profileData, err := json.Marshal(profile)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling KYC profile data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, profile.GetObjectType(), []string{profile.CustomerID})
+	if err := stub.PutState(key, profileData); err != nil {
+		return nil, err
+	}
+	return profileData, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// AML transaction limit handler functions
+//------------------------------
+
+// transferFailure is a transfer error carrying the failure code reported with
+// the transfer.failed event
+type transferFailure struct {
+	code    model.TxFailureCode
+	err     error
+	matches []*model.BlockedParty // blocklist entries matched by a sanctions hit
+}
+
+func (f *transferFailure) Error() string {
+	return f.err.Error()
+}
+
+// failureCode returns the failure code of a transfer error, if it has one
+func failureCode(err error) model.TxFailureCode {
+	if f, ok := err.(*transferFailure); ok {
+		return f.code
+	}
+	return model.TxFailureCodeNone
+}
+
+// SetLimits creates or replaces a customer's transaction limits in a currency.
+// Restricted to compliance officers.
+func (cc *Chaincode) SetLimits(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required limits data JSON")
+	}
+	setBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := model.CreateLimits([]byte(args[0]), setBy, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating limits. Error: %s", err)
+	}
+	limitsData, _ := json.Marshal(limits)
+	key, _ := cc.createCompositeKey(stub, limits.GetObjectType(), []string{limits.CustomerID, limits.CurrencyCode})
+	if err := stub.PutState(key, limitsData); err != nil {
+		return nil, err
+	}
+	return limitsData, nil
+}
+
+// RemoveLimits deletes a customer's transaction limits in a currency.
+// Restricted to compliance officers.
+func (cc *Chaincode) RemoveLimits(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or currency")
+	}
+	key, _ := cc.createCompositeKey(stub, model.LimitsObjectType, args)
+	return nil, stub.DelState(key)
+}
+
+// GetLimits query a customer's transaction limits with the current rolling counters
+func (cc *Chaincode) GetLimits(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or currency")
+	}
+	limits, err := cc.getLimits(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if limits == nil {
+		return nil, fmt.Errorf("Limits of customer %s in %s not found.", args[0], args[1])
+	}
+	usage, err := cc.getLimitUsage(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&model.LimitStatus{Limits: limits, Counters: usage.Counters(txContext(stub).Time.Unix())})
+}
+
+// checkLimits counts a transfer against the paying customer's rolling limit
+// counters. Limits enforced by rejection fail a breaching transfer with the
+// limit_exceeded code; limits enforced by flagging let it settle, emit an
+// aml.limit_exceeded event and report it to the regulator.
+func (cc *Chaincode) checkLimits(stub shim.ChaincodeStubInterface, account *model.Account, payee *model.Account, t *model.Transfer) error {
+	limits, err := cc.getLimits(stub, account.CustomerID, t.CurrencyCode)
+	if err != nil {
+		return err
+	}
+	if limits == nil {
+		config, err := cc.getConfig(stub)
+		if err != nil {
+			return err
+		}
+		if limits = config.LimitsFor(account.CustomerID, t.CurrencyCode); limits == nil {
+			return nil
+		}
+	}
+	usage, err := cc.getLimitUsage(stub, account.CustomerID, t.CurrencyCode)
+	if err != nil {
+		return err
+	}
+	now := txContext(stub).Time.Unix()
+	usage.Add(now, t.Amount)
+	counters := usage.Counters(now)
+	if breached := limits.Breaches(t.Amount, counters); len(breached) > 0 {
+		if limits.Enforcement == model.LimitReject {
+			return &transferFailure{
+				code: model.LimitExceeded,
+				err:  fmt.Errorf("Transfer would exceed the %s limits of customer %s in %s", strings.Join(breached, ", "), account.CustomerID, t.CurrencyCode),
+			}
+		}
+		loggerFor(stub).Warningf("Transfer from account %s exceeds the %s limits of customer %s", account.ID, strings.Join(breached, ", "), account.CustomerID)
+		breach := &model.LimitBreach{
+			CustomerID:   account.CustomerID,
+			AccountID:    account.ID,
+			CurrencyCode: t.CurrencyCode,
+			Amount:       t.Amount,
+			Breached:     breached,
+			Counters:     counters,
+		}
+		if err := emitEvent(stub, model.EventLimitExceeded, breach); err != nil {
+			return err
+		}
+		report := model.CreateRegulatoryReport(model.ReportLimitExceeded, account, payee, t, txContext(stub))
+		report.Breached = breached
+		if err := cc.putRegulatoryReport(stub, report); err != nil {
+			return err
+		}
+	}
+	usageData, _ := json.Marshal(usage)
+	usageKey, _ := cc.createCompositeKey(stub, usage.GetObjectType(), []string{usage.CustomerID, usage.CurrencyCode})
+	return stub.PutState(usageKey, usageData)
+}
+
+func (cc *Chaincode) getLimits(stub shim.ChaincodeStubInterface, customerID string, currencyCode string) (*model.Limits, error) {
+	key, _ := cc.createCompositeKey(stub, model.LimitsObjectType, []string{customerID, currencyCode})
+	limitsBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get limits details. Error: %s", err)
+		return nil, err
+	}
+	if limitsBytes == nil {
+		return nil, nil
+	}
+	limits := new(model.Limits)
+	if err := bytesToStruct(limitsBytes, limits); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+func (cc *Chaincode) getLimitUsage(stub shim.ChaincodeStubInterface, customerID string, currencyCode string) (*model.LimitUsage, error) {
+	key, _ := cc.createCompositeKey(stub, model.LimitUsageObjectType, []string{customerID, currencyCode})
+	usageBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	usage := model.CreateLimitUsage(customerID, currencyCode)
+	if usageBytes != nil {
+		if err := bytesToStruct(usageBytes, usage); err != nil {
+			return nil, err
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestRejectedLimitsCountRollingTransfers(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 5000)
+	officer, payer := testsupport.Operator(t, RoleComplianceOfficer), testsupport.Customer(t, "1001")
+	stub.As(officer).MustCall(t, "SetLimits", `{"customer_id":"1001","currency":"AUD","single_max":500,"daily_max":800}`)
+	transfer := func(amount int64) error {
+		_, err := stub.As(payer).Call("TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", amount).JSON())
+		return err
+	}
+
+	if err := transfer(600); err == nil || !strings.Contains(err.Error(), "exceed the single_max limits of customer 1001 in AUD") {
+		t.Errorf("Expected a transfer above the single limit refused, got %v", err)
+	}
+	for _, amount := range []int64{400, 400} {
+		if err := transfer(amount); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := transfer(100); err == nil || !strings.Contains(err.Error(), "exceed the daily_max limits") {
+		t.Errorf("Expected a transfer above the daily total refused, got %v", err)
+	}
+	stub.Advance(24 * time.Hour)
+	if err := transfer(100); err != nil {
+		t.Errorf("Expected the daily total to roll over, got %v", err)
+	}
+
+	status := new(model.LimitStatus)
+	if err := json.Unmarshal(stub.As(officer).MustCall(t, "GetLimits", "1001", "AUD"), status); err != nil {
+		t.Fatal(err)
+	}
+	if c := status.Counters; c.DailyAmount != 100 || c.DailyCount != 1 || c.MonthlyAmount != 900 {
+		t.Errorf("Expected only the settled transfers counted, got %+v", c)
+	}
+}
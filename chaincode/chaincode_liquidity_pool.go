@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Liquidity pool handler functions
+//------------------------------
+
+// CreateLiquidityPool creates a new shared interbank liquidity pool
+func (cc *Chaincode) CreateLiquidityPool(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required liquidity pool data JSON")
+	}
+	pool, err := model.CreateLiquidityPool([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating liquidity pool. Error: %s", err)
+	}
+	existing, err := cc.getLiquidityPool(stub, pool.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Liquidity pool %s already exists", pool.ID)
+	}
+	if pool.Benchmark != "" {
+		rate, err := cc.latestBenchmarkRate(stub, pool.Benchmark)
+		if err != nil {
+			return nil, err
+		}
+		if rate == nil {
+			return nil, fmt.Errorf("Benchmark %s has no published rate", pool.Benchmark)
+		}
+		pool.BenchmarkRate = rate.Rate
+	}
+	return cc.putLiquidityPool(stub, pool)
+}
+
+// JoinLiquidityPool registers a bank nostro account and drawing limit with a pool
+// on behalf of the calling operator's bank. The nostro account must be held at the bank.
+func (cc *Chaincode) JoinLiquidityPool(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 5 {
+		return nil, errors.New("Missing required pool ID, bank ID, nostro customer ID, nostro account ID and / or drawing limit")
+	}
+	pool, err := cc.mustGetLiquidityPool(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	bank, err := cc.mustGetBank(stub, args[1])
+	if err != nil {
+		return nil, err
+	}
+	if !bank.IsActive() {
+		return nil, fmt.Errorf("Bank %s is suspended", bank.BIC)
+	}
+	if err := requireBankCaller(stub, bank); err != nil {
+		return nil, err
+	}
+	drawLimit, err := strconv.ParseInt(args[4], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing drawing limit value %s", args[4])
+	}
+	account, err := cc.getAccountStruct(stub, args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	if account.BankName != bank.BIC {
+		return nil, fmt.Errorf("Nostro account %s is not held at bank %s", account.ID, bank.BIC)
+	}
+	if account.CurrencyCode != pool.CurrencyCode {
+		return nil, fmt.Errorf("Nostro account currency %s does not match pool currency %s", account.CurrencyCode, pool.CurrencyCode)
+	}
+	if _, err := pool.Join(args[1], account.CustomerID, account.ID, drawLimit, txContext(stub)); err != nil {
+		return nil, err
+	}
+	return cc.putLiquidityPool(stub, pool)
+}
+
+// ContributeToPool moves funds from a member's nostro account into the pool
+func (cc *Chaincode) ContributeToPool(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	pool, member, amount, err := cc.poolMemberArgs(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, member.NostroCustomerID, member.NostroAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if account.Unheld()-amount < 0 {
+		return nil, fmt.Errorf("Insufficient funds available in account %s", account.ID)
+	}
+	if err := pool.Contribute(member, amount); err != nil {
+		return nil, err
+	}
+	if err := cc.debitAccount(stub, account, account.Money(amount)); err != nil {
+		return nil, err
+	}
+	if err := cc.recordPoolTransaction(stub, account, pool, amount, "Liquidity pool contribution", model.Debited); err != nil {
+		return nil, err
+	}
+	return cc.putLiquidityPool(stub, pool)
+}
+
+// WithdrawFromPool returns undrawn contributed funds to a member's nostro account
+func (cc *Chaincode) WithdrawFromPool(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	pool, member, amount, err := cc.poolMemberArgs(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, member.NostroCustomerID, member.NostroAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if err := pool.Withdraw(member, amount); err != nil {
+		return nil, err
+	}
+	if err := cc.creditAccount(stub, account, account.Money(amount)); err != nil {
+		return nil, err
+	}
+	if err := cc.recordPoolTransaction(stub, account, pool, amount, "Liquidity pool withdrawal", model.Credited); err != nil {
+		return nil, err
+	}
+	return cc.putLiquidityPool(stub, pool)
+}
+
+// DrawFromPool lends pool funds to a member's nostro account within its drawing limit
+func (cc *Chaincode) DrawFromPool(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	pool, member, amount, err := cc.poolMemberArgs(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, member.NostroCustomerID, member.NostroAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if err := pool.Draw(member, amount, txContext(stub).Time.Unix()); err != nil {
+		return nil, err
+	}
+	if err := cc.trackPoolDraw(stub, pool, member, amount); err != nil {
+		return nil, err
+	}
+	if err := cc.creditAccount(stub, account, account.Money(amount)); err != nil {
+		return nil, err
+	}
+	if err := cc.recordPoolTransaction(stub, account, pool, amount, "Liquidity pool draw", model.Credited); err != nil {
+		return nil, err
+	}
+	return cc.putLiquidityPool(stub, pool)
+}
+
+// RepayPool repays accrued interest and drawn principal from a member's nostro account
+func (cc *Chaincode) RepayPool(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	pool, member, amount, err := cc.poolMemberArgs(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, member.NostroCustomerID, member.NostroAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if account.Unheld()-amount < 0 {
+		return nil, fmt.Errorf("Insufficient funds available in account %s", account.ID)
+	}
+	principal, _, err := pool.Repay(member, amount, txContext(stub).Time.Unix())
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.trackPoolDraw(stub, pool, member, -principal); err != nil {
+		return nil, err
+	}
+	if err := cc.debitAccount(stub, account, account.Money(amount)); err != nil {
+		return nil, err
+	}
+	if err := cc.recordPoolTransaction(stub, account, pool, amount, "Liquidity pool repayment", model.Debited); err != nil {
+		return nil, err
+	}
+	return cc.putLiquidityPool(stub, pool)
+}
+
+// GetPoolPosition query pool funds, drawings and contribution shares
+func (cc *Chaincode) GetPoolPosition(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required pool ID")
+	}
+	pool, err := cc.mustGetLiquidityPool(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	now := txContext(stub).Time.Unix()
+	for _, m := range pool.Members {
+		pool.Accrue(m, now)
+	}
+	return json.Marshal(pool.Position())
+}
+
+// drawPoolLiquidity covers a settlement shortfall on a nostro account from the
+// first pool in the account currency the account is a member of. It returns
+// true if the shortfall was drawn and credited to the account.
+func (cc *Chaincode) drawPoolLiquidity(stub shim.ChaincodeStubInterface, account *model.Account, shortfall int64) (bool, error) {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.LiquidityPoolObjectType, []string{})
+	if err != nil {
+		return false, err
+	}
+	defer keysIter.Close()
+	for keysIter.HasNext() {
+		poolBytes := nextValue(keysIter)
+		pool := new(model.LiquidityPool)
+		if err := json.Unmarshal(poolBytes, pool); err != nil {
+			loggerFor(stub).Errorf("Failed to get liquidity pool details. Error: %s", err)
+			continue
+		}
+		if pool.CurrencyCode != account.CurrencyCode {
+			continue
+		}
+		member := pool.MemberByAccount(account.CustomerID, account.ID)
+		if member == nil {
+			continue
+		}
+		if err := cc.refreshPoolBenchmark(stub, pool); err != nil {
+			return false, err
+		}
+		if err := pool.Draw(member, shortfall, txContext(stub).Time.Unix()); err != nil {
+			loggerFor(stub).Infof("Liquidity pool %s cannot cover shortfall of account %s: %s", pool.ID, account.ID, err)
+			continue
+		}
+		if err := cc.trackPoolDraw(stub, pool, member, shortfall); err != nil {
+			return false, err
+		}
+		if err := cc.creditAccount(stub, account, account.Money(shortfall)); err != nil {
+			return false, err
+		}
+		if err := cc.recordPoolTransaction(stub, account, pool, shortfall, "Liquidity pool draw", model.Credited); err != nil {
+			return false, err
+		}
+		if _, err := cc.putLiquidityPool(stub, pool); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// trackPoolDraw updates the member bank's treasury with a change in outstanding pool draws
+func (cc *Chaincode) trackPoolDraw(stub shim.ChaincodeStubInterface, pool *model.LiquidityPool, member *model.PoolMember, amount int64) error {
+	return cc.updateTreasury(stub, member.BankID, pool.CurrencyCode, func(p *model.CurrencyPosition) { p.PoolDrawn += amount })
+}
+
+func (cc *Chaincode) poolMemberArgs(stub shim.ChaincodeStubInterface, args []string) (*model.LiquidityPool, *model.PoolMember, int64, error) {
+	if len(args) != 3 {
+		return nil, nil, 0, errors.New("Missing required pool ID, bank ID and / or amount")
+	}
+	pool, err := cc.mustGetLiquidityPool(stub, args[0])
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	member, ok := pool.Members[args[1]]
+	if !ok {
+		return nil, nil, 0, fmt.Errorf("Bank %s is not a member of liquidity pool %s", args[1], args[0])
+	}
+	bank, err := cc.mustGetBank(stub, member.BankID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if err := requireBankCaller(stub, bank); err != nil {
+		return nil, nil, 0, err
+	}
+	amount, err := model.ParseAmount(args[2])
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return pool, member, amount, nil
+}
+
+func (cc *Chaincode) recordPoolTransaction(stub shim.ChaincodeStubInterface, a *model.Account, pool *model.LiquidityPool, amount int64, description string, status model.TxStatus) error {
+	t := &model.Transfer{
+		Amount:       amount,
+		CurrencyCode: pool.CurrencyCode,
+		Description:  description,
+		Params:       map[string]string{"liquidity_pool": pool.ID},
+	}
+	return cc.recordTransaction(stub, a.CustomerID, a.ID, t, "", status)
+}
+
+func (cc *Chaincode) getLiquidityPool(stub shim.ChaincodeStubInterface, poolID string) (*model.LiquidityPool, error) {
+	key, _ := cc.createCompositeKey(stub, model.LiquidityPoolObjectType, []string{poolID})
+	poolBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get liquidity pool details. Error: %s", err)
+		return nil, err
+	}
+	if poolBytes == nil {
+		return nil, nil
+	}
+	pool := new(model.LiquidityPool)
+	if err := bytesToStruct(poolBytes, pool); err != nil {
+		return nil, err
+	}
+	if err := cc.refreshPoolBenchmark(stub, pool); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+// refreshPoolBenchmark prices the pool off the latest published rate of its benchmark
+func (cc *Chaincode) refreshPoolBenchmark(stub shim.ChaincodeStubInterface, pool *model.LiquidityPool) error {
+	if pool.Benchmark == "" {
+		return nil
+	}
+	rate, err := cc.latestBenchmarkRate(stub, pool.Benchmark)
+	if err != nil {
+		return err
+	}
+	if rate != nil {
+		pool.BenchmarkRate = rate.Rate
+	}
+	return nil
+}
+
+func (cc *Chaincode) mustGetLiquidityPool(stub shim.ChaincodeStubInterface, poolID string) (*model.LiquidityPool, error) {
+	pool, err := cc.getLiquidityPool(stub, poolID)
+	if err != nil {
+		return nil, err
+	}
+	if pool == nil {
+		return nil, fmt.Errorf("Liquidity pool %s not found.", poolID)
+	}
+	return pool, nil
+}
+
+func (cc *Chaincode) putLiquidityPool(stub shim.ChaincodeStubInterface, pool *model.LiquidityPool) ([]byte, error) {
+	poolData, err := json.Marshal(pool)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling liquidity pool data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, pool.GetObjectType(), []string{pool.ID})
+	if err := stub.PutState(key, poolData); err != nil {
+		return nil, err
+	}
+	return poolData, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestLiquidityPoolLendsContributionsWithinDrawingLimits(t *testing.T) {
+	stub := newTestStub()
+	stub.As(testsupport.Operator(t, RoleNetworkOperator))
+	stub.MustCall(t, "RegisterBank", settlementBank("FINNAU2S", "9001"))
+	stub.MustCall(t, "RegisterBank", settlementBank("OTHRAU2S", "9002"))
+	stub.MustCall(t, "CreateLiquidityPool", `{"id":"pool1","currency":"AUD"}`)
+	stub.OpenAccount(t, testsupport.NewAccount("9001", "1").AtBank("FINNAU2S"))
+	stub.OpenAccount(t, testsupport.NewAccount("9002", "1").AtBank("OTHRAU2S"))
+	stub.Topup(t, "9001", "1", 100000)
+
+	stub.As(testsupport.Operator(t, RoleAccountOperator))
+	stub.MustCall(t, "JoinLiquidityPool", "pool1", "FINNAU2S", "9001", "1", "0")
+	stub.MustCall(t, "JoinLiquidityPool", "pool1", "OTHRAU2S", "9002", "1", "20000")
+	stub.MustCall(t, "ContributeToPool", "pool1", "FINNAU2S", "50000")
+	stub.MustCall(t, "DrawFromPool", "pool1", "OTHRAU2S", "15000")
+	if _, err := stub.Call("DrawFromPool", "pool1", "OTHRAU2S", "10000"); err == nil || !strings.Contains(err.Error(), "exceeds drawing limit") {
+		t.Errorf("Expected a draw beyond the limit refused, got %v", err)
+	}
+	if _, err := stub.Call("WithdrawFromPool", "pool1", "FINNAU2S", "50000"); err == nil || !strings.Contains(err.Error(), "Insufficient undrawn funds") {
+		t.Errorf("Expected a withdrawal of drawn funds refused, got %v", err)
+	}
+	if contributor, borrower := balanceOf(t, stub, "9001", "1"), balanceOf(t, stub, "9002", "1"); contributor != 50000 || borrower != 15000 {
+		t.Errorf("Expected 50000 contributed and 15000 drawn, got balances of %d and %d", contributor, borrower)
+	}
+	if position := treasuryPosition(t, stub, "OTHRAU2S"); position.PoolDrawn != 15000 {
+		t.Errorf("Expected the draw in the borrower's treasury, got %+v", position)
+	}
+
+	stub.MustCall(t, "RepayPool", "pool1", "OTHRAU2S", "15000")
+	stub.MustCall(t, "WithdrawFromPool", "pool1", "FINNAU2S", "50000")
+	position := new(model.PoolPosition)
+	if err := json.Unmarshal(stub.MustCall(t, "GetPoolPosition", "pool1"), position); err != nil {
+		t.Fatal(err)
+	}
+	if position.Available != 0 || position.TotalContributed != 0 || position.TotalDrawn != 0 || len(position.Members) != 2 {
+		t.Errorf("Expected the pool emptied, got %+v", position)
+	}
+	if contributor, borrower := balanceOf(t, stub, "9001", "1"), balanceOf(t, stub, "9002", "1"); contributor != 100000 || borrower != 0 {
+		t.Errorf("Expected the contribution returned and the draw repaid, got balances of %d and %d", contributor, borrower)
+	}
+	if position := treasuryPosition(t, stub, "OTHRAU2S"); position.PoolDrawn != 0 {
+		t.Errorf("Expected the repaid draw cleared from the treasury, got %+v", position)
+	}
+}
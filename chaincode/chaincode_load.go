@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+const (
+	// loadTestEnv enables GenerateLoad when set to "enabled" in the chaincode container environment
+	loadTestEnv = "FINNET_LOAD_TEST"
+	// productionChannelsEnv lists channels, comma separated, on which GenerateLoad is always refused
+	productionChannelsEnv = "FINNET_PRODUCTION_CHANNELS"
+	// loadHotKey is the shared key touched by the hot_key fault
+	loadHotKey = "LoadHotKey"
+)
+
+//------------------------------
+// Load testing handler functions
+//------------------------------
+
+// GenerateLoad creates synthetic accounts and randomized transfers, with optional
+// fault injection, for benchmarking. It is refused unless the load test feature
+// flag is set and the channel is not a production channel.
+func (cc *Chaincode) GenerateLoad(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if err := loadTestAllowed(stub); err != nil {
+		return nil, err
+	}
+	spec, err := model.CreateLoadSpec([]byte(optionalArg(args, 0)))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating load spec. Error: %s", err)
+	}
+	if spec.Seed == 0 {
+		h := fnv.New64a()
+		h.Write([]byte(stub.GetTxID()))
+		spec.Seed = int64(h.Sum64())
+	}
+	rnd := rand.New(rand.NewSource(spec.Seed))
+	report := model.CreateLoadReport()
+
+	accounts := make([]*model.Account, spec.Accounts)
+	for i := range accounts {
+		a := &model.Account{
+			Entity:        model.Entity{ObjectType: model.AccountObjectType},
+			ID:            "1",
+			CustomerID:    spec.Prefix + "-" + strconv.Itoa(i),
+			AccountHolder: "Load test " + strconv.Itoa(i),
+			CurrencyCode:  spec.CurrencyCode,
+			Balance:       spec.InitialBalance,
+		}
+		if len(spec.CountryCodes) > 0 {
+			a.CountryCode = spec.CountryCodes[i%len(spec.CountryCodes)]
+		}
+		if _, err := cc.putAccount(stub, a); err != nil {
+			return nil, err
+		}
+		accounts[i] = a
+	}
+	report.Accounts = len(accounts)
+
+	for i := 0; i < spec.Transfers; i++ {
+		from := accounts[rnd.Intn(len(accounts))]
+		to := accounts[rnd.Intn(len(accounts))]
+		for to == from {
+			to = accounts[rnd.Intn(len(accounts))]
+		}
+		t := &model.Transfer{
+			FromCustomerID: from.CustomerID,
+			FromAccountID:  from.ID,
+			ToCustomerID:   to.CustomerID,
+			ToAccountID:    to.ID,
+			Amount:         1 + rnd.Int63n(spec.MaxAmount),
+			CurrencyCode:   spec.CurrencyCode,
+			Description:    "Synthetic load",
+			Params:         map[string]string{"load_seed": strconv.FormatInt(spec.Seed, 10)},
+		}
+		if len(spec.Faults) > 0 && rnd.Intn(100) < spec.FaultRate {
+			fault := spec.Faults[rnd.Intn(len(spec.Faults))]
+			if err := cc.injectFault(stub, fault, t); err != nil {
+				return nil, err
+			}
+			report.Faults[fault]++
+		}
+		report.Transfers++
+		if _, err := cc.executeTransfer(stub, t); err != nil {
+			report.Failed++
+			report.Failures[err.Error()]++
+			continue
+		}
+		report.Settled++
+	}
+	return json.Marshal(report)
+}
+
+// injectFault prepares the given fault for the next synthetic transfer
+func (cc *Chaincode) injectFault(stub shim.ChaincodeStubInterface, fault model.LoadFault, t *model.Transfer) error {
+	switch fault {
+	case model.FaultInsufficientFunds:
+		from, err := cc.getAccountStruct(stub, t.FromCustomerID, t.FromAccountID)
+		if err != nil {
+			return err
+		}
+		t.Amount = from.Balance + 1
+	case model.FaultClosedAccount:
+		to, err := cc.getAccountStruct(stub, t.ToCustomerID, t.ToAccountID)
+		if err != nil {
+			return err
+		}
+		to.Status = model.AccountClosed
+		_, err = cc.putAccount(stub, to)
+		return err
+	case model.FaultHotKey:
+		counter, err := stub.GetState(loadHotKey)
+		if err != nil {
+			return err
+		}
+		n, _ := strconv.Atoi(string(counter))
+		return stub.PutState(loadHotKey, []byte(strconv.Itoa(n+1)))
+	}
+	return nil
+}
+
+// loadTestAllowed checks the load test feature flag and refuses production channels
+func loadTestAllowed(stub shim.ChaincodeStubInterface) error {
+	if os.Getenv(loadTestEnv) != "enabled" {
+		return errors.New("Load generation is disabled on this deployment")
+	}
+	channel := stub.GetChannelID()
+	if channel == "" {
+		return errors.New("Load generation requires a known channel")
+	}
+	for _, prod := range strings.Split(os.Getenv(productionChannelsEnv), ",") {
+		if strings.TrimSpace(prod) == channel {
+			return fmt.Errorf("Load generation is not allowed on production channel %s", channel)
+		}
+	}
+	return nil
+}
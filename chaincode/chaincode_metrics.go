@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//---------------------------
+// Metrics handler functions
+//---------------------------
+
+// GetMetrics query the invocation, error and latency counts of the handler
+// functions in the chaincode container of the peer answering the query.
+// Takes an optional format, "json" (the default) or "prometheus" for the
+// Prometheus text exposition format.
+func (cc *Chaincode) GetMetrics(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 1 {
+		return nil, errors.New("Too many arguments, expected optional format")
+	}
+	snapshot := metrics.snapshot()
+	switch format := optionalArg(args, 0); format {
+	case "", "json":
+		return json.Marshal(snapshot)
+	case "prometheus":
+		return prometheusText(snapshot), nil
+	default:
+		return nil, fmt.Errorf("Invalid metrics format %s, expected json or prometheus", format)
+	}
+}
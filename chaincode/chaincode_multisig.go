@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Multi-signature account handler functions
+//------------------------------
+
+// SetAccountSigners configures the M-of-N signer identities of a corporate account.
+// The first configuration is made by a caller authorized to transfer from the
+// account; once it has signers, only one of them may change the configuration.
+func (cc *Chaincode) SetAccountSigners(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 {
+		return nil, errors.New("Missing required customer ID, account ID, signers JSON and / or required signatures")
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if account.IsMultiSig() {
+		if err := cc.requireSigner(stub, account); err != nil {
+			return nil, err
+		}
+	} else if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	var signers []string
+	if err := json.Unmarshal([]byte(args[2]), &signers); err != nil {
+		return nil, fmt.Errorf("Error parsing signers JSON. Error: %s", err)
+	}
+	quorum, err := strconv.Atoi(args[3])
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing required signatures value %s", args[3])
+	}
+	if err := account.SetSigners(signers, quorum); err != nil {
+		return nil, err
+	}
+	accountData, err := cc.putAccount(stub, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return accountData, nil
+}
+
+// ApproveOutgoingTransfer records the calling signer's approval of a pending
+// transfer and settles it once the account's quorum is met
+func (cc *Chaincode) ApproveOutgoingTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required transfer ID")
+	}
+	outgoing, err := cc.getOutgoingTransfer(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, outgoing.Transfer.FromCustomerID, outgoing.Transfer.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.requireSigner(stub, account); err != nil {
+		return nil, err
+	}
+	return cc.approveOutgoingTransfer(stub, account, outgoing)
+}
+
+// GetOutgoingTransfer query an approval-gated transfer by ID
+func (cc *Chaincode) GetOutgoingTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required transfer ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.OutgoingTransferObjectType, []string{args[0]})
+	return stub.GetState(key)
+}
+
+// proposeOutgoingTransfer parks a transfer from a multi-signature account until
+// its quorum approves. The initiator's approval counts if they are a signer.
+func (cc *Chaincode) proposeOutgoingTransfer(stub shim.ChaincodeStubInterface, account *model.Account, t *model.Transfer) ([]byte, error) {
+	outgoing := model.CreateOutgoingTransfer(t, account.Quorum, txContext(stub))
+	if err := cc.requireSigner(stub, account); err != nil {
+		return cc.putOutgoingTransfer(stub, outgoing)
+	}
+	return cc.approveOutgoingTransfer(stub, account, outgoing)
+}
+
+// approveOutgoingTransfer adds the caller's approval and settles the transfer
+// once the current signers of the account reach quorum
+func (cc *Chaincode) approveOutgoingTransfer(stub shim.ChaincodeStubInterface, account *model.Account, outgoing *model.OutgoingTransfer) ([]byte, error) {
+	signer, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := outgoing.Approve(signer, txContext(stub)); err != nil {
+		return nil, err
+	}
+	// the signers may have changed since the transfer was proposed
+	outgoing.Quorum = account.Quorum
+	if outgoing.QuorumMet(account) {
+		if _, err := cc.executeTransfer(stub, outgoing.Transfer); err != nil {
+			return nil, err
+		}
+		outgoing.Status = model.ApprovalSettled
+	}
+	return cc.putOutgoingTransfer(stub, outgoing)
+}
+
+func (cc *Chaincode) requireSigner(stub shim.ChaincodeStubInterface, account *model.Account) error {
+	signer, err := callerID(stub)
+	if err != nil {
+		return err
+	}
+	if !account.IsSigner(signer) {
+		return fmt.Errorf("Caller is not an authorized signer on account %s", account.ID)
+	}
+	return nil
+}
+
+func (cc *Chaincode) getOutgoingTransfer(stub shim.ChaincodeStubInterface, transferID string) (*model.OutgoingTransfer, error) {
+	key, _ := cc.createCompositeKey(stub, model.OutgoingTransferObjectType, []string{transferID})
+	outgoingBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if outgoingBytes == nil {
+		return nil, fmt.Errorf("Outgoing transfer %s not found.", transferID)
+	}
+	outgoing := new(model.OutgoingTransfer)
+	if err := bytesToStruct(outgoingBytes, outgoing); err != nil {
+		return nil, err
+	}
+	return outgoing, nil
+}
+
+func (cc *Chaincode) putOutgoingTransfer(stub shim.ChaincodeStubInterface, outgoing *model.OutgoingTransfer) ([]byte, error) {
+	outgoingData, err := json.Marshal(outgoing)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling outgoing transfer data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, outgoing.GetObjectType(), []string{outgoing.ID})
+	if err := stub.PutState(key, outgoingData); err != nil {
+		return nil, err
+	}
+	return outgoingData, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// signer returns an identity acting for the customer under its own name, and
+// its ID as listed in the signers of an account
+func signer(t *testing.T, stub *testsupport.Stub, customerID string, name string) (*testsupport.Identity, string) {
+	t.Helper()
+	identity, err := testsupport.NewIdentity(testsupport.DefaultMSPID, name, map[string]string{
+		auth.RoleAttribute:     RoleCustomer,
+		auth.CustomerAttribute: customerID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := stub.As(identity).Invoke(func(stub shim.ChaincodeStubInterface) ([]byte, error) {
+		id, err := callerID(stub)
+		return []byte(id), err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identity, string(id)
+}
+
+func TestApprovalsOfRemovedSignersStopCounting(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 5000)
+	alice, aliceID := signer(t, stub, "1001", "alice")
+	bob, bobID := signer(t, stub, "1001", "bob")
+	carol, carolID := signer(t, stub, "1001", "carol")
+	signers, _ := json.Marshal([]string{aliceID, bobID, carolID})
+	stub.As(alice).MustCall(t, "SetAccountSigners", "1001", "1", string(signers), "2")
+
+	outgoing := new(model.OutgoingTransfer)
+	if err := json.Unmarshal(stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON()), outgoing); err != nil {
+		t.Fatal(err)
+	}
+	signers, _ = json.Marshal([]string{bobID, carolID})
+	stub.As(bob).MustCall(t, "SetAccountSigners", "1001", "1", string(signers), "2")
+
+	stub.As(carol).MustCall(t, "ApproveOutgoingTransfer", outgoing.ID)
+	if balance := balanceOf(t, stub, "1001", "1"); balance != 5000 {
+		t.Errorf("Expected the approval of the removed signer not counted, got a balance of %d", balance)
+	}
+	if err := json.Unmarshal(stub.As(bob).MustCall(t, "ApproveOutgoingTransfer", outgoing.ID), outgoing); err != nil {
+		t.Fatal(err)
+	}
+	if outgoing.Status != model.ApprovalSettled || balanceOf(t, stub, "1001", "1") != 4000 {
+		t.Errorf("Expected the transfer settled by the current signers, got %+v", outgoing)
+	}
+}
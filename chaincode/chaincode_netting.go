@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Netting handler functions
+//------------------------------
+
+// RunNetting nets the pending interbank obligations of a value date into a
+// settlement batch with each participant's net position per currency, settles
+// the batch and marks the obligations netted. Restricted to settlement agents.
+func (cc *Chaincode) RunNetting(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required value date")
+	}
+	valueDate := args[0]
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.InterbankObligationObjectType, []string{valueDate})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get interbank obligations. Error: %s", err)
+		return nil, err
+	}
+	var obligations []*model.InterbankObligation
+	for keysIter.HasNext() {
+		obligation := new(model.InterbankObligation)
+		if err := json.Unmarshal(nextValue(keysIter), obligation); err != nil {
+			loggerFor(stub).Errorf("Failed to get interbank obligation details. Error: %s", err)
+			continue
+		}
+		obligations = append(obligations, obligation)
+	}
+	runBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := model.CreateSettlementBatch(stub.GetTxID(), valueDate, obligations, runBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	if len(batch.ObligationIDs) == 0 {
+		return nil, fmt.Errorf("No pending interbank obligations for value date %s", valueDate)
+	}
+	for _, obligation := range obligations {
+		if obligation.BatchID == batch.ID {
+			if err := cc.putObligation(stub, obligation); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := cc.settleBatch(stub, batch, obligations); err != nil {
+		return nil, err
+	}
+	batchData, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling settlement batch data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, batch.GetObjectType(), []string{batch.ValueDate, batch.ID})
+	if err := stub.PutState(key, batchData); err != nil {
+		return nil, err
+	}
+	return batchData, nil
+}
+
+// GetSettlementBatches query the settlement batches of a value date
+func (cc *Chaincode) GetSettlementBatches(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required value date")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.SettlementBatchObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get settlement batches. Error: %s", err)
+		return nil, err
+	}
+	list := model.SettlementBatchList{Batches: []*model.SettlementBatch{}}
+	for keysIter.HasNext() {
+		batch := new(model.SettlementBatch)
+		if err := json.Unmarshal(nextValue(keysIter), batch); err != nil {
+			loggerFor(stub).Errorf("Failed to get settlement batch details. Error: %s", err)
+			continue
+		}
+		list.Batches = append(list.Batches, batch)
+	}
+	return json.Marshal(list)
+}
+
+// settleBatch moves the net amount each pair of banks owes in a currency
+// between their settlement accounts, so a batch settles one payment per pair
+// rather than one per transfer, and releases the netted obligations from the
+// banks' treasury pending amounts. Pairs without a settlement account in the
+// currency on both sides settle outside the ledger.
+func (cc *Chaincode) settleBatch(stub shim.ChaincodeStubInterface, batch *model.SettlementBatch, obligations []*model.InterbankObligation) error {
+	net := map[[3]string]int64{}
+	var pairs [][3]string
+	for _, obligation := range obligations {
+		if obligation.BatchID != batch.ID {
+			continue
+		}
+		pair, amount := [3]string{obligation.FromBankID, obligation.ToBankID, obligation.CurrencyCode}, obligation.Amount
+		if obligation.ToBankID < obligation.FromBankID {
+			pair, amount = [3]string{obligation.ToBankID, obligation.FromBankID, obligation.CurrencyCode}, -obligation.Amount
+		}
+		if _, ok := net[pair]; !ok {
+			pairs = append(pairs, pair)
+		}
+		net[pair] += amount
+		if err := cc.releaseInterbankFlow(stub, obligation.FromBankID, obligation.ToBankID, obligation.CurrencyCode, obligation.Amount); err != nil {
+			return err
+		}
+	}
+	for _, pair := range pairs {
+		fromBankID, toBankID, amount := pair[0], pair[1], net[pair]
+		if amount == 0 {
+			continue
+		}
+		if amount < 0 {
+			fromBankID, toBankID, amount = toBankID, fromBankID, -amount
+		}
+		fromBank, err := cc.mustGetBank(stub, fromBankID)
+		if err != nil {
+			return err
+		}
+		toBank, err := cc.mustGetBank(stub, toBankID)
+		if err != nil {
+			return err
+		}
+		nostro, vostro := fromBank.SettlementAccountFor(pair[2]), toBank.SettlementAccountFor(pair[2])
+		if nostro == nil || vostro == nil {
+			continue
+		}
+		if err := cc.moveSettlementFunds(stub, nostro, vostro, batch, pair[2], amount); err != nil {
+			return fmt.Errorf("Error settling %d %s from bank %s to %s. Error: %s", amount, pair[2], fromBankID, toBankID, err)
+		}
+	}
+	return nil
+}
+
+// recordObligation records what the paying bank owes the receiving bank for a
+// transfer until the netting run of its value date
+func (cc *Chaincode) recordObligation(stub shim.ChaincodeStubInterface, fromBankID string, toBankID string, t *model.Transfer) error {
+	obligation, err := model.CreateInterbankObligation(fromBankID, toBankID, t, txContext(stub))
+	if err != nil {
+		return err
+	}
+	return cc.putObligation(stub, obligation)
+}
+
+func (cc *Chaincode) putObligation(stub shim.ChaincodeStubInterface, obligation *model.InterbankObligation) error {
+	obligationData, err := json.Marshal(obligation)
+	if err != nil {
+		return fmt.Errorf("Error marshalling interbank obligation data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, obligation.GetObjectType(), []string{obligation.ValueDate, obligation.ID})
+	return stub.PutState(key, obligationData)
+}
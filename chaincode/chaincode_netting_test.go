@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// settlementBank returns the bank data JSON of a bank of the default MSP
+// settling AUD through the account of its customer
+func settlementBank(bic string, customerID string) string {
+	return `{"bic":"` + bic + `","name":"Bank ` + bic + `","msp_id":"` + testsupport.DefaultMSPID + `","settlement_accounts":{"AUD":{"customer_id":"` + customerID + `","account_id":"1"}}}`
+}
+
+// treasuryPosition returns the AUD treasury position of a bank
+func treasuryPosition(t *testing.T, stub *testsupport.Stub, bic string) *model.CurrencyPosition {
+	t.Helper()
+	view := new(model.TreasuryView)
+	if err := json.Unmarshal(stub.MustCall(t, "GetTreasuryPosition", bic), view); err != nil {
+		t.Fatal(err)
+	}
+	for _, position := range view.Positions {
+		if position.CurrencyCode == "AUD" {
+			return position
+		}
+	}
+	return &model.CurrencyPosition{CurrencyCode: "AUD"}
+}
+
+func TestRunNettingSettlesNetBetweenSettlementAccounts(t *testing.T) {
+	stub := newTestStub()
+	stub.As(testsupport.Operator(t, RoleNetworkOperator))
+	stub.MustCall(t, "RegisterBank", settlementBank("FINNAU2S", "9001"))
+	stub.MustCall(t, "RegisterBank", settlementBank("OTHRAU2S", "9002"))
+	stub.OpenAccount(t, testsupport.NewAccount("9001", "1").AtBank("FINNAU2S"))
+	stub.OpenAccount(t, testsupport.NewAccount("9002", "1").AtBank("OTHRAU2S"))
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1").AtBank("FINNAU2S"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1").AtBank("OTHRAU2S"))
+	for _, customerID := range []string{"9001", "9002", "1001", "1002"} {
+		stub.Topup(t, customerID, "1", 100000)
+	}
+
+	stub.As(testsupport.Customer(t, "1001")).MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 30000).JSON())
+	stub.As(testsupport.Customer(t, "1002")).MustCall(t, "TransferMoney", testsupport.NewTransfer("1002", "1", "1001", "1", 10000).JSON())
+	if nostro, vostro := balanceOf(t, stub, "9001", "1"), balanceOf(t, stub, "9002", "1"); nostro != 100000 || vostro != 100000 {
+		t.Errorf("Expected the settlement accounts untouched before netting, got %d and %d", nostro, vostro)
+	}
+	if position := treasuryPosition(t, stub, "FINNAU2S"); position.PendingOut != 30000 || position.PendingIn != 10000 {
+		t.Errorf("Expected 30000 pending out and 10000 pending in, got %+v", position)
+	}
+
+	stub.As(testsupport.Operator(t, RoleSettlementAgent))
+	batch := new(model.SettlementBatch)
+	if err := json.Unmarshal(stub.MustCall(t, "RunNetting", "2021-03-01"), batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.ObligationIDs) != 2 || batch.Gross["AUD"] != 40000 || len(batch.Positions) != 2 || batch.Positions[0].BankID != "FINNAU2S" || batch.Positions[0].Net != -20000 {
+		t.Errorf("Expected two obligations netted to 20000 owed by FINNAU2S, got %+v", batch)
+	}
+	if nostro, vostro := balanceOf(t, stub, "9001", "1"), balanceOf(t, stub, "9002", "1"); nostro != 80000 || vostro != 120000 {
+		t.Errorf("Expected the net 20000 moved between the settlement accounts, got %d and %d", nostro, vostro)
+	}
+	for _, bic := range []string{"FINNAU2S", "OTHRAU2S"} {
+		if position := treasuryPosition(t, stub, bic); position.PendingOut != 0 || position.PendingIn != 0 {
+			t.Errorf("Expected the pending amounts of %s released, got %+v", bic, position)
+		}
+	}
+	if _, err := stub.Call("RunNetting", "2021-03-01"); err == nil || !strings.Contains(err.Error(), "No pending interbank obligations") {
+		t.Errorf("Expected the netted obligations left out of a second run, got %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// P2P handle handler functions
+//------------------------------
+
+// RegisterHandle claims a handle for one of the caller's accounts
+func (cc *Chaincode) RegisterHandle(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required handle data JSON")
+	}
+	handle, err := model.CreateHandle([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating handle. Error: %s", err)
+	}
+	existing, err := cc.getHandle(stub, handle.Handle)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Handle %s is already taken", handle.Handle)
+	}
+	account, err := cc.getAccountStruct(stub, handle.CustomerID, handle.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsClosed() {
+		return nil, fmt.Errorf("Cannot register a handle for closed account %s", account.ID)
+	}
+	if account.IsMultiSig() {
+		return nil, fmt.Errorf("Cannot register a handle for multi-signature account %s", account.ID)
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if handle.Owner, err = callerID(stub); err != nil {
+		return nil, err
+	}
+	return cc.putHandle(stub, handle)
+}
+
+// ResolveHandle query the display name registered for a handle
+func (cc *Chaincode) ResolveHandle(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required handle")
+	}
+	handle, err := cc.mustGetHandle(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(handle.View())
+}
+
+// P2PSend pays a small amount from the caller's handle to another handle once
+// the sender has confirmed the recipient's display name
+func (cc *Chaincode) P2PSend(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required P2P payment JSON")
+	}
+	payment := new(model.P2PPayment)
+	if err := bytesToStruct([]byte(args[0]), payment); err != nil {
+		return nil, err
+	}
+	if err := payment.Validate(); err != nil {
+		return nil, err
+	}
+	return cc.settleP2P(stub, payment, nil)
+}
+
+// RequestP2PPayment asks another handle to pay the caller's handle
+func (cc *Chaincode) RequestP2PPayment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required P2P request JSON")
+	}
+	request, err := model.CreateP2PRequest([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating P2P request. Error: %s", err)
+	}
+	if _, err := cc.ownedHandle(stub, request.RequesterHandle); err != nil {
+		return nil, err
+	}
+	if _, err := cc.mustGetHandle(stub, request.PayerHandle); err != nil {
+		return nil, err
+	}
+	return cc.putP2PRequest(stub, request)
+}
+
+// PayP2PRequest settles a pending request addressed to the caller's handle
+func (cc *Chaincode) PayP2PRequest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required payer handle, request ID and / or confirmed display name")
+	}
+	request, err := cc.pendingP2PRequest(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return cc.settleP2P(stub, request.Payment(args[2]), request)
+}
+
+// DeclineP2PRequest refuses a pending request addressed to the caller's handle
+func (cc *Chaincode) DeclineP2PRequest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required payer handle and / or request ID")
+	}
+	request, err := cc.pendingP2PRequest(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cc.ownedHandle(stub, request.PayerHandle); err != nil {
+		return nil, err
+	}
+	request.Status = model.P2PRequestDeclined
+	return cc.putP2PRequest(stub, request)
+}
+
+// GetP2PRequests query the payment requests addressed to a handle
+func (cc *Chaincode) GetP2PRequests(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required payer handle")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.P2PRequestObjectType, []string{model.NormalizeHandle(args[0])})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get P2P requests. Error: %s", err)
+		return nil, err
+	}
+	list := model.P2PRequestList{Requests: []*model.P2PRequest{}}
+	for keysIter.HasNext() {
+		requestBytes := nextValue(keysIter)
+		request := new(model.P2PRequest)
+		if err := json.Unmarshal(requestBytes, request); err != nil {
+			loggerFor(stub).Errorf("Failed to get P2P request details. Error: %s", err)
+			continue
+		}
+		list.Requests = append(list.Requests, request)
+	}
+	return json.Marshal(list)
+}
+
+// settleP2P resolves both handles, checks the caller owns the sending handle and
+// the confirmed display name, then settles through the regular transfer path
+// with the KYC and approval checks of TransferMoney
+func (cc *Chaincode) settleP2P(stub shim.ChaincodeStubInterface, payment *model.P2PPayment, request *model.P2PRequest) ([]byte, error) {
+	from, err := cc.ownedHandle(stub, payment.FromHandle)
+	if err != nil {
+		return nil, err
+	}
+	to, err := cc.mustGetHandle(stub, payment.ToHandle)
+	if err != nil {
+		return nil, err
+	}
+	if payment.ConfirmName != to.DisplayName {
+		return nil, fmt.Errorf("Confirmed name does not match the display name of %s", to.Handle)
+	}
+	// the handle owner may since have lost access to the account
+	account, err := cc.getAccountStruct(stub, from.CustomerID, from.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if err := cc.requireKYC(stub, account.CustomerID); err != nil {
+		return nil, err
+	}
+	params := map[string]string{"from_handle": from.Handle, "to_handle": to.Handle}
+	if request != nil {
+		params["p2p_request"] = request.ID
+	}
+	t := &model.Transfer{
+		FromCustomerID: from.CustomerID,
+		FromAccountID:  from.AccountID,
+		ToCustomerID:   to.CustomerID,
+		ToAccountID:    to.AccountID,
+		Amount:         payment.Amount,
+		CurrencyCode:   payment.CurrencyCode,
+		Description:    payment.Note,
+		Params:         params,
+		Initiated:      txContext(stub).Time.Unix(),
+	}
+	// a payment held for approval leaves the request pending
+	if queued, err := cc.queueForApproval(stub, t); err != nil || queued != nil {
+		return queued, err
+	}
+	if _, err := cc.executeTransfer(stub, t); err != nil {
+		return nil, err
+	}
+	if request != nil {
+		request.Status = model.P2PRequestPaid
+		return cc.putP2PRequest(stub, request)
+	}
+	return json.Marshal(payment)
+}
+
+// ownedHandle returns the handle if it was registered by the caller
+func (cc *Chaincode) ownedHandle(stub shim.ChaincodeStubInterface, name string) (*model.Handle, error) {
+	handle, err := cc.mustGetHandle(stub, name)
+	if err != nil {
+		return nil, err
+	}
+	caller, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if handle.Owner != caller {
+		return nil, fmt.Errorf("Caller does not own handle %s", handle.Handle)
+	}
+	return handle, nil
+}
+
+func (cc *Chaincode) pendingP2PRequest(stub shim.ChaincodeStubInterface, payerHandle string, requestID string) (*model.P2PRequest, error) {
+	key, _ := cc.createCompositeKey(stub, model.P2PRequestObjectType, []string{model.NormalizeHandle(payerHandle), requestID})
+	requestBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if requestBytes == nil {
+		return nil, fmt.Errorf("P2P request %s not found.", requestID)
+	}
+	request := new(model.P2PRequest)
+	if err := bytesToStruct(requestBytes, request); err != nil {
+		return nil, err
+	}
+	if request.Status != model.P2PRequestPending {
+		return nil, fmt.Errorf("P2P request %s is %s", request.ID, request.Status)
+	}
+	return request, nil
+}
+
+func (cc *Chaincode) putP2PRequest(stub shim.ChaincodeStubInterface, request *model.P2PRequest) ([]byte, error) {
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling P2P request data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, request.GetObjectType(), []string{request.PayerHandle, request.ID})
+	if err := stub.PutState(key, requestData); err != nil {
+		return nil, err
+	}
+	return requestData, nil
+}
+
+func (cc *Chaincode) getHandle(stub shim.ChaincodeStubInterface, name string) (*model.Handle, error) {
+	key, _ := cc.createCompositeKey(stub, model.HandleObjectType, []string{model.NormalizeHandle(name)})
+	handleBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get handle details. Error: %s", err)
+		return nil, err
+	}
+	if handleBytes == nil {
+		return nil, nil
+	}
+	handle := new(model.Handle)
+	if err := bytesToStruct(handleBytes, handle); err != nil {
+		return nil, err
+	}
+	return handle, nil
+}
+
+func (cc *Chaincode) mustGetHandle(stub shim.ChaincodeStubInterface, name string) (*model.Handle, error) {
+	handle, err := cc.getHandle(stub, name)
+	if err != nil {
+		return nil, err
+	}
+	if handle == nil {
+		return nil, fmt.Errorf("Handle %s not found.", model.NormalizeHandle(name))
+	}
+	return handle, nil
+}
+
+func (cc *Chaincode) putHandle(stub shim.ChaincodeStubInterface, handle *model.Handle) ([]byte, error) {
+	handleData, err := json.Marshal(handle)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling handle data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, handle.GetObjectType(), []string{handle.Handle})
+	if err := stub.PutState(key, handleData); err != nil {
+		return nil, err
+	}
+	return json.Marshal(handle.View())
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestP2PPaymentsBetweenHandles(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+
+	// handle ownership is bound to the identity that registered it
+	alice, bob := testsupport.Customer(t, "1001"), testsupport.Customer(t, "1002")
+	stub.As(alice).MustCall(t, "RegisterHandle", `{"handle":"@Alice","customer_id":"1001","account_id":"1","display_name":"Alice"}`)
+	stub.As(bob).MustCall(t, "RegisterHandle", `{"handle":"bob","customer_id":"1002","account_id":"1","display_name":"Bob"}`)
+	if _, err := stub.Call("RegisterHandle", `{"handle":"alice","customer_id":"1002","account_id":"1","display_name":"Alice"}`); err == nil || !strings.Contains(err.Error(), "already taken") {
+		t.Errorf("Expected a taken handle refused, got %v", err)
+	}
+	view := new(model.HandleView)
+	if err := json.Unmarshal(stub.MustCall(t, "ResolveHandle", "@ALICE"), view); err != nil {
+		t.Fatal(err)
+	}
+	if view.Handle != "alice" || view.DisplayName != "Alice" {
+		t.Errorf("Expected the normalized handle resolved, got %+v", view)
+	}
+
+	stub.As(alice)
+	for _, c := range []struct {
+		payment, refused string
+	}{
+		{`{"from_handle":"alice","to_handle":"bob","amount":1000,"currency":"AUD","confirm_name":"Robert"}`, "Confirmed name does not match"},
+		{`{"from_handle":"alice","to_handle":"bob","amount":50001,"currency":"AUD","confirm_name":"Bob"}`, "exceeds limit"},
+		{`{"from_handle":"alice","to_handle":"alice","amount":1000,"currency":"AUD","confirm_name":"Alice"}`, "Cannot pay your own handle"},
+	} {
+		if _, err := stub.Call("P2PSend", c.payment); err == nil || !strings.Contains(err.Error(), c.refused) {
+			t.Errorf("Expected %q, got %v", c.refused, err)
+		}
+	}
+	stub.MustCall(t, "P2PSend", `{"from_handle":"alice","to_handle":"bob","amount":1000,"currency":"AUD","confirm_name":"Bob"}`)
+
+	paid, declined := new(model.P2PRequest), new(model.P2PRequest)
+	if err := json.Unmarshal(stub.As(bob).MustCall(t, "RequestP2PPayment", `{"requester_handle":"bob","payer_handle":"alice","amount":2000,"currency":"AUD","note":"Dinner"}`), paid); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(stub.MustCall(t, "RequestP2PPayment", `{"requester_handle":"bob","payer_handle":"alice","amount":3000,"currency":"AUD"}`), declined); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stub.Call("PayP2PRequest", "alice", paid.ID, "Bob"); err == nil || !strings.Contains(err.Error(), "Caller does not own handle alice") {
+		t.Errorf("Expected the requester refused as payer, got %v", err)
+	}
+	stub.As(alice).MustCall(t, "PayP2PRequest", "alice", paid.ID, "Bob")
+	stub.MustCall(t, "DeclineP2PRequest", "alice", declined.ID)
+	if _, err := stub.Call("PayP2PRequest", "alice", declined.ID, "Bob"); err == nil || !strings.Contains(err.Error(), "is declined") {
+		t.Errorf("Expected a declined request left unpaid, got %v", err)
+	}
+	if payer, payee := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); payer != 97000 || payee != 3000 {
+		t.Errorf("Expected the payment and the paid request settled, got balances of %d and %d", payer, payee)
+	}
+
+	list := new(model.P2PRequestList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetP2PRequests", "alice"), list); err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]model.P2PRequestStatus{}
+	for _, request := range list.Requests {
+		statuses[request.ID] = request.Status
+	}
+	if len(statuses) != 2 || statuses[paid.ID] != model.P2PRequestPaid || statuses[declined.ID] != model.P2PRequestDeclined {
+		t.Errorf("Expected one paid and one declined request, got %v", statuses)
+	}
+}
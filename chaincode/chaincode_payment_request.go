@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Payment request handler functions
+//------------------------------
+
+// CreatePaymentRequest asks a customer to pay an amount into one of the
+// payee's accounts
+func (cc *Chaincode) CreatePaymentRequest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required payment request data JSON")
+	}
+	requestedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	request, err := model.CreatePaymentRequest([]byte(args[0]), requestedBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	payee, err := cc.getAccountStruct(stub, request.PayeeCustomerID, request.PayeeAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.RequestPayment, payee); err != nil {
+		return nil, err
+	}
+	if payee.IsClosed() {
+		return nil, fmt.Errorf("Cannot request a payment into closed account %s", payee.ID)
+	}
+	if request.PayerCustomerID == request.PayeeCustomerID {
+		return nil, errors.New("Cannot request a payment from the payee customer")
+	}
+	key, _ := cc.createCompositeKey(stub, request.GetObjectType(), []string{request.PayerCustomerID, request.ID})
+	existing, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Payment request %s already exists", request.ID)
+	}
+	return cc.putPaymentRequest(stub, request)
+}
+
+// GetPaymentRequests query the payment requests addressed to a customer
+func (cc *Chaincode) GetPaymentRequests(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required payer customer ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.PaymentRequestObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get payment requests. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	list := model.PaymentRequestList{Requests: []*model.PaymentRequest{}}
+	for keysIter.HasNext() {
+		requestBytes := nextValue(keysIter)
+		request := new(model.PaymentRequest)
+		if err := json.Unmarshal(requestBytes, request); err != nil {
+			loggerFor(stub).Errorf("Failed to get payment request details. Error: %s", err)
+			continue
+		}
+		list.Requests = append(list.Requests, request)
+	}
+	return json.Marshal(list)
+}
+
+// PayRequest settles a pending payment request from one of the payer's
+// accounts through the regular transfer path and marks it paid with the
+// paying transaction
+func (cc *Chaincode) PayRequest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required payer customer ID, request ID and / or payer account ID")
+	}
+	request, err := cc.pendingPaymentRequest(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	fromAccount, err := cc.getAccountStruct(stub, request.PayerCustomerID, args[2])
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, fromAccount); err != nil {
+		return nil, err
+	}
+	if fromAccount.IsMultiSig() {
+		return nil, fmt.Errorf("Cannot pay a request from multi-signature account %s", fromAccount.ID)
+	}
+	if err := cc.requireKYC(stub, fromAccount.CustomerID); err != nil {
+		return nil, err
+	}
+	tx := txContext(stub)
+	t := request.Transfer(fromAccount.ID)
+	t.Initiated = tx.Time.Unix()
+	if _, err := cc.executeTransfer(stub, t); err != nil {
+		return nil, err
+	}
+	request.MarkPaid(fromAccount.ID, tx)
+	return cc.putPaymentRequest(stub, request)
+}
+
+// CancelPaymentRequest withdraws a pending payment request. Only the payee may
+// cancel it.
+func (cc *Chaincode) CancelPaymentRequest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required payer customer ID and / or request ID")
+	}
+	request, err := cc.pendingPaymentRequest(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.RequestPayment, &model.Account{CustomerID: request.PayeeCustomerID}); err != nil {
+		return nil, err
+	}
+	request.Status = model.PaymentRequestCancelled
+	return cc.putPaymentRequest(stub, request)
+}
+
+func (cc *Chaincode) pendingPaymentRequest(stub shim.ChaincodeStubInterface, payerCustomerID string, requestID string) (*model.PaymentRequest, error) {
+	key, _ := cc.createCompositeKey(stub, model.PaymentRequestObjectType, []string{payerCustomerID, requestID})
+	requestBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if requestBytes == nil {
+		return nil, fmt.Errorf("Payment request %s not found.", requestID)
+	}
+	request := new(model.PaymentRequest)
+	if err := bytesToStruct(requestBytes, request); err != nil {
+		return nil, err
+	}
+	if request.Status != model.PaymentRequestPending {
+		return nil, fmt.Errorf("Payment request %s is %s", request.ID, request.Status)
+	}
+	return request, nil
+}
+
+func (cc *Chaincode) putPaymentRequest(stub shim.ChaincodeStubInterface, request *model.PaymentRequest) ([]byte, error) {
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling payment request data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, request.GetObjectType(), []string{request.PayerCustomerID, request.ID})
+	if err := stub.PutState(key, requestData); err != nil {
+		return nil, err
+	}
+	return requestData, nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Payroll handler functions
+//------------------------------
+
+// CreatePayrollRun schedules a payroll run for an employer account
+func (cc *Chaincode) CreatePayrollRun(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required payroll run data JSON")
+	}
+	run, err := model.CreatePayrollRun([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating payroll run. Error: %s", err)
+	}
+	employer, err := cc.getAccountStruct(stub, run.EmployerCustomerID, run.EmployerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, employer); err != nil {
+		return nil, err
+	}
+	if employer.IsClosed() {
+		return nil, fmt.Errorf("Cannot schedule payroll from closed account %s", employer.ID)
+	}
+	if employer.IsMultiSig() {
+		if err := cc.requireSigner(stub, employer); err != nil {
+			return nil, err
+		}
+	}
+	if run.CurrencyCode == "" {
+		run.CurrencyCode = employer.CurrencyCode
+	}
+	if run.CurrencyCode != employer.CurrencyCode {
+		return nil, fmt.Errorf("Payroll currency %s does not match employer account currency %s", run.CurrencyCode, employer.CurrencyCode)
+	}
+	if run.PayDate < txContext(stub).Time.UTC().Format(model.PayDateFormat) {
+		return nil, fmt.Errorf("Pay date %s is in the past", run.PayDate)
+	}
+	existing, err := cc.getPayrollRun(stub, run.EmployerCustomerID, run.EmployerAccountID, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Payroll run %s already exists", run.ID)
+	}
+	return cc.putPayrollRun(stub, run)
+}
+
+// ExecutePayrollRun pays all employees of a scheduled run in a single
+// transaction once the pay date is reached and the employer account is funded
+func (cc *Chaincode) ExecutePayrollRun(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	run, err := cc.payrollRunArgs(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != model.PayrollScheduled {
+		return nil, fmt.Errorf("Payroll run %s is %s", run.ID, run.Status)
+	}
+	now := txContext(stub).Time
+	if !run.Due(now) {
+		return nil, fmt.Errorf("Payroll run %s is not due before %s", run.ID, run.PayDate)
+	}
+	employer, err := cc.getAccountStruct(stub, run.EmployerCustomerID, run.EmployerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, employer); err != nil {
+		return nil, err
+	}
+	if employer.IsMultiSig() {
+		if err := cc.requireSigner(stub, employer); err != nil {
+			return nil, err
+		}
+	}
+	if employer.Unheld() < run.Total {
+		return nil, fmt.Errorf("Insufficient funds in account %s for payroll total %d", employer.ID, run.Total)
+	}
+	params := map[string]string{"payroll_run": run.ID}
+	if err := cc.disburse(stub, employer, run.Disbursements(), params); err != nil {
+		return nil, fmt.Errorf("Payroll run %s failed. Error: %s", run.ID, err)
+	}
+	run.Status = model.PayrollExecuted
+	run.Executed = now.Unix()
+	run.TxID = stub.GetTxID()
+	if _, err := cc.putPayrollRun(stub, run); err != nil {
+		return nil, err
+	}
+	return json.Marshal(run.Report())
+}
+
+// CancelPayrollRun withdraws a scheduled payroll run
+func (cc *Chaincode) CancelPayrollRun(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	run, err := cc.payrollRunArgs(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != model.PayrollScheduled {
+		return nil, fmt.Errorf("Payroll run %s is %s", run.ID, run.Status)
+	}
+	employer, err := cc.getAccountStruct(stub, run.EmployerCustomerID, run.EmployerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, employer); err != nil {
+		return nil, err
+	}
+	run.Status = model.PayrollCancelled
+	return cc.putPayrollRun(stub, run)
+}
+
+// GetPayrollReport query the report of a payroll run
+func (cc *Chaincode) GetPayrollReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	run, err := cc.payrollRunArgs(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(run.Report())
+}
+
+// GetPayrollHistory query all payroll runs of an employer account
+func (cc *Chaincode) GetPayrollHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required employer customer ID and / or account ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.PayrollRunObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get payroll history. Error: %s", err)
+		return nil, err
+	}
+	history := model.PayrollRunList{}
+	for keysIter.HasNext() {
+		runBytes := nextValue(keysIter)
+		run := new(model.PayrollRun)
+		if err := json.Unmarshal(runBytes, run); err != nil {
+			loggerFor(stub).Errorf("Failed to get payroll run details. Error: %s", err)
+			continue
+		}
+		history.Runs = append(history.Runs, run)
+	}
+	return json.Marshal(history)
+}
+
+func (cc *Chaincode) payrollRunArgs(stub shim.ChaincodeStubInterface, args []string) (*model.PayrollRun, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required employer customer ID, account ID and / or payroll run ID")
+	}
+	run, err := cc.getPayrollRun(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, fmt.Errorf("Payroll run %s not found.", args[2])
+	}
+	return run, nil
+}
+
+func (cc *Chaincode) getPayrollRun(stub shim.ChaincodeStubInterface, customerID string, accountID string, runID string) (*model.PayrollRun, error) {
+	key, _ := cc.createCompositeKey(stub, model.PayrollRunObjectType, []string{customerID, accountID, runID})
+	runBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get payroll run details. Error: %s", err)
+		return nil, err
+	}
+	if runBytes == nil {
+		return nil, nil
+	}
+	run := new(model.PayrollRun)
+	if err := bytesToStruct(runBytes, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (cc *Chaincode) putPayrollRun(stub shim.ChaincodeStubInterface, run *model.PayrollRun) ([]byte, error) {
+	runData, err := json.Marshal(run)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling payroll run data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, run.GetObjectType(), []string{run.EmployerCustomerID, run.EmployerAccountID, run.ID})
+	if err := stub.PutState(key, runData); err != nil {
+		return nil, err
+	}
+	return runData, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestPayrollRunPaysEmployeesOnPayDate(t *testing.T) {
+	stub := newTestStub()
+	for _, customerID := range []string{"1001", "1002", "1003"} {
+		stub.OpenAccount(t, testsupport.NewAccount(customerID, "1"))
+	}
+	stub.Topup(t, "1001", "1", 10000)
+
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "CreatePayrollRun", `{"id":"run1","employer_customer":"1001","employer_account":"1","pay_date":"2021-03-02","items":[{"employee_id":"e1","customer_id":"1002","account_id":"1","amount":3000},{"employee_id":"e2","customer_id":"1003","account_id":"1","amount":4000}]}`)
+	if _, err := stub.Call("ExecutePayrollRun", "1001", "1", "run1"); err == nil || !strings.Contains(err.Error(), "is not due") {
+		t.Errorf("Expected the run held until its pay date, got %v", err)
+	}
+
+	stub.Advance(24 * time.Hour)
+	report := new(model.PayrollReport)
+	if err := json.Unmarshal(stub.MustCall(t, "ExecutePayrollRun", "1001", "1", "run1"), report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != model.PayrollExecuted || report.EmployeeCount != 2 || report.Total != 7000 || report.TxID != stub.LastTxID() {
+		t.Errorf("Expected both employees paid in one transaction, got %+v", report)
+	}
+	for customerID, expected := range map[string]int64{"1001": 3000, "1002": 3000, "1003": 4000} {
+		if balance := balanceOf(t, stub, customerID, "1"); balance != expected {
+			t.Errorf("Expected customer %s at %d, got %d", customerID, expected, balance)
+		}
+	}
+	if _, err := stub.Call("ExecutePayrollRun", "1001", "1", "run1"); err == nil || !strings.Contains(err.Error(), "is executed") {
+		t.Errorf("Expected an executed run not paid twice, got %v", err)
+	}
+
+	if _, err := stub.Call("CreatePayrollRun", `{"id":"run2","employer_customer":"1001","employer_account":"1","pay_date":"2021-03-01","items":[{"employee_id":"e1","customer_id":"1002","account_id":"1","amount":3000}]}`); err == nil || !strings.Contains(err.Error(), "in the past") {
+		t.Errorf("Expected a pay date in the past refused, got %v", err)
+	}
+	stub.MustCall(t, "CreatePayrollRun", `{"id":"run2","employer_customer":"1001","employer_account":"1","pay_date":"2021-03-02","items":[{"employee_id":"e1","customer_id":"1002","account_id":"1","amount":5000}]}`)
+	if _, err := stub.Call("ExecutePayrollRun", "1001", "1", "run2"); err == nil || !strings.Contains(err.Error(), "Insufficient funds") {
+		t.Errorf("Expected an unfunded run refused, got %v", err)
+	}
+	stub.MustCall(t, "CancelPayrollRun", "1001", "1", "run2")
+
+	history := new(model.PayrollRunList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetPayrollHistory", "1001", "1"), history); err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Runs) != 2 || history.Runs[0].Status != model.PayrollExecuted || history.Runs[1].Status != model.PayrollCancelled {
+		t.Errorf("Expected the executed and the cancelled run, got %+v", history.Runs)
+	}
+}
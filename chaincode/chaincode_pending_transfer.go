@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Pending transfer handler functions
+//------------------------------
+
+// InitiateTransfer starts a two-phase transfer, such as a cross-border payment
+// that is not settled instantly. The amount and fee are debited from the payer
+// and held until the transfer is settled or rejected.
+func (cc *Chaincode) InitiateTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing transfer details JSON")
+	}
+	t := new(model.Transfer)
+	if err := model.Unmarshal([]byte(args[0]), t); err != nil {
+		return nil, err
+	}
+	t.Initiated = txContext(stub).Time.Unix()
+	fromAccount, err := cc.getAccountStruct(stub, t.FromCustomerID, t.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, fromAccount); err != nil {
+		return nil, err
+	}
+	if err := fromAccount.CheckVersion(t.PayerVersion); err != nil {
+		return nil, err
+	}
+	if err := cc.requireKYC(stub, fromAccount.CustomerID); err != nil {
+		return nil, err
+	}
+	if fromAccount.IsMultiSig() {
+		return nil, fmt.Errorf("Multi-signature account %s cannot initiate pending transfers", fromAccount.ID)
+	}
+	if !fromAccount.CanSend() {
+		return nil, fmt.Errorf("Cannot transfer money from %s account %s", fromAccount.Status, t.FromAccountID)
+	}
+	if fromAccount.CurrencyCode != t.CurrencyCode {
+		return nil, fmt.Errorf("Cannot transfer %s from account %s in %s", t.CurrencyCode, t.FromAccountID, fromAccount.CurrencyCode)
+	}
+	payeeStub, err := cc.payeeScope(stub, t.ToTenant)
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := cc.getAccountStruct(payeeStub, t.ToCustomerID, t.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !toAccount.CanReceive() {
+		return nil, fmt.Errorf("Cannot transfer money into closed account %s", t.ToAccountID)
+	}
+	if _, err := cc.activeBank(stub, fromAccount); err != nil {
+		return nil, err
+	}
+	if _, err := cc.activeBank(stub, toAccount); err != nil {
+		return nil, err
+	}
+	schedule, err := cc.feeScheduleFor(stub, t.CurrencyCode, fromAccount.CountryCode, toAccount.CountryCode)
+	if err != nil {
+		return nil, err
+	}
+	t.Fee = 0
+	if schedule != nil {
+		t.Fee = schedule.Fee(t.Amount)
+	}
+	held, err := t.Total()
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Available() < held.Amount {
+		return nil, fmt.Errorf("Insufficient funds available in account %s", t.FromAccountID)
+	}
+	initiator, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	pending := model.CreatePendingTransfer(stub.GetTxID(), t, held.Amount, initiator, txContext(stub))
+	if err := cc.debitAccount(stub, fromAccount, held); err != nil {
+		return nil, err
+	}
+	if err := emitEvent(stub, model.EventTransferInitiated, model.NewTransferEvent(t)); err != nil {
+		return nil, err
+	}
+	return cc.putPendingTransfer(stub, pending)
+}
+
+// SettleTransfer pays a pending transfer to the payee. The held funds are
+// returned to the payer and the transfer is executed as by TransferMoney, so
+// the fee, conversion and withholding in force at settlement apply. If the
+// transfer cannot be executed, it stays pending. Restricted to settlement agents.
+func (cc *Chaincode) SettleTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, account ID and / or transfer ID")
+	}
+	pending, err := cc.mustGetPendingTransfer(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	agent, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.releasePendingTransfer(stub, pending); err != nil {
+		return nil, err
+	}
+	t := pending.Transfer
+	if _, err := cc.executeTransfer(stub, &t); err != nil {
+		return nil, fmt.Errorf("Pending transfer %s cannot be settled. Error: %s", pending.ID, err)
+	}
+	pending.Resolve(model.TransferSettled, "", agent, txContext(stub))
+	return cc.putPendingTransfer(stub, pending)
+}
+
+// RejectTransfer returns the held funds of a pending transfer to the payer.
+// Restricted to settlement agents. A transfer held for approval is rejected
+// instead, restricted to transfer approvers.
+func (cc *Chaincode) RejectTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 {
+		return nil, errors.New("Missing required customer ID, account ID, transfer ID and / or reason")
+	}
+	approval, err := cc.getTransferApproval(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	if approval != nil {
+		return cc.rejectTransferApproval(stub, approval, args[3])
+	}
+	if err := cc.requireRole(stub, RoleSettlementAgent); err != nil {
+		return nil, err
+	}
+	pending, err := cc.mustGetPendingTransfer(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	agent, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.releasePendingTransfer(stub, pending); err != nil {
+		return nil, err
+	}
+	pending.Resolve(model.TransferRejected, args[3], agent, txContext(stub))
+	if err := emitTransferEvent(stub, &pending.Transfer, errors.New(args[3])); err != nil {
+		return nil, err
+	}
+	return cc.putPendingTransfer(stub, pending)
+}
+
+// GetPendingTransfers query the transfers of an account awaiting settlement
+func (cc *Chaincode) GetPendingTransfers(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.PendingTransferObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get pending transfers. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	list := model.PendingTransferList{Transfers: []*model.PendingTransfer{}}
+	for keysIter.HasNext() {
+		pendingBytes := nextValue(keysIter)
+		pending := new(model.PendingTransfer)
+		if err := json.Unmarshal(pendingBytes, pending); err != nil {
+			loggerFor(stub).Errorf("Failed to get pending transfer details. Error: %s", err)
+			continue
+		}
+		if pending.Status == model.TransferPending {
+			list.Transfers = append(list.Transfers, pending)
+		}
+	}
+	return json.Marshal(list)
+}
+
+// releasePendingTransfer returns the held funds to the payer account, which
+// may have been frozen or closed since
+func (cc *Chaincode) releasePendingTransfer(stub shim.ChaincodeStubInterface, pending *model.PendingTransfer) error {
+	if pending.Status != model.TransferPending {
+		return fmt.Errorf("Transfer %s is %s", pending.ID, pending.Status)
+	}
+	payer, err := cc.getAccountStruct(stub, pending.Transfer.FromCustomerID, pending.Transfer.FromAccountID)
+	if err != nil {
+		return err
+	}
+	return cc.creditAccount(stub, payer, payer.Money(pending.Held))
+}
+
+func (cc *Chaincode) mustGetPendingTransfer(stub shim.ChaincodeStubInterface, customerID string, accountID string, transferID string) (*model.PendingTransfer, error) {
+	key, _ := cc.createCompositeKey(stub, model.PendingTransferObjectType, []string{customerID, accountID, transferID})
+	pendingBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get pending transfer details. Error: %s", err)
+		return nil, err
+	}
+	if pendingBytes == nil {
+		return nil, fmt.Errorf("Pending transfer %s not found.", transferID)
+	}
+	pending := new(model.PendingTransfer)
+	if err := bytesToStruct(pendingBytes, pending); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+func (cc *Chaincode) putPendingTransfer(stub shim.ChaincodeStubInterface, pending *model.PendingTransfer) ([]byte, error) {
+	pendingData, err := json.Marshal(pending)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling pending transfer data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, pending.GetObjectType(), []string{pending.Transfer.FromCustomerID, pending.Transfer.FromAccountID, pending.ID})
+	if err := stub.PutState(key, pendingData); err != nil {
+		return nil, err
+	}
+	return pendingData, nil
+}
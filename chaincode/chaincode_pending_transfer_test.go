@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// initiateTransfer starts a pending transfer as the payer customer
+func initiateTransfer(t *testing.T, stub *testsupport.Stub, transfer *testsupport.TransferFixture) *model.PendingTransfer {
+	t.Helper()
+	pending := new(model.PendingTransfer)
+	if err := json.Unmarshal(stub.As(testsupport.Customer(t, transfer.FromCustomerID)).MustCall(t, "InitiateTransfer", transfer.JSON()), pending); err != nil {
+		t.Fatal(err)
+	}
+	return pending
+}
+
+func TestPendingTransfersHoldFundsUntilSettledOrRejected(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 5000)
+
+	settled := initiateTransfer(t, stub, testsupport.NewTransfer("1001", "1", "1002", "1", 1000))
+	rejected := initiateTransfer(t, stub, testsupport.NewTransfer("1001", "1", "1002", "1", 1500))
+	if payer, payee := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); payer != 2500 || payee != 0 {
+		t.Errorf("Expected the amounts debited from the payer and not yet credited, got balances of %d and %d", payer, payee)
+	}
+
+	stub.As(testsupport.Operator(t, RoleSettlementAgent))
+	stub.MustCall(t, "SettleTransfer", "1001", "1", settled.ID)
+	stub.MustCall(t, "RejectTransfer", "1001", "1", rejected.ID, "beneficiary bank unreachable")
+	if payer, payee := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); payer != 4000 || payee != 1000 {
+		t.Errorf("Expected the settled transfer paid and the rejected one returned, got balances of %d and %d", payer, payee)
+	}
+	if _, err := stub.Call("SettleTransfer", "1001", "1", rejected.ID); err == nil || !strings.Contains(err.Error(), "is rejected") {
+		t.Errorf("Expected a rejected transfer not settled, got %v", err)
+	}
+
+	list := new(model.PendingTransferList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetPendingTransfers", "1001", "1"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Transfers) != 0 {
+		t.Errorf("Expected no transfers pending, got %+v", list.Transfers)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Loyalty points handler functions
+//------------------------------
+
+// SetPointsProgram creates or replaces the loyalty program of a currency
+func (cc *Chaincode) SetPointsProgram(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required points program data JSON")
+	}
+	program, err := model.CreatePointsProgram([]byte(args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating points program. Error: %s", err)
+	}
+	funding, err := cc.getAccountStruct(stub, program.FundingCustomerID, program.FundingAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if funding.CurrencyCode != program.CurrencyCode {
+		return nil, fmt.Errorf("Funding account currency %s does not match program currency %s", funding.CurrencyCode, program.CurrencyCode)
+	}
+	programData, _ := json.Marshal(program)
+	key, _ := cc.createCompositeKey(stub, program.GetObjectType(), []string{program.CurrencyCode})
+	if err := stub.PutState(key, programData); err != nil {
+		return nil, err
+	}
+	return programData, nil
+}
+
+// GetPointsProgram query the loyalty program of a currency
+func (cc *Chaincode) GetPointsProgram(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	key, _ := cc.createCompositeKey(stub, model.PointsProgramObjectType, args)
+	return stub.GetState(key)
+}
+
+// GetPointsBalance query the loyalty points balance of a customer
+func (cc *Chaincode) GetPointsBalance(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required customer ID")
+	}
+	balance, err := cc.getPointsBalance(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(balance)
+}
+
+// RedeemPoints converts loyalty points into a credit on one of the customer's
+// accounts at the program's redemption rate, funded by the program account
+func (cc *Chaincode) RedeemPoints(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, account ID and / or points")
+	}
+	points, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing points value %s", args[2])
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	// redeeming spends the points of the account's customer
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if !account.CanReceive() {
+		return nil, fmt.Errorf("Cannot redeem points into closed account %s", account.ID)
+	}
+	program, err := cc.getPointsProgram(stub, account.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	if program == nil {
+		return nil, fmt.Errorf("No points program for currency %s", account.CurrencyCode)
+	}
+	funding, err := cc.getAccountStruct(stub, program.FundingCustomerID, program.FundingAccountID)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := cc.getPointsBalance(stub, account.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := balance.Redeem(points, txContext(stub)); err != nil {
+		return nil, err
+	}
+	credit, err := account.Money(program.RedemptionRate).Mul(points)
+	if err != nil {
+		return nil, fmt.Errorf("Error computing redemption of %d points. Error: %s", points, err)
+	}
+	if funding.Unheld()-credit.Amount < 0 {
+		return nil, fmt.Errorf("Insufficient funds available in points funding account %s", funding.ID)
+	}
+	t := &model.Transfer{
+		FromCustomerID: funding.CustomerID,
+		FromAccountID:  funding.ID,
+		ToCustomerID:   account.CustomerID,
+		ToAccountID:    account.ID,
+		Amount:         credit.Amount,
+		CurrencyCode:   account.CurrencyCode,
+		Description:    "Loyalty points redemption",
+		Params:         map[string]string{"points": strconv.FormatInt(points, 10)},
+	}
+	if err := cc.debitAccount(stub, funding, funding.Money(credit.Amount)); err != nil {
+		return nil, err
+	}
+	if err := cc.recordTransaction(stub, funding.CustomerID, funding.ID, t, "", model.Debited); err != nil {
+		return nil, err
+	}
+	if err := cc.creditAccount(stub, account, credit); err != nil {
+		return nil, err
+	}
+	if err := cc.recordTransaction(stub, account.CustomerID, account.ID, t, "", model.Credited); err != nil {
+		return nil, err
+	}
+	return cc.putPointsBalance(stub, balance)
+}
+
+// earnPoints credits the paying customer with the points a settled transfer earns
+func (cc *Chaincode) earnPoints(stub shim.ChaincodeStubInterface, customerID string, t *model.Transfer) error {
+	program, err := cc.getPointsProgram(stub, t.CurrencyCode)
+	if err != nil || program == nil {
+		return err
+	}
+	points := program.Earned(t.Amount, t.Fee)
+	if points <= 0 {
+		return nil
+	}
+	balance, err := cc.getPointsBalance(stub, customerID)
+	if err != nil {
+		return err
+	}
+	balance.Earn(points, txContext(stub))
+	_, err = cc.putPointsBalance(stub, balance)
+	return err
+}
+
+func (cc *Chaincode) getPointsProgram(stub shim.ChaincodeStubInterface, currency string) (*model.PointsProgram, error) {
+	key, _ := cc.createCompositeKey(stub, model.PointsProgramObjectType, []string{currency})
+	programBytes, err := stub.GetState(key)
+	if err != nil || programBytes == nil {
+		return nil, err
+	}
+	program := new(model.PointsProgram)
+	if err := bytesToStruct(programBytes, program); err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+func (cc *Chaincode) getPointsBalance(stub shim.ChaincodeStubInterface, customerID string) (*model.PointsBalance, error) {
+	key, _ := cc.createCompositeKey(stub, model.PointsBalanceObjectType, []string{customerID})
+	balanceBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if balanceBytes == nil {
+		return model.CreatePointsBalance(customerID), nil
+	}
+	balance := new(model.PointsBalance)
+	if err := bytesToStruct(balanceBytes, balance); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+func (cc *Chaincode) putPointsBalance(stub shim.ChaincodeStubInterface, balance *model.PointsBalance) ([]byte, error) {
+	balanceData, err := json.Marshal(balance)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling points balance data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, balance.GetObjectType(), []string{balance.CustomerID})
+	if err := stub.PutState(key, balanceData); err != nil {
+		return nil, err
+	}
+	return balanceData, nil
+}
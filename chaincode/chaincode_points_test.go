@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// pointsOf returns the loyalty points balance of a customer
+func pointsOf(t *testing.T, stub *testsupport.Stub, customerID string) *model.PointsBalance {
+	t.Helper()
+	balance := new(model.PointsBalance)
+	if err := json.Unmarshal(stub.MustCall(t, "GetPointsBalance", customerID), balance); err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+func TestPointsEarnedOnTransfersAndRedeemed(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("9000", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "9000", "1", 100000)
+	stub.Topup(t, "1001", "1", 100000)
+	admin := testsupport.Operator(t, RoleProductAdmin)
+	stub.As(admin).MustCall(t, "SetPointsProgram", `{"currency":"AUD","points_per_transfer_unit":1,"redemption_rate":10,"funding_customer":"9000","funding_account":"1"}`)
+
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 25000).JSON())
+	if points := pointsOf(t, stub, "1001"); points.Balance != 250 || points.Earned != 250 {
+		t.Errorf("Expected a point per dollar transferred, got %+v", points)
+	}
+	if points := pointsOf(t, stub, "1002"); points.Balance != 0 {
+		t.Errorf("Expected no points for the payee, got %+v", points)
+	}
+
+	stub.MustCall(t, "RedeemPoints", "1001", "1", "100")
+	if points := pointsOf(t, stub, "1001"); points.Balance != 150 || points.Redeemed != 100 {
+		t.Errorf("Expected 100 points redeemed, got %+v", points)
+	}
+	if customer, funding := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "9000", "1"); customer != 76000 || funding != 99000 {
+		t.Errorf("Expected 1000 credited from the funding account, got balances of %d and %d", customer, funding)
+	}
+	if _, err := stub.Call("RedeemPoints", "1001", "1", "151"); err == nil || !strings.Contains(err.Error(), "Insufficient points balance") {
+		t.Errorf("Expected a redemption beyond the balance refused, got %v", err)
+	}
+
+	stub.As(admin).MustCall(t, "SetPointsProgram", `{"currency":"AUD","redemption_rate":9223372036854775807,"funding_customer":"9000","funding_account":"1"}`)
+	if _, err := stub.As(testsupport.Customer(t, "1001")).Call("RedeemPoints", "1001", "1", "2"); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Errorf("Expected an overflowing redemption refused, got %v", err)
+	}
+	if points := pointsOf(t, stub, "1001"); points.Balance != 150 {
+		t.Errorf("Expected the refused redemption to keep the points, got %+v", points)
+	}
+}
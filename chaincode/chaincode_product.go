@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Product catalog handler functions
+//------------------------------
+
+// CreateProduct adds a product to the catalog. Restricted to product administrators.
+func (cc *Chaincode) CreateProduct(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required product data JSON")
+	}
+	product, err := cc.validProduct(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	existing, err := cc.getProduct(stub, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Product %s already exists", product.ID)
+	}
+	return cc.putProduct(stub, product)
+}
+
+// UpdateProduct replaces the definition of a product of the catalog. Accounts
+// already opened with it are not re-checked. Restricted to product administrators.
+func (cc *Chaincode) UpdateProduct(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required product data JSON")
+	}
+	product, err := cc.validProduct(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := cc.mustGetProduct(stub, product.ID); err != nil {
+		return nil, err
+	}
+	return cc.putProduct(stub, product)
+}
+
+// GetProduct query a product of the catalog
+func (cc *Chaincode) GetProduct(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required product ID")
+	}
+	product, err := cc.mustGetProduct(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(product)
+}
+
+// GetProductList query the product catalog
+func (cc *Chaincode) GetProductList(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.ProductObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get product list. Error: %s", err)
+		return nil, err
+	}
+	list := model.ProductList{Products: []*model.Product{}}
+	for keysIter.HasNext() {
+		product := new(model.Product)
+		if err := json.Unmarshal(nextValue(keysIter), product); err != nil {
+			loggerFor(stub).Errorf("Failed to get product details. Error: %s", err)
+			continue
+		}
+		list.Products = append(list.Products, product)
+	}
+	return json.Marshal(list)
+}
+
+// validProduct creates a product and checks the collection accounts of its
+// fee schedules exist in their currencies
+func (cc *Chaincode) validProduct(stub shim.ChaincodeStubInterface, productJSON string) (*model.Product, error) {
+	product, err := model.CreateProduct([]byte(productJSON), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating product. Error: %s", err)
+	}
+	for currency, schedule := range product.FeeSchedules {
+		collector, err := cc.getAccountStruct(stub, schedule.CollectionCustomerID, schedule.CollectionAccountID)
+		if err != nil {
+			return nil, err
+		}
+		if collector.CurrencyCode != currency {
+			return nil, fmt.Errorf("Fee collection account currency %s does not match %s", collector.CurrencyCode, currency)
+		}
+	}
+	return product, nil
+}
+
+// admitToProduct fails unless an account opened with a product meets its
+// constraints. Accounts without a product are not constrained.
+func (cc *Chaincode) admitToProduct(stub shim.ChaincodeStubInterface, account *model.Account) error {
+	if account.ProductID == "" {
+		return nil
+	}
+	product, err := cc.mustGetProduct(stub, account.ProductID)
+	if err != nil {
+		return err
+	}
+	return product.Admit(account)
+}
+
+// productFeeSchedule returns the fee schedule of the account's product in a
+// currency, or nil if the account has no product or the product no schedule
+func (cc *Chaincode) productFeeSchedule(stub shim.ChaincodeStubInterface, account *model.Account, currency string) (*model.FeeSchedule, error) {
+	if account.ProductID == "" {
+		return nil, nil
+	}
+	product, err := cc.getProduct(stub, account.ProductID)
+	if err != nil || product == nil {
+		return nil, err
+	}
+	return product.FeeSchedule(currency), nil
+}
+
+func (cc *Chaincode) getProduct(stub shim.ChaincodeStubInterface, productID string) (*model.Product, error) {
+	key, _ := cc.createCompositeKey(stub, model.ProductObjectType, []string{productID})
+	productBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get product details. Error: %s", err)
+		return nil, err
+	}
+	if productBytes == nil {
+		return nil, nil
+	}
+	product := new(model.Product)
+	if err := bytesToStruct(productBytes, product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (cc *Chaincode) mustGetProduct(stub shim.ChaincodeStubInterface, productID string) (*model.Product, error) {
+	product, err := cc.getProduct(stub, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, fmt.Errorf("Product %s not found.", productID)
+	}
+	return product, nil
+}
+
+func (cc *Chaincode) putProduct(stub shim.ChaincodeStubInterface, product *model.Product) ([]byte, error) {
+	productData, err := json.Marshal(product)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling product data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, product.GetObjectType(), []string{product.ID})
+	if err := stub.PutState(key, productData); err != nil {
+		return nil, err
+	}
+	return productData, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/utils"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Rich query handler functions
+//------------------------------
+
+// QueryTransactions query transactions matching a CouchDB Mango selector, e.g.
+// on amount ranges, status, creation date windows or counterparty. The query
+// runs on the state database when the peer uses CouchDB; on LevelDB, which has
+// no rich queries, transactions are range scanned and filtered in the
+// chaincode instead, narrowed to a customer and account when the selector
+// names them.
+func (cc *Chaincode) QueryTransactions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 || len(args) > 3 {
+		return nil, errors.New("Missing required selector JSON")
+	}
+	selector := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(args[0]), &selector); err != nil {
+		return nil, fmt.Errorf("Error parsing selector. Error: %s", err)
+	}
+	selector["docType"] = model.TransactionObjectType
+	pageSize, err := listPageSizeArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	bookmark := optionalArg(args, 2)
+
+	query, _ := json.Marshal(map[string]interface{}{"selector": selector})
+	list, err := cc.richQueryTransactions(stub, string(query), pageSize, bookmark)
+	if err != nil && strings.Contains(err.Error(), "not supported") {
+		loggerFor(stub).Infof("Rich queries not supported by the state database, filtering a range scan. Error: %s", err)
+		list, err = cc.scanQueryTransactions(stub, selector, pageSize, bookmark)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(list)
+}
+
+// GetTransactionByReference query the transactions carrying an end-to-end
+// reference, typically the payer's and payee's side of a payment, without
+// knowing their customer and account IDs. Transactions are found through an
+// index maintained when they are recorded, newest first.
+func (cc *Chaincode) GetTransactionByReference(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 || args[0] == "" {
+		return nil, errors.New("Missing required end-to-end reference")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TransactionReferenceObjectType, []string{args[0]})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction references. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	list := &model.TransactionList{Transactions: []*model.Transaction{}}
+	for keysIter.HasNext() {
+		txnKey := nextValue(keysIter)
+		txnBytes, err := stub.GetState(string(txnKey))
+		if err != nil {
+			return nil, err
+		}
+		if txnBytes == nil {
+			continue
+		}
+		txn := new(model.Transaction)
+		if err := json.Unmarshal(txnBytes, txn); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			continue
+		}
+		list.Transactions = append(list.Transactions, txn)
+	}
+	sort.Sort(sort.Reverse(model.ByCreated(list.Transactions)))
+	return json.Marshal(list)
+}
+
+func (cc *Chaincode) richQueryTransactions(stub shim.ChaincodeStubInterface, query string, pageSize int, bookmark string) (*model.TransactionList, error) {
+	resultsIter, metadata, err := stub.GetQueryResultWithPagination(query, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIter.Close()
+	list := &model.TransactionList{Transactions: []*model.Transaction{}}
+	for resultsIter.HasNext() {
+		kv, err := resultsIter.Next()
+		if err != nil {
+			return nil, err
+		}
+		txn := new(model.Transaction)
+		if err := bytesToStruct(kv.Value, txn); err != nil {
+			return nil, err
+		}
+		list.Transactions = append(list.Transactions, txn)
+	}
+	// CouchDB returns a bookmark even after the last page
+	if metadata != nil && int(metadata.FetchedRecordsCount) == pageSize {
+		list.NextBookmark = metadata.Bookmark
+	}
+	return list, nil
+}
+
+// scanQueryTransactions evaluates the selector against transactions in key
+// order. The bookmark is the key of the last transaction returned.
+func (cc *Chaincode) scanQueryTransactions(stub shim.ChaincodeStubInterface, selector map[string]interface{}, pageSize int, bookmark string) (*model.TransactionList, error) {
+	var keys []string
+	if customerID, ok := selector["customer_id"].(string); ok {
+		keys = append(keys, customerID)
+		if accountID, ok := selector["account_id"].(string); ok {
+			keys = append(keys, accountID)
+		}
+	}
+	partialCompositeKey, err := cc.createCompositeKey(stub, model.TransactionObjectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	start := partialCompositeKey
+	if bookmark != "" {
+		if !strings.HasPrefix(bookmark, partialCompositeKey) {
+			return nil, fmt.Errorf("Invalid bookmark %s", bookmark)
+		}
+		// the range start is inclusive, so start just after the bookmark
+		start = bookmark + "\x00"
+	}
+	keysIter, err := compositeKeyRange(stub, partialCompositeKey, start, partialCompositeKey+string(utf8.MaxRune))
+	if err != nil {
+		return nil, err
+	}
+	defer keysIter.Close()
+	list := &model.TransactionList{Transactions: []*model.Transaction{}}
+	lastKey := ""
+	for keysIter.HasNext() {
+		key, value, err := nextKeyValue(keysIter)
+		if err != nil {
+			return nil, err
+		}
+		doc := make(map[string]interface{})
+		if err := json.Unmarshal(value, &doc); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			continue
+		}
+		matched, err := utils.MatchSelector(doc, selector)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+		if len(list.Transactions) == pageSize {
+			list.NextBookmark = lastKey
+			break
+		}
+		txn := new(model.Transaction)
+		if err := bytesToStruct(value, txn); err != nil {
+			return nil, err
+		}
+		list.Transactions = append(list.Transactions, txn)
+		lastKey = key
+	}
+	return list, nil
+}
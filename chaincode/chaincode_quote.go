@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Quote handler functions
+//------------------------------
+
+// RequestQuote prices a transfer with the fee schedule and exchange rate in
+// force and stores the quote, which TransferMoney can reference by its ID
+// until it expires
+func (cc *Chaincode) RequestQuote(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required quote request JSON")
+	}
+	requestedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := model.CreateQuote([]byte(args[0]), requestedBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	fromAccount, err := cc.getAccountStruct(stub, quote.FromCustomerID, quote.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, fromAccount); err != nil {
+		return nil, err
+	}
+	if !fromAccount.CanSend() {
+		return nil, fmt.Errorf("Cannot transfer money from %s account %s", fromAccount.Status, fromAccount.ID)
+	}
+	payeeStub, err := cc.payeeScope(stub, quote.ToTenant)
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := cc.getAccountStruct(payeeStub, quote.ToCustomerID, quote.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !toAccount.CanReceive() {
+		return nil, fmt.Errorf("Cannot transfer money into closed account %s", toAccount.ID)
+	}
+	schedule, err := cc.transferFeeSchedule(stub, fromAccount, toAccount, fromAccount.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	var rate *model.ExchangeRate
+	if toAccount.CurrencyCode != fromAccount.CurrencyCode {
+		if rate, err = cc.currentExchangeRate(stub, fromAccount.CurrencyCode, toAccount.CurrencyCode); err != nil {
+			return nil, err
+		}
+	}
+	if err := quote.Price(fromAccount, toAccount, schedule, rate); err != nil {
+		return nil, err
+	}
+	return cc.putQuote(stub, quote)
+}
+
+// GetQuote query a quote requested for a payer customer
+func (cc *Chaincode) GetQuote(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or quote ID")
+	}
+	quote, err := cc.getQuote(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(quote)
+}
+
+// executableQuote returns the quote a transfer references, failing unless it
+// quotes the transfer and can still be executed
+func (cc *Chaincode) executableQuote(stub shim.ChaincodeStubInterface, t *model.Transfer) (*model.Quote, error) {
+	quote, err := cc.getQuote(stub, t.FromCustomerID, t.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	if !quote.Matches(t) {
+		return nil, fmt.Errorf("Transfer does not match quote %s", quote.ID)
+	}
+	if !quote.Executable(txContext(stub).Time.Unix()) {
+		return nil, &transferFailure{
+			code: model.QuoteExpired,
+			err:  fmt.Errorf("Quote %s has expired or was already executed", quote.ID),
+		}
+	}
+	return quote, nil
+}
+
+// useQuote marks a quote executed by the current transaction
+func (cc *Chaincode) useQuote(stub shim.ChaincodeStubInterface, quote *model.Quote) error {
+	quote.UsedTxID = stub.GetTxID()
+	_, err := cc.putQuote(stub, quote)
+	return err
+}
+
+func (cc *Chaincode) getQuote(stub shim.ChaincodeStubInterface, customerID string, quoteID string) (*model.Quote, error) {
+	key, _ := cc.createCompositeKey(stub, model.QuoteObjectType, []string{customerID, quoteID})
+	quoteBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get quote details. Error: %s", err)
+		return nil, err
+	}
+	if quoteBytes == nil {
+		return nil, fmt.Errorf("Quote %s not found.", quoteID)
+	}
+	quote := new(model.Quote)
+	if err := bytesToStruct(quoteBytes, quote); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+func (cc *Chaincode) putQuote(stub shim.ChaincodeStubInterface, quote *model.Quote) ([]byte, error) {
+	quoteData, err := json.Marshal(quote)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling quote data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, quote.GetObjectType(), []string{quote.FromCustomerID, quote.ID})
+	if err := stub.PutState(key, quoteData); err != nil {
+		return nil, err
+	}
+	return quoteData, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Regulatory reporting handler functions
+//------------------------------
+
+// SetReportingThreshold sets the amount in a currency above which a single
+// transfer is reported to the regulator. Restricted to regulators.
+func (cc *Chaincode) SetReportingThreshold(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required reporting threshold data JSON")
+	}
+	setBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := model.CreateReportingThreshold([]byte(args[0]), setBy, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating reporting threshold. Error: %s", err)
+	}
+	thresholdData, _ := json.Marshal(threshold)
+	key, _ := cc.createCompositeKey(stub, threshold.GetObjectType(), []string{threshold.CurrencyCode})
+	if err := stub.PutState(key, thresholdData); err != nil {
+		return nil, err
+	}
+	return thresholdData, nil
+}
+
+// GetReportingThreshold query the reporting threshold of a currency
+func (cc *Chaincode) GetReportingThreshold(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	key, _ := cc.createCompositeKey(stub, model.ReportingThresholdObjectType, args)
+	return stub.GetState(key)
+}
+
+// GetLargeTransactions query the transfers of at least an amount, optionally
+// within a date window (YYYY-MM-DD, inclusive) and in a currency. Each transfer
+// is listed once, by its payer's transaction. Restricted to regulators.
+func (cc *Chaincode) GetLargeTransactions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 4 {
+		return nil, errors.New("Missing required threshold amount")
+	}
+	threshold, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || threshold <= 0 {
+		return nil, fmt.Errorf("Invalid threshold amount %s", args[0])
+	}
+	from, to, err := model.ParseReportingPeriod(optionalArg(args, 1), optionalArg(args, 2))
+	if err != nil {
+		return nil, err
+	}
+	currency := optionalArg(args, 3)
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TransactionObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction list. Error: %s", err)
+		return nil, err
+	}
+	list := model.TransactionList{Transactions: []*model.Transaction{}}
+	for keysIter.HasNext() {
+		txn := new(model.Transaction)
+		if err := json.Unmarshal(nextValue(keysIter), txn); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			continue
+		}
+		if txn.Status != model.Debited || txn.Type != "" || txn.Amount < threshold {
+			continue
+		}
+		if (currency != "" && txn.CurrencyCode != currency) || !model.InPeriod(from, to, model.ReportDate(txn.Created)) {
+			continue
+		}
+		list.Transactions = append(list.Transactions, txn)
+	}
+	sort.Sort(sort.Reverse(model.ByCreated(list.Transactions)))
+	return json.Marshal(list)
+}
+
+// GetFlaggedTransactions query the transfers that breached limits enforced by
+// flagging and those stopped by sanctions screening, optionally within a date
+// window (YYYY-MM-DD, inclusive). Restricted to regulators.
+func (cc *Chaincode) GetFlaggedTransactions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 2 {
+		return nil, errors.New("Too many arguments, expected optional from and to dates")
+	}
+	from, to, err := model.ParseReportingPeriod(optionalArg(args, 0), optionalArg(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	reports, err := cc.getRegulatoryReports(stub, from, to, model.ReportLimitExceeded)
+	if err != nil {
+		return nil, err
+	}
+	flagged := &model.FlaggedTransactions{From: from, To: to, Reports: reports, Alerts: []*model.ComplianceAlert{}}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.ComplianceAlertObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get compliance alerts. Error: %s", err)
+		return nil, err
+	}
+	for keysIter.HasNext() {
+		alert := new(model.ComplianceAlert)
+		if err := json.Unmarshal(nextValue(keysIter), alert); err != nil {
+			loggerFor(stub).Errorf("Failed to get compliance alert details. Error: %s", err)
+			continue
+		}
+		if model.InPeriod(from, to, model.ReportDate(alert.Created)) {
+			flagged.Alerts = append(flagged.Alerts, alert)
+		}
+	}
+	return json.Marshal(flagged)
+}
+
+// GetAggregateFlows query the settled and failed transfer totals of all
+// corridors, grouped by corridor and currency or by currency only, optionally
+// within a date window (YYYY-MM-DD, inclusive). Restricted to regulators.
+func (cc *Chaincode) GetAggregateFlows(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, errors.New("Missing required grouping")
+	}
+	from, to, err := model.ParseReportingPeriod(optionalArg(args, 1), optionalArg(args, 2))
+	if err != nil {
+		return nil, err
+	}
+	flows, err := model.CreateAggregateFlows(model.FlowGrouping(args[0]), from, to)
+	if err != nil {
+		return nil, err
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.CorridorBucketObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get corridor buckets. Error: %s", err)
+		return nil, err
+	}
+	for keysIter.HasNext() {
+		bucket := new(model.CorridorBucket)
+		if err := json.Unmarshal(nextValue(keysIter), bucket); err != nil {
+			loggerFor(stub).Errorf("Failed to get corridor bucket details. Error: %s", err)
+			continue
+		}
+		flows.Add(bucket)
+	}
+	return json.Marshal(flows)
+}
+
+// GetRegulatoryReports query the transfers reported to the regulator,
+// optionally within a date window (YYYY-MM-DD, inclusive) and of a kind.
+// Restricted to regulators.
+func (cc *Chaincode) GetRegulatoryReports(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 3 {
+		return nil, errors.New("Too many arguments, expected optional from date, to date and kind")
+	}
+	from, to, err := model.ParseReportingPeriod(optionalArg(args, 0), optionalArg(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	reports, err := cc.getRegulatoryReports(stub, from, to, model.RegulatoryReportKind(optionalArg(args, 2)))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&model.RegulatoryReportList{Reports: reports})
+}
+
+// reportLargeTransfer reports a settled transfer above the reporting threshold
+// of its currency. Currencies without a threshold are not reported.
+func (cc *Chaincode) reportLargeTransfer(stub shim.ChaincodeStubInterface, from *model.Account, to *model.Account, t *model.Transfer) error {
+	key, _ := cc.createCompositeKey(stub, model.ReportingThresholdObjectType, []string{t.CurrencyCode})
+	thresholdBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get reporting threshold details. Error: %s", err)
+		return err
+	}
+	if thresholdBytes == nil {
+		return nil
+	}
+	threshold := new(model.ReportingThreshold)
+	if err := bytesToStruct(thresholdBytes, threshold); err != nil {
+		return err
+	}
+	if !threshold.Exceeded(t.Amount) {
+		return nil
+	}
+	report := model.CreateRegulatoryReport(model.ReportLargeTransaction, from, to, t, txContext(stub))
+	report.Threshold = threshold.Amount
+	return cc.putRegulatoryReport(stub, report)
+}
+
+// getRegulatoryReports returns the reports of a period, of any kind if kind is empty
+func (cc *Chaincode) getRegulatoryReports(stub shim.ChaincodeStubInterface, from string, to string, kind model.RegulatoryReportKind) ([]*model.RegulatoryReport, error) {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.RegulatoryReportObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get regulatory reports. Error: %s", err)
+		return nil, err
+	}
+	reports := []*model.RegulatoryReport{}
+	for keysIter.HasNext() {
+		report := new(model.RegulatoryReport)
+		if err := json.Unmarshal(nextValue(keysIter), report); err != nil {
+			loggerFor(stub).Errorf("Failed to get regulatory report details. Error: %s", err)
+			continue
+		}
+		if (kind == "" || report.Kind == kind) && model.InPeriod(from, to, report.Date) {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+func (cc *Chaincode) putRegulatoryReport(stub shim.ChaincodeStubInterface, report *model.RegulatoryReport) error {
+	reportData, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("Error marshalling regulatory report data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, report.GetObjectType(), []string{report.Date, report.ID})
+	return stub.PutState(key, reportData)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Repo handler functions
+//------------------------------
+
+// OpenRepo records a repo agreement and settles its opening leg, delivering
+// the collateral to the lender against the cash amount in one transaction
+// The caller must be authorized to transfer for both the borrower and the lender.
+func (cc *Chaincode) OpenRepo(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required repo data JSON")
+	}
+	repo, err := model.CreateRepo([]byte(args[0]), txContext(stub).Time.UTC().Format(model.PayDateFormat), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating repo. Error: %s", err)
+	}
+	existing, err := cc.getRepo(stub, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Repo %s already exists", repo.ID)
+	}
+	// the borrower delivers the collateral
+	if err := cc.authorize(stub, auth.Transfer, &model.Account{CustomerID: repo.BorrowerCustomerID}); err != nil {
+		return nil, err
+	}
+	if err := cc.settleDvP(stub, repo.OpeningLeg(), "Repo opening leg"); err != nil {
+		return nil, fmt.Errorf("Repo %s opening leg failed. Error: %s", repo.ID, err)
+	}
+	return cc.putRepo(stub, repo)
+}
+
+// CloseRepo settles the closing leg of an open repo, returning the collateral
+// to the borrower against the repurchase price. If the borrower cannot fund
+// the repurchase the repo is marked failed and the lender retains the collateral
+func (cc *Chaincode) CloseRepo(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required repo ID")
+	}
+	repo, err := cc.mustGetRepo(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if repo.Status != model.RepoOpen {
+		return nil, fmt.Errorf("Repo %s is %s", repo.ID, repo.Status)
+	}
+	borrower, err := cc.getAccountStruct(stub, repo.BorrowerCustomerID, repo.BorrowerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, borrower); err != nil {
+		return nil, err
+	}
+	repo.Closed = txContext(stub).Time.Unix()
+	if !borrower.CanSend() || borrower.Unheld() < repo.RepurchasePrice {
+		return cc.failRepo(stub, repo, borrower)
+	}
+	if err := cc.settleDvP(stub, repo.ClosingLeg(), "Repo closing leg"); err != nil {
+		return nil, fmt.Errorf("Repo %s closing leg failed. Error: %s", repo.ID, err)
+	}
+	repo.Status = model.RepoClosed
+	return cc.putRepo(stub, repo)
+}
+
+// GetRepo query a repo agreement by ID
+func (cc *Chaincode) GetRepo(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required repo ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.RepoObjectType, args)
+	return stub.GetState(key)
+}
+
+// failRepo marks a repo whose closing leg cannot be funded as failed. The
+// collateral stays with the lender and a failed transaction is recorded
+// against the borrower account so the fail is visible in its history
+func (cc *Chaincode) failRepo(stub shim.ChaincodeStubInterface, repo *model.Repo, borrower *model.Account) ([]byte, error) {
+	loggerFor(stub).Warningf("Repo %s failed to close: borrower account %s cannot fund repurchase price %d", repo.ID, borrower.ID, repo.RepurchasePrice)
+	code := model.InsufficientFunds
+	if borrower.IsClosed() {
+		code = model.ClosedAccount
+	} else if !borrower.CanSend() {
+		code = model.AccountInactive
+	}
+	leg := repo.ClosingLeg()
+	t := &model.Transfer{
+		FromCustomerID: leg.BuyerCustomerID,
+		FromAccountID:  leg.BuyerAccountID,
+		ToCustomerID:   leg.SellerCustomerID,
+		ToAccountID:    leg.SellerAccountID,
+		Amount:         leg.Price,
+		CurrencyCode:   leg.CurrencyCode,
+		Description:    "Repo closing leg",
+		Params:         map[string]string{"repo": repo.ID},
+	}
+	if err := cc.recordTransaction(stub, borrower.CustomerID, borrower.ID, t, code, model.Failed); err != nil {
+		return nil, err
+	}
+	lender, err := cc.getAccountStruct(stub, repo.LenderCustomerID, repo.LenderAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.recordCorridorFlow(stub, borrower, lender, t, model.Failed); err != nil {
+		return nil, err
+	}
+	repo.Status = model.RepoFailed
+	return cc.putRepo(stub, repo)
+}
+
+func (cc *Chaincode) getRepo(stub shim.ChaincodeStubInterface, repoID string) (*model.Repo, error) {
+	key, _ := cc.createCompositeKey(stub, model.RepoObjectType, []string{repoID})
+	repoBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get repo details. Error: %s", err)
+		return nil, err
+	}
+	if repoBytes == nil {
+		return nil, nil
+	}
+	repo := new(model.Repo)
+	if err := bytesToStruct(repoBytes, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (cc *Chaincode) mustGetRepo(stub shim.ChaincodeStubInterface, repoID string) (*model.Repo, error) {
+	repo, err := cc.getRepo(stub, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("Repo %s not found.", repoID)
+	}
+	return repo, nil
+}
+
+func (cc *Chaincode) putRepo(stub shim.ChaincodeStubInterface, repo *model.Repo) ([]byte, error) {
+	repoData, err := json.Marshal(repo)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling repo data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, repo.GetObjectType(), []string{repo.ID})
+	if err := stub.PutState(key, repoData); err != nil {
+		return nil, err
+	}
+	return repoData, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Reserve attestation handler functions
+//------------------------------
+
+// PublishReserveAttestation records an auditor's signed statement of the reserves
+// backing an emitted currency. Restricted to auditors; attestations are immutable.
+func (cc *Chaincode) PublishReserveAttestation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required reserve attestation data JSON")
+	}
+	attestation, err := model.CreateReserveAttestation([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating reserve attestation. Error: %s", err)
+	}
+	if err := verifyCallerSignature(stub, attestation.SignedMessage(), attestation.Signature); err != nil {
+		return nil, err
+	}
+	if attestation.Auditor, err = callerID(stub); err != nil {
+		return nil, err
+	}
+	if attestation.AuditorMSP, err = callerMSPID(stub); err != nil {
+		return nil, err
+	}
+	key, _ := cc.createCompositeKey(stub, attestation.GetObjectType(), []string{attestation.CurrencyCode, attestation.AsOf})
+	existing, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Reserve attestation for %s as of %s already published", attestation.CurrencyCode, attestation.AsOf)
+	}
+	attestationData, _ := json.Marshal(attestation)
+	if err := stub.PutState(key, attestationData); err != nil {
+		return nil, err
+	}
+	return attestationData, nil
+}
+
+// GetReserveStatus query the latest reserve attestation of a currency together
+// with its circulating supply
+func (cc *Chaincode) GetReserveStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	currency := args[0]
+	status := &model.ReserveStatus{CurrencyCode: currency}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.ReserveAttestationObjectType, []string{currency})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get reserve attestations. Error: %s", err)
+		return nil, err
+	}
+	for keysIter.HasNext() {
+		attestationBytes := nextValue(keysIter)
+		attestation := new(model.ReserveAttestation)
+		if err := json.Unmarshal(attestationBytes, attestation); err != nil {
+			loggerFor(stub).Errorf("Failed to get reserve attestation details. Error: %s", err)
+			continue
+		}
+		// keys are ordered by as-of date within a currency
+		if attestation.CurrencyCode == currency {
+			status.Attestation = attestation
+		}
+	}
+	circulation, err := cc.getCirculation(stub, currency)
+	if err != nil {
+		return nil, err
+	}
+	status.CirculatingSupply = circulation.Circulating
+	if status.Attestation != nil && status.CirculatingSupply > 0 {
+		status.Coverage = float64(status.Attestation.ReserveTotal) / float64(status.CirculatingSupply)
+	}
+	return json.Marshal(status)
+}
+
+// UpdateReserve records the off-chain reserves backing a currency, citing the
+// as-of date of a published attestation of the currency proving them. The
+// amount may not exceed the attested total and a reserve may not cite an
+// attestation older than the one it replaces. Restricted to the emission authority.
+func (cc *Chaincode) UpdateReserve(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required currency, amount and / or attestation as-of date")
+	}
+	updatedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.requireEmissionAuthorityMSP(stub); err != nil {
+		return nil, err
+	}
+	amount, err := model.ParseAmount(args[1])
+	if err != nil {
+		return nil, err
+	}
+	key, _ := cc.createCompositeKey(stub, model.ReserveAttestationObjectType, []string{args[0], args[2]})
+	attestationBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if attestationBytes == nil {
+		return nil, fmt.Errorf("No reserve attestation for %s as of %s", args[0], args[2])
+	}
+	attestation := new(model.ReserveAttestation)
+	if err := bytesToStruct(attestationBytes, attestation); err != nil {
+		return nil, err
+	}
+	current, err := cc.getReserve(stub, attestation.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	// as-of dates are YYYY-MM-DD, so they compare in date order
+	if current != nil && attestation.AsOf < current.AttestationAsOf {
+		return nil, fmt.Errorf("Attestation as of %s is older than the attestation as of %s of the current reserve", attestation.AsOf, current.AttestationAsOf)
+	}
+	reserve, err := model.CreateReserve(attestation, amount, updatedBy, txContext(stub))
+	if err != nil {
+		return nil, err
+	}
+	reserveData, _ := json.Marshal(reserve)
+	reserveKey, _ := cc.createCompositeKey(stub, reserve.GetObjectType(), []string{reserve.CurrencyCode})
+	if err := stub.PutState(reserveKey, reserveData); err != nil {
+		return nil, err
+	}
+	return reserveData, nil
+}
+
+// GetReserveRatio query the recorded reserves of a currency against its
+// emitted supply. Restricted to regulators and auditors.
+func (cc *Chaincode) GetReserveRatio(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	supply, err := cc.getSupply(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	reserve, err := cc.getReserve(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(model.NewReserveRatio(args[0], supply.Total, reserve))
+}
+
+// getReserve returns the recorded reserves of a currency, nil if none are recorded
+func (cc *Chaincode) getReserve(stub shim.ChaincodeStubInterface, currency string) (*model.Reserve, error) {
+	key, _ := cc.createCompositeKey(stub, model.ReserveObjectType, []string{currency})
+	reserveBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get reserve details. Error: %s", err)
+		return nil, err
+	}
+	if reserveBytes == nil {
+		return nil, nil
+	}
+	reserve := new(model.Reserve)
+	if err := bytesToStruct(reserveBytes, reserve); err != nil {
+		return nil, err
+	}
+	return reserve, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Role handler functions
+//------------------------------
+
+// GrantRole grants a role on the ledger to a client identity. Restricted to
+// network operators.
+func (cc *Chaincode) GrantRole(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	grant, role, operator, err := cc.roleChangeArgs(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	if !grant.Grant(role, operator, txContext(stub)) {
+		return nil, fmt.Errorf("Role %s is already granted to %s", role, grant.Identity)
+	}
+	if err := cc.putRoleGrant(stub, grant); err != nil {
+		return nil, err
+	}
+	return json.Marshal(grant)
+}
+
+// RevokeRole revokes a role granted on the ledger to a client identity. Roles
+// carried in the identity's certificate are not affected. Restricted to network
+// operators.
+func (cc *Chaincode) RevokeRole(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	grant, role, operator, err := cc.roleChangeArgs(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	if !grant.Revoke(role, operator, txContext(stub)) {
+		return nil, fmt.Errorf("Role %s is not granted to %s", role, grant.Identity)
+	}
+	if err := cc.putRoleGrant(stub, grant); err != nil {
+		return nil, err
+	}
+	return json.Marshal(grant)
+}
+
+// GetRoles query the roles granted on the ledger to a client identity
+func (cc *Chaincode) GetRoles(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required identity")
+	}
+	grant, err := cc.getRoleGrant(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(grant)
+}
+
+// roleChangeArgs validates the identity and role arguments of GrantRole and
+// RevokeRole and returns the identity's grant along with the invoking operator
+func (cc *Chaincode) roleChangeArgs(stub shim.ChaincodeStubInterface, args []string) (*model.RoleGrant, string, string, error) {
+	if len(args) < 2 || args[0] == "" {
+		return nil, "", "", errors.New("Missing required identity and / or role")
+	}
+	role := args[1]
+	if !knownRoles[role] {
+		return nil, "", "", fmt.Errorf("Unknown role %s", role)
+	}
+	operator, err := callerID(stub)
+	if err != nil {
+		return nil, "", "", err
+	}
+	grant, err := cc.getRoleGrant(stub, args[0])
+	if err != nil {
+		return nil, "", "", err
+	}
+	return grant, role, operator, nil
+}
+
+// getRoleGrant returns the roles granted on the ledger to an identity, or an
+// empty grant if none were
+func (cc *Chaincode) getRoleGrant(stub shim.ChaincodeStubInterface, identity string) (*model.RoleGrant, error) {
+	key, _ := cc.createCompositeKey(stub, model.RoleGrantObjectType, []string{identity})
+	grantBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get role grant details. Error: %s", err)
+		return nil, err
+	}
+	grant := model.CreateRoleGrant(identity)
+	if grantBytes == nil {
+		return grant, nil
+	}
+	if err := bytesToStruct(grantBytes, grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (cc *Chaincode) putRoleGrant(stub shim.ChaincodeStubInterface, grant *model.RoleGrant) error {
+	grantData, _ := json.Marshal(grant)
+	key, _ := cc.createCompositeKey(stub, grant.GetObjectType(), []string{grant.Identity})
+	return stub.PutState(key, grantData)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Donation round-up handler functions
+//------------------------------
+
+// SetRoundUpRule opts an account into rounding up its outgoing transfers for a charity
+func (cc *Chaincode) SetRoundUpRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required round-up rule data JSON")
+	}
+	rule, err := model.CreateRoundUpRule([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating round-up rule. Error: %s", err)
+	}
+	account, err := cc.getAccountStruct(stub, rule.CustomerID, rule.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	charity, err := cc.getAccountStruct(stub, rule.CharityCustomerID, rule.CharityAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !charity.CanReceive() {
+		return nil, fmt.Errorf("Charity account %s is closed", charity.ID)
+	}
+	if account.CurrencyCode != charity.CurrencyCode {
+		return nil, fmt.Errorf("Charity account currency %s does not match %s", charity.CurrencyCode, account.CurrencyCode)
+	}
+	ruleData, _ := json.Marshal(rule)
+	key, _ := cc.createCompositeKey(stub, rule.GetObjectType(), []string{rule.CustomerID, rule.AccountID})
+	if err := stub.PutState(key, ruleData); err != nil {
+		return nil, err
+	}
+	return ruleData, nil
+}
+
+// GetRoundUpRule query the round-up rule of an account
+func (cc *Chaincode) GetRoundUpRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.RoundUpRuleObjectType, args)
+	return stub.GetState(key)
+}
+
+// RemoveRoundUpRule opts an account out of round-ups. Totals already donated are kept.
+func (cc *Chaincode) RemoveRoundUpRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	key, _ := cc.createCompositeKey(stub, model.RoundUpRuleObjectType, args)
+	return nil, stub.DelState(key)
+}
+
+// GetRoundUpTotals query the monthly round-up totals of an account, optionally for a single month (YYYY-MM)
+func (cc *Chaincode) GetRoundUpTotals(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.RoundUpTotalObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get round-up totals. Error: %s", err)
+		return nil, err
+	}
+	list := model.RoundUpTotalList{Totals: []*model.RoundUpTotal{}}
+	for keysIter.HasNext() {
+		totalBytes := nextValue(keysIter)
+		total := new(model.RoundUpTotal)
+		if err := json.Unmarshal(totalBytes, total); err != nil {
+			loggerFor(stub).Errorf("Failed to get round-up total details. Error: %s", err)
+			continue
+		}
+		list.Totals = append(list.Totals, total)
+	}
+	return json.Marshal(list)
+}
+
+// applyRoundUp donates the round-up of a settled transfer to the charity of the
+// paying account's rule. Round-ups are skipped when the account cannot fund them.
+func (cc *Chaincode) applyRoundUp(stub shim.ChaincodeStubInterface, from *model.Account, t *model.Transfer) error {
+	key, _ := cc.createCompositeKey(stub, model.RoundUpRuleObjectType, []string{from.CustomerID, from.ID})
+	ruleBytes, err := stub.GetState(key)
+	if err != nil || ruleBytes == nil {
+		return err
+	}
+	rule := new(model.RoundUpRule)
+	if err := bytesToStruct(ruleBytes, rule); err != nil {
+		return err
+	}
+	amount := rule.RoundUp(t.Amount)
+	if amount == 0 || from.Unheld() < amount {
+		return nil
+	}
+	charity, err := cc.getAccountStruct(stub, rule.CharityCustomerID, rule.CharityAccountID)
+	if err != nil {
+		return err
+	}
+	if !charity.CanReceive() || charity.CurrencyCode != from.CurrencyCode {
+		loggerFor(stub).Warningf("Skipping round-up of account %s: charity account %s cannot be credited", from.ID, charity.ID)
+		return nil
+	}
+	donation := &model.Transfer{
+		FromCustomerID: from.CustomerID,
+		FromAccountID:  from.ID,
+		ToCustomerID:   charity.CustomerID,
+		ToAccountID:    charity.ID,
+		Amount:         amount,
+		CurrencyCode:   from.CurrencyCode,
+		Description:    "Round-up donation",
+		Params:         map[string]string{"initiated_by": "system", "round_up_of": t.Description},
+	}
+	if err := cc.debitAccount(stub, from, from.Money(amount)); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, from.CustomerID, from.ID, donation, "", model.Debited); err != nil {
+		return err
+	}
+	if err := cc.creditAccount(stub, charity, charity.Money(amount)); err != nil {
+		return err
+	}
+	if err := cc.recordTransaction(stub, charity.CustomerID, charity.ID, donation, "", model.Credited); err != nil {
+		return err
+	}
+
+	month := txContext(stub).Time.UTC().Format(model.RoundUpMonthFormat)
+	totalKey, _ := cc.createCompositeKey(stub, model.RoundUpTotalObjectType, []string{rule.CustomerID, rule.AccountID, month})
+	totalBytes, err := stub.GetState(totalKey)
+	if err != nil {
+		return err
+	}
+	total := model.CreateRoundUpTotal(rule, month)
+	if totalBytes != nil {
+		if err := bytesToStruct(totalBytes, total); err != nil {
+			return err
+		}
+	}
+	total.Total += amount
+	total.Count++
+	totalData, _ := json.Marshal(total)
+	return stub.PutState(totalKey, totalData)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestRoundUpsDonateTheDifferenceToTheNextWholeUnit(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("9000", "1"))
+	stub.Topup(t, "1001", "1", 1050)
+	stub.As(testsupport.Customer(t, "1001")).MustCall(t, "SetRoundUpRule", `{"customer_id":"1001","account_id":"1","charity_customer":"9000","charity_account":"1","unit":100}`)
+
+	for _, amount := range []int64{250, 300, 420} {
+		stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", amount).JSON())
+	}
+	// 250 rounds up by 50, 300 needs no round-up and the 80 of 420 is skipped
+	// as the remaining 30 cannot fund it
+	if payer, charity := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "9000", "1"); payer != 30 || charity != 50 {
+		t.Errorf("Expected one round-up donated, got balances of %d and %d", payer, charity)
+	}
+
+	list := new(model.RoundUpTotalList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetRoundUpTotals", "1001", "1"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Totals) != 1 || list.Totals[0].Month != "2021-03" || list.Totals[0].Total != 50 || list.Totals[0].Count != 1 {
+		t.Errorf("Expected the donation counted in the month of the transfer, got %+v", list.Totals)
+	}
+}
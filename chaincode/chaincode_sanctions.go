@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Sanctions screening handler functions
+//------------------------------
+
+// AddBlockedParty adds a customer, account or country to the blocklist.
+// Restricted to compliance officers.
+func (cc *Chaincode) AddBlockedParty(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required blocked party JSON")
+	}
+	addedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	party, err := model.CreateBlockedParty([]byte(args[0]), addedBy, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating blocked party. Error: %s", err)
+	}
+	partyData, _ := json.Marshal(party)
+	key, _ := cc.createCompositeKey(stub, party.GetObjectType(), party.Keys())
+	if err := stub.PutState(key, partyData); err != nil {
+		return nil, err
+	}
+	return partyData, nil
+}
+
+// RemoveBlockedParty removes a blocklist entry. Args: kind, then the customer
+// ID, customer and account ID, or country the entry matches. Restricted to
+// compliance officers.
+func (cc *Chaincode) RemoveBlockedParty(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	keys, err := blockedPartyArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	party, err := cc.getBlockedParty(stub, keys)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil {
+		return nil, fmt.Errorf("Blocked party %s not found.", strings.Join(args, " "))
+	}
+	key, _ := cc.createCompositeKey(stub, model.BlockedPartyObjectType, keys)
+	return nil, stub.DelState(key)
+}
+
+// IsBlocked query whether a party is on the blocklist. Args as for RemoveBlockedParty.
+func (cc *Chaincode) IsBlocked(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	keys, err := blockedPartyArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	party, err := cc.getBlockedParty(stub, keys)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&model.BlockedCheck{Blocked: party != nil, Party: party})
+}
+
+// GetComplianceAlerts query the compliance alerts raised by sanctions screening
+func (cc *Chaincode) GetComplianceAlerts(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 2 {
+		return nil, errors.New("Too many arguments, expected optional page size and bookmark")
+	}
+	pageSize, err := listPageSizeArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	list := newListWriter("alerts").EmptyAsArray()
+	nextBookmark, err := cc.scanCompositeKeyPage(stub, model.ComplianceAlertObjectType, []string{}, optionalArg(args, 1), pageSize, func(_ string, alertBytes []byte) error {
+		alert := new(model.ComplianceAlert)
+		if err := json.Unmarshal(alertBytes, alert); err != nil {
+			loggerFor(stub).Errorf("Failed to get compliance alert details. Error: %s", err)
+			return nil
+		}
+		return list.Add(alert)
+	})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get compliance alerts. Error: %s", err)
+		return nil, err
+	}
+	return list.Bytes(nextBookmark), nil
+}
+
+// screenTransfer checks both parties of a transfer against the blocklist,
+// failing it with the sanctions_hit code on any match
+func (cc *Chaincode) screenTransfer(stub shim.ChaincodeStubInterface, from *model.Account, to *model.Account) error {
+	var matches []*model.BlockedParty
+	for _, a := range []*model.Account{from, to} {
+		candidates := [][]string{
+			{string(model.BlockedCustomer), a.CustomerID},
+			{string(model.BlockedAccount), a.CustomerID, a.ID},
+		}
+		if a.CountryCode != "" {
+			candidates = append(candidates, []string{string(model.BlockedCountry), a.CountryCode})
+		}
+		for _, keys := range candidates {
+			party, err := cc.getBlockedParty(stub, keys)
+			if err != nil {
+				return err
+			}
+			if party != nil {
+				matches = append(matches, party)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	return &transferFailure{
+		code:    model.SanctionsHit,
+		err:     fmt.Errorf("Transfer from account %s to account %s stopped by sanctions screening", from.ID, to.ID),
+		matches: matches,
+	}
+}
+
+// transferFailed reports a transfer that failed without failing the invocation.
+// A sanctions hit additionally records a failed transaction against the payer
+// account, which is returned, and a compliance alert for investigators.
+func (cc *Chaincode) transferFailed(stub shim.ChaincodeStubInterface, t *model.Transfer, failure error) (*model.Transaction, error) {
+	var txn *model.Transaction
+	if f, ok := failure.(*transferFailure); ok && f.code == model.SanctionsHit {
+		var err error
+		if txn, err = cc.storeTransaction(stub, t.FromCustomerID, t.FromAccountID, t, f.code, model.Failed); err != nil {
+			return nil, err
+		}
+		// an invocation may stop several transfers, e.g. in a batch, so alert IDs
+		// are the transaction ID with a sequence number
+		for seq := 0; ; seq++ {
+			alert := model.CreateComplianceAlert(fmt.Sprintf("%s-%d", stub.GetTxID(), seq), f.code, t, f.matches, txContext(stub))
+			key, _ := cc.createCompositeKey(stub, alert.GetObjectType(), []string{alert.ID})
+			existing, err := stub.GetState(key)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				continue
+			}
+			alertData, _ := json.Marshal(alert)
+			if err := stub.PutState(key, alertData); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return txn, emitTransferEvent(stub, t, failure)
+}
+
+func (cc *Chaincode) getBlockedParty(stub shim.ChaincodeStubInterface, keys []string) (*model.BlockedParty, error) {
+	key, _ := cc.createCompositeKey(stub, model.BlockedPartyObjectType, keys)
+	partyBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get blocked party details. Error: %s", err)
+		return nil, err
+	}
+	if partyBytes == nil {
+		return nil, nil
+	}
+	party := new(model.BlockedParty)
+	if err := bytesToStruct(partyBytes, party); err != nil {
+		return nil, err
+	}
+	return party, nil
+}
+
+// blockedPartyArgs returns the blocklist key attributes given as arguments
+func blockedPartyArgs(args []string) ([]string, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New("Missing required blocked party kind and / or identifier")
+	}
+	kind := model.BlockedPartyKind(args[0])
+	switch kind {
+	case model.BlockedCustomer:
+		return model.BlockedPartyKeys(kind, args[1], "", "")
+	case model.BlockedAccount:
+		return model.BlockedPartyKeys(kind, args[1], optionalArg(args, 2), "")
+	case model.BlockedCountry:
+		return model.BlockedPartyKeys(kind, "", "", args[1])
+	}
+	return nil, fmt.Errorf("Invalid blocked party kind %s", kind)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Standing order handler functions
+//------------------------------
+
+// CreateStandingOrder registers a recurring transfer from an account
+func (cc *Chaincode) CreateStandingOrder(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required standing order data JSON")
+	}
+	order, err := model.CreateStandingOrder([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating standing order. Error: %s", err)
+	}
+	if order.StartDate < txContext(stub).Time.UTC().Format(model.PayDateFormat) {
+		return nil, fmt.Errorf("Start date %s is in the past", order.StartDate)
+	}
+	t := &order.Transfer
+	account, err := cc.getAccountStruct(stub, t.FromCustomerID, t.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if account.IsClosed() {
+		return nil, fmt.Errorf("Cannot create standing order from closed account %s", account.ID)
+	}
+	if account.IsMultiSig() {
+		if err := cc.requireSigner(stub, account); err != nil {
+			return nil, err
+		}
+	}
+	if t.CurrencyCode != account.CurrencyCode {
+		return nil, fmt.Errorf("Standing order currency %s does not match account currency %s", t.CurrencyCode, account.CurrencyCode)
+	}
+	existing, err := cc.getStandingOrder(stub, t.FromCustomerID, t.FromAccountID, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Standing order %s already exists", order.ID)
+	}
+	return cc.putStandingOrder(stub, order)
+}
+
+// CancelStandingOrder stops an active standing order
+func (cc *Chaincode) CancelStandingOrder(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, account ID and / or standing order ID")
+	}
+	order, err := cc.getStandingOrder(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, fmt.Errorf("Standing order %s not found.", args[2])
+	}
+	if order.Status != model.StandingOrderActive {
+		return nil, fmt.Errorf("Standing order %s is %s", order.ID, order.Status)
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if account.IsMultiSig() {
+		if err := cc.requireSigner(stub, account); err != nil {
+			return nil, err
+		}
+	}
+	order.Status = model.StandingOrderCancelled
+	return cc.putStandingOrder(stub, order)
+}
+
+// GetStandingOrders query all standing orders of an account
+func (cc *Chaincode) GetStandingOrders(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	orders, err := cc.standingOrders(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(model.StandingOrderList{Orders: orders})
+}
+
+// ExecuteDueStandingOrders pays every active standing order whose next run date
+// has been reached. It is meant to be invoked by an external scheduler. A failed
+// payment leaves no state behind and is retried on the next invocation; run
+// dates that pass while an order cannot be paid are skipped, not paid twice.
+func (cc *Chaincode) ExecuteDueStandingOrders(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	orders, err := cc.standingOrders(stub, []string{})
+	if err != nil {
+		return nil, err
+	}
+	today := txContext(stub).Time.UTC().Format(model.PayDateFormat)
+	report := &model.StandingOrderReport{Date: today, Results: []*model.StandingOrderResult{}}
+	for _, order := range orders {
+		if !order.Due(today) {
+			continue
+		}
+		t := order.Transfer
+		t.Initiated = txContext(stub).Time.Unix()
+		t.Params = map[string]string{"initiated_by": "system", "standing_order": order.ID, "run_date": order.NextRun}
+		for k, v := range order.Transfer.Params {
+			if _, ok := t.Params[k]; !ok {
+				t.Params[k] = v
+			}
+		}
+		result := &model.StandingOrderResult{
+			CustomerID: t.FromCustomerID,
+			AccountID:  t.FromAccountID,
+			OrderID:    order.ID,
+			RunDate:    order.NextRun,
+			Amount:     t.Amount,
+		}
+		err := atomically(stub, func() error {
+			_, err := cc.executeTransfer(stub, &t)
+			return err
+		})
+		if err != nil {
+			loggerFor(stub).Warningf("Standing order %s failed. Error: %s", order.ID, err)
+			result.Error = err.Error()
+			order.LastError = err.Error()
+			if _, err := cc.transferFailed(stub, &t, err); err != nil {
+				return nil, err
+			}
+		} else {
+			order.Runs++
+			order.LastError = ""
+			order.Advance(today)
+		}
+		if _, err := cc.putStandingOrder(stub, order); err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, result)
+	}
+	return json.Marshal(report)
+}
+
+func (cc *Chaincode) standingOrders(stub shim.ChaincodeStubInterface, keys []string) ([]*model.StandingOrder, error) {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.StandingOrderObjectType, keys)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get standing orders. Error: %s", err)
+		return nil, err
+	}
+	var orders []*model.StandingOrder
+	for keysIter.HasNext() {
+		orderBytes := nextValue(keysIter)
+		order := new(model.StandingOrder)
+		if err := json.Unmarshal(orderBytes, order); err != nil {
+			loggerFor(stub).Errorf("Failed to get standing order details. Error: %s", err)
+			continue
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func (cc *Chaincode) getStandingOrder(stub shim.ChaincodeStubInterface, customerID string, accountID string, orderID string) (*model.StandingOrder, error) {
+	key, _ := cc.createCompositeKey(stub, model.StandingOrderObjectType, []string{customerID, accountID, orderID})
+	orderBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get standing order details. Error: %s", err)
+		return nil, err
+	}
+	if orderBytes == nil {
+		return nil, nil
+	}
+	order := new(model.StandingOrder)
+	if err := bytesToStruct(orderBytes, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (cc *Chaincode) putStandingOrder(stub shim.ChaincodeStubInterface, order *model.StandingOrder) ([]byte, error) {
+	orderData, err := json.Marshal(order)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling standing order data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, order.GetObjectType(), []string{order.Transfer.FromCustomerID, order.Transfer.FromAccountID, order.ID})
+	if err := stub.PutState(key, orderData); err != nil {
+		return nil, err
+	}
+	return orderData, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// executeDueStandingOrders runs the standing order scheduler and returns its report
+func executeDueStandingOrders(t *testing.T, stub *testsupport.Stub) *model.StandingOrderReport {
+	t.Helper()
+	report := new(model.StandingOrderReport)
+	if err := json.Unmarshal(stub.MustCall(t, "ExecuteDueStandingOrders"), report); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestStandingOrderPaysEachRunDateUntilItsEndDate(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 2500)
+	transfer := testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON()
+	stub.As(testsupport.Customer(t, "1001")).MustCall(t, "CreateStandingOrder", `{"id":"rent","transfer":`+transfer+`,"frequency":"daily","start_date":"2021-03-01","end_date":"2021-03-03"}`)
+
+	stub.As(testsupport.Operator(t, RoleAccountOperator))
+	if report := executeDueStandingOrders(t, stub); len(report.Results) != 1 || report.Results[0].Error != "" {
+		t.Errorf("Expected the order paid on its start date, got %+v", report.Results)
+	}
+	if report := executeDueStandingOrders(t, stub); len(report.Results) != 0 {
+		t.Errorf("Expected the order not paid twice on a run date, got %+v", report.Results)
+	}
+	stub.Advance(24 * time.Hour)
+	executeDueStandingOrders(t, stub)
+
+	stub.Advance(24 * time.Hour)
+	if report := executeDueStandingOrders(t, stub); len(report.Results) != 1 || report.Results[0].Error == "" {
+		t.Errorf("Expected the unfunded payment failed, got %+v", report.Results)
+	}
+	stub.Topup(t, "1001", "1", 1000)
+	if report := executeDueStandingOrders(t, stub); len(report.Results) != 1 || report.Results[0].RunDate != "2021-03-03" || report.Results[0].Error != "" {
+		t.Errorf("Expected the failed payment retried, got %+v", report.Results)
+	}
+
+	list := new(model.StandingOrderList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetStandingOrders", "1001", "1"), list); err != nil {
+		t.Fatal(err)
+	}
+	if order := list.Orders[0]; order.Status != model.StandingOrderCompleted || order.Runs != 3 || order.Missed != 0 || order.LastError != "" {
+		t.Errorf("Expected the order completed after three payments, got %+v", order)
+	}
+	if payer, payee := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); payer != 500 || payee != 3000 {
+		t.Errorf("Expected three payments made, got balances of %d and %d", payer, payee)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Statement handler functions
+//------------------------------
+
+// GetStatement query the transactions of an account between two dates, both
+// inclusive, with the opening, closing and running balances
+func (cc *Chaincode) GetStatement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 {
+		return nil, errors.New("Missing required customer ID, account ID, from date and / or to date")
+	}
+	start, end, err := model.ParseStatementPeriod(args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := cc.nearestBalanceSnapshot(stub, account, args[3])
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		txns, err := cc.accountTransactions(stub, account)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(model.CreateStatement(account, txns, start, end))
+	}
+	// only the transactions from the period start to the snapshot or the
+	// period end, whichever is later, are read
+	until := end.Unix()
+	if snapshot.Taken > until {
+		until = snapshot.Taken
+	}
+	txns, err := cc.accountTransactionsBetween(stub, account, start.Unix(), until)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(model.CreateStatementAt(account, txns, start, end, snapshot.Balance, snapshot.Taken))
+}
+
+// TakeBalanceSnapshots stores the end-of-day balance snapshot of one page of
+// accounts, keyed by the day of the transaction. Takes an optional page size
+// and the bookmark of the previous page; closed accounts are skipped. A
+// snapshot taken again the same day replaces the earlier one.
+func (cc *Chaincode) TakeBalanceSnapshots(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 2 {
+		return nil, errors.New("Too many arguments, expected optional page size and bookmark")
+	}
+	pageSize, err := listPageSizeArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	tx := txContext(stub)
+	report := &model.BalanceSnapshotReport{Day: tx.Time.UTC().Format(model.StatementDateFormat)}
+	report.NextBookmark, err = cc.scanCompositeKeyPage(stub, model.AccountObjectType, []string{}, optionalArg(args, 1), pageSize, func(_ string, accountBytes []byte) error {
+		account := new(model.Account)
+		if err := json.Unmarshal(accountBytes, account); err != nil {
+			loggerFor(stub).Errorf("Failed to get account details. Error: %s", err)
+			return nil
+		}
+		if account.ObjectType != model.AccountObjectType || account.Status == model.AccountClosed {
+			return nil
+		}
+		snapshot := model.CreateBalanceSnapshot(account, tx)
+		snapshotData, err := json.Marshal(snapshot)
+		if err != nil {
+			return fmt.Errorf("Error marshalling balance snapshot. Error: %s", err)
+		}
+		key, _ := cc.createCompositeKey(stub, snapshot.GetObjectType(), []string{snapshot.CustomerID, snapshot.AccountID, model.DayBucket(snapshot.Taken)})
+		if err := stub.PutState(key, snapshotData); err != nil {
+			return err
+		}
+		report.Snapshots++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(report)
+}
+
+// GetBalanceSnapshots query the end-of-day balance snapshots of an account,
+// oldest first. Takes an optional page size, bookmark and first and last day
+// (YYYY-MM-DD, inclusive).
+func (cc *Chaincode) GetBalanceSnapshots(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 2 || len(args) > 6 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	pageSize, err := listPageSizeArg(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	prefix, start, end, err := cc.dayRange(stub, model.BalanceSnapshotObjectType, args[:2], optionalArg(args, 4), optionalArg(args, 5))
+	if err != nil {
+		return nil, err
+	}
+	list := newListWriter("snapshots").EmptyAsArray()
+	nextBookmark, err := scanKeyRangePage(stub, prefix, start, end, optionalArg(args, 3), pageSize, func(_ string, snapshotBytes []byte) error {
+		return list.AddRaw(snapshotBytes)
+	})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get balance snapshots. Error: %s", err)
+		return nil, err
+	}
+	return list.Bytes(nextBookmark), nil
+}
+
+// nearestBalanceSnapshot returns the earliest balance snapshot of the account
+// taken on or after the day, or nil if there is none
+func (cc *Chaincode) nearestBalanceSnapshot(stub shim.ChaincodeStubInterface, account *model.Account, day string) (*model.BalanceSnapshot, error) {
+	prefix, start, end, err := cc.dayRange(stub, model.BalanceSnapshotObjectType, []string{account.CustomerID, account.ID}, day, "")
+	if err != nil {
+		return nil, err
+	}
+	snapshotsIter, err := compositeKeyRange(stub, prefix, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer snapshotsIter.Close()
+	if !snapshotsIter.HasNext() {
+		return nil, nil
+	}
+	_, snapshotBytes, err := nextKeyValue(snapshotsIter)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := new(model.BalanceSnapshot)
+	if err := bytesToStruct(snapshotBytes, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// accountTransactions returns the committed transaction records of an account
+func (cc *Chaincode) accountTransactions(stub shim.ChaincodeStubInterface, account *model.Account) ([]*model.Transaction, error) {
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TransactionObjectType, []string{account.CustomerID, account.ID})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction list. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	var txns []*model.Transaction
+	for keysIter.HasNext() {
+		txn := new(model.Transaction)
+		if err := json.Unmarshal(nextValue(keysIter), txn); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			continue
+		}
+		txns = append(txns, txn)
+	}
+	return txns, nil
+}
+
+// accountTransactionsBetween returns the committed transaction records of an
+// account created from the start until the end unix time, exclusive, reading
+// only the keys of those days. Transactions whose keys were not migrated by
+// MigrateTransactionKeys are missed.
+func (cc *Chaincode) accountTransactionsBetween(stub shim.ChaincodeStubInterface, account *model.Account, start int64, end int64) ([]*model.Transaction, error) {
+	prefix, startKey, endKey, err := cc.dayRange(stub, model.TransactionObjectType, []string{account.CustomerID, account.ID}, time.Unix(start, 0).UTC().Format(model.StatementDateFormat), time.Unix(end-1, 0).UTC().Format(model.StatementDateFormat))
+	if err != nil {
+		return nil, err
+	}
+	keysIter, err := compositeKeyRange(stub, prefix, startKey, endKey)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction list. Error: %s", err)
+		return nil, err
+	}
+	defer keysIter.Close()
+	var txns []*model.Transaction
+	for keysIter.HasNext() {
+		txn := new(model.Transaction)
+		if err := json.Unmarshal(nextValue(keysIter), txn); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			continue
+		}
+		if txn.Created >= start && txn.Created < end {
+			txns = append(txns, txn)
+		}
+	}
+	return txns, nil
+}
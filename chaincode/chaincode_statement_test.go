@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestGetStatementStartsFromNearestSnapshot(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON())
+	stub.Advance(24 * time.Hour)
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 2000).JSON())
+
+	report := new(model.BalanceSnapshotReport)
+	if err := json.Unmarshal(stub.As(testsupport.Operator(t, RoleRecordsAdmin)).MustCall(t, "TakeBalanceSnapshots"), report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Day != "2021-03-02" || report.Snapshots != 2 || report.NextBookmark != "" {
+		t.Fatalf("Expected both accounts snapshot on 2021-03-02, got %+v", report)
+	}
+
+	stub.Advance(24 * time.Hour)
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 4000).JSON())
+	// top-ups are not recorded as transactions, so only a snapshot taken after
+	// them accounts for them
+	stub.Topup(t, "1001", "1", 50000)
+
+	stub.As(testsupport.Operator(t, RoleTeller))
+	for _, c := range []struct {
+		from, to         string
+		opening, closing int64
+		lines            int
+	}{
+		{"2021-03-01", "2021-03-01", 100000, 99000, 1},
+		{"2021-03-02", "2021-03-02", 99000, 97000, 1},
+		{"2021-03-01", "2021-03-02", 100000, 97000, 2},
+	} {
+		statement := new(model.Statement)
+		if err := json.Unmarshal(stub.MustCall(t, "GetStatement", "1001", "1", c.from, c.to), statement); err != nil {
+			t.Fatal(err)
+		}
+		if statement.OpeningBalance != c.opening || statement.ClosingBalance != c.closing || len(statement.Lines) != c.lines {
+			t.Errorf("Expected statement from %s to %s from %d to %d with %d lines, got %d to %d with %d lines", c.from, c.to, c.opening, c.closing, c.lines, statement.OpeningBalance, statement.ClosingBalance, len(statement.Lines))
+		}
+	}
+
+	list := new(model.BalanceSnapshotList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetBalanceSnapshots", "1001", "1"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Snapshots) != 1 || list.Snapshots[0].Day != "2021-03-02" || list.Snapshots[0].Balance != 97000 {
+		t.Errorf("Expected the snapshot of 2021-03-02 with 97000, got %d snapshots", len(list.Snapshots))
+	}
+	if err := json.Unmarshal(stub.MustCall(t, "GetBalanceSnapshots", "1001", "1", "", "", "2021-03-03"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Snapshots) != 0 {
+		t.Errorf("Expected no snapshots from 2021-03-03, got %d", len(list.Snapshots))
+	}
+}
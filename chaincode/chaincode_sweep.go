@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Cash sweep handler functions
+//------------------------------
+
+// SetSweepRule configures the sweep rule of an account, replacing any existing rule
+func (cc *Chaincode) SetSweepRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required sweep rule data JSON")
+	}
+	rule, err := model.CreateSweepRule([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating sweep rule. Error: %s", err)
+	}
+	account, err := cc.getAccountStruct(stub, rule.CustomerID, rule.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	concentration, err := cc.getAccountStruct(stub, rule.ConcentrationCustomerID, rule.ConcentrationAccountID)
+	if err != nil {
+		return nil, err
+	}
+	// sweeps pay from both accounts
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, concentration); err != nil {
+		return nil, err
+	}
+	if account.CurrencyCode != concentration.CurrencyCode {
+		return nil, fmt.Errorf("Concentration account currency %s does not match %s", concentration.CurrencyCode, account.CurrencyCode)
+	}
+	return cc.putSweepRule(stub, rule)
+}
+
+// GetSweepRule query the sweep rule of an account
+func (cc *Chaincode) GetSweepRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	key, _ := cc.createCompositeKey(stub, model.SweepRuleObjectType, args)
+	return stub.GetState(key)
+}
+
+// RemoveSweepRule deletes the sweep rule of an account
+func (cc *Chaincode) RemoveSweepRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, account); err != nil {
+		return nil, err
+	}
+	key, _ := cc.createCompositeKey(stub, model.SweepRuleObjectType, args)
+	return nil, stub.DelState(key)
+}
+
+// RunSweeps runs every sweep rule that is due, moving the excess above the
+// target balance to the concentration account or funding a deficit from it.
+// A rule that cannot run leaves no state behind, is reported and retried on
+// the next invocation.
+func (cc *Chaincode) RunSweeps(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	now := txContext(stub).Time
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.SweepRuleObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get sweep rules. Error: %s", err)
+		return nil, err
+	}
+	var rules []*model.SweepRule
+	for keysIter.HasNext() {
+		ruleBytes := nextValue(keysIter)
+		rule := new(model.SweepRule)
+		if err := json.Unmarshal(ruleBytes, rule); err != nil {
+			loggerFor(stub).Errorf("Failed to get sweep rule details. Error: %s", err)
+			continue
+		}
+		if rule.Due(now) {
+			rules = append(rules, rule)
+		}
+	}
+
+	report := &model.SweepReport{Date: now.UTC().Format(model.PayDateFormat), Sweeps: []*model.SweepResult{}}
+	for _, rule := range rules {
+		result := &model.SweepResult{CustomerID: rule.CustomerID, AccountID: rule.AccountID}
+		var amount int64
+		err := atomically(stub, func() (err error) {
+			amount, err = cc.runSweep(stub, rule)
+			return err
+		})
+		if err != nil {
+			loggerFor(stub).Warningf("Sweep of account %s failed. Error: %s", rule.AccountID, err)
+			result.Error = err.Error()
+		} else {
+			result.Amount = amount
+			rule.LastRun = report.Date
+			if _, err := cc.putSweepRule(stub, rule); err != nil {
+				return nil, err
+			}
+		}
+		report.Sweeps = append(report.Sweeps, result)
+	}
+	return json.Marshal(report)
+}
+
+// runSweep applies a single sweep rule and returns the amount swept to the
+// concentration account, negative when the account was funded from it
+func (cc *Chaincode) runSweep(stub shim.ChaincodeStubInterface, rule *model.SweepRule) (int64, error) {
+	account, err := cc.getAccountStruct(stub, rule.CustomerID, rule.AccountID)
+	if err != nil {
+		return 0, err
+	}
+	concentration, err := cc.getAccountStruct(stub, rule.ConcentrationCustomerID, rule.ConcentrationAccountID)
+	if err != nil {
+		return 0, err
+	}
+	if !account.CanSend() || !concentration.CanSend() {
+		return 0, errors.New("Cannot sweep an account that is not active")
+	}
+	excess := rule.Excess(account.Unheld())
+	from, to, amount := account, concentration, excess
+	if excess < 0 {
+		from, to, amount = concentration, account, -excess
+		if amount > concentration.Unheld() {
+			amount = concentration.Unheld()
+		}
+	}
+	if amount <= 0 {
+		return 0, nil
+	}
+	t := &model.Transfer{
+		FromCustomerID: from.CustomerID,
+		FromAccountID:  from.ID,
+		ToCustomerID:   to.CustomerID,
+		ToAccountID:    to.ID,
+		Amount:         amount,
+		CurrencyCode:   from.CurrencyCode,
+		Description:    fmt.Sprintf("%s sweep", rule.Schedule),
+		Params:         map[string]string{"initiated_by": "system", "sweep_account": rule.AccountID},
+	}
+	if err := cc.debitAccount(stub, from, from.Money(amount)); err != nil {
+		return 0, err
+	}
+	if err := cc.recordTransaction(stub, from.CustomerID, from.ID, t, "", model.Debited); err != nil {
+		return 0, err
+	}
+	if err := cc.creditAccount(stub, to, to.Money(amount)); err != nil {
+		return 0, err
+	}
+	if err := cc.recordTransaction(stub, to.CustomerID, to.ID, t, "", model.Credited); err != nil {
+		return 0, err
+	}
+	if excess < 0 {
+		return -amount, nil
+	}
+	return amount, nil
+}
+
+func (cc *Chaincode) putSweepRule(stub shim.ChaincodeStubInterface, rule *model.SweepRule) ([]byte, error) {
+	ruleData, err := json.Marshal(rule)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling sweep rule data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, rule.GetObjectType(), []string{rule.CustomerID, rule.AccountID})
+	if err := stub.PutState(key, ruleData); err != nil {
+		return nil, err
+	}
+	return ruleData, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestRunSweepsKeepsAccountsAtTargetOncePerPeriod(t *testing.T) {
+	stub := newTestStub()
+	for _, accountID := range []string{"1", "2", "3"} {
+		stub.OpenAccount(t, testsupport.NewAccount("1001", accountID))
+	}
+	stub.Topup(t, "1001", "1", 50000)
+	stub.Topup(t, "1001", "2", 100000)
+	stub.Topup(t, "1001", "3", 10000)
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "SetSweepRule", `{"customer_id":"1001","account_id":"1","concentration_customer":"1001","concentration_account":"2","target_balance":20000,"schedule":"daily"}`)
+	stub.MustCall(t, "SetSweepRule", `{"customer_id":"1001","account_id":"3","concentration_customer":"1001","concentration_account":"2","target_balance":50000,"schedule":"daily"}`)
+
+	stub.As(testsupport.Operator(t, RoleAccountOperator))
+	report := new(model.SweepReport)
+	if err := json.Unmarshal(stub.MustCall(t, "RunSweeps"), report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Sweeps) != 2 || report.Sweeps[0].Amount != 30000 || report.Sweeps[1].Amount != -40000 {
+		t.Errorf("Expected the excess of 30000 swept and the deficit of 40000 funded, got %+v", report.Sweeps)
+	}
+	for accountID, expected := range map[string]int64{"1": 20000, "2": 90000, "3": 50000} {
+		if balance := balanceOf(t, stub, "1001", accountID); balance != expected {
+			t.Errorf("Expected account %s at %d, got %d", accountID, expected, balance)
+		}
+	}
+
+	stub.Topup(t, "1001", "1", 5000)
+	if err := json.Unmarshal(stub.MustCall(t, "RunSweeps"), report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Sweeps) != 0 {
+		t.Errorf("Expected no sweep before the next period, got %+v", report.Sweeps)
+	}
+	stub.Advance(24 * time.Hour)
+	if err := json.Unmarshal(stub.MustCall(t, "RunSweeps"), report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Sweeps) != 2 || report.Sweeps[0].Amount != 5000 || report.Sweeps[1].Amount != 0 {
+		t.Errorf("Expected only the new excess swept the next day, got %+v", report.Sweeps)
+	}
+}
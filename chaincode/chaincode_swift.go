@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/swift"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// SWIFT message handler functions
+//------------------------------
+
+// SubmitMT103 transfers money as instructed by a SWIFT MT103 message. The
+// message is mapped onto a transfer, which is then made as by TransferMoney.
+func (cc *Chaincode) SubmitMT103(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required MT103 message")
+	}
+	msg, err := swift.ParseMT103(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing MT103 message. Error: %s", err)
+	}
+	transferData, err := json.Marshal(msg.Transfer())
+	if err != nil {
+		return nil, err
+	}
+	return cc.TransferMoney(stub, []string{string(transferData)})
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Tenant handler functions
+//------------------------------
+
+// SetTenancyMode switches the deployment between a single shared key space and
+// per-tenant namespaces. State written in one mode is not visible in the other.
+func (cc *Chaincode) SetTenancyMode(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required tenancy mode")
+	}
+	mode := model.TenancyMode(args[0])
+	if mode != model.SingleTenant && mode != model.MultiTenant {
+		return nil, fmt.Errorf("Invalid tenancy mode %s", args[0])
+	}
+	config := &model.TenancyConfig{Entity: model.Entity{ObjectType: model.TenancyConfigObjectType}, Mode: mode}
+	configData, _ := json.Marshal(config)
+	key, _ := cc.createCompositeKey(stub, config.GetObjectType(), []string{})
+	if err := stub.PutState(key, configData); err != nil {
+		return nil, err
+	}
+	return configData, nil
+}
+
+// AssignTenant places the callers of an MSP in an explicit tenant namespace
+// instead of the namespace named after the MSP
+func (cc *Chaincode) AssignTenant(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required MSP ID and / or tenant ID")
+	}
+	operator, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := model.CreateTenant(args[0], args[1], operator)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating tenant. Error: %s", err)
+	}
+	tenantData, _ := json.Marshal(tenant)
+	key, _ := cc.createCompositeKey(stub, tenant.GetObjectType(), []string{tenant.MSPID})
+	if err := stub.PutState(key, tenantData); err != nil {
+		return nil, err
+	}
+	return tenantData, nil
+}
+
+// GetTenant query the tenancy mode and the caller's tenant
+func (cc *Chaincode) GetTenant(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	tenant := ""
+	if ts, ok := stub.(*tenantStub); ok {
+		tenant = ts.tenant
+	}
+	config, err := cc.getTenancyConfig(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]string{"mode": string(config.Mode), "tenant_id": tenant})
+}
+
+// GetTenantAccounts query all accounts of the caller's tenant. The network
+// operator may pass another tenant ID.
+func (cc *Chaincode) GetTenantAccounts(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	scoped, err := cc.tenantAccess(stub, optionalArg(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(scoped, model.AccountObjectType, []string{})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get account list. Error: %s", err)
+		return nil, err
+	}
+	accountList := model.AccountList{Accounts: []*model.Account{}}
+	for keysIter.HasNext() {
+		accountBytes := nextValue(keysIter)
+		acc := new(model.Account)
+		if err := json.Unmarshal(accountBytes, acc); err != nil {
+			loggerFor(stub).Errorf("Failed to get account details. Error: %s", err)
+			continue
+		}
+		accountList.Accounts = append(accountList.Accounts, acc)
+	}
+	return json.Marshal(accountList)
+}
+
+// GetTenantTransactions query the transactions of the caller's tenant,
+// optionally of a single customer. The network operator may pass another tenant ID.
+func (cc *Chaincode) GetTenantTransactions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	scoped, err := cc.tenantAccess(stub, optionalArg(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	if customerID := optionalArg(args, 1); customerID != "" {
+		keys = append(keys, customerID)
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(scoped, model.TransactionObjectType, keys)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction list. Error: %s", err)
+		return nil, err
+	}
+	txList := model.TransactionList{Transactions: []*model.Transaction{}}
+	for keysIter.HasNext() {
+		txnBytes := nextValue(keysIter)
+		txn := new(model.Transaction)
+		if err := json.Unmarshal(txnBytes, txn); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			continue
+		}
+		txList.Transactions = append(txList.Transactions, txn)
+	}
+	return json.Marshal(txList)
+}
+
+// tenantScope returns the stub handlers run with: the stub itself in single
+// tenant mode, otherwise a stub namespaced to the caller's tenant
+func (cc *Chaincode) tenantScope(stub shim.ChaincodeStubInterface) (shim.ChaincodeStubInterface, error) {
+	config, err := cc.getTenancyConfig(stub)
+	if err != nil {
+		return nil, err
+	}
+	if config.Mode != model.MultiTenant {
+		return stub, nil
+	}
+	mspID, err := callerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := mspID
+	key, _ := cc.createCompositeKey(stub, model.TenantObjectType, []string{mspID})
+	tenantBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if tenantBytes != nil {
+		tenant := new(model.Tenant)
+		if err := bytesToStruct(tenantBytes, tenant); err != nil {
+			return nil, err
+		}
+		tenantID = tenant.TenantID
+	}
+	if err := model.ValidateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	return &tenantStub{ChaincodeStubInterface: stub, tenant: tenantID}, nil
+}
+
+// tenantAccess returns a stub scoped to the given tenant. Only the network
+// operator may reach into a tenant other than the caller's own.
+func (cc *Chaincode) tenantAccess(stub shim.ChaincodeStubInterface, tenantID string) (shim.ChaincodeStubInterface, error) {
+	ts, ok := stub.(*tenantStub)
+	if tenantID == "" || (ok && tenantID == ts.tenant) {
+		return stub, nil
+	}
+	if !ok {
+		return nil, errors.New("Tenant scoped queries require multi-tenant mode")
+	}
+	if err := cc.requireRole(stub, RoleNetworkOperator); err != nil {
+		return nil, fmt.Errorf("Cross-tenant access denied. Error: %s", err)
+	}
+	if err := model.ValidateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	return &tenantStub{ChaincodeStubInterface: ts.ChaincodeStubInterface, tenant: tenantID}, nil
+}
+
+// payeeScope returns the stub the payee side of a transfer is read and credited
+// through. Crediting an account and recording its incoming transaction is the
+// only write a tenant may make into another tenant's namespace.
+func (cc *Chaincode) payeeScope(stub shim.ChaincodeStubInterface, tenantID string) (shim.ChaincodeStubInterface, error) {
+	ts, ok := stub.(*tenantStub)
+	if !ok || tenantID == "" || tenantID == ts.tenant {
+		return stub, nil
+	}
+	if err := model.ValidateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	return &tenantStub{ChaincodeStubInterface: ts.ChaincodeStubInterface, tenant: tenantID}, nil
+}
+
+func (cc *Chaincode) getTenancyConfig(stub shim.ChaincodeStubInterface) (*model.TenancyConfig, error) {
+	config := &model.TenancyConfig{Entity: model.Entity{ObjectType: model.TenancyConfigObjectType}, Mode: model.SingleTenant}
+	key, _ := cc.createCompositeKey(stub, config.GetObjectType(), []string{})
+	configBytes, err := stub.GetState(key)
+	if err != nil || configBytes == nil {
+		return config, err
+	}
+	if err := bytesToStruct(configBytes, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// testChaincode is the receiver of the registered handlers the tests invoke
+var testChaincode = new(Chaincode)
+
+func TestMain(m *testing.M) {
+	// models format timestamps in the local time zone, which the golden files must not depend on
+	time.Local = time.UTC
+	testChaincode.registerHandlers()
+	os.Exit(m.Run())
+}
+
+func newTestStub() *testsupport.Stub {
+	return testsupport.NewStub(testChaincode.handleInvocation)
+}
+
+// registeredFunctions returns the names of the registered handler functions,
+// sorted, without those the tests register themselves
+func registeredFunctions() []string {
+	var names []string
+	for name := range handlerMap.handlers {
+		if !strings.HasPrefix(name, "Test") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestEveryHandlerRejectsMissingArguments(t *testing.T) {
+	for _, function := range registeredFunctions() {
+		t.Run(function, func(t *testing.T) {
+			role := RoleAccountOperator
+			if roles := handlerMap.Roles(function); len(roles) > 0 {
+				role = roles[0]
+			}
+			for _, args := range [][]string{{}, {"{"}} {
+				stub := newTestStub().As(testsupport.Operator(t, role))
+				_, err := stub.Call(function, args...)
+				if _, ok := err.(*handlerPanic); ok {
+					t.Fatalf("Handler panicked with args %q: %s", args, err)
+				}
+				if err != nil && (len(stub.State) > 0 || len(stub.Events) > 0) {
+					t.Errorf("Expected no writes or events of the failed invocation with args %q, got %d keys and %d events", args, len(stub.State), len(stub.Events))
+				}
+			}
+		})
+	}
+}
+
+func TestEveryRestrictedHandlerRejectsCallersWithoutRole(t *testing.T) {
+	for _, function := range registeredFunctions() {
+		roles := handlerMap.Roles(function)
+		if len(roles) == 0 {
+			continue
+		}
+		t.Run(function, func(t *testing.T) {
+			stub := newTestStub().As(testsupport.Anonymous(t))
+			_, err := stub.Call(function)
+			if err == nil || !strings.Contains(err.Error(), "Caller is not authorized as "+strings.Join(roles, " or ")) {
+				t.Errorf("Expected the caller to be rejected, got %v", err)
+			}
+			if len(stub.State) > 0 {
+				t.Errorf("Expected no writes, got %d keys", len(stub.State))
+			}
+		})
+	}
+}
+
+func TestTransferBetweenCustomers(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+
+	stub.As(testsupport.Customer(t, "1001"))
+	res := stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 25000).JSON())
+	result := new(model.TransferResult)
+	if err := json.Unmarshal(res, result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != model.TransferCompleted || result.TxID != "tx10" || result.Balance == nil || *result.Balance != 75000 {
+		t.Errorf("Expected the transfer completed in tx10 with 75000 left, got %s", res)
+	}
+	if len(stub.Events) != 1 || !strings.Contains(string(stub.Events[0].Payload), model.EventTransferCompleted) {
+		t.Errorf("Expected the transfer event, got %v", stub.Events)
+	}
+	testsupport.AssertGolden(t, "transfer_payer_balance", stub.MustCall(t, "GetBalance", "1001", "1"))
+
+	stub.As(testsupport.Customer(t, "1002"))
+	testsupport.AssertGolden(t, "transfer_payee_account", stub.MustCall(t, "GetAccount", "1002", "1"))
+	list := new(model.TransactionList)
+	if err := json.Unmarshal(stub.MustCall(t, "GetTransactionList", "1002", "1"), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Transactions) != 1 {
+		t.Fatalf("Expected the credit only, got %d transactions", len(list.Transactions))
+	}
+	credit := list.Transactions[0]
+	if credit.Status != model.Credited || credit.Amount != 25000 || credit.CounterpartyCustomerID != "1001" || credit.TxID != "tx10" {
+		t.Errorf("Unexpected credit %+v", credit)
+	}
+}
+
+func TestTransferRejectsInsufficientFunds(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "2"))
+	stub.Topup(t, "1001", "1", 1000)
+
+	stub.As(testsupport.Customer(t, "1001"))
+	_, err := stub.Call("TransferMoney", testsupport.NewTransfer("1001", "1", "1001", "2", 1001).JSON())
+	if err == nil || !strings.Contains(err.Error(), "Insufficient funds") {
+		t.Fatalf("Expected insufficient funds, got %v", err)
+	}
+	balance := new(model.Balance)
+	if err := json.Unmarshal(stub.MustCall(t, "GetBalance", "1001", "1"), balance); err != nil {
+		t.Fatal(err)
+	}
+	if balance.Balance != 1000 || balance.AsOfTx != "tx6" {
+		t.Errorf("Expected the balance of the topup untouched, got %+v", balance)
+	}
+}
+
+func TestTransferRejectsOtherCustomers(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 1000)
+
+	stub.As(testsupport.Customer(t, "1002"))
+	_, err := stub.Call("TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 500).JSON())
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Errorf("Expected the payee to be rejected as payer, got %v", err)
+	}
+}
+
+func TestOpenAccountIgnoresClientBalance(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+
+	stub.As(testsupport.Operator(t, RoleTeller)).MustCall(t, "OpenAccount", `{"id":"2","customer_id":"1001","currency":"AUD","balance":100000}`)
+	if balance := balanceOf(t, stub, "1001", "2"); balance != 0 {
+		t.Errorf("Expected the account opened empty, got a balance of %d", balance)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//---------------------------------------
+// Transaction index handler functions
+//---------------------------------------
+
+// QueryTransactionIndex query the transactions of a secondary index range, e.g.
+// the failed transfers to an account in a month, as a range scan of the index
+// instead of a scan of every transaction. Takes the index query JSON, an
+// optional page size and an optional bookmark; transactions are returned in
+// order of their creation day.
+func (cc *Chaincode) QueryTransactionIndex(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 || len(args) > 3 {
+		return nil, errors.New("Missing required index query JSON")
+	}
+	query := new(model.TransactionIndexQuery)
+	if err := json.Unmarshal([]byte(args[0]), query); err != nil {
+		return nil, fmt.Errorf("Error parsing index query. Error: %s", err)
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	pageSize, err := listPageSizeArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	attributes := append([]string{string(query.Index)}, query.Values...)
+	prefix, start, end, err := cc.dayRange(stub, model.TransactionIndexObjectType, attributes, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	list := newListWriter("transactions").EmptyAsArray()
+	nextBookmark, err := scanKeyRangePage(stub, prefix, start, end, optionalArg(args, 2), pageSize, func(_ string, txnKey []byte) error {
+		txnBytes, err := stub.GetState(string(txnKey))
+		if err != nil {
+			return err
+		}
+		if txnBytes == nil {
+			loggerFor(stub).Warningf("Ignoring index entry of missing transaction %q", txnKey)
+			return nil
+		}
+		return list.AddRaw(txnBytes)
+	})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to query transaction index %s. Error: %s", query.Index, err)
+		return nil, err
+	}
+	return list.Bytes(nextBookmark), nil
+}
+
+// RebuildTransactionIndexes writes the secondary index entries of one page of
+// transactions, for transactions recorded or imported before the indexes
+// existed. Takes an optional page size and the bookmark of the previous page.
+// Entries are written idempotently, so the pages may be rebuilt again.
+func (cc *Chaincode) RebuildTransactionIndexes(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 2 {
+		return nil, errors.New("Too many arguments, expected optional page size and bookmark")
+	}
+	pageSize, err := listPageSizeArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	report := new(model.TransactionIndexReport)
+	report.NextBookmark, err = cc.scanCompositeKeyPage(stub, model.TransactionObjectType, []string{}, optionalArg(args, 1), pageSize, func(key string, txnBytes []byte) error {
+		txn := new(model.Transaction)
+		if err := json.Unmarshal(txnBytes, txn); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			return nil
+		}
+		if err := cc.indexTransaction(stub, txn, key); err != nil {
+			return err
+		}
+		report.Indexed++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(report)
+}
+
+// indexTransaction writes the entries of a transaction in the secondary
+// indexes, each holding the transaction key
+func (cc *Chaincode) indexTransaction(stub shim.ChaincodeStubInterface, txn *model.Transaction, txnKey string) error {
+	for _, index := range model.TransactionIndexes {
+		key, ok := cc.transactionIndexKey(stub, index, txn)
+		if !ok {
+			continue
+		}
+		if err := stub.PutState(key, []byte(txnKey)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindexTransaction deletes the secondary index entries of a transaction
+func (cc *Chaincode) unindexTransaction(stub shim.ChaincodeStubInterface, txn *model.Transaction) error {
+	for _, index := range model.TransactionIndexes {
+		key, ok := cc.transactionIndexKey(stub, index, txn)
+		if !ok {
+			continue
+		}
+		if err := stub.DelState(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transactionIndexKey returns the key of the entry of a transaction in the
+// index, and false if the index does not cover the transaction
+func (cc *Chaincode) transactionIndexKey(stub shim.ChaincodeStubInterface, index model.TransactionIndex, txn *model.Transaction) (string, bool) {
+	values, ok := txn.IndexAttributes(index)
+	if !ok {
+		return "", false
+	}
+	attributes := append([]string{string(index)}, values...)
+	attributes = append(attributes, model.DayBucket(txn.Created), txn.CustomerID, txn.AccountID, txn.ID)
+	key, err := cc.createCompositeKey(stub, model.TransactionIndexObjectType, attributes)
+	return key, err == nil
+}
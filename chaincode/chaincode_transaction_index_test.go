@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestQueryTransactionIndexRanges(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON())
+	stub.Advance(24 * time.Hour)
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 2000).JSON())
+
+	stub.As(testsupport.Operator(t, RoleTeller))
+	for _, c := range []struct {
+		query   string
+		amounts []int64
+	}{
+		{`{"index":"counterparty","values":["1002","1","debited"]}`, []int64{1000, 2000}},
+		{`{"index":"counterparty","values":["1002","1","debited"],"from":"2021-03-02","to":"2021-03-31"}`, []int64{2000}},
+		{`{"index":"counterparty","values":["1002","1","failed"],"from":"2021-03-01","to":"2021-03-31"}`, []int64{}},
+		{`{"index":"counterparty","values":["1001","1"]}`, []int64{1000, 2000}},
+		{`{"index":"status","values":["credited"],"to":"2021-03-01"}`, []int64{1000}},
+		{`{"index":"currency","values":["AUD"]}`, []int64{1000, 1000, 2000, 2000}},
+		{`{"index":"date","from":"2021-03-02"}`, []int64{2000, 2000}},
+	} {
+		list := new(model.TransactionList)
+		if err := json.Unmarshal(stub.MustCall(t, "QueryTransactionIndex", c.query), list); err != nil {
+			t.Fatal(err)
+		}
+		var amounts []int64
+		for _, txn := range list.Transactions {
+			amounts = append(amounts, txn.Amount)
+		}
+		if fmt.Sprint(amounts) != fmt.Sprint(c.amounts) {
+			t.Errorf("Query %s returned amounts %v, expected %v", c.query, amounts, c.amounts)
+		}
+	}
+
+	for _, query := range []string{
+		`{"index":"amount"}`,
+		`{"index":"status","values":["credited","AUD"]}`,
+		`{"index":"counterparty","values":["1002"],"from":"2021-03-01"}`,
+		`{"index":"date","from":"2021-03-31","to":"2021-03-01"}`,
+		`{"index":"date","from":"1 March"}`,
+	} {
+		if _, err := stub.Call("QueryTransactionIndex", query); err == nil {
+			t.Errorf("Expected query %s rejected", query)
+		}
+	}
+}
+
+func TestRebuildTransactionIndexesRestoresDeletedEntries(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON())
+
+	indexed := func() int {
+		n := 0
+		for key := range stub.State {
+			if objectType, _, err := stub.SplitCompositeKey(key); err == nil && objectType == model.TransactionIndexObjectType {
+				n++
+			}
+		}
+		return n
+	}
+	// a debit and a credit, each in all four indexes
+	if n := indexed(); n != 8 {
+		t.Fatalf("Expected 8 index entries, got %d", n)
+	}
+	stub.MockTransactionStart("drop")
+	for key := range stub.State {
+		if objectType, _, err := stub.SplitCompositeKey(key); err == nil && objectType == model.TransactionIndexObjectType {
+			stub.DelState(key)
+		}
+	}
+	stub.MockTransactionEnd("drop")
+
+	report := new(model.TransactionIndexReport)
+	res := stub.As(testsupport.Operator(t, RoleNetworkOperator)).MustCall(t, "RebuildTransactionIndexes")
+	if err := json.Unmarshal(res, report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Indexed != 2 || report.NextBookmark != "" || indexed() != 8 {
+		t.Errorf("Expected both transactions indexed again, got %s and %d entries", res, indexed())
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Transfer approval handler functions
+//------------------------------
+
+// SetApprovalPolicy sets the threshold above which transfers in a currency are
+// held for approval and the number of approvals they need, replacing any
+// existing policy. Restricted to compliance officers.
+func (cc *Chaincode) SetApprovalPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required approval policy data JSON")
+	}
+	setBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := model.CreateApprovalPolicy([]byte(args[0]), setBy, txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating approval policy. Error: %s", err)
+	}
+	policyData, _ := json.Marshal(policy)
+	key, _ := cc.createCompositeKey(stub, policy.GetObjectType(), []string{policy.CurrencyCode})
+	if err := stub.PutState(key, policyData); err != nil {
+		return nil, err
+	}
+	return policyData, nil
+}
+
+// GetApprovalPolicy query the approval policy of a currency
+func (cc *Chaincode) GetApprovalPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required currency")
+	}
+	key, _ := cc.createCompositeKey(stub, model.ApprovalPolicyObjectType, args)
+	return stub.GetState(key)
+}
+
+// ApproveTransfer records the calling approver's approval of a transfer held
+// for approval and executes it once it has the approvals its policy requires.
+// If the transfer cannot be executed, it stays pending approval. Restricted to
+// transfer approvers.
+func (cc *Chaincode) ApproveTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, account ID and / or transfer ID")
+	}
+	approval, err := cc.getTransferApproval(stub, args[0], args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	if approval == nil {
+		return nil, fmt.Errorf("Transfer %s pending approval not found.", args[2])
+	}
+	approver, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := approval.Approve(approver, txContext(stub)); err != nil {
+		return nil, err
+	}
+	if approval.Approved() {
+		fromAccount, err := cc.getAccountStruct(stub, approval.Transfer.FromCustomerID, approval.Transfer.FromAccountID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := cc.submitTransfer(stub, fromAccount, approval.Transfer); err != nil {
+			return nil, fmt.Errorf("Approved transfer %s cannot be executed. Error: %s", approval.ID, err)
+		}
+		approval.Settle(txContext(stub))
+	}
+	return cc.putTransferApproval(stub, approval)
+}
+
+// ListPendingApprovals query the transfers awaiting approval, optionally only
+// those of a customer or account
+func (cc *Chaincode) ListPendingApprovals(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 2 {
+		return nil, errors.New("Too many arguments, expected an optional customer ID and account ID")
+	}
+	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TransferApprovalObjectType, args)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transfer approvals. Error: %s", err)
+		return nil, err
+	}
+	list := model.TransferApprovalList{Approvals: []*model.TransferApproval{}}
+	for keysIter.HasNext() {
+		approvalBytes := nextValue(keysIter)
+		approval := new(model.TransferApproval)
+		if err := json.Unmarshal(approvalBytes, approval); err != nil {
+			loggerFor(stub).Errorf("Failed to get transfer approval details. Error: %s", err)
+			continue
+		}
+		if approval.Status == model.PendingApproval {
+			list.Approvals = append(list.Approvals, approval)
+		}
+	}
+	return json.Marshal(list)
+}
+
+// queueForApproval holds a transfer for approval if the approval policy of its
+// currency requires it, returning the queued record, or nil if it does not
+func (cc *Chaincode) queueForApproval(stub shim.ChaincodeStubInterface, t *model.Transfer) ([]byte, error) {
+	key, _ := cc.createCompositeKey(stub, model.ApprovalPolicyObjectType, []string{t.CurrencyCode})
+	policyBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get approval policy details. Error: %s", err)
+		return nil, err
+	}
+	if policyBytes == nil {
+		return nil, nil
+	}
+	policy := new(model.ApprovalPolicy)
+	if err := bytesToStruct(policyBytes, policy); err != nil {
+		return nil, err
+	}
+	if !policy.Requires(t) {
+		return nil, nil
+	}
+	initiator, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	approval := model.CreateTransferApproval(stub.GetTxID(), t, policy.RequiredApprovals, initiator, txContext(stub))
+	return cc.putTransferApproval(stub, approval)
+}
+
+// rejectTransferApproval rejects a transfer held for approval. Restricted to
+// transfer approvers.
+func (cc *Chaincode) rejectTransferApproval(stub shim.ChaincodeStubInterface, approval *model.TransferApproval, reason string) ([]byte, error) {
+	if err := cc.requireRole(stub, RoleTransferApprover); err != nil {
+		return nil, err
+	}
+	approver, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := approval.Reject(approver, reason, txContext(stub)); err != nil {
+		return nil, err
+	}
+	if err := emitTransferEvent(stub, approval.Transfer, errors.New(reason)); err != nil {
+		return nil, err
+	}
+	return cc.putTransferApproval(stub, approval)
+}
+
+// getTransferApproval returns the transfer held for approval, or nil if there is none
+func (cc *Chaincode) getTransferApproval(stub shim.ChaincodeStubInterface, customerID string, accountID string, transferID string) (*model.TransferApproval, error) {
+	key, _ := cc.createCompositeKey(stub, model.TransferApprovalObjectType, []string{customerID, accountID, transferID})
+	approvalBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transfer approval details. Error: %s", err)
+		return nil, err
+	}
+	if approvalBytes == nil {
+		return nil, nil
+	}
+	approval := new(model.TransferApproval)
+	if err := bytesToStruct(approvalBytes, approval); err != nil {
+		return nil, err
+	}
+	return approval, nil
+}
+
+func (cc *Chaincode) putTransferApproval(stub shim.ChaincodeStubInterface, approval *model.TransferApproval) ([]byte, error) {
+	approvalData, err := json.Marshal(approval)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling transfer approval data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, approval.GetObjectType(), []string{approval.Transfer.FromCustomerID, approval.Transfer.FromAccountID, approval.ID})
+	if err := stub.PutState(key, approvalData); err != nil {
+		return nil, err
+	}
+	return approvalData, nil
+}
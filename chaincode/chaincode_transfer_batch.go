@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Batch transfer handler functions
+//------------------------------
+
+// TransferBatch applies a list of transfers in one invocation, e.g. a payroll
+// payout. Every transfer is validated and authorized before any is applied. In
+// atomic mode the batch fails as a whole on the first failing transfer; in
+// partial mode failing transfers are skipped, leaving no state behind, and
+// reported in the batch result.
+func (cc *Chaincode) TransferBatch(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required transfer batch JSON")
+	}
+	batch, err := model.CreateTransferBatch([]byte(args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating transfer batch. Error: %s", err)
+	}
+	authorized := make(map[string]bool)
+	for i, t := range batch.Transfers {
+		payerKey, _ := cc.createCompositeKey(stub, model.AccountObjectType, []string{t.FromCustomerID, t.FromAccountID})
+		if authorized[payerKey] {
+			continue
+		}
+		payer, err := cc.getAccountStruct(stub, t.FromCustomerID, t.FromAccountID)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid transfer %d. Error: %s", i, err)
+		}
+		if err := cc.authorize(stub, auth.Transfer, payer); err != nil {
+			return nil, err
+		}
+		if err := cc.requireKYC(stub, payer.CustomerID); err != nil {
+			return nil, fmt.Errorf("Invalid transfer %d. Error: %s", i, err)
+		}
+		if payer.IsMultiSig() {
+			return nil, fmt.Errorf("Invalid transfer %d. Multi-signature account %s cannot transfer in a batch", i, payer.ID)
+		}
+		authorized[payerKey] = true
+	}
+
+	now := txContext(stub).Time.Unix()
+	result := &model.TransferBatchResult{Mode: batch.Mode, Total: len(batch.Transfers), Items: []*model.TransferBatchItem{}}
+	for i, t := range batch.Transfers {
+		t.Initiated = now
+		item := &model.TransferBatchItem{Index: i, Status: "settled"}
+		if batch.Mode == model.BatchAtomic {
+			if _, err := cc.executeTransfer(stub, t); err != nil {
+				return nil, fmt.Errorf("Transfer %d failed, no transfer of the batch was applied. Error: %s", i, err)
+			}
+		} else {
+			err := atomically(stub, func() error {
+				_, err := cc.executeTransfer(stub, t)
+				return err
+			})
+			if err != nil {
+				loggerFor(stub).Warningf("Transfer %d of batch failed. Error: %s", i, err)
+				item.Status = "failed"
+				item.Error = err.Error()
+				item.FailureCode = failureCode(err)
+				result.Failed++
+				result.Items = append(result.Items, item)
+				if _, err := cc.transferFailed(stub, t, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		item.Fee = t.Fee
+		result.Settled++
+		result.Amount += t.Amount
+		result.Fees += t.Fee
+		result.Items = append(result.Items, item)
+	}
+	return json.Marshal(result)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestTransferBatchModes(t *testing.T) {
+	stub := newTestStub()
+	for _, customerID := range []string{"1001", "1002", "1003"} {
+		stub.OpenAccount(t, testsupport.NewAccount(customerID, "1"))
+	}
+	stub.Topup(t, "1001", "1", 1000)
+	// the second transfer is not covered once the first is made
+	transfers := strings.Join([]string{
+		testsupport.NewTransfer("1001", "1", "1002", "1", 600).JSON(),
+		testsupport.NewTransfer("1001", "1", "1003", "1", 600).JSON(),
+		testsupport.NewTransfer("1001", "1", "1003", "1", 300).JSON(),
+	}, ",")
+	stub.As(testsupport.Customer(t, "1001"))
+
+	if _, err := stub.Call("TransferBatch", `{"mode":"atomic","transfers":[`+transfers+`]}`); err == nil || !strings.Contains(err.Error(), "Transfer 1 failed, no transfer of the batch was applied") {
+		t.Errorf("Expected the atomic batch failed as a whole, got %v", err)
+	}
+	if balance := balanceOf(t, stub, "1001", "1"); balance != 1000 {
+		t.Errorf("Expected no transfer of the atomic batch applied, got a balance of %d", balance)
+	}
+
+	result := new(model.TransferBatchResult)
+	if err := json.Unmarshal(stub.MustCall(t, "TransferBatch", `{"mode":"partial","transfers":[`+transfers+`]}`), result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Settled != 2 || result.Failed != 1 || result.Amount != 900 || result.Items[1].Status != "failed" || result.Items[1].Error == "" {
+		t.Errorf("Expected the uncovered transfer reported and the others settled, got %+v", result)
+	}
+	if payer, first, second := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"), balanceOf(t, stub, "1003", "1"); payer != 100 || first != 600 || second != 300 {
+		t.Errorf("Expected the settled transfers applied, got balances of %d, %d and %d", payer, first, second)
+	}
+}
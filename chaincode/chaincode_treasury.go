@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Treasury handler functions
+//------------------------------
+
+// SetNostroAccount designates the nostro account a bank funds settlements from in a currency
+func (cc *Chaincode) SetNostroAccount(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required bank ID, nostro customer ID and / or nostro account ID")
+	}
+	account, err := cc.getAccountStruct(stub, args[1], args[2])
+	if err != nil {
+		return nil, err
+	}
+	treasury, err := cc.getTreasury(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	position := treasury.Position(account.CurrencyCode, txContext(stub))
+	position.NostroCustomerID = account.CustomerID
+	position.NostroAccountID = account.ID
+	return cc.putTreasury(stub, treasury)
+}
+
+// GetTreasuryPosition query a bank's positions by currency, with nostro balances read from the ledger
+func (cc *Chaincode) GetTreasuryPosition(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Missing required bank ID")
+	}
+	treasury, err := cc.getTreasury(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, position := range treasury.Positions {
+		if position.NostroAccountID == "" {
+			continue
+		}
+		account, err := cc.getAccountStruct(stub, position.NostroCustomerID, position.NostroAccountID)
+		if err != nil {
+			return nil, err
+		}
+		position.NostroBalance = account.Balance
+	}
+	return json.Marshal(treasury.View())
+}
+
+// updateTreasury applies a change to a bank's position in a currency
+func (cc *Chaincode) updateTreasury(stub shim.ChaincodeStubInterface, bankID string, currency string, update func(*model.CurrencyPosition)) error {
+	if bankID == "" {
+		return nil
+	}
+	treasury, err := cc.getTreasury(stub, bankID)
+	if err != nil {
+		return err
+	}
+	update(treasury.Position(currency, txContext(stub)))
+	_, err = cc.putTreasury(stub, treasury)
+	return err
+}
+
+// recordInterbankFlow tracks an unsettled payment between two banks as pending
+// out for the paying bank and pending in for the receiving bank
+func (cc *Chaincode) recordInterbankFlow(stub shim.ChaincodeStubInterface, fromBank string, toBank string, currency string, amount int64) error {
+	if fromBank == "" || toBank == "" || fromBank == toBank {
+		return nil
+	}
+	if err := cc.updateTreasury(stub, fromBank, currency, func(p *model.CurrencyPosition) { p.PendingOut += amount }); err != nil {
+		return err
+	}
+	return cc.updateTreasury(stub, toBank, currency, func(p *model.CurrencyPosition) { p.PendingIn += amount })
+}
+
+// releaseInterbankFlow removes a settled payment between two banks from the
+// paying bank's pending out and the receiving bank's pending in
+func (cc *Chaincode) releaseInterbankFlow(stub shim.ChaincodeStubInterface, fromBank string, toBank string, currency string, amount int64) error {
+	return cc.recordInterbankFlow(stub, fromBank, toBank, currency, -amount)
+}
+
+func (cc *Chaincode) getTreasury(stub shim.ChaincodeStubInterface, bankID string) (*model.Treasury, error) {
+	key, _ := cc.createCompositeKey(stub, model.TreasuryObjectType, []string{bankID})
+	treasuryBytes, err := stub.GetState(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get treasury details. Error: %s", err)
+		return nil, err
+	}
+	if treasuryBytes == nil {
+		return model.CreateTreasury(bankID), nil
+	}
+	treasury := new(model.Treasury)
+	if err := bytesToStruct(treasuryBytes, treasury); err != nil {
+		return nil, err
+	}
+	return treasury, nil
+}
+
+func (cc *Chaincode) putTreasury(stub shim.ChaincodeStubInterface, treasury *model.Treasury) ([]byte, error) {
+	treasuryData, err := json.Marshal(treasury)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling treasury data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, treasury.GetObjectType(), []string{treasury.BankID})
+	if err := stub.PutState(key, treasuryData); err != nil {
+		return nil, err
+	}
+	return treasuryData, nil
+}
@@ -12,16 +12,21 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 	"unicode/utf8"
 
+	"github.com/iShamSLam/chaincode/auth"
 	"github.com/iShamSLam/chaincode/model"
 
-	"github.com/hyperledger/fabric/core/chaincode/shim" // v0.6
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
 )
 
 var (
 	// passport chaincode application logger
-	logger = shim.NewLogger("passport-chaincode")
+	logger = NewLogger("passport-chaincode")
 	// mapping of chaincode handler functions
 	handlerMap = NewHandlerMap()
 )
@@ -30,42 +35,78 @@ func main() {
 	initLogging()
 	cc := new(Chaincode)
 	cc.registerHandlers()
-	err := shim.Start(cc)
+	chaincode, err := contractapi.NewChaincode(newSmartContract(cc))
 	if err != nil {
+		logger.Errorf("Error creating chaincode: %s", err)
+		return
+	}
+	if err := shim.Start(&traceChaincode{chaincode}); err != nil {
 		logger.Errorf("Error starting chaincode: %s", err)
 	}
 }
 
 // Chaincode Chaincode shim method receiver struct
-type Chaincode struct{}
+type Chaincode struct {
+	// authorizer decides who may act on an account, defaultAuthorizer if nil
+	authorizer auth.Authorizer
+}
 
 //------------------------
 // Chaincode API functions
 //------------------------
 
-// Init called to initialize the chaincode
-func (cc *Chaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	return nil, nil
-}
-
-// Invoke chaincode interface implementation
-func (cc *Chaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	return cc.handleInvocation(stub, function, args)
-}
-
-// Query chaincode interface implementation
-func (cc *Chaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	return cc.handleInvocation(stub, function, args)
-}
-
-func (cc *Chaincode) handleInvocation(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	logger.Debugf("Invoking chaincode handler function %s with args %v", function, args)
+// handleInvocation runs a registered handler function on behalf of a contract
+// transaction, see contract.go
+func (cc *Chaincode) handleInvocation(stub shim.ChaincodeStubInterface, function string, args []string) (res []byte, err error) {
+	args, traceparent := invocationTraceparent(stub, args)
+	log := logger.ForInvocation(function, stub.GetTxID())
+	if traceparent != "" {
+		log = log.With(model.TraceparentField, traceparent)
+	}
+	log.Debugf("Invoking chaincode handler function with args %v", args)
+	if handlerMap.Registered(function) {
+		defer func(start time.Time) {
+			metrics.observe(function, time.Since(start), err != nil)
+		}(time.Now())
+	}
+	// a panic discards the buffered writes and events along with the result
+	defer recoverInvocation(log, function, &res, &err)
 
-	res, err := handlerMap.Handle(stub, function, args)
+	tx, err := newTxStub(stub)
 	if err != nil {
-		logger.Errorf("Error when calling handler for function %s. Error: %s", function, err)
+		log.Errorf("Error starting transaction. Error: %s", err)
+		return nil, err
 	}
-	return res, err
+	tx.logger = log
+	tx.context.Traceparent = traceparent
+	scoped, err := cc.tenantScope(newPrivateStub(cc, tx))
+	if err != nil {
+		log.Errorf("Error resolving tenant. Error: %s", err)
+		return nil, err
+	}
+	res, err = handlerMap.Handle(scoped, function, args)
+	if err != nil {
+		log.Errorf("Error when calling handler. Error: %s", err)
+		return nil, err
+	}
+	if err := cc.recordCirculation(tx); err != nil {
+		log.Errorf("Error updating circulation. Error: %s", err)
+		return nil, err
+	}
+	if err := cc.recordAudit(tx, function); err != nil {
+		log.Errorf("Error recording audit entry. Error: %s", err)
+		return nil, err
+	}
+	// only a successful handler's writes and events reach the ledger, all together
+	if err := tx.publishEvents(function); err != nil {
+		log.Errorf("Error publishing events. Error: %s", err)
+		return nil, err
+	}
+	if err := tx.flush(); err != nil {
+		log.Errorf("Error writing state. Error: %s", err)
+		return nil, err
+	}
+	return withTraceparent(res, traceparent), nil
 }
 
 //------------------
@@ -74,37 +115,54 @@ func (cc *Chaincode) handleInvocation(stub shim.ChaincodeStubInterface, function
 
 // GetAccountList query blockchain accounts by customer ID
 func (cc *Chaincode) GetAccountList(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering GetAccountList with args %v", args)
-
-	if len(args) == 0 {
+	if len(args) == 0 || len(args) > 3 {
 		return nil, errors.New("Missing required customer ID")
 	}
 	customerID := args[0]
-	// Query state using partial keys
-	keysIter, err := cc.partialCompositeKeyQuery(stub, model.AccountObjectType, []string{customerID})
+	pageSize, err := listPageSizeArg(args, 1)
 	if err != nil {
-		logger.Errorf("Failed to get account list. Error: %s", err)
 		return nil, err
 	}
-	accountList := model.AccountList{}
-	for keysIter.HasNext() {
-		_, accountBytes, _ := keysIter.Next()
-		acc := new(model.Account)
-		if err := json.Unmarshal(accountBytes, acc); err != nil {
-			logger.Errorf("Failed to get account details. Error: %s", err)
-			continue
+	// Query state using partial keys, streaming the records into the response
+	list := newListWriter("accounts")
+	nextBookmark, err := cc.scanCompositeKeyPage(stub, model.AccountObjectType, []string{customerID}, optionalArg(args, 2), pageSize, func(_ string, accountBytes []byte) error {
+		element, err := listedAccount(accountBytes)
+		if err != nil {
+			loggerFor(stub).Errorf("Failed to get account details. Error: %s", err)
+			return nil
 		}
-		accountList.Accounts = append(accountList.Accounts, acc)
+		return list.AddRaw(element)
+	})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get account list. Error: %s", err)
+		return nil, err
 	}
-	jsonList, _ := json.Marshal(accountList)
-	logger.Debugf("Returning account list: %s", jsonList)
-	return jsonList, nil
+	loggerFor(stub).Debugf("Returning %d accounts", list.Count())
+	return list.Bytes(nextBookmark), nil
+}
+
+// listedAccount returns an account record as account lists return it: as
+// stored, unless it was written before account statuses and is decoded to
+// derive its status
+func listedAccount(accountBytes []byte) ([]byte, error) {
+	header := struct {
+		Status model.AccountStatus `json:"status"`
+	}{}
+	if err := json.Unmarshal(accountBytes, &header); err != nil {
+		return nil, err
+	}
+	if header.Status != "" {
+		return accountBytes, nil
+	}
+	acc := new(model.Account)
+	if err := json.Unmarshal(accountBytes, acc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(acc)
 }
 
 // GetAccount query blockchain account by account ID
 func (cc *Chaincode) GetAccount(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering GetAccount with args %v", args)
-
 	if len(args) != 2 {
 		return nil, errors.New("Missing required customer ID and / or account ID")
 	}
@@ -112,40 +170,170 @@ func (cc *Chaincode) GetAccount(stub shim.ChaincodeStubInterface, args []string)
 	customerID := args[0]
 	accountID := args[1]
 
-	key, _ := cc.createCompositeKey(model.AccountObjectType, []string{customerID, accountID})
+	key, _ := cc.createCompositeKey(stub, model.AccountObjectType, []string{customerID, accountID})
 	accountBytes, err := stub.GetState(key)
 	if err != nil {
-		logger.Errorf("Failed to get account details. Error: %s", err)
+		loggerFor(stub).Errorf("Failed to get account details. Error: %s", err)
 		return nil, err
 	}
 	return accountBytes, nil
 }
 
+// GetBalance query the balance and available balance of an account, without
+// the rest of the account record
+func (cc *Chaincode) GetBalance(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(model.NewBalance(account))
+}
+
+// GetAccountHistory query the committed changes of an account record from the
+// ledger history, including the ID and timestamp of each writing transaction
+func (cc *Chaincode) GetAccountHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+
+	key, _ := cc.createCompositeKey(stub, model.AccountObjectType, args)
+	historyIter, err := stub.GetHistoryForKey(key)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get account history. Error: %s", err)
+		return nil, err
+	}
+	defer historyIter.Close()
+	history := model.AccountHistory{CustomerID: args[0], AccountID: args[1]}
+	for historyIter.HasNext() {
+		modification, err := historyIter.Next()
+		if err != nil {
+			return nil, err
+		}
+		entry := &model.AccountHistoryEntry{
+			TxID:      modification.TxId,
+			Timestamp: modification.Timestamp.GetSeconds(),
+			IsDelete:  modification.IsDelete,
+		}
+		if !modification.IsDelete {
+			entry.Account = new(model.Account)
+			if err := bytesToStruct(modification.Value, entry.Account); err != nil {
+				return nil, err
+			}
+		}
+		history.Entries = append(history.Entries, entry)
+	}
+	return json.Marshal(history)
+}
+
 // OpenAccount opens an account, store into chaincode state as a JSON record
 func (cc *Chaincode) OpenAccount(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering OpenAccount with args %v", args)
-
 	if len(args) == 0 {
 		return nil, errors.New("Missing required account data JSON")
 	}
 
-	account, err := model.CreateAccount([]byte(args[0]))
+	account, err := model.CreateAccount([]byte(args[0]), txContext(stub))
 	if err != nil {
-		logger.Errorf("Error when creating new account. Error: %s", err)
+		loggerFor(stub).Errorf("Error when creating new account. Error: %s", err)
 		return nil, fmt.Errorf("Error creating new account. Error: %s", err)
 	}
-	key, _ := cc.createCompositeKey(account.GetObjectType(), []string{account.CustomerID, account.ID})
-	accountData, _ := json.Marshal(account)
-	stub.PutState(key, accountData)
+	if err := cc.authorize(stub, auth.OpenAccount, account); err != nil {
+		return nil, err
+	}
+	if err := cc.requireCustomer(stub, account.CustomerID); err != nil {
+		return nil, err
+	}
+	if err := cc.admitToProduct(stub, account); err != nil {
+		return nil, err
+	}
+	if err := cc.requireKYC(stub, account.CustomerID); err != nil {
+		return nil, err
+	}
+	if err := cc.requireBankMSP(stub, account); err != nil {
+		return nil, err
+	}
+	accountData, err := cc.putAccount(stub, account)
+	if err != nil {
+		return nil, err
+	}
+	if err := emitAccountEvent(stub, model.EventAccountOpened, account); err != nil {
+		return nil, err
+	}
 
 	return accountData, nil
 }
 
-// TopupAccount update account balance
-func (cc *Chaincode) TopupAccount(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering TopupAccount with args %v", args)
+// FreezeAccount stops an active account from sending money while still letting
+// it receive. Restricted to compliance officers.
+func (cc *Chaincode) FreezeAccount(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, account ID and / or reason")
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if account.Status != model.AccountActive {
+		return nil, fmt.Errorf("Cannot freeze %s account %s", account.Status, account.ID)
+	}
+	account.Status = model.AccountFrozen
+	account.StatusReason = args[2]
+	if err := emitAccountEvent(stub, model.EventAccountFrozen, account); err != nil {
+		return nil, err
+	}
+	return cc.putAccount(stub, account)
+}
 
+// UnfreezeAccount returns a frozen or dormant account to active. Restricted to
+// compliance officers.
+func (cc *Chaincode) UnfreezeAccount(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Missing required customer ID and / or account ID")
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if account.Status != model.AccountFrozen && account.Status != model.AccountDormant {
+		return nil, fmt.Errorf("Cannot unfreeze %s account %s", account.Status, account.ID)
+	}
+	account.Status = model.AccountActive
+	account.StatusReason = ""
+	if err := emitAccountEvent(stub, model.EventAccountUnfrozen, account); err != nil {
+		return nil, err
+	}
+	return cc.putAccount(stub, account)
+}
+
+// SetOverdraftLimit sets the amount an account balance may go below zero.
+// Restricted to credit officers.
+func (cc *Chaincode) SetOverdraftLimit(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 3 {
+		return nil, errors.New("Missing required customer ID, account ID and / or limit")
+	}
+	limit, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil || limit < 0 {
+		return nil, fmt.Errorf("Error parsing limit value %s", args[2])
+	}
+	account, err := cc.getAccountStruct(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if account.IsClosed() {
+		return nil, fmt.Errorf("Cannot set overdraft limit of closed account %s", account.ID)
+	}
+	account.Overdraft = limit
+	if err := emitAccountEvent(stub, model.EventOverdraftChanged, account); err != nil {
+		return nil, err
+	}
+	return cc.putAccount(stub, account)
+}
+
+// TopupAccount update account balance
+func (cc *Chaincode) TopupAccount(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 && len(args) != 4 {
 		return nil, errors.New("Missing required input arguments")
 	}
 
@@ -158,24 +346,42 @@ func (cc *Chaincode) TopupAccount(stub shim.ChaincodeStubInterface, args []strin
 	}
 	account := new(model.Account)
 	bytesToStruct([]byte(accountData), account)
-	amount, err := strconv.ParseInt(args[2], 10, 64)
+	if err := cc.authorize(stub, auth.Topup, account); err != nil {
+		return nil, err
+	}
+	amount, err := model.ParseAmount(args[2])
+	if err != nil {
+		return nil, err
+	}
+	if !account.CanReceive() {
+		return nil, fmt.Errorf("Cannot top up closed account %s", account.ID)
+	}
+	if currency := optionalArg(args, 3); currency != "" && currency != account.CurrencyCode {
+		return nil, fmt.Errorf("Topup currency %s does not match account currency %s", currency, account.CurrencyCode)
+	}
+	if err := account.Credit(account.Money(amount), txContext(stub)); err != nil {
+		return nil, err
+	}
+	accountData, err = cc.putAccount(stub, account)
 	if err != nil {
-		return nil, fmt.Errorf("Error parsing amount value %s", args[2])
+		return nil, err
+	}
+	event := model.NewAccountEvent(account)
+	event.Amount = amount
+	if err := emitEvent(stub, model.EventAccountToppedUp, event); err != nil {
+		return nil, err
 	}
-	account.Credit(amount)
-	key, _ := cc.createCompositeKey(account.GetObjectType(), []string{account.CustomerID, account.ID})
-	accountData, _ = json.Marshal(account)
-	stub.PutState(key, accountData)
 
 	return accountData, nil
 }
 
-// CloseAccount closes the given account
+// CloseAccount closes the given account. An account with a balance is only
+// closed when the optional sweep customer and account ID name an account to
+// move the balance into. The final statement of the account is generated and
+// stored on the ledger.
 func (cc *Chaincode) CloseAccount(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering CloseAccount with args %v", args)
-
-	if len(args) != 2 {
-		return nil, errors.New("Missing required customer ID and / or account ID")
+	if len(args) != 2 && len(args) != 4 {
+		return nil, errors.New("Missing required customer ID and / or account ID, or sweep customer ID and / or sweep account ID")
 	}
 
 	accountData, err := cc.GetAccount(stub, args[:2])
@@ -188,27 +394,99 @@ func (cc *Chaincode) CloseAccount(stub shim.ChaincodeStubInterface, args []strin
 
 	account := new(model.Account)
 	bytesToStruct(accountData, account)
-	account.Closed = true
-	key, _ := cc.createCompositeKey(account.GetObjectType(), []string{account.CustomerID, account.ID})
-	accountData, _ = json.Marshal(account)
-	stub.PutState(key, accountData)
+	if err := cc.authorize(stub, auth.CloseAccount, account); err != nil {
+		return nil, err
+	}
+	if account.Held > 0 {
+		return nil, fmt.Errorf("Cannot close account %s with active holds", account.ID)
+	}
+	if err := cc.checkNoPendingTransfers(stub, account); err != nil {
+		return nil, err
+	}
+	closedBy, err := callerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	txns, err := cc.accountTransactions(stub, account)
+	if err != nil {
+		return nil, err
+	}
+	final := model.CreateFinalStatement(account, txns, closedBy, txContext(stub))
+	if err := cc.sweepForClosure(stub, account, optionalArg(args, 2), optionalArg(args, 3), final); err != nil {
+		return nil, err
+	}
+	if err := cc.putFinalStatement(stub, final); err != nil {
+		return nil, err
+	}
+	account.Status = model.AccountClosed
+	accountData, err = cc.putAccount(stub, account)
+	if err != nil {
+		return nil, err
+	}
+	if err := emitAccountEvent(stub, model.EventAccountClosed, account); err != nil {
+		return nil, err
+	}
 
 	return accountData, nil
 }
 
 // TransferMoney transfer money
 func (cc *Chaincode) TransferMoney(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering with args %v", args)
-
 	if len(args) == 0 {
 		return nil, errors.New("Missing transfer details JSON")
 	}
-	transferData := args[0]
 	t := new(model.Transfer)
-	bytesToStruct([]byte(transferData), t)
-	if err := t.Validate(); err != nil {
+	if err := model.Unmarshal([]byte(args[0]), t); err != nil {
+		return nil, err
+	}
+	t.Initiated = txContext(stub).Time.Unix()
+	fromAccount, err := cc.getAccountStruct(stub, t.FromCustomerID, t.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.authorize(stub, auth.Transfer, fromAccount); err != nil {
 		return nil, err
 	}
+	if err := fromAccount.CheckVersion(t.PayerVersion); err != nil {
+		return nil, err
+	}
+	if err := cc.requireKYC(stub, fromAccount.CustomerID); err != nil {
+		return nil, err
+	}
+	if queued, err := cc.queueForApproval(stub, t); err != nil || queued != nil {
+		return queued, err
+	}
+	return cc.submitTransfer(stub, fromAccount, t)
+}
+
+// submitTransfer proposes an authorized transfer to the signers of a
+// multi-signature account, or executes it
+func (cc *Chaincode) submitTransfer(stub shim.ChaincodeStubInterface, fromAccount *model.Account, t *model.Transfer) ([]byte, error) {
+	if fromAccount.IsMultiSig() {
+		return cc.proposeOutgoingTransfer(stub, fromAccount, t)
+	}
+	var res []byte
+	err := atomically(stub, func() error {
+		var err error
+		res, err = cc.executeTransfer(stub, t)
+		return err
+	})
+	// a sanctions hit is recorded, so the invocation commits with the transfer failed
+	if failureCode(err) == model.SanctionsHit {
+		loggerFor(stub).Warningf("Transfer from account %s stopped by sanctions screening", t.FromAccountID)
+		debit, recordErr := cc.transferFailed(stub, t, err)
+		if recordErr != nil {
+			return nil, recordErr
+		}
+		failed := model.NewTransferResult(t, debit, nil, txContext(stub))
+		failed.Error, failed.FailureCode = err.Error(), model.SanctionsHit
+		return json.Marshal(failed)
+	}
+	return res, err
+}
+
+// executeTransfer settles a validated transfer between two accounts
+func (cc *Chaincode) executeTransfer(stub shim.ChaincodeStubInterface, t *model.Transfer) ([]byte, error) {
 	accountData, err := cc.GetAccount(stub, []string{t.FromCustomerID, t.FromAccountID})
 	if err != nil {
 		return nil, err
@@ -218,7 +496,16 @@ func (cc *Chaincode) TransferMoney(stub shim.ChaincodeStubInterface, args []stri
 	}
 	fromAccount := new(model.Account)
 	bytesToStruct(accountData, fromAccount)
-	accountData, err = cc.GetAccount(stub, []string{t.ToCustomerID, t.ToAccountID})
+	payeeStub, err := cc.payeeScope(stub, t.ToTenant)
+	if err != nil {
+		return nil, err
+	}
+	// the payee is read before the payer is debited, so crediting the payer
+	// itself would overwrite the debit
+	if payeeStub == stub && t.ToCustomerID == t.FromCustomerID && t.ToAccountID == t.FromAccountID {
+		return nil, fmt.Errorf("Cannot transfer money from account %s to itself", t.FromAccountID)
+	}
+	accountData, err = cc.GetAccount(payeeStub, []string{t.ToCustomerID, t.ToAccountID})
 	if err != nil {
 		return nil, err
 	}
@@ -228,66 +515,232 @@ func (cc *Chaincode) TransferMoney(stub shim.ChaincodeStubInterface, args []stri
 	toAccount := new(model.Account)
 	bytesToStruct(accountData, toAccount)
 
-	if fromAccount.Closed {
-		cc.recordTransaction(stub, fromAccount.CustomerID, fromAccount.ID, t, model.AccountClosed, model.Failed)
-		return nil, fmt.Errorf("Cannot transfer money from closed account %s", t.FromAccountID)
+	if !fromAccount.CanSend() {
+		return nil, fmt.Errorf("Cannot transfer money from %s account %s", fromAccount.Status, t.FromAccountID)
 	}
 
-	if toAccount.Closed {
-		cc.recordTransaction(stub, toAccount.CustomerID, toAccount.ID, t, model.AccountClosed, model.Failed)
+	if !toAccount.CanReceive() {
 		return nil, fmt.Errorf("Cannot transfer money into closed account %s", t.ToAccountID)
 	}
 
-	if fromAccount.Balance-t.Amount < 0 {
-		cc.recordTransaction(stub, fromAccount.CustomerID, fromAccount.ID, t, model.InsufficientFunds, model.Failed)
+	if fromAccount.CurrencyCode != t.CurrencyCode {
+		return nil, fmt.Errorf("Cannot transfer %s from account %s in %s", t.CurrencyCode, t.FromAccountID, fromAccount.CurrencyCode)
+	}
+
+	if err := cc.screenTransfer(stub, fromAccount, toAccount); err != nil {
+		return nil, err
+	}
+
+	// the fee is locked by the quote the transfer references, or else set by
+	// the fee schedule of the payer's product or of the corridor, never by the client
+	t.Fee, t.Quote = 0, nil
+	var schedule *model.FeeSchedule
+	if t.QuoteID != "" {
+		if t.Quote, err = cc.executableQuote(stub, t); err != nil {
+			return nil, err
+		}
+		t.Fee, schedule = t.Quote.Fee, t.Quote.FeeSchedule()
+	} else {
+		if schedule, err = cc.transferFeeSchedule(stub, fromAccount, toAccount, t.CurrencyCode); err != nil {
+			return nil, err
+		}
+		if schedule != nil {
+			t.Fee = schedule.Fee(t.Amount)
+		}
+	}
+
+	if _, err := cc.activeBank(stub, fromAccount); err != nil {
+		return nil, err
+	}
+	if _, err := cc.activeBank(stub, toAccount); err != nil {
+		return nil, err
+	}
+
+	if shortfall := t.Amount + t.Fee - fromAccount.Available(); shortfall > 0 {
+		drawn, err := cc.drawPoolLiquidity(stub, fromAccount, shortfall)
+		if err != nil {
+			return nil, err
+		}
+		if drawn {
+			loggerFor(stub).Infof("Covered shortfall of %d on account %s from liquidity pool", shortfall, fromAccount.ID)
+		}
+	}
+
+	if fromAccount.Available()-t.Amount-t.Fee < 0 {
 		return nil, fmt.Errorf("Insufficient funds available in account %s", t.FromAccountID)
 	}
 
-	cc.debitAccount(stub, fromAccount, t.Amount+t.Fee)
-	cc.recordTransaction(stub, fromAccount.CustomerID, fromAccount.ID, t, "", model.Debited)
-	cc.creditAccount(stub, toAccount, t.Amount)
-	cc.recordTransaction(stub, toAccount.CustomerID, toAccount.ID, t, "", model.Credited)
+	if err := cc.checkBeneficiary(stub, fromAccount, t); err != nil {
+		return nil, err
+	}
+	if err := cc.checkLimits(stub, fromAccount, toAccount, t); err != nil {
+		return nil, err
+	}
+	if err := cc.trackBudget(stub, fromAccount.CustomerID, t); err != nil {
+		return nil, err
+	}
+
+	if err := cc.increaseExposure(stub, fromAccount.BankName, toAccount.BankName, t.CurrencyCode, t.Amount); err != nil {
+		return nil, err
+	}
+	if err := cc.recordInterbankFlow(stub, fromAccount.BankName, toAccount.BankName, t.CurrencyCode, t.Amount); err != nil {
+		return nil, err
+	}
+
+	taxAuthority, err := cc.applyWithholding(stub, fromAccount, toAccount, t)
+	if err != nil {
+		return nil, err
+	}
+	credit := t.Amount
+	if t.Withholding != nil {
+		credit = t.Withholding.NetAmount
+	}
+	if toAccount.CurrencyCode != t.CurrencyCode {
+		if credit, err = cc.convertTransfer(stub, t, toAccount.CurrencyCode, credit); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.ValueDate, err = cc.valueDate(stub, t.CurrencyCode); err != nil {
+		return nil, err
+	}
 
-	return nil, nil
+	// The debit including the fee, the credits and their transaction records
+	// join the invocation's single write set; any error below discards them all.
+	total, err := t.Total()
+	if err != nil {
+		return nil, err
+	}
+	t.Overdraft = fromAccount.Balance-total.Amount < 0
+	if err := cc.debitAccount(stub, fromAccount, total); err != nil {
+		return nil, err
+	}
+	debit, err := cc.storeTransaction(stub, fromAccount.CustomerID, fromAccount.ID, t, "", model.Debited)
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.creditAccount(payeeStub, toAccount, toAccount.Money(credit)); err != nil {
+		return nil, err
+	}
+	creditTxn, err := cc.storeTransaction(payeeStub, toAccount.CustomerID, toAccount.ID, t, "", model.Credited)
+	if err != nil {
+		return nil, err
+	}
+	if t.Withholding != nil {
+		if err := cc.creditAccount(stub, taxAuthority, taxAuthority.Money(t.Withholding.TaxAmount)); err != nil {
+			return nil, err
+		}
+		if err := cc.recordTransaction(stub, taxAuthority.CustomerID, taxAuthority.ID, t, "", model.Credited); err != nil {
+			return nil, err
+		}
+	}
+	if t.Fee > 0 {
+		if err := cc.collectFee(stub, schedule, fromAccount, t); err != nil {
+			return nil, err
+		}
+	}
+	if err := cc.settleInterbank(stub, fromAccount.BankName, toAccount.BankName, t); err != nil {
+		return nil, err
+	}
+	if err := cc.recordCorridorFlow(stub, fromAccount, toAccount, t, model.Debited); err != nil {
+		return nil, err
+	}
+	if err := cc.reportLargeTransfer(stub, fromAccount, toAccount, t); err != nil {
+		return nil, err
+	}
+	if err := cc.earnPoints(stub, fromAccount.CustomerID, t); err != nil {
+		return nil, err
+	}
+	if err := cc.applyRoundUp(stub, fromAccount, t); err != nil {
+		return nil, err
+	}
+	if t.Quote != nil {
+		if err := cc.useQuote(stub, t.Quote); err != nil {
+			return nil, err
+		}
+	}
+	if err := emitTransferEvent(stub, t, nil); err != nil {
+		return nil, err
+	}
+
+	result := model.NewTransferResult(t, debit, creditTxn, txContext(stub))
+	result.SetPayerBalance(fromAccount)
+	return json.Marshal(result)
 }
 
-// GetTransactionList query blockchain accounts by account ID
+// GetTransactionList query blockchain accounts by account ID. Takes an
+// optional page size, bookmark and first and last creation day (YYYY-MM-DD,
+// inclusive). A day range limits the transactions returned, but the peer still
+// reads the account's keys up to the last day, see compositeKeyRange.
 func (cc *Chaincode) GetTransactionList(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering with args %v", args)
-
-	if len(args) != 2 {
+	if len(args) < 2 || len(args) > 6 {
 		return nil, errors.New("Missing required customer ID and / or account ID")
 	}
 
 	customerID := args[0]
 	accountID := args[1]
-
-	// Query state using partial keys
-	keysIter, err := cc.partialCompositeKeyQuery(stub, model.TransactionObjectType, []string{customerID, accountID})
+	pageSize, err := listPageSizeArg(args, 2)
 	if err != nil {
-		logger.Errorf("Failed to get transaction list. Error: %s", err)
 		return nil, err
 	}
-	tranList := model.TransactionList{}
-	for keysIter.HasNext() {
-		_, txnBytes, _ := keysIter.Next()
-		txn := new(model.Transaction)
-		if err := json.Unmarshal(txnBytes, txn); err != nil {
-			logger.Errorf("Failed to get transaction details. Error: %s", err)
-			continue
+	prefix, start, end, err := cc.dayRange(stub, model.TransactionObjectType, []string{customerID, accountID}, optionalArg(args, 4), optionalArg(args, 5))
+	if err != nil {
+		return nil, err
+	}
+	// the days were validated by dayRange
+	from, _ := model.DayBucketOf(optionalArg(args, 4))
+	to, _ := model.DayBucketOf(optionalArg(args, 5))
+
+	// Query state using partial keys. Only the creation time of each record is
+	// decoded, to sort the page newest first; the records are returned as stored.
+	// The page is cut off in key order, before sorting, once the records reach
+	// the response size limit, so that the next page continues after them.
+	type listedTransaction struct {
+		created int64
+		record  []byte
+	}
+	list := newListWriter("transactions")
+	txns := make([]listedTransaction, 0, pageSize)
+	size := 0
+	nextBookmark, err := scanKeyRangePage(stub, prefix, start, end, optionalArg(args, 3), pageSize, func(_ string, txnBytes []byte) error {
+		if len(txns) > 0 && size+1+len(txnBytes) > model.MaxListResponseSize {
+			list.Truncate()
+			return errListFull
+		}
+		header := struct {
+			Created string `json:"created"`
+		}{}
+		if err := json.Unmarshal(txnBytes, &header); err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			return nil
+		}
+		created, err := time.Parse(time.RFC3339, header.Created)
+		if err != nil {
+			loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+			return nil
 		}
-		tranList.Transactions = append(tranList.Transactions, txn)
+		// keys not yet migrated to day buckets may sort within the range
+		if day := model.DayBucket(created.Unix()); (from != "" && day < from) || (to != "" && day > to) {
+			return nil
+		}
+		txns = append(txns, listedTransaction{created: created.Unix(), record: txnBytes})
+		size += len(txnBytes) + 1
+		return nil
+	})
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction list. Error: %s", err)
+		return nil, err
+	}
+	sort.SliceStable(txns, func(i, j int) bool { return txns[i].created > txns[j].created })
+	for _, txn := range txns {
+		list.AddRaw(txn.record)
 	}
-	sort.Sort(sort.Reverse(model.ByCreated(tranList.Transactions)))
-	jsonList, _ := json.Marshal(tranList)
-	logger.Debugf("Returning transaction list: %s", jsonList)
-	return jsonList, nil
+	loggerFor(stub).Debugf("Returning %d transactions", list.Count())
+	return list.Bytes(nextBookmark), nil
 }
 
 // GetTransaction query blockchain transaction by transaction ID
 func (cc *Chaincode) GetTransaction(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	logger.Debugf("Entering with args %v", args)
-
 	if len(args) != 3 {
 		return nil, errors.New("Missing required account ID and / or transaction ID")
 	}
@@ -296,84 +749,585 @@ func (cc *Chaincode) GetTransaction(stub shim.ChaincodeStubInterface, args []str
 	accountID := args[1]
 	tranID := args[2]
 
-	key, _ := cc.createCompositeKey(model.TransactionObjectType, []string{customerID, accountID, tranID})
+	txnKey, err := stub.GetState(cc.transactionIDKey(stub, customerID, accountID, tranID))
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
+		return nil, err
+	}
+	key := string(txnKey)
+	if txnKey == nil {
+		// transactions stored before keys were bucketed by day are keyed by ID
+		// until MigrateTransactionKeys moves them
+		key, _ = cc.createCompositeKey(stub, model.TransactionObjectType, []string{customerID, accountID, tranID})
+	}
 	txnBytes, err := stub.GetState(key)
 	if err != nil {
-		logger.Errorf("Failed to get transaction details. Error: %s", err)
+		loggerFor(stub).Errorf("Failed to get transaction details. Error: %s", err)
 		return nil, err
 	}
 	return txnBytes, nil
 }
 
+// getAccountStruct loads an account by customer and account ID, failing if it does not exist
+func (cc *Chaincode) getAccountStruct(stub shim.ChaincodeStubInterface, customerID string, accountID string) (*model.Account, error) {
+	accountData, err := cc.GetAccount(stub, []string{customerID, accountID})
+	if err != nil {
+		return nil, err
+	}
+	if accountData == nil {
+		return nil, fmt.Errorf("Account with number %s not found.", accountID)
+	}
+	account := new(model.Account)
+	if err := bytesToStruct(accountData, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// putAccount stores the account state, stamped with the transaction writing it
+func (cc *Chaincode) putAccount(stub shim.ChaincodeStubInterface, a *model.Account) ([]byte, error) {
+	a.Touch(txContext(stub))
+	accountData, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling account data. Error: %s", err)
+	}
+	key, _ := cc.createCompositeKey(stub, a.GetObjectType(), []string{a.CustomerID, a.ID})
+	if err := stub.PutState(key, accountData); err != nil {
+		return nil, err
+	}
+	return accountData, nil
+}
+
 func (cc *Chaincode) recordTransaction(stub shim.ChaincodeStubInterface, customerID string, accountID string, t *model.Transfer, code model.TxFailureCode, status model.TxStatus) error {
-	txn, _ := model.CreateTransaction(customerID, accountID, t, code, status)
+	_, err := cc.storeTransaction(stub, customerID, accountID, t, code, status)
+	return err
+}
+
+// storeTransaction records a transaction of the transfer on the account and
+// returns the record
+func (cc *Chaincode) storeTransaction(stub shim.ChaincodeStubInterface, customerID string, accountID string, t *model.Transfer, code model.TxFailureCode, status model.TxStatus) (*model.Transaction, error) {
+	txn, _ := model.CreateTransaction(customerID, accountID, t, code, status, txContext(stub))
 	txnData, err := json.Marshal(txn)
 	if err != nil {
-		return fmt.Errorf("Error marshalling transaction data. Error: %s", err)
+		return nil, fmt.Errorf("Error marshalling transaction data. Error: %s", err)
 	}
-	key, _ := cc.createCompositeKey(txn.GetObjectType(), []string{txn.CustomerID, txn.AccountID, txn.ID})
-	stub.PutState(key, txnData)
-	return nil
+	key := cc.transactionKey(stub, txn)
+	if err := stub.PutState(key, txnData); err != nil {
+		return nil, err
+	}
+	if err := cc.linkTransaction(stub, txn, key); err != nil {
+		return nil, err
+	}
+	return txn, nil
 }
 
-func (cc *Chaincode) debitAccount(stub shim.ChaincodeStubInterface, a *model.Account, amount int64) error {
-	a.Debit(amount)
-	accountData, _ := json.Marshal(a)
-	key, _ := cc.createCompositeKey(a.GetObjectType(), []string{a.CustomerID, a.ID})
-	stub.PutState(key, accountData)
-	return nil
+// transactionKey returns the key of a transaction, bucketed by its creation day
+func (cc *Chaincode) transactionKey(stub shim.ChaincodeStubInterface, txn *model.Transaction) string {
+	key, _ := cc.createCompositeKey(stub, model.TransactionObjectType, []string{txn.CustomerID, txn.AccountID, model.DayBucket(txn.Created), txn.ID})
+	return key
 }
 
-func (cc *Chaincode) creditAccount(stub shim.ChaincodeStubInterface, a *model.Account, amount int64) error {
-	a.Credit(amount)
-	accountData, _ := json.Marshal(a)
-	key, _ := cc.createCompositeKey(a.GetObjectType(), []string{a.CustomerID, a.ID})
-	stub.PutState(key, accountData)
-	return nil
+// transactionIDKey returns the key of the entry locating a transaction by its
+// customer, account and transaction ID, whose value is the transaction key
+func (cc *Chaincode) transactionIDKey(stub shim.ChaincodeStubInterface, customerID string, accountID string, txnID string) string {
+	key, _ := cc.createCompositeKey(stub, model.TransactionIDObjectType, []string{customerID, accountID, txnID})
+	return key
+}
+
+// linkTransaction writes the entries pointing to a transaction stored under
+// the key: by ID, by end-to-end reference and in the secondary indexes
+func (cc *Chaincode) linkTransaction(stub shim.ChaincodeStubInterface, txn *model.Transaction, key string) error {
+	if err := stub.PutState(cc.transactionIDKey(stub, txn.CustomerID, txn.AccountID, txn.ID), []byte(key)); err != nil {
+		return err
+	}
+	if txn.EndToEndID != "" {
+		if err := stub.PutState(cc.transactionReferenceKey(stub, txn), []byte(key)); err != nil {
+			return err
+		}
+	}
+	return cc.indexTransaction(stub, txn, key)
+}
+
+// unlinkTransaction deletes the entries pointing to a transaction
+func (cc *Chaincode) unlinkTransaction(stub shim.ChaincodeStubInterface, txn *model.Transaction) error {
+	if err := stub.DelState(cc.transactionIDKey(stub, txn.CustomerID, txn.AccountID, txn.ID)); err != nil {
+		return err
+	}
+	if txn.EndToEndID != "" {
+		if err := stub.DelState(cc.transactionReferenceKey(stub, txn)); err != nil {
+			return err
+		}
+	}
+	return cc.unindexTransaction(stub, txn)
+}
+
+// transactionReferenceKey returns the key of the index entry of a transaction
+// under its end-to-end reference, whose value is the transaction key
+func (cc *Chaincode) transactionReferenceKey(stub shim.ChaincodeStubInterface, txn *model.Transaction) string {
+	key, _ := cc.createCompositeKey(stub, model.TransactionReferenceObjectType, []string{txn.EndToEndID, txn.CustomerID, txn.AccountID, txn.ID})
+	return key
+}
+
+func (cc *Chaincode) debitAccount(stub shim.ChaincodeStubInterface, a *model.Account, amount model.Money) error {
+	if err := a.Debit(amount, txContext(stub)); err != nil {
+		return err
+	}
+	_, err := cc.putAccount(stub, a)
+	return err
+}
+
+func (cc *Chaincode) creditAccount(stub shim.ChaincodeStubInterface, a *model.Account, amount model.Money) error {
+	if err := a.Credit(amount, txContext(stub)); err != nil {
+		return err
+	}
+	_, err := cc.putAccount(stub, a)
+	return err
 }
 
 //-------------------------------------------------
 // Helpers
 //-------------------------------------------------
 func initLogging() {
-	logger.SetLevel(shim.LogInfo)
-	logLevel, _ := shim.LogLevel(os.Getenv("SHIM_LOGGING_LEVEL"))
-	shim.SetLoggingLevel(logLevel)
+	if level, err := ParseLogLevel(os.Getenv("SHIM_LOGGING_LEVEL")); err == nil {
+		logger.SetLevel(level)
+	}
+	if format := os.Getenv("FINNET_LOG_FORMAT"); format != "" {
+		if err := logger.SetFormat(format); err != nil {
+			logger.Warningf("Ignoring FINNET_LOG_FORMAT. Error: %s", err)
+		}
+	}
+	levels, err := ParseHandlerLevels(os.Getenv("FINNET_LOG_LEVELS"))
+	if err != nil {
+		logger.Warningf("Ignoring FINNET_LOG_LEVELS. Error: %s", err)
+	}
+	for function, level := range levels {
+		logger.SetHandlerLevel(function, level)
+	}
 }
 
 // Registers handler function mappings
 func (cc *Chaincode) registerHandlers() {
-	handlerMap.Add("OpenAccount", cc.OpenAccount)
-	handlerMap.Add("CloseAccount", cc.CloseAccount)
+	handlerMap.SetRoleCheck(cc.requireAnyRole)
+	handlerMap.SetFeatureCheck(cc.requireFeature)
+	handlerMap.Add("OpenAccount", cc.idempotent("OpenAccount", 1, cc.OpenAccount), RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("CloseAccount", cc.CloseAccount, RoleCustomer, RoleTeller, RoleAccountOperator)
 	handlerMap.Add("GetAccount", cc.GetAccount)
+	handlerMap.Add("GetBalance", cc.GetBalance)
 	handlerMap.Add("GetAccountList", cc.GetAccountList)
-	handlerMap.Add("TransferMoney", cc.TransferMoney)
-	handlerMap.Add("TopupAccount", cc.TopupAccount)
+	handlerMap.Add("TransferMoney", cc.idempotent("TransferMoney", 1, cc.TransferMoney), RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("SubmitMT103", cc.idempotent("SubmitMT103", 1, cc.SubmitMT103), RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("TopupAccount", cc.idempotent("TopupAccount", 4, cc.TopupAccount), RoleCustomer, RoleTeller, RoleAccountOperator)
 	handlerMap.Add("GetTransaction", cc.GetTransaction)
+	handlerMap.Add("QueryTransactions", cc.QueryTransactions)
+	handlerMap.Add("GetTransactionByReference", cc.GetTransactionByReference, RoleTeller, RoleAccountOperator, RoleComplianceOfficer, RoleAuditor)
+	handlerMap.Add("QueryTransactionIndex", cc.QueryTransactionIndex, RoleTeller, RoleAccountOperator, RoleComplianceOfficer, RoleAuditor)
+	handlerMap.Add("InitiateTransfer", cc.idempotent("InitiateTransfer", 1, cc.InitiateTransfer), RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("SettleTransfer", cc.SettleTransfer, RoleSettlementAgent)
+	handlerMap.Add("RejectTransfer", cc.RejectTransfer, RoleSettlementAgent, RoleTransferApprover)
+	handlerMap.Add("SetApprovalPolicy", cc.SetApprovalPolicy, RoleComplianceOfficer)
+	handlerMap.Add("GetApprovalPolicy", cc.GetApprovalPolicy)
+	handlerMap.Add("ApproveTransfer", cc.ApproveTransfer, RoleTransferApprover)
+	handlerMap.Add("ListPendingApprovals", cc.ListPendingApprovals, RoleTransferApprover, RoleComplianceOfficer, RoleAuditor)
+	handlerMap.Add("GetPendingTransfers", cc.GetPendingTransfers)
+	handlerMap.Add("PlaceHold", cc.PlaceHold, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("ReleaseHold", cc.ReleaseHold, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetHolds", cc.GetHolds)
+	handlerMap.Add("GetAvailableBalance", cc.GetAvailableBalance)
+	handlerMap.Add("SetLimits", cc.SetLimits, RoleComplianceOfficer)
+	handlerMap.Add("RemoveLimits", cc.RemoveLimits, RoleComplianceOfficer)
+	handlerMap.Add("GetLimits", cc.GetLimits, RoleComplianceOfficer, RoleAuditor, RoleRegulator)
+	handlerMap.Add("AddBlockedParty", cc.AddBlockedParty, RoleComplianceOfficer)
+	handlerMap.Add("RemoveBlockedParty", cc.RemoveBlockedParty, RoleComplianceOfficer)
+	handlerMap.Add("IsBlocked", cc.IsBlocked, RoleComplianceOfficer, RoleTeller, RoleRegulator)
+	handlerMap.Add("GetComplianceAlerts", cc.GetComplianceAlerts, RoleComplianceOfficer, RoleRegulator, RoleAuditor)
+	handlerMap.Add("RegisterCustomer", cc.RegisterCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("UpdateCustomer", cc.UpdateCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetCustomer", cc.GetCustomer)
+	handlerMap.Add("GetCustomerSummary", cc.GetCustomerSummary)
+	handlerMap.Add("AddBeneficiary", cc.AddBeneficiary, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("ListBeneficiaries", cc.ListBeneficiaries, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("RemoveBeneficiary", cc.RemoveBeneficiary, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("SetBeneficiaryPolicy", cc.SetBeneficiaryPolicy, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("RemoveBeneficiaryPolicy", cc.RemoveBeneficiaryPolicy, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("CreatePaymentRequest", cc.CreatePaymentRequest, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetPaymentRequests", cc.GetPaymentRequests, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("PayRequest", cc.PayRequest, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("CancelPaymentRequest", cc.CancelPaymentRequest, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("OpenDispute", cc.OpenDispute, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("ResolveDispute", cc.ResolveDispute, RoleDisputeOfficer)
+	handlerMap.Add("GetDisputes", cc.GetDisputes, RoleCustomer, RoleTeller, RoleAccountOperator, RoleDisputeOfficer)
+	handlerMap.Add("SubmitKYC", cc.SubmitKYC, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("ApproveKYC", cc.ApproveKYC, RoleComplianceOfficer)
+	handlerMap.Add("RejectKYC", cc.RejectKYC, RoleComplianceOfficer)
+	handlerMap.Add("GetKYCStatus", cc.GetKYCStatus)
+	handlerMap.Add("TransferBatch", cc.idempotent("TransferBatch", 1, cc.TransferBatch), RoleCustomer, RoleTeller, RoleAccountOperator)
 	handlerMap.Add("GetTransactionList", cc.GetTransactionList)
+	handlerMap.Add("CreateLiquidityPool", cc.CreateLiquidityPool, RoleNetworkOperator)
+	handlerMap.Add("JoinLiquidityPool", cc.JoinLiquidityPool, RoleAccountOperator)
+	handlerMap.Add("ContributeToPool", cc.ContributeToPool)
+	handlerMap.Add("WithdrawFromPool", cc.WithdrawFromPool)
+	handlerMap.Add("DrawFromPool", cc.DrawFromPool)
+	handlerMap.Add("RepayPool", cc.RepayPool)
+	handlerMap.Add("GetPoolPosition", cc.GetPoolPosition)
+	handlerMap.Add("SetExposureLimit", cc.SetExposureLimit, RoleRiskOfficer)
+	handlerMap.Add("GetBilateralExposure", cc.GetBilateralExposure)
+	handlerMap.Add("SettleBilateralExposure", cc.SettleBilateralExposure, RoleRiskOfficer, RoleSettlementAgent)
+	handlerMap.Add("PledgeCollateral", cc.PledgeCollateral, RoleRiskOfficer)
+	handlerMap.Add("ReleaseCollateral", cc.ReleaseCollateral, RoleRiskOfficer)
+	handlerMap.Add("MarkCollateralToMarket", cc.MarkCollateralToMarket, RoleRiskOfficer)
+	handlerMap.Add("GetCollateral", cc.GetCollateral)
+	handlerMap.Add("PublishBenchmarkRate", cc.PublishBenchmarkRate, RoleRateAdmin)
+	handlerMap.Add("GetBenchmarkRate", cc.GetBenchmarkRate)
+	handlerMap.Add("GetBenchmarkRateHistory", cc.GetBenchmarkRateHistory)
+	handlerMap.Add("SetAccountSigners", cc.SetAccountSigners)
+	handlerMap.Add("ApproveOutgoingTransfer", cc.ApproveOutgoingTransfer)
+	handlerMap.Add("GetOutgoingTransfer", cc.GetOutgoingTransfer)
+	handlerMap.Add("CreatePayrollRun", cc.CreatePayrollRun, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("ExecutePayrollRun", cc.ExecutePayrollRun, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("CancelPayrollRun", cc.CancelPayrollRun, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetPayrollReport", cc.GetPayrollReport)
+	handlerMap.Add("GetPayrollHistory", cc.GetPayrollHistory)
+	handlerMap.Add("SetWithholdingRule", cc.SetWithholdingRule, RoleComplianceOfficer)
+	handlerMap.Add("GetWithholdingRule", cc.GetWithholdingRule)
+	handlerMap.Add("RemoveWithholdingRule", cc.RemoveWithholdingRule, RoleComplianceOfficer)
+	handlerMap.Add("SetPointsProgram", cc.SetPointsProgram, RoleProductAdmin)
+	handlerMap.Add("GetPointsProgram", cc.GetPointsProgram)
+	handlerMap.Add("GetPointsBalance", cc.GetPointsBalance)
+	handlerMap.Add("RedeemPoints", cc.RedeemPoints, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("PublishReserveAttestation", cc.PublishReserveAttestation, RoleAuditor)
+	handlerMap.Add("GetReserveStatus", cc.GetReserveStatus)
+	handlerMap.Add("UpdateReserve", cc.UpdateReserve, RoleEmissionAuthority, RoleIssuer)
+	handlerMap.Add("GetReserveRatio", cc.GetReserveRatio, RoleRegulator, RoleAuditor)
+	handlerMap.Add("SetNostroAccount", cc.SetNostroAccount, RoleSettlementAgent)
+	handlerMap.Add("GetTreasuryPosition", cc.GetTreasuryPosition)
+	handlerMap.Add("GetInterbankPosition", cc.GetInterbankPosition)
+	handlerMap.Add("RunNetting", cc.RunNetting, RoleSettlementAgent)
+	handlerMap.Add("GetSettlementBatches", cc.GetSettlementBatches)
+	handlerMap.Add("IssueGuarantee", cc.IssueGuarantee, RoleAccountOperator)
+	handlerMap.Add("ClaimGuarantee", cc.ClaimGuarantee, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("ExpireGuarantee", cc.ExpireGuarantee)
+	handlerMap.Add("GetGuarantee", cc.GetGuarantee)
+	handlerMap.Add("CreateAssetToken", cc.CreateAssetToken, RoleIssuer)
+	handlerMap.Add("GetAssetToken", cc.GetAssetToken)
+	handlerMap.Add("GetAssetHolding", cc.GetAssetHolding)
+	handlerMap.Add("TransferAsset", cc.TransferAsset, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("AtomicDvP", cc.AtomicDvP, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("OpenRepo", cc.OpenRepo, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("CloseRepo", cc.CloseRepo, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetRepo", cc.GetRepo)
+	handlerMap.Add("SetSweepRule", cc.SetSweepRule, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetSweepRule", cc.GetSweepRule)
+	handlerMap.Add("RemoveSweepRule", cc.RemoveSweepRule, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("RunSweeps", cc.RunSweeps, RoleAccountOperator)
+	handlerMap.Add("RegisterHandle", cc.RegisterHandle, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("ResolveHandle", cc.ResolveHandle)
+	handlerMap.Add("P2PSend", cc.P2PSend, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("RequestP2PPayment", cc.RequestP2PPayment, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("PayP2PRequest", cc.PayP2PRequest, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("DeclineP2PRequest", cc.DeclineP2PRequest, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetP2PRequests", cc.GetP2PRequests)
+	handlerMap.Add("SetRoundUpRule", cc.SetRoundUpRule, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetRoundUpRule", cc.GetRoundUpRule)
+	handlerMap.Add("RemoveRoundUpRule", cc.RemoveRoundUpRule, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetRoundUpTotals", cc.GetRoundUpTotals)
+	handlerMap.Add("SetBudget", cc.SetBudget, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("RemoveBudget", cc.RemoveBudget, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetBudgets", cc.GetBudgets)
+	handlerMap.Add("SetEscheatmentPolicy", cc.SetEscheatmentPolicy, RoleEscheatmentOfficer)
+	handlerMap.Add("Escheat", cc.Escheat, RoleEscheatmentOfficer)
+	handlerMap.Add("ReclaimEscheated", cc.ReclaimEscheated, RoleEscheatmentOfficer)
+	handlerMap.Add("GetEscheatments", cc.GetEscheatments)
+	handlerMap.Add("RegisterBank", cc.RegisterBank, RoleNetworkOperator)
+	handlerMap.Add("SuspendBank", cc.SuspendBank, RoleNetworkOperator)
+	handlerMap.Add("ReinstateBank", cc.ReinstateBank, RoleNetworkOperator)
+	handlerMap.Add("GetBank", cc.GetBank)
+	handlerMap.Add("GetBankList", cc.GetBankList)
+	handlerMap.Add("RegisterParticipant", cc.RegisterParticipant, RoleNetworkOperator)
+	handlerMap.Add("ListParticipants", cc.ListParticipants)
+	handlerMap.Add("GetCorridorStats", cc.GetCorridorStats)
+	handlerMap.Add("SetReportingThreshold", cc.SetReportingThreshold, RoleRegulator)
+	handlerMap.Add("GetReportingThreshold", cc.GetReportingThreshold, RoleRegulator, RoleComplianceOfficer)
+	handlerMap.Add("GetLargeTransactions", cc.GetLargeTransactions, RoleRegulator)
+	handlerMap.Add("GetFlaggedTransactions", cc.GetFlaggedTransactions, RoleRegulator)
+	handlerMap.Add("GetAggregateFlows", cc.GetAggregateFlows, RoleRegulator)
+	handlerMap.Add("GetRegulatoryReports", cc.GetRegulatoryReports, RoleRegulator)
+	handlerMap.Add("QueryAuditLog", cc.QueryAuditLog, RoleAuditor, RoleRegulator)
+	handlerMap.Add("GenerateLoad", cc.GenerateLoad, RoleNetworkOperator)
+	handlerMap.Add("ExportState", cc.ExportState, RoleNetworkOperator)
+	handlerMap.Add("ImportState", cc.ImportState, RoleNetworkOperator)
+	handlerMap.Add("LoadAccounts", cc.idempotent("LoadAccounts", 1, cc.LoadAccounts), RoleAccountOperator)
+	handlerMap.Add("PreviewArchive", cc.PreviewArchive, RoleRecordsAdmin)
+	handlerMap.Add("ArchiveTransactions", cc.ArchiveTransactions, RoleRecordsAdmin)
+	handlerMap.Add("ArchiveClosedAccounts", cc.ArchiveClosedAccounts, RoleRecordsAdmin)
+	handlerMap.Add("GetAccountTombstone", cc.GetAccountTombstone)
+	handlerMap.Add("GetTransactionArchives", cc.GetTransactionArchives)
+	handlerMap.Add("SetTenancyMode", cc.SetTenancyMode, RoleNetworkOperator)
+	handlerMap.Add("Init", cc.Init, RoleNetworkOperator)
+	handlerMap.Add("GetConfig", cc.GetConfig, RoleNetworkOperator)
+	handlerMap.Add("UpdateConfig", cc.UpdateConfig, RoleNetworkOperator)
+	handlerMap.Add("EnableFeature", cc.EnableFeature, RoleNetworkOperator)
+	handlerMap.Add("DisableFeature", cc.DisableFeature, RoleNetworkOperator)
+	handlerMap.Add("ListFeatures", cc.ListFeatures)
+	handlerMap.Add("AssignTenant", cc.AssignTenant, RoleNetworkOperator)
+	handlerMap.Add("GetTenant", cc.GetTenant)
+	handlerMap.Add("GetTenantAccounts", cc.GetTenantAccounts)
+	handlerMap.Add("GetTenantTransactions", cc.GetTenantTransactions)
+	handlerMap.Add("CreateCheckpoint", cc.CreateCheckpoint, RoleNetworkOperator)
+	handlerMap.Add("VerifyAgainstCheckpoint", cc.VerifyAgainstCheckpoint)
+	handlerMap.Add("GetCheckpoints", cc.GetCheckpoints)
+	handlerMap.Add("SetExchangeRate", cc.SetExchangeRate, RoleRateAdmin)
+	handlerMap.Add("GetExchangeRate", cc.GetExchangeRate)
+	handlerMap.Add("AddHoliday", cc.AddHoliday, RoleNetworkOperator)
+	handlerMap.Add("RemoveHoliday", cc.RemoveHoliday, RoleNetworkOperator)
+	handlerMap.Add("ListHolidays", cc.ListHolidays)
+	handlerMap.Add("RequestQuote", cc.RequestQuote, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetQuote", cc.GetQuote, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("PostRate", cc.PostRate, RoleRateOracle)
+	handlerMap.Add("GetRateAttestation", cc.GetRateAttestation)
+	handlerMap.Add("SetRateConfig", cc.SetRateConfig, RoleRateAdmin)
+	handlerMap.Add("SetInterestConfig", cc.SetInterestConfig, RoleInterestAdmin)
+	handlerMap.Add("GetInterestConfig", cc.GetInterestConfig)
+	handlerMap.Add("AccrueInterest", cc.AccrueInterest, RoleInterestAdmin)
+	handlerMap.Add("GetAccruedInterest", cc.GetAccruedInterest)
+	handlerMap.Add("CreateProduct", cc.CreateProduct, RoleProductAdmin)
+	handlerMap.Add("UpdateProduct", cc.UpdateProduct, RoleProductAdmin)
+	handlerMap.Add("GetProduct", cc.GetProduct)
+	handlerMap.Add("GetProductList", cc.GetProductList)
+	handlerMap.Add("GetAccountHistory", cc.GetAccountHistory)
+	handlerMap.Add("GetStatement", cc.GetStatement)
+	handlerMap.Add("TakeBalanceSnapshots", cc.TakeBalanceSnapshots, RoleRecordsAdmin)
+	handlerMap.Add("GetBalanceSnapshots", cc.GetBalanceSnapshots)
+	handlerMap.Add("GetFinalStatement", cc.GetFinalStatement)
+	handlerMap.Add("CreateStandingOrder", cc.CreateStandingOrder, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("CancelStandingOrder", cc.CancelStandingOrder, RoleCustomer, RoleTeller, RoleAccountOperator)
+	handlerMap.Add("GetStandingOrders", cc.GetStandingOrders)
+	handlerMap.Add("ExecuteDueStandingOrders", cc.ExecuteDueStandingOrders, RoleAccountOperator)
+	handlerMap.Add("FreezeAccount", cc.FreezeAccount, RoleComplianceOfficer, RoleRegulator)
+	handlerMap.Add("UnfreezeAccount", cc.UnfreezeAccount, RoleComplianceOfficer, RoleRegulator)
+	handlerMap.Add("SetOverdraftLimit", cc.SetOverdraftLimit, RoleCreditOfficer)
+	handlerMap.Add("SetFeeSchedule", cc.SetFeeSchedule, RoleFeeAdmin)
+	handlerMap.Add("GetFeeSchedule", cc.GetFeeSchedule)
+	handlerMap.Add("Mint", cc.Mint, RoleEmissionAuthority, RoleIssuer)
+	handlerMap.Add("Burn", cc.Burn, RoleEmissionAuthority, RoleIssuer)
+	handlerMap.Add("TotalSupply", cc.TotalSupply)
+	handlerMap.Add("GetEmissionRecords", cc.GetEmissionRecords)
+	handlerMap.Add("GetCurrencyMetrics", cc.GetCurrencyMetrics)
+	handlerMap.Add("RebuildCirculation", cc.RebuildCirculation, RoleNetworkOperator)
+	handlerMap.Add("RebuildTransactionIndexes", cc.RebuildTransactionIndexes, RoleNetworkOperator)
+	handlerMap.Add("SetEmissionPolicy", cc.SetEmissionPolicy, RoleNetworkOperator)
+	handlerMap.Add("GetEmissionPolicy", cc.GetEmissionPolicy)
+	handlerMap.Add("ProposeEmission", cc.ProposeEmission, RoleEmissionAuthority, RoleIssuer)
+	handlerMap.Add("ApproveEmission", cc.ApproveEmission, RoleEmissionAuthority, RoleIssuer)
+	handlerMap.Add("ExecuteEmission", cc.ExecuteEmission, RoleEmissionAuthority, RoleIssuer)
+	handlerMap.Add("GetEmissionProposals", cc.GetEmissionProposals)
+	handlerMap.Add("GrantRole", cc.GrantRole, RoleNetworkOperator)
+	handlerMap.Add("RevokeRole", cc.RevokeRole, RoleNetworkOperator)
+	handlerMap.Add("GetRoles", cc.GetRoles)
+	handlerMap.Add("MigrateKeys", cc.MigrateKeys, RoleNetworkOperator)
+	handlerMap.Add("MigrateKey", cc.MigrateKey, RoleNetworkOperator)
+	handlerMap.Add("MigrateTransactionKeys", cc.MigrateTransactionKeys, RoleNetworkOperator)
+	handlerMap.Add("GetMetrics", cc.GetMetrics, RoleNetworkOperator, RoleAuditor)
 }
 
 // Helper functions
 
-func (cc *Chaincode) createCompositeKey(objectType string, attributes []string) (string, error) {
-	const minKeyValue = "0"
-	key := objectType + minKeyValue
-	for _, att := range attributes {
-		key += att + minKeyValue
+// createCompositeKey creates the state key of an object from its object type and
+// key attributes. Keys are built by the shim, which delimits the object type and
+// every attribute with U+0000, so a partial key of one object type or attribute
+// value never matches the keys of another; see migrateKeys for keys written
+// before the shim format was adopted.
+func (cc *Chaincode) createCompositeKey(stub shim.ChaincodeStubInterface, objectType string, attributes []string) (string, error) {
+	key, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		loggerFor(stub).Errorf("Error creating %s key. Error: %s", objectType, err)
+		return "", err
 	}
-	logger.Debugf("Created composite key: %s", key)
 	return key, nil
 }
 
-func (cc *Chaincode) partialCompositeKeyQuery(stub shim.ChaincodeStubInterface, objectType string, keys []string) (shim.StateRangeQueryIteratorInterface, error) {
-	partialCompositeKey, _ := cc.createCompositeKey(objectType, keys)
-	keysIter, err := stub.RangeQueryState(partialCompositeKey, partialCompositeKey+string(utf8.MaxRune))
+func (cc *Chaincode) partialCompositeKeyQuery(stub shim.ChaincodeStubInterface, objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	keysIter, err := stub.GetStateByPartialCompositeKey(objectType, keys)
 	if err != nil {
 		return nil, fmt.Errorf("Error fetching rows: %s", err)
 	}
 	return keysIter, nil
 }
 
+// compositeKeyRange scans the composite keys under the partial composite key
+// prefix from start, inclusive, to end, exclusive. Peers refuse range queries
+// over composite keys, so the whole partial composite key is queried and the
+// keys outside the range are skipped.
+//
+// The range therefore bounds the results, not the cost: a query of a few days
+// of an account's transactions still reads, and records in the read set, every
+// transaction key of the account up to the end of the range. Callers that can
+// name the attributes of the range, e.g. a single day, should query that
+// partial composite key instead.
+//
+// Like every range query on Fabric, the scan reads committed state only: the
+// txStub and tenantStub wrappers do not merge the writes buffered earlier in
+// the same transaction into it, so records put by the invocation itself are
+// not returned.
+func compositeKeyRange(stub shim.ChaincodeStubInterface, prefix string, start string, end string) (shim.StateQueryIteratorInterface, error) {
+	objectType, attributes, err := stub.SplitCompositeKey(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid partial composite key %q. Error: %s", prefix, err)
+	}
+	keysIter, err := stub.GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching rows: %s", err)
+	}
+	return &keyRangeIterator{StateQueryIteratorInterface: keysIter, start: start, end: end}, nil
+}
+
+// keyRangeIterator skips the records of a partial composite key query before
+// the start key and ends at the end key
+type keyRangeIterator struct {
+	shim.StateQueryIteratorInterface
+	start string
+	end   string
+	next  *queryresult.KV
+	err   error
+	done  bool
+}
+
+// HasNext returns true if another record within the range remains
+func (it *keyRangeIterator) HasNext() bool {
+	for it.next == nil && it.err == nil && !it.done && it.StateQueryIteratorInterface.HasNext() {
+		kv, err := it.StateQueryIteratorInterface.Next()
+		switch {
+		case err != nil:
+			it.err = err
+		case kv.Key >= it.end:
+			it.done = true
+		case kv.Key >= it.start:
+			it.next = kv
+		}
+	}
+	return it.next != nil || it.err != nil
+}
+
+// Next returns the next record within the range
+func (it *keyRangeIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, errors.New("No more query results")
+	}
+	kv, err := it.next, it.err
+	it.next, it.err = nil, nil
+	return kv, err
+}
+
+// nextValue returns the value of the next record of a range query, nil on error
+func nextValue(it shim.StateQueryIteratorInterface) []byte {
+	kv, err := it.Next()
+	if err != nil || kv == nil {
+		return nil
+	}
+	return kv.Value
+}
+
+// nextKeyValue returns the key and value of the next record of a range query
+func nextKeyValue(it shim.StateQueryIteratorInterface) (string, []byte, error) {
+	kv, err := it.Next()
+	if err != nil {
+		return "", nil, err
+	}
+	return kv.Key, kv.Value, nil
+}
+
+// scanCompositeKeyPage calls fn with the key and value of at most pageSize
+// records under a partial composite key in key order, starting after the
+// bookmark key, without collecting them. It returns the bookmark of the next
+// page, which is empty on the last page. A page ends early when fn returns
+// errListFull, and the next page starts with the record fn refused.
+func (cc *Chaincode) scanCompositeKeyPage(stub shim.ChaincodeStubInterface, objectType string, keys []string, bookmark string, pageSize int, fn func(key string, value []byte) error) (string, error) {
+	partialCompositeKey, err := cc.createCompositeKey(stub, objectType, keys)
+	if err != nil {
+		return "", err
+	}
+	return scanKeyRangePage(stub, partialCompositeKey, partialCompositeKey, partialCompositeKey+string(utf8.MaxRune), bookmark, pageSize, fn)
+}
+
+// dayRange returns the key prefix of the records of an object type under the
+// partial key, and the range of their keys from the first to the last day
+// (YYYY-MM-DD, inclusive), for object types whose next key attribute is the
+// day bucket of the record. Either day may be empty to leave the range open.
+func (cc *Chaincode) dayRange(stub shim.ChaincodeStubInterface, objectType string, keys []string, from string, to string) (string, string, string, error) {
+	fromBucket, err := model.DayBucketOf(from)
+	if err != nil {
+		return "", "", "", err
+	}
+	toBucket, err := model.DayBucketOf(to)
+	if err != nil {
+		return "", "", "", err
+	}
+	if fromBucket != "" && toBucket != "" && fromBucket > toBucket {
+		return "", "", "", fmt.Errorf("Day range %s to %s ends before it starts", from, to)
+	}
+	prefix, err := cc.createCompositeKey(stub, objectType, keys)
+	if err != nil {
+		return "", "", "", err
+	}
+	start, end := prefix, prefix+string(utf8.MaxRune)
+	if fromBucket != "" {
+		start, _ = cc.createCompositeKey(stub, objectType, append(keys[:len(keys):len(keys)], fromBucket))
+	}
+	if toBucket != "" {
+		end, _ = cc.createCompositeKey(stub, objectType, append(keys[:len(keys):len(keys)], toBucket))
+		end += string(utf8.MaxRune)
+	}
+	return prefix, start, end, nil
+}
+
+// scanKeyRangePage pages through the keys from start to end like
+// scanCompositeKeyPage. Bookmarks must lie under the prefix of the range.
+func scanKeyRangePage(stub shim.ChaincodeStubInterface, prefix string, start string, end string, bookmark string, pageSize int, fn func(key string, value []byte) error) (string, error) {
+	if bookmark != "" {
+		if !strings.HasPrefix(bookmark, prefix) {
+			return "", fmt.Errorf("Invalid bookmark %s", bookmark)
+		}
+		// the range start is inclusive, so start just after the bookmark
+		start = bookmark + "\x00"
+	}
+	keysIter, err := compositeKeyRange(stub, prefix, start, end)
+	if err != nil {
+		return "", err
+	}
+	defer keysIter.Close()
+	scanned := 0
+	lastKey := ""
+	for keysIter.HasNext() {
+		key, value, err := nextKeyValue(keysIter)
+		if err != nil {
+			return "", err
+		}
+		if scanned == pageSize {
+			return lastKey, nil
+		}
+		if err := fn(key, value); err == errListFull {
+			return lastKey, nil
+		} else if err != nil {
+			return "", err
+		}
+		scanned++
+		lastKey = key
+	}
+	return "", nil
+}
+
+// listPageSizeArg parses the optional page size argument of a list query
+func listPageSizeArg(args []string, i int) (int, error) {
+	size := optionalArg(args, i)
+	if size == "" {
+		return model.DefaultListPageSize, nil
+	}
+	n, err := strconv.Atoi(size)
+	if err != nil || n <= 0 || n > model.MaxListPageSize {
+		return 0, fmt.Errorf("Invalid page size %s", size)
+	}
+	return n, nil
+}
+
 // bytesToStruct unmarshals byte slice into given data type
 func bytesToStruct(data []byte, v interface{}) error {
 	if err := json.Unmarshal(data, v); err != nil {
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+//------------------------------
+// Withholding tax handler functions
+//------------------------------
+
+// SetWithholdingRule creates or replaces the withholding rule of a corridor and purpose code
+func (cc *Chaincode) SetWithholdingRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing required withholding rule data JSON")
+	}
+	rule, err := model.CreateWithholdingRule([]byte(args[0]), txContext(stub))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating withholding rule. Error: %s", err)
+	}
+	if _, err := cc.getAccountStruct(stub, rule.TaxAuthorityCustomerID, rule.TaxAuthorityAccountID); err != nil {
+		return nil, err
+	}
+	ruleData, _ := json.Marshal(rule)
+	key, _ := cc.createCompositeKey(stub, rule.GetObjectType(), []string{rule.FromCountry, rule.ToCountry, rule.PurposeCode})
+	if err := stub.PutState(key, ruleData); err != nil {
+		return nil, err
+	}
+	return ruleData, nil
+}
+
+// GetWithholdingRule query the withholding rule of a corridor and optional purpose code
+func (cc *Chaincode) GetWithholdingRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New("Missing required from country and / or to country")
+	}
+	key, _ := cc.createCompositeKey(stub, model.WithholdingRuleObjectType, []string{args[0], args[1], optionalArg(args, 2)})
+	return stub.GetState(key)
+}
+
+// RemoveWithholdingRule deletes the withholding rule of a corridor and optional purpose code
+func (cc *Chaincode) RemoveWithholdingRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New("Missing required from country and / or to country")
+	}
+	key, _ := cc.createCompositeKey(stub, model.WithholdingRuleObjectType, []string{args[0], args[1], optionalArg(args, 2)})
+	return nil, stub.DelState(key)
+}
+
+// withholdingRule finds the rule for a corridor, preferring a purpose-specific rule
+func (cc *Chaincode) withholdingRule(stub shim.ChaincodeStubInterface, fromCountry string, toCountry string, purposeCode string) (*model.WithholdingRule, error) {
+	purposes := []string{""}
+	if purposeCode != "" {
+		purposes = []string{purposeCode, ""}
+	}
+	for _, purpose := range purposes {
+		key, _ := cc.createCompositeKey(stub, model.WithholdingRuleObjectType, []string{fromCountry, toCountry, purpose})
+		ruleBytes, err := stub.GetState(key)
+		if err != nil {
+			return nil, err
+		}
+		if ruleBytes != nil {
+			rule := new(model.WithholdingRule)
+			if err := bytesToStruct(ruleBytes, rule); err != nil {
+				return nil, err
+			}
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// applyWithholding attaches a withholding certificate to a cross-border transfer
+// covered by a rule and returns the tax authority account to credit
+func (cc *Chaincode) applyWithholding(stub shim.ChaincodeStubInterface, from *model.Account, to *model.Account, t *model.Transfer) (*model.Account, error) {
+	t.Withholding = nil
+	if from.CountryCode == to.CountryCode {
+		return nil, nil
+	}
+	rule, err := cc.withholdingRule(stub, from.CountryCode, to.CountryCode, t.PurposeCode)
+	if err != nil || rule == nil {
+		return nil, err
+	}
+	authority, err := cc.getAccountStruct(stub, rule.TaxAuthorityCustomerID, rule.TaxAuthorityAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if authority.CurrencyCode != t.CurrencyCode {
+		return nil, fmt.Errorf("Tax authority account currency %s does not match transfer currency %s", authority.CurrencyCode, t.CurrencyCode)
+	}
+	if (authority.CustomerID == from.CustomerID && authority.ID == from.ID) || (authority.CustomerID == to.CustomerID && authority.ID == to.ID) {
+		return nil, errors.New("Tax authority account cannot be a party to the transfer")
+	}
+	t.Withholding = rule.Withhold(t.Amount, t.PurposeCode, stub.GetTxID(), txContext(stub))
+	return authority, nil
+}
+
+// optionalArg returns the argument at index i, or an empty string if absent
+func optionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestCrossBorderTransfersWithholdTax(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1").InCountry("NZ"))
+	stub.OpenAccount(t, testsupport.NewAccount("1003", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("9001", "1"))
+	stub.Topup(t, "1001", "1", 10000)
+	stub.As(testsupport.Operator(t, RoleComplianceOfficer)).MustCall(t, "SetWithholdingRule", `{"from_country":"AU","to_country":"NZ","rate":1500,"tax_authority_customer":"9001","tax_authority_account":"1"}`)
+
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 2000).JSON())
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1003", "1", 1000).JSON())
+
+	if balance := balanceOf(t, stub, "1001", "1"); balance != 7000 {
+		t.Errorf("Expected the payer debited the gross amounts, got %d", balance)
+	}
+	if balance := balanceOf(t, stub, "1002", "1"); balance != 1700 {
+		t.Errorf("Expected the foreign payee credited the net amount, got %d", balance)
+	}
+	if balance := balanceOf(t, stub, "9001", "1"); balance != 300 {
+		t.Errorf("Expected the tax authority credited the withheld tax, got %d", balance)
+	}
+	if balance := balanceOf(t, stub, "1003", "1"); balance != 1000 {
+		t.Errorf("Expected nothing withheld from a domestic transfer, got %d", balance)
+	}
+}
@@ -0,0 +1,442 @@
+// Package client lets Go applications call the FinNet chaincode through the
+// Fabric SDK with typed methods. Each method marshals its arguments the way
+// the handler parses them, submits or evaluates the transaction, and decodes
+// the response into the model types or the error into an *Error.
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// ErrNotFound is returned by queries for a record that does not exist
+var ErrNotFound = errors.New("not found")
+
+// Contract is the chaincode a Client invokes. It is implemented by
+// *gateway.Contract of the Fabric SDK and can be replaced in tests.
+type Contract interface {
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+	RegisterEvent(eventFilter string) (fab.Registration, <-chan *fab.CCEvent, error)
+	Unregister(registration fab.Registration)
+}
+
+// transactionCreator is implemented by *gateway.Contract, whose transactions
+// can carry transient data
+type transactionCreator interface {
+	CreateTransaction(name string, opts ...gateway.TransactionOption) (*gateway.Transaction, error)
+}
+
+// privateDataSaltKey is the transient data field carrying the secret salt the
+// chaincode hashes private fields with
+const privateDataSaltKey = "private_data_salt"
+
+// Client invokes the FinNet chaincode
+type Client struct {
+	contract Contract
+}
+
+// New creates a client invoking the contract
+func New(contract Contract) *Client {
+	return &Client{contract: contract}
+}
+
+// Connect creates a client for the chaincode deployed on a channel the
+// gateway is connected to
+func Connect(gw *gateway.Gateway, channel string, chaincode string) (*Client, error) {
+	network, err := gw.GetNetwork(channel)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get network %s. Error: %s", channel, err)
+	}
+	return New(network.GetContract(chaincode)), nil
+}
+
+// Submit submits a transaction of any chaincode function with its raw
+// arguments and returns the raw response. Cancelling the context stops
+// waiting for the result but does not withdraw a transaction already sent
+// for ordering.
+func (c *Client) Submit(ctx context.Context, function string, args ...string) ([]byte, error) {
+	return c.call(ctx, c.submit, function, args)
+}
+
+// submit submits a transaction passing a new random salt for the hashes of
+// its private fields as transient data, which is not recorded on the ledger
+func (c *Client) submit(function string, args ...string) ([]byte, error) {
+	creator, ok := c.contract.(transactionCreator)
+	if !ok {
+		return c.contract.SubmitTransaction(function, args...)
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("Error generating private data salt. Error: %s", err)
+	}
+	txn, err := creator.CreateTransaction(function, gateway.WithTransient(map[string][]byte{privateDataSaltKey: salt}))
+	if err != nil {
+		return nil, err
+	}
+	return txn.Submit(args...)
+}
+
+// Evaluate queries any chaincode function with its raw arguments and returns
+// the raw response, without submitting a transaction
+func (c *Client) Evaluate(ctx context.Context, function string, args ...string) ([]byte, error) {
+	return c.call(ctx, c.contract.EvaluateTransaction, function, args)
+}
+
+// traceparentKey is the context key of the traceparent of invocations
+type traceparentKey struct{}
+
+// WithTraceparent returns a context whose invocations pass the W3C
+// traceparent to the chaincode, which attaches it to its log lines, events
+// and audit entry. An invalid traceparent is ignored.
+func WithTraceparent(ctx context.Context, traceparent string) context.Context {
+	if !model.IsTraceparent(traceparent) {
+		return ctx
+	}
+	return context.WithValue(ctx, traceparentKey{}, traceparent)
+}
+
+// Traceparent returns the traceparent invocations with the context pass, if any
+func Traceparent(ctx context.Context) string {
+	traceparent, _ := ctx.Value(traceparentKey{}).(string)
+	return traceparent
+}
+
+type result struct {
+	res []byte
+	err error
+}
+
+func (c *Client) call(ctx context.Context, send func(string, ...string) ([]byte, error), function string, args []string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if traceparent := Traceparent(ctx); traceparent != "" {
+		args = append(args[:len(args):len(args)], traceparent)
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := send(function, args...)
+		done <- result{res, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			return nil, newError(function, r.err)
+		}
+		return r.res, nil
+	}
+}
+
+// submitJSON submits a transaction and decodes its JSON response into v
+func (c *Client) submitJSON(ctx context.Context, v interface{}, function string, args ...string) error {
+	res, err := c.Submit(ctx, function, args...)
+	if err != nil {
+		return err
+	}
+	return decode(function, res, v)
+}
+
+// evaluateJSON evaluates a query and decodes its JSON response into v. An
+// empty response is reported as ErrNotFound.
+func (c *Client) evaluateJSON(ctx context.Context, v interface{}, function string, args ...string) error {
+	res, err := c.Evaluate(ctx, function, args...)
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		return ErrNotFound
+	}
+	return decode(function, res, v)
+}
+
+func decode(function string, res []byte, v interface{}) error {
+	if err := json.Unmarshal(res, v); err != nil {
+		return fmt.Errorf("Error decoding %s response. Error: %s", function, err)
+	}
+	return nil
+}
+
+// jsonArg encodes a payload as the JSON argument its handler parses
+func jsonArg(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// pageArgs returns the optional page size and bookmark arguments of a list
+// query, leaving out those not set
+func pageArgs(args []string, page *Page) []string {
+	if page == nil || (page.Size == 0 && page.Bookmark == "") {
+		return args
+	}
+	size := ""
+	if page.Size > 0 {
+		size = strconv.Itoa(page.Size)
+	}
+	args = append(args, size)
+	if page.Bookmark != "" {
+		args = append(args, page.Bookmark)
+	}
+	return args
+}
+
+// Page selects a page of a list query. A zero Size uses the default page
+// size of the chaincode; Bookmark is the next_bookmark of the previous page.
+type Page struct {
+	Size     int
+	Bookmark string
+}
+
+//------------------------------
+// Account functions
+//------------------------------
+
+// OpenAccount opens an account and returns it as stored on the ledger
+func (c *Client) OpenAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	arg, err := jsonArg(account)
+	if err != nil {
+		return nil, err
+	}
+	opened := new(model.Account)
+	if err := c.submitJSON(ctx, opened, "OpenAccount", arg); err != nil {
+		return nil, err
+	}
+	return opened, nil
+}
+
+// CloseAccount closes an account. An account with a balance is only closed
+// when a sweep customer and account ID name an account to move it into;
+// leave them empty otherwise.
+func (c *Client) CloseAccount(ctx context.Context, customerID string, accountID string, sweepCustomerID string, sweepAccountID string) (*model.Account, error) {
+	args := []string{customerID, accountID}
+	if sweepCustomerID != "" || sweepAccountID != "" {
+		args = append(args, sweepCustomerID, sweepAccountID)
+	}
+	closed := new(model.Account)
+	if err := c.submitJSON(ctx, closed, "CloseAccount", args...); err != nil {
+		return nil, err
+	}
+	return closed, nil
+}
+
+// TopupAccount credits an account with an amount in cents
+func (c *Client) TopupAccount(ctx context.Context, customerID string, accountID string, amount int64) (*model.Account, error) {
+	account := new(model.Account)
+	if err := c.submitJSON(ctx, account, "TopupAccount", customerID, accountID, strconv.FormatInt(amount, 10)); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// GetAccount query an account, ErrNotFound if it does not exist
+func (c *Client) GetAccount(ctx context.Context, customerID string, accountID string) (*model.Account, error) {
+	account := new(model.Account)
+	if err := c.evaluateJSON(ctx, account, "GetAccount", customerID, accountID); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// GetBalance query the balance and available balance of an account
+func (c *Client) GetBalance(ctx context.Context, customerID string, accountID string) (*model.Balance, error) {
+	balance := new(model.Balance)
+	if err := c.evaluateJSON(ctx, balance, "GetBalance", customerID, accountID); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// GetAccountList query a page of the accounts of a customer; page may be nil
+func (c *Client) GetAccountList(ctx context.Context, customerID string, page *Page) (*model.AccountList, error) {
+	list := new(model.AccountList)
+	if err := c.evaluateJSON(ctx, list, "GetAccountList", pageArgs([]string{customerID}, page)...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+//------------------------------
+// Transfer functions
+//------------------------------
+
+// TransferResponse is the outcome of a transfer. Exactly one field is set:
+// the Result of an executed transfer, the Approval of a transfer held in the
+// approval queue, or the Proposal of a transfer from a multi-signature
+// account awaiting its signers.
+type TransferResponse struct {
+	Result   *model.TransferResult
+	Approval *model.TransferApproval
+	Proposal *model.OutgoingTransfer
+}
+
+// Transfer submits a transfer. A transfer stopped by sanctions screening is
+// committed as failed and returned without an error; check Result.Status.
+func (c *Client) Transfer(ctx context.Context, t *model.Transfer) (*TransferResponse, error) {
+	arg, err := jsonArg(t)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Submit(ctx, "TransferMoney", arg)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := decode("TransferMoney", res, &fields); err != nil {
+		return nil, err
+	}
+	response := new(TransferResponse)
+	switch {
+	case fields["required_approvals"] != nil:
+		response.Approval = new(model.TransferApproval)
+		err = decode("TransferMoney", res, response.Approval)
+	case fields["required_signatures"] != nil:
+		response.Proposal = new(model.OutgoingTransfer)
+		err = decode("TransferMoney", res, response.Proposal)
+	default:
+		response.Result = new(model.TransferResult)
+		err = decode("TransferMoney", res, response.Result)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetTransactionList query a page of the transactions of an account; page may be nil
+func (c *Client) GetTransactionList(ctx context.Context, customerID string, accountID string, page *Page) (*model.TransactionList, error) {
+	list := new(model.TransactionList)
+	if err := c.evaluateJSON(ctx, list, "GetTransactionList", pageArgs([]string{customerID, accountID}, page)...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetTransactionListBetween query a page of the transactions of an account
+// created from the first to the last day (YYYY-MM-DD, inclusive); either day
+// may be empty to leave the range open and page may be nil
+func (c *Client) GetTransactionListBetween(ctx context.Context, customerID string, accountID string, from string, to string, page *Page) (*model.TransactionList, error) {
+	if from == "" && to == "" {
+		return c.GetTransactionList(ctx, customerID, accountID, page)
+	}
+	size, bookmark := "", ""
+	if page != nil {
+		if page.Size > 0 {
+			size = strconv.Itoa(page.Size)
+		}
+		bookmark = page.Bookmark
+	}
+	list := new(model.TransactionList)
+	if err := c.evaluateJSON(ctx, list, "GetTransactionList", customerID, accountID, size, bookmark, from, to); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetTransaction query a transaction of an account, ErrNotFound if it does not exist
+func (c *Client) GetTransaction(ctx context.Context, customerID string, accountID string, transactionID string) (*model.Transaction, error) {
+	txn := new(model.Transaction)
+	if err := c.evaluateJSON(ctx, txn, "GetTransaction", customerID, accountID, transactionID); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+//------------------------------
+// Emission and limits functions
+//------------------------------
+
+// Mint creates money in an account in minor units of its currency, with an
+// optional reference. Amounts above the emission policy threshold must be
+// proposed and approved instead.
+func (c *Client) Mint(ctx context.Context, customerID string, accountID string, amount int64, reference string) (*model.EmissionRecord, error) {
+	return c.emit(ctx, "Mint", customerID, accountID, amount, reference)
+}
+
+// Burn removes money from an account in minor units of its currency, with an
+// optional reference
+func (c *Client) Burn(ctx context.Context, customerID string, accountID string, amount int64, reference string) (*model.EmissionRecord, error) {
+	return c.emit(ctx, "Burn", customerID, accountID, amount, reference)
+}
+
+func (c *Client) emit(ctx context.Context, function string, customerID string, accountID string, amount int64, reference string) (*model.EmissionRecord, error) {
+	args := []string{customerID, accountID, strconv.FormatInt(amount, 10)}
+	if reference != "" {
+		args = append(args, reference)
+	}
+	record := new(model.EmissionRecord)
+	if err := c.submitJSON(ctx, record, function, args...); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// SetLimits sets the transaction limits of a customer in a currency
+func (c *Client) SetLimits(ctx context.Context, limits *model.Limits) (*model.Limits, error) {
+	arg, err := jsonArg(limits)
+	if err != nil {
+		return nil, err
+	}
+	set := new(model.Limits)
+	if err := c.submitJSON(ctx, set, "SetLimits", arg); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+//------------------------------
+// Migration functions
+//------------------------------
+
+// LoadAccounts opens the accounts of a chunk of a migrated account book with
+// their opening balances and returns the outcome of every row. With an
+// idempotency key, a chunk resubmitted after a lost response is not loaded
+// again.
+func (c *Client) LoadAccounts(ctx context.Context, load *model.AccountLoad, idempotencyKey string) (*model.AccountLoadResult, error) {
+	arg, err := jsonArg(load)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{arg}
+	if idempotencyKey != "" {
+		args = append(args, idempotencyKey)
+	}
+	result := new(model.AccountLoadResult)
+	if err := c.submitJSON(ctx, result, "LoadAccounts", args...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//------------------------------
+// Operations functions
+//------------------------------
+
+// GetMetrics returns the handler metrics of the chaincode container of the
+// peer answering the query
+func (c *Client) GetMetrics(ctx context.Context) (*model.Metrics, error) {
+	metrics := new(model.Metrics)
+	if err := c.evaluateJSON(ctx, metrics, "GetMetrics"); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// GetPrometheusMetrics returns the handler metrics of the chaincode container
+// of the peer answering the query in the Prometheus text exposition format
+func (c *Client) GetPrometheusMetrics(ctx context.Context) ([]byte, error) {
+	return c.Evaluate(ctx, "GetMetrics", "prometheus")
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// fakeContract answers every transaction with a fixed response or error
+type fakeContract struct {
+	res  []byte
+	err  error
+	name string
+	args []string
+}
+
+func (f *fakeContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	f.name, f.args = name, args
+	return f.res, f.err
+}
+
+func (f *fakeContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	return f.SubmitTransaction(name, args...)
+}
+
+func (f *fakeContract) RegisterEvent(eventFilter string) (fab.Registration, <-chan *fab.CCEvent, error) {
+	return nil, nil, errors.New("not supported")
+}
+
+func (f *fakeContract) Unregister(registration fab.Registration) {}
+
+func TestErrorDecoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     string
+		message string
+		code    model.TxFailureCode
+		fields  int
+	}{
+		{
+			name:    "endorsement failure",
+			err:     "Failed to submit: Multiple errors occurred: - Transaction processing for endorser [peer0:7051]: Chaincode status Code: (500) UNKNOWN. Description: Account with number 1 not found.",
+			message: "Account with number 1 not found.",
+		},
+		{
+			name:    "failure code",
+			err:     "Chaincode status Code: (500) UNKNOWN. Description: concurrent_modification: Account 1 is at version 8, expected 7",
+			message: "concurrent_modification: Account 1 is at version 8, expected 7",
+			code:    model.ConcurrentlyModified,
+		},
+		{
+			name:    "invalid fields",
+			err:     `Description: Error creating new account. Error: Invalid fields [{"field":"currency","rule":"required","message":"is required"}]`,
+			message: `Error creating new account. Error: Invalid fields [{"field":"currency","rule":"required","message":"is required"}]`,
+			fields:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&fakeContract{err: errors.New(tt.err)})
+			_, err := c.Submit(context.Background(), "OpenAccount", "{}")
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+			if e.Message != tt.message || e.Code != tt.code || len(e.Fields) != tt.fields {
+				t.Errorf("unexpected error %+v", e)
+			}
+		})
+	}
+}
+
+func TestTransferDecodesResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		res  string
+		set  func(r *TransferResponse) bool
+	}{
+		{"executed", `{"from_account":"1","status":"completed","tx_id":"tx1","balance":9000}`, func(r *TransferResponse) bool { return r.Result != nil && *r.Result.Balance == 9000 }},
+		{"held for approval", `{"id":"a1","required_approvals":2,"status":"pending_approval"}`, func(r *TransferResponse) bool { return r.Approval != nil && r.Approval.ID == "a1" }},
+		{"proposed to signers", `{"id":"p1","required_signatures":2}`, func(r *TransferResponse) bool { return r.Proposal != nil && r.Proposal.Quorum == 2 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contract := &fakeContract{res: []byte(tt.res)}
+			res, err := New(contract).Transfer(context.Background(), &model.Transfer{FromCustomerID: "1234", FromAccountID: "1", Amount: 1000})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contract.name != "TransferMoney" || len(contract.args) != 1 {
+				t.Errorf("unexpected invocation %s %v", contract.name, contract.args)
+			}
+			if !tt.set(res) {
+				t.Errorf("unexpected response %+v", res)
+			}
+		})
+	}
+}
+
+func TestGetAccountNotFound(t *testing.T) {
+	_, err := New(&fakeContract{}).GetAccount(context.Background(), "1234", "1")
+	if err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestTraceparentPassedAsLastArgument(t *testing.T) {
+	contract := &fakeContract{res: []byte(`{"id":"1","traceparent":"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}`)}
+	c := New(contract)
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if _, err := c.GetAccount(WithTraceparent(context.Background(), traceparent), "1234", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(contract.args) != 3 || contract.args[2] != traceparent {
+		t.Errorf("Expected the traceparent passed last, got %q", contract.args)
+	}
+	if _, err := c.GetAccount(WithTraceparent(context.Background(), "not-a-traceparent"), "1234", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(contract.args) != 2 {
+		t.Errorf("Expected an invalid traceparent ignored, got %q", contract.args)
+	}
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/iShamSLam/chaincode/model"
+)
+
+// failureCodes are the failure codes chaincode errors may start with
+var failureCodes = []model.TxFailureCode{
+	model.InsufficientFunds,
+	model.ClosedAccount,
+	model.AccountInactive,
+	model.ExposureLimitExceeded,
+	model.BudgetExceeded,
+	model.LimitExceeded,
+	model.SanctionsHit,
+	model.InvalidAmount,
+	model.ConcurrentlyModified,
+	model.BeneficiaryNotAllowed,
+	model.QuoteExpired,
+}
+
+// Error is a chaincode function failure, with the message of the handler
+// stripped of the SDK's endorsement error wrapping
+type Error struct {
+	Function string
+	Message  string
+	Code     model.TxFailureCode // set if the message starts with a failure code
+	Fields   []*model.FieldError // the invalid fields of a rejected payload
+	Rejected bool                // false if the SDK failed before the chaincode answered, e.g. no peer was reachable
+	Err      error               // the error returned by the SDK
+}
+
+func (e *Error) Error() string {
+	return e.Function + ": " + e.Message
+}
+
+// Unwrap returns the error returned by the SDK
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsConcurrentModification returns true if the function failed because a
+// record was written since the client read it, so reading it again and
+// retrying may succeed
+func (e *Error) IsConcurrentModification() bool {
+	return e.Code == model.ConcurrentlyModified
+}
+
+// newError decodes the error of a function returned by the SDK
+func newError(function string, err error) *Error {
+	e := &Error{Function: function, Err: err}
+	e.Message, e.Rejected = chaincodeMessage(err.Error())
+	for _, code := range failureCodes {
+		if strings.HasPrefix(e.Message, string(code)+": ") {
+			e.Code = code
+			break
+		}
+	}
+	// validation errors may be wrapped by the handler, e.g. "Error creating
+	// new account. Error: Invalid fields [...]"
+	if i := strings.Index(e.Message, "Invalid fields "); i >= 0 {
+		var fields []*model.FieldError
+		if err := json.NewDecoder(strings.NewReader(e.Message[i+len("Invalid fields "):])).Decode(&fields); err == nil {
+			e.Fields = fields
+		}
+	}
+	return e
+}
+
+// chaincodeMessage returns the message of the chaincode error an SDK error
+// reports, and false with the SDK error if it reports none. Endorsement
+// failures carry it after "Description: ", once per endorsing peer.
+func chaincodeMessage(msg string) (string, bool) {
+	const description = "Description: "
+	i := strings.LastIndex(msg, description)
+	if i < 0 {
+		return msg, false
+	}
+	msg = msg[i+len(description):]
+	// the gRPC status wrapping the endorsement failures may follow
+	if j := strings.Index(msg, "\n"); j >= 0 {
+		msg = msg[:j]
+	}
+	return strings.TrimSpace(msg), true
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+)
+
+// AllEvents is the event filter matching every chaincode event
+const AllEvents = ".*"
+
+// BlockEvent is a chaincode event delivered with the block committing its
+// transaction
+type BlockEvent struct {
+	BlockNumber uint64
+	Envelope    *model.EventEnvelope
+}
+
+// Events streams the chaincode events whose name matches the filter, e.g.
+// AllEvents, model.EventTransferCompleted or model.EventBatch, until the
+// context is done. An invocation emitting several events sends them as one
+// envelope named model.EventBatch, so filter on the event types inside the
+// envelope rather than the name when events of one type are wanted. Events
+// whose payload cannot be decoded are skipped.
+func (c *Client) Events(ctx context.Context, filter string) (<-chan *BlockEvent, error) {
+	registration, notifier, err := c.contract.RegisterEvent(filter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to register for chaincode events. Error: %s", err)
+	}
+	events := make(chan *BlockEvent)
+	go func() {
+		defer close(events)
+		defer c.contract.Unregister(registration)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ccEvent, ok := <-notifier:
+				if !ok {
+					return
+				}
+				envelope := new(model.EventEnvelope)
+				if err := json.Unmarshal(ccEvent.Payload, envelope); err != nil {
+					continue
+				}
+				select {
+				case events <- &BlockEvent{BlockNumber: ccEvent.BlockNumber, Envelope: envelope}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
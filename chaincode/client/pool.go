@@ -0,0 +1,97 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// ErrUnknownIdentity is returned for an empty identity, or one the wallet
+// does not hold
+var ErrUnknownIdentity = errors.New("Unknown identity")
+
+// OpenWallet opens the file system wallet holding client identities
+func OpenWallet(path string) (*gateway.Wallet, error) {
+	wallet, err := gateway.NewFileSystemWallet(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open wallet %s. Error: %s", path, err)
+	}
+	return wallet, nil
+}
+
+// Pool serves the clients of services invoking the chaincode on behalf of
+// several wallet identities. It connects one gateway per identity on first
+// use and keeps it open for later calls of the identity.
+type Pool struct {
+	wallet    *gateway.Wallet
+	profile   string
+	channel   string
+	chaincode string
+
+	mu       sync.Mutex
+	gateways map[string]*gateway.Gateway
+	clients  map[string]*Client
+}
+
+// NewPool creates a pool of clients for the chaincode on a channel, connecting
+// with the SDK connection profile
+func NewPool(wallet *gateway.Wallet, profile string, channel string, chaincode string) *Pool {
+	return &Pool{
+		wallet:    wallet,
+		profile:   profile,
+		channel:   channel,
+		chaincode: chaincode,
+		gateways:  make(map[string]*gateway.Gateway),
+		clients:   make(map[string]*Client),
+	}
+}
+
+// Client returns the client invoking with the identity
+func (p *Pool) Client(identity string) (*Client, error) {
+	if identity == "" || !p.wallet.Exists(identity) {
+		return nil, ErrUnknownIdentity
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.clients[identity]; ok {
+		return c, nil
+	}
+	gw, err := gateway.Connect(gateway.WithConfig(config.FromFile(p.profile)), gateway.WithIdentity(p.wallet, identity))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect gateway for identity %s. Error: %s", identity, err)
+	}
+	c, err := Connect(gw, p.channel, p.chaincode)
+	if err != nil {
+		gw.Close()
+		return nil, err
+	}
+	p.gateways[identity] = gw
+	p.clients[identity] = c
+	return c, nil
+}
+
+// Forget closes the gateway of an identity, so the next call connects with
+// the identity as the wallet now holds it
+func (p *Pool) Forget(identity string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if gw, ok := p.gateways[identity]; ok {
+		gw.Close()
+		delete(p.gateways, identity)
+		delete(p.clients, identity)
+	}
+}
+
+// Close closes the gateways of all identities
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for identity, gw := range p.gateways {
+		gw.Close()
+		delete(p.gateways, identity)
+		delete(p.clients, identity)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iShamSLam/chaincode/client"
+)
+
+// sink is a destination events are republished to
+type sink interface {
+	// Publish delivers the event, returning an error if it may not have been
+	// received
+	Publish(ctx context.Context, e *client.BlockEvent) error
+	Name() string
+}
+
+// bridge republishes chaincode events to its sinks in the order they were
+// committed. An event is delivered to every sink, retrying failed deliveries
+// with exponential backoff, before the next event is read, and the block it
+// was committed in is then recorded in the checkpoint file.
+type bridge struct {
+	sinks      []sink
+	checkpoint string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// run delivers the events until the context is done, or fails if the
+// stream ends before
+func (b *bridge) run(ctx context.Context, events <-chan *client.BlockEvent) error {
+	for e := range events {
+		for _, s := range b.sinks {
+			if err := b.deliver(ctx, s, e); err != nil {
+				return err
+			}
+		}
+		if err := b.saveCheckpoint(e.BlockNumber); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.New("Event stream closed")
+}
+
+// deliver publishes an event to a sink until it succeeds or the context is done
+func (b *bridge) deliver(ctx context.Context, s sink, e *client.BlockEvent) error {
+	backoff := b.minBackoff
+	for {
+		err := s.Publish(ctx, e)
+		if err == nil {
+			return nil
+		}
+		log.Printf("Failed to publish event of transaction %s to %s, retrying in %s. Error: %s", e.Envelope.TxID, s.Name(), backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > b.maxBackoff {
+			backoff = b.maxBackoff
+		}
+	}
+}
+
+// loadCheckpoint returns the block of the last delivered event, and false if
+// no event was delivered yet
+func (b *bridge) loadCheckpoint() (uint64, bool, error) {
+	if b.checkpoint == "" {
+		return 0, false, nil
+	}
+	data, err := ioutil.ReadFile(b.checkpoint)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	block, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("Invalid checkpoint %s. Error: %s", b.checkpoint, err)
+	}
+	return block, true, nil
+}
+
+// saveCheckpoint records the block of the last delivered event, replacing the
+// checkpoint file atomically
+func (b *bridge) saveCheckpoint(block uint64) error {
+	if b.checkpoint == "" {
+		return nil
+	}
+	tmp := b.checkpoint + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatUint(block, 10)+"\n"), 0644); err != nil {
+		return fmt.Errorf("Failed to write checkpoint. Error: %s", err)
+	}
+	if err := os.Rename(tmp, b.checkpoint); err != nil {
+		return fmt.Errorf("Failed to write checkpoint. Error: %s", err)
+	}
+	return nil
+}
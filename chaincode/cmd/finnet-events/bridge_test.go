@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/client"
+	"github.com/iShamSLam/chaincode/model"
+)
+
+func TestWebhookRetriedUntilDelivered(t *testing.T) {
+	var attempts int
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = ioutil.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "finnet-events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint := filepath.Join(dir, "checkpoint")
+	b := &bridge{
+		sinks:      []sink{&webhookSink{url: srv.URL, secret: []byte("s3cret"), client: srv.Client()}},
+		checkpoint: checkpoint,
+		minBackoff: time.Millisecond,
+		maxBackoff: time.Millisecond,
+	}
+	events := make(chan *client.BlockEvent, 1)
+	events <- &client.BlockEvent{BlockNumber: 42, Envelope: &model.EventEnvelope{
+		TxID:        "t1",
+		Traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Events:      []*model.Event{{Type: model.EventTransferCompleted, Data: []byte(`{}`)}},
+	}}
+	close(events)
+	if err := b.run(context.Background(), events); err == nil || err.Error() != "Event stream closed" {
+		t.Fatalf("expected the closed stream error, got %v", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if got := header.Get(EventHeader); got != model.EventTransferCompleted {
+		t.Errorf("expected event header %s, got %s", model.EventTransferCompleted, got)
+	}
+	if got := header.Get(SignatureHeader); got != "sha256="+sign([]byte("s3cret"), body) {
+		t.Errorf("unexpected signature %s", got)
+	}
+	if got := header.Get(TraceparentHeader); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("unexpected traceparent %s", got)
+	}
+	block, ok, err := b.loadCheckpoint()
+	if err != nil || !ok || block != 42 {
+		t.Errorf("expected checkpoint 42, got %d %v %v", block, ok, err)
+	}
+}
+
+func TestDeliveryStopsWithContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	b := &bridge{
+		sinks:      []sink{&webhookSink{url: srv.URL, client: srv.Client()}},
+		minBackoff: time.Millisecond,
+		maxBackoff: 10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	events := make(chan *client.BlockEvent, 1)
+	events <- &client.BlockEvent{BlockNumber: 7, Envelope: &model.EventEnvelope{TxID: "t1"}}
+	if err := b.run(ctx, events); err != context.DeadlineExceeded {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Command finnet-events republishes the FinNet chaincode events to Kafka
+// topics and webhook URLs, so downstream notification and reconciliation
+// systems receive them without the Fabric SDK. Each event envelope is
+// delivered at least once, in commit order: failed deliveries are retried
+// until they succeed, and after a restart delivery resumes from the block of
+// the last delivered event, so receivers must ignore envelopes whose tx_id
+// they have already seen.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/iShamSLam/chaincode/client"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// urlList collects the values of a repeated flag
+type urlList []string
+
+func (l *urlList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *urlList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	profile := flag.String("connection-profile", "connection.yaml", "Fabric SDK connection profile")
+	walletPath := flag.String("wallet", "wallet", "directory of the identity wallet")
+	identity := flag.String("identity", "", "wallet identity to listen with")
+	channel := flag.String("channel", "mychannel", "channel the chaincode is deployed on")
+	chaincode := flag.String("chaincode", "mycc", "name of the chaincode")
+	filter := flag.String("filter", client.AllEvents, "regular expression of the chaincode event names to republish")
+	checkpoint := flag.String("checkpoint", "finnet-events.checkpoint", "file recording the block of the last delivered event")
+	brokers := flag.String("kafka-brokers", "", "comma separated Kafka broker addresses")
+	topic := flag.String("kafka-topic", "finnet-events", "Kafka topic to write the events to")
+	var webhooks urlList
+	flag.Var(&webhooks, "webhook", "URL to post the events to, repeatable")
+	secret := flag.String("webhook-secret", os.Getenv("FINNET_WEBHOOK_SECRET"), "key of the HMAC signature of webhook requests")
+	timeout := flag.Duration("webhook-timeout", 10*time.Second, "time to wait for a webhook to answer")
+	maxBackoff := flag.Duration("max-backoff", time.Minute, "longest wait between delivery attempts")
+	flag.Parse()
+
+	b := &bridge{checkpoint: *checkpoint, minBackoff: time.Second, maxBackoff: *maxBackoff}
+	if *brokers != "" {
+		k := newKafkaSink(strings.Split(*brokers, ","), *topic)
+		defer k.Close()
+		b.sinks = append(b.sinks, k)
+	}
+	for _, url := range webhooks {
+		b.sinks = append(b.sinks, &webhookSink{url: url, secret: []byte(*secret), client: &http.Client{Timeout: *timeout}})
+	}
+	if len(b.sinks) == 0 {
+		log.Fatal("No sinks, set -kafka-brokers or -webhook")
+	}
+
+	wallet, err := client.OpenWallet(*walletPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !wallet.Exists(*identity) {
+		log.Fatalf("%s %s", client.ErrUnknownIdentity, *identity)
+	}
+	options := []gateway.ConnectOption{gateway.WithConfig(config.FromFile(*profile)), gateway.WithIdentity(wallet, *identity)}
+	block, ok, err := b.loadCheckpoint()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if ok {
+		// events of the checkpoint block after the last delivered one are
+		// not recorded, so the whole block is delivered again
+		options = append(options, gateway.WithBlockNum(block))
+	}
+	gw, err := gateway.Connect(options...)
+	if err != nil {
+		log.Fatalf("Failed to connect gateway for identity %s. Error: %s", *identity, err)
+	}
+	defer gw.Close()
+	c, err := client.Connect(gw, *channel, *chaincode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		cancel()
+	}()
+	events, err := c.Events(ctx, *filter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("finnet-events republishing events of chaincode %s on channel %s to %d sinks", *chaincode, *channel, len(b.sinks))
+	if err := b.run(ctx, events); err != nil && err != context.Canceled {
+		log.Fatal(err)
+	}
+}
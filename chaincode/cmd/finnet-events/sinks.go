@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/iShamSLam/chaincode/client"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers of the HTTP requests and Kafka messages carrying an event
+const (
+	EventHeader     = "X-FinNet-Event"
+	TxIDHeader      = "X-FinNet-Tx-Id"
+	BlockHeader     = "X-FinNet-Block"
+	SignatureHeader = "X-FinNet-Signature"
+	// TraceparentHeader carries the W3C trace context of the invocation that
+	// emitted the event, if it had one
+	TraceparentHeader = "traceparent"
+)
+
+// webhookSink posts the event envelope as JSON to a URL. A response status
+// other than 2xx is a failed delivery. With a secret, the request carries the
+// hex encoded HMAC-SHA256 of the body keyed with the secret.
+type webhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func (w *webhookSink) Name() string {
+	return w.url
+}
+
+func (w *webhookSink) Publish(ctx context.Context, e *client.BlockEvent) error {
+	body, err := json.Marshal(e.Envelope)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, e.Envelope.Name())
+	req.Header.Set(TxIDHeader, e.Envelope.TxID)
+	req.Header.Set(BlockHeader, strconv.FormatUint(e.BlockNumber, 10))
+	if e.Envelope.Traceparent != "" {
+		req.Header.Set(TraceparentHeader, e.Envelope.Traceparent)
+	}
+	if len(w.secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+sign(w.secret, body))
+	}
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("Webhook answered %s", res.Status)
+	}
+	return nil
+}
+
+func sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// kafkaSink writes the event envelope as a JSON message to a Kafka topic,
+// keyed by transaction ID, waiting for all in-sync replicas to acknowledge it
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+func newKafkaSink(brokers []string, topic string) *kafkaSink {
+	return &kafkaSink{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		RequiredAcks: kafka.RequireAll,
+		MaxAttempts:  1,
+	}}
+}
+
+func (k *kafkaSink) Name() string {
+	return "kafka topic " + k.writer.Topic
+}
+
+func (k *kafkaSink) Publish(ctx context.Context, e *client.BlockEvent) error {
+	value, err := json.Marshal(e.Envelope)
+	if err != nil {
+		return err
+	}
+	headers := []kafka.Header{
+		{Key: EventHeader, Value: []byte(e.Envelope.Name())},
+		{Key: BlockHeader, Value: []byte(strconv.FormatUint(e.BlockNumber, 10))},
+	}
+	if e.Envelope.Traceparent != "" {
+		headers = append(headers, kafka.Header{Key: TraceparentHeader, Value: []byte(e.Envelope.Traceparent)})
+	}
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(e.Envelope.TxID),
+		Value:   value,
+		Headers: headers,
+	})
+}
+
+func (k *kafkaSink) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/iShamSLam/chaincode/client"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// IdentityImport is the payload importing a client identity into the wallet
+type IdentityImport struct {
+	Label       string `json:"label"`
+	MSPID       string `json:"msp_id"`
+	Certificate string `json:"certificate"` // PEM encoded X.509 certificate
+	PrivateKey  string `json:"private_key"` // PEM encoded private key
+}
+
+// clientSource returns the chaincode client invoking with a wallet identity,
+// see client.Pool
+type clientSource interface {
+	Client(identity string) (*client.Client, error)
+	Forget(identity string)
+}
+
+// importIdentity stores an X.509 identity in the wallet, replacing an
+// identity of the same label
+func importIdentity(wallet *gateway.Wallet, id *IdentityImport) error {
+	if id.Label == "" || id.MSPID == "" || id.Certificate == "" || id.PrivateKey == "" {
+		return errors.New("Missing required label, msp_id, certificate and / or private_key")
+	}
+	return wallet.Put(id.Label, gateway.NewX509Identity(id.MSPID, id.Certificate, id.PrivateKey))
+}
@@ -0,0 +1,47 @@
+// Command finnet-gateway serves a REST API in front of the FinNet chaincode.
+// Each request is invoked with a client identity of a wallet, chosen by the
+// X-FinNet-Identity header, and chaincode errors are answered with the HTTP
+// status matching their failure code.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/iShamSLam/chaincode/client"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	profile := flag.String("connection-profile", "connection.yaml", "Fabric SDK connection profile")
+	walletPath := flag.String("wallet", "wallet", "directory of the identity wallet")
+	identity := flag.String("identity", "", "wallet identity of requests without an X-FinNet-Identity header")
+	channel := flag.String("channel", "mychannel", "channel the chaincode is deployed on")
+	chaincode := flag.String("chaincode", "mycc", "name of the chaincode")
+	adminToken := flag.String("admin-token", os.Getenv("FINNET_GATEWAY_ADMIN_TOKEN"), "bearer token required to import identities, none disables the identities endpoint")
+	openapi := flag.Bool("openapi", false, "print the OpenAPI specification and exit")
+	flag.Parse()
+
+	if *openapi {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(openAPISpec()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	wallet, err := client.OpenWallet(*walletPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	clients := client.NewPool(wallet, *profile, *channel, *chaincode)
+	defer clients.Close()
+	srv := &server{clients: clients, wallet: wallet, defaultIdentity: *identity, adminToken: *adminToken}
+
+	log.Printf("finnet-gateway listening on %s for chaincode %s on channel %s", *addr, *chaincode, *channel)
+	log.Fatal(http.ListenAndServe(*addr, srv.routes()))
+}
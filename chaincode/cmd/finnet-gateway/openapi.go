@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// APIVersion is the version of the REST API in the OpenAPI specification
+const APIVersion = "1.0.0"
+
+// customFields lists the fields of types with custom JSON marshalling that
+// are written as RFC 3339 date-times instead of unix timestamps, and the
+// fields the marshalling adds
+var customFields = map[string]map[string]map[string]interface{}{
+	"Account": {
+		"created": {"type": "string", "format": "date-time"},
+		"closed":  {"type": "boolean"},
+	},
+	"Transaction": {
+		"created": {"type": "string", "format": "date-time"},
+	},
+}
+
+// openAPISpec generates the OpenAPI 3 specification of the routes, with the
+// schemas of their request and response bodies derived from the JSON and
+// validate struct tags of the model types
+func openAPISpec() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	schemas["ErrorResponse"] = typeSchema(reflect.TypeOf(ErrorResponse{}), schemas)
+	paths := map[string]interface{}{}
+	for _, rt := range apiRoutes {
+		op := map[string]interface{}{
+			"summary":     rt.Summary,
+			"operationId": operationID(rt),
+		}
+		var params []interface{}
+		for _, p := range rt.Params {
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.Required,
+				"description": p.Description,
+				"schema":      map[string]interface{}{"type": p.Type},
+			})
+		}
+		if rt.Admin {
+			op["security"] = []interface{}{map[string]interface{}{"adminToken": []string{}}}
+		} else {
+			params = append(params, map[string]interface{}{
+				"name":        IdentityHeader,
+				"in":          "header",
+				"description": "wallet identity to invoke the chaincode with, the gateway's default identity if not set",
+				"schema":      map[string]interface{}{"type": "string"},
+			})
+		}
+		op["parameters"] = params
+		if rt.Request != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(typeSchema(reflect.TypeOf(rt.Request), schemas)),
+			}
+		}
+		responses := map[string]interface{}{
+			"default": map[string]interface{}{
+				"description": "Error, with the status matching the chaincode failure code",
+				"content":     jsonContent(typeSchema(reflect.TypeOf(ErrorResponse{}), schemas)),
+			},
+		}
+		for status, body := range rt.Responses {
+			response := map[string]interface{}{"description": http.StatusText(status)}
+			switch body := body.(type) {
+			case nil:
+			case []interface{}:
+				var oneOf []interface{}
+				for _, b := range body {
+					oneOf = append(oneOf, typeSchema(reflect.TypeOf(b), schemas))
+				}
+				response["content"] = jsonContent(map[string]interface{}{"oneOf": oneOf})
+			default:
+				response["content"] = jsonContent(typeSchema(reflect.TypeOf(body), schemas))
+			}
+			responses[strconv.Itoa(status)] = response
+		}
+		op["responses"] = responses
+		path, ok := paths[rt.Path].(map[string]interface{})
+		if !ok {
+			path = map[string]interface{}{}
+			paths[rt.Path] = path
+		}
+		path[strings.ToLower(rt.Method)] = op
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "FinNet Gateway",
+			"version": APIVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"adminToken": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+func operationID(rt *route) string {
+	id := strings.ToLower(rt.Method)
+	for _, segment := range strings.Split(strings.Trim(rt.Path, "/"), "/") {
+		segment = strings.Trim(segment, "{}")
+		id += strings.ToUpper(segment[:1]) + segment[1:]
+	}
+	return id
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// typeSchema returns the schema of a type, adding the schemas of named
+// struct types to the components and referring to them
+func typeSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), schemas)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = nil // placeholder for recursive types
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	addStructFields(t, schemas, properties, &required)
+	for name, schema := range customFields[t.Name()] {
+		properties[name] = schema
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addStructFields adds the JSON fields of a struct, including those of
+// embedded structs, to the properties
+func addStructFields(t reflect.Type, schemas map[string]interface{}, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(embedded, schemas, properties, required)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type, schemas)
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			if rule == "required" {
+				*required = append(*required, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/iShamSLam/chaincode/client"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// IdentityHeader names the wallet identity a request is invoked with
+const IdentityHeader = "X-FinNet-Identity"
+
+// TraceparentHeader carries the W3C trace context of a request and its response
+const TraceparentHeader = "traceparent"
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Error  string              `json:"error"`
+	Code   model.TxFailureCode `json:"code,omitempty"`   // failure code of the chaincode error, if any
+	Fields []*model.FieldError `json:"fields,omitempty"` // invalid fields of a rejected payload
+}
+
+type server struct {
+	clients         clientSource
+	wallet          *gateway.Wallet
+	defaultIdentity string
+	adminToken      string
+}
+
+// param is a path or query parameter of a route
+type param struct {
+	Name        string
+	In          string // "path" or "query"
+	Type        string // "string" or "integer"
+	Required    bool
+	Description string
+}
+
+// route maps a method and path onto a handler. Path segments in braces match
+// any value and are passed to the handler in order. The routes also generate
+// the OpenAPI specification, see openAPISpec.
+type route struct {
+	Method    string
+	Path      string
+	Summary   string
+	Params    []param
+	Request   interface{}         // body of the request, nil if none
+	Responses map[int]interface{} // bodies of the successful responses by status, a slice if one of several
+	Admin     bool                // requires the admin token instead of a wallet identity
+	handle    func(s *server, w http.ResponseWriter, r *http.Request, vars []string)
+}
+
+var customerParam = param{Name: "customer_id", In: "query", Type: "string", Required: true, Description: "customer the account belongs to"}
+
+var accountParam = param{Name: "id", In: "path", Type: "string", Required: true, Description: "account ID"}
+
+var apiRoutes = []*route{
+	{
+		Method:    http.MethodPost,
+		Path:      "/accounts",
+		Summary:   "Open an account",
+		Request:   &model.Account{},
+		Responses: map[int]interface{}{http.StatusCreated: &model.Account{}},
+		handle:    (*server).openAccount,
+	},
+	{
+		Method:    http.MethodGet,
+		Path:      "/accounts/{id}",
+		Summary:   "Get an account",
+		Params:    []param{accountParam, customerParam},
+		Responses: map[int]interface{}{http.StatusOK: &model.Account{}},
+		handle:    (*server).getAccount,
+	},
+	{
+		Method:    http.MethodGet,
+		Path:      "/accounts/{id}/balance",
+		Summary:   "Get the balance and available balance of an account",
+		Params:    []param{accountParam, customerParam},
+		Responses: map[int]interface{}{http.StatusOK: &model.Balance{}},
+		handle:    (*server).getBalance,
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/accounts/{id}/transactions",
+		Summary: "List a page of the transactions of an account",
+		Params: []param{accountParam, customerParam,
+			{Name: "page_size", In: "query", Type: "integer", Description: "transactions per page, at most 500"},
+			{Name: "bookmark", In: "query", Type: "string", Description: "next_bookmark of the previous page"},
+		},
+		Responses: map[int]interface{}{http.StatusOK: &model.TransactionList{}},
+		handle:    (*server).getTransactions,
+	},
+	{
+		Method:  http.MethodPost,
+		Path:    "/transfers",
+		Summary: "Transfer money between accounts",
+		Request: &model.Transfer{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:             &model.TransferResult{},
+			http.StatusAccepted:            []interface{}{&model.TransferApproval{}, &model.OutgoingTransfer{}},
+			http.StatusUnprocessableEntity: &model.TransferResult{},
+		},
+		handle: (*server).transfer,
+	},
+	{
+		Method:  http.MethodGet,
+		Path:    "/metrics",
+		Summary: "Get the handler metrics of the chaincode container of a peer, in the Prometheus text format with format=prometheus",
+		Params: []param{
+			{Name: "format", In: "query", Type: "string", Description: "json (the default) or prometheus"},
+		},
+		Responses: map[int]interface{}{http.StatusOK: &model.Metrics{}},
+		handle:    (*server).getMetrics,
+	},
+	{
+		Method:    http.MethodPost,
+		Path:      "/identities",
+		Summary:   "Import a client identity into the wallet",
+		Request:   &IdentityImport{},
+		Responses: map[int]interface{}{http.StatusNoContent: nil},
+		Admin:     true,
+		handle:    (*server).importIdentity,
+	},
+}
+
+// routes returns the handler serving all routes and the OpenAPI specification
+func (s *server) routes() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/openapi.json" {
+			writeJSON(w, http.StatusOK, openAPISpec())
+			return
+		}
+		// the chaincode invocations of the request join its trace, or a new
+		// one the response names
+		traceparent := r.Header.Get(TraceparentHeader)
+		if !model.IsTraceparent(traceparent) {
+			traceparent = newTraceparent()
+		}
+		w.Header().Set(TraceparentHeader, traceparent)
+		r = r.WithContext(client.WithTraceparent(r.Context(), traceparent))
+		pathMatched := false
+		for _, rt := range apiRoutes {
+			vars, ok := matchPath(rt.Path, r.URL.Path)
+			if !ok {
+				continue
+			}
+			pathMatched = true
+			if rt.Method != r.Method {
+				continue
+			}
+			if rt.Admin && !s.isAdmin(r) {
+				writeError(w, http.StatusUnauthorized, errors.New("Missing or invalid admin token"))
+				return
+			}
+			rt.handle(s, w, r, vars)
+			return
+		}
+		if pathMatched {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("Method %s not allowed", r.Method))
+			return
+		}
+		writeError(w, http.StatusNotFound, fmt.Errorf("No route for %s", r.URL.Path))
+	})
+}
+
+// matchPath returns the values of the braced segments of the pattern if the
+// path matches it
+func matchPath(pattern string, path string) ([]string, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+	var vars []string
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") {
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+			vars = append(vars, pathSegments[i])
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return vars, true
+}
+
+// newTraceparent starts a sampled trace with random trace and parent IDs
+func newTraceparent() string {
+	ids := make([]byte, 24)
+	if _, err := rand.Read(ids); err != nil {
+		log.Printf("Failed to start a trace. Error: %s", err)
+		return ""
+	}
+	return fmt.Sprintf("00-%x-%x-01", ids[:16], ids[16:])
+}
+
+func (s *server) isAdmin(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return s.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1
+}
+
+// client returns the chaincode client of the identity the request names
+func (s *server) client(r *http.Request) (*client.Client, error) {
+	identity := r.Header.Get(IdentityHeader)
+	if identity == "" {
+		identity = s.defaultIdentity
+	}
+	return s.clients.Client(identity)
+}
+
+//------------------------------
+// Route handlers
+//------------------------------
+
+func (s *server) openAccount(w http.ResponseWriter, r *http.Request, vars []string) {
+	account := new(model.Account)
+	if !readJSON(w, r, account) {
+		return
+	}
+	c, err := s.client(r)
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	opened, err := c.OpenAccount(r.Context(), account)
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, opened)
+}
+
+func (s *server) getAccount(w http.ResponseWriter, r *http.Request, vars []string) {
+	customerID, ok := requireQuery(w, r, "customer_id")
+	if !ok {
+		return
+	}
+	c, err := s.client(r)
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	account, err := c.GetAccount(r.Context(), customerID, vars[0])
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, account)
+}
+
+func (s *server) getBalance(w http.ResponseWriter, r *http.Request, vars []string) {
+	customerID, ok := requireQuery(w, r, "customer_id")
+	if !ok {
+		return
+	}
+	c, err := s.client(r)
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	balance, err := c.GetBalance(r.Context(), customerID, vars[0])
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, balance)
+}
+
+func (s *server) getTransactions(w http.ResponseWriter, r *http.Request, vars []string) {
+	customerID, ok := requireQuery(w, r, "customer_id")
+	if !ok {
+		return
+	}
+	page := &client.Page{Bookmark: r.URL.Query().Get("bookmark")}
+	if size := r.URL.Query().Get("page_size"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid page size %s", size))
+			return
+		}
+		page.Size = n
+	}
+	c, err := s.client(r)
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	list, err := c.GetTransactionList(r.Context(), customerID, vars[0], page)
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *server) transfer(w http.ResponseWriter, r *http.Request, vars []string) {
+	t := new(model.Transfer)
+	if !readJSON(w, r, t) {
+		return
+	}
+	c, err := s.client(r)
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	res, err := c.Transfer(r.Context(), t)
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	switch {
+	case res.Approval != nil:
+		writeJSON(w, http.StatusAccepted, res.Approval)
+	case res.Proposal != nil:
+		writeJSON(w, http.StatusAccepted, res.Proposal)
+	case res.Result.Status == model.TransferFailed:
+		// committed as failed, e.g. stopped by sanctions screening
+		writeJSON(w, http.StatusUnprocessableEntity, res.Result)
+	default:
+		writeJSON(w, http.StatusCreated, res.Result)
+	}
+}
+
+func (s *server) getMetrics(w http.ResponseWriter, r *http.Request, vars []string) {
+	c, err := s.client(r)
+	if err != nil {
+		writeError(w, httpStatus(err), err)
+		return
+	}
+	switch format := r.URL.Query().Get("format"); format {
+	case "prometheus":
+		text, err := c.GetPrometheusMetrics(r.Context())
+		if err != nil {
+			writeError(w, httpStatus(err), err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if _, err := w.Write(text); err != nil {
+			log.Printf("Failed to write response. Error: %s", err)
+		}
+	case "", "json":
+		metrics, err := c.GetMetrics(r.Context())
+		if err != nil {
+			writeError(w, httpStatus(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, metrics)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid metrics format %s", format))
+	}
+}
+
+func (s *server) importIdentity(w http.ResponseWriter, r *http.Request, vars []string) {
+	id := new(IdentityImport)
+	if !readJSON(w, r, id) {
+		return
+	}
+	if err := importIdentity(s.wallet, id); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.clients.Forget(id.Label)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+//------------------------------
+// Request and response helpers
+//------------------------------
+
+// httpStatus returns the HTTP status answering a failed invocation
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, client.ErrUnknownIdentity):
+		return http.StatusUnauthorized
+	case errors.Is(err, client.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	e, ok := err.(*client.Error)
+	if !ok || !e.Rejected {
+		return http.StatusBadGateway
+	}
+	switch e.Code {
+	case model.ConcurrentlyModified:
+		return http.StatusConflict
+	case model.InvalidAmount:
+		return http.StatusBadRequest
+	case model.TxFailureCodeNone:
+	default:
+		return http.StatusUnprocessableEntity
+	}
+	switch {
+	case len(e.Fields) > 0:
+		return http.StatusBadRequest
+	case strings.Contains(e.Message, "not authorized"):
+		return http.StatusForbidden
+	case strings.Contains(e.Message, "not found"):
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+func requireQuery(w http.ResponseWriter, r *http.Request, name string) (string, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Missing required query parameter %s", name))
+		return "", false
+	}
+	return value, true
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Error parsing request body. Error: %s", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response. Error: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	res := &ErrorResponse{Error: err.Error()}
+	if e, ok := err.(*client.Error); ok {
+		res.Error, res.Code, res.Fields = e.Message, e.Code, e.Fields
+	}
+	writeJSON(w, status, res)
+}
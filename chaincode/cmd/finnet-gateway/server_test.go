@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/client"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// fakeContract answers every transaction with a fixed response or error
+type fakeContract struct {
+	res  []byte
+	err  error
+	args []string
+}
+
+func (f *fakeContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	f.args = append([]string{name}, args...)
+	return f.res, f.err
+}
+
+func (f *fakeContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	return f.SubmitTransaction(name, args...)
+}
+
+func (f *fakeContract) RegisterEvent(eventFilter string) (fab.Registration, <-chan *fab.CCEvent, error) {
+	return nil, nil, errors.New("not supported")
+}
+
+func (f *fakeContract) Unregister(registration fab.Registration) {}
+
+// fakeClients invokes the contract for the "appUser" identity
+type fakeClients struct {
+	contract *fakeContract
+}
+
+func (f *fakeClients) Client(identity string) (*client.Client, error) {
+	if identity != "appUser" {
+		return nil, client.ErrUnknownIdentity
+	}
+	return client.New(f.contract), nil
+}
+
+func (f *fakeClients) Forget(identity string) {}
+
+func TestRoutesTranslateChaincodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		identity string
+		res      string
+		err      string
+		status   int
+		code     string
+	}{
+		{"transfer completed", "POST", "/transfers", `{"from_customer":"1234","from_account":"1","amount":1000}`, "appUser", `{"status":"completed","tx_id":"tx1"}`, "", http.StatusCreated, ""},
+		{"transfer sanctioned", "POST", "/transfers", `{"amount":1000}`, "appUser", `{"status":"failed","failure_code":"sanctions_hit"}`, "", http.StatusUnprocessableEntity, ""},
+		{"transfer held", "POST", "/transfers", `{"amount":1000}`, "appUser", `{"id":"a1","required_approvals":2}`, "", http.StatusAccepted, ""},
+		{"stale version", "POST", "/transfers", `{"amount":1000}`, "appUser", "", "Description: concurrent_modification: Account 1 is at version 8, expected 7", http.StatusConflict, "concurrent_modification"},
+		{"invalid fields", "POST", "/accounts", `{}`, "appUser", "", `Description: Error creating new account. Error: Invalid fields [{"field":"currency","rule":"required","message":"is required"}]`, http.StatusBadRequest, ""},
+		{"not authorized", "GET", "/accounts/1?customer_id=1234", "", "appUser", "", "Description: Caller is not authorized to transfer for customer 1234", http.StatusForbidden, ""},
+		{"account not found", "GET", "/accounts/1?customer_id=1234", "", "appUser", "", "", http.StatusNotFound, ""},
+		{"peer unreachable", "GET", "/accounts/1/balance?customer_id=1234", "", "appUser", "", "connection refused", http.StatusBadGateway, ""},
+		{"unknown identity", "GET", "/accounts/1?customer_id=1234", "", "nobody", "", "", http.StatusUnauthorized, ""},
+		{"missing customer", "GET", "/accounts/1/transactions", "", "appUser", "", "", http.StatusBadRequest, ""},
+		{"no route", "GET", "/customers", "", "appUser", "", "", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contract := &fakeContract{res: []byte(tt.res)}
+			if tt.err != "" {
+				contract.err = errors.New(tt.err)
+			}
+			srv := &server{clients: &fakeClients{contract}}
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(IdentityHeader, tt.identity)
+			rec := httptest.NewRecorder()
+			srv.routes().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+			if tt.code != "" {
+				res := new(ErrorResponse)
+				if err := json.Unmarshal(rec.Body.Bytes(), res); err != nil || string(res.Code) != tt.code {
+					t.Errorf("expected code %s, got %s", tt.code, rec.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestTransactionsRoutePassesPage(t *testing.T) {
+	contract := &fakeContract{res: []byte(`{"transactions":[]}`)}
+	srv := &server{clients: &fakeClients{contract}, defaultIdentity: "appUser"}
+	req := httptest.NewRequest("GET", "/accounts/1/transactions?customer_id=1234&page_size=50&bookmark=b1", nil)
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got := strings.Join(contract.args, " "); !strings.HasPrefix(got, "GetTransactionList 1234 1 50 b1 00-") {
+		t.Errorf("unexpected invocation %s", got)
+	}
+}
+
+func TestMetricsRouteServesPrometheusText(t *testing.T) {
+	text := "finnet_handler_invocations_total{container=\"peer0\",function=\"TransferMoney\"} 3\n"
+	contract := &fakeContract{res: []byte(text)}
+	srv := &server{clients: &fakeClients{contract}, defaultIdentity: "appUser"}
+	req := httptest.NewRequest("GET", "/metrics?format=prometheus", nil)
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != text || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("expected the metrics text, got %d %s: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	if got := strings.Join(contract.args, " "); !strings.HasPrefix(got, "GetMetrics prometheus 00-") {
+		t.Errorf("unexpected invocation %s", got)
+	}
+}
+
+func TestRoutesPropagateTraceparent(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	contract := &fakeContract{res: []byte(`{"id":"1"}`)}
+	srv := &server{clients: &fakeClients{contract}, defaultIdentity: "appUser"}
+	req := httptest.NewRequest("GET", "/accounts/1?customer_id=1234", nil)
+	req.Header.Set(TraceparentHeader, traceparent)
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, req)
+	if got := strings.Join(contract.args, " "); got != "GetAccount 1234 1 "+traceparent {
+		t.Errorf("unexpected invocation %s", got)
+	}
+	if got := rec.Header().Get(TraceparentHeader); got != traceparent {
+		t.Errorf("expected the traceparent returned, got %q", got)
+	}
+
+	req = httptest.NewRequest("GET", "/accounts/1?customer_id=1234", nil)
+	rec = httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, req)
+	started := rec.Header().Get(TraceparentHeader)
+	if started == "" || started == traceparent || contract.args[len(contract.args)-1] != started {
+		t.Errorf("expected a new trace passed to the chaincode, got %q and %q", started, contract.args)
+	}
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	spec, err := json.Marshal(openAPISpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"/accounts/{id}/transactions"`, `"#/components/schemas/TransferResult"`, `"from_customer"`, `"next_bookmark"`} {
+		if !strings.Contains(string(spec), want) {
+			t.Errorf("expected %s in the specification", want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	finnetpb "github.com/iShamSLam/chaincode/api/finnet/v1"
+	"github.com/iShamSLam/chaincode/client"
+	"github.com/iShamSLam/chaincode/model"
+)
+
+//------------------------------
+// Conversions between model types and protobuf messages
+//------------------------------
+
+func accountFromProto(a *finnetpb.Account) *model.Account {
+	return &model.Account{
+		ID:            a.GetId(),
+		CustomerID:    a.GetCustomerId(),
+		BankName:      a.GetBankName(),
+		AccountHolder: a.GetAccountHolder(),
+		ProductID:     a.GetProductId(),
+		Type:          a.GetAccountType(),
+		Description:   a.GetDescription(),
+		CountryCode:   a.GetCountry(),
+		CurrencyCode:  a.GetCurrency(),
+		Balance:       a.GetBalance(),
+		Default:       a.GetDefaultAccount(),
+		Signers:       a.GetSigners(),
+		Quorum:        int(a.GetRequiredSignatures()),
+		Params:        a.GetParams(),
+	}
+}
+
+func accountToProto(a *model.Account) *finnetpb.Account {
+	return &finnetpb.Account{
+		Id:                 a.ID,
+		CustomerId:         a.CustomerID,
+		BankName:           a.BankName,
+		AccountHolder:      a.AccountHolder,
+		ProductId:          a.ProductID,
+		AccountType:        a.Type,
+		Description:        a.Description,
+		Country:            a.CountryCode,
+		Currency:           a.CurrencyCode,
+		Created:            a.Created,
+		Updated:            a.Updated,
+		TxId:               a.TxID,
+		Version:            a.Version,
+		Balance:            a.Balance,
+		OverdraftLimit:     a.Overdraft,
+		Held:               a.Held,
+		DefaultAccount:     a.Default,
+		Status:             string(a.Status),
+		StatusReason:       a.StatusReason,
+		Signers:            a.Signers,
+		RequiredSignatures: int32(a.Quorum),
+		Params:             a.Params,
+	}
+}
+
+func balanceToProto(b *model.Balance) *finnetpb.Balance {
+	return &finnetpb.Balance{
+		Balance:   b.Balance,
+		Available: b.Available,
+		Currency:  b.CurrencyCode,
+		AsOfTx:    b.AsOfTx,
+	}
+}
+
+func transferFromProto(t *finnetpb.Transfer) *model.Transfer {
+	return &model.Transfer{
+		FromCustomerID: t.GetFromCustomer(),
+		FromAccountID:  t.GetFromAccount(),
+		ToCustomerID:   t.GetToCustomer(),
+		ToAccountID:    t.GetToAccount(),
+		ToTenant:       t.GetToTenant(),
+		Amount:         t.GetAmount(),
+		CurrencyCode:   t.GetCurrency(),
+		Description:    t.GetDescription(),
+		PurposeCode:    t.GetPurposeCode(),
+		InvoiceRef:     t.GetInvoiceRef(),
+		EndToEndID:     t.GetEndToEndId(),
+		Memo:           t.GetMemo(),
+		Category:       t.GetCategory(),
+		Params:         t.GetParams(),
+		PayerVersion:   t.GetExpectedVersion(),
+		QuoteID:        t.GetQuoteId(),
+	}
+}
+
+func transferToProto(t *model.Transfer) *finnetpb.Transfer {
+	if t == nil {
+		return nil
+	}
+	return &finnetpb.Transfer{
+		FromCustomer:    t.FromCustomerID,
+		FromAccount:     t.FromAccountID,
+		ToCustomer:      t.ToCustomerID,
+		ToAccount:       t.ToAccountID,
+		ToTenant:        t.ToTenant,
+		Amount:          t.Amount,
+		Fee:             t.Fee,
+		Currency:        t.CurrencyCode,
+		Description:     t.Description,
+		PurposeCode:     t.PurposeCode,
+		InvoiceRef:      t.InvoiceRef,
+		EndToEndId:      t.EndToEndID,
+		Memo:            t.Memo,
+		Category:        t.Category,
+		Params:          t.Params,
+		ExpectedVersion: t.PayerVersion,
+		QuoteId:         t.QuoteID,
+	}
+}
+
+func transferResultToProto(r *model.TransferResult) *finnetpb.TransferResult {
+	return &finnetpb.TransferResult{
+		Transfer: &finnetpb.Transfer{
+			FromCustomer: r.FromCustomerID,
+			FromAccount:  r.FromAccountID,
+			ToCustomer:   r.ToCustomerID,
+			ToAccount:    r.ToAccountID,
+			Amount:       r.Amount,
+			Fee:          r.Fee,
+			Currency:     r.CurrencyCode,
+			Description:  r.Description,
+			Params:       r.Params,
+		},
+		Status:              string(r.Status),
+		TxId:                r.TxID,
+		DebitTransactionId:  r.DebitTransactionID,
+		CreditTransactionId: r.CreditTransactionID,
+		ValueDate:           r.ValueDate,
+		Balance:             r.Balance,
+		Available:           r.Available,
+		Error:               r.Error,
+		FailureCode:         string(r.FailureCode),
+	}
+}
+
+func transferResponseToProto(r *client.TransferResponse) *finnetpb.TransferResponse {
+	switch {
+	case r.Approval != nil:
+		return &finnetpb.TransferResponse{Outcome: &finnetpb.TransferResponse_Pending{Pending: &finnetpb.PendingTransfer{
+			Kind:     finnetpb.PendingTransfer_APPROVAL,
+			Id:       r.Approval.ID,
+			Transfer: transferToProto(r.Approval.Transfer),
+			Required: int32(r.Approval.RequiredApprovals),
+			Received: int32(len(r.Approval.Approvals)),
+			Status:   string(r.Approval.Status),
+		}}}
+	case r.Proposal != nil:
+		return &finnetpb.TransferResponse{Outcome: &finnetpb.TransferResponse_Pending{Pending: &finnetpb.PendingTransfer{
+			Kind:     finnetpb.PendingTransfer_SIGNATURES,
+			Id:       r.Proposal.ID,
+			Transfer: transferToProto(r.Proposal.Transfer),
+			Required: int32(r.Proposal.Quorum),
+			Received: int32(len(r.Proposal.Approvals)),
+			Status:   string(r.Proposal.Status),
+		}}}
+	}
+	return &finnetpb.TransferResponse{Outcome: &finnetpb.TransferResponse_Result{Result: transferResultToProto(r.Result)}}
+}
+
+func transactionToProto(t *model.Transaction) *finnetpb.Transaction {
+	return &finnetpb.Transaction{
+		Id:                   t.ID,
+		CustomerId:           t.CustomerID,
+		AccountId:            t.AccountID,
+		Amount:               t.Amount,
+		Fee:                  t.Fee,
+		Currency:             t.CurrencyCode,
+		Created:              t.Created,
+		TxId:                 t.TxID,
+		Description:          t.Description,
+		PurposeCode:          t.PurposeCode,
+		InvoiceRef:           t.InvoiceRef,
+		EndToEndId:           t.EndToEndID,
+		Memo:                 t.Memo,
+		Category:             t.Category,
+		Params:               t.Params,
+		CounterpartyCustomer: t.CounterpartyCustomerID,
+		CounterpartyAccount:  t.CounterpartyAccountID,
+		Overdraft:            t.Overdraft,
+		Type:                 string(t.Type),
+		ValueDate:            t.ValueDate,
+		Status:               string(t.Status),
+		FailureCode:          string(t.FailureCode),
+	}
+}
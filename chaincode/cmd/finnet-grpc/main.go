@@ -0,0 +1,54 @@
+// Command finnet-grpc serves the FinNetService gRPC API defined in
+// api/finnet/v1/finnet.proto in front of the FinNet chaincode. Each call is
+// invoked with a client identity of a wallet, chosen by the x-finnet-identity
+// metadata, and chaincode errors are answered with the status code matching
+// their failure code.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	finnetpb "github.com/iShamSLam/chaincode/api/finnet/v1"
+	"github.com/iShamSLam/chaincode/client"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	profile := flag.String("connection-profile", "connection.yaml", "Fabric SDK connection profile")
+	walletPath := flag.String("wallet", "wallet", "directory of the identity wallet")
+	identity := flag.String("identity", "", "wallet identity of calls without x-finnet-identity metadata")
+	channel := flag.String("channel", "mychannel", "channel the chaincode is deployed on")
+	chaincode := flag.String("chaincode", "mycc", "name of the chaincode")
+	certFile := flag.String("tls-cert", "", "TLS certificate of the server, plaintext if not set")
+	keyFile := flag.String("tls-key", "", "TLS private key of the server")
+	flag.Parse()
+
+	var opts []grpc.ServerOption
+	if *certFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(*certFile, *keyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	wallet, err := client.OpenWallet(*walletPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	clients := client.NewPool(wallet, *profile, *channel, *chaincode)
+	defer clients.Close()
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := grpc.NewServer(opts...)
+	finnetpb.RegisterFinNetServiceServer(srv, &server{clients: clients, defaultIdentity: *identity})
+	log.Printf("finnet-grpc listening on %s for chaincode %s on channel %s", *addr, *chaincode, *channel)
+	log.Fatal(srv.Serve(lis))
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	finnetpb "github.com/iShamSLam/chaincode/api/finnet/v1"
+	"github.com/iShamSLam/chaincode/client"
+	"github.com/iShamSLam/chaincode/model"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// IdentityMetadata is the metadata key naming the wallet identity a call is
+// invoked with
+const IdentityMetadata = "x-finnet-identity"
+
+// clientSource returns the chaincode client invoking with a wallet identity,
+// see client.Pool
+type clientSource interface {
+	Client(identity string) (*client.Client, error)
+}
+
+// server implements FinNetService by invoking the chaincode through the
+// client package
+type server struct {
+	finnetpb.UnimplementedFinNetServiceServer
+	clients         clientSource
+	defaultIdentity string
+}
+
+// client returns the chaincode client of the identity the call names
+func (s *server) client(ctx context.Context) (*client.Client, error) {
+	identity := s.defaultIdentity
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(IdentityMetadata); len(values) > 0 && values[0] != "" {
+			identity = values[0]
+		}
+	}
+	c, err := s.clients.Client(identity)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return c, nil
+}
+
+// OpenAccount opens an account and returns it as stored on the ledger
+func (s *server) OpenAccount(ctx context.Context, req *finnetpb.OpenAccountRequest) (*finnetpb.Account, error) {
+	if req.GetAccount() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Missing required account")
+	}
+	c, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account, err := c.OpenAccount(ctx, accountFromProto(req.GetAccount()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return accountToProto(account), nil
+}
+
+// GetAccount returns an account
+func (s *server) GetAccount(ctx context.Context, req *finnetpb.AccountRef) (*finnetpb.Account, error) {
+	c, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account, err := c.GetAccount(ctx, req.GetCustomerId(), req.GetAccountId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return accountToProto(account), nil
+}
+
+// CloseAccount closes an account
+func (s *server) CloseAccount(ctx context.Context, req *finnetpb.CloseAccountRequest) (*finnetpb.Account, error) {
+	c, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ref, sweepTo := req.GetAccount(), req.GetSweepTo()
+	account, err := c.CloseAccount(ctx, ref.GetCustomerId(), ref.GetAccountId(), sweepTo.GetCustomerId(), sweepTo.GetAccountId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return accountToProto(account), nil
+}
+
+// TopupAccount credits an account
+func (s *server) TopupAccount(ctx context.Context, req *finnetpb.TopupAccountRequest) (*finnetpb.Account, error) {
+	c, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ref := req.GetAccount()
+	account, err := c.TopupAccount(ctx, ref.GetCustomerId(), ref.GetAccountId(), req.GetAmount())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return accountToProto(account), nil
+}
+
+// GetBalance returns the balance and available balance of an account
+func (s *server) GetBalance(ctx context.Context, req *finnetpb.AccountRef) (*finnetpb.Balance, error) {
+	c, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := c.GetBalance(ctx, req.GetCustomerId(), req.GetAccountId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return balanceToProto(balance), nil
+}
+
+// Transfer submits a transfer. A transfer committed as failed, e.g. stopped
+// by sanctions screening, is returned as a result with the failed status.
+func (s *server) Transfer(ctx context.Context, req *finnetpb.TransferRequest) (*finnetpb.TransferResponse, error) {
+	if req.GetTransfer() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Missing required transfer")
+	}
+	c, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Transfer(ctx, transferFromProto(req.GetTransfer()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return transferResponseToProto(res), nil
+}
+
+// ListTransactions returns a page of the transactions of an account
+func (s *server) ListTransactions(ctx context.Context, req *finnetpb.ListTransactionsRequest) (*finnetpb.ListTransactionsResponse, error) {
+	if req.GetPageSize() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid page size %d", req.GetPageSize())
+	}
+	c, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ref := req.GetAccount()
+	list, err := c.GetTransactionList(ctx, ref.GetCustomerId(), ref.GetAccountId(), &client.Page{Size: int(req.GetPageSize()), Bookmark: req.GetBookmark()})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	res := &finnetpb.ListTransactionsResponse{NextBookmark: list.NextBookmark}
+	for _, txn := range list.Transactions {
+		res.Transactions = append(res.Transactions, transactionToProto(txn))
+	}
+	return res, nil
+}
+
+// StreamEvents streams the chaincode events of the requested types, one
+// message per event of an envelope, until the call is cancelled
+func (s *server) StreamEvents(req *finnetpb.StreamEventsRequest, stream finnetpb.FinNetService_StreamEventsServer) error {
+	c, err := s.client(stream.Context())
+	if err != nil {
+		return err
+	}
+	types := make(map[string]bool)
+	for _, t := range req.GetTypes() {
+		types[t] = true
+	}
+	events, err := c.Events(stream.Context(), client.AllEvents)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	for blockEvent := range events {
+		envelope := blockEvent.Envelope
+		for _, event := range envelope.Events {
+			if len(types) > 0 && !types[event.Type] {
+				continue
+			}
+			if err := stream.Send(&finnetpb.Event{
+				Type:        event.Type,
+				TxId:        envelope.TxID,
+				Function:    envelope.Function,
+				Timestamp:   envelope.Timestamp,
+				BlockNumber: blockEvent.BlockNumber,
+				Data:        event.Data,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return stream.Context().Err()
+}
+
+// grpcError returns the status answering a failed invocation. Chaincode
+// failure codes are carried as the status message prefix, as in the chaincode
+// error.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, client.ErrUnknownIdentity):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, client.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	e, ok := err.(*client.Error)
+	if !ok || !e.Rejected {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(statusCode(e), e.Message)
+}
+
+func statusCode(e *client.Error) codes.Code {
+	switch e.Code {
+	case model.ConcurrentlyModified:
+		return codes.Aborted
+	case model.InvalidAmount:
+		return codes.InvalidArgument
+	case model.TxFailureCodeNone:
+	default:
+		return codes.FailedPrecondition
+	}
+	switch {
+	case len(e.Fields) > 0:
+		return codes.InvalidArgument
+	case strings.Contains(e.Message, "not authorized"):
+		return codes.PermissionDenied
+	case strings.Contains(e.Message, "not found"):
+		return codes.NotFound
+	}
+	return codes.FailedPrecondition
+}
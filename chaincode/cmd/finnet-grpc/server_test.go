@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	finnetpb "github.com/iShamSLam/chaincode/api/finnet/v1"
+	"github.com/iShamSLam/chaincode/client"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeContract answers every transaction with a fixed response or error
+type fakeContract struct {
+	res []byte
+	err error
+}
+
+func (f *fakeContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	return f.res, f.err
+}
+
+func (f *fakeContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	return f.res, f.err
+}
+
+func (f *fakeContract) RegisterEvent(eventFilter string) (fab.Registration, <-chan *fab.CCEvent, error) {
+	return nil, nil, errors.New("not supported")
+}
+
+func (f *fakeContract) Unregister(registration fab.Registration) {}
+
+// fakeClients invokes the contract for the "appUser" identity
+type fakeClients struct {
+	contract *fakeContract
+}
+
+func (f *fakeClients) Client(identity string) (*client.Client, error) {
+	if identity != "appUser" {
+		return nil, client.ErrUnknownIdentity
+	}
+	return client.New(f.contract), nil
+}
+
+func TestTransferStatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity string
+		err      string
+		code     codes.Code
+	}{
+		{"stale version", "appUser", "Description: concurrent_modification: Account 1 is at version 8, expected 7", codes.Aborted},
+		{"invalid amount", "appUser", "Description: invalid_amount: amount -500 must be positive", codes.InvalidArgument},
+		{"not authorized", "appUser", "Description: Caller is not authorized to transfer for customer 1234", codes.PermissionDenied},
+		{"business rule", "appUser", "Description: Cannot close account 1 with active holds", codes.FailedPrecondition},
+		{"peer unreachable", "appUser", "connection refused", codes.Unavailable},
+		{"unknown identity", "nobody", "", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contract := &fakeContract{}
+			if tt.err != "" {
+				contract.err = errors.New(tt.err)
+			}
+			srv := &server{clients: &fakeClients{contract}}
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdentityMetadata, tt.identity))
+			_, err := srv.Transfer(ctx, &finnetpb.TransferRequest{Transfer: &finnetpb.Transfer{FromCustomer: "1234", FromAccount: "1", Amount: 1000}})
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("expected code %v, got %v (%v)", tt.code, got, err)
+			}
+		})
+	}
+}
+
+func TestTransferResponseOutcome(t *testing.T) {
+	contract := &fakeContract{res: []byte(`{"id":"a1","transfer":{"from_account":"1"},"required_approvals":2,"approvals":[],"status":"pending_approval"}`)}
+	srv := &server{clients: &fakeClients{contract}, defaultIdentity: "appUser"}
+	res, err := srv.Transfer(context.Background(), &finnetpb.TransferRequest{Transfer: &finnetpb.Transfer{FromAccount: "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, ok := res.GetOutcome().(*finnetpb.TransferResponse_Pending)
+	if !ok || pending.Pending.GetKind() != finnetpb.PendingTransfer_APPROVAL || pending.Pending.GetRequired() != 2 {
+		t.Errorf("unexpected response %+v", res)
+	}
+}
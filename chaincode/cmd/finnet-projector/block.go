@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// write is a public state write of the chaincode committed in a block
+type write struct {
+	TxID     string
+	Key      string
+	Value    []byte
+	IsDelete bool
+}
+
+// blockWrites returns the state writes of the chaincode made by the valid
+// transactions of a block, in commit order
+func blockWrites(block *common.Block, chaincode string) ([]*write, error) {
+	var filter []byte
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		filter = block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+	var writes []*write
+	for i, data := range block.Data.Data {
+		if i < len(filter) && filter[i] != byte(pb.TxValidationCode_VALID) {
+			continue
+		}
+		txWrites, err := transactionWrites(data, chaincode)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing transaction %d of block %d. Error: %s", i, block.Header.Number, err)
+		}
+		writes = append(writes, txWrites...)
+	}
+	return writes, nil
+}
+
+func transactionWrites(data []byte, chaincode string) ([]*write, error) {
+	env := &common.Envelope{}
+	if err := proto.Unmarshal(data, env); err != nil {
+		return nil, err
+	}
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(env.Payload, payload); err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, nil
+	}
+	chdr := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.Header.ChannelHeader, chdr); err != nil {
+		return nil, err
+	}
+	if chdr.Type != int32(common.HeaderType_ENDORSER_TRANSACTION) {
+		return nil, nil
+	}
+	tx := &pb.Transaction{}
+	if err := proto.Unmarshal(payload.Data, tx); err != nil {
+		return nil, err
+	}
+	var writes []*write
+	for _, action := range tx.Actions {
+		actionPayload := &pb.ChaincodeActionPayload{}
+		if err := proto.Unmarshal(action.Payload, actionPayload); err != nil {
+			return nil, err
+		}
+		if actionPayload.Action == nil {
+			continue
+		}
+		prp := &pb.ProposalResponsePayload{}
+		if err := proto.Unmarshal(actionPayload.Action.ProposalResponsePayload, prp); err != nil {
+			return nil, err
+		}
+		ca := &pb.ChaincodeAction{}
+		if err := proto.Unmarshal(prp.Extension, ca); err != nil {
+			return nil, err
+		}
+		txRWSet := &rwset.TxReadWriteSet{}
+		if err := proto.Unmarshal(ca.Results, txRWSet); err != nil {
+			return nil, err
+		}
+		for _, ns := range txRWSet.NsRwset {
+			if ns.Namespace != chaincode {
+				continue
+			}
+			kv := &kvrwset.KVRWSet{}
+			if err := proto.Unmarshal(ns.Rwset, kv); err != nil {
+				return nil, err
+			}
+			for _, w := range kv.Writes {
+				writes = append(writes, &write{TxID: chdr.TxId, Key: w.Key, Value: w.Value, IsDelete: w.IsDelete})
+			}
+		}
+	}
+	return writes, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+func mustMarshal(t *testing.T, m proto.Message) []byte {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// endorserTransaction builds the envelope of a transaction writing the keys
+// of a namespace
+func endorserTransaction(t *testing.T, txID string, namespace string, writes ...*kvrwset.KVWrite) []byte {
+	results := mustMarshal(t, &rwset.TxReadWriteSet{NsRwset: []*rwset.NsReadWriteSet{
+		{Namespace: namespace, Rwset: mustMarshal(t, &kvrwset.KVRWSet{Writes: writes})},
+	}})
+	prp := mustMarshal(t, &pb.ProposalResponsePayload{Extension: mustMarshal(t, &pb.ChaincodeAction{Results: results})})
+	actionPayload := mustMarshal(t, &pb.ChaincodeActionPayload{Action: &pb.ChaincodeEndorsedAction{ProposalResponsePayload: prp}})
+	tx := mustMarshal(t, &pb.Transaction{Actions: []*pb.TransactionAction{{Payload: actionPayload}}})
+	chdr := mustMarshal(t, &common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION), TxId: txID})
+	payload := mustMarshal(t, &common.Payload{Header: &common.Header{ChannelHeader: chdr}, Data: tx})
+	return mustMarshal(t, &common.Envelope{Payload: payload})
+}
+
+func TestBlockWrites(t *testing.T) {
+	account := []byte(`{"docType":"Account","id":"1","customer_id":"1234","currency":"AUD","balance":5000,"created":"2026-10-01T09:00:00Z","version":3,"status":"active"}`)
+	block := &common.Block{
+		Header: &common.BlockHeader{Number: 12},
+		Data: &common.BlockData{Data: [][]byte{
+			endorserTransaction(t, "t1", "mycc", &kvrwset.KVWrite{Key: "\x00Account\x001234\x001\x00", Value: account}),
+			endorserTransaction(t, "t2", "mycc", &kvrwset.KVWrite{Key: "\x00Account\x001234\x002\x00", Value: account}),
+			endorserTransaction(t, "t3", "othercc", &kvrwset.KVWrite{Key: "a", Value: []byte("1")}),
+			endorserTransaction(t, "t4", "mycc", &kvrwset.KVWrite{Key: "\x00Transaction\x001234\x001\x00a\x00", IsDelete: true}),
+		}},
+		Metadata: &common.BlockMetadata{Metadata: make([][]byte, len(common.BlockMetadataIndex_name))},
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{
+		byte(pb.TxValidationCode_VALID),
+		byte(pb.TxValidationCode_MVCC_READ_CONFLICT),
+		byte(pb.TxValidationCode_VALID),
+		byte(pb.TxValidationCode_VALID),
+	}
+
+	writes, err := blockWrites(block, "mycc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(writes) != 2 || writes[0].TxID != "t1" || writes[1].TxID != "t4" {
+		t.Fatalf("expected the writes of t1 and t4, got %+v", writes)
+	}
+
+	stmts, err := project(writes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 1 || stmts[0].query != upsertAccount {
+		t.Fatalf("expected an account upsert, got %+v", stmts)
+	}
+	if balance := stmts[0].args[7]; balance != int64(5000) {
+		t.Errorf("expected balance 5000, got %v", balance)
+	}
+	stmts, err = project(writes[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 {
+		t.Errorf("expected the deletes of the key, got %+v", stmts)
+	}
+}
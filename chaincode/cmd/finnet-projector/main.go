@@ -0,0 +1,92 @@
+// Command finnet-projector maintains a PostgreSQL mirror of the FinNet
+// accounts and transactions for analytical queries and BI dashboards that are
+// impractical against the chaincode state. It reads the blocks of the channel
+// and applies the chaincode's state writes of each valid transaction to the
+// accounts and transactions tables, recording the last applied block in the
+// same database transaction so a restart resumes after it.
+//
+// The mirror holds the public state only: fields kept in private data
+// collections, such as account holder names and remittance details, are
+// absent from the doc column.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	profile := flag.String("connection-profile", "connection.yaml", "Fabric SDK connection profile")
+	org := flag.String("org", "Org1", "organization of the user in the connection profile")
+	user := flag.String("user", "User1", "user of the connection profile allowed to read the channel's blocks")
+	channel := flag.String("channel", "mychannel", "channel the chaincode is deployed on")
+	chaincode := flag.String("chaincode", "mycc", "name of the chaincode")
+	dsn := flag.String("db", os.Getenv("FINNET_PROJECTOR_DB"), "PostgreSQL connection string")
+	flag.Parse()
+
+	db, err := sql.Open("postgres", *dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	s := &store{db: db}
+	if err := s.migrate(); err != nil {
+		log.Fatal(err)
+	}
+	var from uint64
+	last, ok, err := s.lastBlock()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if ok {
+		from = last + 1
+	}
+
+	sdk, err := fabsdk.New(config.FromFile(*profile))
+	if err != nil {
+		log.Fatalf("Failed to create SDK. Error: %s", err)
+	}
+	defer sdk.Close()
+	events, err := event.New(sdk.ChannelContext(*channel, fabsdk.WithUser(*user), fabsdk.WithOrg(*org)),
+		event.WithBlockEvents(), event.WithSeekType(seek.FromBlock), event.WithBlockNum(from))
+	if err != nil {
+		log.Fatalf("Failed to create event client. Error: %s", err)
+	}
+	registration, blocks, err := events.RegisterBlockEvent()
+	if err != nil {
+		log.Fatalf("Failed to register for block events. Error: %s", err)
+	}
+	defer events.Unregister(registration)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	log.Printf("finnet-projector mirroring chaincode %s on channel %s from block %d", *chaincode, *channel, from)
+	for {
+		select {
+		case <-stop:
+			return
+		case e, ok := <-blocks:
+			if !ok {
+				log.Fatal("Block event stream closed")
+			}
+			number := e.Block.Header.Number
+			writes, err := blockWrites(e.Block, *chaincode)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := s.apply(number, writes); err != nil {
+				log.Fatalf("Failed to apply block %d. Error: %s", number, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+)
+
+// schema creates the mirror tables. Rows are keyed by the ledger key of the
+// record they mirror; doc holds the record as written to the public state.
+const schema = `
+CREATE TABLE IF NOT EXISTS finnet_checkpoint (
+	id INTEGER PRIMARY KEY,
+	block BIGINT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS accounts (
+	ledger_key TEXT PRIMARY KEY,
+	customer_id TEXT NOT NULL,
+	account_id TEXT NOT NULL,
+	bank_name TEXT NOT NULL,
+	product_id TEXT NOT NULL,
+	country TEXT NOT NULL,
+	currency TEXT NOT NULL,
+	balance BIGINT NOT NULL,
+	held BIGINT NOT NULL,
+	overdraft_limit BIGINT NOT NULL,
+	status TEXT NOT NULL,
+	version BIGINT NOT NULL,
+	created TIMESTAMPTZ NOT NULL,
+	updated TIMESTAMPTZ,
+	tx_id TEXT NOT NULL,
+	doc JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS accounts_customer ON accounts (customer_id, account_id);
+CREATE TABLE IF NOT EXISTS transactions (
+	ledger_key TEXT PRIMARY KEY,
+	transaction_id TEXT NOT NULL,
+	customer_id TEXT NOT NULL,
+	account_id TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	fee BIGINT NOT NULL,
+	currency TEXT NOT NULL,
+	status TEXT NOT NULL,
+	failure_code TEXT NOT NULL,
+	type TEXT NOT NULL,
+	counterparty_customer TEXT NOT NULL,
+	counterparty_account TEXT NOT NULL,
+	end_to_end_id TEXT NOT NULL,
+	created TIMESTAMPTZ NOT NULL,
+	tx_id TEXT NOT NULL,
+	doc JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transactions_customer ON transactions (customer_id, account_id, created);
+CREATE INDEX IF NOT EXISTS transactions_created ON transactions (created);
+CREATE INDEX IF NOT EXISTS transactions_amount ON transactions (currency, amount);
+`
+
+const upsertAccount = `
+INSERT INTO accounts (ledger_key, customer_id, account_id, bank_name, product_id, country, currency,
+	balance, held, overdraft_limit, status, version, created, updated, tx_id, doc)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+ON CONFLICT (ledger_key) DO UPDATE SET customer_id = $2, account_id = $3, bank_name = $4, product_id = $5,
+	country = $6, currency = $7, balance = $8, held = $9, overdraft_limit = $10, status = $11, version = $12,
+	created = $13, updated = $14, tx_id = $15, doc = $16`
+
+const upsertTransaction = `
+INSERT INTO transactions (ledger_key, transaction_id, customer_id, account_id, amount, fee, currency, status,
+	failure_code, type, counterparty_customer, counterparty_account, end_to_end_id, created, tx_id, doc)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+ON CONFLICT (ledger_key) DO UPDATE SET transaction_id = $2, customer_id = $3, account_id = $4, amount = $5,
+	fee = $6, currency = $7, status = $8, failure_code = $9, type = $10, counterparty_customer = $11,
+	counterparty_account = $12, end_to_end_id = $13, created = $14, tx_id = $15, doc = $16`
+
+// statement is a SQL statement with its arguments
+type statement struct {
+	query string
+	args  []interface{}
+}
+
+// store is the relational mirror in PostgreSQL
+type store struct {
+	db *sql.DB
+}
+
+// migrate creates the tables and indexes that do not exist yet
+func (s *store) migrate() error {
+	if _, err := s.db.Exec(schema); err != nil {
+		return fmt.Errorf("Failed to create the schema. Error: %s", err)
+	}
+	return nil
+}
+
+// lastBlock returns the last block applied to the mirror, and false if none
+func (s *store) lastBlock() (uint64, bool, error) {
+	var block int64
+	err := s.db.QueryRow("SELECT block FROM finnet_checkpoint WHERE id = 1").Scan(&block)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(block), true, nil
+}
+
+// apply mirrors the writes of a block and records it as the last block in a
+// single database transaction, so a block is applied exactly once
+func (s *store) apply(block uint64, writes []*write) error {
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+	for _, w := range writes {
+		stmts, err := project(w)
+		if err != nil {
+			return fmt.Errorf("Error projecting key %q of transaction %s. Error: %s", w.Key, w.TxID, err)
+		}
+		for _, stmt := range stmts {
+			if _, err := dbTx.Exec(stmt.query, stmt.args...); err != nil {
+				return fmt.Errorf("Error mirroring key %q of transaction %s. Error: %s", w.Key, w.TxID, err)
+			}
+		}
+	}
+	if _, err := dbTx.Exec("INSERT INTO finnet_checkpoint (id, block) VALUES (1, $1) ON CONFLICT (id) DO UPDATE SET block = $1", int64(block)); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// project returns the statements mirroring a write: an upsert of an account
+// or transaction record, or a delete of the rows of a deleted key. Writes of
+// other records need no statement.
+func project(w *write) ([]*statement, error) {
+	if w.IsDelete {
+		return []*statement{
+			{"DELETE FROM accounts WHERE ledger_key = $1", []interface{}{w.Key}},
+			{"DELETE FROM transactions WHERE ledger_key = $1", []interface{}{w.Key}},
+		}, nil
+	}
+	var entity model.Entity
+	if err := json.Unmarshal(w.Value, &entity); err != nil {
+		// not every key holds a JSON document, e.g. counters
+		return nil, nil
+	}
+	switch entity.ObjectType {
+	case model.AccountObjectType:
+		a := new(model.Account)
+		if err := json.Unmarshal(w.Value, a); err != nil {
+			return nil, err
+		}
+		return []*statement{{upsertAccount, []interface{}{
+			w.Key, a.CustomerID, a.ID, a.BankName, a.ProductID, a.CountryCode, a.CurrencyCode,
+			a.Balance, a.Held, a.Overdraft, string(a.Status), a.Version, time.Unix(a.Created, 0).UTC(),
+			nullTime(a.Updated), w.TxID, string(w.Value),
+		}}}, nil
+	case model.TransactionObjectType:
+		t := new(model.Transaction)
+		if err := json.Unmarshal(w.Value, t); err != nil {
+			return nil, err
+		}
+		return []*statement{{upsertTransaction, []interface{}{
+			w.Key, t.ID, t.CustomerID, t.AccountID, t.Amount, t.Fee, t.CurrencyCode, string(t.Status),
+			string(t.FailureCode), string(t.Type), t.CounterpartyCustomerID, t.CounterpartyAccountID,
+			t.EndToEndID, time.Unix(t.Created, 0).UTC(), w.TxID, string(w.Value),
+		}}}, nil
+	}
+	return nil, nil
+}
+
+func nullTime(unix int64) interface{} {
+	if unix == 0 {
+		return nil
+	}
+	return time.Unix(unix, 0).UTC()
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iShamSLam/chaincode/client"
+	"github.com/iShamSLam/chaincode/model"
+)
+
+// invocation runs a parsed command and returns the result to print
+type invocation func(ctx context.Context, c *client.Client) (interface{}, error)
+
+// preparation checks the parsed flags of a command and returns its
+// invocation, or errUsage if the flags are incomplete
+type preparation func() (invocation, error)
+
+// command is a finnetctl subcommand. setup defines its flags and returns the
+// preparation to run once they are parsed, before connecting.
+type command struct {
+	group   string
+	name    string
+	summary string
+	setup   func(fs *flag.FlagSet) preparation
+}
+
+var commands = []*command{
+	{"account", "open", "open an account", accountOpen},
+	{"account", "close", "close an account, sweeping its balance if named", accountClose},
+	{"account", "get", "show an account", accountGet},
+	{"account", "load", "load an account book with opening balances from a CSV file", accountLoad},
+	{"transfer", "send", "transfer money between accounts", transferSend},
+	{"tx", "list", "list the transactions of an account", txList},
+	{"emission", "mint", "mint money into an account", emissionMint},
+	{"emission", "burn", "burn money from an account", emissionBurn},
+	{"limits", "set", "set the transaction limits of a customer", limitsSet},
+}
+
+func findCommand(group string, name string) *command {
+	for _, cmd := range commands {
+		if cmd.group == group && cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// readPayload decodes a JSON payload file into v, - for standard input
+func readPayload(path string, v interface{}) error {
+	f := os.Stdin
+	if path != "-" {
+		var err error
+		if f, err = os.Open(path); err != nil {
+			return err
+		}
+		defer f.Close()
+	}
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("Error parsing %s. Error: %s", path, err)
+	}
+	return nil
+}
+
+func accountOpen(fs *flag.FlagSet) preparation {
+	file := fs.String("f", "", "account JSON file, - for standard input; the other flags override its fields")
+	customerID := fs.String("customer", "", "customer ID (required)")
+	accountID := fs.String("account", "", "account ID, generated if not set")
+	currency := fs.String("currency", "", "ISO 4217 currency code (required)")
+	bank := fs.String("bank", "", "bank name")
+	holder := fs.String("holder", "", "account holder")
+	country := fs.String("country", "", "ISO 3166-1 alpha-2 country code")
+	product := fs.String("product", "", "product of the catalog")
+	return func() (invocation, error) {
+		account := new(model.Account)
+		if *file != "" {
+			if err := readPayload(*file, account); err != nil {
+				return nil, err
+			}
+		}
+		setIf(&account.CustomerID, *customerID)
+		setIf(&account.ID, *accountID)
+		setIf(&account.CurrencyCode, *currency)
+		setIf(&account.BankName, *bank)
+		setIf(&account.AccountHolder, *holder)
+		setIf(&account.CountryCode, *country)
+		setIf(&account.ProductID, *product)
+		if account.CustomerID == "" || account.CurrencyCode == "" {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, c *client.Client) (interface{}, error) {
+			return c.OpenAccount(ctx, account)
+		}, nil
+	}
+}
+
+func accountClose(fs *flag.FlagSet) preparation {
+	customerID := fs.String("customer", "", "customer ID (required)")
+	accountID := fs.String("account", "", "account ID (required)")
+	sweepCustomerID := fs.String("sweep-customer", "", "customer ID of the account to sweep the balance into")
+	sweepAccountID := fs.String("sweep-account", "", "account ID of the account to sweep the balance into")
+	return func() (invocation, error) {
+		if *customerID == "" || *accountID == "" || (*sweepCustomerID == "") != (*sweepAccountID == "") {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, c *client.Client) (interface{}, error) {
+			return c.CloseAccount(ctx, *customerID, *accountID, *sweepCustomerID, *sweepAccountID)
+		}, nil
+	}
+}
+
+func accountGet(fs *flag.FlagSet) preparation {
+	customerID := fs.String("customer", "", "customer ID (required)")
+	accountID := fs.String("account", "", "account ID (required)")
+	return func() (invocation, error) {
+		if *customerID == "" || *accountID == "" {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, c *client.Client) (interface{}, error) {
+			account, err := c.GetAccount(ctx, *customerID, *accountID)
+			if err == client.ErrNotFound {
+				return nil, fmt.Errorf("Account %s of customer %s not found", *accountID, *customerID)
+			}
+			return account, err
+		}, nil
+	}
+}
+
+func transferSend(fs *flag.FlagSet) preparation {
+	file := fs.String("f", "", "transfer JSON file, - for standard input; the other flags override its fields")
+	fromCustomerID := fs.String("from-customer", "", "payer customer ID (required)")
+	fromAccountID := fs.String("from-account", "", "payer account ID (required)")
+	toCustomerID := fs.String("to-customer", "", "payee customer ID (required)")
+	toAccountID := fs.String("to-account", "", "payee account ID (required)")
+	amount := fs.Int64("amount", 0, "amount in minor units of the currency (required)")
+	currency := fs.String("currency", "", "ISO 4217 currency code (required)")
+	description := fs.String("description", "", "description")
+	expectedVersion := fs.Int64("expected-version", 0, "payer account version the transfer must apply to")
+	return func() (invocation, error) {
+		t := new(model.Transfer)
+		if *file != "" {
+			if err := readPayload(*file, t); err != nil {
+				return nil, err
+			}
+		}
+		setIf(&t.FromCustomerID, *fromCustomerID)
+		setIf(&t.FromAccountID, *fromAccountID)
+		setIf(&t.ToCustomerID, *toCustomerID)
+		setIf(&t.ToAccountID, *toAccountID)
+		setIf(&t.CurrencyCode, *currency)
+		setIf(&t.Description, *description)
+		if *amount != 0 {
+			t.Amount = *amount
+		}
+		if *expectedVersion != 0 {
+			t.PayerVersion = *expectedVersion
+		}
+		if t.FromCustomerID == "" || t.FromAccountID == "" || t.ToCustomerID == "" || t.ToAccountID == "" || t.Amount == 0 || t.CurrencyCode == "" {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, c *client.Client) (interface{}, error) {
+			res, err := c.Transfer(ctx, t)
+			if err != nil {
+				return nil, err
+			}
+			// a transfer committed as failed, e.g. by sanctions screening, fails the command
+			if res.Result != nil && res.Result.Status == model.TransferFailed {
+				return res, fmt.Errorf("Transfer failed: %s", res.Result.Error)
+			}
+			return res, nil
+		}, nil
+	}
+}
+
+func txList(fs *flag.FlagSet) preparation {
+	customerID := fs.String("customer", "", "customer ID (required)")
+	accountID := fs.String("account", "", "account ID (required)")
+	pageSize := fs.Int("page-size", 0, "transactions per page, at most 500")
+	bookmark := fs.String("bookmark", "", "next_bookmark of the previous page")
+	all := fs.Bool("all", false, "follow the bookmarks and list every page")
+	from := fs.String("from", "", "first creation day, YYYY-MM-DD")
+	to := fs.String("to", "", "last creation day, YYYY-MM-DD")
+	return func() (invocation, error) {
+		if *customerID == "" || *accountID == "" || *pageSize < 0 {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, c *client.Client) (interface{}, error) {
+			page := &client.Page{Size: *pageSize, Bookmark: *bookmark}
+			list := &model.TransactionList{Transactions: []*model.Transaction{}}
+			for {
+				res, err := c.GetTransactionListBetween(ctx, *customerID, *accountID, *from, *to, page)
+				if err != nil {
+					return nil, err
+				}
+				list.Transactions = append(list.Transactions, res.Transactions...)
+				list.NextBookmark = res.NextBookmark
+				if !*all || res.NextBookmark == "" {
+					return list, nil
+				}
+				page.Bookmark = res.NextBookmark
+			}
+		}, nil
+	}
+}
+
+func emissionMint(fs *flag.FlagSet) preparation {
+	return emission(fs, (*client.Client).Mint)
+}
+
+func emissionBurn(fs *flag.FlagSet) preparation {
+	return emission(fs, (*client.Client).Burn)
+}
+
+func emission(fs *flag.FlagSet, emit func(*client.Client, context.Context, string, string, int64, string) (*model.EmissionRecord, error)) preparation {
+	customerID := fs.String("customer", "", "customer ID (required)")
+	accountID := fs.String("account", "", "account ID (required)")
+	amount := fs.Int64("amount", 0, "amount in minor units of the account currency (required)")
+	reference := fs.String("reference", "", "reference of the emission")
+	return func() (invocation, error) {
+		if *customerID == "" || *accountID == "" || *amount == 0 {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, c *client.Client) (interface{}, error) {
+			return emit(c, ctx, *customerID, *accountID, *amount, *reference)
+		}, nil
+	}
+}
+
+func limitsSet(fs *flag.FlagSet) preparation {
+	limits := new(model.Limits)
+	fs.StringVar(&limits.CustomerID, "customer", "", "customer ID (required)")
+	fs.StringVar(&limits.CurrencyCode, "currency", "", "ISO 4217 currency code (required)")
+	fs.Int64Var(&limits.SingleMax, "single-max", 0, "largest single transfer in minor units, 0 for no limit")
+	fs.Int64Var(&limits.DailyMax, "daily-max", 0, "total transferred in the last 24 hours, 0 for no limit")
+	fs.Int64Var(&limits.MonthlyMax, "monthly-max", 0, "total transferred in the last 30 days, 0 for no limit")
+	fs.IntVar(&limits.DailyCount, "daily-count", 0, "number of transfers in the last 24 hours, 0 for no limit")
+	enforcement := fs.String("enforcement", string(model.LimitReject), "reject or flag transfers breaching the limits")
+	return func() (invocation, error) {
+		if limits.CustomerID == "" || limits.CurrencyCode == "" {
+			return nil, errUsage
+		}
+		limits.Enforcement = model.LimitEnforcement(*enforcement)
+		return func(ctx context.Context, c *client.Client) (interface{}, error) {
+			return c.SetLimits(ctx, limits)
+		}, nil
+	}
+}
+
+func setIf(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
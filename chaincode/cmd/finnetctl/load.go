@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iShamSLam/chaincode/client"
+	"github.com/iShamSLam/chaincode/model"
+)
+
+// loadColumns maps the CSV columns of an account book to the account fields;
+// the customer_ columns fill the profile of customers to register
+var loadColumns = map[string]string{
+	"customer_id":        "customer_id",
+	"account_id":         "id",
+	"currency":           "currency",
+	"bank":               "bank_name",
+	"holder":             "account_holder",
+	"country":            "country",
+	"product":            "product_id",
+	"account_type":       "account_type",
+	"description":        "description",
+	"customer_name":      "name",
+	"customer_country":   "country",
+	"customer_residency": "residency",
+	"customer_risk_tier": "risk_tier",
+}
+
+// loadReport reconciles the rows of an account book with their outcome
+type loadReport struct {
+	Rows     int                      `json:"rows"`
+	Loaded   int                      `json:"loaded"`
+	Rejected int                      `json:"rejected"`
+	Expected map[string]int64         `json:"expected_balances"` // opening balances in cents of every row per currency
+	Balances map[string]int64         `json:"loaded_balances"`   // opening balances in cents of the loaded rows per currency
+	Items    []*model.AccountLoadItem `json:"items"`
+}
+
+func accountLoad(fs *flag.FlagSet) preparation {
+	file := fs.String("f", "", "CSV file of the account book, - for standard input (required)")
+	batch := fs.Int("batch", 200, fmt.Sprintf("rows per invocation, at most %d", model.MaxAccountLoadRows))
+	reportFile := fs.String("report", "", "CSV file to write the outcome of every row to")
+	return func() (invocation, error) {
+		if *file == "" || *batch < 1 || *batch > model.MaxAccountLoadRows {
+			return nil, errUsage
+		}
+		report, rows, err := readAccountBook(*file)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, c *client.Client) (interface{}, error) {
+			for start := 0; start < len(rows); start += *batch {
+				end := start + *batch
+				if end > len(rows) {
+					end = len(rows)
+				}
+				load := &model.AccountLoad{Rows: rows[start:end]}
+				data, _ := json.Marshal(load)
+				key := sha256.Sum256(data)
+				result, err := c.LoadAccounts(ctx, load, hex.EncodeToString(key[:]))
+				if err != nil {
+					// the whole chunk failed, e.g. the peers could not be reached
+					for _, row := range load.Rows {
+						report.add(&model.AccountLoadItem{Row: row.Row, Status: "rejected", OpeningBalance: row.OpeningBalance, Error: err.Error()}, "")
+					}
+					continue
+				}
+				currencies := make(map[int]string, len(load.Rows))
+				for _, row := range load.Rows {
+					currencies[row.Row] = rowCurrency(row)
+				}
+				for _, item := range result.Items {
+					report.add(item, currencies[item.Row])
+				}
+			}
+			report.sortItems()
+			if *reportFile != "" {
+				if err := writeLoadReport(*reportFile, report); err != nil {
+					return report, err
+				}
+			}
+			if report.Rejected > 0 {
+				return report, fmt.Errorf("%d of %d rows rejected", report.Rejected, report.Rows)
+			}
+			return report, nil
+		}, nil
+	}
+}
+
+// readAccountBook parses the rows of an account book. Rows that cannot be
+// parsed are rejected in the returned report; the others are returned for
+// loading.
+func readAccountBook(path string) (*loadReport, []*model.AccountLoadRow, error) {
+	f := os.Stdin
+	if path != "-" {
+		var err error
+		if f, err = os.Open(path); err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+	}
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error reading header of %s. Error: %s", path, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := loadColumns[name]; !ok && name != "opening_balance" {
+			return nil, nil, fmt.Errorf("Unknown column %s in %s", name, path)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"customer_id", "currency"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("Missing required column %s in %s", name, path)
+		}
+	}
+	report := &loadReport{Expected: map[string]int64{}, Balances: map[string]int64{}, Items: []*model.AccountLoadItem{}}
+	var rows []*model.AccountLoadRow
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			return report, rows, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error reading %s. Error: %s", path, err)
+		}
+		report.Rows++
+		row, err := parseLoadRow(line, columns, record)
+		if err != nil {
+			report.add(&model.AccountLoadItem{Row: line, Status: "rejected", Error: err.Error()}, "")
+			continue
+		}
+		report.Expected[rowCurrency(row)] += row.OpeningBalance
+		rows = append(rows, row)
+	}
+}
+
+// parseLoadRow builds the load row of a CSV record on a line of the file
+func parseLoadRow(line int, columns map[string]int, record []string) (*model.AccountLoadRow, error) {
+	account := map[string]string{}
+	customer := map[string]string{}
+	for name, i := range columns {
+		value := strings.TrimSpace(record[i])
+		field, ok := loadColumns[name]
+		if !ok || value == "" {
+			continue
+		}
+		if strings.HasPrefix(name, "customer_") && name != "customer_id" {
+			customer[field] = value
+		} else {
+			account[field] = value
+		}
+	}
+	if account["customer_id"] == "" || account["currency"] == "" {
+		return nil, fmt.Errorf("Missing required customer_id and / or currency")
+	}
+	row := &model.AccountLoadRow{Row: line}
+	if i, ok := columns["opening_balance"]; ok && strings.TrimSpace(record[i]) != "" {
+		amount, err := parseDecimal(strings.TrimSpace(record[i]), account["currency"])
+		if err != nil {
+			return nil, err
+		}
+		row.OpeningBalance = amount
+	}
+	row.Account, _ = json.Marshal(account)
+	if len(customer) > 0 {
+		customer["id"] = account["customer_id"]
+		row.Customer, _ = json.Marshal(customer)
+	}
+	return row, nil
+}
+
+// parseDecimal parses an amount in the major units of a currency, e.g.
+// 1234.56 AUD, into minor units
+func parseDecimal(value string, currency string) (int64, error) {
+	exponent := model.CurrencyMinorUnits(currency)
+	whole, fraction := value, ""
+	if i := strings.Index(value, "."); i >= 0 {
+		whole, fraction = value[:i], value[i+1:]
+	}
+	if len(fraction) > exponent {
+		return 0, fmt.Errorf("Opening balance %s has more than %d decimals for %s", value, exponent, currency)
+	}
+	amount, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", exponent-len(fraction)), 10, 64)
+	if err != nil || strings.HasPrefix(value, "+") {
+		return 0, fmt.Errorf("Invalid opening balance %s", value)
+	}
+	return amount, nil
+}
+
+func rowCurrency(row *model.AccountLoadRow) string {
+	var account struct {
+		CurrencyCode string `json:"currency"`
+	}
+	json.Unmarshal(row.Account, &account)
+	return account.CurrencyCode
+}
+
+func (r *loadReport) add(item *model.AccountLoadItem, currency string) {
+	if item.Status == "loaded" {
+		r.Loaded++
+		r.Balances[currency] += item.OpeningBalance
+	} else {
+		r.Rejected++
+	}
+	r.Items = append(r.Items, item)
+}
+
+// sortItems orders the items by row, as rows rejected while parsing come first
+func (r *loadReport) sortItems() {
+	sort.SliceStable(r.Items, func(i, j int) bool { return r.Items[i].Row < r.Items[j].Row })
+}
+
+func writeLoadReport(path string, report *loadReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"row", "customer_id", "account_id", "status", "opening_balance", "error"})
+	for _, item := range report.Items {
+		w.Write([]string{strconv.Itoa(item.Row), item.CustomerID, item.AccountID, item.Status, strconv.FormatInt(item.OpeningBalance, 10), item.Error})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
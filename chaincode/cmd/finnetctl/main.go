@@ -0,0 +1,166 @@
+// Command finnetctl lets operators run FinNet chaincode functions from the
+// shell and from runbook scripts:
+//
+//	finnetctl [flags] <group> <command> [command flags]
+//
+// It invokes the chaincode with a client identity of a wallet and prints the
+// result as a table, or as JSON with -o json. Failures are printed to
+// standard error and exit with status 1, usage errors with status 2.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/iShamSLam/chaincode/client"
+)
+
+// errUsage is returned by command preparations for incomplete command lines
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, connect))
+}
+
+// options are the global flags of finnetctl
+type options struct {
+	profile   string
+	wallet    string
+	identity  string
+	channel   string
+	chaincode string
+	output    string
+	timeout   time.Duration
+}
+
+// connector connects the client invoking the chaincode
+type connector func(opts *options) (*client.Client, func(), error)
+
+func run(args []string, stdout io.Writer, stderr io.Writer, connect connector) int {
+	opts := &options{}
+	fs := flag.NewFlagSet("finnetctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.profile, "connection-profile", envOr("FINNET_CONNECTION_PROFILE", "connection.yaml"), "Fabric SDK connection profile")
+	fs.StringVar(&opts.wallet, "wallet", envOr("FINNET_WALLET", "wallet"), "directory of the identity wallet")
+	fs.StringVar(&opts.identity, "identity", os.Getenv("FINNET_IDENTITY"), "wallet identity to invoke the chaincode with")
+	fs.StringVar(&opts.channel, "channel", envOr("FINNET_CHANNEL", "mychannel"), "channel the chaincode is deployed on")
+	fs.StringVar(&opts.chaincode, "chaincode", envOr("FINNET_CHAINCODE", "mycc"), "name of the chaincode")
+	fs.StringVar(&opts.output, "o", "table", "output format, table or json")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "time to wait for the chaincode")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if opts.output != "table" && opts.output != "json" {
+		fmt.Fprintf(stderr, "finnetctl: invalid output format %s\n", opts.output)
+		return 2
+	}
+	if fs.NArg() < 2 {
+		usage(fs)
+		return 2
+	}
+	cmd := findCommand(fs.Arg(0), fs.Arg(1))
+	if cmd == nil {
+		fmt.Fprintf(stderr, "finnetctl: unknown command %s %s\n", fs.Arg(0), fs.Arg(1))
+		usage(fs)
+		return 2
+	}
+	cmdFlags := flag.NewFlagSet("finnetctl "+cmd.group+" "+cmd.name, flag.ContinueOnError)
+	cmdFlags.SetOutput(stderr)
+	prepare := cmd.setup(cmdFlags)
+	if err := cmdFlags.Parse(fs.Args()[2:]); err != nil {
+		return 2
+	}
+	invoke, err := prepare()
+	if err == errUsage {
+		cmdFlags.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "finnetctl: %s\n", err)
+		return 2
+	}
+
+	c, closeClient, err := connect(opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "finnetctl: %s\n", err)
+		return 1
+	}
+	defer closeClient()
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+	res, err := invoke(ctx, c)
+	if res != nil {
+		if printErr := printResult(stdout, opts.output, res); printErr != nil {
+			fmt.Fprintf(stderr, "finnetctl: %s\n", printErr)
+			return 1
+		}
+	}
+	if err != nil {
+		printError(stderr, opts.output, err)
+		return 1
+	}
+	return 0
+}
+
+// connect connects a gateway with the wallet identity and returns the client
+// with the function closing the gateway
+func connect(opts *options) (*client.Client, func(), error) {
+	if opts.identity == "" {
+		return nil, nil, errors.New("Missing identity, set -identity or FINNET_IDENTITY")
+	}
+	wallet, err := client.OpenWallet(opts.wallet)
+	if err != nil {
+		return nil, nil, err
+	}
+	pool := client.NewPool(wallet, opts.profile, opts.channel, opts.chaincode)
+	c, err := pool.Client(opts.identity)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s %s", err, opts.identity)
+	}
+	return c, pool.Close, nil
+}
+
+func usage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintln(out, "Usage: finnetctl [flags] <group> <command> [command flags]")
+	fmt.Fprintln(out, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-20s %s\n", cmd.group+" "+cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(out, "\nFlags:")
+	fs.PrintDefaults()
+}
+
+func envOr(name string, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// printError prints the message of a failed command, as a JSON object of the
+// error, failure code and invalid fields with -o json
+func printError(w io.Writer, output string, err error) {
+	if output != "json" {
+		fmt.Fprintf(w, "finnetctl: %s\n", err)
+		return
+	}
+	res := map[string]interface{}{"error": err.Error()}
+	if e, ok := err.(*client.Error); ok {
+		res["error"] = e.Message
+		if e.Code != "" {
+			res["code"] = e.Code
+		}
+		if len(e.Fields) > 0 {
+			res["fields"] = e.Fields
+		}
+	}
+	json.NewEncoder(w).Encode(res)
+}
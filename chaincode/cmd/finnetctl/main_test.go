@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/client"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// fakeContract answers every transaction with a fixed response or error and
+// records the last call
+type fakeContract struct {
+	res      []byte
+	err      error
+	function string
+	args     []string
+}
+
+func (f *fakeContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	f.function, f.args = name, args
+	return f.res, f.err
+}
+
+func (f *fakeContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	f.function, f.args = name, args
+	return f.res, f.err
+}
+
+func (f *fakeContract) RegisterEvent(eventFilter string) (fab.Registration, <-chan *fab.CCEvent, error) {
+	return nil, nil, errors.New("not supported")
+}
+
+func (f *fakeContract) Unregister(registration fab.Registration) {}
+
+func runWith(contract *fakeContract, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	connect := func(opts *options) (*client.Client, func(), error) {
+		return client.New(contract), func() {}, nil
+	}
+	code := run(args, &stdout, &stderr, connect)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no command", []string{}},
+		{"unknown command", []string{"account", "delete"}},
+		{"invalid output", []string{"-o", "yaml", "account", "get", "-customer", "1234", "-account", "1"}},
+		{"missing account", []string{"account", "get", "-customer", "1234"}},
+		{"half sweep", []string{"account", "close", "-customer", "1234", "-account", "1", "-sweep-customer", "5678"}},
+		{"missing amount", []string{"emission", "mint", "-customer", "1234", "-account", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contract := &fakeContract{}
+			if code, _, _ := runWith(contract, tt.args...); code != 2 {
+				t.Errorf("expected exit status 2, got %d", code)
+			}
+			if contract.function != "" {
+				t.Errorf("expected no invocation, got %s", contract.function)
+			}
+		})
+	}
+}
+
+func TestTransferSend(t *testing.T) {
+	contract := &fakeContract{res: []byte(`{"from_account":"1","amount":1000,"status":"completed","tx_id":"t1"}`)}
+	code, stdout, _ := runWith(contract, "transfer", "send", "-from-customer", "1234", "-from-account", "1",
+		"-to-customer", "5678", "-to-account", "2", "-currency", "AUD", "-amount", "1000")
+	if code != 0 {
+		t.Fatalf("expected exit status 0, got %d", code)
+	}
+	if contract.function != "TransferMoney" || !strings.Contains(contract.args[0], `"amount":1000`) {
+		t.Errorf("unexpected invocation %s %v", contract.function, contract.args)
+	}
+	if !strings.Contains(stdout, "tx_id") || !strings.Contains(stdout, "t1") {
+		t.Errorf("expected the result table, got %q", stdout)
+	}
+}
+
+func TestTransferFailedResult(t *testing.T) {
+	contract := &fakeContract{res: []byte(`{"from_account":"1","amount":1000,"status":"failed","error":"sanctions hit"}`)}
+	code, stdout, stderr := runWith(contract, "-o", "json", "transfer", "send", "-from-customer", "1234", "-from-account", "1",
+		"-to-customer", "5678", "-to-account", "2", "-currency", "AUD", "-amount", "1000")
+	if code != 1 {
+		t.Errorf("expected exit status 1, got %d", code)
+	}
+	if !strings.Contains(stdout, `"status": "failed"`) {
+		t.Errorf("expected the failed result, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "sanctions hit") {
+		t.Errorf("expected the failure reason, got %q", stderr)
+	}
+}
+
+func TestJSONError(t *testing.T) {
+	contract := &fakeContract{err: errors.New("Description: concurrent_modification: Account 1 is at version 8, expected 7")}
+	code, _, stderr := runWith(contract, "-o", "json", "account", "close", "-customer", "1234", "-account", "1")
+	if code != 1 {
+		t.Errorf("expected exit status 1, got %d", code)
+	}
+	if !strings.Contains(stderr, `"code":"concurrent_modification"`) {
+		t.Errorf("expected the failure code, got %q", stderr)
+	}
+}
+
+func TestAccountLoad(t *testing.T) {
+	f, err := ioutil.TempFile("", "accounts*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("customer_id,account_id,currency,holder,opening_balance\n" +
+		"1234,1,AUD,Jane Citizen,1500.25\n" +
+		"1234,2,AUD,Jane Citizen,10.123\n" +
+		"5678,1,JPY,Taro Yamada,5000\n")
+	f.Close()
+	contract := &fakeContract{res: []byte(`{"total":2,"loaded":1,"rejected":1,"balances":{"AUD":150025},"items":[` +
+		`{"row":2,"customer_id":"1234","account_id":"1","status":"loaded","opening_balance":150025},` +
+		`{"row":4,"customer_id":"5678","account_id":"1","status":"rejected","opening_balance":5000,"error":"Customer 5678 is not registered"}]}`)}
+
+	code, stdout, stderr := runWith(contract, "-o", "json", "account", "load", "-f", f.Name())
+	if code != 1 {
+		t.Errorf("expected exit status 1, got %d", code)
+	}
+	if contract.function != "LoadAccounts" || !strings.Contains(contract.args[0], `"opening_balance":150025`) || !strings.Contains(contract.args[0], `"opening_balance":5000`) {
+		t.Errorf("unexpected invocation %s %v", contract.function, contract.args)
+	}
+	report := new(loadReport)
+	if err := json.Unmarshal([]byte(stdout), report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 3 || report.Loaded != 1 || report.Rejected != 2 {
+		t.Errorf("expected 1 of 3 rows loaded, got %+v", report)
+	}
+	if report.Expected["AUD"] != 150025 || report.Balances["AUD"] != 150025 || report.Expected["JPY"] != 5000 || report.Balances["JPY"] != 0 {
+		t.Errorf("unexpected balances %v loaded of %v", report.Balances, report.Expected)
+	}
+	if len(report.Items) != 3 || report.Items[1].Row != 3 || !strings.Contains(report.Items[1].Error, "decimals") {
+		t.Errorf("expected row 3 rejected for its decimals, got %+v", report.Items)
+	}
+	if !strings.Contains(stderr, "2 of 3 rows rejected") {
+		t.Errorf("expected the rejected rows, got %q", stderr)
+	}
+}
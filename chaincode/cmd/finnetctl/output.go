@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/iShamSLam/chaincode/client"
+	"github.com/iShamSLam/chaincode/model"
+)
+
+// printResult prints the result of a command as an indented JSON document,
+// or with -o table as a field / value table, a transaction list as one row
+// per transaction and an account load as its totals and rejected rows
+func printResult(w io.Writer, output string, res interface{}) error {
+	if r, ok := res.(*client.TransferResponse); ok {
+		res = transferOutcome(r)
+	}
+	if output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	switch res := res.(type) {
+	case *model.TransactionList:
+		printTransactions(tw, res)
+		return tw.Flush()
+	case *loadReport:
+		printLoadReport(tw, res)
+		return tw.Flush()
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	rows := map[string]string{}
+	flatten(rows, "", fields)
+	names := make([]string, 0, len(rows))
+	for name := range rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(tw, "%s\t%s\n", name, rows[name])
+	}
+	return tw.Flush()
+}
+
+// transferOutcome returns the field set in the response of a transfer
+func transferOutcome(r *client.TransferResponse) interface{} {
+	switch {
+	case r.Approval != nil:
+		return r.Approval
+	case r.Proposal != nil:
+		return r.Proposal
+	default:
+		return r.Result
+	}
+}
+
+func printTransactions(w io.Writer, list *model.TransactionList) {
+	fmt.Fprintln(w, "ID\tCREATED\tAMOUNT\tFEE\tSTATUS\tCOUNTERPARTY\tDESCRIPTION")
+	for _, t := range list.Transactions {
+		counterparty := ""
+		if t.CounterpartyAccountID != "" {
+			counterparty = t.CounterpartyCustomerID + "/" + t.CounterpartyAccountID
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, time.Unix(t.Created, 0).UTC().Format(time.RFC3339),
+			model.NewMoney(t.Amount, t.CurrencyCode), model.NewMoney(t.Fee, t.CurrencyCode), t.Status, counterparty, t.Description)
+	}
+	if list.NextBookmark != "" {
+		fmt.Fprintf(w, "\nnext bookmark: %s\n", list.NextBookmark)
+	}
+}
+
+// printLoadReport prints the totals of an account load per currency and the
+// rejected rows
+func printLoadReport(w io.Writer, r *loadReport) {
+	fmt.Fprintf(w, "rows\t%d\nloaded\t%d\nrejected\t%d\n", r.Rows, r.Loaded, r.Rejected)
+	currencies := make([]string, 0, len(r.Expected))
+	for currency := range r.Expected {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	fmt.Fprintln(w, "\nCURRENCY\tEXPECTED\tLOADED")
+	for _, currency := range currencies {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", currency, model.NewMoney(r.Expected[currency], currency), model.NewMoney(r.Balances[currency], currency))
+	}
+	if r.Rejected == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nROW\tCUSTOMER\tACCOUNT\tERROR")
+	for _, item := range r.Items {
+		if item.Status != "loaded" {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", item.Row, item.CustomerID, item.AccountID, item.Error)
+		}
+	}
+}
+
+// flatten adds the scalar fields of a decoded JSON object to rows, naming
+// the fields of nested objects by their path, e.g. transfer.from_account
+func flatten(rows map[string]string, prefix string, fields map[string]interface{}) {
+	for name, v := range fields {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			flatten(rows, prefix+name+".", v)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				data, _ := json.Marshal(item)
+				items[i] = strings.Trim(string(data), `"`)
+			}
+			rows[prefix+name] = strings.Join(items, ", ")
+		case nil:
+		default:
+			data, _ := json.Marshal(v)
+			rows[prefix+name] = strings.Trim(string(data), `"`)
+		}
+	}
+}
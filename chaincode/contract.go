@@ -0,0 +1,960 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SmartContract exposes the chaincode handler functions as contract
+// transactions with typed parameters. Each transaction passes its parameters
+// on to the handler of the same name in their former string form, so role
+// checks, idempotency, tenancy, private data and events apply as before and
+// clients keep invoking functions by the same names and arguments. Functions
+// taking optional arguments are not declared here; they are dispatched by
+// the contract's unknown transaction handler with the raw arguments.
+type SmartContract struct {
+	contractapi.Contract
+	cc *Chaincode
+}
+
+func newSmartContract(cc *Chaincode) *SmartContract {
+	s := &SmartContract{cc: cc}
+	s.UnknownTransaction = s.dispatch
+	return s
+}
+
+// invoke runs the registered handler function with the arguments
+func (s *SmartContract) invoke(ctx contractapi.TransactionContextInterface, function string, args ...string) (string, error) {
+	res, err := s.cc.handleInvocation(ctx.GetStub(), function, args)
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
+// dispatch runs the handler function named by the invocation with its raw
+// arguments, for functions without a typed transaction
+func (s *SmartContract) dispatch(ctx contractapi.TransactionContextInterface) (string, error) {
+	function, args := ctx.GetStub().GetFunctionAndParameters()
+	// the function may be qualified with the contract name
+	if i := strings.LastIndex(function, ":"); i >= 0 {
+		function = function[i+1:]
+	}
+	return s.invoke(ctx, function, args...)
+}
+
+// jsonArg encodes a typed parameter as the JSON argument its handler parses
+func jsonArg(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+//------------------------------
+// Contract transactions
+//------------------------------
+
+// GetAccount query blockchain account by account ID
+func (s *SmartContract) GetAccount(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetAccount", customerID, accountID)
+}
+
+// GetBalance query the balance and available balance of an account
+func (s *SmartContract) GetBalance(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetBalance", customerID, accountID)
+}
+
+// GetTransactionByReference query the transactions carrying an end-to-end reference
+func (s *SmartContract) GetTransactionByReference(ctx contractapi.TransactionContextInterface, reference string) (string, error) {
+	return s.invoke(ctx, "GetTransactionByReference", reference)
+}
+
+// GetTransaction query blockchain transaction by transaction ID
+func (s *SmartContract) GetTransaction(ctx contractapi.TransactionContextInterface, customerID string, accountID string, transactionID string) (string, error) {
+	return s.invoke(ctx, "GetTransaction", customerID, accountID, transactionID)
+}
+
+// SettleTransfer pays a pending transfer to the payee
+func (s *SmartContract) SettleTransfer(ctx contractapi.TransactionContextInterface, customerID string, accountID string, transferID string) (string, error) {
+	return s.invoke(ctx, "SettleTransfer", customerID, accountID, transferID)
+}
+
+// RejectTransfer returns the held funds of a pending transfer to the payer, or
+// rejects a transfer held for approval
+func (s *SmartContract) RejectTransfer(ctx contractapi.TransactionContextInterface, customerID string, accountID string, transferID string, reason string) (string, error) {
+	return s.invoke(ctx, "RejectTransfer", customerID, accountID, transferID, reason)
+}
+
+// SetApprovalPolicy sets the threshold above which transfers in a currency are
+// held for approval and the number of approvals they need
+func (s *SmartContract) SetApprovalPolicy(ctx contractapi.TransactionContextInterface, policyJSON string) (string, error) {
+	return s.invoke(ctx, "SetApprovalPolicy", policyJSON)
+}
+
+// GetApprovalPolicy query the approval policy of a currency
+func (s *SmartContract) GetApprovalPolicy(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "GetApprovalPolicy", currency)
+}
+
+// ApproveTransfer records the calling approver's approval of a transfer held
+// for approval and executes it once it has the approvals required
+func (s *SmartContract) ApproveTransfer(ctx contractapi.TransactionContextInterface, customerID string, accountID string, transferID string) (string, error) {
+	return s.invoke(ctx, "ApproveTransfer", customerID, accountID, transferID)
+}
+
+// GetPendingTransfers query the transfers of an account awaiting settlement
+func (s *SmartContract) GetPendingTransfers(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetPendingTransfers", customerID, accountID)
+}
+
+// PlaceHold reserves part of an account balance without moving money
+func (s *SmartContract) PlaceHold(ctx contractapi.TransactionContextInterface, customerID string, accountID string, amount int64, reference string) (string, error) {
+	return s.invoke(ctx, "PlaceHold", customerID, accountID, strconv.FormatInt(amount, 10), reference)
+}
+
+// ReleaseHold releases an active hold, returning its amount to the available
+// balance
+func (s *SmartContract) ReleaseHold(ctx contractapi.TransactionContextInterface, customerID string, accountID string, holdID string) (string, error) {
+	return s.invoke(ctx, "ReleaseHold", customerID, accountID, holdID)
+}
+
+// GetHolds query the active holds of an account
+func (s *SmartContract) GetHolds(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetHolds", customerID, accountID)
+}
+
+// GetAvailableBalance query the amount that may be transferred out of an
+// account: its balance plus overdraft limit, less the amount held
+func (s *SmartContract) GetAvailableBalance(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetAvailableBalance", customerID, accountID)
+}
+
+// SetLimits creates or replaces a customer's transaction limits in a currency
+func (s *SmartContract) SetLimits(ctx contractapi.TransactionContextInterface, limitsJSON string) (string, error) {
+	return s.invoke(ctx, "SetLimits", limitsJSON)
+}
+
+// RemoveLimits deletes a customer's transaction limits in a currency
+func (s *SmartContract) RemoveLimits(ctx contractapi.TransactionContextInterface, customerID string, currency string) (string, error) {
+	return s.invoke(ctx, "RemoveLimits", customerID, currency)
+}
+
+// GetLimits query a customer's transaction limits with the current rolling
+// counters
+func (s *SmartContract) GetLimits(ctx contractapi.TransactionContextInterface, customerID string, currency string) (string, error) {
+	return s.invoke(ctx, "GetLimits", customerID, currency)
+}
+
+// AddBlockedParty adds a customer, account or country to the blocklist
+func (s *SmartContract) AddBlockedParty(ctx contractapi.TransactionContextInterface, partyJSON string) (string, error) {
+	return s.invoke(ctx, "AddBlockedParty", partyJSON)
+}
+
+// RegisterCustomer registers a new customer profile
+func (s *SmartContract) RegisterCustomer(ctx contractapi.TransactionContextInterface, customerJSON string) (string, error) {
+	return s.invoke(ctx, "RegisterCustomer", customerJSON)
+}
+
+// UpdateCustomer replaces a registered customer's profile
+func (s *SmartContract) UpdateCustomer(ctx contractapi.TransactionContextInterface, customerID string, customerJSON string) (string, error) {
+	return s.invoke(ctx, "UpdateCustomer", customerID, customerJSON)
+}
+
+// GetCustomer query a registered customer's profile
+func (s *SmartContract) GetCustomer(ctx contractapi.TransactionContextInterface, customerID string) (string, error) {
+	return s.invoke(ctx, "GetCustomer", customerID)
+}
+
+// GetCustomerSummary query the balances per currency and account counts of a customer
+func (s *SmartContract) GetCustomerSummary(ctx contractapi.TransactionContextInterface, customerID string) (string, error) {
+	return s.invoke(ctx, "GetCustomerSummary", customerID)
+}
+
+// AddBeneficiary saves a payee in a customer's address book
+func (s *SmartContract) AddBeneficiary(ctx contractapi.TransactionContextInterface, beneficiaryJSON string) (string, error) {
+	return s.invoke(ctx, "AddBeneficiary", beneficiaryJSON)
+}
+
+// ListBeneficiaries query a customer's beneficiaries and beneficiary policy
+func (s *SmartContract) ListBeneficiaries(ctx contractapi.TransactionContextInterface, customerID string) (string, error) {
+	return s.invoke(ctx, "ListBeneficiaries", customerID)
+}
+
+// RemoveBeneficiary deletes a payee from a customer's address book
+func (s *SmartContract) RemoveBeneficiary(ctx contractapi.TransactionContextInterface, customerID string, beneficiaryID string) (string, error) {
+	return s.invoke(ctx, "RemoveBeneficiary", customerID, beneficiaryID)
+}
+
+// SetBeneficiaryPolicy restricts a customer's transfers to verified beneficiaries
+func (s *SmartContract) SetBeneficiaryPolicy(ctx contractapi.TransactionContextInterface, policyJSON string) (string, error) {
+	return s.invoke(ctx, "SetBeneficiaryPolicy", policyJSON)
+}
+
+// RemoveBeneficiaryPolicy lifts the beneficiary policy of a customer
+func (s *SmartContract) RemoveBeneficiaryPolicy(ctx contractapi.TransactionContextInterface, customerID string) (string, error) {
+	return s.invoke(ctx, "RemoveBeneficiaryPolicy", customerID)
+}
+
+// CreatePaymentRequest asks a customer to pay into one of the payee's accounts
+func (s *SmartContract) CreatePaymentRequest(ctx contractapi.TransactionContextInterface, requestJSON string) (string, error) {
+	return s.invoke(ctx, "CreatePaymentRequest", requestJSON)
+}
+
+// GetPaymentRequests query the payment requests addressed to a customer
+func (s *SmartContract) GetPaymentRequests(ctx contractapi.TransactionContextInterface, payerCustomerID string) (string, error) {
+	return s.invoke(ctx, "GetPaymentRequests", payerCustomerID)
+}
+
+// PayRequest settles a pending payment request from one of the payer's accounts
+func (s *SmartContract) PayRequest(ctx contractapi.TransactionContextInterface, payerCustomerID string, requestID string, payerAccountID string) (string, error) {
+	return s.invoke(ctx, "PayRequest", payerCustomerID, requestID, payerAccountID)
+}
+
+// CancelPaymentRequest withdraws a pending payment request
+func (s *SmartContract) CancelPaymentRequest(ctx contractapi.TransactionContextInterface, payerCustomerID string, requestID string) (string, error) {
+	return s.invoke(ctx, "CancelPaymentRequest", payerCustomerID, requestID)
+}
+
+// OpenDispute disputes a transfer debited from the payer's account
+func (s *SmartContract) OpenDispute(ctx contractapi.TransactionContextInterface, customerID string, accountID string, transactionID string, reason string) (string, error) {
+	return s.invoke(ctx, "OpenDispute", customerID, accountID, transactionID, reason)
+}
+
+// ResolveDispute closes an open dispute with the refund or reject outcome
+func (s *SmartContract) ResolveDispute(ctx contractapi.TransactionContextInterface, customerID string, disputeID string, outcome string) (string, error) {
+	return s.invoke(ctx, "ResolveDispute", customerID, disputeID, outcome)
+}
+
+// GetDisputes query the disputes opened by a customer
+func (s *SmartContract) GetDisputes(ctx contractapi.TransactionContextInterface, customerID string) (string, error) {
+	return s.invoke(ctx, "GetDisputes", customerID)
+}
+
+// SubmitKYC submits a customer's identity documents hash for verification,
+// replacing any earlier profile with a pending one
+func (s *SmartContract) SubmitKYC(ctx contractapi.TransactionContextInterface, profileJSON string) (string, error) {
+	return s.invoke(ctx, "SubmitKYC", profileJSON)
+}
+
+// ApproveKYC approves a customer's pending KYC profile with a risk rating and
+// expiry date
+func (s *SmartContract) ApproveKYC(ctx contractapi.TransactionContextInterface, customerID string, riskRating string, expiry string) (string, error) {
+	return s.invoke(ctx, "ApproveKYC", customerID, riskRating, expiry)
+}
+
+// RejectKYC rejects a customer's pending KYC profile
+func (s *SmartContract) RejectKYC(ctx contractapi.TransactionContextInterface, customerID string, reason string) (string, error) {
+	return s.invoke(ctx, "RejectKYC", customerID, reason)
+}
+
+// GetKYCStatus query a customer's KYC profile and whether it is currently valid
+func (s *SmartContract) GetKYCStatus(ctx contractapi.TransactionContextInterface, customerID string) (string, error) {
+	return s.invoke(ctx, "GetKYCStatus", customerID)
+}
+
+// CreateLiquidityPool creates a new shared interbank liquidity pool
+func (s *SmartContract) CreateLiquidityPool(ctx contractapi.TransactionContextInterface, poolJSON string) (string, error) {
+	return s.invoke(ctx, "CreateLiquidityPool", poolJSON)
+}
+
+// JoinLiquidityPool registers a bank nostro account and drawing limit with a
+// pool
+func (s *SmartContract) JoinLiquidityPool(ctx contractapi.TransactionContextInterface, poolID string, bankID string, nostroCustomerID string, nostroAccountID string, drawingLimit int64) (string, error) {
+	return s.invoke(ctx, "JoinLiquidityPool", poolID, bankID, nostroCustomerID, nostroAccountID, strconv.FormatInt(drawingLimit, 10))
+}
+
+// ContributeToPool moves funds from a member's nostro account into the pool
+func (s *SmartContract) ContributeToPool(ctx contractapi.TransactionContextInterface, poolID string, bankID string, amount int64) (string, error) {
+	return s.invoke(ctx, "ContributeToPool", poolID, bankID, strconv.FormatInt(amount, 10))
+}
+
+// WithdrawFromPool returns undrawn contributed funds to a member's nostro
+// account
+func (s *SmartContract) WithdrawFromPool(ctx contractapi.TransactionContextInterface, poolID string, bankID string, amount int64) (string, error) {
+	return s.invoke(ctx, "WithdrawFromPool", poolID, bankID, strconv.FormatInt(amount, 10))
+}
+
+// DrawFromPool lends pool funds to a member's nostro account within its drawing
+// limit
+func (s *SmartContract) DrawFromPool(ctx contractapi.TransactionContextInterface, poolID string, bankID string, amount int64) (string, error) {
+	return s.invoke(ctx, "DrawFromPool", poolID, bankID, strconv.FormatInt(amount, 10))
+}
+
+// RepayPool repays accrued interest and drawn principal from a member's nostro
+// account
+func (s *SmartContract) RepayPool(ctx contractapi.TransactionContextInterface, poolID string, bankID string, amount int64) (string, error) {
+	return s.invoke(ctx, "RepayPool", poolID, bankID, strconv.FormatInt(amount, 10))
+}
+
+// GetPoolPosition query pool funds, drawings and contribution shares
+func (s *SmartContract) GetPoolPosition(ctx contractapi.TransactionContextInterface, poolID string) (string, error) {
+	return s.invoke(ctx, "GetPoolPosition", poolID)
+}
+
+// SetExposureLimit configures the uncollateralized limit a bank may owe a
+// counterparty in a currency
+func (s *SmartContract) SetExposureLimit(ctx contractapi.TransactionContextInterface, bankID string, counterpartyID string, currency string, limit int64) (string, error) {
+	return s.invoke(ctx, "SetExposureLimit", bankID, counterpartyID, currency, strconv.FormatInt(limit, 10))
+}
+
+// GetBilateralExposure query the exposure of a bank to a counterparty in a
+// currency
+func (s *SmartContract) GetBilateralExposure(ctx contractapi.TransactionContextInterface, bankID string, counterpartyID string, currency string) (string, error) {
+	return s.invoke(ctx, "GetBilateralExposure", bankID, counterpartyID, currency)
+}
+
+// SettleBilateralExposure reduces the amount a bank owes a counterparty once
+// settled
+func (s *SmartContract) SettleBilateralExposure(ctx contractapi.TransactionContextInterface, bankID string, counterpartyID string, currency string, amount int64) (string, error) {
+	return s.invoke(ctx, "SettleBilateralExposure", bankID, counterpartyID, currency, strconv.FormatInt(amount, 10))
+}
+
+// PledgeCollateral registers collateral against a configured bilateral exposure
+func (s *SmartContract) PledgeCollateral(ctx contractapi.TransactionContextInterface, collateralJSON string) (string, error) {
+	return s.invoke(ctx, "PledgeCollateral", collateralJSON)
+}
+
+// ReleaseCollateral returns pledged collateral, provided the remaining
+// uncollateralized exposure stays within the configured limit
+func (s *SmartContract) ReleaseCollateral(ctx contractapi.TransactionContextInterface, collateralID string) (string, error) {
+	return s.invoke(ctx, "ReleaseCollateral", collateralID)
+}
+
+// MarkCollateralToMarket updates the market value of pledged collateral and
+// revalues the exposure it is registered against
+func (s *SmartContract) MarkCollateralToMarket(ctx contractapi.TransactionContextInterface, collateralID string, marketValue int64) (string, error) {
+	return s.invoke(ctx, "MarkCollateralToMarket", collateralID, strconv.FormatInt(marketValue, 10))
+}
+
+// GetCollateral query collateral by ID
+func (s *SmartContract) GetCollateral(ctx contractapi.TransactionContextInterface, collateralID string) (string, error) {
+	return s.invoke(ctx, "GetCollateral", collateralID)
+}
+
+// PublishBenchmarkRate publishes a daily reference rate
+func (s *SmartContract) PublishBenchmarkRate(ctx contractapi.TransactionContextInterface, rateJSON string) (string, error) {
+	return s.invoke(ctx, "PublishBenchmarkRate", rateJSON)
+}
+
+// GetBenchmarkRateHistory query all published rates of a benchmark, oldest
+// first
+func (s *SmartContract) GetBenchmarkRateHistory(ctx contractapi.TransactionContextInterface, name string) (string, error) {
+	return s.invoke(ctx, "GetBenchmarkRateHistory", name)
+}
+
+// SetAccountSigners configures the M-of-N signer identities of a corporate
+// account
+func (s *SmartContract) SetAccountSigners(ctx contractapi.TransactionContextInterface, customerID string, accountID string, signers []string, requiredSignatures int) (string, error) {
+	return s.invoke(ctx, "SetAccountSigners", customerID, accountID, jsonArg(signers), strconv.Itoa(requiredSignatures))
+}
+
+// ApproveOutgoingTransfer records the calling signer's approval of a pending
+// transfer and settles it once the account's quorum is met
+func (s *SmartContract) ApproveOutgoingTransfer(ctx contractapi.TransactionContextInterface, transferID string) (string, error) {
+	return s.invoke(ctx, "ApproveOutgoingTransfer", transferID)
+}
+
+// GetOutgoingTransfer query an approval-gated transfer by ID
+func (s *SmartContract) GetOutgoingTransfer(ctx contractapi.TransactionContextInterface, transferID string) (string, error) {
+	return s.invoke(ctx, "GetOutgoingTransfer", transferID)
+}
+
+// CreatePayrollRun schedules a payroll run for an employer account
+func (s *SmartContract) CreatePayrollRun(ctx contractapi.TransactionContextInterface, runJSON string) (string, error) {
+	return s.invoke(ctx, "CreatePayrollRun", runJSON)
+}
+
+// ExecutePayrollRun pays all employees of a scheduled run in a single
+// transaction once the pay date is reached and the employer account is funded
+func (s *SmartContract) ExecutePayrollRun(ctx contractapi.TransactionContextInterface, employerCustomerID string, accountID string, runID string) (string, error) {
+	return s.invoke(ctx, "ExecutePayrollRun", employerCustomerID, accountID, runID)
+}
+
+// CancelPayrollRun withdraws a scheduled payroll run
+func (s *SmartContract) CancelPayrollRun(ctx contractapi.TransactionContextInterface, employerCustomerID string, accountID string, runID string) (string, error) {
+	return s.invoke(ctx, "CancelPayrollRun", employerCustomerID, accountID, runID)
+}
+
+// GetPayrollReport query the report of a payroll run
+func (s *SmartContract) GetPayrollReport(ctx contractapi.TransactionContextInterface, employerCustomerID string, accountID string, runID string) (string, error) {
+	return s.invoke(ctx, "GetPayrollReport", employerCustomerID, accountID, runID)
+}
+
+// GetPayrollHistory query all payroll runs of an employer account
+func (s *SmartContract) GetPayrollHistory(ctx contractapi.TransactionContextInterface, employerCustomerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetPayrollHistory", employerCustomerID, accountID)
+}
+
+// SetWithholdingRule creates or replaces the withholding rule of a corridor and
+// purpose code
+func (s *SmartContract) SetWithholdingRule(ctx contractapi.TransactionContextInterface, ruleJSON string) (string, error) {
+	return s.invoke(ctx, "SetWithholdingRule", ruleJSON)
+}
+
+// SetPointsProgram creates or replaces the loyalty program of a currency
+func (s *SmartContract) SetPointsProgram(ctx contractapi.TransactionContextInterface, programJSON string) (string, error) {
+	return s.invoke(ctx, "SetPointsProgram", programJSON)
+}
+
+// GetPointsProgram query the loyalty program of a currency
+func (s *SmartContract) GetPointsProgram(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "GetPointsProgram", currency)
+}
+
+// GetPointsBalance query the loyalty points balance of a customer
+func (s *SmartContract) GetPointsBalance(ctx contractapi.TransactionContextInterface, customerID string) (string, error) {
+	return s.invoke(ctx, "GetPointsBalance", customerID)
+}
+
+// RedeemPoints converts loyalty points into a credit on one of the customer's
+// accounts at the program's redemption rate, funded by the program account
+func (s *SmartContract) RedeemPoints(ctx contractapi.TransactionContextInterface, customerID string, accountID string, points int64) (string, error) {
+	return s.invoke(ctx, "RedeemPoints", customerID, accountID, strconv.FormatInt(points, 10))
+}
+
+// PublishReserveAttestation records an auditor's signed statement of the
+// reserves backing an emitted currency
+func (s *SmartContract) PublishReserveAttestation(ctx contractapi.TransactionContextInterface, attestationJSON string) (string, error) {
+	return s.invoke(ctx, "PublishReserveAttestation", attestationJSON)
+}
+
+// GetReserveStatus query the latest reserve attestation of a currency together
+// with its circulating supply
+func (s *SmartContract) GetReserveStatus(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "GetReserveStatus", currency)
+}
+
+// UpdateReserve records the off-chain reserves backing a currency, proven by
+// a published attestation
+func (s *SmartContract) UpdateReserve(ctx contractapi.TransactionContextInterface, currency string, amount string, attestationAsOf string) (string, error) {
+	return s.invoke(ctx, "UpdateReserve", currency, amount, attestationAsOf)
+}
+
+// GetReserveRatio query the recorded reserves of a currency against its
+// emitted supply
+func (s *SmartContract) GetReserveRatio(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "GetReserveRatio", currency)
+}
+
+// SetNostroAccount designates the nostro account a bank funds settlements from
+// in a currency
+func (s *SmartContract) SetNostroAccount(ctx contractapi.TransactionContextInterface, bankID string, nostroCustomerID string, nostroAccountID string) (string, error) {
+	return s.invoke(ctx, "SetNostroAccount", bankID, nostroCustomerID, nostroAccountID)
+}
+
+// GetTreasuryPosition query a bank's positions by currency, with nostro
+// balances read from the ledger
+func (s *SmartContract) GetTreasuryPosition(ctx contractapi.TransactionContextInterface, bankID string) (string, error) {
+	return s.invoke(ctx, "GetTreasuryPosition", bankID)
+}
+
+// RunNetting nets the pending interbank obligations of a value date into a
+// settlement batch
+func (s *SmartContract) RunNetting(ctx contractapi.TransactionContextInterface, valueDate string) (string, error) {
+	return s.invoke(ctx, "RunNetting", valueDate)
+}
+
+// GetSettlementBatches query the settlement batches of a value date
+func (s *SmartContract) GetSettlementBatches(ctx contractapi.TransactionContextInterface, valueDate string) (string, error) {
+	return s.invoke(ctx, "GetSettlementBatches", valueDate)
+}
+
+// IssueGuarantee issues a bank guarantee in favour of a beneficiary
+func (s *SmartContract) IssueGuarantee(ctx contractapi.TransactionContextInterface, guaranteeJSON string) (string, error) {
+	return s.invoke(ctx, "IssueGuarantee", guaranteeJSON)
+}
+
+// ClaimGuarantee pays the beneficiary up to the remaining guaranteed amount,
+// from the applicant account first and from the issuing bank for any shortfall
+func (s *SmartContract) ClaimGuarantee(ctx contractapi.TransactionContextInterface, guaranteeID string, amount int64) (string, error) {
+	return s.invoke(ctx, "ClaimGuarantee", guaranteeID, strconv.FormatInt(amount, 10))
+}
+
+// ExpireGuarantee marks a guarantee past its expiry date as expired
+func (s *SmartContract) ExpireGuarantee(ctx contractapi.TransactionContextInterface, guaranteeID string) (string, error) {
+	return s.invoke(ctx, "ExpireGuarantee", guaranteeID)
+}
+
+// GetGuarantee query a guarantee by ID
+func (s *SmartContract) GetGuarantee(ctx contractapi.TransactionContextInterface, guaranteeID string) (string, error) {
+	return s.invoke(ctx, "GetGuarantee", guaranteeID)
+}
+
+// CreateAssetToken registers a tokenized asset and assigns all units to its
+// issuer
+func (s *SmartContract) CreateAssetToken(ctx contractapi.TransactionContextInterface, tokenJSON string) (string, error) {
+	return s.invoke(ctx, "CreateAssetToken", tokenJSON)
+}
+
+// GetAssetToken query an asset token by ID
+func (s *SmartContract) GetAssetToken(ctx contractapi.TransactionContextInterface, tokenID string) (string, error) {
+	return s.invoke(ctx, "GetAssetToken", tokenID)
+}
+
+// GetAssetHolding query the units of an asset token held by a customer
+func (s *SmartContract) GetAssetHolding(ctx contractapi.TransactionContextInterface, tokenID string, customerID string) (string, error) {
+	return s.invoke(ctx, "GetAssetHolding", tokenID, customerID)
+}
+
+// TransferAsset moves asset units between customers free of payment
+func (s *SmartContract) TransferAsset(ctx contractapi.TransactionContextInterface, tokenID string, fromCustomerID string, toCustomerID string, units int64) (string, error) {
+	return s.invoke(ctx, "TransferAsset", tokenID, fromCustomerID, toCustomerID, strconv.FormatInt(units, 10))
+}
+
+// AtomicDvP delivers asset units from seller to buyer against payment from
+// buyer to seller in a single transaction, so neither leg settles without the
+// other
+func (s *SmartContract) AtomicDvP(ctx contractapi.TransactionContextInterface, instructionJSON string) (string, error) {
+	return s.invoke(ctx, "AtomicDvP", instructionJSON)
+}
+
+// OpenRepo records a repo agreement and settles its opening leg, delivering the
+// collateral to the lender against the cash amount in one transaction
+func (s *SmartContract) OpenRepo(ctx contractapi.TransactionContextInterface, repoJSON string) (string, error) {
+	return s.invoke(ctx, "OpenRepo", repoJSON)
+}
+
+// CloseRepo settles the closing leg of an open repo, returning the collateral
+// to the borrower against the repurchase price
+func (s *SmartContract) CloseRepo(ctx contractapi.TransactionContextInterface, repoID string) (string, error) {
+	return s.invoke(ctx, "CloseRepo", repoID)
+}
+
+// GetRepo query a repo agreement by ID
+func (s *SmartContract) GetRepo(ctx contractapi.TransactionContextInterface, repoID string) (string, error) {
+	return s.invoke(ctx, "GetRepo", repoID)
+}
+
+// SetSweepRule configures the sweep rule of an account, replacing any existing
+// rule
+func (s *SmartContract) SetSweepRule(ctx contractapi.TransactionContextInterface, ruleJSON string) (string, error) {
+	return s.invoke(ctx, "SetSweepRule", ruleJSON)
+}
+
+// GetSweepRule query the sweep rule of an account
+func (s *SmartContract) GetSweepRule(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetSweepRule", customerID, accountID)
+}
+
+// RemoveSweepRule deletes the sweep rule of an account
+func (s *SmartContract) RemoveSweepRule(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "RemoveSweepRule", customerID, accountID)
+}
+
+// RunSweeps runs every sweep rule that is due, moving the excess above the
+// target balance to the concentration account or funding a deficit from it
+func (s *SmartContract) RunSweeps(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "RunSweeps")
+}
+
+// RegisterHandle claims a handle for one of the caller's accounts
+func (s *SmartContract) RegisterHandle(ctx contractapi.TransactionContextInterface, handleJSON string) (string, error) {
+	return s.invoke(ctx, "RegisterHandle", handleJSON)
+}
+
+// ResolveHandle query the display name registered for a handle
+func (s *SmartContract) ResolveHandle(ctx contractapi.TransactionContextInterface, handle string) (string, error) {
+	return s.invoke(ctx, "ResolveHandle", handle)
+}
+
+// P2PSend pays a small amount from the caller's handle to another handle once
+// the sender has confirmed the recipient's display name
+func (s *SmartContract) P2PSend(ctx contractapi.TransactionContextInterface, paymentJSON string) (string, error) {
+	return s.invoke(ctx, "P2PSend", paymentJSON)
+}
+
+// RequestP2PPayment asks another handle to pay the caller's handle
+func (s *SmartContract) RequestP2PPayment(ctx contractapi.TransactionContextInterface, requestJSON string) (string, error) {
+	return s.invoke(ctx, "RequestP2PPayment", requestJSON)
+}
+
+// PayP2PRequest settles a pending request addressed to the caller's handle
+func (s *SmartContract) PayP2PRequest(ctx contractapi.TransactionContextInterface, payerHandle string, requestID string, displayName string) (string, error) {
+	return s.invoke(ctx, "PayP2PRequest", payerHandle, requestID, displayName)
+}
+
+// DeclineP2PRequest refuses a pending request addressed to the caller's handle
+func (s *SmartContract) DeclineP2PRequest(ctx contractapi.TransactionContextInterface, payerHandle string, requestID string) (string, error) {
+	return s.invoke(ctx, "DeclineP2PRequest", payerHandle, requestID)
+}
+
+// GetP2PRequests query the payment requests addressed to a handle
+func (s *SmartContract) GetP2PRequests(ctx contractapi.TransactionContextInterface, payerHandle string) (string, error) {
+	return s.invoke(ctx, "GetP2PRequests", payerHandle)
+}
+
+// SetRoundUpRule opts an account into rounding up its outgoing transfers for a
+// charity
+func (s *SmartContract) SetRoundUpRule(ctx contractapi.TransactionContextInterface, ruleJSON string) (string, error) {
+	return s.invoke(ctx, "SetRoundUpRule", ruleJSON)
+}
+
+// GetRoundUpRule query the round-up rule of an account
+func (s *SmartContract) GetRoundUpRule(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetRoundUpRule", customerID, accountID)
+}
+
+// RemoveRoundUpRule opts an account out of round-ups
+func (s *SmartContract) RemoveRoundUpRule(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "RemoveRoundUpRule", customerID, accountID)
+}
+
+// SetBudget creates or replaces a customer's monthly budget for a category
+func (s *SmartContract) SetBudget(ctx contractapi.TransactionContextInterface, budgetJSON string) (string, error) {
+	return s.invoke(ctx, "SetBudget", budgetJSON)
+}
+
+// RemoveBudget deletes a customer's budget for a category
+func (s *SmartContract) RemoveBudget(ctx contractapi.TransactionContextInterface, customerID string, category string) (string, error) {
+	return s.invoke(ctx, "RemoveBudget", customerID, category)
+}
+
+// GetBudgets query a customer's budgets with the current month's spend
+func (s *SmartContract) GetBudgets(ctx contractapi.TransactionContextInterface, customerID string) (string, error) {
+	return s.invoke(ctx, "GetBudgets", customerID)
+}
+
+// SetEscheatmentPolicy configures the dormancy period and unclaimed-property
+// account of a currency
+func (s *SmartContract) SetEscheatmentPolicy(ctx contractapi.TransactionContextInterface, policyJSON string) (string, error) {
+	return s.invoke(ctx, "SetEscheatmentPolicy", policyJSON)
+}
+
+// Escheat moves the balances of all accounts dormant beyond the statutory
+// period of their currency to its unclaimed-property account, keeping an audit
+// record of each
+func (s *SmartContract) Escheat(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "Escheat")
+}
+
+// ReclaimEscheated returns an escheated balance from the unclaimed-property
+// account to the customer's original account
+func (s *SmartContract) ReclaimEscheated(ctx contractapi.TransactionContextInterface, customerID string, accountID string, escheatmentID string) (string, error) {
+	return s.invoke(ctx, "ReclaimEscheated", customerID, accountID, escheatmentID)
+}
+
+// RegisterBank onboards a participant bank
+func (s *SmartContract) RegisterBank(ctx contractapi.TransactionContextInterface, bankJSON string) (string, error) {
+	return s.invoke(ctx, "RegisterBank", bankJSON)
+}
+
+// GetBank query a participant bank by BIC
+func (s *SmartContract) GetBank(ctx contractapi.TransactionContextInterface, bic string) (string, error) {
+	return s.invoke(ctx, "GetBank", bic)
+}
+
+// GetBankList query all participant banks
+func (s *SmartContract) GetBankList(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "GetBankList")
+}
+
+// RegisterParticipant onboards a participant institution in the bank registry
+func (s *SmartContract) RegisterParticipant(ctx contractapi.TransactionContextInterface, participantJSON string) (string, error) {
+	return s.invoke(ctx, "RegisterParticipant", participantJSON)
+}
+
+// ListParticipants query all participant institutions
+func (s *SmartContract) ListParticipants(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "ListParticipants")
+}
+
+// PreviewArchive query the transaction details of an account created before the
+// horizon date, with their Merkle root, so they can be exported off-chain
+func (s *SmartContract) PreviewArchive(ctx contractapi.TransactionContextInterface, customerID string, accountID string, horizonDate string) (string, error) {
+	return s.invoke(ctx, "PreviewArchive", customerID, accountID, horizonDate)
+}
+
+// ArchiveTransactions replaces the transaction details of an account created
+// before the horizon date with a summary and the Merkle root of the batch
+func (s *SmartContract) ArchiveTransactions(ctx contractapi.TransactionContextInterface, customerID string, accountID string, horizonDate string, merkleRoot string) (string, error) {
+	return s.invoke(ctx, "ArchiveTransactions", customerID, accountID, horizonDate, merkleRoot)
+}
+
+// GetTransactionArchives query the archive summaries of an account
+func (s *SmartContract) GetTransactionArchives(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetTransactionArchives", customerID, accountID)
+}
+
+// ArchiveClosedAccounts purges the accounts closed longer ago than the
+// retention period, keeping a tombstone of each
+func (s *SmartContract) ArchiveClosedAccounts(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "ArchiveClosedAccounts")
+}
+
+// GetAccountTombstone query the tombstone left in place of a purged account
+func (s *SmartContract) GetAccountTombstone(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetAccountTombstone", customerID, accountID)
+}
+
+// SetTenancyMode switches the deployment between a single shared key space and
+// per-tenant namespaces
+func (s *SmartContract) SetTenancyMode(ctx contractapi.TransactionContextInterface, mode string) (string, error) {
+	return s.invoke(ctx, "SetTenancyMode", mode)
+}
+
+// AssignTenant places the callers of an MSP in an explicit tenant namespace
+// instead of the namespace named after the MSP
+func (s *SmartContract) AssignTenant(ctx contractapi.TransactionContextInterface, mspID string, tenantID string) (string, error) {
+	return s.invoke(ctx, "AssignTenant", mspID, tenantID)
+}
+
+// GetTenant query the tenancy mode and the caller's tenant
+func (s *SmartContract) GetTenant(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "GetTenant")
+}
+
+// CreateCheckpoint records the per-currency supply, per-bank totals and a
+// digest of the account and transaction state as a known-good checkpoint
+func (s *SmartContract) CreateCheckpoint(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "CreateCheckpoint")
+}
+
+// VerifyAgainstCheckpoint query whether current state matches a checkpoint,
+// listing every difference in supply, bank totals, record counts and digests
+func (s *SmartContract) VerifyAgainstCheckpoint(ctx contractapi.TransactionContextInterface, checkpointID string) (string, error) {
+	return s.invoke(ctx, "VerifyAgainstCheckpoint", checkpointID)
+}
+
+// GetCheckpoints query all checkpoints
+func (s *SmartContract) GetCheckpoints(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "GetCheckpoints")
+}
+
+// SetExchangeRate sets the rate used to convert a base currency into a quote
+// currency
+func (s *SmartContract) SetExchangeRate(ctx contractapi.TransactionContextInterface, rateJSON string) (string, error) {
+	return s.invoke(ctx, "SetExchangeRate", rateJSON)
+}
+
+// GetExchangeRate query the exchange rate of a base and quote currency
+func (s *SmartContract) GetExchangeRate(ctx contractapi.TransactionContextInterface, baseCurrency string, quoteCurrency string) (string, error) {
+	return s.invoke(ctx, "GetExchangeRate", baseCurrency, quoteCurrency)
+}
+
+// RequestQuote prices a transfer and locks its fee and exchange rate until the quote expires
+func (s *SmartContract) RequestQuote(ctx contractapi.TransactionContextInterface, quoteJSON string) (string, error) {
+	return s.invoke(ctx, "RequestQuote", quoteJSON)
+}
+
+// GetQuote query a quote requested for a payer customer
+func (s *SmartContract) GetQuote(ctx contractapi.TransactionContextInterface, customerID string, quoteID string) (string, error) {
+	return s.invoke(ctx, "GetQuote", customerID, quoteID)
+}
+
+// AddHoliday adds a non-business day to the settlement calendar of a currency
+func (s *SmartContract) AddHoliday(ctx contractapi.TransactionContextInterface, holidayJSON string) (string, error) {
+	return s.invoke(ctx, "AddHoliday", holidayJSON)
+}
+
+// RemoveHoliday removes a day from the settlement calendar of a currency
+func (s *SmartContract) RemoveHoliday(ctx contractapi.TransactionContextInterface, currency string, date string) (string, error) {
+	return s.invoke(ctx, "RemoveHoliday", currency, date)
+}
+
+// ListHolidays query the holidays of a currency
+func (s *SmartContract) ListHolidays(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "ListHolidays", currency)
+}
+
+// PostRate records an exchange rate signed by a rate oracle and makes it the
+// current rate of its currency pair
+func (s *SmartContract) PostRate(ctx contractapi.TransactionContextInterface, attestationJSON string) (string, error) {
+	return s.invoke(ctx, "PostRate", attestationJSON)
+}
+
+// GetRateAttestation query an oracle's attestation of a rate
+func (s *SmartContract) GetRateAttestation(ctx contractapi.TransactionContextInterface, baseCurrency string, quoteCurrency string, attestationID string) (string, error) {
+	return s.invoke(ctx, "GetRateAttestation", baseCurrency, quoteCurrency, attestationID)
+}
+
+// SetRateConfig sets the maximum age of the rates transfers may be converted at
+func (s *SmartContract) SetRateConfig(ctx contractapi.TransactionContextInterface, configJSON string) (string, error) {
+	return s.invoke(ctx, "SetRateConfig", configJSON)
+}
+
+// SetInterestConfig sets the interest paid on accounts of a type in a currency
+func (s *SmartContract) SetInterestConfig(ctx contractapi.TransactionContextInterface, configJSON string) (string, error) {
+	return s.invoke(ctx, "SetInterestConfig", configJSON)
+}
+
+// GetInterestConfig query the interest config of an account type and currency
+func (s *SmartContract) GetInterestConfig(ctx contractapi.TransactionContextInterface, accountType string, currency string) (string, error) {
+	return s.invoke(ctx, "GetInterestConfig", accountType, currency)
+}
+
+// AccrueInterest credits one compounding period's interest to every eligible
+// account of a type and currency
+func (s *SmartContract) AccrueInterest(ctx contractapi.TransactionContextInterface, accountType string, currency string, period string) (string, error) {
+	return s.invoke(ctx, "AccrueInterest", accountType, currency, period)
+}
+
+// GetAccruedInterest query the interest credited to an account
+func (s *SmartContract) GetAccruedInterest(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetAccruedInterest", customerID, accountID)
+}
+
+// CreateProduct adds a product to the catalog
+func (s *SmartContract) CreateProduct(ctx contractapi.TransactionContextInterface, productJSON string) (string, error) {
+	return s.invoke(ctx, "CreateProduct", productJSON)
+}
+
+// UpdateProduct replaces the definition of a product of the catalog
+func (s *SmartContract) UpdateProduct(ctx contractapi.TransactionContextInterface, productJSON string) (string, error) {
+	return s.invoke(ctx, "UpdateProduct", productJSON)
+}
+
+// GetProduct query a product of the catalog
+func (s *SmartContract) GetProduct(ctx contractapi.TransactionContextInterface, productID string) (string, error) {
+	return s.invoke(ctx, "GetProduct", productID)
+}
+
+// GetProductList query the product catalog
+func (s *SmartContract) GetProductList(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "GetProductList")
+}
+
+// GetAccountHistory query the committed changes of an account record from the
+// ledger history, including the ID and timestamp of each writing transaction
+func (s *SmartContract) GetAccountHistory(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetAccountHistory", customerID, accountID)
+}
+
+// GetStatement query the transactions of an account between two dates, both
+// inclusive, with the opening, closing and running balances
+func (s *SmartContract) GetStatement(ctx contractapi.TransactionContextInterface, customerID string, accountID string, fromDate string, toDate string) (string, error) {
+	return s.invoke(ctx, "GetStatement", customerID, accountID, fromDate, toDate)
+}
+
+// GetFinalStatement query the final statement generated when an account was closed
+func (s *SmartContract) GetFinalStatement(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetFinalStatement", customerID, accountID)
+}
+
+// CreateStandingOrder registers a recurring transfer from an account
+func (s *SmartContract) CreateStandingOrder(ctx contractapi.TransactionContextInterface, orderJSON string) (string, error) {
+	return s.invoke(ctx, "CreateStandingOrder", orderJSON)
+}
+
+// CancelStandingOrder stops an active standing order
+func (s *SmartContract) CancelStandingOrder(ctx contractapi.TransactionContextInterface, customerID string, accountID string, orderID string) (string, error) {
+	return s.invoke(ctx, "CancelStandingOrder", customerID, accountID, orderID)
+}
+
+// GetStandingOrders query all standing orders of an account
+func (s *SmartContract) GetStandingOrders(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "GetStandingOrders", customerID, accountID)
+}
+
+// ExecuteDueStandingOrders pays every active standing order whose next run date
+// has been reached
+func (s *SmartContract) ExecuteDueStandingOrders(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "ExecuteDueStandingOrders")
+}
+
+// FreezeAccount stops an active account from sending money while still letting
+// it receive
+func (s *SmartContract) FreezeAccount(ctx contractapi.TransactionContextInterface, customerID string, accountID string, reason string) (string, error) {
+	return s.invoke(ctx, "FreezeAccount", customerID, accountID, reason)
+}
+
+// UnfreezeAccount returns a frozen or dormant account to active
+func (s *SmartContract) UnfreezeAccount(ctx contractapi.TransactionContextInterface, customerID string, accountID string) (string, error) {
+	return s.invoke(ctx, "UnfreezeAccount", customerID, accountID)
+}
+
+// SetOverdraftLimit sets the amount an account balance may go below zero
+func (s *SmartContract) SetOverdraftLimit(ctx contractapi.TransactionContextInterface, customerID string, accountID string, limit int64) (string, error) {
+	return s.invoke(ctx, "SetOverdraftLimit", customerID, accountID, strconv.FormatInt(limit, 10))
+}
+
+// SetFeeSchedule sets the fees charged on transfers in a currency along a
+// corridor, replacing any existing schedule
+func (s *SmartContract) SetFeeSchedule(ctx contractapi.TransactionContextInterface, scheduleJSON string) (string, error) {
+	return s.invoke(ctx, "SetFeeSchedule", scheduleJSON)
+}
+
+// TotalSupply query the total money issued in a currency
+func (s *SmartContract) TotalSupply(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "TotalSupply", currency)
+}
+
+// GetEmissionRecords query the audit records of all mints and burns in a
+// currency
+func (s *SmartContract) GetEmissionRecords(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "GetEmissionRecords", currency)
+}
+
+// SetEmissionPolicy sets the threshold above which mints and burns in a
+// currency require quorum approval
+func (s *SmartContract) SetEmissionPolicy(ctx contractapi.TransactionContextInterface, policyJSON string) (string, error) {
+	return s.invoke(ctx, "SetEmissionPolicy", policyJSON)
+}
+
+// GetEmissionPolicy query the emission policy of a currency
+func (s *SmartContract) GetEmissionPolicy(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "GetEmissionPolicy", currency)
+}
+
+// ApproveEmission records the caller's approval of an emission proposal
+func (s *SmartContract) ApproveEmission(ctx contractapi.TransactionContextInterface, currency string, proposalID string) (string, error) {
+	return s.invoke(ctx, "ApproveEmission", currency, proposalID)
+}
+
+// ExecuteEmission mints or burns an approved emission proposal
+func (s *SmartContract) ExecuteEmission(ctx contractapi.TransactionContextInterface, currency string, proposalID string) (string, error) {
+	return s.invoke(ctx, "ExecuteEmission", currency, proposalID)
+}
+
+// GetEmissionProposals query the emission proposals of a currency
+func (s *SmartContract) GetEmissionProposals(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "GetEmissionProposals", currency)
+}
+
+// GetCurrencyMetrics query the supply of a currency with the balances in
+// circulation and held in frozen accounts
+func (s *SmartContract) GetCurrencyMetrics(ctx contractapi.TransactionContextInterface, currency string) (string, error) {
+	return s.invoke(ctx, "GetCurrencyMetrics", currency)
+}
+
+// RebuildCirculation recomputes the circulation of every currency from all accounts
+func (s *SmartContract) RebuildCirculation(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "RebuildCirculation")
+}
+
+// GetRoles query the roles granted on the ledger to a client identity
+func (s *SmartContract) GetRoles(ctx contractapi.TransactionContextInterface, identity string) (string, error) {
+	return s.invoke(ctx, "GetRoles", identity)
+}
+
+// SetReportingThreshold sets the amount in a currency above which a single
+// transfer is reported to the regulator
+func (s *SmartContract) SetReportingThreshold(ctx contractapi.TransactionContextInterface, thresholdJSON string) (string, error) {
+	return s.invoke(ctx, "SetReportingThreshold", thresholdJSON)
+}
+
+// GetConfig query the deployment configuration
+func (s *SmartContract) GetConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "GetConfig")
+}
+
+// UpdateConfig replaces the deployment configuration
+func (s *SmartContract) UpdateConfig(ctx contractapi.TransactionContextInterface, configJSON string) (string, error) {
+	return s.invoke(ctx, "UpdateConfig", configJSON)
+}
+
+// EnableFeature turns a feature flag on
+func (s *SmartContract) EnableFeature(ctx contractapi.TransactionContextInterface, feature string) (string, error) {
+	return s.invoke(ctx, "EnableFeature", feature)
+}
+
+// DisableFeature turns a feature flag off
+func (s *SmartContract) DisableFeature(ctx contractapi.TransactionContextInterface, feature string) (string, error) {
+	return s.invoke(ctx, "DisableFeature", feature)
+}
+
+// ListFeatures query the feature flags and whether they are enabled
+func (s *SmartContract) ListFeatures(ctx contractapi.TransactionContextInterface) (string, error) {
+	return s.invoke(ctx, "ListFeatures")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// emitEvent adds an event to those published when the invocation succeeds.
+// Events emitted inside a failed atomically block are discarded with its
+// writes. Without a txStub underneath, the event is published at once.
+func emitEvent(stub shim.ChaincodeStubInterface, eventType string, data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("Error marshalling %s event data. Error: %s", eventType, err)
+	}
+	event := &model.Event{Type: eventType, Data: dataBytes}
+	tx := unwrapTxStub(stub)
+	if tx == nil {
+		return setEvents(stub, "", []*model.Event{event})
+	}
+	tx.events = append(tx.events, event)
+	return nil
+}
+
+// publishEvents hands the invocation's events to the peer as a single chaincode
+// event. An invocation that writes state without emitting any event publishes
+// a state.changed event so that listeners see every state change.
+func (s *txStub) publishEvents(function string) error {
+	events := s.events
+	if len(events) == 0 && len(s.writes) > 0 {
+		data, _ := json.Marshal(&model.StateChangedEvent{Keys: len(s.writes)})
+		events = []*model.Event{{Type: model.EventStateChanged, Data: data}}
+	}
+	s.events = nil
+	if len(events) == 0 {
+		return nil
+	}
+	return setEvents(s, function, events)
+}
+
+func setEvents(stub shim.ChaincodeStubInterface, function string, events []*model.Event) error {
+	context := txContext(stub)
+	envelope := &model.EventEnvelope{
+		SchemaVersion: model.EventSchemaVersion,
+		TxID:          stub.GetTxID(),
+		Function:      function,
+		Timestamp:     context.Time.Unix(),
+		Traceparent:   context.Traceparent,
+		Events:        events,
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("Error marshalling event envelope. Error: %s", err)
+	}
+	return stub.SetEvent(envelope.Name(), payload)
+}
+
+// emitAccountEvent emits an account event carrying the account's current state
+func emitAccountEvent(stub shim.ChaincodeStubInterface, eventType string, a *model.Account) error {
+	return emitEvent(stub, eventType, model.NewAccountEvent(a))
+}
+
+// emitTransferEvent emits a transfer event, with the reason if the transfer failed
+func emitTransferEvent(stub shim.ChaincodeStubInterface, t *model.Transfer, failure error) error {
+	event := model.NewTransferEvent(t)
+	if failure != nil {
+		event.Error = failure.Error()
+		event.FailureCode = failureCode(failure)
+		return emitEvent(stub, model.EventTransferFailed, event)
+	}
+	return emitEvent(stub, model.EventTransferCompleted, event)
+}
@@ -0,0 +1,15 @@
+module github.com/iShamSLam/chaincode
+
+go 1.17
+
+require (
+	github.com/golang/protobuf v1.5.2
+	github.com/hyperledger/fabric-chaincode-go v0.0.0-20200424173110-d7076418f212
+	github.com/hyperledger/fabric-contract-api-go v1.2.2
+	github.com/hyperledger/fabric-protos-go v0.0.0-20200424173316-dd554ba3746e
+	github.com/hyperledger/fabric-sdk-go v1.0.0
+	github.com/lib/pq v1.10.4
+	github.com/segmentio/kafka-go v0.4.47
+	google.golang.org/grpc v1.43.0
+	google.golang.org/protobuf v1.27.1
+)
@@ -2,34 +2,125 @@ package main
 
 import (
 	"fmt"
+	"runtime/debug"
+	"sort"
 
-	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 )
 
 // HandlerFunc is a chaincode API handler function type
 type HandlerFunc func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error)
 
+// RoleCheckFunc fails unless the invoking client holds one of the roles
+type RoleCheckFunc func(stub shim.ChaincodeStubInterface, roles []string) error
+
+// FeatureCheckFunc fails unless the feature flag is enabled
+type FeatureCheckFunc func(stub shim.ChaincodeStubInterface, feature string) error
+
 // FuncMap is a mapping of function name to handler function
 type FuncMap struct {
-	handlers map[string]HandlerFunc
+	handlers     map[string]HandlerFunc
+	roles        map[string][]string
+	roleCheck    RoleCheckFunc
+	features     map[string]string
+	featureCheck FeatureCheckFunc
 }
 
 // NewHandlerMap creates a new handler mapping and returns a pointer
 func NewHandlerMap() *FuncMap {
-	return &FuncMap{make(map[string]HandlerFunc)}
+	return &FuncMap{handlers: make(map[string]HandlerFunc), roles: make(map[string][]string), features: make(map[string]string)}
 }
 
-// Add registers a handler function
-func (p *FuncMap) Add(name string, handler HandlerFunc) {
+// Add registers a handler function. If roles are given, only callers holding
+// one of them may invoke the function.
+func (p *FuncMap) Add(name string, handler HandlerFunc, roles ...string) {
 	p.handlers[name] = handler
+	if len(roles) > 0 {
+		p.roles[name] = roles
+	}
+}
+
+// Registered reports whether a handler function is registered under the name
+func (p *FuncMap) Registered(name string) bool {
+	_, ok := p.handlers[name]
+	return ok
+}
+
+// SetRoleCheck sets the check applied to functions registered with roles
+func (p *FuncMap) SetRoleCheck(check RoleCheckFunc) {
+	p.roleCheck = check
 }
 
-// Handle gets a handler function by name and invokes it
+// Roles returns the roles allowed to invoke a function, nil if it is unrestricted
+func (p *FuncMap) Roles(name string) []string {
+	return p.roles[name]
+}
+
+// RequireFeature makes a registered function available only while the
+// feature flag is enabled, so that it can be deployed dark
+func (p *FuncMap) RequireFeature(name string, feature string) {
+	p.features[name] = feature
+}
+
+// SetFeatureCheck sets the check applied to functions requiring a feature
+func (p *FuncMap) SetFeatureCheck(check FeatureCheckFunc) {
+	p.featureCheck = check
+}
+
+// Features returns the functions requiring each feature flag, sorted by name
+func (p *FuncMap) Features() map[string][]string {
+	features := make(map[string][]string)
+	for name, feature := range p.features {
+		features[feature] = append(features[feature], name)
+	}
+	for _, names := range features {
+		sort.Strings(names)
+	}
+	return features
+}
+
+// Handle gets a handler function by name, checks it is enabled and the caller
+// may invoke it and invokes it
 func (p *FuncMap) Handle(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	for name, handlerFunc := range p.handlers {
-		if name == function {
-			return handlerFunc(stub, args)
+	handlerFunc, ok := p.handlers[function]
+	if !ok {
+		return nil, fmt.Errorf("Handler function with name \"%s\" not registered.", function)
+	}
+	if feature := p.features[function]; feature != "" {
+		if p.featureCheck == nil {
+			return nil, fmt.Errorf("No feature check configured for function %s", function)
+		}
+		if err := p.featureCheck(stub, feature); err != nil {
+			return nil, err
+		}
+	}
+	if roles := p.roles[function]; len(roles) > 0 {
+		if p.roleCheck == nil {
+			return nil, fmt.Errorf("No role check configured for restricted function %s", function)
+		}
+		if err := p.roleCheck(stub, roles); err != nil {
+			return nil, err
 		}
 	}
-	return nil, fmt.Errorf("Handler function with name \"%s\" not registered.", function)
+	return handlerFunc(stub, args)
+}
+
+// handlerPanic is the error returned for an invocation whose handler panicked
+type handlerPanic struct {
+	function string
+	value    interface{}
+}
+
+func (p *handlerPanic) Error() string {
+	return fmt.Sprintf("Handler function %s failed unexpectedly: %v", p.function, p.value)
+}
+
+// recoverInvocation turns a panic of the invocation of a function into an
+// error, logging the stack trace, so that a faulty handler fails its
+// transaction instead of the chaincode container. It must be deferred.
+func recoverInvocation(log *Logger, function string, res *[]byte, err *error) {
+	if r := recover(); r != nil {
+		log.Errorf("Recovered from panic in handler function %s: %v\n%s", function, r, debug.Stack())
+		*res, *err = nil, &handlerPanic{function: function, value: r}
+	}
 }
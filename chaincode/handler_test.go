@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+func TestHandleInvocationRecoversPanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		handler  HandlerFunc
+		message  string
+	}{
+		{
+			name:     "nil dereference",
+			function: "TestPanicNilAccount",
+			handler: func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+				var account *model.Account
+				if err := stub.PutState("account", []byte(`{}`)); err != nil {
+					return nil, err
+				}
+				return []byte(account.ID), nil
+			},
+			message: "nil pointer dereference",
+		},
+		{
+			name:     "panic with error",
+			function: "TestPanicError",
+			handler: func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+				panic(errors.New("ledger corrupted"))
+			},
+			message: "ledger corrupted",
+		},
+		{
+			name:     "index out of range",
+			function: "TestPanicArgs",
+			handler: func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+				return []byte(args[3]), nil
+			},
+			message: "index out of range",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerMap.Add(tt.function, tt.handler)
+			stub := shimtest.NewMockStub("finnet", nil)
+			stub.MockTransactionStart("tx1")
+			defer stub.MockTransactionEnd("tx1")
+
+			res, err := new(Chaincode).handleInvocation(stub, tt.function, []string{})
+			if res != nil {
+				t.Errorf("expected no result, got %s", res)
+			}
+			if _, ok := err.(*handlerPanic); !ok {
+				t.Fatalf("expected a handler panic error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.function) || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected error naming %s and %q, got %q", tt.function, tt.message, err)
+			}
+			if len(stub.State) != 0 {
+				t.Errorf("expected the writes of the panicking handler to be discarded, got %d keys", len(stub.State))
+			}
+		})
+	}
+}
+
+func TestHandleInvocationReturnsHandlerResult(t *testing.T) {
+	handlerMap.Add("TestEcho", func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		return []byte(strings.Join(args, ",")), nil
+	})
+	stub := shimtest.NewMockStub("finnet", nil)
+	stub.MockTransactionStart("tx1")
+	defer stub.MockTransactionEnd("tx1")
+
+	res, err := new(Chaincode).handleInvocation(stub, "TestEcho", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(res) != "a,b" {
+		t.Errorf("expected a,b, got %s", res)
+	}
+}
+
+func TestHandleInvocationRequiresFeature(t *testing.T) {
+	cc := new(Chaincode)
+	handlerMap.SetFeatureCheck(cc.requireFeature)
+	handlerMap.Add("TestDark", func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		return []byte("ok"), nil
+	})
+	handlerMap.RequireFeature("TestDark", "test_dark")
+	stub := shimtest.NewMockStub("finnet", nil)
+
+	stub.MockTransactionStart("tx1")
+	_, err := cc.handleInvocation(stub, "TestDark", []string{})
+	stub.MockTransactionEnd("tx1")
+	if err == nil || !strings.Contains(err.Error(), "test_dark") {
+		t.Fatalf("expected the disabled feature to be reported, got %v", err)
+	}
+
+	stub.MockTransactionStart("tx2")
+	config := &model.Config{Entity: model.Entity{ObjectType: model.ConfigObjectType}, Features: map[string]bool{"test_dark": true}}
+	configData, _ := json.Marshal(config)
+	if err := stub.PutState(cc.configKey(stub), configData); err != nil {
+		t.Fatal(err)
+	}
+	stub.MockTransactionEnd("tx2")
+
+	stub.MockTransactionStart("tx3")
+	defer stub.MockTransactionEnd("tx3")
+	res, err := cc.handleInvocation(stub, "TestDark", []string{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(res) != "ok" {
+		t.Errorf("expected ok, got %s", res)
+	}
+	if functions := handlerMap.Features()["test_dark"]; len(functions) != 1 || functions[0] != "TestDark" {
+		t.Errorf("expected TestDark to require test_dark, got %v", functions)
+	}
+}
+
+func TestHandleInvocationTracksCirculation(t *testing.T) {
+	cc := new(Chaincode)
+	handlerMap.Add("TestPutAccounts", func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		for _, arg := range args {
+			account := new(model.Account)
+			if err := json.Unmarshal([]byte(arg), account); err != nil {
+				return nil, err
+			}
+			account.ObjectType = model.AccountObjectType
+			if _, err := cc.putAccount(stub, account); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	stub := shimtest.NewMockStub("finnet", nil)
+	stub.TransientMap = map[string][]byte{"private_data_salt": []byte("private data salt of the test")}
+	invoke := func(txID string, accounts ...string) *model.Circulation {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		if _, err := cc.handleInvocation(stub, "TestPutAccounts", accounts); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		circulation, err := cc.getCirculation(stub, "AUD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return circulation
+	}
+
+	c := invoke("tx1",
+		`{"customer_id":"1","id":"a","currency":"AUD","balance":1000,"status":"active"}`,
+		`{"customer_id":"2","id":"b","currency":"AUD","balance":500,"status":"frozen"}`,
+		`{"customer_id":"3","id":"c","currency":"AUD","balance":0,"status":"active"}`)
+	if c.Circulating != 1500 || c.Frozen != 500 || c.TxID != "tx1" {
+		t.Errorf("expected 1500 circulating, 500 frozen as of tx1, got %+v", c)
+	}
+	c = invoke("tx2",
+		`{"customer_id":"1","id":"a","currency":"AUD","balance":800,"status":"active"}`,
+		`{"customer_id":"2","id":"b","currency":"AUD","balance":700,"status":"frozen"}`)
+	if c.Circulating != 1500 || c.Frozen != 700 || c.TxID != "tx2" {
+		t.Errorf("expected 1500 circulating, 700 frozen as of tx2, got %+v", c)
+	}
+	c = invoke("tx3",
+		`{"customer_id":"1","id":"a","currency":"AUD","balance":700,"status":"active"}`,
+		`{"customer_id":"3","id":"c","currency":"AUD","balance":100,"status":"active"}`)
+	if c.Circulating != 1500 || c.Frozen != 700 || c.TxID != "tx2" {
+		t.Errorf("expected a transfer between active accounts to leave the circulation unwritten, got %+v", c)
+	}
+}
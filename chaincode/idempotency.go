@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// idempotent wraps a state-changing handler so that clients may pass an
+// idempotency key as the argument at position keyArg. The first successful
+// invocation with a key stores its response; a replay with the same key and
+// arguments returns the stored response without invoking the handler again.
+// Keys are scoped to the calling identity: the handler authorized the caller
+// who stored the response, so no other caller may replay it or claim the key.
+// Failed invocations write nothing, so they may be retried with the same key.
+func (cc *Chaincode) idempotent(function string, keyArg int, handler HandlerFunc) HandlerFunc {
+	return func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		key := optionalArg(args, keyArg)
+		if len(args) > keyArg {
+			args = args[:keyArg]
+		}
+		if key == "" {
+			return handler(stub, args)
+		}
+		if err := model.ValidateIdempotencyKey(key); err != nil {
+			return nil, err
+		}
+		requestHash, err := hashArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		caller, err := callerID(stub)
+		if err != nil {
+			return nil, err
+		}
+		record, err := cc.getIdempotencyRecord(stub, function, caller, key)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			if record.RequestHash != requestHash {
+				return nil, fmt.Errorf("Idempotency key %s was already used with different arguments", key)
+			}
+			loggerFor(stub).Infof("Returning stored response of %s for idempotency key %s", function, key)
+			return record.Response, nil
+		}
+		response, err := handler(stub, args)
+		if err != nil {
+			return nil, err
+		}
+		record = model.CreateIdempotencyRecord(function, caller, key, requestHash, response, txContext(stub))
+		if err := cc.putIdempotencyRecord(stub, record); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+}
+
+// hashArgs returns the hex SHA-256 digest of the JSON encoded handler arguments
+func hashArgs(args []string) (string, error) {
+	argsData, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(argsData)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+func (cc *Chaincode) getIdempotencyRecord(stub shim.ChaincodeStubInterface, function string, caller string, key string) (*model.IdempotencyRecord, error) {
+	stateKey, _ := cc.createCompositeKey(stub, model.IdempotencyRecordObjectType, []string{function, caller, key})
+	recordBytes, err := stub.GetState(stateKey)
+	if err != nil {
+		loggerFor(stub).Errorf("Failed to get idempotency record. Error: %s", err)
+		return nil, err
+	}
+	if recordBytes == nil {
+		return nil, nil
+	}
+	record := new(model.IdempotencyRecord)
+	if err := bytesToStruct(recordBytes, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (cc *Chaincode) putIdempotencyRecord(stub shim.ChaincodeStubInterface, record *model.IdempotencyRecord) error {
+	recordData, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("Error marshalling idempotency record data. Error: %s", err)
+	}
+	stateKey, _ := cc.createCompositeKey(stub, record.GetObjectType(), []string{record.Function, record.Caller, record.Key})
+	return stub.PutState(stateKey, recordData)
+}
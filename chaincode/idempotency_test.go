@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestIdempotencyKeysAreScopedToTheCaller(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 10000)
+	stub.Topup(t, "1002", "1", 10000)
+	payer, other := testsupport.Customer(t, "1001"), testsupport.Customer(t, "1002")
+	transfer := testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON()
+
+	response := stub.As(payer).MustCall(t, "TransferMoney", transfer, "k1")
+	if replayed := stub.MustCall(t, "TransferMoney", transfer, "k1"); string(replayed) != string(response) {
+		t.Errorf("Expected the stored response replayed, got %s", replayed)
+	}
+	if balance := balanceOf(t, stub, "1001", "1"); balance != 9000 {
+		t.Errorf("Expected the replay not applied again, got a balance of %d", balance)
+	}
+
+	// another identity replaying the key runs the request as its own and is authorized afresh
+	if replayed, err := stub.As(other).Call("TransferMoney", transfer, "k1"); err == nil || !strings.Contains(err.Error(), "Caller is not authorized to transfer for customer 1001") {
+		t.Errorf("Expected the stored response withheld from another caller, got %s, %v", replayed, err)
+	}
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1002", "1", "1001", "1", 500).JSON(), "k1")
+	if _, err := stub.As(payer).Call("TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 2000).JSON(), "k1"); err == nil || !strings.Contains(err.Error(), "already used with different arguments") {
+		t.Errorf("Expected the key still bound to the first request of its caller, got %v", err)
+	}
+	if payer, payee := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); payer != 9500 || payee != 10500 {
+		t.Errorf("Expected one transfer each way, got balances of %d and %d", payer, payee)
+	}
+}
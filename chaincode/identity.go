@@ -0,0 +1,166 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// roleAttribute is the client certificate attribute holding the caller's FinNet role
+const roleAttribute = auth.RoleAttribute
+
+const (
+	// RoleRateAdmin may publish benchmark reference rates
+	RoleRateAdmin = "rate_admin"
+	// RoleAuditor may publish reserve attestations
+	RoleAuditor = "auditor"
+	// RoleEscheatmentOfficer may escheat dormant balances and return them to their owners
+	RoleEscheatmentOfficer = "escheatment_officer"
+	// RoleNetworkOperator may register and suspend participant banks
+	RoleNetworkOperator = "network_operator"
+	// RoleRecordsAdmin may archive aged transaction detail
+	RoleRecordsAdmin = "records_admin"
+	// RoleComplianceOfficer may freeze, unfreeze and reactivate accounts
+	RoleComplianceOfficer = "compliance_officer"
+	// RoleCreditOfficer may set account overdraft limits
+	RoleCreditOfficer = "credit_officer"
+	// RoleFeeAdmin may set transfer fee schedules
+	RoleFeeAdmin = "fee_admin"
+	// RoleEmissionAuthority may mint and burn money
+	RoleEmissionAuthority = "emission_authority"
+	// RoleAccountOperator may open, close, top up and transfer from any customer's accounts
+	RoleAccountOperator = "account_operator"
+	// RoleCustomer may operate the accounts of the customer its certificate acts for
+	RoleCustomer = "customer"
+	// RoleTeller may operate customer accounts at a bank branch
+	RoleTeller = "teller"
+	// RoleRegulator may freeze and unfreeze accounts and read regulatory reports
+	RoleRegulator = "regulator"
+	// RoleIssuer may mint and burn money and create asset tokens
+	RoleIssuer = "issuer"
+	// RoleSettlementAgent may settle and reject pending transfers
+	RoleSettlementAgent = "settlement_agent"
+	// RoleTransferApprover may approve and reject transfers held for approval
+	RoleTransferApprover = "transfer_approver"
+	// RoleRateOracle may post signed exchange rates
+	RoleRateOracle = "rate_oracle"
+	// RoleInterestAdmin may set interest rates and accrue interest
+	RoleInterestAdmin = "interest_admin"
+	// RoleProductAdmin may manage the account product catalog
+	RoleProductAdmin = "product_admin"
+	// RoleDisputeOfficer may resolve disputed transfers
+	RoleDisputeOfficer = "dispute_officer"
+	// RoleRiskOfficer may set interbank exposure limits and manage collateral
+	RoleRiskOfficer = "risk_officer"
+)
+
+// knownRoles lists the roles that may be granted on the ledger
+var knownRoles = map[string]bool{
+	RoleRateAdmin: true, RoleAuditor: true, RoleEscheatmentOfficer: true, RoleNetworkOperator: true,
+	RoleRecordsAdmin: true, RoleComplianceOfficer: true, RoleCreditOfficer: true, RoleFeeAdmin: true,
+	RoleEmissionAuthority: true, RoleAccountOperator: true, RoleCustomer: true, RoleTeller: true,
+	RoleRegulator: true, RoleIssuer: true, RoleSettlementAgent: true, RoleTransferApprover: true,
+	RoleRateOracle: true, RoleInterestAdmin: true, RoleProductAdmin: true, RoleDisputeOfficer: true,
+	RoleRiskOfficer: true,
+}
+
+// defaultAuthorizer admits the owning customer, the signers of multi-signature
+// accounts for transfers, and account operators and tellers
+var defaultAuthorizer auth.Authorizer = auth.NewOwnerPolicy(RoleAccountOperator, RoleTeller)
+
+// authorize fails unless the invoking client may perform the action on the account
+func (cc *Chaincode) authorize(stub shim.ChaincodeStubInterface, action auth.Action, account *model.Account) error {
+	authorizer := cc.authorizer
+	if authorizer == nil {
+		authorizer = defaultAuthorizer
+	}
+	caller, err := auth.GetCaller(stub)
+	if err != nil {
+		return err
+	}
+	grant, err := cc.getRoleGrant(stub, caller.ID)
+	if err != nil {
+		return err
+	}
+	caller.Roles = append(caller.Roles, grant.Roles...)
+	return authorizer.Authorize(caller, action, &auth.Account{CustomerID: account.CustomerID, AccountID: account.ID, Signers: account.Signers})
+}
+
+// callerID returns the unique ID of the invoking client identity
+func callerID(stub shim.ChaincodeStubInterface) (string, error) {
+	id, err := cid.GetID(stub)
+	if err != nil {
+		return "", fmt.Errorf("Error reading caller identity. Error: %s", err)
+	}
+	return id, nil
+}
+
+// callerMSPID returns the MSP ID of the invoking client identity
+func callerMSPID(stub shim.ChaincodeStubInterface) (string, error) {
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return "", fmt.Errorf("Error reading caller MSP ID. Error: %s", err)
+	}
+	return mspID, nil
+}
+
+// requireRole fails unless the invoking client holds the role, either in its
+// certificate role attribute or granted on the ledger
+func (cc *Chaincode) requireRole(stub shim.ChaincodeStubInterface, role string) error {
+	return cc.requireAnyRole(stub, []string{role})
+}
+
+// requireAnyRole fails unless the invoking client holds one of the roles. It is
+// the role check the dispatcher applies to functions registered with roles.
+func (cc *Chaincode) requireAnyRole(stub shim.ChaincodeStubInterface, roles []string) error {
+	for _, role := range roles {
+		if cid.AssertAttributeValue(stub, roleAttribute, role) == nil {
+			return nil
+		}
+	}
+	id, err := callerID(stub)
+	if err != nil {
+		return err
+	}
+	grant, err := cc.getRoleGrant(stub, id)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if grant.Has(role) {
+			return nil
+		}
+	}
+	return fmt.Errorf("Caller is not authorized as %s", strings.Join(roles, " or "))
+}
+
+// verifyCallerSignature checks a base64 encoded ECDSA signature over the SHA-256
+// digest of message against the public key of the invoking client certificate
+func verifyCallerSignature(stub shim.ChaincodeStubInterface, message []byte, signature string) error {
+	cert, err := cid.GetX509Certificate(stub)
+	if err != nil || cert == nil {
+		return fmt.Errorf("Error reading caller certificate. Error: %v", err)
+	}
+	publicKey, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("Caller certificate does not hold an ECDSA public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("Error decoding signature. Error: %s", err)
+	}
+	digest := sha256.Sum256(message)
+	if !ecdsa.VerifyASN1(publicKey, digest[:], sig) {
+		return errors.New("Signature does not match the caller certificate")
+	}
+	return nil
+}
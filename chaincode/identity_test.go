@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/auth"
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+const testBank = `{"bic":"FINNAU2S","name":"FinNet Bank","msp_id":"` + testsupport.DefaultMSPID + `"}`
+
+const testTermsHash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestDebitPathsRejectOtherCustomers(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "2"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.As(testsupport.Operator(t, RoleNetworkOperator)).MustCall(t, "RegisterBank", testBank)
+	stub.OpenAccount(t, testsupport.NewAccount("9000", "1").AtBank("FINNAU2S"))
+	stub.Topup(t, "9000", "1", 100000)
+	stub.Topup(t, "1001", "1", 100000)
+	stub.Topup(t, "1002", "1", 100000)
+
+	owner, other := testsupport.Customer(t, "1001"), testsupport.Customer(t, "1002")
+	stub.As(testsupport.Operator(t, RoleNetworkOperator)).MustCall(t, "CreateLiquidityPool", `{"id":"pool1","currency":"AUD"}`)
+	stub.As(testsupport.Operator(t, RoleIssuer)).MustCall(t, "CreateAssetToken", `{"id":"BOND1","name":"Bond","type":"bond","issuer_customer":"1001","total_units":10}`)
+	stub.As(testsupport.Operator(t, RoleAccountOperator)).MustCall(t, "JoinLiquidityPool", "pool1", "FINNAU2S", "9000", "1", "50000")
+	stub.As(testsupport.Operator(t, RoleTeller)).MustCall(t, "OpenRepo", `{"id":"repo1","borrower_customer":"1001","borrower_account":"1","lender_customer":"1002","lender_account":"1","token_id":"BOND1","units":5,"cash_amount":1000,"currency":"AUD","repurchase_date":"2021-04-01"}`)
+	stub.As(testsupport.Operator(t, RoleAccountOperator)).MustCall(t, "IssueGuarantee", `{"id":"g1","issuing_bank":"bank1","issuer_customer":"1002","issuer_account":"1","applicant_customer":"1001","applicant_account":"1","beneficiary_customer":"1001","beneficiary_account":"2","amount":1000,"currency":"AUD","expiry":"2021-12-31","terms_hash":"`+testTermsHash+`"}`)
+	stub.As(owner)
+	stub.MustCall(t, "CreatePayrollRun", `{"id":"run1","employer_customer":"1001","employer_account":"1","pay_date":"2021-03-01","items":[{"employee_id":"e1","customer_id":"1002","account_id":"1","amount":100}]}`)
+	stub.MustCall(t, "CreateStandingOrder", `{"id":"so1","transfer":`+testsupport.NewTransfer("1001", "1", "1002", "1", 100).JSON()+`,"frequency":"monthly","start_date":"2021-04-01"}`)
+	stub.MustCall(t, "SetSweepRule", `{"customer_id":"1001","account_id":"1","concentration_customer":"1001","concentration_account":"2","target_balance":0,"schedule":"daily"}`)
+	stub.MustCall(t, "SetRoundUpRule", `{"customer_id":"1001","account_id":"1","charity_customer":"1002","charity_account":"1"}`)
+	stub.MustCall(t, "RegisterHandle", `{"handle":"victim","customer_id":"1001","account_id":"1","display_name":"Victim"}`)
+	stub.As(other).MustCall(t, "RegisterHandle", `{"handle":"thief","customer_id":"1002","account_id":"1","display_name":"Thief"}`)
+
+	refused, nostroRefused := "Caller is not authorized to transfer for customer 1001", "Caller is not authorized to transfer for customer 9000"
+	for _, c := range []struct {
+		function string
+		args     []string
+		refused  string
+	}{
+		{"ContributeToPool", []string{"pool1", "FINNAU2S", "1000"}, nostroRefused},
+		{"WithdrawFromPool", []string{"pool1", "FINNAU2S", "1000"}, nostroRefused},
+		{"DrawFromPool", []string{"pool1", "FINNAU2S", "1000"}, nostroRefused},
+		{"RepayPool", []string{"pool1", "FINNAU2S", "1000"}, nostroRefused},
+		{"AtomicDvP", []string{`{"token_id":"BOND1","units":1,"seller_customer":"1002","seller_account":"1","buyer_customer":"1001","buyer_account":"1","price":100,"currency":"AUD"}`}, refused},
+		{"AtomicDvP", []string{`{"token_id":"BOND1","units":1,"seller_customer":"1001","seller_account":"1","buyer_customer":"1002","buyer_account":"1","price":100,"currency":"AUD"}`}, refused},
+		{"TransferAsset", []string{"BOND1", "1001", "1002", "1"}, refused},
+		{"OpenRepo", []string{`{"borrower_customer":"1002","borrower_account":"1","lender_customer":"1001","lender_account":"1","token_id":"BOND1","units":1,"cash_amount":100,"currency":"AUD","repurchase_date":"2021-04-01"}`}, refused},
+		{"CloseRepo", []string{"repo1"}, refused},
+		{"SetSweepRule", []string{`{"customer_id":"1001","account_id":"1","concentration_customer":"1002","concentration_account":"1","schedule":"daily"}`}, refused},
+		{"SetSweepRule", []string{`{"customer_id":"1002","account_id":"1","concentration_customer":"1001","concentration_account":"1","schedule":"daily"}`}, refused},
+		{"RemoveSweepRule", []string{"1001", "1"}, refused},
+		{"RegisterHandle", []string{`{"handle":"victim2","customer_id":"1001","account_id":"1","display_name":"Victim"}`}, refused},
+		{"P2PSend", []string{`{"from_handle":"victim","to_handle":"thief","amount":100,"currency":"AUD","confirm_name":"Thief"}`}, "Caller does not own handle victim"},
+		{"CreatePayrollRun", []string{`{"employer_customer":"1001","employer_account":"1","pay_date":"2021-03-01","items":[{"employee_id":"e1","customer_id":"1002","account_id":"1","amount":100}]}`}, refused},
+		{"ExecutePayrollRun", []string{"1001", "1", "run1"}, refused},
+		{"CancelPayrollRun", []string{"1001", "1", "run1"}, refused},
+		{"RedeemPoints", []string{"1001", "1", "10"}, refused},
+		{"CreateStandingOrder", []string{`{"id":"so2","transfer":` + testsupport.NewTransfer("1001", "1", "1002", "1", 100).JSON() + `,"frequency":"monthly","start_date":"2021-04-01"}`}, refused},
+		{"CancelStandingOrder", []string{"1001", "1", "so1"}, refused},
+		{"IssueGuarantee", []string{`{"issuing_bank":"bank1","issuer_customer":"1002","issuer_account":"1","applicant_customer":"1001","applicant_account":"1","beneficiary_customer":"1002","beneficiary_account":"1","amount":1000,"currency":"AUD","expiry":"2021-12-31","terms_hash":"` + testTermsHash + `"}`}, "Caller is not authorized as account_operator"},
+		{"ClaimGuarantee", []string{"g1", "500"}, "Caller is not authorized to request_payment for customer 1001"},
+		{"SetRoundUpRule", []string{`{"customer_id":"1001","account_id":"1","charity_customer":"1002","charity_account":"1"}`}, refused},
+		{"RemoveRoundUpRule", []string{"1001", "1"}, refused},
+		{"SetAccountSigners", []string{"1001", "1", `["signer"]`, "1"}, refused},
+	} {
+		t.Run(c.function, func(t *testing.T) {
+			before := committedState(stub)
+			_, err := stub.As(other).Call(c.function, c.args...)
+			if err == nil || !strings.Contains(err.Error(), c.refused) {
+				t.Errorf("Expected the other customer refused, got %v", err)
+			}
+			if !reflect.DeepEqual(committedState(stub), before) {
+				t.Errorf("Expected no writes of the refused invocation")
+			}
+		})
+	}
+}
+
+func TestJoinLiquidityPoolRequiresBankOperator(t *testing.T) {
+	stub := newTestStub()
+	stub.As(testsupport.Operator(t, RoleNetworkOperator)).MustCall(t, "RegisterBank", testBank)
+	stub.MustCall(t, "CreateLiquidityPool", `{"id":"pool1","currency":"AUD"}`)
+	stub.OpenAccount(t, testsupport.NewAccount("9000", "1").AtBank("FINNAU2S"))
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+
+	foreign, err := testsupport.NewIdentity("Org2MSP", RoleAccountOperator, map[string]string{auth.RoleAttribute: RoleAccountOperator})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		caller  *testsupport.Identity
+		nostro  string
+		refused string
+	}{
+		{testsupport.Operator(t, RoleTeller), "9000", "Caller is not authorized as account_operator"},
+		{foreign, "9000", "Caller MSP Org2MSP is not the MSP of bank FINNAU2S"},
+		{testsupport.Operator(t, RoleAccountOperator), "1001", "Nostro account 1 is not held at bank FINNAU2S"},
+	} {
+		if _, err := stub.As(c.caller).Call("JoinLiquidityPool", "pool1", "FINNAU2S", c.nostro, "1", "50000"); err == nil || !strings.Contains(err.Error(), c.refused) {
+			t.Errorf("Expected %q, got %v", c.refused, err)
+		}
+	}
+	stub.As(testsupport.Operator(t, RoleAccountOperator)).MustCall(t, "JoinLiquidityPool", "pool1", "FINNAU2S", "9000", "1", "50000")
+	stub.Topup(t, "9000", "1", 1000)
+	if _, err := stub.As(foreign).Call("ContributeToPool", "pool1", "FINNAU2S", "1000"); err == nil || !strings.Contains(err.Error(), "is not the MSP of bank FINNAU2S") {
+		t.Errorf("Expected a contribution by another MSP refused, got %v", err)
+	}
+}
+
+func TestP2PSendHeldForApproval(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+	stub.As(testsupport.Operator(t, RoleComplianceOfficer)).MustCall(t, "SetApprovalPolicy", `{"currency":"AUD","threshold":1000,"required_approvals":1}`)
+
+	payer, payee := testsupport.Customer(t, "1001"), testsupport.Customer(t, "1002")
+	stub.As(payee).MustCall(t, "RegisterHandle", `{"handle":"bob","customer_id":"1002","account_id":"1","display_name":"Bob"}`)
+	stub.As(payer).MustCall(t, "RegisterHandle", `{"handle":"alice","customer_id":"1001","account_id":"1","display_name":"Alice"}`)
+	stub.MustCall(t, "P2PSend", `{"from_handle":"alice","to_handle":"bob","amount":500,"currency":"AUD","confirm_name":"Bob"}`)
+
+	approval := new(model.TransferApproval)
+	if err := json.Unmarshal(stub.MustCall(t, "P2PSend", `{"from_handle":"alice","to_handle":"bob","amount":5000,"currency":"AUD","confirm_name":"Bob"}`), approval); err != nil {
+		t.Fatal(err)
+	}
+	if approval.Status != model.PendingApproval || approval.Transfer.Params["from_handle"] != "alice" {
+		t.Errorf("Expected the payment above the threshold held for approval, got %+v", approval)
+	}
+	if payerBalance, payeeBalance := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); payerBalance != 99500 || payeeBalance != 500 {
+		t.Errorf("Expected only the payment below the threshold settled, got %d and %d", payerBalance, payeeBalance)
+	}
+}
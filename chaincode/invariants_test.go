@@ -0,0 +1,341 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+// invariantCurrencies are the currencies of the accounts the random walks open
+var invariantCurrencies = []string{"AUD", "AUD", "USD"}
+
+// ledgerWalk drives a random sequence of account operations against a stub
+// and checks the invariants of the ledger after every step. Operations the
+// chaincode rejects are part of the walk: they must leave the ledger as it was.
+type ledgerWalk struct {
+	t         *testing.T
+	rnd       *rand.Rand
+	stub      *testsupport.Stub
+	accounts  []*testsupport.AccountFixture
+	callers   map[string]*testsupport.Identity // identities of the customers, by customer ID
+	teller    *testsupport.Identity
+	authority *testsupport.Identity
+	topups    map[string]int64 // successful topups by currency, the only money created outside the supply
+	emitted   map[string]int64 // money minted less money burned by successful operations, by currency
+	applied   map[string]int   // successful operations by name
+	selfPaid  int              // transfers from an account to itself, which must be rejected
+}
+
+// ledgerState is the committed world state of a stub, decoded by object type
+type ledgerState struct {
+	accounts     []*model.Account
+	transactions []*model.Transaction
+	emissions    []*model.EmissionRecord
+	supplies     map[string]*model.Supply
+	circulations map[string]*model.Circulation
+}
+
+func newLedgerWalk(t *testing.T, seed int64) *ledgerWalk {
+	return &ledgerWalk{
+		t:         t,
+		rnd:       rand.New(rand.NewSource(seed)),
+		stub:      newTestStub(),
+		callers:   make(map[string]*testsupport.Identity),
+		teller:    testsupport.Operator(t, RoleTeller),
+		authority: testsupport.Operator(t, RoleEmissionAuthority),
+		topups:    make(map[string]int64),
+		emitted:   make(map[string]int64),
+		applied:   make(map[string]int),
+	}
+}
+
+func TestLedgerInvariantsUnderRandomOperations(t *testing.T) {
+	seeds, steps := int64(10), 200
+	if testing.Short() {
+		seeds, steps = 3, 50
+	}
+	applied := make(map[string]int)
+	selfPaid := 0
+	for seed := int64(1); seed <= seeds; seed++ {
+		t.Run(fmt.Sprintf("seed=%d", seed), func(t *testing.T) {
+			walk := newLedgerWalk(t, seed)
+			walk.run(steps)
+			for op, n := range walk.applied {
+				applied[op] += n
+			}
+			selfPaid += walk.selfPaid
+		})
+	}
+	if selfPaid == 0 {
+		t.Errorf("Expected the walks to transfer from an account to itself at least once")
+	}
+	for _, op := range []string{"OpenAccount", "TopupAccount", "TransferMoney", "Mint", "Burn"} {
+		if applied[op] == 0 {
+			t.Errorf("Expected the walks to apply %s at least once, the invariants were not exercised", op)
+		}
+	}
+}
+
+// run takes the steps, opening two accounts first so that every operation has
+// accounts to choose from
+func (w *ledgerWalk) run(steps int) {
+	w.open()
+	w.open()
+	for step := 1; step <= steps; step++ {
+		op := w.step()
+		if failed := w.check(); len(failed) > 0 {
+			w.t.Fatalf("Invariants broken after step %d (%s, %s):\n%s", step, op, w.stub.LastTxID(), failed)
+		}
+	}
+}
+
+// step takes one random operation and returns its description
+func (w *ledgerWalk) step() string {
+	switch n := w.rnd.Intn(20); {
+	case n == 0 && len(w.accounts) < 8:
+		return w.open()
+	case n < 5:
+		account := w.pick()
+		amount := 1 + w.rnd.Int63n(100000)
+		if w.call(w.teller, "TopupAccount", account.CustomerID, account.ID, strconv.FormatInt(amount, 10)) {
+			w.topups[account.Currency] += amount
+		}
+		return fmt.Sprintf("topup %d to %s/%s", amount, account.CustomerID, account.ID)
+	case n < 13:
+		from, to := w.pick(), w.pickIn(w.pickCurrency())
+		if w.rnd.Intn(10) == 0 {
+			to = from
+		}
+		amount := 1 + w.rnd.Int63n(60000)
+		transfer := testsupport.NewTransfer(from.CustomerID, from.ID, to.CustomerID, to.ID, amount).InCurrency(from.Currency)
+		if w.call(w.customer(from.CustomerID), "TransferMoney", transfer.JSON()) && to == from {
+			w.t.Fatalf("Expected the transfer of %d from %s/%s to itself rejected", amount, from.CustomerID, from.ID)
+		}
+		if to == from {
+			w.selfPaid++
+		}
+		return fmt.Sprintf("transfer %d %s from %s/%s to %s/%s", amount, from.Currency, from.CustomerID, from.ID, to.CustomerID, to.ID)
+	case n < 17:
+		account := w.pick()
+		amount := 1 + w.rnd.Int63n(100000)
+		if w.call(w.authority, "Mint", account.CustomerID, account.ID, strconv.FormatInt(amount, 10)) {
+			w.emitted[account.Currency] += amount
+		}
+		return fmt.Sprintf("mint %d to %s/%s", amount, account.CustomerID, account.ID)
+	default:
+		account := w.pick()
+		amount := 1 + w.rnd.Int63n(60000)
+		if w.call(w.authority, "Burn", account.CustomerID, account.ID, strconv.FormatInt(amount, 10)) {
+			w.emitted[account.Currency] -= amount
+		}
+		return fmt.Sprintf("burn %d from %s/%s", amount, account.CustomerID, account.ID)
+	}
+}
+
+// open opens an account for one of three customers in a random currency
+func (w *ledgerWalk) open() string {
+	customerID := strconv.Itoa(3001 + w.rnd.Intn(3))
+	account := testsupport.NewAccount(customerID, strconv.Itoa(len(w.accounts)+1)).InCurrency(w.pickCurrency())
+	w.stub.OpenAccount(w.t, account)
+	w.accounts = append(w.accounts, account)
+	w.applied["OpenAccount"]++
+	return fmt.Sprintf("open %s/%s in %s", account.CustomerID, account.ID, account.Currency)
+}
+
+func (w *ledgerWalk) pick() *testsupport.AccountFixture {
+	return w.accounts[w.rnd.Intn(len(w.accounts))]
+}
+
+func (w *ledgerWalk) pickCurrency() string {
+	return invariantCurrencies[w.rnd.Intn(len(invariantCurrencies))]
+}
+
+// pickIn picks an account in the currency, mostly, so that most transfers do
+// not need a conversion the walk has no exchange rates for
+func (w *ledgerWalk) pickIn(currency string) *testsupport.AccountFixture {
+	var candidates []*testsupport.AccountFixture
+	for _, account := range w.accounts {
+		if account.Currency == currency {
+			candidates = append(candidates, account)
+		}
+	}
+	if len(candidates) == 0 || w.rnd.Intn(10) == 0 {
+		return w.pick()
+	}
+	return candidates[w.rnd.Intn(len(candidates))]
+}
+
+func (w *ledgerWalk) customer(customerID string) *testsupport.Identity {
+	if _, ok := w.callers[customerID]; !ok {
+		w.callers[customerID] = testsupport.Customer(w.t, customerID)
+	}
+	return w.callers[customerID]
+}
+
+// call invokes the function as the caller and returns whether it succeeded.
+// Rejections are expected, panics are not.
+func (w *ledgerWalk) call(caller *testsupport.Identity, function string, args ...string) bool {
+	w.t.Helper()
+	_, err := w.stub.As(caller).Call(function, args...)
+	if _, ok := err.(*handlerPanic); ok {
+		w.t.Fatalf("%s panicked with args %q: %s", function, args, err)
+	}
+	if err == nil {
+		w.applied[function]++
+	}
+	return err == nil
+}
+
+// state decodes the committed world state of the stub
+func (w *ledgerWalk) state() *ledgerState {
+	state := &ledgerState{supplies: make(map[string]*model.Supply), circulations: make(map[string]*model.Circulation)}
+	keys := make([]string, 0, len(w.stub.State))
+	for key := range w.stub.State {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		objectType, _, err := w.stub.SplitCompositeKey(key)
+		if err != nil {
+			continue
+		}
+		data := w.stub.State[key]
+		switch objectType {
+		case model.AccountObjectType:
+			account := new(model.Account)
+			err = json.Unmarshal(data, account)
+			state.accounts = append(state.accounts, account)
+		case model.TransactionObjectType:
+			txn := new(model.Transaction)
+			err = json.Unmarshal(data, txn)
+			state.transactions = append(state.transactions, txn)
+		case model.EmissionRecordObjectType:
+			record := new(model.EmissionRecord)
+			err = json.Unmarshal(data, record)
+			state.emissions = append(state.emissions, record)
+		case model.SupplyObjectType:
+			supply := new(model.Supply)
+			err = json.Unmarshal(data, supply)
+			state.supplies[supply.CurrencyCode] = supply
+		case model.CirculationObjectType:
+			circulation := new(model.Circulation)
+			err = json.Unmarshal(data, circulation)
+			state.circulations[circulation.CurrencyCode] = circulation
+		}
+		if err != nil {
+			w.t.Fatalf("Error decoding %s record %q. Error: %s", objectType, key, err)
+		}
+	}
+	return state
+}
+
+// check returns the invariants the committed ledger breaks, one per line:
+//   - the balances of the accounts in a currency add up to its supply and the
+//     money topped up, and to its circulation
+//   - no account is below its overdraft limit or holds a negative amount
+//   - the supply of a currency is the sum of its emission records, and the
+//     money the walk minted less the money it burned
+//   - in every ledger transaction, the money credited less the money debited
+//     is the money it minted less the money it burned
+//   - every debit of a transfer has the credit of its counterparty in the same
+//     ledger transaction
+func (w *ledgerWalk) check() string {
+	state := w.state()
+	var failed []string
+	fail := func(format string, args ...interface{}) {
+		failed = append(failed, fmt.Sprintf(format, args...))
+	}
+
+	balances := make(map[string]int64)
+	for _, account := range state.accounts {
+		balances[account.CurrencyCode] += account.Balance
+		if account.Balance < -account.Overdraft {
+			fail("account %s/%s has balance %d below its overdraft limit of %d", account.CustomerID, account.ID, account.Balance, account.Overdraft)
+		}
+		if account.Held < 0 {
+			fail("account %s/%s holds %d", account.CustomerID, account.ID, account.Held)
+		}
+	}
+	emitted := make(map[string]int64)
+	emittedIn := make(map[string]int64) // by ledger transaction and currency
+	for _, record := range state.emissions {
+		amount := record.Amount
+		if record.Operation == model.Burn {
+			amount = -amount
+		}
+		emitted[record.CurrencyCode] += amount
+		emittedIn[record.TxID+"/"+record.CurrencyCode] += amount
+	}
+	for _, currency := range []string{"AUD", "USD"} {
+		var supply, circulating int64
+		if s := state.supplies[currency]; s != nil {
+			supply = s.Total
+			if s.Total != s.Minted-s.Burned {
+				fail("%s supply total %d is not minted %d less burned %d", currency, s.Total, s.Minted, s.Burned)
+			}
+		}
+		if c := state.circulations[currency]; c != nil {
+			circulating = c.Circulating
+		}
+		if balances[currency] != supply+w.topups[currency] {
+			fail("%s balances add up to %d, expected the supply of %d and topups of %d", currency, balances[currency], supply, w.topups[currency])
+		}
+		if balances[currency] != circulating {
+			fail("%s balances add up to %d, circulation is %d", currency, balances[currency], circulating)
+		}
+		if emitted[currency] != supply {
+			fail("%s emission records add up to %d, supply is %d", currency, emitted[currency], supply)
+		}
+		if w.emitted[currency] != supply {
+			fail("%s supply is %d, the walk minted less burned %d", currency, supply, w.emitted[currency])
+		}
+	}
+
+	moved := make(map[string]int64) // by ledger transaction and currency
+	credits := make(map[string]bool)
+	for _, txn := range state.transactions {
+		switch txn.Status {
+		case model.Credited:
+			moved[txn.TxID+"/"+txn.CurrencyCode] += txn.Amount
+			credits[fmt.Sprintf("%s/%s/%s/%s/%s/%d", txn.TxID, txn.CustomerID, txn.AccountID, txn.CounterpartyCustomerID, txn.CounterpartyAccountID, txn.Amount)] = true
+		case model.Debited:
+			moved[txn.TxID+"/"+txn.CurrencyCode] -= txn.Amount + txn.Fee
+		}
+	}
+	for _, txn := range state.transactions {
+		if txn.Status != model.Debited || txn.CounterpartyAccountID == "" {
+			continue
+		}
+		if !credits[fmt.Sprintf("%s/%s/%s/%s/%s/%d", txn.TxID, txn.CounterpartyCustomerID, txn.CounterpartyAccountID, txn.CustomerID, txn.AccountID, txn.Amount)] {
+			fail("debit %s of %d from %s/%s in %s has no credit to %s/%s", txn.ID, txn.Amount, txn.CustomerID, txn.AccountID, txn.TxID, txn.CounterpartyCustomerID, txn.CounterpartyAccountID)
+		}
+	}
+	for key := range emittedIn {
+		if _, ok := moved[key]; !ok {
+			moved[key] = 0
+		}
+	}
+	keys := make([]string, 0, len(moved))
+	for key := range moved {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if moved[key] != emittedIn[key] {
+			fail("transaction %s moved %d, expected its net emission of %d", key, moved[key], emittedIn[key])
+		}
+	}
+
+	sort.Strings(failed)
+	var report string
+	for _, line := range failed {
+		report += "  " + line + "\n"
+	}
+	return report
+}
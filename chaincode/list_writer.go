@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/iShamSLam/chaincode/model"
+)
+
+// maxPooledListBuffer caps the capacity of the buffers kept for reuse, so that
+// one very large response does not pin its buffer for the life of the container
+const maxPooledListBuffer = 4 << 20
+
+// listBuffers recycles the buffers list responses are encoded into
+var listBuffers = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// errListFull is returned when an element does not fit into the response. List
+// handlers return it from their scan callback to end the page early; the next
+// page starts with the element that did not fit.
+var errListFull = errors.New("List response is full")
+
+// listWriter encodes a list response element by element into a growing
+// buffer, as {"<field>":[<element>,...],"next_bookmark":"<bookmark>"}.
+// Elements are appended as the JSON they are stored as, or encoded one at a
+// time, rather than collected and encoded with the list. Once the elements
+// reach limit bytes the list is cut off: further elements are refused with
+// errListFull and the response is flagged "truncated". The first element is
+// always accepted, so that every page makes progress.
+type listWriter struct {
+	buf       *bytes.Buffer
+	enc       *json.Encoder
+	start     int // length of the response before the elements
+	count     int
+	limit     int
+	empty     string // encoding of a list without elements
+	truncated bool
+}
+
+// newListWriter starts a list response whose elements are the value of field,
+// limited to model.MaxListResponseSize bytes of elements
+func newListWriter(field string) *listWriter {
+	buf := listBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	buf.WriteString(`{"`)
+	buf.WriteString(field)
+	buf.WriteString(`":`)
+	return &listWriter{buf: buf, enc: json.NewEncoder(buf), start: buf.Len(), limit: model.MaxListResponseSize, empty: "null"}
+}
+
+// EmptyAsArray encodes a list without elements as [] rather than null, for
+// list models that are never nil
+func (w *listWriter) EmptyAsArray() *listWriter {
+	w.empty = "[]"
+	return w
+}
+
+// Fits returns true if an element of n bytes fits into the response. An
+// element that does not fit truncates the list.
+func (w *listWriter) Fits(n int) bool {
+	if w.count > 0 && w.buf.Len()-w.start+1+n > w.limit {
+		w.truncated = true
+	}
+	return !w.truncated
+}
+
+// AddRaw appends an element that is JSON already
+func (w *listWriter) AddRaw(element []byte) error {
+	if !w.Fits(len(element)) {
+		return errListFull
+	}
+	w.separate()
+	w.buf.Write(element)
+	w.count++
+	return nil
+}
+
+// Add encodes an element into the response
+func (w *listWriter) Add(element interface{}) error {
+	if w.truncated {
+		return errListFull
+	}
+	mark := w.buf.Len()
+	w.separate()
+	if err := w.enc.Encode(element); err != nil {
+		w.buf.Truncate(mark)
+		return err
+	}
+	// the encoder ends every value with a newline
+	w.buf.Truncate(w.buf.Len() - 1)
+	if w.count > 0 && w.buf.Len()-w.start > w.limit {
+		w.buf.Truncate(mark)
+		w.truncated = true
+		return errListFull
+	}
+	w.count++
+	return nil
+}
+
+// Truncate flags the list as cut off by the handler, which ended the page
+// before its page size for the elements to fit
+func (w *listWriter) Truncate() {
+	w.truncated = true
+}
+
+// Count returns the number of elements appended
+func (w *listWriter) Count() int {
+	return w.count
+}
+
+// Bytes ends the list with the bookmark of the next page, omitted if empty,
+// and the truncation flag, and returns the response. The writer must not be
+// used afterwards.
+func (w *listWriter) Bytes(nextBookmark string) []byte {
+	if w.count == 0 {
+		w.buf.WriteString(w.empty)
+	} else {
+		w.buf.WriteByte(']')
+	}
+	if nextBookmark != "" {
+		w.buf.WriteString(`,"next_bookmark":`)
+		w.enc.Encode(nextBookmark)
+		w.buf.Truncate(w.buf.Len() - 1)
+	}
+	if w.truncated {
+		w.buf.WriteString(`,"truncated":true`)
+	}
+	w.buf.WriteByte('}')
+
+	res := make([]byte, w.buf.Len())
+	copy(res, w.buf.Bytes())
+	if w.buf.Cap() <= maxPooledListBuffer {
+		listBuffers.Put(w.buf)
+	}
+	w.buf, w.enc = nil, nil
+	return res
+}
+
+func (w *listWriter) separate() {
+	if w.count == 0 {
+		w.buf.WriteByte('[')
+	} else {
+		w.buf.WriteByte(',')
+	}
+}
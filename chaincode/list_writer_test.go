@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// listRecords is the number of accounts and of transactions the list
+// benchmarks page through
+const listRecords = 10000
+
+// newListStub returns a stub holding listRecords accounts of customer 1001 and
+// listRecords transactions of its account 1, written to the state directly
+// rather than through the handlers
+func newListStub(tb testing.TB) *testsupport.Stub {
+	tb.Helper()
+	stub := newTestStub()
+	stub.MockTransactionStart("seed")
+	defer stub.MockTransactionEnd("seed")
+	for i := 1; i <= listRecords; i++ {
+		tx := model.CreateTxContext("seed"+strconv.Itoa(i), testsupport.Epoch.Add(time.Duration(i)*time.Minute))
+		account, err := model.CreateAccount([]byte(testsupport.NewAccount("1001", strconv.Itoa(i)).JSON()), tx)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		key, _ := stub.CreateCompositeKey(model.AccountObjectType, []string{account.CustomerID, account.ID})
+		putRecord(tb, stub, key, account)
+	}
+	seedTransactions(tb, stub, listRecords, "")
+	return stub
+}
+
+// seedTransactions writes n debits of account 1001/1 with the memo to the
+// state of a stub within a transaction
+func seedTransactions(tb testing.TB, stub *testsupport.Stub, n int, memo string) {
+	tb.Helper()
+	for i := 1; i <= n; i++ {
+		tx := model.CreateTxContext("seed"+strconv.Itoa(i), testsupport.Epoch.Add(time.Duration(i)*time.Minute))
+		transfer := &model.Transfer{FromCustomerID: "1001", FromAccountID: "1", ToCustomerID: "1002", ToAccountID: "1", Amount: int64(i), CurrencyCode: "AUD", Description: "Seeded transfer", Memo: memo}
+		txn, _ := model.CreateTransaction("1001", "1", transfer, model.TxFailureCodeNone, model.Debited, tx)
+		key, _ := stub.CreateCompositeKey(model.TransactionObjectType, []string{txn.CustomerID, txn.AccountID, model.DayBucket(txn.Created), txn.ID})
+		putRecord(tb, stub, key, txn)
+	}
+}
+
+func putRecord(tb testing.TB, stub *testsupport.Stub, key string, v interface{}) {
+	tb.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := stub.PutState(key, data); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// listPage invokes a list handler for one page of the given size
+func listPage(stub *testsupport.Stub, handler func(shim.ChaincodeStubInterface, []string) ([]byte, error), args []string, pageSize int, bookmark string) ([]byte, error) {
+	args = append(append([]string{}, args...), strconv.Itoa(pageSize), bookmark)
+	return stub.Invoke(func(s shim.ChaincodeStubInterface) ([]byte, error) {
+		return handler(newPrivateStub(testChaincode, s), args)
+	})
+}
+
+func TestGetTransactionListPagesNewestFirst(t *testing.T) {
+	if testing.Short() {
+		t.Skip("seeding the ledger takes a while")
+	}
+	stub := newListStub(t)
+	seen := make(map[string]bool)
+	bookmark := ""
+	for pages := 0; ; pages++ {
+		res, err := listPage(stub, testChaincode.GetTransactionList, []string{"1001", "1"}, model.MaxListPageSize, bookmark)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := new(model.TransactionList)
+		if err := json.Unmarshal(res, list); err != nil {
+			t.Fatalf("Invalid list %.200s. Error: %s", res, err)
+		}
+		for i, txn := range list.Transactions {
+			if i > 0 && txn.Created > list.Transactions[i-1].Created {
+				t.Fatalf("Expected the page sorted newest first, got %d after %d", txn.Created, list.Transactions[i-1].Created)
+			}
+			if seen[txn.ID] {
+				t.Fatalf("Transaction %s listed twice", txn.ID)
+			}
+			seen[txn.ID] = true
+		}
+		if list.NextBookmark == "" {
+			if want := listRecords / model.MaxListPageSize; pages+1 != want {
+				t.Errorf("Expected %d pages, got %d", want, pages+1)
+			}
+			break
+		}
+		bookmark = list.NextBookmark
+	}
+	if len(seen) != listRecords {
+		t.Errorf("Expected %d transactions, got %d", listRecords, len(seen))
+	}
+}
+
+func TestGetTransactionListTruncatesAtResponseSize(t *testing.T) {
+	stub := newTestStub()
+	stub.MockTransactionStart("seed")
+	seedTransactions(t, stub, 400, strings.Repeat("m", 4096))
+	stub.MockTransactionEnd("seed")
+
+	seen := make(map[string]bool)
+	bookmark := ""
+	for pages := 1; ; pages++ {
+		res, err := listPage(stub, testChaincode.GetTransactionList, []string{"1001", "1"}, model.MaxListPageSize, bookmark)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) > model.MaxListResponseSize+1024 {
+			t.Errorf("Expected page %d within the response size limit, got %d bytes", pages, len(res))
+		}
+		list := new(model.TransactionList)
+		if err := json.Unmarshal(res, list); err != nil {
+			t.Fatal(err)
+		}
+		for _, txn := range list.Transactions {
+			if seen[txn.ID] {
+				t.Fatalf("Transaction %s listed twice", txn.ID)
+			}
+			seen[txn.ID] = true
+		}
+		if pages == 1 && (!list.Truncated || list.NextBookmark == "" || len(list.Transactions) >= 400) {
+			t.Errorf("Expected the first page truncated with a bookmark, got %d transactions, truncated %t", len(list.Transactions), list.Truncated)
+		}
+		if list.NextBookmark == "" {
+			if list.Truncated {
+				t.Errorf("Expected the last page not truncated")
+			}
+			break
+		}
+		bookmark = list.NextBookmark
+	}
+	if len(seen) != 400 {
+		t.Errorf("Expected the pages to continue where the truncated page ended, got %d of 400 transactions", len(seen))
+	}
+}
+
+func TestGetTransactionListBetweenDays(t *testing.T) {
+	stub := newTestStub()
+	stub.MockTransactionStart("seed")
+	// a transaction a minute from 9:01 on March 1st, 1440 of them on March 2nd
+	seedTransactions(t, stub, 3000, "")
+	stub.MockTransactionEnd("seed")
+
+	list := func(bookmark string, from string, to string) (*model.TransactionList, error) {
+		res, err := stub.Invoke(func(s shim.ChaincodeStubInterface) ([]byte, error) {
+			return testChaincode.GetTransactionList(newPrivateStub(testChaincode, s), []string{"1001", "1", strconv.Itoa(model.MaxListPageSize), bookmark, from, to})
+		})
+		if err != nil {
+			return nil, err
+		}
+		list := new(model.TransactionList)
+		return list, json.Unmarshal(res, list)
+	}
+	count := func(from string, to string) int {
+		n := 0
+		for bookmark := ""; ; {
+			page, err := list(bookmark, from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, txn := range page.Transactions {
+				if day := model.DayBucket(txn.Created); (from != "" && day < strings.Replace(from, "-", "", -1)) || (to != "" && day > strings.Replace(to, "-", "", -1)) {
+					t.Fatalf("Expected transactions from %s to %s, got one of %s", from, to, day)
+				}
+			}
+			n += len(page.Transactions)
+			if bookmark = page.NextBookmark; bookmark == "" {
+				return n
+			}
+		}
+	}
+	for _, c := range []struct {
+		from, to string
+		want     int
+	}{
+		{"2021-03-02", "2021-03-02", 1440},
+		{"", "2021-03-01", 899},
+		{"2021-03-03", "", 661},
+		{"2021-03-04", "", 0},
+	} {
+		if n := count(c.from, c.to); n != c.want {
+			t.Errorf("Expected %d transactions from %q to %q, got %d", c.want, c.from, c.to, n)
+		}
+	}
+	for _, days := range [][2]string{{"2021-03-03", "2021-03-02"}, {"March 2nd", ""}} {
+		if _, err := list("", days[0], days[1]); err == nil {
+			t.Errorf("Expected days %q rejected", days)
+		}
+	}
+}
+
+func TestListWriterRollsBackElementBeyondLimit(t *testing.T) {
+	list := newListWriter("values")
+	list.limit = 10
+	if err := list.Add("first value beyond the limit"); err != nil {
+		t.Fatalf("Expected the first element accepted, got %v", err)
+	}
+	if err := list.Add("second"); err != errListFull {
+		t.Errorf("Expected the list full, got %v", err)
+	}
+	if res := string(list.Bytes("")); res != `{"values":["first value beyond the limit"],"truncated":true}` {
+		t.Errorf("Unexpected response %s", res)
+	}
+}
+
+func TestGetAccountListOfCustomerWithoutAccounts(t *testing.T) {
+	stub := newTestStub()
+	res, err := listPage(stub, testChaincode.GetAccountList, []string{"1001"}, model.DefaultListPageSize, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != `{"accounts":null}` {
+		t.Errorf("Expected an empty list, got %s", res)
+	}
+}
+
+func BenchmarkGetTransactionList(b *testing.B) {
+	benchmarkList(b, testChaincode.GetTransactionList, "1001", "1")
+}
+
+func BenchmarkGetAccountList(b *testing.B) {
+	benchmarkList(b, testChaincode.GetAccountList, "1001")
+}
+
+// benchmarkList measures a list handler reading the first page of the largest
+// size, and reading all listRecords records page by page
+func benchmarkList(b *testing.B, handler func(shim.ChaincodeStubInterface, []string) ([]byte, error), args ...string) {
+	stub := newListStub(b)
+	b.Run("page", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := listPage(stub, handler, args, model.MaxListPageSize, ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("all", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bookmark := ""
+			for {
+				res, err := listPage(stub, handler, args, model.MaxListPageSize, bookmark)
+				if err != nil {
+					b.Fatal(err)
+				}
+				page := struct {
+					NextBookmark string `json:"next_bookmark"`
+				}{}
+				if err := json.Unmarshal(res, &page); err != nil {
+					b.Fatal(err)
+				}
+				if page.NextBookmark == "" {
+					break
+				}
+				bookmark = page.NextBookmark
+			}
+		}
+	})
+}
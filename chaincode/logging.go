@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// LogLevel is the severity of a log message
+type LogLevel int
+
+const (
+	// LogDebug detailed tracing of handler calls
+	LogDebug LogLevel = iota
+	// LogInfo notable events
+	LogInfo
+	// LogWarning recoverable problems
+	LogWarning
+	// LogError failed operations
+	LogError
+)
+
+var logLevelNames = map[string]LogLevel{
+	"DEBUG":   LogDebug,
+	"INFO":    LogInfo,
+	"WARNING": LogWarning,
+	"ERROR":   LogError,
+}
+
+// logLevelTags are the level names of text output, by level
+var logLevelTags = []string{"DEBU", "INFO", "WARN", "ERRO"}
+
+// String returns the name of the level, e.g. DEBUG
+func (level LogLevel) String() string {
+	for name, l := range logLevelNames {
+		if l == level {
+			return name
+		}
+	}
+	return fmt.Sprintf("LEVEL%d", int(level))
+}
+
+// redactedValue replaces the values of sensitive fields in logged messages
+const redactedValue = "[REDACTED]"
+
+// redactedFields matches the JSON fields holding personal data or balances,
+// with a plain or an escaped value, as in messages quoting a record with %q.
+// Personal data are the private fields of the private models.
+var redactedFields = redactedFieldPattern()
+
+func redactedFieldPattern() *regexp.Regexp {
+	names := []string{"held", "overdraft_limit", "available", `[a-z_]*balance`}
+	for _, m := range model.PrivateModels {
+		for _, name := range m.PrivateFields() {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+	}
+	sort.Strings(names)
+	return regexp.MustCompile(`(\\?")(` + strings.Join(names, "|") + `)\\?"\s*:\s*(?:\\?"(?:[^"\\]|\\[^"])*\\?"|-?[0-9][0-9.eE+-]*|true|false|null)`)
+}
+
+// redact replaces the values of the personal data and balance fields of the
+// JSON records quoted in a message
+func redact(msg string) string {
+	return redactedFields.ReplaceAllString(msg, "${1}${2}${1}:${1}"+redactedValue+"${1}")
+}
+
+// Logger is a leveled logger writing to the chaincode container's standard
+// error, which the peer collects. The v0.6 shim provided one, the
+// fabric-chaincode-go shim leaves logging to the chaincode.
+//
+// Messages are written as text or as one JSON object per line. Loggers
+// derived with With or ForInvocation share the output, format and levels of
+// the logger they are derived from and add their fields to each message.
+// Messages logged at Info and above have the personal data and balances of
+// quoted records redacted; Debug messages are logged as they are.
+type Logger struct {
+	name     string
+	config   *logConfig
+	function string // handler function whose level applies, if any
+	fields   []logField
+}
+
+// logField is a key / value pair added to each message of a logger
+type logField struct {
+	key   string
+	value string
+}
+
+// logConfig is shared by a logger and the loggers derived from it
+type logConfig struct {
+	mu            sync.Mutex
+	out           io.Writer
+	level         LogLevel
+	handlerLevels map[string]LogLevel // level by handler function, overriding level
+	json          bool
+}
+
+// NewLogger creates a logger prefixing messages with the name
+func NewLogger(name string) *Logger {
+	return &Logger{name: name, config: &logConfig{out: os.Stderr, level: LogInfo, handlerLevels: map[string]LogLevel{}}}
+}
+
+// SetLevel sets the lowest level logged
+func (l *Logger) SetLevel(level LogLevel) {
+	l.config.mu.Lock()
+	defer l.config.mu.Unlock()
+	l.config.level = level
+}
+
+// SetHandlerLevel sets the lowest level logged by invocations of a handler
+// function, overriding the level set with SetLevel
+func (l *Logger) SetHandlerLevel(function string, level LogLevel) {
+	l.config.mu.Lock()
+	defer l.config.mu.Unlock()
+	l.config.handlerLevels[function] = level
+}
+
+// SetFormat sets the output format, "text" (the default) or "json"
+func (l *Logger) SetFormat(format string) error {
+	var asJSON bool
+	switch strings.ToLower(format) {
+	case "text":
+	case "json":
+		asJSON = true
+	default:
+		return fmt.Errorf("Invalid log format %s", format)
+	}
+	l.config.mu.Lock()
+	defer l.config.mu.Unlock()
+	l.config.json = asJSON
+	return nil
+}
+
+// SetOutput sets the writer messages are written to
+func (l *Logger) SetOutput(out io.Writer) {
+	l.config.mu.Lock()
+	defer l.config.mu.Unlock()
+	l.config.out = out
+}
+
+// With returns a logger adding the key and value to each message
+func (l *Logger) With(key, value string) *Logger {
+	derived := *l
+	derived.fields = append(append([]logField{}, l.fields...), logField{key, value})
+	return &derived
+}
+
+// ForInvocation returns the logger of an invocation of a handler function,
+// adding the function and the transaction ID to each message and logging at
+// the level set for the function
+func (l *Logger) ForInvocation(function, txID string) *Logger {
+	derived := l.With("function", function).With("tx_id", txID)
+	derived.function = function
+	return derived
+}
+
+// ParseLogLevel parses a level name such as DEBUG, case insensitively
+func ParseLogLevel(name string) (LogLevel, error) {
+	level, ok := logLevelNames[strings.ToUpper(name)]
+	if !ok {
+		return LogInfo, fmt.Errorf("Invalid log level %s", name)
+	}
+	return level, nil
+}
+
+// ParseHandlerLevels parses a comma separated list of handler function levels
+// such as "TransferMoney=DEBUG,GetAccount=WARNING"
+func ParseHandlerLevels(spec string) (map[string]LogLevel, error) {
+	levels := map[string]LogLevel{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("Invalid handler log level %s, expected function=LEVEL", entry)
+		}
+		level, err := ParseLogLevel(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(parts[0])] = level
+	}
+	return levels, nil
+}
+
+// Debugf logs a debug message
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LogDebug, format, args...)
+}
+
+// Infof logs an informational message
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LogInfo, format, args...)
+}
+
+// Warningf logs a warning
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.logf(LogWarning, format, args...)
+}
+
+// Errorf logs an error
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(LogError, format, args...)
+}
+
+func (l *Logger) logf(level LogLevel, format string, args ...interface{}) {
+	c := l.config
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	threshold := c.level
+	if handlerLevel, ok := c.handlerLevels[l.function]; ok && l.function != "" {
+		threshold = handlerLevel
+	}
+	if level < threshold {
+		return
+	}
+	msg := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	if level >= LogInfo {
+		msg = redact(msg)
+	}
+	now := time.Now()
+	var line strings.Builder
+	if c.json {
+		writeJSONField(&line, "{", "time", now.UTC().Format(time.RFC3339Nano))
+		writeJSONField(&line, ",", "level", level.String())
+		writeJSONField(&line, ",", "logger", l.name)
+		writeJSONField(&line, ",", "msg", msg)
+		for _, f := range l.fields {
+			writeJSONField(&line, ",", f.key, f.value)
+		}
+		line.WriteString("}\n")
+	} else {
+		fmt.Fprintf(&line, "%s %s %s %s", l.name, now.Format("2006/01/02 15:04:05.000000"), logLevelTags[level], msg)
+		for _, f := range l.fields {
+			fmt.Fprintf(&line, " %s=%s", f.key, f.value)
+		}
+		line.WriteString("\n")
+	}
+	io.WriteString(c.out, line.String())
+}
+
+func writeJSONField(line *strings.Builder, separator, key, value string) {
+	keyData, _ := json.Marshal(key)
+	valueData, _ := json.Marshal(value)
+	line.WriteString(separator)
+	line.Write(keyData)
+	line.WriteString(":")
+	line.Write(valueData)
+}
+
+// loggerFor returns the logger of the invocation the stub belongs to, adding
+// the handler function and transaction ID to each message, or the chaincode
+// logger outside of an invocation
+func loggerFor(stub shim.ChaincodeStubInterface) *Logger {
+	if tx := unwrapTxStub(stub); tx != nil && tx.logger != nil {
+		return tx.logger
+	}
+	return logger
+}
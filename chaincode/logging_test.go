@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+func TestLoggerRedactsAtInfoAndAbove(t *testing.T) {
+	var out bytes.Buffer
+	l := NewLogger("test")
+	l.SetOutput(&out)
+	l.SetLevel(LogDebug)
+	account := `{"id":"1","account_holder":"Jane Doe","balance":100000,"held":500,"currency":"AUD"}`
+
+	l.Debugf("Account %s", account)
+	if !strings.Contains(out.String(), account) {
+		t.Errorf("Expected debug message logged as it is, got %s", out.String())
+	}
+	out.Reset()
+	l.Errorf("Account %s", account)
+	l.Infof("Account %q", account)
+	for _, value := range []string{"Jane Doe", ":100000", ":500"} {
+		if strings.Contains(out.String(), value) {
+			t.Errorf("Expected %s redacted, got %s", value, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), `"id":"1"`) || !strings.Contains(out.String(), `"currency":"AUD"`) || strings.Count(out.String(), redactedValue) != 6 {
+		t.Errorf("Expected only the holder, balance and held redacted, got %s", out.String())
+	}
+}
+
+func TestInvocationLoggerWritesJSONAtHandlerLevel(t *testing.T) {
+	var out bytes.Buffer
+	logger.SetOutput(&out)
+	logger.SetFormat("json")
+	logger.SetHandlerLevel("TestLoggedHandler", LogDebug)
+	defer func() {
+		logger.SetOutput(os.Stderr)
+		logger.SetFormat("text")
+		delete(logger.config.handlerLevels, "TestLoggedHandler")
+	}()
+	handlerMap.Add("TestLoggedHandler", func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		loggerFor(stub).Debugf("Handling %s", args[0])
+		return nil, nil
+	})
+	handlerMap.Add("TestQuietHandler", func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		loggerFor(stub).Debugf("Handling %s", args[0])
+		return nil, nil
+	})
+	stub := shimtest.NewMockStub("finnet", nil)
+	stub.MockTransactionStart("tx1")
+	defer stub.MockTransactionEnd("tx1")
+
+	if _, err := new(Chaincode).handleInvocation(stub, "TestQuietHandler", []string{"quietly"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing logged below the default level, got %s", out.String())
+	}
+	if _, err := new(Chaincode).handleInvocation(stub, "TestLoggedHandler", []string{"loudly"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected the invocation and the handler message logged, got %s", out.String())
+	}
+	entry := map[string]string{}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["level"] != "DEBUG" || entry["msg"] != "Handling loudly" || entry["function"] != "TestLoggedHandler" || entry["tx_id"] != "tx1" {
+		t.Errorf("Expected the message correlated with the invocation, got %s", lines[1])
+	}
+}
+
+func TestParseHandlerLevels(t *testing.T) {
+	levels, err := ParseHandlerLevels("TransferMoney=debug, GetAccount=WARNING,")
+	if err != nil || len(levels) != 2 || levels["TransferMoney"] != LogDebug || levels["GetAccount"] != LogWarning {
+		t.Errorf("Expected two handler levels, got %v, %v", levels, err)
+	}
+	for _, spec := range []string{"TransferMoney", "=DEBUG", "TransferMoney=LOUD"} {
+		if _, err := ParseHandlerLevels(spec); err == nil {
+			t.Errorf("Expected %s rejected", spec)
+		}
+	}
+}
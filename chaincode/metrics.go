@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+)
+
+// metrics counts the invocations of the handler functions in this chaincode
+// container
+var metrics = newMetricsRegistry()
+
+// metricsRegistry counts invocations, errors and latencies by handler
+// function. Latencies are measured on the peer's clock: the counts are
+// reported by GetMetrics and never written to the ledger, so endorsers need
+// not agree on them.
+type metricsRegistry struct {
+	mu        sync.Mutex
+	container string
+	started   time.Time
+	handlers  map[string]*model.HandlerMetrics
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	container, _ := os.Hostname()
+	return &metricsRegistry{container: container, started: time.Now(), handlers: make(map[string]*model.HandlerMetrics)}
+}
+
+// observe counts an invocation of a function that took the duration
+func (m *metricsRegistry) observe(function string, duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.handlers[function]
+	if !ok {
+		h = &model.HandlerMetrics{Function: function, Latency: make([]int64, len(model.MetricsLatencyBuckets))}
+		m.handlers[function] = h
+	}
+	h.Invocations++
+	if failed {
+		h.Errors++
+	}
+	seconds := duration.Seconds()
+	h.LatencySum += seconds
+	for i, bound := range model.MetricsLatencyBuckets {
+		if seconds <= bound {
+			h.Latency[i]++
+		}
+	}
+}
+
+// snapshot returns a copy of the counts
+func (m *metricsRegistry) snapshot() *model.Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := &model.Metrics{Container: m.container, Started: m.started.Unix(), Buckets: model.MetricsLatencyBuckets, Handlers: []*model.HandlerMetrics{}}
+	for _, h := range m.handlers {
+		c := *h
+		c.Latency = append([]int64{}, h.Latency...)
+		snapshot.Handlers = append(snapshot.Handlers, &c)
+	}
+	sort.Slice(snapshot.Handlers, func(i, j int) bool {
+		return snapshot.Handlers[i].Function < snapshot.Handlers[j].Function
+	})
+	return snapshot
+}
+
+// prometheusText writes metrics in the Prometheus text exposition format,
+// labelled with the function and the container
+func prometheusText(metrics *model.Metrics) []byte {
+	var b strings.Builder
+	labels := func(h *model.HandlerMetrics, extra string) string {
+		return fmt.Sprintf("{container=%q,function=%q%s}", metrics.Container, h.Function, extra)
+	}
+	b.WriteString("# HELP finnet_handler_invocations_total Invocations of chaincode handler functions.\n")
+	b.WriteString("# TYPE finnet_handler_invocations_total counter\n")
+	for _, h := range metrics.Handlers {
+		fmt.Fprintf(&b, "finnet_handler_invocations_total%s %d\n", labels(h, ""), h.Invocations)
+	}
+	b.WriteString("# HELP finnet_handler_errors_total Failed invocations of chaincode handler functions.\n")
+	b.WriteString("# TYPE finnet_handler_errors_total counter\n")
+	for _, h := range metrics.Handlers {
+		fmt.Fprintf(&b, "finnet_handler_errors_total%s %d\n", labels(h, ""), h.Errors)
+	}
+	b.WriteString("# HELP finnet_handler_duration_seconds Duration of chaincode handler function invocations.\n")
+	b.WriteString("# TYPE finnet_handler_duration_seconds histogram\n")
+	for _, h := range metrics.Handlers {
+		for i, bound := range metrics.Buckets {
+			fmt.Fprintf(&b, "finnet_handler_duration_seconds_bucket%s %d\n", labels(h, fmt.Sprintf(",le=%q", strconv.FormatFloat(bound, 'g', -1, 64))), h.Latency[i])
+		}
+		fmt.Fprintf(&b, "finnet_handler_duration_seconds_bucket%s %d\n", labels(h, `,le="+Inf"`), h.Invocations)
+		fmt.Fprintf(&b, "finnet_handler_duration_seconds_sum%s %s\n", labels(h, ""), strconv.FormatFloat(h.LatencySum, 'g', -1, 64))
+		fmt.Fprintf(&b, "finnet_handler_duration_seconds_count%s %d\n", labels(h, ""), h.Invocations)
+	}
+	b.WriteString("# HELP finnet_chaincode_start_time_seconds Start time of the chaincode container since unix epoch in seconds.\n")
+	b.WriteString("# TYPE finnet_chaincode_start_time_seconds gauge\n")
+	fmt.Fprintf(&b, "finnet_chaincode_start_time_seconds{container=%q} %d\n", metrics.Container, metrics.Started)
+	return []byte(b.String())
+}
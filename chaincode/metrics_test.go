@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+)
+
+func TestGetMetricsCountsInvocationsAndErrors(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+
+	transferMetrics := func() model.HandlerMetrics {
+		m := new(model.Metrics)
+		if err := json.Unmarshal(stub.As(testsupport.Operator(t, RoleNetworkOperator)).MustCall(t, "GetMetrics"), m); err != nil {
+			t.Fatal(err)
+		}
+		for _, h := range m.Handlers {
+			if h.Function == "TransferMoney" {
+				return *h
+			}
+		}
+		return model.HandlerMetrics{Latency: make([]int64, len(model.MetricsLatencyBuckets))}
+	}
+	before := transferMetrics()
+	stub.As(testsupport.Customer(t, "1001"))
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON())
+	stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 2000).JSON())
+	if _, err := stub.Call("TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 1000000).JSON()); err == nil {
+		t.Fatal("Expected the transfer beyond the balance to fail")
+	}
+	after := transferMetrics()
+	if after.Invocations-before.Invocations != 3 || after.Errors-before.Errors != 1 {
+		t.Errorf("Expected 3 invocations and 1 error counted, got %d and %d", after.Invocations-before.Invocations, after.Errors-before.Errors)
+	}
+	last := len(model.MetricsLatencyBuckets) - 1
+	if after.Latency[last] > after.Invocations || after.Latency[0] > after.Latency[last] {
+		t.Errorf("Expected cumulative latency buckets, got %v of %d invocations", after.Latency, after.Invocations)
+	}
+
+	text := string(stub.As(testsupport.Operator(t, RoleAuditor)).MustCall(t, "GetMetrics", "prometheus"))
+	for _, want := range []string{
+		"# TYPE finnet_handler_duration_seconds histogram",
+		`function="TransferMoney"`,
+		`le="+Inf"`,
+		"finnet_handler_errors_total{",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %s in the Prometheus metrics, got %s", want, text)
+		}
+	}
+	if _, err := stub.Call("GetMetrics", "xml"); err == nil {
+		t.Errorf("Expected an unknown format rejected")
+	}
+	if _, err := stub.As(testsupport.Customer(t, "1001")).Call("GetMetrics"); err == nil {
+		t.Errorf("Expected a customer denied the metrics")
+	}
+}
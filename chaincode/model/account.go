@@ -2,35 +2,69 @@ package model
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"time"
-
-	"github.com/iShamSLam/chaincode/utils"
 )
 
 // AccountObjectType blockchain object type
 const AccountObjectType = "Account"
 
-// Account struct holds information about a bank account
+// AccountStatus stores allowed values for an account's lifecycle status.
+// Allowed values are "active", "frozen", "dormant", "closed"
+type AccountStatus string
+
+const (
+	// AccountActive account may send and receive money
+	AccountActive AccountStatus = "active"
+	// AccountFrozen account may receive but not send money until unfrozen
+	AccountFrozen AccountStatus = "frozen"
+	// AccountDormant account had its dormant balance escheated and may receive
+	// but not send money until reactivated
+	AccountDormant AccountStatus = "dormant"
+	// AccountClosed account may neither send nor receive money
+	AccountClosed AccountStatus = "closed"
+)
+
+// Account struct holds information about a bank account. Its amounts are
+// stored as whole minor units of CurrencyCode to keep the ledger's JSON shape;
+// they are changed through Money so that the arithmetic is checked.
 type Account struct {
 	Entity
-	ID            string            `json:"id"`
-	CustomerID    string            `json:"customer_id"`
-	BankName      string            `json:"bank_name"`
-	AccountHolder string            `json:"account_holder"`
-	Description   string            `json:"description"`
-	CountryCode   string            `json:"country"`
-	CurrencyCode  string            `json:"currency"`
-	Created       int64             `json:"created"` // unix timestamp
-	Balance       int64             `json:"balance"` // account balance in cents
+	ID            string            `json:"id" validate:"max=64"`
+	CustomerID    string            `json:"customer_id" validate:"required,max=64"`
+	BankName      string            `json:"bank_name" validate:"max=64"`
+	AccountHolder string            `json:"account_holder" validate:"max=140"`
+	ProductID     string            `json:"product_id,omitempty" validate:"max=64"`   // product of the catalog the account was opened with
+	Type          string            `json:"account_type,omitempty" validate:"max=64"` // e.g. "savings", selects the interest paid
+	Description   string            `json:"description" validate:"max=255"`
+	CountryCode   string            `json:"country" validate:"country"`
+	CurrencyCode  string            `json:"currency" validate:"required,currency"` // ISO 4217 code, all amounts of the account are in this currency
+	Created       int64             `json:"created"`                               // unix timestamp
+	Updated       int64             `json:"updated,omitempty"`                     // unix timestamp of the last write
+	TxID          string            `json:"tx_id,omitempty"`                       // ledger transaction of the last write
+	Version       int64             `json:"version"`                               // number of transactions that wrote the account
+	Balance       int64             `json:"balance" validate:"min=0"`              // account balance in cents
+	Overdraft     int64             `json:"overdraft_limit,omitempty"`             // amount in cents the balance may go below zero
+	Held          int64             `json:"held,omitempty"`                        // amount in cents reserved by active holds
 	Default       bool              `json:"default_account"`
-	Closed        bool              `json:"closed"`
-	Params        map[string]string `json:"params,omitempty"` // additional name / value pairs
+	Status        AccountStatus     `json:"status"`
+	StatusReason  string            `json:"status_reason,omitempty"`                        // why the account was frozen
+	LastActivity  int64             `json:"last_activity,omitempty"`                        // unix timestamp of the last debit or credit
+	Signers       []string          `json:"signers,omitempty" validate:"max=20"`            // identities authorized to approve outgoing transfers
+	Quorum        int               `json:"required_signatures,omitempty" validate:"min=0"` // approvals needed from signers (M of N)
+	Params        map[string]string `json:"params,omitempty" validate:"max=32"`             // additional name / value pairs
+}
+
+// PrivateFields returns the account fields kept in the bank's private data collection
+func (a *Account) PrivateFields() []string {
+	return []string{"account_holder"}
 }
 
 // AccountList holds a list of bank accounts
 type AccountList struct {
-	Accounts []*Account `json:"accounts"`
+	Accounts     []*Account `json:"accounts"`
+	NextBookmark string     `json:"next_bookmark,omitempty"` // empty on the last page
+	Truncated    bool       `json:"truncated,omitempty"`     // the page ended early at the response size limit
 }
 
 // UnmarshalJSON custom unmarshalling handles time conversion
@@ -38,6 +72,7 @@ func (a *Account) UnmarshalJSON(data []byte) error {
 	type AccountData Account
 	wrapper := &struct {
 		Created string `json:"created"`
+		Closed  bool   `json:"closed"`
 		*AccountData
 	}{
 		AccountData: (*AccountData)(a),
@@ -45,6 +80,12 @@ func (a *Account) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &wrapper); err != nil {
 		return err
 	}
+	if a.Status == "" { // records written before account statuses only carry the closed flag
+		a.Status = AccountActive
+		if wrapper.Closed {
+			a.Status = AccountClosed
+		}
+	}
 	if wrapper.Created != "" {
 		t1, err := time.Parse(time.RFC3339, wrapper.Created)
 		if err != nil {
@@ -60,41 +101,202 @@ func (a *Account) MarshalJSON() ([]byte, error) {
 	type AccountData Account
 	return json.Marshal(&struct {
 		Created string `json:"created"`
+		Closed  bool   `json:"closed"`
 		*AccountData
 	}{
 		Created:     time.Unix(a.Created, 0).Format(time.RFC3339),
+		Closed:      a.IsClosed(),
 		AccountData: (*AccountData)(a),
 	})
 }
 
 // CreateAccount Factory function creates a new Account struct and returns a pointer to it
-func CreateAccount(accountBytes []byte) (*Account, error) {
+func CreateAccount(accountBytes []byte, tx *TxContext) (*Account, error) {
 	account := new(Account)
-	if err := json.Unmarshal(accountBytes, account); err != nil {
+	if err := Unmarshal(accountBytes, account); err != nil {
 		return nil, err
 	}
-	if account == nil {
-		return nil, errors.New("Error unmarshalling account data")
-	}
 	account.ObjectType = AccountObjectType
-	if account.CustomerID == "" {
-		return nil, errors.New("Missing required customer_id")
-	}
 	if account.ID == "" { // generate hash
-		account.ID = utils.GenerateID(8)
+		account.ID = tx.NewID(8)
 	}
-	if account.Created == 0 {
-		account.Created = time.Now().Unix()
+	account.Created = tx.Time.Unix()
+	account.Version = 0
+	account.Status = AccountActive
+	account.StatusReason = ""
+	// accounts open empty, opening balances are credited by an account load
+	account.Balance = 0
+	account.Overdraft = 0
+	account.Held = 0
+	if err := account.SetSigners(account.Signers, account.Quorum); err != nil {
+		return nil, err
 	}
 	return account, nil
 }
 
-// Debit - debit the account
-func (a *Account) Debit(amount int64) {
-	a.Balance -= amount
+// SetSigners configures the account to require quorum approvals out of the given signer identities
+func (a *Account) SetSigners(signers []string, quorum int) error {
+	if len(signers) == 0 {
+		a.Signers = nil
+		a.Quorum = 0
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, s := range signers {
+		if s == "" || seen[s] {
+			return fmt.Errorf("Invalid or duplicate signer identity %q", s)
+		}
+		seen[s] = true
+	}
+	if quorum < 1 || quorum > len(signers) {
+		return fmt.Errorf("Invalid quorum %d for %d signers", quorum, len(signers))
+	}
+	a.Signers = signers
+	a.Quorum = quorum
+	return nil
+}
+
+// IsMultiSig returns true if outgoing transfers need signer approvals
+func (a *Account) IsMultiSig() bool {
+	return a.Quorum > 0 && len(a.Signers) > 0
+}
+
+// IsSigner returns true if the identity is an authorized signer on the account
+func (a *Account) IsSigner(identity string) bool {
+	for _, s := range a.Signers {
+		if s == identity {
+			return true
+		}
+	}
+	return false
+}
+
+// IsClosed returns true if the account was closed
+func (a *Account) IsClosed() bool {
+	return a.Status == AccountClosed
+}
+
+// CanSend returns true if money may be transferred out of the account
+func (a *Account) CanSend() bool {
+	return a.Status == AccountActive
+}
+
+// CanReceive returns true if money may be transferred into the account
+func (a *Account) CanReceive() bool {
+	return a.Status != AccountClosed
+}
+
+// Available returns the amount that may be transferred out, including the
+// overdraft limit and excluding the amount reserved by holds
+func (a *Account) Available() int64 {
+	return a.Balance + a.Overdraft - a.Held
+}
+
+// Balance is the balance of an account as of the transaction that last wrote it
+type Balance struct {
+	Balance      int64  `json:"balance"`
+	Available    int64  `json:"available"` // balance plus overdraft limit less held
+	CurrencyCode string `json:"currency"`
+	AsOfTx       string `json:"as_of_tx"` // ledger transaction of the last write
+}
+
+// NewBalance returns the balance of the account
+func NewBalance(a *Account) *Balance {
+	return &Balance{Balance: a.Balance, Available: a.Available(), CurrencyCode: a.CurrencyCode, AsOfTx: a.TxID}
+}
+
+// Unheld returns the balance not reserved by holds, which is what debits other
+// than transfers may draw on
+func (a *Account) Unheld() int64 {
+	return a.Balance - a.Held
 }
 
-// Credit - credit the account
-func (a *Account) Credit(amount int64) {
-	a.Balance += amount
+// Touch stamps the account as written by the transaction. The version is
+// incremented on the first write of each transaction only.
+func (a *Account) Touch(tx *TxContext) {
+	if a.TxID != tx.ID {
+		a.Version++
+	}
+	a.Updated = tx.Time.Unix()
+	a.TxID = tx.ID
+}
+
+// CheckVersion fails with a ConcurrentModification error unless the account
+// is at the expected version. An expected version of 0 skips the check.
+func (a *Account) CheckVersion(expected int64) error {
+	if expected != 0 && expected != a.Version {
+		return &ConcurrentModification{ObjectType: AccountObjectType, ID: a.ID, Expected: expected, Actual: a.Version}
+	}
+	return nil
+}
+
+// ConcurrentModification is the error of a compare-and-set write whose record
+// was written by another transaction since the client read it
+type ConcurrentModification struct {
+	ObjectType string
+	ID         string
+	Expected   int64
+	Actual     int64
+}
+
+func (e *ConcurrentModification) Error() string {
+	return fmt.Sprintf("%s: %s %s is at version %d, expected %d", ConcurrentlyModified, e.ObjectType, e.ID, e.Actual, e.Expected)
+}
+
+// Money returns an amount in minor units of the account currency
+func (a *Account) Money(amount int64) Money {
+	return NewMoney(amount, a.CurrencyCode)
+}
+
+// Debit - debit the account. Fails if the amount is in another currency or
+// the balance would overflow.
+func (a *Account) Debit(amount Money, tx *TxContext) error {
+	balance, err := a.Money(a.Balance).Sub(amount)
+	if err != nil {
+		return fmt.Errorf("Cannot debit account %s. Error: %s", a.ID, err)
+	}
+	a.Balance = balance.Amount
+	a.LastActivity = tx.Time.Unix()
+	return nil
+}
+
+// Credit - credit the account. Fails if the amount is in another currency or
+// the balance would overflow.
+func (a *Account) Credit(amount Money, tx *TxContext) error {
+	balance, err := a.Money(a.Balance).Add(amount)
+	if err != nil {
+		return fmt.Errorf("Cannot credit account %s. Error: %s", a.ID, err)
+	}
+	a.Balance = balance.Amount
+	a.LastActivity = tx.Time.Unix()
+	return nil
+}
+
+// Hold reserves the amount of the balance. Fails if the amount is in another
+// currency or the held amount would overflow.
+func (a *Account) Hold(amount Money) error {
+	held, err := a.Money(a.Held).Add(amount)
+	if err != nil {
+		return fmt.Errorf("Cannot hold funds of account %s. Error: %s", a.ID, err)
+	}
+	a.Held = held.Amount
+	return nil
+}
+
+// Unhold returns a held amount to the available balance
+func (a *Account) Unhold(amount Money) error {
+	held, err := a.Money(a.Held).Sub(amount)
+	if err != nil {
+		return fmt.Errorf("Cannot release funds of account %s. Error: %s", a.ID, err)
+	}
+	a.Held = held.Amount
+	return nil
+}
+
+// DormantSince returns the unix time of the account's last activity, or its creation if it has none
+func (a *Account) DormantSince() int64 {
+	if a.LastActivity > a.Created {
+		return a.LastActivity
+	}
+	return a.Created
 }
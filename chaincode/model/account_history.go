@@ -0,0 +1,17 @@
+package model
+
+// AccountHistoryEntry is one committed change of an account record as kept by
+// the peer's history database
+type AccountHistoryEntry struct {
+	TxID      string   `json:"tx_id"`
+	Timestamp int64    `json:"timestamp"` // unix timestamp of the transaction proposal
+	IsDelete  bool     `json:"is_delete"`
+	Account   *Account `json:"account,omitempty"` // account state written by the transaction
+}
+
+// AccountHistory lists the changes of an account record, oldest first
+type AccountHistory struct {
+	CustomerID string                 `json:"customer_id"`
+	AccountID  string                 `json:"account_id"`
+	Entries    []*AccountHistoryEntry `json:"entries"`
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MaxAccountLoadRows caps the rows of a single LoadAccounts invocation
+const MaxAccountLoadRows = 500
+
+// AccountLoadRow is an account of a migrated account book. Row numbers the
+// row in the source file so that the result can be reconciled with it.
+type AccountLoadRow struct {
+	Row            int             `json:"row"`
+	Customer       json.RawMessage `json:"customer,omitempty"` // profile registered when the customer is not registered yet
+	Account        json.RawMessage `json:"account"`            // account data as passed to OpenAccount
+	OpeningBalance int64           `json:"opening_balance"`    // balance in cents credited to the new account
+}
+
+// AccountLoad is a chunk of an account book loaded in one invocation
+type AccountLoad struct {
+	Rows []*AccountLoadRow `json:"rows"`
+}
+
+// AccountLoadItem reports the outcome of a single row of a load
+type AccountLoadItem struct {
+	Row            int    `json:"row"`
+	CustomerID     string `json:"customer_id,omitempty"`
+	AccountID      string `json:"account_id,omitempty"`
+	Status         string `json:"status"` // "loaded" or "rejected"
+	OpeningBalance int64  `json:"opening_balance"`
+	Error          string `json:"error,omitempty"`
+}
+
+// AccountLoadResult summarizes the outcome of a LoadAccounts invocation
+type AccountLoadResult struct {
+	Total    int                `json:"total"`
+	Loaded   int                `json:"loaded"`
+	Rejected int                `json:"rejected"`
+	Balances map[string]int64   `json:"balances"` // opening balances in cents of the loaded rows per currency
+	Items    []*AccountLoadItem `json:"items"`
+}
+
+// CreateAccountLoad Factory function creates a new AccountLoad struct and
+// returns a pointer to it. Rows are validated when they are loaded, so that
+// an invalid row only rejects itself.
+func CreateAccountLoad(loadBytes []byte) (*AccountLoad, error) {
+	load := new(AccountLoad)
+	if err := json.Unmarshal(loadBytes, load); err != nil {
+		return nil, err
+	}
+	if len(load.Rows) == 0 {
+		return nil, errors.New("Missing required rows")
+	}
+	if len(load.Rows) > MaxAccountLoadRows {
+		return nil, fmt.Errorf("Load of %d rows exceeds the maximum of %d", len(load.Rows), MaxAccountLoadRows)
+	}
+	for i, row := range load.Rows {
+		if row == nil || len(row.Account) == 0 {
+			return nil, fmt.Errorf("Invalid row %d. Missing account data", i)
+		}
+	}
+	return load, nil
+}
+
+// CreateAccountLoadResult Factory function creates an empty AccountLoadResult
+func CreateAccountLoadResult(total int) *AccountLoadResult {
+	return &AccountLoadResult{Total: total, Balances: make(map[string]int64), Items: []*AccountLoadItem{}}
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// TransactionArchiveObjectType blockchain object type
+	TransactionArchiveObjectType = "TransactionArchive"
+	// AccountTombstoneObjectType blockchain object type
+	AccountTombstoneObjectType = "AccountTombstone"
+)
+
+// MinRetentionDays is the shortest retention horizon transaction detail can be archived at
+const MinRetentionDays = 90
+
+// DefaultClosedRetentionDays is how long closed accounts are kept when the
+// configuration sets no retention period
+const DefaultClosedRetentionDays = 7 * 365
+
+// TransactionArchive summarises a batch of archived transaction details of an account.
+// The Merkle root commits to the exact detail records exported off-chain.
+type TransactionArchive struct {
+	Entity
+	ID          string `json:"id"`
+	CustomerID  string `json:"customer_id"`
+	AccountID   string `json:"account_id"`
+	Horizon     string `json:"horizon"` // details created before this date (YYYY-MM-DD) were archived
+	Count       int    `json:"count"`
+	First       int64  `json:"first"` // unix timestamp of the oldest archived transaction
+	Last        int64  `json:"last"`  // unix timestamp of the newest archived transaction
+	Debited     int64  `json:"debited"`
+	Credited    int64  `json:"credited"`
+	Fees        int64  `json:"fees"`
+	Failed      int    `json:"failed"`
+	MerkleRoot  string `json:"merkle_root"`
+	Archived    int64  `json:"archived"` // unix timestamp
+	ArchiveTxID string `json:"archive_tx_id"`
+}
+
+// ArchiveBatch lists the transaction details that would be archived, in key order,
+// so they can be exported off-chain and their Merkle root checked before archiving
+type ArchiveBatch struct {
+	CustomerID   string         `json:"customer_id"`
+	AccountID    string         `json:"account_id"`
+	Horizon      string         `json:"horizon"`
+	Transactions []*StateRecord `json:"transactions"`
+	MerkleRoot   string         `json:"merkle_root"`
+}
+
+// TransactionArchiveList holds the archives of an account
+type TransactionArchiveList struct {
+	Archives []*TransactionArchive `json:"archives"`
+}
+
+// RetentionHorizon parses an archive horizon date and checks it respects the minimum retention
+func RetentionHorizon(horizon string, now time.Time) (time.Time, bool) {
+	h, err := time.Parse(PayDateFormat, horizon)
+	if err != nil {
+		return h, false
+	}
+	return h, !h.After(now.UTC().AddDate(0, 0, -MinRetentionDays))
+}
+
+// Add summarises an archived transaction
+func (a *TransactionArchive) Add(txn *Transaction) {
+	if a.Count == 0 || txn.Created < a.First {
+		a.First = txn.Created
+	}
+	if txn.Created > a.Last {
+		a.Last = txn.Created
+	}
+	a.Count++
+	switch txn.Status {
+	case Debited:
+		a.Debited += txn.Amount
+		a.Fees += txn.Fee
+	case Credited:
+		a.Credited += txn.Amount
+	case Failed:
+		a.Failed++
+	}
+}
+
+// AccountTombstone is left in place of a purged closed account. The hashes
+// commit to the account record and its transaction details deleted with it.
+type AccountTombstone struct {
+	Entity
+	CustomerID       string `json:"customer_id"`
+	AccountID        string `json:"account_id"`
+	CurrencyCode     string `json:"currency"`
+	Closed           int64  `json:"closed"` // unix timestamp
+	AccountHash      string `json:"account_hash"`
+	TransactionCount int    `json:"transaction_count"`
+	TransactionsRoot string `json:"transactions_root,omitempty"` // Merkle root of the transaction details in key order
+	Purged           int64  `json:"purged"`                      // unix timestamp
+	PurgeTxID        string `json:"purge_tx_id"`
+}
+
+// AccountTombstoneList holds a list of account tombstones
+type AccountTombstoneList struct {
+	Tombstones []*AccountTombstone `json:"tombstones"`
+}
+
+// CreateAccountTombstone a factory function for the tombstone of a purged account
+func CreateAccountTombstone(a *Account, accountHash string, count int, transactionsRoot string, tx *TxContext) *AccountTombstone {
+	return &AccountTombstone{
+		Entity:           Entity{AccountTombstoneObjectType},
+		CustomerID:       a.CustomerID,
+		AccountID:        a.ID,
+		CurrencyCode:     a.CurrencyCode,
+		Closed:           a.Updated,
+		AccountHash:      accountHash,
+		TransactionCount: count,
+		TransactionsRoot: transactionsRoot,
+		Purged:           tx.Time.Unix(),
+		PurgeTxID:        tx.ID,
+	}
+}
+
+// RetentionExpired returns true if the account was closed more than the given
+// number of days ago. Closing is the last write of an account, so its update
+// time is the time it was closed.
+func (a *Account) RetentionExpired(days int, now time.Time) bool {
+	return a.IsClosed() && a.Updated <= now.AddDate(0, 0, -days).Unix()
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// AssetTokenObjectType blockchain object type
+	AssetTokenObjectType = "AssetToken"
+	// AssetHoldingObjectType blockchain object type
+	AssetHoldingObjectType = "AssetHolding"
+)
+
+// AssetToken is a generic tokenized asset (e.g. a security or commodity) held in whole units
+type AssetToken struct {
+	Entity
+	ID               string            `json:"id"` // e.g. an ISIN
+	Name             string            `json:"name"`
+	Type             string            `json:"type"` // e.g. "bond", "equity", "commodity"
+	IssuerCustomerID string            `json:"issuer_customer"`
+	TotalUnits       int64             `json:"total_units"`
+	Created          int64             `json:"created"` // unix timestamp
+	Params           map[string]string `json:"params,omitempty"`
+}
+
+// AssetHolding holds the units of an asset token owned by a customer
+type AssetHolding struct {
+	Entity
+	TokenID    string `json:"token_id"`
+	CustomerID string `json:"customer_id"`
+	Units      int64  `json:"units"`
+	Pledged    int64  `json:"pledged"` // units locked as collateral
+}
+
+// DvPInstruction describes a delivery-versus-payment swap of asset units against cash
+type DvPInstruction struct {
+	TokenID             string `json:"token_id"`
+	Units               int64  `json:"units"`
+	SellerCustomerID    string `json:"seller_customer"`
+	SellerAccountID     string `json:"seller_account"` // cash account receiving payment
+	BuyerCustomerID     string `json:"buyer_customer"`
+	BuyerAccountID      string `json:"buyer_account"` // cash account making payment
+	Price               int64  `json:"price"`         // total cash leg in cents
+	CurrencyCode        string `json:"currency"`
+	SettlementReference string `json:"reference,omitempty"`
+}
+
+// CreateAssetToken Factory function creates a new AssetToken struct and returns a pointer to it
+func CreateAssetToken(tokenBytes []byte, tx *TxContext) (*AssetToken, error) {
+	token := new(AssetToken)
+	if err := json.Unmarshal(tokenBytes, token); err != nil {
+		return nil, err
+	}
+	token.ObjectType = AssetTokenObjectType
+	if token.ID == "" || token.Name == "" {
+		return nil, errors.New("Missing required id and / or name")
+	}
+	if token.IssuerCustomerID == "" {
+		return nil, errors.New("Missing required issuer_customer")
+	}
+	if token.TotalUnits <= 0 {
+		return nil, fmt.Errorf("Invalid total units %d", token.TotalUnits)
+	}
+	token.Created = tx.Time.Unix()
+	return token, nil
+}
+
+// CreateAssetHolding Factory function creates an empty AssetHolding
+func CreateAssetHolding(tokenID string, customerID string) *AssetHolding {
+	return &AssetHolding{Entity: Entity{AssetHoldingObjectType}, TokenID: tokenID, CustomerID: customerID}
+}
+
+// Available returns the units that are not pledged
+func (h *AssetHolding) Available() int64 {
+	return h.Units - h.Pledged
+}
+
+// Validate - checks that required are present in the DvP instruction
+func (d *DvPInstruction) Validate() error {
+	if d.TokenID == "" {
+		return errors.New("Missing required token_id value")
+	}
+	if d.Units <= 0 {
+		return fmt.Errorf("Invalid units %d", d.Units)
+	}
+	if d.SellerCustomerID == "" || d.SellerAccountID == "" {
+		return errors.New("Missing required seller_customer and / or seller_account")
+	}
+	if d.BuyerCustomerID == "" || d.BuyerAccountID == "" {
+		return errors.New("Missing required buyer_customer and / or buyer_account")
+	}
+	if d.SellerCustomerID == d.BuyerCustomerID {
+		return errors.New("Seller and buyer must differ")
+	}
+	if d.Price <= 0 {
+		return fmt.Errorf("Invalid price %d", d.Price)
+	}
+	if d.CurrencyCode == "" {
+		return errors.New("Missing required currency value")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"sort"
+)
+
+// AuditEntryObjectType blockchain object type
+const AuditEntryObjectType = "AuditEntry"
+
+// AuditOutcome stores allowed values for the outcome of an audited invocation.
+// Allowed values are "success", "partial"
+type AuditOutcome string
+
+const (
+	// AuditSuccess invocation completed all its work
+	AuditSuccess AuditOutcome = "success"
+	// AuditPartial invocation committed but reported some items as failed, e.g.
+	// transfers of a batch
+	AuditPartial AuditOutcome = "partial"
+)
+
+// AuditEntry is the immutable record of a state-changing invocation. Failed
+// invocations are not committed, so they leave no entry.
+type AuditEntry struct {
+	Entity
+	TxID        string       `json:"tx_id"`
+	Function    string       `json:"function"`
+	Caller      string       `json:"caller"`
+	CallerMSP   string       `json:"caller_msp"`
+	Date        string       `json:"date"`      // YYYY-MM-DD
+	Timestamp   int64        `json:"timestamp"` // unix timestamp
+	Keys        []string     `json:"keys"`      // ledger keys written or deleted
+	Private     []string     `json:"private,omitempty"`
+	Outcome     AuditOutcome `json:"outcome"`
+	Failures    int          `json:"failures,omitempty"`    // items reported as failed
+	Traceparent string       `json:"traceparent,omitempty"` // W3C trace context the client passed with the invocation
+}
+
+// AuditLog holds the audit entries matching a query, oldest first
+type AuditLog struct {
+	Entries []*AuditEntry `json:"entries"`
+}
+
+// AuditFilter selects audit entries. Empty fields match any entry; dates are inclusive.
+type AuditFilter struct {
+	Caller   string
+	Function string
+	From     string
+	To       string
+}
+
+// CreateAuditEntry a factory function for the audit entry of an invocation.
+// Private writes are listed as collection and key.
+func CreateAuditEntry(function string, caller string, callerMSP string, keys []string, private []string, failures int, tx *TxContext) *AuditEntry {
+	sort.Strings(keys)
+	sort.Strings(private)
+	entry := &AuditEntry{
+		Entity:      Entity{AuditEntryObjectType},
+		TxID:        tx.ID,
+		Function:    function,
+		Caller:      caller,
+		CallerMSP:   callerMSP,
+		Date:        tx.Time.UTC().Format(ReportDateFormat),
+		Timestamp:   tx.Time.Unix(),
+		Keys:        keys,
+		Private:     private,
+		Outcome:     AuditSuccess,
+		Failures:    failures,
+		Traceparent: tx.Traceparent,
+	}
+	if failures > 0 {
+		entry.Outcome = AuditPartial
+	}
+	return entry
+}
+
+// Matches returns true if the entry is selected by the filter
+func (f *AuditFilter) Matches(e *AuditEntry) bool {
+	return (f.Caller == "" || e.Caller == f.Caller) &&
+		(f.Function == "" || e.Function == f.Function) &&
+		InPeriod(f.From, f.To, e.Date)
+}
+
+// ByTimestamp sorts audit entries by invocation time
+type ByTimestamp []*AuditEntry
+
+func (e ByTimestamp) Len() int {
+	return len(e)
+}
+
+func (e ByTimestamp) Less(i, j int) bool {
+	return e[i].Timestamp < e[j].Timestamp
+}
+
+func (e ByTimestamp) Swap(i, j int) {
+	e[i], e[j] = e[j], e[i]
+}
@@ -0,0 +1,50 @@
+package model
+
+// BalanceSnapshotObjectType blockchain object type
+const BalanceSnapshotObjectType = "BalanceSnapshot"
+
+// BalanceSnapshot is the balance of an account when an end-of-day snapshot was
+// taken, keyed by customer ID, account ID and the day it was taken (YYYYMMDD,
+// UTC). Statements work their balances out from the nearest snapshot rather
+// than from the current balance.
+type BalanceSnapshot struct {
+	Entity
+	CustomerID   string `json:"customer_id"`
+	AccountID    string `json:"account_id"`
+	CurrencyCode string `json:"currency"`
+	Day          string `json:"day"`     // YYYY-MM-DD, UTC
+	Balance      int64  `json:"balance"` // balance in cents
+	Held         int64  `json:"held,omitempty"`
+	Taken        int64  `json:"taken"` // unix timestamp; transactions created before it are reflected in the balance
+	TxID         string `json:"tx_id"` // ledger transaction that took the snapshot
+}
+
+// CreateBalanceSnapshot a factory function for creating the snapshot of an
+// account's balance in this transaction
+func CreateBalanceSnapshot(a *Account, tx *TxContext) *BalanceSnapshot {
+	return &BalanceSnapshot{
+		Entity:       Entity{BalanceSnapshotObjectType},
+		CustomerID:   a.CustomerID,
+		AccountID:    a.ID,
+		CurrencyCode: a.CurrencyCode,
+		Day:          tx.Time.UTC().Format(StatementDateFormat),
+		Balance:      a.Balance,
+		Held:         a.Held,
+		Taken:        tx.Time.Unix(),
+		TxID:         tx.ID,
+	}
+}
+
+// BalanceSnapshotList stores a list of the balance snapshots of an account
+type BalanceSnapshotList struct {
+	Snapshots    []*BalanceSnapshot `json:"snapshots"`
+	NextBookmark string             `json:"next_bookmark,omitempty"` // empty on the last page
+	Truncated    bool               `json:"truncated,omitempty"`     // the page ended early at the response size limit
+}
+
+// BalanceSnapshotReport lists the outcome of a TakeBalanceSnapshots invocation
+type BalanceSnapshotReport struct {
+	Day          string `json:"day"`                     // YYYY-MM-DD the snapshots are keyed by
+	Snapshots    int    `json:"snapshots"`               // accounts whose balance was snapshot
+	NextBookmark string `json:"next_bookmark,omitempty"` // empty once every account has been snapshot
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// BankObjectType blockchain object type
+const BankObjectType = "Bank"
+
+var bicPattern = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+
+// BankStatus stores allowed values for a participant bank's status.
+// Allowed values are "active", "suspended"
+type BankStatus string
+
+const (
+	// BankActive bank may open accounts and send or receive transfers
+	BankActive BankStatus = "active"
+	// BankSuspended bank is barred from the network until reinstated
+	BankSuspended BankStatus = "suspended"
+)
+
+// SettlementAccount identifies the account a bank settles a currency through
+type SettlementAccount struct {
+	CustomerID string `json:"customer_id"`
+	AccountID  string `json:"account_id"`
+}
+
+// Bank is a participant of the FinNet network. Accounts reference their bank by BIC in bank_name.
+type Bank struct {
+	Entity
+	BIC                string                        `json:"bic"`
+	Name               string                        `json:"name"`
+	MSPID              string                        `json:"msp_id"`
+	SettlementAccounts map[string]*SettlementAccount `json:"settlement_accounts,omitempty"` // by currency
+	Currencies         []string                      `json:"currencies,omitempty"`          // supported currencies, any if empty
+	Status             BankStatus                    `json:"status"`
+	StatusReason       string                        `json:"status_reason,omitempty"`
+	Registered         int64                         `json:"registered"` // unix timestamp
+	Updated            int64                         `json:"updated"`    // unix timestamp
+}
+
+// BankList holds a list of participant banks
+type BankList struct {
+	Banks []*Bank `json:"banks"`
+}
+
+// CreateBank Factory function creates a new Bank struct and returns a pointer to it
+func CreateBank(bankBytes []byte, tx *TxContext) (*Bank, error) {
+	bank := new(Bank)
+	if err := json.Unmarshal(bankBytes, bank); err != nil {
+		return nil, err
+	}
+	bank.ObjectType = BankObjectType
+	if !bicPattern.MatchString(bank.BIC) {
+		return nil, fmt.Errorf("Invalid BIC %s", bank.BIC)
+	}
+	if bank.Name == "" || bank.MSPID == "" {
+		return nil, errors.New("Missing required name and / or msp_id")
+	}
+	for _, currency := range bank.Currencies {
+		if err := ValidateCurrency(currency); err != nil {
+			return nil, err
+		}
+	}
+	for currency, account := range bank.SettlementAccounts {
+		if account == nil || account.CustomerID == "" || account.AccountID == "" {
+			return nil, fmt.Errorf("Invalid settlement account for %s", currency)
+		}
+		if err := ValidateCurrency(currency); err != nil {
+			return nil, err
+		}
+		if len(bank.Currencies) > 0 && !bank.Supports(currency) {
+			return nil, fmt.Errorf("Settlement account for %s, which is not a supported currency", currency)
+		}
+	}
+	// a bank that lists no currencies supports those it settles in
+	if len(bank.Currencies) == 0 {
+		for currency := range bank.SettlementAccounts {
+			bank.Currencies = append(bank.Currencies, currency)
+		}
+		sort.Strings(bank.Currencies)
+	}
+	bank.Status = BankActive
+	bank.StatusReason = ""
+	bank.Registered = tx.Time.Unix()
+	bank.Updated = bank.Registered
+	return bank, nil
+}
+
+// SetStatus changes the bank's status, recording the reason
+func (b *Bank) SetStatus(status BankStatus, reason string, tx *TxContext) error {
+	if b.Status == status {
+		return fmt.Errorf("Bank %s is already %s", b.BIC, status)
+	}
+	b.Status = status
+	b.StatusReason = reason
+	b.Updated = tx.Time.Unix()
+	return nil
+}
+
+// IsActive returns true if the bank may transact
+func (b *Bank) IsActive() bool {
+	return b.Status == BankActive
+}
+
+// Supports returns true if the bank transacts in the currency. A bank without
+// supported currencies transacts in any.
+func (b *Bank) Supports(currency string) bool {
+	if len(b.Currencies) == 0 {
+		return true
+	}
+	for _, c := range b.Currencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// SettlementAccountFor returns the account the bank settles a currency
+// through, or nil if it has none
+func (b *Bank) SettlementAccountFor(currency string) *SettlementAccount {
+	return b.SettlementAccounts[currency]
+}
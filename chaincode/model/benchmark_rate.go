@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BenchmarkRateObjectType blockchain object type
+const BenchmarkRateObjectType = "BenchmarkRate"
+
+// BenchmarkDateFormat is the layout of benchmark publication dates
+const BenchmarkDateFormat = "2006-01-02"
+
+// BenchmarkRate is a daily reference rate (e.g. an overnight rate) published
+// by a rate administrator. Published rates are never overwritten.
+type BenchmarkRate struct {
+	Entity
+	Name         string `json:"name"` // e.g. "AONIA", "SOFR"
+	Date         string `json:"date"` // YYYY-MM-DD
+	CurrencyCode string `json:"currency"`
+	Rate         int64  `json:"rate"` // annual rate in basis points
+	Publisher    string `json:"publisher"`
+	Published    int64  `json:"published"` // unix timestamp
+}
+
+// BenchmarkRateList holds the publication history of a benchmark
+type BenchmarkRateList struct {
+	Rates []*BenchmarkRate `json:"rates"`
+}
+
+// CreateBenchmarkRate Factory function creates a new BenchmarkRate struct and returns a pointer to it
+func CreateBenchmarkRate(rateBytes []byte, publisher string, tx *TxContext) (*BenchmarkRate, error) {
+	rate := new(BenchmarkRate)
+	if err := json.Unmarshal(rateBytes, rate); err != nil {
+		return nil, err
+	}
+	rate.ObjectType = BenchmarkRateObjectType
+	if rate.Name == "" {
+		return nil, errors.New("Missing required name value")
+	}
+	if _, err := time.Parse(BenchmarkDateFormat, rate.Date); err != nil {
+		return nil, fmt.Errorf("Invalid benchmark date %s", rate.Date)
+	}
+	if rate.CurrencyCode == "" {
+		return nil, errors.New("Missing required currency value")
+	}
+	rate.Publisher = publisher
+	rate.Published = tx.Time.Unix()
+	return rate, nil
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"fmt"
+)
+
+const (
+	// BeneficiaryObjectType blockchain object type
+	BeneficiaryObjectType = "Beneficiary"
+	// BeneficiaryPolicyObjectType blockchain object type
+	BeneficiaryPolicyObjectType = "BeneficiaryPolicy"
+)
+
+// Beneficiary is a payee saved in a customer's address book
+type Beneficiary struct {
+	Entity
+	ID           string `json:"id" validate:"max=64"`
+	CustomerID   string `json:"customer_id" validate:"required,max=64"` // customer owning the address book
+	Nickname     string `json:"nickname" validate:"required,max=64"`
+	ToCustomerID string `json:"to_customer" validate:"required,max=64"`
+	ToAccountID  string `json:"to_account" validate:"required,max=64"`
+	ToTenant     string `json:"to_tenant,omitempty" validate:"max=64"` // payee tenant in multi-tenant mode, if not the customer's
+	BankName     string `json:"bank_name,omitempty" validate:"max=64"`
+	CurrencyCode string `json:"currency,omitempty" validate:"currency"`
+	Verified     bool   `json:"verified"` // set server-side when the payee account was found on the ledger
+	AddedBy      string `json:"added_by"`
+	Added        int64  `json:"added"` // unix timestamp
+}
+
+// BeneficiaryList holds the address book of a customer and its policy, if any
+type BeneficiaryList struct {
+	Beneficiaries []*Beneficiary     `json:"beneficiaries"`
+	Policy        *BeneficiaryPolicy `json:"policy,omitempty"`
+}
+
+// BeneficiaryPolicy restricts the transfers of a customer to verified
+// beneficiaries saved at least MinAgeHours before. Transfers between the
+// customer's own accounts are not restricted.
+type BeneficiaryPolicy struct {
+	Entity
+	CustomerID  string `json:"customer_id" validate:"required,max=64"`
+	MinAgeHours int    `json:"min_age_hours" validate:"min=0,max=8760"`
+	SetBy       string `json:"set_by"`
+	Updated     int64  `json:"updated"` // unix timestamp
+}
+
+// CreateBeneficiary Factory function creates a new Beneficiary struct and returns a pointer to it
+func CreateBeneficiary(beneficiaryBytes []byte, addedBy string, tx *TxContext) (*Beneficiary, error) {
+	beneficiary := new(Beneficiary)
+	if err := Unmarshal(beneficiaryBytes, beneficiary); err != nil {
+		return nil, err
+	}
+	beneficiary.ObjectType = BeneficiaryObjectType
+	if beneficiary.ID == "" {
+		beneficiary.ID = tx.NewID(8)
+	}
+	beneficiary.Verified = false
+	beneficiary.AddedBy = addedBy
+	beneficiary.Added = tx.Time.Unix()
+	return beneficiary, nil
+}
+
+// Verify marks the beneficiary verified against the payee account found on
+// the ledger, taking its bank and currency if not given
+func (b *Beneficiary) Verify(payee *Account) error {
+	if b.CurrencyCode == "" {
+		b.CurrencyCode = payee.CurrencyCode
+	}
+	if b.CurrencyCode != payee.CurrencyCode {
+		return fmt.Errorf("Beneficiary currency %s does not match account currency %s", b.CurrencyCode, payee.CurrencyCode)
+	}
+	if b.BankName == "" {
+		b.BankName = payee.BankName
+	}
+	b.Verified = true
+	return nil
+}
+
+// Pays returns true if the beneficiary is the payee of the transfer
+func (b *Beneficiary) Pays(t *Transfer) bool {
+	return b.ToCustomerID == t.ToCustomerID && b.ToAccountID == t.ToAccountID && b.ToTenant == t.ToTenant
+}
+
+// CreateBeneficiaryPolicy Factory function creates a new BeneficiaryPolicy struct and returns a pointer to it
+func CreateBeneficiaryPolicy(policyBytes []byte, setBy string, tx *TxContext) (*BeneficiaryPolicy, error) {
+	policy := new(BeneficiaryPolicy)
+	if err := Unmarshal(policyBytes, policy); err != nil {
+		return nil, err
+	}
+	policy.ObjectType = BeneficiaryPolicyObjectType
+	policy.SetBy = setBy
+	policy.Updated = tx.Time.Unix()
+	return policy, nil
+}
+
+// Allows returns true if a transfer may be made to the beneficiary at the
+// given unix time
+func (p *BeneficiaryPolicy) Allows(b *Beneficiary, now int64) bool {
+	return b.Verified && now-b.Added >= int64(p.MinAgeHours)*60*60
+}
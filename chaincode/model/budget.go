@@ -0,0 +1,106 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// BudgetObjectType blockchain object type
+	BudgetObjectType = "Budget"
+	// BudgetSpendObjectType blockchain object type
+	BudgetSpendObjectType = "BudgetSpend"
+)
+
+// BudgetWarningEvent is the chaincode event emitted when a soft budget is exceeded
+const BudgetWarningEvent = "BudgetWarning"
+
+// BudgetEnforcement stores allowed values for how a budget is enforced.
+// Allowed values are "soft", "hard"
+type BudgetEnforcement string
+
+const (
+	// BudgetSoft payments over budget settle and emit a warning event
+	BudgetSoft BudgetEnforcement = "soft"
+	// BudgetHard payments over budget are rejected
+	BudgetHard BudgetEnforcement = "hard"
+)
+
+// Budget caps a customer's monthly spend in a payment category
+type Budget struct {
+	Entity
+	CustomerID   string            `json:"customer_id"`
+	Category     string            `json:"category"`
+	CurrencyCode string            `json:"currency"`
+	Limit        int64             `json:"limit"` // monthly limit in cents
+	Enforcement  BudgetEnforcement `json:"enforcement"`
+	Created      int64             `json:"created"`
+}
+
+// BudgetSpend holds a customer's spend in a category for a month
+type BudgetSpend struct {
+	Entity
+	CustomerID string `json:"customer_id"`
+	Category   string `json:"category"`
+	Month      string `json:"month"` // YYYY-MM
+	Spent      int64  `json:"spent"`
+}
+
+// BudgetStatus reports a budget with the current month's spend
+type BudgetStatus struct {
+	*Budget
+	Month     string `json:"month"`
+	Spent     int64  `json:"spent"`
+	Remaining int64  `json:"remaining"`
+}
+
+// BudgetStatusList holds the budgets of a customer
+type BudgetStatusList struct {
+	Budgets []*BudgetStatus `json:"budgets"`
+}
+
+// BudgetWarning is the payload of a BudgetWarning event
+type BudgetWarning struct {
+	CustomerID string `json:"customer_id"`
+	Category   string `json:"category"`
+	Month      string `json:"month"`
+	Limit      int64  `json:"limit"`
+	Spent      int64  `json:"spent"`
+}
+
+// CreateBudget Factory function creates a new Budget struct and returns a pointer to it
+func CreateBudget(budgetBytes []byte, tx *TxContext) (*Budget, error) {
+	budget := new(Budget)
+	if err := json.Unmarshal(budgetBytes, budget); err != nil {
+		return nil, err
+	}
+	budget.ObjectType = BudgetObjectType
+	if budget.CustomerID == "" || budget.Category == "" {
+		return nil, errors.New("Missing required customer_id and / or category")
+	}
+	if budget.CurrencyCode == "" {
+		return nil, errors.New("Missing required currency value")
+	}
+	if budget.Limit < 0 {
+		return nil, fmt.Errorf("Invalid budget limit %d", budget.Limit)
+	}
+	if budget.Enforcement == "" {
+		budget.Enforcement = BudgetSoft
+	}
+	if budget.Enforcement != BudgetSoft && budget.Enforcement != BudgetHard {
+		return nil, fmt.Errorf("Invalid budget enforcement %s", budget.Enforcement)
+	}
+	budget.Created = tx.Time.Unix()
+	return budget, nil
+}
+
+// CreateBudgetSpend Factory function creates an empty BudgetSpend for a month
+func CreateBudgetSpend(customerID string, category string, month string) *BudgetSpend {
+	return &BudgetSpend{Entity: Entity{BudgetSpendObjectType}, CustomerID: customerID, Category: category, Month: month}
+}
+
+// Status returns the budget status given the month's spend
+func (b *Budget) Status(spend *BudgetSpend) *BudgetStatus {
+	return &BudgetStatus{Budget: b, Month: spend.Month, Spent: spend.Spent, Remaining: b.Limit - spend.Spent}
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// HolidayObjectType blockchain object type
+const HolidayObjectType = "Holiday"
+
+// CutOffTimeFormat is the layout of the daily cut-off time, in UTC
+const CutOffTimeFormat = "15:04"
+
+// MaxValueDateDays bounds the number of days a value date may be rolled
+// forward over non-business days
+const MaxValueDateDays = 31
+
+// Holiday is a non-business day of the settlement calendar of a currency
+type Holiday struct {
+	Entity
+	CurrencyCode string `json:"currency" validate:"required,currency"`
+	Date         string `json:"date" validate:"required"` // YYYY-MM-DD
+	Name         string `json:"name" validate:"max=64"`
+	AddedBy      string `json:"added_by"`
+	Added        int64  `json:"added"` // unix timestamp
+}
+
+// HolidayList holds the holidays of a currency
+type HolidayList struct {
+	Holidays []*Holiday `json:"holidays"`
+}
+
+// CreateHoliday Factory function creates a new Holiday struct and returns a pointer to it
+func CreateHoliday(holidayBytes []byte, addedBy string, tx *TxContext) (*Holiday, error) {
+	holiday := new(Holiday)
+	if err := Unmarshal(holidayBytes, holiday); err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse(ValueDateFormat, holiday.Date); err != nil {
+		return nil, &ValidationError{Fields: []*FieldError{{Field: "date", Rule: "date", Message: "must be a date in YYYY-MM-DD format"}}}
+	}
+	holiday.ObjectType = HolidayObjectType
+	holiday.AddedBy = addedBy
+	holiday.Added = tx.Time.Unix()
+	return holiday, nil
+}
+
+// ValidateCutOffTime checks a cut-off time is given as HH:MM
+func ValidateCutOffTime(cutOff string) error {
+	if _, err := time.Parse(CutOffTimeFormat, cutOff); err != nil {
+		return fmt.Errorf("Invalid cut-off time %q, expected HH:MM", cutOff)
+	}
+	return nil
+}
+
+// FirstValueDate returns the date a transfer submitted at the given time
+// takes value before non-business days are skipped: the submission date, or
+// the next day if submitted at or after the cut-off time. An empty cut-off
+// time sets no cut-off.
+func FirstValueDate(submitted time.Time, cutOff string) time.Time {
+	submitted = submitted.UTC()
+	date := time.Date(submitted.Year(), submitted.Month(), submitted.Day(), 0, 0, 0, 0, time.UTC)
+	if cutOff == "" {
+		return date
+	}
+	c, err := time.Parse(CutOffTimeFormat, cutOff)
+	if err != nil {
+		return date
+	}
+	if submitted.Sub(date) >= time.Duration(c.Hour())*time.Hour+time.Duration(c.Minute())*time.Minute {
+		return date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// IsWeekend returns true if the date falls on a Saturday or Sunday
+func IsWeekend(date time.Time) bool {
+	day := date.Weekday()
+	return day == time.Saturday || day == time.Sunday
+}
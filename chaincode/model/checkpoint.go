@@ -0,0 +1,107 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CheckpointObjectType blockchain object type
+const CheckpointObjectType = "Checkpoint"
+
+// CheckpointObjectTypes are the object types whose state is digested by a checkpoint
+var CheckpointObjectTypes = []string{AccountObjectType, TransactionObjectType}
+
+// Checkpoint records a known-good view of application state at a point in time
+type Checkpoint struct {
+	Entity
+	ID         string                      `json:"id"`
+	Supply     map[string]int64            `json:"supply"`      // total balance by currency
+	BankTotals map[string]map[string]int64 `json:"bank_totals"` // total balance by bank and currency
+	Digests    map[string]string           `json:"digests"`     // hex SHA-256 of all records by object type
+	Counts     map[string]int              `json:"counts"`      // number of records by object type
+	Created    int64                       `json:"created"`     // unix timestamp
+	TxID       string                      `json:"tx_id"`
+	Operator   string                      `json:"operator"`
+}
+
+// CheckpointVerification reports the differences between current state and a checkpoint
+type CheckpointVerification struct {
+	CheckpointID string      `json:"checkpoint_id"`
+	Matches      bool        `json:"matches"`
+	Mismatches   []string    `json:"mismatches"`
+	Current      *Checkpoint `json:"current"`
+}
+
+// CheckpointList holds a list of checkpoints
+type CheckpointList struct {
+	Checkpoints []*Checkpoint `json:"checkpoints"`
+}
+
+// CreateCheckpoint Factory function creates an empty Checkpoint
+func CreateCheckpoint(id string) *Checkpoint {
+	return &Checkpoint{
+		Entity:     Entity{CheckpointObjectType},
+		ID:         id,
+		Supply:     make(map[string]int64),
+		BankTotals: make(map[string]map[string]int64),
+		Digests:    make(map[string]string),
+		Counts:     make(map[string]int),
+	}
+}
+
+// AddAccount adds an account balance to the supply and bank totals
+func (c *Checkpoint) AddAccount(a *Account) {
+	c.Supply[a.CurrencyCode] += a.Balance
+	if c.BankTotals[a.BankName] == nil {
+		c.BankTotals[a.BankName] = make(map[string]int64)
+	}
+	c.BankTotals[a.BankName][a.CurrencyCode] += a.Balance
+}
+
+// Compare returns the differences of the current state against the checkpoint, in a stable order
+func (c *Checkpoint) Compare(current *Checkpoint) []string {
+	mismatches := []string{}
+	for _, currency := range unionKeys(c.Supply, current.Supply) {
+		if c.Supply[currency] != current.Supply[currency] {
+			mismatches = append(mismatches, fmt.Sprintf("supply of %s is %d, checkpoint %d", currency, current.Supply[currency], c.Supply[currency]))
+		}
+	}
+	banks := make(map[string]int64)
+	for bank := range c.BankTotals {
+		banks[bank] = 0
+	}
+	for bank := range current.BankTotals {
+		banks[bank] = 0
+	}
+	for _, bank := range unionKeys(banks, nil) {
+		for _, currency := range unionKeys(c.BankTotals[bank], current.BankTotals[bank]) {
+			if c.BankTotals[bank][currency] != current.BankTotals[bank][currency] {
+				mismatches = append(mismatches, fmt.Sprintf("total of bank %q in %s is %d, checkpoint %d", bank, currency, current.BankTotals[bank][currency], c.BankTotals[bank][currency]))
+			}
+		}
+	}
+	for _, objectType := range CheckpointObjectTypes {
+		if c.Counts[objectType] != current.Counts[objectType] {
+			mismatches = append(mismatches, fmt.Sprintf("%s count is %d, checkpoint %d", objectType, current.Counts[objectType], c.Counts[objectType]))
+		}
+		if c.Digests[objectType] != current.Digests[objectType] {
+			mismatches = append(mismatches, fmt.Sprintf("%s digest is %s, checkpoint %s", objectType, current.Digests[objectType], c.Digests[objectType]))
+		}
+	}
+	return mismatches
+}
+
+func unionKeys(a map[string]int64, b map[string]int64) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string]int64{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,71 @@
+package model
+
+// CirculationObjectType blockchain object type
+const CirculationObjectType = "Circulation"
+
+// Circulation holds running totals of the account balances in a currency. It
+// is updated by every invocation that changes them, so the totals never need
+// a scan of all accounts.
+type Circulation struct {
+	Entity
+	CurrencyCode string `json:"currency"`
+	Circulating  int64  `json:"circulating"` // balances of all accounts in cents
+	Frozen       int64  `json:"frozen"`      // balances of frozen accounts in cents
+	Updated      int64  `json:"updated"`     // unix timestamp
+	TxID         string `json:"tx_id"`       // ledger transaction of the last update
+}
+
+// CurrencyMetrics combines the emitted supply of a currency with its circulation
+type CurrencyMetrics struct {
+	CurrencyCode string `json:"currency"`
+	Minted       int64  `json:"minted"`      // amount in cents
+	Burned       int64  `json:"burned"`      // amount in cents
+	Supply       int64  `json:"supply"`      // amount in cents, minted less burned
+	Circulating  int64  `json:"circulating"` // balances of all accounts in cents
+	Frozen       int64  `json:"frozen"`      // balances of frozen accounts in cents
+	Updated      int64  `json:"updated,omitempty"`
+}
+
+// CreateCirculation a factory function for the empty circulation of a currency
+func CreateCirculation(currency string) *Circulation {
+	return &Circulation{Entity: Entity{CirculationObjectType}, CurrencyCode: currency}
+}
+
+// Add counts the balance of an account in the circulation, or removes it
+// again if sign is -1
+func (c *Circulation) Add(a *Account, sign int64) {
+	c.Circulating += sign * a.Balance
+	if a.Status == AccountFrozen {
+		c.Frozen += sign * a.Balance
+	}
+}
+
+// Empty returns true if both totals are zero
+func (c *Circulation) Empty() bool {
+	return c.Circulating == 0 && c.Frozen == 0
+}
+
+// Apply adds the change of the totals made in a transaction
+func (c *Circulation) Apply(change *Circulation, tx *TxContext) {
+	c.Circulating += change.Circulating
+	c.Frozen += change.Frozen
+	c.Updated = tx.Time.Unix()
+	c.TxID = tx.ID
+}
+
+// NewCurrencyMetrics combines the supply and circulation of a currency
+func NewCurrencyMetrics(s *Supply, c *Circulation) *CurrencyMetrics {
+	updated := s.Updated
+	if c.Updated > updated {
+		updated = c.Updated
+	}
+	return &CurrencyMetrics{
+		CurrencyCode: s.CurrencyCode,
+		Minted:       s.Minted,
+		Burned:       s.Burned,
+		Supply:       s.Total,
+		Circulating:  c.Circulating,
+		Frozen:       c.Frozen,
+		Updated:      updated,
+	}
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// CollateralObjectType blockchain object type
+	CollateralObjectType = "Collateral"
+	// ExposureObjectType blockchain object type
+	ExposureObjectType = "BilateralExposure"
+)
+
+// CollateralStatus stores allowed values for a collateral's status.
+// Allowed values are "pledged", "released"
+type CollateralStatus string
+
+const (
+	// CollateralPledged collateral status
+	CollateralPledged CollateralStatus = "pledged"
+	// CollateralReleased collateral status
+	CollateralReleased CollateralStatus = "released"
+)
+
+// Collateral is an asset pledged by a bank against its exposure to a counterparty
+type Collateral struct {
+	Entity
+	ID             string            `json:"id"`
+	BankID         string            `json:"bank_id"`         // pledging bank
+	CounterpartyID string            `json:"counterparty_id"` // bank the collateral is pledged to
+	CurrencyCode   string            `json:"currency"`
+	Type           string            `json:"type"`           // e.g. "cash", "government_bond"
+	PledgedAmount  int64             `json:"pledged_amount"` // nominal amount in cents
+	MarketValue    int64             `json:"market_value"`   // latest marked value in cents
+	Haircut        int64             `json:"haircut"`        // haircut in basis points
+	Status         CollateralStatus  `json:"status"`
+	Created        int64             `json:"created"`     // unix timestamp
+	LastMarked     int64             `json:"last_marked"` // unix timestamp
+	Params         map[string]string `json:"params,omitempty"`
+}
+
+// BilateralExposure tracks what a bank owes a counterparty in a currency and
+// the uncollateralized amount it is allowed to owe
+type BilateralExposure struct {
+	Entity
+	BankID          string   `json:"bank_id"`
+	CounterpartyID  string   `json:"counterparty_id"`
+	CurrencyCode    string   `json:"currency"`
+	Limit           int64    `json:"limit"`            // maximum uncollateralized exposure in cents
+	Exposure        int64    `json:"exposure"`         // gross amount owed in cents
+	CollateralValue int64    `json:"collateral_value"` // post-haircut value of pledged collateral
+	CollateralIDs   []string `json:"collateral_ids"`
+}
+
+// CreateCollateral Factory function creates a new Collateral struct and returns a pointer to it
+func CreateCollateral(collateralBytes []byte, tx *TxContext) (*Collateral, error) {
+	c := new(Collateral)
+	if err := json.Unmarshal(collateralBytes, c); err != nil {
+		return nil, err
+	}
+	c.ObjectType = CollateralObjectType
+	if c.BankID == "" || c.CounterpartyID == "" {
+		return nil, errors.New("Missing required bank_id and / or counterparty_id")
+	}
+	if c.CurrencyCode == "" {
+		return nil, errors.New("Missing required currency value")
+	}
+	if c.Type == "" {
+		return nil, errors.New("Missing required type value")
+	}
+	if c.PledgedAmount <= 0 {
+		return nil, fmt.Errorf("Invalid pledged amount %d", c.PledgedAmount)
+	}
+	if c.Haircut < 0 || c.Haircut > 10000 {
+		return nil, fmt.Errorf("Invalid haircut %d", c.Haircut)
+	}
+	if c.ID == "" {
+		c.ID = tx.NewID(12)
+	}
+	if c.MarketValue == 0 {
+		c.MarketValue = c.PledgedAmount
+	}
+	if c.Created == 0 {
+		c.Created = tx.Time.Unix()
+	}
+	c.LastMarked = c.Created
+	c.Status = CollateralPledged
+	return c, nil
+}
+
+// Value returns the collateral value after applying the haircut
+func (c *Collateral) Value() int64 {
+	if c.Status != CollateralPledged {
+		return 0
+	}
+	return c.MarketValue * (10000 - c.Haircut) / 10000
+}
+
+// CreateBilateralExposure Factory function creates a new BilateralExposure struct and returns a pointer to it
+func CreateBilateralExposure(bankID string, counterpartyID string, currency string) *BilateralExposure {
+	return &BilateralExposure{
+		Entity:         Entity{ExposureObjectType},
+		BankID:         bankID,
+		CounterpartyID: counterpartyID,
+		CurrencyCode:   currency,
+	}
+}
+
+// Uncollateralized returns the exposure not covered by collateral
+func (e *BilateralExposure) Uncollateralized() int64 {
+	if e.Exposure <= e.CollateralValue {
+		return 0
+	}
+	return e.Exposure - e.CollateralValue
+}
+
+// CanIncrease checks whether the exposure can grow by amount without
+// exceeding the uncollateralized limit
+func (e *BilateralExposure) CanIncrease(amount int64) bool {
+	return e.Exposure+amount-e.CollateralValue <= e.Limit
+}
+
+// AddCollateral links a pledged collateral to the exposure
+func (e *BilateralExposure) AddCollateral(c *Collateral) {
+	e.CollateralIDs = append(e.CollateralIDs, c.ID)
+	e.CollateralValue += c.Value()
+}
+
+// RemoveCollateral unlinks a collateral valued at value from the exposure
+func (e *BilateralExposure) RemoveCollateral(c *Collateral, value int64) {
+	for i, id := range e.CollateralIDs {
+		if id == c.ID {
+			e.CollateralIDs = append(e.CollateralIDs[:i], e.CollateralIDs[i+1:]...)
+			break
+		}
+	}
+	e.CollateralValue -= value
+}
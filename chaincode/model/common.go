@@ -14,3 +14,13 @@ type Entity struct {
 func (e *Entity) GetObjectType() string {
 	return e.ObjectType
 }
+
+// DefaultListPageSize is the page size of list queries that do not give one
+const DefaultListPageSize = 100
+
+// MaxListPageSize caps the records returned in a single list page
+const MaxListPageSize = 500
+
+// MaxListResponseSize caps the bytes of the records returned in a single list
+// page. A page whose records would exceed it ends early, flagged as truncated.
+const MaxListResponseSize = 1 << 20
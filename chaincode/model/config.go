@@ -0,0 +1,142 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ConfigObjectType blockchain object type
+const ConfigObjectType = "Config"
+
+// Config holds the deployment parameters set at instantiation
+type Config struct {
+	Entity
+	BaseCurrency         string          `json:"base_currency,omitempty" validate:"currency"`        // currency the default limits apply to
+	FeeCustomerID        string          `json:"fee_customer,omitempty" validate:"max=64"`           // customer of the fee account
+	FeeAccountID         string          `json:"fee_account,omitempty" validate:"max=64"`            // collection account of fee schedules not naming one
+	EmissionAuthorityMSP string          `json:"emission_authority_msp,omitempty" validate:"max=64"` // only callers of this MSP may mint and burn, if set
+	DefaultLimits        *LimitDefaults  `json:"default_limits,omitempty"`                           // limits of customers without limits of their own
+	Features             map[string]bool `json:"features,omitempty" validate:"max=64"`               // feature flags by name
+	CutOffTime           string          `json:"cut_off_time,omitempty"`                             // HH:MM UTC after which transfers take value the next business day
+	ClosedRetentionDays  int             `json:"closed_retention_days,omitempty" validate:"min=0"`   // days closed accounts are kept before they may be purged
+	SetBy                string          `json:"set_by"`
+	Updated              int64           `json:"updated"` // unix timestamp
+}
+
+// LimitDefaults are the transaction limits in the base currency of customers
+// without limits of their own. A zero limit is not enforced.
+type LimitDefaults struct {
+	SingleMax   int64            `json:"single_max" validate:"min=0"`
+	DailyMax    int64            `json:"daily_max" validate:"min=0"`
+	MonthlyMax  int64            `json:"monthly_max" validate:"min=0"`
+	DailyCount  int              `json:"daily_count" validate:"min=0"`
+	Enforcement LimitEnforcement `json:"enforcement" validate:"oneof=reject flag"`
+}
+
+var featurePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// Feature is the state of a feature flag and the functions it makes available
+type Feature struct {
+	Name      string   `json:"name"`
+	Enabled   bool     `json:"enabled"`
+	Functions []string `json:"functions"`
+}
+
+// ValidateFeatureName checks a feature flag name is lower case letters, digits
+// and underscores
+func ValidateFeatureName(name string) error {
+	if !featurePattern.MatchString(name) {
+		return fmt.Errorf("Invalid feature name %q", name)
+	}
+	return nil
+}
+
+// CreateConfig Factory function creates a new Config struct and returns a pointer to it
+func CreateConfig(configBytes []byte, setBy string, tx *TxContext) (*Config, error) {
+	config := new(Config)
+	if err := Unmarshal(configBytes, config); err != nil {
+		return nil, err
+	}
+	if config.DefaultLimits != nil {
+		if err := ValidateFields(config.DefaultLimits); err != nil {
+			for _, f := range err.(*ValidationError).Fields {
+				f.Field = "default_limits." + f.Field
+			}
+			return nil, err
+		}
+		if config.BaseCurrency == "" {
+			return nil, &ValidationError{Fields: []*FieldError{{Field: "base_currency", Rule: "required", Message: "is required with default_limits"}}}
+		}
+		if config.DefaultLimits.Enforcement == "" {
+			config.DefaultLimits.Enforcement = LimitReject
+		}
+	}
+	for name := range config.Features {
+		if err := ValidateFeatureName(name); err != nil {
+			return nil, &ValidationError{Fields: []*FieldError{{Field: "features", Rule: "feature", Message: err.Error()}}}
+		}
+	}
+	if config.CutOffTime != "" {
+		if err := ValidateCutOffTime(config.CutOffTime); err != nil {
+			return nil, &ValidationError{Fields: []*FieldError{{Field: "cut_off_time", Rule: "time", Message: err.Error()}}}
+		}
+	}
+	if config.ClosedRetentionDays != 0 && config.ClosedRetentionDays < MinRetentionDays {
+		return nil, &ValidationError{Fields: []*FieldError{{Field: "closed_retention_days", Rule: "min", Message: fmt.Sprintf("must be at least %d", MinRetentionDays)}}}
+	}
+	if (config.FeeCustomerID == "") != (config.FeeAccountID == "") {
+		return nil, &ValidationError{Fields: []*FieldError{{Field: "fee_account", Rule: "required", Message: "fee_customer and fee_account must be set together"}}}
+	}
+	config.ObjectType = ConfigObjectType
+	config.SetBy = setBy
+	config.Updated = tx.Time.Unix()
+	return config, nil
+}
+
+// LimitsFor returns the default limits of a customer in a currency, or nil if
+// no defaults apply to the currency
+func (c *Config) LimitsFor(customerID string, currencyCode string) *Limits {
+	if c.DefaultLimits == nil || currencyCode != c.BaseCurrency {
+		return nil
+	}
+	d := c.DefaultLimits
+	return &Limits{
+		Entity:       Entity{LimitsObjectType},
+		CustomerID:   customerID,
+		CurrencyCode: currencyCode,
+		SingleMax:    d.SingleMax,
+		DailyMax:     d.DailyMax,
+		MonthlyMax:   d.MonthlyMax,
+		DailyCount:   d.DailyCount,
+		Enforcement:  d.Enforcement,
+		SetBy:        c.SetBy,
+		Updated:      c.Updated,
+	}
+}
+
+// ClosedRetention returns the number of days closed accounts are kept
+func (c *Config) ClosedRetention() int {
+	if c.ClosedRetentionDays == 0 {
+		return DefaultClosedRetentionDays
+	}
+	return c.ClosedRetentionDays
+}
+
+// FeatureEnabled returns true if the feature flag is set
+func (c *Config) FeatureEnabled(name string) bool {
+	return c.Features[name]
+}
+
+// SetFeature enables or disables a feature flag
+func (c *Config) SetFeature(name string, enabled bool, setBy string, tx *TxContext) {
+	if enabled {
+		if c.Features == nil {
+			c.Features = make(map[string]bool)
+		}
+		c.Features[name] = true
+	} else {
+		delete(c.Features, name)
+	}
+	c.SetBy = setBy
+	c.Updated = tx.Time.Unix()
+}
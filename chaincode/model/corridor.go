@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+)
+
+// CorridorBucketObjectType blockchain object type
+const CorridorBucketObjectType = "CorridorBucket"
+
+// CorridorBucket holds the incremental daily totals of a payment corridor
+// (sending country to receiving country) in a currency
+type CorridorBucket struct {
+	Entity
+	Corridor          string `json:"corridor"` // e.g. "AU-NZ"
+	CurrencyCode      string `json:"currency"`
+	Date              string `json:"date"`               // YYYY-MM-DD
+	Settled           int64  `json:"settled"`            // number of settled transfers
+	Failed            int64  `json:"failed"`             // number of failed transfers
+	Volume            int64  `json:"volume"`             // settled amount in cents
+	Fees              int64  `json:"fees"`               // fees of settled transfers in cents
+	SettlementSeconds int64  `json:"settlement_seconds"` // total time from initiation to settlement
+}
+
+// CorridorStats aggregates the buckets of a corridor over a time window
+type CorridorStats struct {
+	Corridor                 string            `json:"corridor"`
+	CurrencyCode             string            `json:"currency"`
+	From                     string            `json:"from"`
+	To                       string            `json:"to"`
+	Settled                  int64             `json:"settled"`
+	Failed                   int64             `json:"failed"`
+	Volume                   int64             `json:"volume"`
+	Fees                     int64             `json:"fees"`
+	FailureRate              float64           `json:"failure_rate"`               // failed / (settled + failed)
+	AverageSettlementSeconds float64           `json:"average_settlement_seconds"` // per settled transfer
+	Buckets                  []*CorridorBucket `json:"buckets"`
+	settlementSeconds        int64
+}
+
+// CorridorID returns the corridor identifier of two country codes
+func CorridorID(fromCountry string, toCountry string) string {
+	return fromCountry + "-" + toCountry
+}
+
+// CreateCorridorBucket Factory function creates an empty bucket for a corridor, currency and day
+func CreateCorridorBucket(corridor string, currency string, date string) *CorridorBucket {
+	return &CorridorBucket{Entity: Entity{CorridorBucketObjectType}, Corridor: corridor, CurrencyCode: currency, Date: date}
+}
+
+// Settle adds a settled transfer to the bucket
+func (b *CorridorBucket) Settle(t *Transfer, now time.Time) {
+	b.Settled++
+	b.Volume += t.Amount
+	b.Fees += t.Fee
+	if t.Initiated > 0 && now.Unix() > t.Initiated {
+		b.SettlementSeconds += now.Unix() - t.Initiated
+	}
+}
+
+// Fail adds a failed transfer to the bucket
+func (b *CorridorBucket) Fail() {
+	b.Failed++
+}
+
+// CreateCorridorStats Factory function creates empty stats for a corridor window
+func CreateCorridorStats(corridor string, currency string, from string, to string) *CorridorStats {
+	return &CorridorStats{Corridor: corridor, CurrencyCode: currency, From: from, To: to, Buckets: []*CorridorBucket{}}
+}
+
+// Add aggregates a bucket into the stats if it falls within the window
+func (s *CorridorStats) Add(b *CorridorBucket) {
+	if (s.From != "" && b.Date < s.From) || (s.To != "" && b.Date > s.To) {
+		return
+	}
+	s.Buckets = append(s.Buckets, b)
+	s.Settled += b.Settled
+	s.Failed += b.Failed
+	s.Volume += b.Volume
+	s.Fees += b.Fees
+	s.settlementSeconds += b.SettlementSeconds
+	if total := s.Settled + s.Failed; total > 0 {
+		s.FailureRate = float64(s.Failed) / float64(total)
+	}
+	if s.Settled > 0 {
+		s.AverageSettlementSeconds = float64(s.settlementSeconds) / float64(s.Settled)
+	}
+}
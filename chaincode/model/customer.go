@@ -0,0 +1,170 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// CustomerObjectType blockchain object type
+const CustomerObjectType = "Customer"
+
+// CustomerResidency stores allowed values for a customer's tax residency.
+// Allowed values are "resident", "non_resident"
+type CustomerResidency string
+
+const (
+	// Resident customer is tax resident in their country
+	Resident CustomerResidency = "resident"
+	// NonResident customer is not tax resident in their country
+	NonResident CustomerResidency = "non_resident"
+)
+
+var (
+	countryPattern     = regexp.MustCompile("^[A-Z]{2}$")
+	contactHashPattern = regexp.MustCompile("^[0-9a-f]{64}$")
+)
+
+// Customer is a registered holder of accounts. Only a hash of the customer's
+// contact details is stored on the ledger.
+type Customer struct {
+	Entity
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	CountryCode  string            `json:"country"` // ISO 3166-1 alpha-2 code
+	Residency    CustomerResidency `json:"residency"`
+	ContactHash  string            `json:"contact_hash,omitempty"` // hex SHA-256 of the contact details
+	RiskTier     KYCRiskRating     `json:"risk_tier"`
+	RegisteredBy string            `json:"registered_by"`
+	Created      int64             `json:"created"`           // unix timestamp
+	Updated      int64             `json:"updated,omitempty"` // unix timestamp of the last update
+	TxID         string            `json:"tx_id,omitempty"`   // ledger transaction of the last write
+}
+
+// CreateCustomer Factory function creates a new Customer struct and returns a pointer to it
+func CreateCustomer(customerBytes []byte, registeredBy string, tx *TxContext) (*Customer, error) {
+	customer := new(Customer)
+	if err := json.Unmarshal(customerBytes, customer); err != nil {
+		return nil, err
+	}
+	if customer.ID == "" {
+		return nil, errors.New("Missing required id value")
+	}
+	if err := customer.validate(); err != nil {
+		return nil, err
+	}
+	customer.ObjectType = CustomerObjectType
+	customer.RegisteredBy = registeredBy
+	customer.Created = tx.Time.Unix()
+	customer.Updated = 0
+	customer.TxID = tx.ID
+	return customer, nil
+}
+
+// Update replaces the customer's profile with that of the update, keeping its
+// ID and registration
+func (c *Customer) Update(updateBytes []byte, tx *TxContext) error {
+	update := new(Customer)
+	if err := json.Unmarshal(updateBytes, update); err != nil {
+		return err
+	}
+	if update.ID != "" && update.ID != c.ID {
+		return fmt.Errorf("Customer ID %s does not match %s", update.ID, c.ID)
+	}
+	if err := update.validate(); err != nil {
+		return err
+	}
+	c.Name = update.Name
+	c.CountryCode = update.CountryCode
+	c.Residency = update.Residency
+	c.ContactHash = update.ContactHash
+	c.RiskTier = update.RiskTier
+	c.Updated = tx.Time.Unix()
+	c.TxID = tx.ID
+	return nil
+}
+
+func (c *Customer) validate() error {
+	if c.Name == "" {
+		return errors.New("Missing required name value")
+	}
+	if !countryPattern.MatchString(c.CountryCode) {
+		return fmt.Errorf("Invalid country %s, expected an ISO 3166-1 alpha-2 code", c.CountryCode)
+	}
+	if c.Residency != Resident && c.Residency != NonResident {
+		return fmt.Errorf("Invalid residency %s", c.Residency)
+	}
+	if c.ContactHash != "" && !contactHashPattern.MatchString(c.ContactHash) {
+		return errors.New("Invalid contact_hash, expected a hex SHA-256 digest")
+	}
+	if c.RiskTier != KYCRiskLow && c.RiskTier != KYCRiskMedium && c.RiskTier != KYCRiskHigh {
+		return fmt.Errorf("Invalid risk tier %s", c.RiskTier)
+	}
+	return nil
+}
+
+// PrivateFields returns the customer fields kept in the bank's private data collection
+func (c *Customer) PrivateFields() []string {
+	return []string{"name", "contact_hash"}
+}
+
+// CustomerSummary aggregates the accounts of a customer
+type CustomerSummary struct {
+	CustomerID     string             `json:"customer_id"`
+	OpenAccounts   int                `json:"open_accounts"` // active, frozen and dormant accounts
+	ClosedAccounts int                `json:"closed_accounts"`
+	LastActivity   int64              `json:"last_activity,omitempty"` // unix timestamp of the last debit or credit of any account
+	Balances       []*CurrencyBalance `json:"balances"`                // ordered by currency
+}
+
+// CurrencyBalance totals the accounts of a customer in one currency
+type CurrencyBalance struct {
+	CurrencyCode string `json:"currency"`
+	Accounts     int    `json:"accounts"`
+	Balance      int64  `json:"balance"`
+	Available    int64  `json:"available"`               // balance plus overdraft limits less held
+	LastActivity int64  `json:"last_activity,omitempty"` // unix timestamp of the last debit or credit in the currency
+}
+
+// SummarizeAccounts totals the balances of a customer's accounts per
+// currency and counts its open and closed accounts. Fails if a total would
+// overflow.
+func SummarizeAccounts(customerID string, accounts []*Account) (*CustomerSummary, error) {
+	summary := &CustomerSummary{CustomerID: customerID, Balances: []*CurrencyBalance{}}
+	byCurrency := make(map[string]*CurrencyBalance)
+	for _, a := range accounts {
+		if a.IsClosed() {
+			summary.ClosedAccounts++
+		} else {
+			summary.OpenAccounts++
+		}
+		total, ok := byCurrency[a.CurrencyCode]
+		if !ok {
+			total = &CurrencyBalance{CurrencyCode: a.CurrencyCode}
+			byCurrency[a.CurrencyCode] = total
+			summary.Balances = append(summary.Balances, total)
+		}
+		balance, err := a.Money(total.Balance).Add(a.Money(a.Balance))
+		if err != nil {
+			return nil, fmt.Errorf("Cannot total %s balances of customer %s. Error: %s", a.CurrencyCode, customerID, err)
+		}
+		available, err := a.Money(total.Available).Add(a.Money(a.Available()))
+		if err != nil {
+			return nil, fmt.Errorf("Cannot total %s balances of customer %s. Error: %s", a.CurrencyCode, customerID, err)
+		}
+		total.Accounts++
+		total.Balance, total.Available = balance.Amount, available.Amount
+		if a.LastActivity > total.LastActivity {
+			total.LastActivity = a.LastActivity
+		}
+		if a.LastActivity > summary.LastActivity {
+			summary.LastActivity = a.LastActivity
+		}
+	}
+	sort.Slice(summary.Balances, func(i, j int) bool {
+		return summary.Balances[i].CurrencyCode < summary.Balances[j].CurrencyCode
+	})
+	return summary, nil
+}
@@ -0,0 +1,36 @@
+package model
+
+import "fmt"
+
+// Disbursement is a single payment of a bulk disbursement from one account
+type Disbursement struct {
+	CustomerID  string `json:"customer_id"`
+	AccountID   string `json:"account_id"`
+	Amount      int64  `json:"amount"` // amount in cents
+	Description string `json:"description,omitempty"`
+}
+
+// Validate - checks that required are present in the disbursement object
+func (d *Disbursement) Validate() error {
+	if d.CustomerID == "" || d.AccountID == "" {
+		return fmt.Errorf("Missing required customer_id and / or account_id")
+	}
+	if d.Amount <= 0 {
+		return fmt.Errorf("Invalid disbursement amount %d", d.Amount)
+	}
+	return nil
+}
+
+// Transfer builds the transfer of this disbursement from the given account
+func (d *Disbursement) Transfer(from *Account, params map[string]string) *Transfer {
+	return &Transfer{
+		FromCustomerID: from.CustomerID,
+		FromAccountID:  from.ID,
+		ToCustomerID:   d.CustomerID,
+		ToAccountID:    d.AccountID,
+		Amount:         d.Amount,
+		CurrencyCode:   from.CurrencyCode,
+		Description:    d.Description,
+		Params:         params,
+	}
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DisputeObjectType blockchain object type
+const DisputeObjectType = "Dispute"
+
+// DisputeStatus stores allowed values for a dispute's status.
+// Allowed values are "open", "refunded", "rejected"
+type DisputeStatus string
+
+const (
+	// DisputeOpen dispute awaiting resolution, its amount is held on the payee account
+	DisputeOpen DisputeStatus = "open"
+	// DisputeRefunded dispute resolved by reversing the disputed amount to the payer
+	DisputeRefunded DisputeStatus = "refunded"
+	// DisputeRejected dispute resolved in favour of the payee
+	DisputeRejected DisputeStatus = "rejected"
+)
+
+// DisputeOutcome stores allowed values for the resolution of a dispute.
+// Allowed values are "refund", "reject"
+type DisputeOutcome string
+
+const (
+	// DisputeRefund outcome reverses the disputed amount to the payer
+	DisputeRefund DisputeOutcome = "refund"
+	// DisputeReject outcome releases the disputed amount to the payee
+	DisputeReject DisputeOutcome = "reject"
+)
+
+// MaxDisputeReason caps the length of a dispute reason
+const MaxDisputeReason = 255
+
+// Dispute is a payer's claim against a transfer it made. While the dispute is
+// open the disputed amount is held on the payee account.
+type Dispute struct {
+	Entity
+	ID              string        `json:"id"` // ID of the disputed transaction record
+	CustomerID      string        `json:"customer_id"`
+	AccountID       string        `json:"account_id"`
+	TxID            string        `json:"tx_id"` // ledger transaction of the disputed transfer
+	PayeeCustomerID string        `json:"payee_customer"`
+	PayeeAccountID  string        `json:"payee_account"`
+	Amount          int64         `json:"amount"` // amount credited to the payee in cents
+	CurrencyCode    string        `json:"currency"`
+	Reason          string        `json:"reason"`
+	HoldID          string        `json:"hold_id"` // hold of the amount on the payee account
+	Status          DisputeStatus `json:"status"`
+	OpenedBy        string        `json:"opened_by"`
+	Opened          int64         `json:"opened"` // unix timestamp
+	ResolvedBy      string        `json:"resolved_by,omitempty"`
+	Resolved        int64         `json:"resolved,omitempty"`    // unix timestamp
+	ReversalTxID    string        `json:"reversal_tx,omitempty"` // ledger transaction that refunded the payer
+}
+
+// DisputeList holds a list of disputes
+type DisputeList struct {
+	Disputes []*Dispute `json:"disputes"`
+}
+
+// CreateDispute a factory function for an open dispute of a transfer debited
+// from the payer's account. Only same currency transfers can be disputed.
+func CreateDispute(txn *Transaction, reason string, holdID string, openedBy string, tx *TxContext) (*Dispute, error) {
+	if reason == "" {
+		return nil, errors.New("Missing required dispute reason")
+	}
+	if len(reason) > MaxDisputeReason {
+		return nil, fmt.Errorf("Dispute reason exceeds %d characters", MaxDisputeReason)
+	}
+	if txn.Status != Debited || txn.Type != "" || txn.CounterpartyAccountID == "" {
+		return nil, fmt.Errorf("Transaction %s is not a transfer debited from account %s", txn.ID, txn.AccountID)
+	}
+	if txn.Conversion != nil {
+		return nil, fmt.Errorf("Cannot dispute cross-currency transaction %s", txn.ID)
+	}
+	amount := txn.Amount
+	if txn.Withholding != nil {
+		amount = txn.Withholding.NetAmount
+	}
+	return &Dispute{
+		Entity:          Entity{DisputeObjectType},
+		ID:              txn.ID,
+		CustomerID:      txn.CustomerID,
+		AccountID:       txn.AccountID,
+		TxID:            txn.TxID,
+		PayeeCustomerID: txn.CounterpartyCustomerID,
+		PayeeAccountID:  txn.CounterpartyAccountID,
+		Amount:          amount,
+		CurrencyCode:    txn.CurrencyCode,
+		Reason:          reason,
+		HoldID:          holdID,
+		Status:          DisputeOpen,
+		OpenedBy:        openedBy,
+		Opened:          tx.Time.Unix(),
+	}, nil
+}
+
+// Resolve closes the dispute with the given outcome
+func (d *Dispute) Resolve(outcome DisputeOutcome, by string, tx *TxContext) error {
+	switch outcome {
+	case DisputeRefund:
+		d.Status = DisputeRefunded
+		d.ReversalTxID = tx.ID
+	case DisputeReject:
+		d.Status = DisputeRejected
+	default:
+		return fmt.Errorf("Invalid dispute outcome %q, must be %s or %s", outcome, DisputeRefund, DisputeReject)
+	}
+	d.ResolvedBy = by
+	d.Resolved = tx.Time.Unix()
+	return nil
+}
+
+// Reversal returns the transfer refunding the disputed amount from the payee to the payer
+func (d *Dispute) Reversal() *Transfer {
+	return &Transfer{
+		FromCustomerID: d.PayeeCustomerID,
+		FromAccountID:  d.PayeeAccountID,
+		ToCustomerID:   d.CustomerID,
+		ToAccountID:    d.AccountID,
+		Amount:         d.Amount,
+		CurrencyCode:   d.CurrencyCode,
+		Description:    "Chargeback of transaction " + d.ID,
+		Params:         map[string]string{"initiated_by": "system", "dispute": d.ID},
+		Type:           TxTypeChargeback,
+	}
+}
@@ -0,0 +1,262 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// SupplyObjectType blockchain object type
+	SupplyObjectType = "Supply"
+	// EmissionRecordObjectType blockchain object type
+	EmissionRecordObjectType = "EmissionRecord"
+	// EmissionPolicyObjectType blockchain object type
+	EmissionPolicyObjectType = "EmissionPolicy"
+	// EmissionProposalObjectType blockchain object type
+	EmissionProposalObjectType = "EmissionProposal"
+)
+
+// EmissionOperation stores allowed values for an emission record's operation.
+// Allowed values are "mint", "burn"
+type EmissionOperation string
+
+const (
+	// Mint issues new money into an account
+	Mint EmissionOperation = "mint"
+	// Burn removes money from an account and from circulation
+	Burn EmissionOperation = "burn"
+)
+
+// EmissionProposalStatus stores allowed values for an emission proposal's status.
+// Allowed values are "pending_approval", "approved", "executed"
+type EmissionProposalStatus string
+
+const (
+	// EmissionPendingApproval proposal is collecting approvals
+	EmissionPendingApproval EmissionProposalStatus = "pending_approval"
+	// EmissionApproved proposal reached its quorum and may be executed
+	EmissionApproved EmissionProposalStatus = "approved"
+	// EmissionExecuted proposal was minted or burned
+	EmissionExecuted EmissionProposalStatus = "executed"
+)
+
+// Supply tracks the total money issued in a currency by the emission authority
+type Supply struct {
+	Entity
+	CurrencyCode string `json:"currency"`
+	Total        int64  `json:"total"`  // amount in cents, minted less burned
+	Minted       int64  `json:"minted"` // amount in cents
+	Burned       int64  `json:"burned"` // amount in cents
+	Updated      int64  `json:"updated"`
+}
+
+// EmissionRecord is the immutable audit record of a single mint or burn
+type EmissionRecord struct {
+	Entity
+	Operation    EmissionOperation `json:"operation"`
+	CurrencyCode string            `json:"currency"`
+	Amount       int64             `json:"amount"` // amount in cents
+	CustomerID   string            `json:"customer_id"`
+	AccountID    string            `json:"account_id"`
+	Reference    string            `json:"reference,omitempty"`
+	Authority    string            `json:"authority"` // identity of the emission authority
+	TxID         string            `json:"tx_id"`
+	SupplyAfter  int64             `json:"supply_after"`
+	Created      int64             `json:"created"`               // unix timestamp
+	ProposalID   string            `json:"proposal_id,omitempty"` // proposal the operation executed, if approved by quorum
+	ProposedBy   string            `json:"proposed_by,omitempty"`
+	Approvals    []*Approval       `json:"approvals,omitempty"`
+}
+
+// EmissionRecordList holds a list of emission records
+type EmissionRecordList struct {
+	Records []*EmissionRecord `json:"records"`
+}
+
+// EmissionPolicy requires mints and burns in a currency above a threshold to
+// be proposed and approved by a quorum of distinct emission authority identities
+type EmissionPolicy struct {
+	Entity
+	CurrencyCode      string `json:"currency"`
+	Threshold         int64  `json:"threshold"` // amount in cents above which approval is required
+	RequiredApprovals int    `json:"required_approvals"`
+	SetBy             string `json:"set_by"`
+	Updated           int64  `json:"updated"` // unix timestamp
+}
+
+// EmissionProposal is a mint or burn awaiting the approvals its policy requires
+type EmissionProposal struct {
+	Entity
+	ID                string                 `json:"id"`
+	Operation         EmissionOperation      `json:"operation"`
+	CurrencyCode      string                 `json:"currency"`
+	Amount            int64                  `json:"amount"` // amount in cents
+	CustomerID        string                 `json:"customer_id"`
+	AccountID         string                 `json:"account_id"`
+	Reference         string                 `json:"reference,omitempty"`
+	RequiredApprovals int                    `json:"required_approvals"`
+	Approvals         []*Approval            `json:"approvals"`
+	Status            EmissionProposalStatus `json:"status"`
+	ProposedBy        string                 `json:"proposed_by"`
+	Created           int64                  `json:"created"` // unix timestamp
+	ExecutedBy        string                 `json:"executed_by,omitempty"`
+	Executed          int64                  `json:"executed,omitempty"` // unix timestamp
+	ExecutedTxID      string                 `json:"executed_tx_id,omitempty"`
+}
+
+// EmissionProposalList holds a list of emission proposals
+type EmissionProposalList struct {
+	Proposals []*EmissionProposal `json:"proposals"`
+}
+
+// CreateSupply a factory function for the empty supply of a currency
+func CreateSupply(currency string) *Supply {
+	return &Supply{Entity: Entity{SupplyObjectType}, CurrencyCode: currency}
+}
+
+// Apply updates the supply by a mint or burn of amount
+func (s *Supply) Apply(op EmissionOperation, amount int64, tx *TxContext) error {
+	switch op {
+	case Mint:
+		s.Minted += amount
+		s.Total += amount
+	case Burn:
+		if amount > s.Total {
+			return fmt.Errorf("Burn of %d exceeds %s supply of %d", amount, s.CurrencyCode, s.Total)
+		}
+		s.Burned += amount
+		s.Total -= amount
+	default:
+		return fmt.Errorf("Invalid emission operation %s", op)
+	}
+	s.Updated = tx.Time.Unix()
+	return nil
+}
+
+// CreateEmissionRecord a factory function for the audit record of a mint or
+// burn of the amount into an account
+func CreateEmissionRecord(op EmissionOperation, a *Account, amount int64, reference string, authority string, tx *TxContext) *EmissionRecord {
+	return &EmissionRecord{
+		Entity:       Entity{EmissionRecordObjectType},
+		Operation:    op,
+		CurrencyCode: a.CurrencyCode,
+		Amount:       amount,
+		CustomerID:   a.CustomerID,
+		AccountID:    a.ID,
+		Reference:    reference,
+		Authority:    authority,
+		TxID:         tx.ID,
+		Created:      tx.Time.Unix(),
+	}
+}
+
+// Transfer returns the transfer recorded against the account of an emission
+func (r *EmissionRecord) Transfer() *Transfer {
+	t := &Transfer{
+		Amount:       r.Amount,
+		CurrencyCode: r.CurrencyCode,
+		Description:  fmt.Sprintf("Emission %s", r.Operation),
+		Params:       map[string]string{"initiated_by": "system", "emission_tx_id": r.TxID},
+	}
+	if r.Operation == Mint {
+		t.ToCustomerID, t.ToAccountID = r.CustomerID, r.AccountID
+	} else {
+		t.FromCustomerID, t.FromAccountID = r.CustomerID, r.AccountID
+	}
+	if r.Reference != "" {
+		t.Params["reference"] = r.Reference
+	}
+	return t
+}
+
+// CreateEmissionPolicy Factory function creates a new EmissionPolicy struct and returns a pointer to it
+func CreateEmissionPolicy(policyBytes []byte, setBy string, tx *TxContext) (*EmissionPolicy, error) {
+	policy := new(EmissionPolicy)
+	if err := json.Unmarshal(policyBytes, policy); err != nil {
+		return nil, err
+	}
+	policy.ObjectType = EmissionPolicyObjectType
+	if err := ValidateCurrency(policy.CurrencyCode); err != nil {
+		return nil, err
+	}
+	if policy.Threshold < 0 {
+		return nil, errors.New("Invalid negative threshold")
+	}
+	if policy.RequiredApprovals < 1 {
+		return nil, fmt.Errorf("Invalid required approvals %d, must be at least 1", policy.RequiredApprovals)
+	}
+	policy.SetBy = setBy
+	policy.Updated = tx.Time.Unix()
+	return policy, nil
+}
+
+// Requires returns true if an emission of the amount needs approval under the policy
+func (p *EmissionPolicy) Requires(amount int64) bool {
+	return amount > p.Threshold
+}
+
+// CreateEmissionProposal a factory function for a mint or burn awaiting approval
+func CreateEmissionProposal(op EmissionOperation, a *Account, amount int64, reference string, required int, proposedBy string, tx *TxContext) (*EmissionProposal, error) {
+	if op != Mint && op != Burn {
+		return nil, fmt.Errorf("Invalid emission operation %s", op)
+	}
+	return &EmissionProposal{
+		Entity:            Entity{EmissionProposalObjectType},
+		ID:                tx.NewID(16),
+		Operation:         op,
+		CurrencyCode:      a.CurrencyCode,
+		Amount:            amount,
+		CustomerID:        a.CustomerID,
+		AccountID:         a.ID,
+		Reference:         reference,
+		RequiredApprovals: required,
+		Approvals:         []*Approval{},
+		Status:            EmissionPendingApproval,
+		ProposedBy:        proposedBy,
+		Created:           tx.Time.Unix(),
+	}, nil
+}
+
+// Approve records an approval. The proposer may not approve their own
+// proposal and no identity may approve twice.
+func (p *EmissionProposal) Approve(approver string, tx *TxContext) error {
+	if p.Status != EmissionPendingApproval {
+		return fmt.Errorf("Emission proposal %s is not pending approval", p.ID)
+	}
+	if approver == p.ProposedBy {
+		return fmt.Errorf("Emission proposal %s cannot be approved by its proposer", p.ID)
+	}
+	for _, approval := range p.Approvals {
+		if approval.Signer == approver {
+			return fmt.Errorf("Emission proposal %s already approved by this approver", p.ID)
+		}
+	}
+	p.Approvals = append(p.Approvals, &Approval{Signer: approver, TxID: tx.ID, Approved: tx.Time.Unix()})
+	if len(p.Approvals) >= p.RequiredApprovals {
+		p.Status = EmissionApproved
+	}
+	return nil
+}
+
+// Record returns the audit record of the executed proposal, naming the
+// proposer and every approver
+func (p *EmissionProposal) Record(a *Account, authority string, tx *TxContext) *EmissionRecord {
+	record := CreateEmissionRecord(p.Operation, a, p.Amount, p.Reference, authority, tx)
+	record.ProposalID = p.ID
+	record.ProposedBy = p.ProposedBy
+	record.Approvals = p.Approvals
+	return record
+}
+
+// Execute records the execution of an approved proposal
+func (p *EmissionProposal) Execute(executedBy string, tx *TxContext) error {
+	if p.Status != EmissionApproved {
+		return fmt.Errorf("Emission proposal %s is %s, not approved", p.ID, p.Status)
+	}
+	p.Status = EmissionExecuted
+	p.ExecutedBy = executedBy
+	p.Executed = tx.Time.Unix()
+	p.ExecutedTxID = tx.ID
+	return nil
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// EscheatmentPolicyObjectType blockchain object type
+	EscheatmentPolicyObjectType = "EscheatmentPolicy"
+	// EscheatmentObjectType blockchain object type
+	EscheatmentObjectType = "Escheatment"
+)
+
+// EscheatmentStatus stores allowed values for an escheatment record's status.
+// Allowed values are "escheated", "reclaimed"
+type EscheatmentStatus string
+
+const (
+	// Escheated balance is held in the unclaimed-property account
+	Escheated EscheatmentStatus = "escheated"
+	// EscheatReclaimed balance was returned to the customer
+	EscheatReclaimed EscheatmentStatus = "reclaimed"
+)
+
+// EscheatmentPolicy holds the statutory dormancy period and unclaimed-property account of a currency
+type EscheatmentPolicy struct {
+	Entity
+	CurrencyCode        string `json:"currency"`
+	DormancyDays        int    `json:"dormancy_days"`
+	UnclaimedCustomerID string `json:"unclaimed_customer"`
+	UnclaimedAccountID  string `json:"unclaimed_account"`
+}
+
+// Escheatment is the audit record of a dormant balance moved to unclaimed property
+type Escheatment struct {
+	Entity
+	ID                  string            `json:"id"`
+	CustomerID          string            `json:"customer_id"`
+	AccountID           string            `json:"account_id"`
+	Amount              int64             `json:"amount"`
+	CurrencyCode        string            `json:"currency"`
+	LastActivity        int64             `json:"last_activity"` // unix timestamp
+	DormancyDays        int               `json:"dormancy_days"`
+	UnclaimedCustomerID string            `json:"unclaimed_customer"`
+	UnclaimedAccountID  string            `json:"unclaimed_account"`
+	Status              EscheatmentStatus `json:"status"`
+	Escheated           int64             `json:"escheated"` // unix timestamp
+	EscheatTxID         string            `json:"escheat_tx_id"`
+	Officer             string            `json:"officer"`
+	Reclaimed           int64             `json:"reclaimed,omitempty"` // unix timestamp
+	ReclaimTxID         string            `json:"reclaim_tx_id,omitempty"`
+}
+
+// EscheatmentList holds a list of escheatment records
+type EscheatmentList struct {
+	Escheatments []*Escheatment `json:"escheatments"`
+}
+
+// CreateEscheatmentPolicy Factory function creates a new EscheatmentPolicy struct and returns a pointer to it
+func CreateEscheatmentPolicy(policyBytes []byte) (*EscheatmentPolicy, error) {
+	policy := new(EscheatmentPolicy)
+	if err := json.Unmarshal(policyBytes, policy); err != nil {
+		return nil, err
+	}
+	policy.ObjectType = EscheatmentPolicyObjectType
+	if policy.CurrencyCode == "" {
+		return nil, errors.New("Missing required currency value")
+	}
+	if policy.DormancyDays <= 0 {
+		return nil, fmt.Errorf("Invalid dormancy period %d", policy.DormancyDays)
+	}
+	if policy.UnclaimedCustomerID == "" || policy.UnclaimedAccountID == "" {
+		return nil, errors.New("Missing required unclaimed_customer and / or unclaimed_account")
+	}
+	return policy, nil
+}
+
+// Dormant returns true if the account has had no activity for the policy's dormancy period
+func (p *EscheatmentPolicy) Dormant(a *Account, now time.Time) bool {
+	return now.Sub(time.Unix(a.DormantSince(), 0)) >= time.Duration(p.DormancyDays)*24*time.Hour
+}
+
+// IsUnclaimedAccount returns true if the account is the policy's unclaimed-property account
+func (p *EscheatmentPolicy) IsUnclaimedAccount(a *Account) bool {
+	return a.CustomerID == p.UnclaimedCustomerID && a.ID == p.UnclaimedAccountID
+}
+
+// CreateEscheatment a factory function for creating the escheatment record of an account
+func CreateEscheatment(a *Account, p *EscheatmentPolicy, txID string, officer string, now time.Time) *Escheatment {
+	return &Escheatment{
+		Entity:              Entity{EscheatmentObjectType},
+		ID:                  txID + "-" + a.ID,
+		CustomerID:          a.CustomerID,
+		AccountID:           a.ID,
+		Amount:              a.Balance,
+		CurrencyCode:        a.CurrencyCode,
+		LastActivity:        a.DormantSince(),
+		DormancyDays:        p.DormancyDays,
+		UnclaimedCustomerID: p.UnclaimedCustomerID,
+		UnclaimedAccountID:  p.UnclaimedAccountID,
+		Status:              Escheated,
+		Escheated:           now.Unix(),
+		EscheatTxID:         txID,
+		Officer:             officer,
+	}
+}
+
+// Transfer returns the transfer of the escheated balance, reversed when reclaiming
+func (e *Escheatment) Transfer(reclaim bool) *Transfer {
+	t := &Transfer{
+		FromCustomerID: e.CustomerID,
+		FromAccountID:  e.AccountID,
+		ToCustomerID:   e.UnclaimedCustomerID,
+		ToAccountID:    e.UnclaimedAccountID,
+		Amount:         e.Amount,
+		CurrencyCode:   e.CurrencyCode,
+		Description:    "Escheatment of dormant balance",
+		Params:         map[string]string{"escheatment": e.ID},
+	}
+	if reclaim {
+		t.FromCustomerID, t.ToCustomerID = t.ToCustomerID, t.FromCustomerID
+		t.FromAccountID, t.ToAccountID = t.ToAccountID, t.FromAccountID
+		t.Description = "Reclaim of escheated balance"
+	}
+	return t
+}
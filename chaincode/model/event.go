@@ -0,0 +1,131 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+// EventSchemaVersion is the version of the chaincode event envelope and
+// payloads. It only changes when a field is removed or changes meaning.
+const EventSchemaVersion = "1"
+
+const (
+	// EventAccountOpened is emitted when an account is opened
+	EventAccountOpened = "account.opened"
+	// EventAccountClosed is emitted when an account is closed
+	EventAccountClosed = "account.closed"
+	// EventAccountFrozen is emitted when an account is frozen
+	EventAccountFrozen = "account.frozen"
+	// EventAccountUnfrozen is emitted when a frozen or dormant account is made active
+	EventAccountUnfrozen = "account.unfrozen"
+	// EventAccountDormant is emitted when escheatment empties an account
+	EventAccountDormant = "account.dormant"
+	// EventAccountToppedUp is emitted when an account is topped up
+	EventAccountToppedUp = "account.topped_up"
+	// EventOverdraftChanged is emitted when the overdraft limit of an account is set
+	EventOverdraftChanged = "account.overdraft_changed"
+	// EventTransferCompleted is emitted when a transfer settles
+	EventTransferCompleted = "transfer.completed"
+	// EventTransferInitiated is emitted when a pending transfer holds the payer's funds
+	EventTransferInitiated = "transfer.initiated"
+	// EventTransferFailed is emitted when a transfer attempted on the customer's
+	// behalf, such as a standing order payment, fails without failing the
+	// invocation, and when a pending transfer is rejected
+	EventTransferFailed = "transfer.failed"
+	// EventLimitExceeded is emitted when a settled transfer breaches limits enforced by flagging
+	EventLimitExceeded = "aml.limit_exceeded"
+	// EventStateChanged is emitted by state-changing invocations that emit no other event
+	EventStateChanged = "state.changed"
+	// EventBatch is the chaincode event name used when an invocation emits more than one event
+	EventBatch = "events.batch"
+)
+
+// Event is a single event emitted by an invocation
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// EventEnvelope is the payload of every chaincode event. A transaction carries
+// at most one chaincode event, so all events emitted by an invocation are
+// delivered together; the chaincode event is named after the event type when
+// there is only one, and EventBatch otherwise.
+type EventEnvelope struct {
+	SchemaVersion string   `json:"schema_version"`
+	TxID          string   `json:"tx_id"`
+	Function      string   `json:"function"`
+	Timestamp     int64    `json:"timestamp"`             // unix timestamp
+	Traceparent   string   `json:"traceparent,omitempty"` // W3C trace context the client passed with the invocation
+	Events        []*Event `json:"events"`
+}
+
+// Name returns the chaincode event name of the envelope
+func (e *EventEnvelope) Name() string {
+	if len(e.Events) == 1 {
+		return e.Events[0].Type
+	}
+	return EventBatch
+}
+
+// AccountEvent is the payload of account events
+type AccountEvent struct {
+	CustomerID   string        `json:"customer_id"`
+	AccountID    string        `json:"account_id"`
+	Status       AccountStatus `json:"status"`
+	StatusReason string        `json:"status_reason,omitempty"`
+	Balance      int64         `json:"balance"`
+	Overdraft    int64         `json:"overdraft_limit"`
+	CurrencyCode string        `json:"currency"`
+	Amount       int64         `json:"amount,omitempty"` // amount in cents topped up
+}
+
+// NewAccountEvent creates the payload of an event about the account
+func NewAccountEvent(a *Account) *AccountEvent {
+	return &AccountEvent{
+		CustomerID:   a.CustomerID,
+		AccountID:    a.ID,
+		Status:       a.Status,
+		StatusReason: a.StatusReason,
+		Balance:      a.Balance,
+		Overdraft:    a.Overdraft,
+		CurrencyCode: a.CurrencyCode,
+	}
+}
+
+// TransferEvent is the payload of transfer events
+type TransferEvent struct {
+	FromCustomerID string            `json:"from_customer"`
+	FromAccountID  string            `json:"from_account"`
+	ToCustomerID   string            `json:"to_customer"`
+	ToAccountID    string            `json:"to_account"`
+	Amount         int64             `json:"amount"` // amount in cents
+	Fee            int64             `json:"fee"`
+	CurrencyCode   string            `json:"currency"`
+	Description    string            `json:"description"`
+	Params         map[string]string `json:"params,omitempty"`
+	Conversion     *FXConversion     `json:"conversion,omitempty"`
+	Overdraft      bool              `json:"overdraft,omitempty"`
+	Error          string            `json:"error,omitempty"` // reason a failed transfer did not settle
+	FailureCode    TxFailureCode     `json:"failure_code,omitempty"`
+}
+
+// NewTransferEvent creates the payload of an event about the transfer
+func NewTransferEvent(t *Transfer) *TransferEvent {
+	return &TransferEvent{
+		FromCustomerID: t.FromCustomerID,
+		FromAccountID:  t.FromAccountID,
+		ToCustomerID:   t.ToCustomerID,
+		ToAccountID:    t.ToAccountID,
+		Amount:         t.Amount,
+		Fee:            t.Fee,
+		CurrencyCode:   t.CurrencyCode,
+		Description:    t.Description,
+		Params:         t.Params,
+		Conversion:     t.Conversion,
+		Overdraft:      t.Overdraft,
+	}
+}
+
+// StateChangedEvent is the payload of a state.changed event
+type StateChangedEvent struct {
+	Keys int `json:"keys"` // number of ledger keys written or deleted
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ExchangeRateObjectType blockchain object type
+const ExchangeRateObjectType = "ExchangeRate"
+
+// ExchangeRateScale is the fixed-point scale of exchange rates, a rate of
+// 1.0 is stored as 1000000
+const ExchangeRateScale = 1000000
+
+// ExchangeRate is the rate at which one unit of the base currency converts
+// into the quote currency. Rates are directional, the reverse pair is set separately.
+type ExchangeRate struct {
+	Entity
+	Base      string `json:"base"`
+	Quote     string `json:"quote"`
+	Rate      int64  `json:"rate"` // quote units per base unit, scaled by ExchangeRateScale
+	Publisher string `json:"publisher"`
+	Updated   int64  `json:"updated"` // unix timestamp
+	// Source and AttestationID are set for rates posted by a rate oracle
+	Source        string `json:"source,omitempty"`
+	AttestationID string `json:"attestation_id,omitempty"`
+}
+
+// FXConversion records the conversion applied to the credited side of a cross-currency transfer
+type FXConversion struct {
+	OriginalAmount    int64  `json:"original_amount"`
+	OriginalCurrency  string `json:"original_currency"`
+	ConvertedAmount   int64  `json:"converted_amount"`
+	ConvertedCurrency string `json:"converted_currency"`
+	Rate              int64  `json:"rate"`                     // scaled by ExchangeRateScale
+	RateUpdated       int64  `json:"rate_updated"`             // unix timestamp of the rate
+	AttestationID     string `json:"attestation_id,omitempty"` // oracle attestation of the rate, if posted by an oracle
+}
+
+// CreateExchangeRate Factory function creates a new ExchangeRate struct and returns a pointer to it
+func CreateExchangeRate(rateBytes []byte, publisher string, tx *TxContext) (*ExchangeRate, error) {
+	rate := new(ExchangeRate)
+	if err := json.Unmarshal(rateBytes, rate); err != nil {
+		return nil, err
+	}
+	rate.ObjectType = ExchangeRateObjectType
+	if err := ValidateCurrency(rate.Base); err != nil {
+		return nil, err
+	}
+	if err := ValidateCurrency(rate.Quote); err != nil {
+		return nil, err
+	}
+	if rate.Base == rate.Quote {
+		return nil, errors.New("Base and quote currency must differ")
+	}
+	if rate.Rate <= 0 {
+		return nil, fmt.Errorf("Invalid exchange rate %d", rate.Rate)
+	}
+	rate.Publisher = publisher
+	rate.Updated = tx.Time.Unix()
+	return rate, nil
+}
+
+// Convert converts an amount in minor units of the base currency into minor
+// units of the quote currency, rounding half up
+func (r *ExchangeRate) Convert(amount int64) *FXConversion {
+	n := new(big.Int).Mul(big.NewInt(amount), big.NewInt(r.Rate))
+	n.Mul(n, pow10(CurrencyMinorUnits(r.Quote)))
+	d := new(big.Int).Mul(big.NewInt(ExchangeRateScale), pow10(CurrencyMinorUnits(r.Base)))
+	n.Mul(n, big.NewInt(2)).Add(n, d)
+	d.Mul(d, big.NewInt(2))
+	return &FXConversion{
+		OriginalAmount:    amount,
+		OriginalCurrency:  r.Base,
+		ConvertedAmount:   n.Quo(n, d).Int64(),
+		ConvertedCurrency: r.Quote,
+		Rate:              r.Rate,
+		RateUpdated:       r.Updated,
+		AttestationID:     r.AttestationID,
+	}
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// ExportFormatVersion is the version of the state export format written by ExportState
+const ExportFormatVersion = 2
+
+// LegacyExportFormatVersion is the version of exports whose keys predate the
+// shim composite key format. They can still be imported, after which their keys
+// are rewritten with MigrateKeys.
+const LegacyExportFormatVersion = 1
+
+// MaxExportPageSize caps the records returned in a single export page
+const MaxExportPageSize = 500
+
+// StateRecord is a single exported key / value pair
+type StateRecord struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"` // base64 encoded in JSON
+}
+
+// StateExportPage is one page of the records of an object type
+type StateExportPage struct {
+	Version      int            `json:"version"`
+	ObjectType   string         `json:"object_type"`
+	Bookmark     string         `json:"bookmark,omitempty"`      // last key of the previous page
+	NextBookmark string         `json:"next_bookmark,omitempty"` // empty on the last page
+	Records      []*StateRecord `json:"records"`
+	Hash         string         `json:"hash"` // hex SHA-256 over version, object type and records
+}
+
+// ComputeHash returns the hex SHA-256 digest of the page contents. Each field
+// is length-prefixed so that no two different pages share an encoding.
+func (p *StateExportPage) ComputeHash() string {
+	h := sha256.New()
+	write := func(b []byte) {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(len(b)))
+		h.Write(n[:])
+		h.Write(b)
+	}
+	write([]byte(fmt.Sprintf("%d", p.Version)))
+	write([]byte(p.ObjectType))
+	for _, r := range p.Records {
+		write([]byte(r.Key))
+		write(r.Value)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Seal sets the page hash
+func (p *StateExportPage) Seal() {
+	p.Hash = p.ComputeHash()
+}
+
+// Verify checks the page version, hash and that every key belongs to the page's object type
+func (p *StateExportPage) Verify(keyPrefix string) error {
+	if p.Version != ExportFormatVersion && p.Version != LegacyExportFormatVersion {
+		return fmt.Errorf("Unsupported export format version %d", p.Version)
+	}
+	if p.ComputeHash() != p.Hash {
+		return fmt.Errorf("Export page of %s does not match its hash", p.ObjectType)
+	}
+	for _, r := range p.Records {
+		if len(r.Key) < len(keyPrefix) || r.Key[:len(keyPrefix)] != keyPrefix {
+			return fmt.Errorf("Key %s does not belong to object type %s", r.Key, p.ObjectType)
+		}
+	}
+	return nil
+}
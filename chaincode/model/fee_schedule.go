@@ -0,0 +1,142 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FeeScheduleObjectType blockchain object type
+const FeeScheduleObjectType = "FeeSchedule"
+
+// AnyCountry matches every country in the corridor of a fee schedule
+const AnyCountry = "*"
+
+// FeeType stores allowed values for how a fee schedule charges a transfer.
+// Allowed values are "flat", "percentage", "tiered"
+type FeeType string
+
+const (
+	// FeeFlat charges the same fee for every transfer
+	FeeFlat FeeType = "flat"
+	// FeePercentage charges a rate of the transfer amount
+	FeePercentage FeeType = "percentage"
+	// FeeTiered charges the flat fee and rate of the tier the transfer amount falls in
+	FeeTiered FeeType = "tiered"
+)
+
+// FeeTier is the flat fee and rate charged on transfers up to an amount
+type FeeTier struct {
+	UpTo    int64 `json:"up_to"`    // amount in cents, 0 for the open-ended last tier
+	Flat    int64 `json:"flat"`     // amount in cents
+	RateBps int64 `json:"rate_bps"` // basis points of the transfer amount
+}
+
+// FeeSchedule holds the fees charged on transfers in a currency along a corridor
+// and the account the fees are collected into
+type FeeSchedule struct {
+	Entity
+	CurrencyCode         string     `json:"currency"`
+	FromCountry          string     `json:"from_country"` // payer account country, or "*"
+	ToCountry            string     `json:"to_country"`   // payee account country, or "*"
+	Type                 FeeType    `json:"type"`
+	Flat                 int64      `json:"flat,omitempty"`     // amount in cents
+	RateBps              int64      `json:"rate_bps,omitempty"` // basis points of the transfer amount
+	Tiers                []*FeeTier `json:"tiers,omitempty"`    // ordered by up_to
+	MinFee               int64      `json:"min_fee,omitempty"`
+	MaxFee               int64      `json:"max_fee,omitempty"` // 0 for no cap
+	CollectionCustomerID string     `json:"collection_customer"`
+	CollectionAccountID  string     `json:"collection_account"`
+	Updated              int64      `json:"updated"` // unix timestamp
+}
+
+// CreateFeeSchedule Factory function creates a new FeeSchedule struct and returns a pointer to it
+func CreateFeeSchedule(scheduleBytes []byte, tx *TxContext) (*FeeSchedule, error) {
+	schedule := new(FeeSchedule)
+	if err := json.Unmarshal(scheduleBytes, schedule); err != nil {
+		return nil, err
+	}
+	schedule.ObjectType = FeeScheduleObjectType
+	if err := ValidateCurrency(schedule.CurrencyCode); err != nil {
+		return nil, err
+	}
+	if schedule.FromCountry == "" {
+		schedule.FromCountry = AnyCountry
+	}
+	if schedule.ToCountry == "" {
+		schedule.ToCountry = AnyCountry
+	}
+	if schedule.CollectionCustomerID == "" || schedule.CollectionAccountID == "" {
+		return nil, errors.New("Missing required collection_customer and / or collection_account")
+	}
+	if schedule.Flat < 0 || schedule.RateBps < 0 || schedule.MinFee < 0 || schedule.MaxFee < 0 {
+		return nil, errors.New("Fees and rates must not be negative")
+	}
+	if schedule.MaxFee > 0 && schedule.MaxFee < schedule.MinFee {
+		return nil, fmt.Errorf("Maximum fee %d is below minimum fee %d", schedule.MaxFee, schedule.MinFee)
+	}
+	switch schedule.Type {
+	case FeeFlat:
+		schedule.RateBps = 0
+		schedule.Tiers = nil
+	case FeePercentage:
+		schedule.Flat = 0
+		schedule.Tiers = nil
+	case FeeTiered:
+		if err := validateFeeTiers(schedule.Tiers); err != nil {
+			return nil, err
+		}
+		schedule.Flat = 0
+		schedule.RateBps = 0
+	default:
+		return nil, fmt.Errorf("Invalid fee type %s", schedule.Type)
+	}
+	schedule.Updated = tx.Time.Unix()
+	return schedule, nil
+}
+
+func validateFeeTiers(tiers []*FeeTier) error {
+	if len(tiers) == 0 {
+		return errors.New("Missing required fee tiers")
+	}
+	var last int64
+	for i, tier := range tiers {
+		if tier.Flat < 0 || tier.RateBps < 0 {
+			return fmt.Errorf("Fee tier %d has a negative fee or rate", i)
+		}
+		if i == len(tiers)-1 {
+			if tier.UpTo != 0 && tier.UpTo <= last {
+				return fmt.Errorf("Fee tier %d is not above the previous tier", i)
+			}
+			break
+		}
+		if tier.UpTo <= last {
+			return fmt.Errorf("Fee tier %d is not above the previous tier", i)
+		}
+		last = tier.UpTo
+	}
+	return nil
+}
+
+// Fee returns the fee charged on a transfer of the given amount
+func (s *FeeSchedule) Fee(amount int64) int64 {
+	flat, rate := s.Flat, s.RateBps
+	if s.Type == FeeTiered {
+		tier := s.Tiers[len(s.Tiers)-1]
+		for _, t := range s.Tiers {
+			if t.UpTo == 0 || amount <= t.UpTo {
+				tier = t
+				break
+			}
+		}
+		flat, rate = tier.Flat, tier.RateBps
+	}
+	fee := flat + (amount*rate+5000)/10000
+	if fee < s.MinFee {
+		fee = s.MinFee
+	}
+	if s.MaxFee > 0 && fee > s.MaxFee {
+		fee = s.MaxFee
+	}
+	return fee
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GuaranteeObjectType blockchain object type
+const GuaranteeObjectType = "Guarantee"
+
+// GuaranteeDateFormat is the layout of guarantee expiry dates
+const GuaranteeDateFormat = "2006-01-02"
+
+// GuaranteeStatus stores allowed values for a guarantee's status.
+// Allowed values are "issued", "claimed", "expired"
+type GuaranteeStatus string
+
+const (
+	// GuaranteeIssued guarantee may be claimed until expiry
+	GuaranteeIssued GuaranteeStatus = "issued"
+	// GuaranteeClaimed guarantee amount has been paid out in full
+	GuaranteeClaimed GuaranteeStatus = "claimed"
+	// GuaranteeExpired guarantee lapsed without being claimed in full
+	GuaranteeExpired GuaranteeStatus = "expired"
+)
+
+// GuaranteeClaim records a payout under a guarantee
+type GuaranteeClaim struct {
+	Amount        int64  `json:"amount"`
+	FromApplicant int64  `json:"from_applicant"` // part paid from the applicant account
+	FromIssuer    int64  `json:"from_issuer"`    // part paid from the issuing bank account
+	TxID          string `json:"tx_id"`
+	Claimed       int64  `json:"claimed"` // unix timestamp
+}
+
+// Guarantee is a bank guarantee issued on behalf of an applicant in favour of a beneficiary
+type Guarantee struct {
+	Entity
+	ID                    string            `json:"id"`
+	IssuingBank           string            `json:"issuing_bank"`
+	IssuerCustomerID      string            `json:"issuer_customer"` // issuing bank account covering applicant shortfalls
+	IssuerAccountID       string            `json:"issuer_account"`
+	ApplicantCustomerID   string            `json:"applicant_customer"`
+	ApplicantAccountID    string            `json:"applicant_account"`
+	BeneficiaryCustomerID string            `json:"beneficiary_customer"`
+	BeneficiaryAccountID  string            `json:"beneficiary_account"`
+	Amount                int64             `json:"amount"` // guaranteed amount in cents
+	CurrencyCode          string            `json:"currency"`
+	Expiry                string            `json:"expiry"`     // YYYY-MM-DD, last day a claim is accepted
+	TermsHash             string            `json:"terms_hash"` // hex encoded SHA-256 of the guarantee terms
+	ClaimedAmount         int64             `json:"claimed_amount"`
+	Claims                []*GuaranteeClaim `json:"claims,omitempty"`
+	Status                GuaranteeStatus   `json:"status"`
+	Created               int64             `json:"created"` // unix timestamp
+}
+
+// CreateGuarantee Factory function creates a new Guarantee struct and returns a pointer to it
+func CreateGuarantee(guaranteeBytes []byte, tx *TxContext) (*Guarantee, error) {
+	g := new(Guarantee)
+	if err := json.Unmarshal(guaranteeBytes, g); err != nil {
+		return nil, err
+	}
+	g.ObjectType = GuaranteeObjectType
+	if g.IssuingBank == "" || g.IssuerCustomerID == "" || g.IssuerAccountID == "" {
+		return nil, errors.New("Missing required issuing_bank, issuer_customer and / or issuer_account")
+	}
+	if g.ApplicantCustomerID == "" || g.ApplicantAccountID == "" {
+		return nil, errors.New("Missing required applicant_customer and / or applicant_account")
+	}
+	if g.BeneficiaryCustomerID == "" || g.BeneficiaryAccountID == "" {
+		return nil, errors.New("Missing required beneficiary_customer and / or beneficiary_account")
+	}
+	if g.Amount <= 0 {
+		return nil, fmt.Errorf("Invalid guarantee amount %d", g.Amount)
+	}
+	if g.CurrencyCode == "" {
+		return nil, errors.New("Missing required currency value")
+	}
+	if _, err := time.Parse(GuaranteeDateFormat, g.Expiry); err != nil {
+		return nil, fmt.Errorf("Invalid expiry date %s", g.Expiry)
+	}
+	if hash, err := hex.DecodeString(g.TermsHash); err != nil || len(hash) != 32 {
+		return nil, errors.New("Invalid terms_hash, expected hex encoded SHA-256")
+	}
+	if g.ID == "" {
+		g.ID = tx.NewID(12)
+	}
+	g.ClaimedAmount = 0
+	g.Claims = nil
+	g.Status = GuaranteeIssued
+	g.Created = tx.Time.Unix()
+	return g, nil
+}
+
+// Expired returns true once the expiry date has passed at the given time
+func (g *Guarantee) Expired(now time.Time) bool {
+	return now.UTC().Format(GuaranteeDateFormat) > g.Expiry
+}
+
+// Remaining returns the amount that may still be claimed
+func (g *Guarantee) Remaining() int64 {
+	return g.Amount - g.ClaimedAmount
+}
+
+// Claim records a payout, marking the guarantee claimed once exhausted
+func (g *Guarantee) Claim(claim *GuaranteeClaim) {
+	g.ClaimedAmount += claim.Amount
+	g.Claims = append(g.Claims, claim)
+	if g.Remaining() == 0 {
+		g.Status = GuaranteeClaimed
+	}
+}
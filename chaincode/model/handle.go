@@ -0,0 +1,173 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// HandleObjectType blockchain object type
+	HandleObjectType = "Handle"
+	// P2PRequestObjectType blockchain object type
+	P2PRequestObjectType = "P2PRequest"
+)
+
+// MaxP2PAmount caps a single P2P payment in cents
+const MaxP2PAmount = 50000
+
+var handlePattern = regexp.MustCompile(`^[a-z0-9_.]{3,30}$`)
+
+// P2PRequestStatus stores allowed values for a P2P payment request's status.
+// Allowed values are "pending", "paid", "declined"
+type P2PRequestStatus string
+
+const (
+	// P2PRequestPending request awaiting the payer
+	P2PRequestPending P2PRequestStatus = "pending"
+	// P2PRequestPaid request settled by the payer
+	P2PRequestPaid P2PRequestStatus = "paid"
+	// P2PRequestDeclined request refused by the payer
+	P2PRequestDeclined P2PRequestStatus = "declined"
+)
+
+// Handle maps a human-friendly name to the account that receives P2P payments
+type Handle struct {
+	Entity
+	Handle      string `json:"handle"`
+	CustomerID  string `json:"customer_id"`
+	AccountID   string `json:"account_id"`
+	DisplayName string `json:"display_name"`
+	Owner       string `json:"owner"` // identity that registered the handle
+	Created     int64  `json:"created"`
+}
+
+// HandleView is the public part of a handle returned when resolving it
+type HandleView struct {
+	Handle      string `json:"handle"`
+	DisplayName string `json:"display_name"`
+}
+
+// P2PPayment is a payment between two handles. ConfirmName must match the
+// recipient's display name so the sender sees who they pay before settlement.
+type P2PPayment struct {
+	FromHandle   string `json:"from_handle"`
+	ToHandle     string `json:"to_handle"`
+	Amount       int64  `json:"amount"` // amount in cents
+	CurrencyCode string `json:"currency"`
+	ConfirmName  string `json:"confirm_name"`
+	Note         string `json:"note,omitempty"`
+}
+
+// P2PRequest asks the payer handle to pay the requester handle
+type P2PRequest struct {
+	Entity
+	ID              string           `json:"id"`
+	RequesterHandle string           `json:"requester_handle"`
+	PayerHandle     string           `json:"payer_handle"`
+	Amount          int64            `json:"amount"`
+	CurrencyCode    string           `json:"currency"`
+	Note            string           `json:"note,omitempty"`
+	Status          P2PRequestStatus `json:"status"`
+	Created         int64            `json:"created"`
+}
+
+// P2PRequestList holds a list of P2P payment requests
+type P2PRequestList struct {
+	Requests []*P2PRequest `json:"requests"`
+}
+
+// NormalizeHandle lowercases a handle and strips a leading "@"
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// CreateHandle Factory function creates a new Handle struct and returns a pointer to it
+func CreateHandle(handleBytes []byte, tx *TxContext) (*Handle, error) {
+	h := new(Handle)
+	if err := json.Unmarshal(handleBytes, h); err != nil {
+		return nil, err
+	}
+	h.ObjectType = HandleObjectType
+	h.Handle = NormalizeHandle(h.Handle)
+	if !handlePattern.MatchString(h.Handle) {
+		return nil, fmt.Errorf("Invalid handle %s", h.Handle)
+	}
+	if h.CustomerID == "" || h.AccountID == "" {
+		return nil, errors.New("Missing required customer_id and / or account_id")
+	}
+	if strings.TrimSpace(h.DisplayName) == "" {
+		return nil, errors.New("Missing required display_name")
+	}
+	h.Created = tx.Time.Unix()
+	return h, nil
+}
+
+// View returns the public part of the handle
+func (h *Handle) View() *HandleView {
+	return &HandleView{Handle: h.Handle, DisplayName: h.DisplayName}
+}
+
+// Validate - checks the P2P payment has both handles and a small positive amount
+func (p *P2PPayment) Validate() error {
+	p.FromHandle = NormalizeHandle(p.FromHandle)
+	p.ToHandle = NormalizeHandle(p.ToHandle)
+	if p.FromHandle == "" || p.ToHandle == "" {
+		return errors.New("Missing required from_handle and / or to_handle")
+	}
+	if p.FromHandle == p.ToHandle {
+		return errors.New("Cannot pay your own handle")
+	}
+	return validateP2PAmount(p.Amount, p.CurrencyCode)
+}
+
+// CreateP2PRequest Factory function creates a new P2PRequest struct and returns a pointer to it
+func CreateP2PRequest(requestBytes []byte, tx *TxContext) (*P2PRequest, error) {
+	r := new(P2PRequest)
+	if err := json.Unmarshal(requestBytes, r); err != nil {
+		return nil, err
+	}
+	r.ObjectType = P2PRequestObjectType
+	r.ID = tx.NewID(12)
+	r.RequesterHandle = NormalizeHandle(r.RequesterHandle)
+	r.PayerHandle = NormalizeHandle(r.PayerHandle)
+	if r.RequesterHandle == "" || r.PayerHandle == "" {
+		return nil, errors.New("Missing required requester_handle and / or payer_handle")
+	}
+	if r.RequesterHandle == r.PayerHandle {
+		return nil, errors.New("Cannot request payment from your own handle")
+	}
+	if err := validateP2PAmount(r.Amount, r.CurrencyCode); err != nil {
+		return nil, err
+	}
+	r.Status = P2PRequestPending
+	r.Created = tx.Time.Unix()
+	return r, nil
+}
+
+// Payment returns the P2P payment settling the request
+func (r *P2PRequest) Payment(confirmName string) *P2PPayment {
+	return &P2PPayment{
+		FromHandle:   r.PayerHandle,
+		ToHandle:     r.RequesterHandle,
+		Amount:       r.Amount,
+		CurrencyCode: r.CurrencyCode,
+		ConfirmName:  confirmName,
+		Note:         r.Note,
+	}
+}
+
+func validateP2PAmount(amount int64, currency string) error {
+	if amount <= 0 {
+		return fmt.Errorf("Invalid P2P amount %d", amount)
+	}
+	if amount > MaxP2PAmount {
+		return fmt.Errorf("P2P amount %d exceeds limit of %d", amount, MaxP2PAmount)
+	}
+	if currency == "" {
+		return errors.New("Missing required currency value")
+	}
+	return nil
+}
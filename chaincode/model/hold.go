@@ -0,0 +1,92 @@
+package model
+
+import (
+	"errors"
+)
+
+// HoldObjectType blockchain object type
+const HoldObjectType = "Hold"
+
+// HoldStatus stores allowed values for a hold's status.
+// Allowed values are "active", "released"
+type HoldStatus string
+
+const (
+	// HoldActive hold reserves its amount of the account balance
+	HoldActive HoldStatus = "active"
+	// HoldReleased hold no longer reserves any amount
+	HoldReleased HoldStatus = "released"
+)
+
+// Hold reserves part of an account balance, e.g. for a card authorization or
+// a pending settlement, without moving money
+type Hold struct {
+	Entity
+	ID         string     `json:"id"`
+	CustomerID string     `json:"customer_id"`
+	AccountID  string     `json:"account_id"`
+	Amount     int64      `json:"amount"` // amount in cents
+	Reference  string     `json:"reference"`
+	Status     HoldStatus `json:"status"`
+	PlacedBy   string     `json:"placed_by"`
+	Placed     int64      `json:"placed"` // unix timestamp
+	ReleasedBy string     `json:"released_by,omitempty"`
+	Released   int64      `json:"released,omitempty"` // unix timestamp
+}
+
+// HoldList holds a list of holds
+type HoldList struct {
+	Holds []*Hold `json:"holds"`
+}
+
+// AvailableBalance breaks down the amount that may be transferred out of an account
+type AvailableBalance struct {
+	CustomerID   string `json:"customer_id"`
+	AccountID    string `json:"account_id"`
+	CurrencyCode string `json:"currency"`
+	Balance      int64  `json:"balance"`
+	Overdraft    int64  `json:"overdraft_limit"`
+	Held         int64  `json:"held"`
+	Available    int64  `json:"available"` // balance plus overdraft limit less held
+}
+
+// CreateHold a factory function for an active hold on an account
+func CreateHold(id string, a *Account, amount int64, reference string, placedBy string, tx *TxContext) (*Hold, error) {
+	if err := ValidateAmount(amount); err != nil {
+		return nil, err
+	}
+	if reference == "" {
+		return nil, errors.New("Missing required hold reference")
+	}
+	return &Hold{
+		Entity:     Entity{HoldObjectType},
+		ID:         id,
+		CustomerID: a.CustomerID,
+		AccountID:  a.ID,
+		Amount:     amount,
+		Reference:  reference,
+		Status:     HoldActive,
+		PlacedBy:   placedBy,
+		Placed:     tx.Time.Unix(),
+	}, nil
+}
+
+// Release marks the hold released
+func (h *Hold) Release(by string, tx *TxContext) {
+	h.Status = HoldReleased
+	h.ReleasedBy = by
+	h.Released = tx.Time.Unix()
+}
+
+// NewAvailableBalance breaks down the available balance of the account
+func NewAvailableBalance(a *Account) *AvailableBalance {
+	return &AvailableBalance{
+		CustomerID:   a.CustomerID,
+		AccountID:    a.ID,
+		CurrencyCode: a.CurrencyCode,
+		Balance:      a.Balance,
+		Overdraft:    a.Overdraft,
+		Held:         a.Held,
+		Available:    a.Available(),
+	}
+}
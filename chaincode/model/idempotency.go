@@ -0,0 +1,46 @@
+package model
+
+import (
+	"fmt"
+)
+
+// IdempotencyRecordObjectType blockchain object type
+const IdempotencyRecordObjectType = "IdempotencyRecord"
+
+// MaxIdempotencyKeyLength caps the length of client supplied idempotency keys
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyRecord stores the response of the first successful invocation of
+// a handler with a client supplied idempotency key, returned again on replay
+type IdempotencyRecord struct {
+	Entity
+	Function    string `json:"function"`
+	Caller      string `json:"caller"` // identity the key belongs to
+	Key         string `json:"key"`
+	RequestHash string `json:"request_hash"` // hex SHA-256 of the handler arguments
+	Response    []byte `json:"response"`     // base64 encoded in JSON
+	TxID        string `json:"tx_id"`        // ledger transaction that applied the request
+	Created     int64  `json:"created"`      // unix timestamp
+}
+
+// CreateIdempotencyRecord a factory function for creating new IdempotencyRecord entities
+func CreateIdempotencyRecord(function string, caller string, key string, requestHash string, response []byte, tx *TxContext) *IdempotencyRecord {
+	return &IdempotencyRecord{
+		Entity:      Entity{IdempotencyRecordObjectType},
+		Function:    function,
+		Caller:      caller,
+		Key:         key,
+		RequestHash: requestHash,
+		Response:    response,
+		TxID:        tx.ID,
+		Created:     tx.Time.Unix(),
+	}
+}
+
+// ValidateIdempotencyKey checks the length of a client supplied idempotency key
+func ValidateIdempotencyKey(key string) error {
+	if len(key) > MaxIdempotencyKeyLength {
+		return fmt.Errorf("Idempotency key longer than %d characters", MaxIdempotencyKeyLength)
+	}
+	return nil
+}
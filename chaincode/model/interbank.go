@@ -0,0 +1,52 @@
+package model
+
+// InterbankPositionObjectType blockchain object type
+const InterbankPositionObjectType = "InterbankPosition"
+
+// InterbankPosition tracks the customer transfers a bank has sent to and
+// received from a counterparty bank in a currency
+type InterbankPosition struct {
+	Entity
+	BankID         string `json:"bank_id"`
+	CounterpartyID string `json:"counterparty_id"`
+	CurrencyCode   string `json:"currency"`
+	Sent           int64  `json:"sent"`      // gross amount paid to the counterparty in cents
+	Received       int64  `json:"received"`  // gross amount received from the counterparty in cents
+	Net            int64  `json:"net"`       // received less sent, positive when the counterparty owes the bank
+	Transfers      int64  `json:"transfers"` // number of transfers in either direction
+	Updated        int64  `json:"updated"`   // unix timestamp
+}
+
+// InterbankPositionReport holds a bank's positions by counterparty and currency
+type InterbankPositionReport struct {
+	BankID    string               `json:"bank_id"`
+	Positions []*InterbankPosition `json:"positions"`
+}
+
+// CreateInterbankPosition Factory function creates an empty position of a bank to a counterparty in a currency
+func CreateInterbankPosition(bankID string, counterpartyID string, currency string) *InterbankPosition {
+	return &InterbankPosition{
+		Entity:         Entity{InterbankPositionObjectType},
+		BankID:         bankID,
+		CounterpartyID: counterpartyID,
+		CurrencyCode:   currency,
+	}
+}
+
+// Send records a transfer paid to the counterparty
+func (p *InterbankPosition) Send(amount int64, tx *TxContext) {
+	p.Sent += amount
+	p.record(tx)
+}
+
+// Receive records a transfer received from the counterparty
+func (p *InterbankPosition) Receive(amount int64, tx *TxContext) {
+	p.Received += amount
+	p.record(tx)
+}
+
+func (p *InterbankPosition) record(tx *TxContext) {
+	p.Net = p.Received - p.Sent
+	p.Transfers++
+	p.Updated = tx.Time.Unix()
+}
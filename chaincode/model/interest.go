@@ -0,0 +1,174 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// InterestConfigObjectType blockchain object type
+	InterestConfigObjectType = "InterestConfig"
+	// InterestAccrualObjectType blockchain object type
+	InterestAccrualObjectType = "InterestAccrual"
+)
+
+// CompoundingPeriod stores allowed values for how often interest is credited.
+// Allowed values are "daily", "monthly", "annually"
+type CompoundingPeriod string
+
+const (
+	// CompoundDaily interest is credited every day, periods are YYYY-MM-DD
+	CompoundDaily CompoundingPeriod = "daily"
+	// CompoundMonthly interest is credited every month, periods are YYYY-MM
+	CompoundMonthly CompoundingPeriod = "monthly"
+	// CompoundAnnually interest is credited every year, periods are YYYY
+	CompoundAnnually CompoundingPeriod = "annually"
+)
+
+// InterestConfig sets the interest paid on accounts of a type in a currency.
+// Interest is paid out of the funding account, e.g. the bank's interest expense account.
+type InterestConfig struct {
+	Entity
+	AccountType       string            `json:"account_type"`
+	CurrencyCode      string            `json:"currency"`
+	Rate              int64             `json:"rate"` // annual rate in basis points
+	Compounding       CompoundingPeriod `json:"compounding"`
+	MinBalance        int64             `json:"min_balance,omitempty"` // balance in cents below which no interest is paid
+	FundingCustomerID string            `json:"funding_customer"`
+	FundingAccountID  string            `json:"funding_account"`
+	SetBy             string            `json:"set_by"`
+	Updated           int64             `json:"updated"` // unix timestamp
+}
+
+// InterestAccrual tracks the interest credited to an account
+type InterestAccrual struct {
+	Entity
+	CustomerID string `json:"customer_id"`
+	AccountID  string `json:"account_id"`
+	LastPeriod string `json:"last_period"` // last period interest was accrued for
+	Total      int64  `json:"total"`       // interest credited in cents
+	Updated    int64  `json:"updated"`     // unix timestamp
+}
+
+// InterestResult holds the interest accrued to one account, or why it failed
+type InterestResult struct {
+	CustomerID string `json:"customer_id"`
+	AccountID  string `json:"account_id"`
+	Amount     int64  `json:"amount"`
+	Error      string `json:"error,omitempty"`
+}
+
+// InterestReport summarises an accrual run
+type InterestReport struct {
+	AccountType  string            `json:"account_type"`
+	CurrencyCode string            `json:"currency"`
+	Period       string            `json:"period"`
+	Total        int64             `json:"total"`
+	Accruals     []*InterestResult `json:"accruals"`
+}
+
+// CreateInterestConfig Factory function creates a new InterestConfig struct and returns a pointer to it
+func CreateInterestConfig(configBytes []byte, setBy string, tx *TxContext) (*InterestConfig, error) {
+	config := new(InterestConfig)
+	if err := json.Unmarshal(configBytes, config); err != nil {
+		return nil, err
+	}
+	config.ObjectType = InterestConfigObjectType
+	if config.AccountType == "" {
+		return nil, errors.New("Missing required account_type value")
+	}
+	if err := ValidateCurrency(config.CurrencyCode); err != nil {
+		return nil, err
+	}
+	if config.Rate <= 0 || config.Rate > 10000 {
+		return nil, fmt.Errorf("Invalid interest rate %d, expected 1 to 10000 basis points", config.Rate)
+	}
+	if config.periodLayout() == "" {
+		return nil, fmt.Errorf("Invalid compounding period %s", config.Compounding)
+	}
+	if config.MinBalance < 0 {
+		return nil, fmt.Errorf("Invalid min balance %d", config.MinBalance)
+	}
+	if config.FundingCustomerID == "" || config.FundingAccountID == "" {
+		return nil, errors.New("Missing required funding_customer and / or funding_account")
+	}
+	config.SetBy = setBy
+	config.Updated = tx.Time.Unix()
+	return config, nil
+}
+
+func (c *InterestConfig) periodLayout() string {
+	switch c.Compounding {
+	case CompoundDaily:
+		return "2006-01-02"
+	case CompoundMonthly:
+		return "2006-01"
+	case CompoundAnnually:
+		return "2006"
+	}
+	return ""
+}
+
+// ValidatePeriod checks the period is written in the layout of the
+// compounding period and has ended by now
+func (c *InterestConfig) ValidatePeriod(period string, now time.Time) error {
+	start, err := time.Parse(c.periodLayout(), period)
+	if err != nil {
+		return fmt.Errorf("Invalid %s period %s, expected %s", c.Compounding, period, c.periodLayout())
+	}
+	end := start.AddDate(0, 0, 1)
+	switch c.Compounding {
+	case CompoundMonthly:
+		end = start.AddDate(0, 1, 0)
+	case CompoundAnnually:
+		end = start.AddDate(1, 0, 0)
+	}
+	if end.After(now) {
+		return fmt.Errorf("Period %s has not ended yet", period)
+	}
+	return nil
+}
+
+// Eligible returns true if the account earns interest under the config
+func (c *InterestConfig) Eligible(a *Account) bool {
+	return a.Type == c.AccountType && a.CurrencyCode == c.CurrencyCode && a.Status == AccountActive &&
+		a.Balance > 0 && a.Balance >= c.MinBalance &&
+		!(a.CustomerID == c.FundingCustomerID && a.ID == c.FundingAccountID)
+}
+
+// Interest returns the interest on a balance for one compounding period,
+// rounded half up to the minor unit
+func (c *InterestConfig) Interest(balance int64) int64 {
+	periods := int64(365)
+	switch c.Compounding {
+	case CompoundMonthly:
+		periods = 12
+	case CompoundAnnually:
+		periods = 1
+	}
+	n := new(big.Int).Mul(big.NewInt(balance), big.NewInt(c.Rate))
+	d := big.NewInt(10000 * periods)
+	n.Mul(n, big.NewInt(2)).Add(n, d)
+	d.Mul(d, big.NewInt(2))
+	return n.Quo(n, d).Int64()
+}
+
+// CreateInterestAccrual Factory function creates an empty accrual of an account
+func CreateInterestAccrual(customerID string, accountID string) *InterestAccrual {
+	return &InterestAccrual{Entity: Entity{InterestAccrualObjectType}, CustomerID: customerID, AccountID: accountID}
+}
+
+// Accrue records interest credited for a period. Periods accrue in order and
+// at most once.
+func (a *InterestAccrual) Accrue(period string, amount int64, tx *TxContext) error {
+	if a.LastPeriod != "" && period <= a.LastPeriod {
+		return fmt.Errorf("Interest of account %s already accrued for period %s", a.AccountID, a.LastPeriod)
+	}
+	a.LastPeriod = period
+	a.Total += amount
+	a.Updated = tx.Time.Unix()
+	return nil
+}
@@ -0,0 +1,15 @@
+package model
+
+// MaxKeyMigrationPageSize caps the legacy keys examined by a single MigrateKeys
+// or MigrateTransactionKeys invocation
+const MaxKeyMigrationPageSize = 200
+
+// KeyMigrationReport lists the outcome of a MigrateKeys, MigrateKey or
+// MigrateTransactionKeys invocation
+type KeyMigrationReport struct {
+	ObjectType   string   `json:"object_type"`
+	Tenant       string   `json:"tenant,omitempty"`
+	Migrated     int      `json:"migrated"`
+	Unresolved   []string `json:"unresolved"`              // legacy keys that could not be migrated
+	NextBookmark string   `json:"next_bookmark,omitempty"` // empty once every legacy key has been examined
+}
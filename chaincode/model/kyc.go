@@ -0,0 +1,125 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// KYCProfileObjectType blockchain object type
+const KYCProfileObjectType = "KYCProfile"
+
+// KYCStatus stores allowed values for a KYC profile's verification status.
+// Allowed values are "pending", "approved", "rejected"
+type KYCStatus string
+
+const (
+	// KYCPending profile submitted and awaiting review
+	KYCPending KYCStatus = "pending"
+	// KYCApproved profile verified until its expiry date
+	KYCApproved KYCStatus = "approved"
+	// KYCRejected profile failed verification
+	KYCRejected KYCStatus = "rejected"
+)
+
+// KYCRiskRating stores allowed values for a customer's risk rating.
+// Allowed values are "low", "medium", "high"
+type KYCRiskRating string
+
+const (
+	// KYCRiskLow low risk customer
+	KYCRiskLow KYCRiskRating = "low"
+	// KYCRiskMedium medium risk customer
+	KYCRiskMedium KYCRiskRating = "medium"
+	// KYCRiskHigh high risk customer, e.g. a politically exposed person
+	KYCRiskHigh KYCRiskRating = "high"
+)
+
+var documentsHashPattern = regexp.MustCompile("^[0-9a-f]{64}$")
+
+// KYCProfile records the verification of a customer's identity. Only a hash
+// of the identity documents is stored on the ledger.
+type KYCProfile struct {
+	Entity
+	CustomerID    string        `json:"customer_id"`
+	DocumentsHash string        `json:"documents_hash"` // hex SHA-256 of the identity documents
+	RiskRating    KYCRiskRating `json:"risk_rating,omitempty"`
+	Status        KYCStatus     `json:"status"`
+	Reason        string        `json:"reason,omitempty"`
+	Expiry        string        `json:"expiry,omitempty"` // YYYY-MM-DD, last day the approval is valid
+	SubmittedBy   string        `json:"submitted_by"`
+	Submitted     int64         `json:"submitted"` // unix timestamp
+	ReviewedBy    string        `json:"reviewed_by,omitempty"`
+	Reviewed      int64         `json:"reviewed,omitempty"` // unix timestamp
+}
+
+// KYCStatusReport reports a customer's KYC status as enforced at the given time
+type KYCStatusReport struct {
+	*KYCProfile
+	Expired bool `json:"expired"`
+	Valid   bool `json:"valid"` // approved and not expired
+}
+
+// CreateKYCProfile Factory function creates a new pending KYCProfile struct and returns a pointer to it
+func CreateKYCProfile(profileBytes []byte, submittedBy string, tx *TxContext) (*KYCProfile, error) {
+	profile := new(KYCProfile)
+	if err := json.Unmarshal(profileBytes, profile); err != nil {
+		return nil, err
+	}
+	if profile.CustomerID == "" {
+		return nil, errors.New("Missing required customer_id value")
+	}
+	if !documentsHashPattern.MatchString(profile.DocumentsHash) {
+		return nil, errors.New("Invalid documents_hash, expected a hex SHA-256 digest")
+	}
+	return &KYCProfile{
+		Entity:        Entity{KYCProfileObjectType},
+		CustomerID:    profile.CustomerID,
+		DocumentsHash: profile.DocumentsHash,
+		Status:        KYCPending,
+		SubmittedBy:   submittedBy,
+		Submitted:     tx.Time.Unix(),
+	}, nil
+}
+
+// Approve marks the profile verified with the risk rating until the expiry date
+func (p *KYCProfile) Approve(rating KYCRiskRating, expiry string, by string, tx *TxContext) error {
+	if rating != KYCRiskLow && rating != KYCRiskMedium && rating != KYCRiskHigh {
+		return fmt.Errorf("Invalid risk rating %s", rating)
+	}
+	if _, err := time.Parse(PayDateFormat, expiry); err != nil {
+		return fmt.Errorf("Invalid expiry date %s", expiry)
+	}
+	p.Status = KYCApproved
+	p.RiskRating = rating
+	p.Expiry = expiry
+	p.Reason = ""
+	p.ReviewedBy = by
+	p.Reviewed = tx.Time.Unix()
+	return nil
+}
+
+// Reject marks the profile as failing verification
+func (p *KYCProfile) Reject(reason string, by string, tx *TxContext) {
+	p.Status = KYCRejected
+	p.Reason = reason
+	p.ReviewedBy = by
+	p.Reviewed = tx.Time.Unix()
+}
+
+// PrivateFields returns the KYC fields kept in the bank's private data collection
+func (p *KYCProfile) PrivateFields() []string {
+	return []string{"documents_hash", "risk_rating", "reason"}
+}
+
+// IsExpired reports whether the approval has lapsed at the given time
+func (p *KYCProfile) IsExpired(now time.Time) bool {
+	return p.Expiry != "" && now.UTC().Format(PayDateFormat) > p.Expiry
+}
+
+// IsValid reports whether the profile is approved and not expired at the given time
+func (p *KYCProfile) IsValid(now time.Time) bool {
+	return p.Status == KYCApproved && !p.IsExpired(now)
+}
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// LimitsObjectType blockchain object type
+	LimitsObjectType = "Limits"
+	// LimitUsageObjectType blockchain object type
+	LimitUsageObjectType = "LimitUsage"
+)
+
+const (
+	// LimitDayWindow is the rolling window in seconds of the daily limits
+	LimitDayWindow = 24 * 60 * 60
+	// LimitMonthWindow is the rolling window in seconds of the monthly limit
+	LimitMonthWindow = 30 * LimitDayWindow
+)
+
+// LimitEnforcement stores allowed values for how transaction limits are enforced.
+// Allowed values are "reject", "flag"
+type LimitEnforcement string
+
+const (
+	// LimitReject transfers breaching a limit fail with the limit_exceeded code
+	LimitReject LimitEnforcement = "reject"
+	// LimitFlag transfers breaching a limit settle and emit an aml.limit_exceeded event
+	LimitFlag LimitEnforcement = "flag"
+)
+
+// Limits caps the transfers a customer may make in a currency. A zero limit
+// is not enforced.
+type Limits struct {
+	Entity
+	CustomerID   string           `json:"customer_id"`
+	CurrencyCode string           `json:"currency"`
+	SingleMax    int64            `json:"single_max"`  // largest single transfer in cents
+	DailyMax     int64            `json:"daily_max"`   // total in cents transferred in the last 24 hours
+	MonthlyMax   int64            `json:"monthly_max"` // total in cents transferred in the last 30 days
+	DailyCount   int              `json:"daily_count"` // number of transfers in the last 24 hours
+	Enforcement  LimitEnforcement `json:"enforcement"`
+	SetBy        string           `json:"set_by"`
+	Updated      int64            `json:"updated"`
+}
+
+// LimitUsageEntry is a transfer counted against a customer's limits
+type LimitUsageEntry struct {
+	Time   int64 `json:"time"`   // unix timestamp
+	Amount int64 `json:"amount"` // amount in cents
+}
+
+// LimitUsage holds the transfers of a customer in a currency within the
+// longest limit window, from which the rolling counters are computed
+type LimitUsage struct {
+	Entity
+	CustomerID   string             `json:"customer_id"`
+	CurrencyCode string             `json:"currency"`
+	Entries      []*LimitUsageEntry `json:"entries"`
+}
+
+// LimitCounters are the rolling counters of a customer's transfers
+type LimitCounters struct {
+	DailyAmount   int64 `json:"daily_amount"`
+	DailyCount    int   `json:"daily_count"`
+	MonthlyAmount int64 `json:"monthly_amount"`
+}
+
+// LimitStatus reports limits with the current rolling counters
+type LimitStatus struct {
+	*Limits
+	Counters *LimitCounters `json:"counters"`
+}
+
+// LimitBreach is the payload of an aml.limit_exceeded event
+type LimitBreach struct {
+	CustomerID   string         `json:"customer_id"`
+	AccountID    string         `json:"account_id"`
+	CurrencyCode string         `json:"currency"`
+	Amount       int64          `json:"amount"` // amount in cents of the transfer
+	Breached     []string       `json:"breached"`
+	Counters     *LimitCounters `json:"counters"` // including the transfer
+}
+
+// CreateLimits Factory function creates a new Limits struct and returns a pointer to it
+func CreateLimits(limitsBytes []byte, setBy string, tx *TxContext) (*Limits, error) {
+	limits := new(Limits)
+	if err := json.Unmarshal(limitsBytes, limits); err != nil {
+		return nil, err
+	}
+	limits.ObjectType = LimitsObjectType
+	if limits.CustomerID == "" {
+		return nil, errors.New("Missing required customer_id value")
+	}
+	if err := ValidateCurrency(limits.CurrencyCode); err != nil {
+		return nil, err
+	}
+	if limits.SingleMax < 0 || limits.DailyMax < 0 || limits.MonthlyMax < 0 || limits.DailyCount < 0 {
+		return nil, errors.New("Invalid negative limit")
+	}
+	if limits.Enforcement == "" {
+		limits.Enforcement = LimitReject
+	}
+	if limits.Enforcement != LimitReject && limits.Enforcement != LimitFlag {
+		return nil, fmt.Errorf("Invalid limit enforcement %s", limits.Enforcement)
+	}
+	limits.SetBy = setBy
+	limits.Updated = tx.Time.Unix()
+	return limits, nil
+}
+
+// CreateLimitUsage Factory function creates an empty LimitUsage
+func CreateLimitUsage(customerID string, currencyCode string) *LimitUsage {
+	return &LimitUsage{Entity: Entity{LimitUsageObjectType}, CustomerID: customerID, CurrencyCode: currencyCode, Entries: []*LimitUsageEntry{}}
+}
+
+// Add counts a transfer at the given time, dropping entries older than the
+// monthly window
+func (u *LimitUsage) Add(now int64, amount int64) {
+	entries := []*LimitUsageEntry{}
+	for _, e := range u.Entries {
+		if now-e.Time < LimitMonthWindow {
+			entries = append(entries, e)
+		}
+	}
+	u.Entries = append(entries, &LimitUsageEntry{Time: now, Amount: amount})
+}
+
+// Counters computes the rolling counters at the given time
+func (u *LimitUsage) Counters(now int64) *LimitCounters {
+	c := new(LimitCounters)
+	for _, e := range u.Entries {
+		age := now - e.Time
+		if age >= LimitMonthWindow {
+			continue
+		}
+		c.MonthlyAmount += e.Amount
+		if age < LimitDayWindow {
+			c.DailyAmount += e.Amount
+			c.DailyCount++
+		}
+	}
+	return c
+}
+
+// Breaches lists the limits breached by a transfer of the amount, given the
+// rolling counters including it
+func (l *Limits) Breaches(amount int64, c *LimitCounters) []string {
+	breached := []string{}
+	if l.SingleMax > 0 && amount > l.SingleMax {
+		breached = append(breached, "single_max")
+	}
+	if l.DailyMax > 0 && c.DailyAmount > l.DailyMax {
+		breached = append(breached, "daily_max")
+	}
+	if l.MonthlyMax > 0 && c.MonthlyAmount > l.MonthlyMax {
+		breached = append(breached, "monthly_max")
+	}
+	if l.DailyCount > 0 && c.DailyCount > l.DailyCount {
+		breached = append(breached, "daily_count")
+	}
+	return breached
+}
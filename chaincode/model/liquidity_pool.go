@@ -0,0 +1,224 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// LiquidityPoolObjectType blockchain object type
+const LiquidityPoolObjectType = "LiquidityPool"
+
+// secondsPerYear is used to pro-rate annual interest rates
+const secondsPerYear = 365 * 24 * 60 * 60
+
+// PoolMember holds a bank's position in a liquidity pool
+type PoolMember struct {
+	BankID           string `json:"bank_id"`
+	NostroCustomerID string `json:"nostro_customer"` // nostro account used for contributions and draws
+	NostroAccountID  string `json:"nostro_account"`
+	Contributed      int64  `json:"contributed"` // amount in cents
+	Drawn            int64  `json:"drawn"`       // outstanding principal in cents
+	DrawLimit        int64  `json:"draw_limit"`  // maximum outstanding principal in cents
+	AccruedInterest  int64  `json:"accrued_interest"`
+	InterestEarned   int64  `json:"interest_earned"`
+	LastAccrual      int64  `json:"last_accrual"` // unix timestamp
+}
+
+// LiquidityPool is a shared pool of funds banks contribute to and draw from
+// when their nostro balance is insufficient for a settlement
+type LiquidityPool struct {
+	Entity
+	ID            string                 `json:"id"`
+	CurrencyCode  string                 `json:"currency"`
+	Benchmark     string                 `json:"benchmark,omitempty"` // reference rate drawn funds are priced against
+	BenchmarkRate int64                  `json:"benchmark_rate"`      // latest published benchmark rate in basis points
+	InterestRate  int64                  `json:"interest_rate"`       // annual spread over the benchmark (or fixed rate) in basis points
+	Available     int64                  `json:"available"`           // undrawn pool funds in cents
+	Members       map[string]*PoolMember `json:"members"`
+	Created       int64                  `json:"created"` // unix timestamp
+}
+
+// PoolPosition is a read-only view of a pool with each member's share
+type PoolPosition struct {
+	PoolID           string                `json:"pool_id"`
+	CurrencyCode     string                `json:"currency"`
+	Available        int64                 `json:"available"`
+	TotalContributed int64                 `json:"total_contributed"`
+	TotalDrawn       int64                 `json:"total_drawn"`
+	Members          []*PoolMemberPosition `json:"members"`
+}
+
+// PoolMemberPosition holds a member position together with its contribution share
+type PoolMemberPosition struct {
+	*PoolMember
+	Share float64 `json:"share"` // fraction of total contributions
+}
+
+// CreateLiquidityPool Factory function creates a new LiquidityPool struct and returns a pointer to it
+func CreateLiquidityPool(poolBytes []byte, tx *TxContext) (*LiquidityPool, error) {
+	pool := new(LiquidityPool)
+	if err := json.Unmarshal(poolBytes, pool); err != nil {
+		return nil, err
+	}
+	pool.ObjectType = LiquidityPoolObjectType
+	if pool.ID == "" {
+		return nil, errors.New("Missing required id")
+	}
+	if pool.CurrencyCode == "" {
+		return nil, errors.New("Missing required currency value")
+	}
+	if pool.InterestRate < 0 {
+		return nil, fmt.Errorf("Invalid interest rate %d", pool.InterestRate)
+	}
+	pool.Available = 0
+	pool.Members = make(map[string]*PoolMember)
+	if pool.Created == 0 {
+		pool.Created = tx.Time.Unix()
+	}
+	return pool, nil
+}
+
+// Join registers a bank as a pool member with the given nostro account and drawing limit
+func (p *LiquidityPool) Join(bankID string, customerID string, accountID string, drawLimit int64, tx *TxContext) (*PoolMember, error) {
+	if bankID == "" || customerID == "" || accountID == "" {
+		return nil, errors.New("Missing required bank ID and / or nostro account")
+	}
+	if drawLimit < 0 {
+		return nil, fmt.Errorf("Invalid drawing limit %d", drawLimit)
+	}
+	member, ok := p.Members[bankID]
+	if !ok {
+		member = &PoolMember{BankID: bankID, LastAccrual: tx.Time.Unix()}
+		p.Members[bankID] = member
+	}
+	member.NostroCustomerID = customerID
+	member.NostroAccountID = accountID
+	member.DrawLimit = drawLimit
+	return member, nil
+}
+
+// MemberByAccount returns the pool member using the given nostro account, if any
+func (p *LiquidityPool) MemberByAccount(customerID string, accountID string) *PoolMember {
+	for _, m := range p.Members {
+		if m.NostroCustomerID == customerID && m.NostroAccountID == accountID {
+			return m
+		}
+	}
+	return nil
+}
+
+// Contribute adds member funds to the pool
+func (p *LiquidityPool) Contribute(m *PoolMember, amount int64) error {
+	if amount <= 0 {
+		return fmt.Errorf("Invalid contribution amount %d", amount)
+	}
+	m.Contributed += amount
+	p.Available += amount
+	return nil
+}
+
+// Withdraw returns contributed funds to a member, limited to undrawn pool funds
+func (p *LiquidityPool) Withdraw(m *PoolMember, amount int64) error {
+	if amount <= 0 {
+		return fmt.Errorf("Invalid withdrawal amount %d", amount)
+	}
+	if amount > m.Contributed {
+		return fmt.Errorf("Withdrawal of %d exceeds contribution of bank %s", amount, m.BankID)
+	}
+	if amount > p.Available {
+		return fmt.Errorf("Insufficient undrawn funds in pool %s", p.ID)
+	}
+	m.Contributed -= amount
+	p.Available -= amount
+	return nil
+}
+
+// Draw lends pool funds to a member, enforcing its drawing limit
+func (p *LiquidityPool) Draw(m *PoolMember, amount int64, now int64) error {
+	if amount <= 0 {
+		return fmt.Errorf("Invalid draw amount %d", amount)
+	}
+	if m.Drawn+amount > m.DrawLimit {
+		return fmt.Errorf("Draw of %d exceeds drawing limit of bank %s", amount, m.BankID)
+	}
+	if amount > p.Available {
+		return fmt.Errorf("Insufficient funds in pool %s", p.ID)
+	}
+	p.Accrue(m, now)
+	m.Drawn += amount
+	p.Available -= amount
+	return nil
+}
+
+// Repay settles outstanding interest first and then principal, returning the
+// amounts applied to each. Interest is distributed to contributors pro rata.
+func (p *LiquidityPool) Repay(m *PoolMember, amount int64, now int64) (int64, int64, error) {
+	if amount <= 0 {
+		return 0, 0, fmt.Errorf("Invalid repayment amount %d", amount)
+	}
+	p.Accrue(m, now)
+	if amount > m.Drawn+m.AccruedInterest {
+		return 0, 0, fmt.Errorf("Repayment of %d exceeds amount owed by bank %s", amount, m.BankID)
+	}
+	interest := amount
+	if interest > m.AccruedInterest {
+		interest = m.AccruedInterest
+	}
+	principal := amount - interest
+	m.AccruedInterest -= interest
+	m.Drawn -= principal
+	p.Available += amount
+	p.distributeInterest(interest)
+	return principal, interest, nil
+}
+
+// Accrue adds interest on the member's drawn balance since its last accrual
+func (p *LiquidityPool) Accrue(m *PoolMember, now int64) {
+	rate := p.BenchmarkRate + p.InterestRate
+	if m.Drawn > 0 && rate > 0 && now > m.LastAccrual {
+		m.AccruedInterest += m.Drawn * rate * (now - m.LastAccrual) / (10000 * secondsPerYear)
+	}
+	m.LastAccrual = now
+}
+
+func (p *LiquidityPool) distributeInterest(interest int64) {
+	total := p.totalContributed()
+	if interest <= 0 || total == 0 {
+		return
+	}
+	for _, m := range p.Members {
+		share := interest * m.Contributed / total
+		m.InterestEarned += share
+		m.Contributed += share
+	}
+}
+
+func (p *LiquidityPool) totalContributed() int64 {
+	var total int64
+	for _, m := range p.Members {
+		total += m.Contributed
+	}
+	return total
+}
+
+// Position returns the pool position with per-member contribution shares
+func (p *LiquidityPool) Position() *PoolPosition {
+	pos := &PoolPosition{
+		PoolID:           p.ID,
+		CurrencyCode:     p.CurrencyCode,
+		Available:        p.Available,
+		TotalContributed: p.totalContributed(),
+	}
+	for _, m := range p.Members {
+		pos.TotalDrawn += m.Drawn
+		mp := &PoolMemberPosition{PoolMember: m}
+		if pos.TotalContributed > 0 {
+			mp.Share = float64(m.Contributed) / float64(pos.TotalContributed)
+		}
+		pos.Members = append(pos.Members, mp)
+	}
+	sort.Slice(pos.Members, func(i, j int) bool { return pos.Members[i].BankID < pos.Members[j].BankID })
+	return pos
+}
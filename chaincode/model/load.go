@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// MaxLoadAccounts caps the accounts a single GenerateLoad invocation creates
+	MaxLoadAccounts = 1000
+	// MaxLoadTransfers caps the transfers a single GenerateLoad invocation submits
+	MaxLoadTransfers = 5000
+)
+
+// LoadFault stores allowed values for faults injected by GenerateLoad.
+// Allowed values are "insufficient_funds", "closed_account", "hot_key"
+type LoadFault string
+
+const (
+	// FaultInsufficientFunds submits a transfer larger than the payer balance
+	FaultInsufficientFunds LoadFault = "insufficient_funds"
+	// FaultClosedAccount closes the payee before the transfer
+	FaultClosedAccount LoadFault = "closed_account"
+	// FaultHotKey reads and writes a shared key so concurrent invocations conflict at validation
+	FaultHotKey LoadFault = "hot_key"
+)
+
+// LoadSpec configures a synthetic load run
+type LoadSpec struct {
+	Prefix         string      `json:"prefix"` // customer ID prefix of generated accounts
+	Accounts       int         `json:"accounts"`
+	Transfers      int         `json:"transfers"`
+	CurrencyCode   string      `json:"currency"`
+	CountryCodes   []string    `json:"countries,omitempty"` // assigned round-robin to spread load across corridors
+	InitialBalance int64       `json:"initial_balance"`
+	MaxAmount      int64       `json:"max_amount"`
+	Seed           int64       `json:"seed"`       // random seed; must be fixed so all endorsers generate the same load
+	FaultRate      int         `json:"fault_rate"` // percentage of transfers with an injected fault
+	Faults         []LoadFault `json:"faults,omitempty"`
+}
+
+// LoadReport summarises a synthetic load run
+type LoadReport struct {
+	Accounts  int               `json:"accounts"`
+	Transfers int               `json:"transfers"`
+	Settled   int               `json:"settled"`
+	Failed    int               `json:"failed"`
+	Faults    map[LoadFault]int `json:"faults"`
+	Failures  map[string]int    `json:"failures"` // error message counts
+}
+
+// CreateLoadSpec Factory function creates a validated LoadSpec with defaults applied
+func CreateLoadSpec(specBytes []byte) (*LoadSpec, error) {
+	spec := &LoadSpec{Prefix: "load", Accounts: 10, Transfers: 100, CurrencyCode: "AUD", InitialBalance: 100000, MaxAmount: 1000}
+	if len(specBytes) > 0 {
+		if err := json.Unmarshal(specBytes, spec); err != nil {
+			return nil, err
+		}
+	}
+	if spec.Accounts < 2 || spec.Accounts > MaxLoadAccounts {
+		return nil, fmt.Errorf("Accounts must be between 2 and %d", MaxLoadAccounts)
+	}
+	if spec.Transfers < 0 || spec.Transfers > MaxLoadTransfers {
+		return nil, fmt.Errorf("Transfers must be between 0 and %d", MaxLoadTransfers)
+	}
+	if spec.MaxAmount <= 0 || spec.InitialBalance < 0 {
+		return nil, fmt.Errorf("Invalid max amount %d or initial balance %d", spec.MaxAmount, spec.InitialBalance)
+	}
+	if spec.FaultRate < 0 || spec.FaultRate > 100 {
+		return nil, fmt.Errorf("Invalid fault rate %d", spec.FaultRate)
+	}
+	for _, f := range spec.Faults {
+		switch f {
+		case FaultInsufficientFunds, FaultClosedAccount, FaultHotKey:
+		default:
+			return nil, fmt.Errorf("Unknown fault %s", f)
+		}
+	}
+	return spec, nil
+}
+
+// CreateLoadReport Factory function creates an empty LoadReport
+func CreateLoadReport() *LoadReport {
+	return &LoadReport{Faults: make(map[LoadFault]int), Failures: make(map[string]int)}
+}
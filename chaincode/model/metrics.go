@@ -0,0 +1,25 @@
+package model
+
+// MetricsLatencyBuckets are the upper bounds in seconds of the latency
+// histogram buckets of the handler metrics
+var MetricsLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// HandlerMetrics counts the invocations of a handler function by a chaincode
+// container since it started
+type HandlerMetrics struct {
+	Function    string  `json:"function"`
+	Invocations int64   `json:"invocations"`
+	Errors      int64   `json:"errors"`         // invocations whose handler failed, including rejected callers
+	Latency     []int64 `json:"latency_bucket"` // invocations taking at most the bucket bound, by MetricsLatencyBuckets
+	LatencySum  float64 `json:"latency_sum"`    // seconds spent in all invocations
+}
+
+// Metrics holds the handler metrics of the chaincode container that answered
+// the query. Each peer runs its own container, so the metrics of a network
+// are the sum of those of its peers.
+type Metrics struct {
+	Container string            `json:"container"` // host name of the chaincode container
+	Started   int64             `json:"started"`   // unix timestamp the counting started at
+	Buckets   []float64         `json:"buckets"`   // upper bounds in seconds of the latency buckets
+	Handlers  []*HandlerMetrics `json:"handlers"`  // sorted by function
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxAmount is the largest amount in minor units a single money operation may
+// move, e.g. ten trillion in a currency with cents. Larger amounts are taken
+// to be client errors rather than payments.
+const MaxAmount int64 = 1000000000000000
+
+// AmountError is the error of an amount rejected by ValidateAmount. Its
+// message starts with the invalid_amount code.
+type AmountError struct {
+	Value  string // the amount as given
+	Reason string
+}
+
+func (e *AmountError) Error() string {
+	return fmt.Sprintf("%s: amount %s %s", InvalidAmount, e.Value, e.Reason)
+}
+
+// ValidateAmount checks the amount of a money operation is positive and at most MaxAmount
+func ValidateAmount(amount int64) error {
+	if amount <= 0 {
+		return &AmountError{Value: strconv.FormatInt(amount, 10), Reason: "must be positive"}
+	}
+	if amount > MaxAmount {
+		return &AmountError{Value: strconv.FormatInt(amount, 10), Reason: fmt.Sprintf("exceeds the maximum of %d", MaxAmount)}
+	}
+	return nil
+}
+
+// ParseAmount parses the amount argument of a money operation and validates it
+func ParseAmount(value string) (int64, error) {
+	amount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &AmountError{Value: value, Reason: "is not a whole number of minor units"}
+	}
+	return amount, ValidateAmount(amount)
+}
+
+// Money is an amount in the minor units of a currency, e.g. cents. Its
+// arithmetic is checked: operations that would overflow or mix currencies fail
+// rather than wrap around or add up amounts of different currencies.
+type Money struct {
+	Amount   int64  `json:"amount"`   // in minor units
+	Currency string `json:"currency"` // ISO 4217 code
+	Exponent int    `json:"exponent"` // number of minor unit digits, e.g. 2 for cents
+}
+
+// NewMoney returns an amount in minor units of the currency
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency, Exponent: CurrencyMinorUnits(currency)}
+}
+
+// Add returns the sum of the amounts
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	if (o.Amount > 0 && m.Amount > math.MaxInt64-o.Amount) || (o.Amount < 0 && m.Amount < math.MinInt64-o.Amount) {
+		return Money{}, fmt.Errorf("Amount %s plus %s overflows", m, o)
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency, Exponent: m.Exponent}, nil
+}
+
+// Sub returns the difference of the amounts
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	if (o.Amount < 0 && m.Amount > math.MaxInt64+o.Amount) || (o.Amount > 0 && m.Amount < math.MinInt64+o.Amount) {
+		return Money{}, fmt.Errorf("Amount %s minus %s overflows", m, o)
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency, Exponent: m.Exponent}, nil
+}
+
+// Mul returns the amount multiplied by the factor
+func (m Money) Mul(factor int64) (Money, error) {
+	product := m.Amount * factor
+	if m.Amount != 0 && (product/m.Amount != factor || (m.Amount == -1 && factor == math.MinInt64) || (factor == -1 && m.Amount == math.MinInt64)) {
+		return Money{}, fmt.Errorf("Amount %s times %d overflows", m, factor)
+	}
+	return Money{Amount: product, Currency: m.Currency, Exponent: m.Exponent}, nil
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// String formats the amount in major units with its currency, e.g. "12.34 AUD"
+func (m Money) String() string {
+	digits := strconv.FormatUint(absAmount(m.Amount), 10)
+	if m.Exponent > 0 {
+		if len(digits) <= m.Exponent {
+			digits = strings.Repeat("0", m.Exponent-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-m.Exponent] + "." + digits[len(digits)-m.Exponent:]
+	}
+	if m.Amount < 0 {
+		digits = "-" + digits
+	}
+	return digits + " " + m.Currency
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("Cannot combine amounts in %s and %s", m.Currency, o.Currency)
+	}
+	return nil
+}
+
+func absAmount(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-(amount + 1)) + 1
+	}
+	return uint64(amount)
+}
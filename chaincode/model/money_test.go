@@ -0,0 +1,95 @@
+package model
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidateAmount(t *testing.T) {
+	tests := []struct {
+		amount int64
+		valid  bool
+	}{
+		{math.MinInt64, false},
+		{-1, false},
+		{0, false},
+		{1, true},
+		{MaxAmount - 1, true},
+		{MaxAmount, true},
+		{MaxAmount + 1, false},
+		{math.MaxInt64, false},
+	}
+	for _, tt := range tests {
+		err := ValidateAmount(tt.amount)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateAmount(%d) = %v, expected no error", tt.amount, err)
+		}
+		if !tt.valid {
+			if _, ok := err.(*AmountError); !ok {
+				t.Errorf("ValidateAmount(%d) = %v, expected an AmountError", tt.amount, err)
+			} else if !strings.HasPrefix(err.Error(), string(InvalidAmount)) {
+				t.Errorf("ValidateAmount(%d) error %q does not start with the %s code", tt.amount, err, InvalidAmount)
+			}
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value  string
+		amount int64
+		valid  bool
+	}{
+		{"1", 1, true},
+		{strconv.FormatInt(MaxAmount, 10), MaxAmount, true},
+		{strconv.FormatInt(MaxAmount+1, 10), 0, false},
+		{"0", 0, false},
+		{"-500", 0, false},
+		{"9223372036854775808", 0, false}, // overflows int64
+		{"12.50", 0, false},
+		{"", 0, false},
+		{"1e3", 0, false},
+	}
+	for _, tt := range tests {
+		amount, err := ParseAmount(tt.value)
+		if tt.valid && (err != nil || amount != tt.amount) {
+			t.Errorf("ParseAmount(%q) = %d, %v, expected %d", tt.value, amount, err, tt.amount)
+		}
+		if !tt.valid {
+			if _, ok := err.(*AmountError); !ok {
+				t.Errorf("ParseAmount(%q) = %d, %v, expected an AmountError", tt.value, amount, err)
+			}
+		}
+	}
+}
+
+func TestTransferAmountBoundaries(t *testing.T) {
+	for _, tt := range []struct {
+		amount int64
+		valid  bool
+	}{{-1, false}, {0, false}, {1, true}, {MaxAmount, true}, {MaxAmount + 1, false}} {
+		transfer := &Transfer{FromCustomerID: "1", FromAccountID: "1", ToCustomerID: "2", ToAccountID: "2", Amount: tt.amount, CurrencyCode: "AUD"}
+		err := transfer.Validate()
+		if tt.valid != (err == nil) {
+			t.Errorf("Validate() of transfer of %d = %v, expected valid %t", tt.amount, err, tt.valid)
+		}
+		if err != nil && !strings.Contains(err.Error(), `"field":"amount"`) {
+			t.Errorf("Validate() of transfer of %d = %v, expected an amount field error", tt.amount, err)
+		}
+	}
+}
+
+func TestAccountHoldIsChecked(t *testing.T) {
+	account := &Account{ID: "1", CurrencyCode: "AUD", Held: math.MaxInt64 - 10}
+	if err := account.Hold(account.Money(11)); err == nil || account.Held != math.MaxInt64-10 {
+		t.Errorf("Expected an overflowing hold refused, got %v and a held amount of %d", err, account.Held)
+	}
+	if err := account.Hold(NewMoney(5, "USD")); err == nil {
+		t.Error("Expected a hold in another currency refused")
+	}
+	if err := account.Unhold(account.Money(10)); err != nil || account.Held != math.MaxInt64-20 {
+		t.Errorf("Expected the amount released, got %v and a held amount of %d", err, account.Held)
+	}
+}
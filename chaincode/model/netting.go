@@ -0,0 +1,146 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// InterbankObligationObjectType blockchain object type
+	InterbankObligationObjectType = "InterbankObligation"
+	// SettlementBatchObjectType blockchain object type
+	SettlementBatchObjectType = "SettlementBatch"
+)
+
+// ValueDateFormat is the layout of value dates
+const ValueDateFormat = "2006-01-02"
+
+// ObligationStatus stores allowed values for an interbank obligation's status.
+// Allowed values are "pending", "netted"
+type ObligationStatus string
+
+const (
+	// ObligationPending obligation awaits the netting run of its value date
+	ObligationPending ObligationStatus = "pending"
+	// ObligationNetted obligation is included in a settlement batch
+	ObligationNetted ObligationStatus = "netted"
+)
+
+// InterbankObligation is what a paying bank owes a receiving bank for one
+// customer transfer, settled at the end of its value date
+type InterbankObligation struct {
+	Entity
+	ID           string           `json:"id"`
+	TxID         string           `json:"tx_id"` // ledger transaction of the transfer
+	ValueDate    string           `json:"value_date"`
+	FromBankID   string           `json:"from_bank"`
+	ToBankID     string           `json:"to_bank"`
+	CurrencyCode string           `json:"currency"`
+	Amount       int64            `json:"amount"` // amount in cents
+	Status       ObligationStatus `json:"status"`
+	BatchID      string           `json:"batch_id,omitempty"`
+	Created      int64            `json:"created"` // unix timestamp
+}
+
+// NetPosition is a participant's multilateral net position in a currency,
+// positive when the participant is owed by the other participants
+type NetPosition struct {
+	BankID       string `json:"bank_id"`
+	CurrencyCode string `json:"currency"`
+	Net          int64  `json:"net"`
+}
+
+// SettlementBatch records the multilateral netting of the pending
+// obligations of a value date
+type SettlementBatch struct {
+	Entity
+	ID            string           `json:"id"`
+	ValueDate     string           `json:"value_date"`
+	ObligationIDs []string         `json:"obligation_ids"`
+	Gross         map[string]int64 `json:"gross"` // total obligations by currency
+	Positions     []*NetPosition   `json:"positions"`
+	RunBy         string           `json:"run_by"`
+	Created       int64            `json:"created"` // unix timestamp
+}
+
+// SettlementBatchList holds a list of settlement batches
+type SettlementBatchList struct {
+	Batches []*SettlementBatch `json:"batches"`
+}
+
+// CreateInterbankObligation a factory function for the obligation of a transfer between two banks.
+// The value date is taken from the transfer's value_date param, or its value
+// date, or the transaction date.
+func CreateInterbankObligation(fromBankID string, toBankID string, t *Transfer, tx *TxContext) (*InterbankObligation, error) {
+	valueDate := tx.Time.UTC().Format(ValueDateFormat)
+	if t.ValueDate != "" {
+		valueDate = t.ValueDate
+	}
+	if date, ok := t.Params["value_date"]; ok {
+		if _, err := time.Parse(ValueDateFormat, date); err != nil {
+			return nil, fmt.Errorf("Invalid value date %s, expected YYYY-MM-DD", date)
+		}
+		valueDate = date
+	}
+	return &InterbankObligation{
+		Entity:       Entity{InterbankObligationObjectType},
+		ID:           tx.NewID(16),
+		TxID:         tx.ID,
+		ValueDate:    valueDate,
+		FromBankID:   fromBankID,
+		ToBankID:     toBankID,
+		CurrencyCode: t.CurrencyCode,
+		Amount:       t.Amount,
+		Status:       ObligationPending,
+		Created:      tx.Time.Unix(),
+	}, nil
+}
+
+// CreateSettlementBatch nets the pending obligations of a value date into a
+// settlement batch and marks them netted. Participants whose obligations
+// cancel out are kept with a zero net position.
+func CreateSettlementBatch(id string, valueDate string, obligations []*InterbankObligation, runBy string, tx *TxContext) (*SettlementBatch, error) {
+	if _, err := time.Parse(ValueDateFormat, valueDate); err != nil {
+		return nil, fmt.Errorf("Invalid value date %s, expected YYYY-MM-DD", valueDate)
+	}
+	batch := &SettlementBatch{
+		Entity:        Entity{SettlementBatchObjectType},
+		ID:            id,
+		ValueDate:     valueDate,
+		ObligationIDs: []string{},
+		Gross:         map[string]int64{},
+		Positions:     []*NetPosition{},
+		RunBy:         runBy,
+		Created:       tx.Time.Unix(),
+	}
+	net := map[[2]string]*NetPosition{}
+	position := func(bankID string, currency string) *NetPosition {
+		p, ok := net[[2]string{bankID, currency}]
+		if !ok {
+			p = &NetPosition{BankID: bankID, CurrencyCode: currency}
+			net[[2]string{bankID, currency}] = p
+			batch.Positions = append(batch.Positions, p)
+		}
+		return p
+	}
+	for _, o := range obligations {
+		if o.Status != ObligationPending || o.ValueDate != valueDate {
+			continue
+		}
+		position(o.FromBankID, o.CurrencyCode).Net -= o.Amount
+		position(o.ToBankID, o.CurrencyCode).Net += o.Amount
+		batch.Gross[o.CurrencyCode] += o.Amount
+		batch.ObligationIDs = append(batch.ObligationIDs, o.ID)
+		o.Status = ObligationNetted
+		o.BatchID = id
+	}
+	sort.Slice(batch.Positions, func(i, j int) bool {
+		a, b := batch.Positions[i], batch.Positions[j]
+		if a.CurrencyCode != b.CurrencyCode {
+			return a.CurrencyCode < b.CurrencyCode
+		}
+		return a.BankID < b.BankID
+	})
+	return batch, nil
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+)
+
+// OutgoingTransferObjectType blockchain object type
+const OutgoingTransferObjectType = "OutgoingTransfer"
+
+// ApprovalStatus stores allowed values for an approval-gated transfer's status.
+// Allowed values are "pending_approval", "settled", "rejected"
+type ApprovalStatus string
+
+const (
+	// PendingApproval transfer is collecting signer approvals
+	PendingApproval ApprovalStatus = "pending_approval"
+	// ApprovalSettled transfer reached quorum and settled
+	ApprovalSettled ApprovalStatus = "settled"
+	// ApprovalRejected transfer was rejected by an approver and never executed
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// Approval is a signer's endorsement of a transfer, identified by the
+// invoking identity and the ledger transaction that carried its signature
+type Approval struct {
+	Signer   string `json:"signer"`
+	TxID     string `json:"tx_id"`
+	Approved int64  `json:"approved"` // unix timestamp
+}
+
+// OutgoingTransfer is a transfer from a multi-signature account awaiting approval
+type OutgoingTransfer struct {
+	Entity
+	ID        string         `json:"id"`
+	Transfer  *Transfer      `json:"transfer"`
+	Quorum    int            `json:"required_signatures"`
+	Approvals []*Approval    `json:"approvals"`
+	Status    ApprovalStatus `json:"status"`
+	Created   int64          `json:"created"` // unix timestamp
+}
+
+// CreateOutgoingTransfer a factory function for creating new OutgoingTransfer entities
+func CreateOutgoingTransfer(t *Transfer, quorum int, tx *TxContext) *OutgoingTransfer {
+	return &OutgoingTransfer{
+		Entity:   Entity{OutgoingTransferObjectType},
+		ID:       tx.NewID(16),
+		Transfer: t,
+		Quorum:   quorum,
+		Status:   PendingApproval,
+		Created:  tx.Time.Unix(),
+	}
+}
+
+// Approve records a signer approval, rejecting duplicate approvals
+func (o *OutgoingTransfer) Approve(signer string, tx *TxContext) error {
+	if o.Status != PendingApproval {
+		return fmt.Errorf("Transfer %s is not pending approval", o.ID)
+	}
+	for _, a := range o.Approvals {
+		if a.Signer == signer {
+			return fmt.Errorf("Transfer %s already approved by this signer", o.ID)
+		}
+	}
+	o.Approvals = append(o.Approvals, &Approval{Signer: signer, TxID: tx.ID, Approved: tx.Time.Unix()})
+	return nil
+}
+
+// QuorumMet returns true once enough distinct signers have approved. The
+// account's current configuration applies: only the approvals of its current
+// signers count, so an approval given by a signer since removed from the
+// account no longer does, and the quorum is the account's current quorum.
+func (o *OutgoingTransfer) QuorumMet(account *Account) bool {
+	approvals := 0
+	for _, a := range o.Approvals {
+		if account.IsSigner(a.Signer) {
+			approvals++
+		}
+	}
+	return approvals >= account.Quorum
+}
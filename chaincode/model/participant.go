@@ -0,0 +1,31 @@
+package model
+
+// Participant is the routing view of a registered bank: the institution whose
+// BIC accounts reference, the MSP its callers belong to, the currencies it
+// transacts in and the accounts it settles each currency through. The bank
+// registry holds the record; a participant carries no state of its own.
+type Participant struct {
+	BIC                string                        `json:"bic"`
+	Name               string                        `json:"name"`
+	MSPID              string                        `json:"msp_id"`
+	SettlementAccounts map[string]*SettlementAccount `json:"settlement_accounts,omitempty"` // by currency
+	Currencies         []string                      `json:"currencies,omitempty"`
+	Status             BankStatus                    `json:"status"`
+}
+
+// ParticipantList holds a list of network participants
+type ParticipantList struct {
+	Participants []*Participant `json:"participants"`
+}
+
+// Participant returns the bank as a network participant
+func (b *Bank) Participant() *Participant {
+	return &Participant{
+		BIC:                b.BIC,
+		Name:               b.Name,
+		MSPID:              b.MSPID,
+		SettlementAccounts: b.SettlementAccounts,
+		Currencies:         b.Currencies,
+		Status:             b.Status,
+	}
+}
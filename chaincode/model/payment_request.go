@@ -0,0 +1,97 @@
+package model
+
+import (
+	"time"
+)
+
+// PaymentRequestObjectType blockchain object type
+const PaymentRequestObjectType = "PaymentRequest"
+
+// DueDateFormat is the layout of payment request due dates
+const DueDateFormat = "2006-01-02"
+
+// PaymentRequestStatus stores allowed values for a payment request's status.
+// Allowed values are "pending", "paid", "cancelled"
+type PaymentRequestStatus string
+
+const (
+	// PaymentRequestPending request awaiting the payer
+	PaymentRequestPending PaymentRequestStatus = "pending"
+	// PaymentRequestPaid request settled by the payer
+	PaymentRequestPaid PaymentRequestStatus = "paid"
+	// PaymentRequestCancelled request withdrawn by the payee
+	PaymentRequestCancelled PaymentRequestStatus = "cancelled"
+)
+
+// PaymentRequest asks a customer to pay an amount into the payee's account
+type PaymentRequest struct {
+	Entity
+	ID              string               `json:"id" validate:"max=35,reference"` // end-to-end reference of the paying transfer
+	PayeeCustomerID string               `json:"payee_customer" validate:"required,max=64"`
+	PayeeAccountID  string               `json:"payee_account" validate:"required,max=64"`
+	PayerCustomerID string               `json:"payer_customer" validate:"required,max=64"`
+	Amount          int64                `json:"amount" validate:"amount"` // amount in cents
+	CurrencyCode    string               `json:"currency" validate:"required,currency"`
+	DueDate         string               `json:"due_date,omitempty"` // YYYY-MM-DD
+	Memo            string               `json:"memo,omitempty" validate:"max=140"`
+	InvoiceRef      string               `json:"invoice_ref,omitempty" validate:"max=35,reference"`
+	Status          PaymentRequestStatus `json:"status"`
+	RequestedBy     string               `json:"requested_by"`
+	Created         int64                `json:"created"`                 // unix timestamp
+	PayerAccountID  string               `json:"payer_account,omitempty"` // account the request was paid from
+	Paid            int64                `json:"paid,omitempty"`          // unix timestamp
+	TxID            string               `json:"tx_id,omitempty"`         // ledger transaction that paid the request
+}
+
+// PaymentRequestList holds a list of payment requests
+type PaymentRequestList struct {
+	Requests []*PaymentRequest `json:"requests"`
+}
+
+// CreatePaymentRequest Factory function creates a new PaymentRequest struct and returns a pointer to it
+func CreatePaymentRequest(requestBytes []byte, requestedBy string, tx *TxContext) (*PaymentRequest, error) {
+	request := new(PaymentRequest)
+	if err := Unmarshal(requestBytes, request); err != nil {
+		return nil, err
+	}
+	if request.DueDate != "" {
+		if _, err := time.Parse(DueDateFormat, request.DueDate); err != nil {
+			return nil, &ValidationError{Fields: []*FieldError{{Field: "due_date", Rule: "date", Message: "must be a date in YYYY-MM-DD format"}}}
+		}
+	}
+	request.ObjectType = PaymentRequestObjectType
+	if request.ID == "" {
+		request.ID = tx.NewID(12)
+	}
+	request.Status = PaymentRequestPending
+	request.RequestedBy = requestedBy
+	request.Created = tx.Time.Unix()
+	request.PayerAccountID, request.Paid, request.TxID = "", 0, ""
+	return request, nil
+}
+
+// Transfer returns the transfer paying the request from the payer account.
+// The request ID is passed to the payee as end-to-end reference.
+func (r *PaymentRequest) Transfer(payerAccountID string) *Transfer {
+	return &Transfer{
+		FromCustomerID: r.PayerCustomerID,
+		FromAccountID:  payerAccountID,
+		ToCustomerID:   r.PayeeCustomerID,
+		ToAccountID:    r.PayeeAccountID,
+		Amount:         r.Amount,
+		CurrencyCode:   r.CurrencyCode,
+		Description:    "Payment request " + r.ID,
+		InvoiceRef:     r.InvoiceRef,
+		EndToEndID:     r.ID,
+		Memo:           r.Memo,
+		Params:         map[string]string{"payment_request": r.ID},
+	}
+}
+
+// MarkPaid records the ledger transaction that paid the request
+func (r *PaymentRequest) MarkPaid(payerAccountID string, tx *TxContext) {
+	r.Status = PaymentRequestPaid
+	r.PayerAccountID = payerAccountID
+	r.Paid = tx.Time.Unix()
+	r.TxID = tx.ID
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PayrollRunObjectType blockchain object type
+const PayrollRunObjectType = "PayrollRun"
+
+// PayDateFormat is the layout of payroll pay dates
+const PayDateFormat = "2006-01-02"
+
+// MaxPayrollItems caps the number of employees paid in a single run
+const MaxPayrollItems = 1000
+
+// PayrollStatus stores allowed values for a payroll run's status.
+// Allowed values are "scheduled", "executed", "cancelled"
+type PayrollStatus string
+
+const (
+	// PayrollScheduled run awaiting its pay date
+	PayrollScheduled PayrollStatus = "scheduled"
+	// PayrollExecuted run has paid all employees
+	PayrollExecuted PayrollStatus = "executed"
+	// PayrollCancelled run was withdrawn before execution
+	PayrollCancelled PayrollStatus = "cancelled"
+)
+
+// PayrollItem is a single employee payment of a payroll run
+type PayrollItem struct {
+	EmployeeID string `json:"employee_id"`
+	Disbursement
+}
+
+// PayrollRun holds the employee payments an employer makes on a pay date
+type PayrollRun struct {
+	Entity
+	ID                 string         `json:"id"`
+	EmployerCustomerID string         `json:"employer_customer"`
+	EmployerAccountID  string         `json:"employer_account"`
+	CurrencyCode       string         `json:"currency"`
+	PayDate            string         `json:"pay_date"` // YYYY-MM-DD
+	Items              []*PayrollItem `json:"items"`
+	Total              int64          `json:"total"` // amount in cents
+	Status             PayrollStatus  `json:"status"`
+	Created            int64          `json:"created"`            // unix timestamp
+	Executed           int64          `json:"executed,omitempty"` // unix timestamp
+	TxID               string         `json:"tx_id,omitempty"`    // ledger transaction that executed the run
+}
+
+// PayrollRunList holds a list of payroll runs
+type PayrollRunList struct {
+	Runs []*PayrollRun `json:"runs"`
+}
+
+// PayrollReport summarises an executed payroll run
+type PayrollReport struct {
+	RunID         string         `json:"run_id"`
+	PayDate       string         `json:"pay_date"`
+	Status        PayrollStatus  `json:"status"`
+	EmployeeCount int            `json:"employee_count"`
+	Total         int64          `json:"total"`
+	CurrencyCode  string         `json:"currency"`
+	Executed      int64          `json:"executed,omitempty"`
+	TxID          string         `json:"tx_id,omitempty"`
+	Items         []*PayrollItem `json:"items"`
+}
+
+// CreatePayrollRun Factory function creates a new PayrollRun struct and returns a pointer to it
+func CreatePayrollRun(runBytes []byte, tx *TxContext) (*PayrollRun, error) {
+	run := new(PayrollRun)
+	if err := json.Unmarshal(runBytes, run); err != nil {
+		return nil, err
+	}
+	run.ObjectType = PayrollRunObjectType
+	if run.ID == "" {
+		run.ID = tx.NewID(12)
+	}
+	if err := run.Validate(); err != nil {
+		return nil, err
+	}
+	run.Status = PayrollScheduled
+	run.Created = tx.Time.Unix()
+	return run, nil
+}
+
+// Validate - checks the run has an employer, a valid pay date and unique, positive employee payments
+func (r *PayrollRun) Validate() error {
+	if r.EmployerCustomerID == "" || r.EmployerAccountID == "" {
+		return errors.New("Missing required employer_customer and / or employer_account")
+	}
+	if _, err := time.Parse(PayDateFormat, r.PayDate); err != nil {
+		return fmt.Errorf("Invalid pay date %s", r.PayDate)
+	}
+	if len(r.Items) == 0 {
+		return errors.New("Payroll run has no employee payments")
+	}
+	if len(r.Items) > MaxPayrollItems {
+		return fmt.Errorf("Payroll run exceeds %d employee payments", MaxPayrollItems)
+	}
+	r.Total = 0
+	employees := make(map[string]bool)
+	accounts := make(map[string]bool)
+	for i, item := range r.Items {
+		if item.EmployeeID == "" {
+			return fmt.Errorf("Missing required employee_id in payment %d", i)
+		}
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("Invalid payment %d. Error: %s", i, err)
+		}
+		account := item.CustomerID + "/" + item.AccountID
+		if employees[item.EmployeeID] || accounts[account] {
+			return fmt.Errorf("Duplicate employee or account in payment %d", i)
+		}
+		employees[item.EmployeeID] = true
+		accounts[account] = true
+		r.Total += item.Amount
+	}
+	return nil
+}
+
+// Due returns true if the pay date has been reached at the given time
+func (r *PayrollRun) Due(now time.Time) bool {
+	payDate, err := time.Parse(PayDateFormat, r.PayDate)
+	return err == nil && !now.UTC().Before(payDate)
+}
+
+// Disbursements returns the employee payments as bulk disbursement items
+func (r *PayrollRun) Disbursements() []*Disbursement {
+	items := make([]*Disbursement, len(r.Items))
+	for i, item := range r.Items {
+		d := item.Disbursement
+		if d.Description == "" {
+			d.Description = fmt.Sprintf("Payroll %s %s", r.PayDate, item.EmployeeID)
+		}
+		items[i] = &d
+	}
+	return items
+}
+
+// Report returns the payroll run report
+func (r *PayrollRun) Report() *PayrollReport {
+	return &PayrollReport{
+		RunID:         r.ID,
+		PayDate:       r.PayDate,
+		Status:        r.Status,
+		EmployeeCount: len(r.Items),
+		Total:         r.Total,
+		CurrencyCode:  r.CurrencyCode,
+		Executed:      r.Executed,
+		TxID:          r.TxID,
+		Items:         r.Items,
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+// PendingTransferObjectType blockchain object type
+const PendingTransferObjectType = "PendingTransfer"
+
+// PendingTransferStatus stores allowed values for a pending transfer's status.
+// Allowed values are "pending", "settled", "rejected"
+type PendingTransferStatus string
+
+const (
+	// TransferPending funds are held from the payer awaiting settlement
+	TransferPending PendingTransferStatus = "pending"
+	// TransferSettled funds were paid to the payee
+	TransferSettled PendingTransferStatus = "settled"
+	// TransferRejected held funds were returned to the payer
+	TransferRejected PendingTransferStatus = "rejected"
+)
+
+// PendingTransfer is a two-phase transfer whose funds are debited from the
+// payer and held until the transfer is settled or rejected
+type PendingTransfer struct {
+	Entity
+	ID          string                `json:"id"`
+	Transfer    Transfer              `json:"transfer"`
+	Held        int64                 `json:"held"` // amount in cents debited from the payer, including the fee
+	Status      PendingTransferStatus `json:"status"`
+	Reason      string                `json:"reason,omitempty"` // why the transfer was rejected
+	InitiatedBy string                `json:"initiated_by"`
+	ResolvedBy  string                `json:"resolved_by,omitempty"`
+	Initiated   int64                 `json:"initiated"`          // unix timestamp
+	Resolved    int64                 `json:"resolved,omitempty"` // unix timestamp
+}
+
+// PendingTransferList holds a list of pending transfers
+type PendingTransferList struct {
+	Transfers []*PendingTransfer `json:"transfers"`
+}
+
+// CreatePendingTransfer a factory function for a transfer holding the given amount
+func CreatePendingTransfer(id string, t *Transfer, held int64, initiatedBy string, tx *TxContext) *PendingTransfer {
+	return &PendingTransfer{
+		Entity:      Entity{PendingTransferObjectType},
+		ID:          id,
+		Transfer:    *t,
+		Held:        held,
+		Status:      TransferPending,
+		InitiatedBy: initiatedBy,
+		Initiated:   tx.Time.Unix(),
+	}
+}
+
+// Resolve records the final status of the transfer
+func (p *PendingTransfer) Resolve(status PendingTransferStatus, reason string, by string, tx *TxContext) {
+	p.Status = status
+	p.Reason = reason
+	p.ResolvedBy = by
+	p.Resolved = tx.Time.Unix()
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// PointsProgramObjectType blockchain object type
+	PointsProgramObjectType = "PointsProgram"
+	// PointsBalanceObjectType blockchain object type
+	PointsBalanceObjectType = "PointsBalance"
+)
+
+// PointsProgram holds the loyalty earn and redemption rules for a currency
+type PointsProgram struct {
+	Entity
+	CurrencyCode          string `json:"currency"`
+	PointsPerFeeUnit      int64  `json:"points_per_fee_unit"`      // points earned per whole currency unit of fees paid
+	PointsPerTransferUnit int64  `json:"points_per_transfer_unit"` // points earned per whole currency unit transferred
+	RedemptionRate        int64  `json:"redemption_rate"`          // cents credited per point redeemed
+	FundingCustomerID     string `json:"funding_customer"`         // account funding redemptions
+	FundingAccountID      string `json:"funding_account"`
+}
+
+// PointsBalance holds a customer's loyalty points, kept alongside their accounts
+type PointsBalance struct {
+	Entity
+	CustomerID string `json:"customer_id"`
+	Balance    int64  `json:"balance"`
+	Earned     int64  `json:"earned"`
+	Redeemed   int64  `json:"redeemed"`
+	Updated    int64  `json:"updated"` // unix timestamp
+}
+
+// CreatePointsProgram Factory function creates a new PointsProgram struct and returns a pointer to it
+func CreatePointsProgram(programBytes []byte) (*PointsProgram, error) {
+	program := new(PointsProgram)
+	if err := json.Unmarshal(programBytes, program); err != nil {
+		return nil, err
+	}
+	program.ObjectType = PointsProgramObjectType
+	if program.CurrencyCode == "" {
+		return nil, errors.New("Missing required currency value")
+	}
+	if program.PointsPerFeeUnit < 0 || program.PointsPerTransferUnit < 0 {
+		return nil, errors.New("Earn rates cannot be negative")
+	}
+	if program.RedemptionRate <= 0 {
+		return nil, fmt.Errorf("Invalid redemption rate %d", program.RedemptionRate)
+	}
+	if program.FundingCustomerID == "" || program.FundingAccountID == "" {
+		return nil, errors.New("Missing required funding_customer and / or funding_account")
+	}
+	return program, nil
+}
+
+// Earned returns the points earned for a transfer amount and the fee paid on it
+func (p *PointsProgram) Earned(amount int64, fee int64) int64 {
+	return fee*p.PointsPerFeeUnit/100 + amount*p.PointsPerTransferUnit/100
+}
+
+// CreatePointsBalance Factory function creates an empty PointsBalance for a customer
+func CreatePointsBalance(customerID string) *PointsBalance {
+	return &PointsBalance{Entity: Entity{PointsBalanceObjectType}, CustomerID: customerID}
+}
+
+// Earn adds points to the balance
+func (b *PointsBalance) Earn(points int64, tx *TxContext) {
+	b.Balance += points
+	b.Earned += points
+	b.Updated = tx.Time.Unix()
+}
+
+// Redeem removes points from the balance
+func (b *PointsBalance) Redeem(points int64, tx *TxContext) error {
+	if points <= 0 {
+		return fmt.Errorf("Invalid points value %d", points)
+	}
+	if points > b.Balance {
+		return fmt.Errorf("Insufficient points balance for customer %s", b.CustomerID)
+	}
+	b.Balance -= points
+	b.Redeemed += points
+	b.Updated = tx.Time.Unix()
+	return nil
+}
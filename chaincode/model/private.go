@@ -0,0 +1,124 @@
+package model
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+// PrivateDataField is the field of a public record referencing the private
+// data split off it
+const PrivateDataField = "private_data"
+
+// privateDataFieldName is the quoted field name, which every record with a
+// private data reference contains
+var privateDataFieldName = []byte(`"` + PrivateDataField + `"`)
+
+// PrivateModel is implemented by models with fields kept in private data
+// collections rather than on the public channel
+type PrivateModel interface {
+	Model
+	// PrivateFields returns the JSON names of the private fields
+	PrivateFields() []string
+}
+
+// PrivateModels holds a prototype of each private model by object type
+var PrivateModels = map[string]PrivateModel{
+	AccountObjectType:     &Account{Entity: Entity{AccountObjectType}},
+	TransactionObjectType: &Transaction{Entity: Entity{TransactionObjectType}},
+	KYCProfileObjectType:  &KYCProfile{Entity: Entity{KYCProfileObjectType}},
+	CustomerObjectType:    &Customer{Entity: Entity{CustomerObjectType}},
+}
+
+// PrivateDataRef records in a public record where its private fields are kept
+// and the hash that proves them
+type PrivateDataRef struct {
+	Collections []string `json:"collections"`
+	Hash        string   `json:"hash"` // hex SHA-256 of the private data
+}
+
+// SplitPrivateData splits the private fields of a record off into a private
+// record salted with the salt, and references it from the public record. It
+// returns nil private data for records without private fields.
+func SplitPrivateData(record []byte, salt string, collections []string) ([]byte, []byte, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(record, &fields); err != nil {
+		return record, nil, nil
+	}
+	var objectType string
+	json.Unmarshal(fields["docType"], &objectType)
+	m, ok := PrivateModels[objectType]
+	if !ok {
+		return record, nil, nil
+	}
+	delete(fields, PrivateDataField)
+	private := map[string]json.RawMessage{}
+	for _, name := range m.PrivateFields() {
+		if value, ok := fields[name]; ok {
+			private[name] = value
+			delete(fields, name)
+		}
+	}
+	saltData, _ := json.Marshal(salt)
+	private["salt"] = saltData
+	privateData, err := json.Marshal(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	ref := &PrivateDataRef{Collections: collections, Hash: PrivateDataHash(privateData)}
+	if fields[PrivateDataField], err = json.Marshal(ref); err != nil {
+		return nil, nil, err
+	}
+	publicData, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicData, privateData, nil
+}
+
+// PrivateDataRefOf returns the private data reference of a public record, or
+// nil if the record has no private data. Records without the field are not
+// decoded, as every record read is checked.
+func PrivateDataRefOf(record []byte) *PrivateDataRef {
+	if !bytes.Contains(record, privateDataFieldName) {
+		return nil
+	}
+	fields := struct {
+		Ref *PrivateDataRef `json:"private_data"`
+	}{}
+	if err := json.Unmarshal(record, &fields); err != nil {
+		return nil
+	}
+	return fields.Ref
+}
+
+// MergePrivateData merges the private fields back into the public record,
+// verifying them against the hash the public record holds
+func MergePrivateData(record []byte, privateData []byte) ([]byte, error) {
+	ref := PrivateDataRefOf(record)
+	if ref == nil {
+		return record, nil
+	}
+	if hash := PrivateDataHash(privateData); hash != ref.Hash {
+		return nil, fmt.Errorf("Private data hash %s does not match public hash %s", hash, ref.Hash)
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(record, &fields); err != nil {
+		return nil, err
+	}
+	private := map[string]json.RawMessage{}
+	if err := json.Unmarshal(privateData, &private); err != nil {
+		return nil, err
+	}
+	delete(private, "salt")
+	for name, value := range private {
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+// PrivateDataHash returns the hex SHA-256 hash of private data
+func PrivateDataHash(privateData []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(privateData))
+}
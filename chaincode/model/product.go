@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ProductObjectType blockchain object type
+const ProductObjectType = "Product"
+
+// ProductKind stores allowed values for the kind of account a product offers.
+// Allowed values are "checking", "savings", "wallet", "merchant"
+type ProductKind string
+
+const (
+	// ProductChecking everyday transaction account
+	ProductChecking ProductKind = "checking"
+	// ProductSavings deposit account
+	ProductSavings ProductKind = "savings"
+	// ProductWallet stored value account
+	ProductWallet ProductKind = "wallet"
+	// ProductMerchant account receiving card and P2P payments for a business
+	ProductMerchant ProductKind = "merchant"
+)
+
+// Product is an account product of the catalog. Accounts opened with a
+// product must hold one of its currencies and be opened with at least its
+// minimum balance; transfers from them are charged the product's fee schedule
+// of the currency instead of the corridor's, and they earn the interest set
+// for the product's account type.
+type Product struct {
+	Entity
+	ID           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	Kind         ProductKind             `json:"kind"`
+	Currencies   []string                `json:"currencies"`
+	MinBalance   int64                   `json:"min_balance,omitempty"`   // opening balance in cents
+	FeeSchedules map[string]*FeeSchedule `json:"fee_schedules,omitempty"` // by currency
+	AccountType  string                  `json:"account_type"`            // account type of the interest config, defaults to the kind
+	Retired      bool                    `json:"retired"`                 // retired products take no new accounts
+	Updated      int64                   `json:"updated"`                 // unix timestamp
+}
+
+// ProductList holds the product catalog
+type ProductList struct {
+	Products []*Product `json:"products"`
+}
+
+// CreateProduct Factory function creates a new Product struct and returns a pointer to it
+func CreateProduct(productBytes []byte, tx *TxContext) (*Product, error) {
+	product := new(Product)
+	if err := json.Unmarshal(productBytes, product); err != nil {
+		return nil, err
+	}
+	product.ObjectType = ProductObjectType
+	if product.ID == "" || product.Name == "" {
+		return nil, errors.New("Missing required id and / or name")
+	}
+	switch product.Kind {
+	case ProductChecking, ProductSavings, ProductWallet, ProductMerchant:
+	default:
+		return nil, fmt.Errorf("Invalid product kind %s", product.Kind)
+	}
+	if len(product.Currencies) == 0 {
+		return nil, errors.New("Missing required currencies")
+	}
+	for _, currency := range product.Currencies {
+		if err := ValidateCurrency(currency); err != nil {
+			return nil, err
+		}
+	}
+	if product.MinBalance < 0 {
+		return nil, fmt.Errorf("Invalid min balance %d", product.MinBalance)
+	}
+	for currency, schedule := range product.FeeSchedules {
+		if !product.Offers(currency) {
+			return nil, fmt.Errorf("Fee schedule for %s, which the product does not offer", currency)
+		}
+		if schedule == nil {
+			return nil, fmt.Errorf("Invalid fee schedule for %s", currency)
+		}
+		schedule.CurrencyCode = currency
+		schedule.FromCountry, schedule.ToCountry = AnyCountry, AnyCountry
+		scheduleBytes, _ := json.Marshal(schedule)
+		validated, err := CreateFeeSchedule(scheduleBytes, tx)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid fee schedule for %s. Error: %s", currency, err)
+		}
+		product.FeeSchedules[currency] = validated
+	}
+	if product.AccountType == "" {
+		product.AccountType = string(product.Kind)
+	}
+	product.Updated = tx.Time.Unix()
+	return product, nil
+}
+
+// Offers returns true if accounts of the product may hold the currency
+func (p *Product) Offers(currency string) bool {
+	for _, c := range p.Currencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// Admit checks an account being opened meets the product's constraints and
+// sets its account type
+func (p *Product) Admit(a *Account) error {
+	if p.Retired {
+		return fmt.Errorf("Product %s is retired", p.ID)
+	}
+	if !p.Offers(a.CurrencyCode) {
+		return fmt.Errorf("Product %s is not offered in %s", p.ID, a.CurrencyCode)
+	}
+	if a.Balance < p.MinBalance {
+		return fmt.Errorf("Opening balance %d is below the minimum balance %d of product %s", a.Balance, p.MinBalance, p.ID)
+	}
+	a.Type = p.AccountType
+	return nil
+}
+
+// FeeSchedule returns the product's fee schedule of a currency, or nil if it has none
+func (p *Product) FeeSchedule(currency string) *FeeSchedule {
+	return p.FeeSchedules[currency]
+}
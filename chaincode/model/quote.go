@@ -0,0 +1,115 @@
+package model
+
+import (
+	"fmt"
+)
+
+// QuoteObjectType blockchain object type
+const QuoteObjectType = "Quote"
+
+// QuoteValidity is the number of seconds a quote can be executed after it was issued
+const QuoteValidity = 300
+
+// Quote locks the fee and exchange rate of a transfer for QuoteValidity
+// seconds. A transfer referencing the quote before it expires debits exactly
+// TotalDebit from the payer.
+type Quote struct {
+	Entity
+	ID             string  `json:"id"`
+	FromCustomerID string  `json:"from_customer" validate:"required,max=64"`
+	FromAccountID  string  `json:"from_account" validate:"required,max=64"`
+	ToCustomerID   string  `json:"to_customer" validate:"required,max=64"`
+	ToAccountID    string  `json:"to_account" validate:"required,max=64"`
+	ToTenant       string  `json:"to_tenant,omitempty" validate:"max=64"`
+	Amount         int64   `json:"amount" validate:"amount"` // amount in cents
+	CurrencyCode   string  `json:"currency"`                 // payer account currency
+	Fee            int64   `json:"fee"`
+	FeeType        FeeType `json:"fee_type,omitempty"`
+	FeeCustomerID  string  `json:"fee_customer,omitempty"` // collection account of the quoted fee
+	FeeAccountID   string  `json:"fee_account,omitempty"`
+	TotalDebit     int64   `json:"total_debit"`     // amount plus fee
+	CreditCurrency string  `json:"credit_currency"` // payee account currency
+	CreditAmount   int64   `json:"credit_amount"`   // before any withholding tax
+	Rate           int64   `json:"rate,omitempty"`  // scaled by ExchangeRateScale, 0 for same currency transfers
+	RateUpdated    int64   `json:"rate_updated,omitempty"`
+	RequestedBy    string  `json:"requested_by"`
+	Created        int64   `json:"created"`              // unix timestamp
+	Expires        int64   `json:"expires"`              // unix timestamp
+	UsedTxID       string  `json:"used_tx_id,omitempty"` // ledger transaction that executed the quote
+}
+
+// CreateQuote Factory function creates a new Quote struct and returns a pointer to it
+func CreateQuote(quoteBytes []byte, requestedBy string, tx *TxContext) (*Quote, error) {
+	quote := new(Quote)
+	if err := Unmarshal(quoteBytes, quote); err != nil {
+		return nil, err
+	}
+	quote.ObjectType = QuoteObjectType
+	quote.ID = tx.NewID(12)
+	quote.RequestedBy = requestedBy
+	quote.Created = tx.Time.Unix()
+	quote.Expires = quote.Created + QuoteValidity
+	quote.UsedTxID = ""
+	return quote, nil
+}
+
+// Price sets the fee, total debit and credited amount of the quote from the
+// fee schedule and exchange rate in force. Either may be nil if none applies.
+func (q *Quote) Price(from *Account, to *Account, schedule *FeeSchedule, rate *ExchangeRate) error {
+	q.CurrencyCode = from.CurrencyCode
+	q.CreditCurrency = to.CurrencyCode
+	q.Fee, q.FeeType, q.FeeCustomerID, q.FeeAccountID = 0, "", "", ""
+	if schedule != nil {
+		q.Fee = schedule.Fee(q.Amount)
+		q.FeeType = schedule.Type
+		q.FeeCustomerID, q.FeeAccountID = schedule.CollectionCustomerID, schedule.CollectionAccountID
+	}
+	total, err := NewMoney(q.Amount, q.CurrencyCode).Add(NewMoney(q.Fee, q.CurrencyCode))
+	if err != nil {
+		return err
+	}
+	q.TotalDebit = total.Amount
+	q.Rate, q.RateUpdated, q.CreditAmount = 0, 0, q.Amount
+	if q.CreditCurrency != q.CurrencyCode {
+		if rate == nil {
+			return fmt.Errorf("No exchange rate set for %s to %s", q.CurrencyCode, q.CreditCurrency)
+		}
+		q.Rate, q.RateUpdated = rate.Rate, rate.Updated
+		q.CreditAmount = q.Convert(q.Amount).ConvertedAmount
+	}
+	return nil
+}
+
+// Matches returns true if the transfer is the one quoted
+func (q *Quote) Matches(t *Transfer) bool {
+	return q.FromCustomerID == t.FromCustomerID && q.FromAccountID == t.FromAccountID &&
+		q.ToCustomerID == t.ToCustomerID && q.ToAccountID == t.ToAccountID && q.ToTenant == t.ToTenant &&
+		q.Amount == t.Amount && q.CurrencyCode == t.CurrencyCode
+}
+
+// Executable returns true if the quote was not executed yet and has not
+// expired at the given unix time
+func (q *Quote) Executable(now int64) bool {
+	return q.UsedTxID == "" && now <= q.Expires
+}
+
+// FeeSchedule returns the fee collection of the quoted fee, or nil if the
+// quote has no fee
+func (q *Quote) FeeSchedule() *FeeSchedule {
+	if q.Fee == 0 {
+		return nil
+	}
+	return &FeeSchedule{
+		Entity:               Entity{FeeScheduleObjectType},
+		CurrencyCode:         q.CurrencyCode,
+		Type:                 q.FeeType,
+		CollectionCustomerID: q.FeeCustomerID,
+		CollectionAccountID:  q.FeeAccountID,
+	}
+}
+
+// Convert converts an amount at the quoted exchange rate
+func (q *Quote) Convert(amount int64) *FXConversion {
+	rate := &ExchangeRate{Base: q.CurrencyCode, Quote: q.CreditCurrency, Rate: q.Rate, Updated: q.RateUpdated}
+	return rate.Convert(amount)
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// RateAttestationObjectType blockchain object type
+	RateAttestationObjectType = "RateAttestation"
+	// RateConfigObjectType blockchain object type
+	RateConfigObjectType = "RateConfig"
+)
+
+// RateAttestation is an exchange rate signed by a rate oracle, kept as the
+// evidence of the rates conversions were made at
+type RateAttestation struct {
+	Entity
+	ID        string `json:"id"` // ledger transaction that posted the rate
+	Base      string `json:"base"`
+	Quote     string `json:"quote"`
+	Rate      int64  `json:"rate"`      // quote units per base unit, scaled by ExchangeRateScale
+	Timestamp int64  `json:"timestamp"` // unix timestamp the oracle observed the rate
+	Source    string `json:"source"`    // where the oracle obtained the rate, e.g. "ECB"
+	Signature string `json:"signature"` // base64 encoded oracle signature over SignedMessage
+	Oracle    string `json:"oracle"`
+	OracleMSP string `json:"oracle_msp"`
+	Posted    int64  `json:"posted"` // unix timestamp
+}
+
+// RateConfig holds the network-wide exchange rate settings
+type RateConfig struct {
+	Entity
+	MaxAge  int64  `json:"max_age"` // seconds after which a rate is too stale to convert at, 0 for no limit
+	SetBy   string `json:"set_by"`
+	Updated int64  `json:"updated"` // unix timestamp
+}
+
+// CreateRateAttestation Factory function creates a new RateAttestation struct and returns a pointer to it
+func CreateRateAttestation(attestationBytes []byte, tx *TxContext) (*RateAttestation, error) {
+	a := new(RateAttestation)
+	if err := json.Unmarshal(attestationBytes, a); err != nil {
+		return nil, err
+	}
+	a.ObjectType = RateAttestationObjectType
+	if err := ValidateCurrency(a.Base); err != nil {
+		return nil, err
+	}
+	if err := ValidateCurrency(a.Quote); err != nil {
+		return nil, err
+	}
+	if a.Base == a.Quote {
+		return nil, errors.New("Base and quote currency must differ")
+	}
+	if a.Rate <= 0 {
+		return nil, fmt.Errorf("Invalid exchange rate %d", a.Rate)
+	}
+	if a.Timestamp <= 0 || a.Timestamp > tx.Time.Unix() {
+		return nil, fmt.Errorf("Invalid rate timestamp %d", a.Timestamp)
+	}
+	if a.Source == "" {
+		return nil, errors.New("Missing required source value")
+	}
+	if a.Signature == "" {
+		return nil, errors.New("Missing required signature")
+	}
+	a.ID = tx.ID
+	a.Posted = tx.Time.Unix()
+	return a, nil
+}
+
+// SignedMessage returns the bytes the oracle signs
+func (a *RateAttestation) SignedMessage() []byte {
+	return []byte(fmt.Sprintf("%s|%s|%d|%d|%s", a.Base, a.Quote, a.Rate, a.Timestamp, a.Source))
+}
+
+// ExchangeRate returns the exchange rate the attestation sets
+func (a *RateAttestation) ExchangeRate() *ExchangeRate {
+	return &ExchangeRate{
+		Entity:        Entity{ExchangeRateObjectType},
+		Base:          a.Base,
+		Quote:         a.Quote,
+		Rate:          a.Rate,
+		Publisher:     a.Oracle,
+		Updated:       a.Timestamp,
+		Source:        a.Source,
+		AttestationID: a.ID,
+	}
+}
+
+// CreateRateConfig Factory function creates a new RateConfig struct and returns a pointer to it
+func CreateRateConfig(configBytes []byte, setBy string, tx *TxContext) (*RateConfig, error) {
+	config := new(RateConfig)
+	if err := json.Unmarshal(configBytes, config); err != nil {
+		return nil, err
+	}
+	config.ObjectType = RateConfigObjectType
+	if config.MaxAge < 0 {
+		return nil, fmt.Errorf("Invalid max age %d", config.MaxAge)
+	}
+	config.SetBy = setBy
+	config.Updated = tx.Time.Unix()
+	return config, nil
+}
+
+// IsStale returns true if the rate is older than the configured maximum age
+func (c *RateConfig) IsStale(rate *ExchangeRate, tx *TxContext) bool {
+	return c.MaxAge > 0 && tx.Time.Unix()-rate.Updated > c.MaxAge
+}
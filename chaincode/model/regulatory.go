@@ -0,0 +1,216 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// ReportingThresholdObjectType blockchain object type
+	ReportingThresholdObjectType = "ReportingThreshold"
+	// RegulatoryReportObjectType blockchain object type
+	RegulatoryReportObjectType = "RegulatoryReport"
+)
+
+// ReportDateFormat is the layout of the dates of regulatory reports and reporting periods
+const ReportDateFormat = "2006-01-02"
+
+// RegulatoryReportKind stores allowed values for the reason a transfer was reported.
+// Allowed values are "large_transaction", "limit_exceeded"
+type RegulatoryReportKind string
+
+const (
+	// ReportLargeTransaction transfer above the reporting threshold of its currency
+	ReportLargeTransaction RegulatoryReportKind = "large_transaction"
+	// ReportLimitExceeded transfer that settled despite breaching limits enforced by flagging
+	ReportLimitExceeded RegulatoryReportKind = "limit_exceeded"
+)
+
+// FlowGrouping stores allowed values for how aggregate flows are grouped.
+// Allowed values are "corridor", "currency"
+type FlowGrouping string
+
+const (
+	// FlowsByCorridor totals flows per corridor and currency
+	FlowsByCorridor FlowGrouping = "corridor"
+	// FlowsByCurrency totals flows per currency across corridors
+	FlowsByCurrency FlowGrouping = "currency"
+)
+
+// ReportingThreshold is the amount in a currency above which a single transfer
+// is reported to the regulator
+type ReportingThreshold struct {
+	Entity
+	CurrencyCode string `json:"currency"`
+	Amount       int64  `json:"amount"` // amount in cents
+	SetBy        string `json:"set_by"`
+	Updated      int64  `json:"updated"` // unix timestamp
+}
+
+// RegulatoryReport records a transfer reported to the regulator
+type RegulatoryReport struct {
+	Entity
+	ID             string               `json:"id"`
+	Kind           RegulatoryReportKind `json:"kind"`
+	Date           string               `json:"date"`  // YYYY-MM-DD
+	TxID           string               `json:"tx_id"` // ledger transaction of the transfer
+	FromCustomerID string               `json:"from_customer"`
+	FromAccountID  string               `json:"from_account"`
+	FromCountry    string               `json:"from_country,omitempty"`
+	ToCustomerID   string               `json:"to_customer"`
+	ToAccountID    string               `json:"to_account"`
+	ToCountry      string               `json:"to_country,omitempty"`
+	Amount         int64                `json:"amount"` // amount in cents
+	CurrencyCode   string               `json:"currency"`
+	PurposeCode    string               `json:"purpose_code,omitempty"`
+	EndToEndID     string               `json:"end_to_end_id,omitempty"`
+	Threshold      int64                `json:"threshold,omitempty"` // reporting threshold exceeded by a large transaction
+	Breached       []string             `json:"breached,omitempty"`  // limits breached by a flagged transfer
+	Created        int64                `json:"created"`             // unix timestamp
+}
+
+// RegulatoryReportList holds a list of regulatory reports
+type RegulatoryReportList struct {
+	Reports []*RegulatoryReport `json:"reports"`
+}
+
+// FlaggedTransactions holds the transfers flagged by AML limits and stopped
+// by sanctions screening in a period
+type FlaggedTransactions struct {
+	From    string              `json:"from"`
+	To      string              `json:"to"`
+	Reports []*RegulatoryReport `json:"reports"`
+	Alerts  []*ComplianceAlert  `json:"alerts"`
+}
+
+// Flow totals the settled and failed transfers of a corridor or currency
+type Flow struct {
+	Corridor     string `json:"corridor,omitempty"` // empty when grouped by currency
+	CurrencyCode string `json:"currency"`
+	Settled      int64  `json:"settled"`
+	Failed       int64  `json:"failed"`
+	Volume       int64  `json:"volume"` // settled amount in cents
+	Fees         int64  `json:"fees"`
+}
+
+// AggregateFlows totals the corridor buckets of a period
+type AggregateFlows struct {
+	GroupBy FlowGrouping `json:"group_by"`
+	From    string       `json:"from,omitempty"`
+	To      string       `json:"to,omitempty"`
+	Flows   []*Flow      `json:"flows"`
+	flows   map[string]*Flow
+}
+
+// CreateReportingThreshold Factory function creates a new ReportingThreshold struct and returns a pointer to it
+func CreateReportingThreshold(thresholdBytes []byte, setBy string, tx *TxContext) (*ReportingThreshold, error) {
+	threshold := new(ReportingThreshold)
+	if err := json.Unmarshal(thresholdBytes, threshold); err != nil {
+		return nil, err
+	}
+	threshold.ObjectType = ReportingThresholdObjectType
+	if err := ValidateCurrency(threshold.CurrencyCode); err != nil {
+		return nil, err
+	}
+	if threshold.Amount <= 0 {
+		return nil, fmt.Errorf("Invalid threshold amount %d", threshold.Amount)
+	}
+	threshold.SetBy = setBy
+	threshold.Updated = tx.Time.Unix()
+	return threshold, nil
+}
+
+// Exceeded returns true if a transfer amount is above the threshold
+func (r *ReportingThreshold) Exceeded(amount int64) bool {
+	return amount > r.Amount
+}
+
+// CreateRegulatoryReport a factory function for a report of a transfer between two accounts
+func CreateRegulatoryReport(kind RegulatoryReportKind, from *Account, to *Account, t *Transfer, tx *TxContext) *RegulatoryReport {
+	return &RegulatoryReport{
+		Entity:         Entity{RegulatoryReportObjectType},
+		ID:             tx.NewID(16),
+		Kind:           kind,
+		Date:           tx.Time.UTC().Format(ReportDateFormat),
+		TxID:           tx.ID,
+		FromCustomerID: from.CustomerID,
+		FromAccountID:  from.ID,
+		FromCountry:    from.CountryCode,
+		ToCustomerID:   t.ToCustomerID,
+		ToAccountID:    t.ToAccountID,
+		ToCountry:      to.CountryCode,
+		Amount:         t.Amount,
+		CurrencyCode:   t.CurrencyCode,
+		PurposeCode:    t.PurposeCode,
+		EndToEndID:     t.EndToEndID,
+		Created:        tx.Time.Unix(),
+	}
+}
+
+// ParseReportingPeriod parses the optional dates of a reporting period, both
+// inclusive. An empty date leaves that end of the period open.
+func ParseReportingPeriod(from string, to string) (string, string, error) {
+	for _, date := range []string{from, to} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(ReportDateFormat, date); err != nil {
+			return "", "", fmt.Errorf("Invalid reporting date %s, expected YYYY-MM-DD", date)
+		}
+	}
+	if from != "" && to != "" && to < from {
+		return "", "", fmt.Errorf("Reporting end date %s is before start date %s", to, from)
+	}
+	return from, to, nil
+}
+
+// InPeriod returns true if a date falls within a reporting period
+func InPeriod(from string, to string, date string) bool {
+	return (from == "" || date >= from) && (to == "" || date <= to)
+}
+
+// ReportDate returns the reporting date of a unix timestamp
+func ReportDate(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format(ReportDateFormat)
+}
+
+// CreateAggregateFlows Factory function creates empty aggregate flows for a period
+func CreateAggregateFlows(groupBy FlowGrouping, from string, to string) (*AggregateFlows, error) {
+	switch groupBy {
+	case FlowsByCorridor, FlowsByCurrency:
+	default:
+		return nil, errors.New("Invalid grouping, expected corridor or currency")
+	}
+	return &AggregateFlows{GroupBy: groupBy, From: from, To: to, Flows: []*Flow{}, flows: map[string]*Flow{}}, nil
+}
+
+// Add totals a corridor bucket into its flow if it falls within the period
+func (a *AggregateFlows) Add(b *CorridorBucket) {
+	if !InPeriod(a.From, a.To, b.Date) {
+		return
+	}
+	corridor := b.Corridor
+	if a.GroupBy == FlowsByCurrency {
+		corridor = ""
+	}
+	key := corridor + "/" + b.CurrencyCode
+	flow, ok := a.flows[key]
+	if !ok {
+		flow = &Flow{Corridor: corridor, CurrencyCode: b.CurrencyCode}
+		a.flows[key] = flow
+		a.Flows = append(a.Flows, flow)
+		sort.Slice(a.Flows, func(i, j int) bool {
+			if a.Flows[i].Corridor != a.Flows[j].Corridor {
+				return a.Flows[i].Corridor < a.Flows[j].Corridor
+			}
+			return a.Flows[i].CurrencyCode < a.Flows[j].CurrencyCode
+		})
+	}
+	flow.Settled += b.Settled
+	flow.Failed += b.Failed
+	flow.Volume += b.Volume
+	flow.Fees += b.Fees
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"regexp"
+)
+
+// referencePattern matches the characters of the SWIFT character set allowed in references
+var referencePattern = regexp.MustCompile(`^[A-Za-z0-9/\-?:().,'+ ]+$`)
+
+// PurposeCodes lists the allowed transfer purpose codes, a subset of the
+// ISO 20022 external purpose code list
+var PurposeCodes = map[string]string{
+	"BONU": "Bonus payment",
+	"CHAR": "Charity payment",
+	"DIVD": "Dividend",
+	"EDUC": "Education",
+	"GDDS": "Purchase or sale of goods",
+	"GIFT": "Gift",
+	"INSU": "Insurance premium",
+	"INTC": "Intra-company payment",
+	"INTE": "Interest",
+	"LOAN": "Loan",
+	"MDCS": "Medical services",
+	"OTHR": "Other",
+	"PENS": "Pension payment",
+	"RENT": "Rent",
+	"SALA": "Salary payment",
+	"SCVE": "Purchase or sale of services",
+	"SUPP": "Supplier payment",
+	"TAXS": "Tax payment",
+	"TRAD": "Trade services",
+	"UBIL": "Utility bill",
+}
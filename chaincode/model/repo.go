@@ -0,0 +1,141 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RepoObjectType blockchain object type
+const RepoObjectType = "Repo"
+
+// RepoStatus stores allowed values for a repo agreement's status.
+// Allowed values are "open", "closed", "failed"
+type RepoStatus string
+
+const (
+	// RepoOpen cash and collateral have been exchanged
+	RepoOpen RepoStatus = "open"
+	// RepoClosed cash and collateral have been returned
+	RepoClosed RepoStatus = "closed"
+	// RepoFailed the borrower could not repurchase and the lender retains the collateral
+	RepoFailed RepoStatus = "failed"
+)
+
+// Repo is a repurchase agreement: the borrower sells asset-token collateral to
+// the lender for cash and buys it back on the repurchase date at an agreed rate
+type Repo struct {
+	Entity
+	ID                 string     `json:"id"`
+	BorrowerCustomerID string     `json:"borrower_customer"`
+	BorrowerAccountID  string     `json:"borrower_account"`
+	LenderCustomerID   string     `json:"lender_customer"`
+	LenderAccountID    string     `json:"lender_account"`
+	TokenID            string     `json:"token_id"`
+	Units              int64      `json:"units"` // collateral units
+	CashAmount         int64      `json:"cash_amount"`
+	CurrencyCode       string     `json:"currency"`
+	Rate               int64      `json:"rate"` // repo rate in basis points per annum
+	StartDate          string     `json:"start_date"`
+	RepurchaseDate     string     `json:"repurchase_date"` // YYYY-MM-DD
+	RepurchasePrice    int64      `json:"repurchase_price"`
+	Status             RepoStatus `json:"status"`
+	Closed             int64      `json:"closed,omitempty"` // unix timestamp
+}
+
+// CreateRepo Factory function creates a new Repo struct starting on the given date
+func CreateRepo(repoBytes []byte, startDate string, tx *TxContext) (*Repo, error) {
+	repo := new(Repo)
+	if err := json.Unmarshal(repoBytes, repo); err != nil {
+		return nil, err
+	}
+	repo.ObjectType = RepoObjectType
+	if repo.ID == "" {
+		repo.ID = tx.NewID(12)
+	}
+	repo.StartDate = startDate
+	if err := repo.Validate(); err != nil {
+		return nil, err
+	}
+	repo.RepurchasePrice = repo.CashAmount + repo.Interest()
+	repo.Status = RepoOpen
+	return repo, nil
+}
+
+// Validate - checks the repo parties, legs and term
+func (r *Repo) Validate() error {
+	if r.BorrowerCustomerID == "" || r.BorrowerAccountID == "" {
+		return errors.New("Missing required borrower_customer and / or borrower_account")
+	}
+	if r.LenderCustomerID == "" || r.LenderAccountID == "" {
+		return errors.New("Missing required lender_customer and / or lender_account")
+	}
+	if r.BorrowerCustomerID == r.LenderCustomerID {
+		return errors.New("Borrower and lender must differ")
+	}
+	if r.TokenID == "" || r.Units <= 0 {
+		return errors.New("Missing required token_id and / or units")
+	}
+	if r.CashAmount <= 0 {
+		return fmt.Errorf("Invalid cash amount %d", r.CashAmount)
+	}
+	if r.CurrencyCode == "" {
+		return errors.New("Missing required currency value")
+	}
+	if r.Rate < 0 {
+		return fmt.Errorf("Invalid repo rate %d", r.Rate)
+	}
+	if r.TermDays() <= 0 {
+		return fmt.Errorf("Repurchase date %s must be after start date %s", r.RepurchaseDate, r.StartDate)
+	}
+	return nil
+}
+
+// TermDays returns the number of days between the start and repurchase dates
+func (r *Repo) TermDays() int64 {
+	start, err := time.Parse(PayDateFormat, r.StartDate)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(PayDateFormat, r.RepurchaseDate)
+	if err != nil {
+		return 0
+	}
+	return int64(end.Sub(start).Hours() / 24)
+}
+
+// Interest returns the repo interest on an actual/365 basis
+func (r *Repo) Interest() int64 {
+	return r.CashAmount * r.Rate * r.TermDays() / (365 * 10000)
+}
+
+// OpeningLeg returns the DvP delivering collateral to the lender against cash
+func (r *Repo) OpeningLeg() *DvPInstruction {
+	return &DvPInstruction{
+		TokenID:             r.TokenID,
+		Units:               r.Units,
+		SellerCustomerID:    r.BorrowerCustomerID,
+		SellerAccountID:     r.BorrowerAccountID,
+		BuyerCustomerID:     r.LenderCustomerID,
+		BuyerAccountID:      r.LenderAccountID,
+		Price:               r.CashAmount,
+		CurrencyCode:        r.CurrencyCode,
+		SettlementReference: r.ID,
+	}
+}
+
+// ClosingLeg returns the DvP returning collateral to the borrower against the repurchase price
+func (r *Repo) ClosingLeg() *DvPInstruction {
+	return &DvPInstruction{
+		TokenID:             r.TokenID,
+		Units:               r.Units,
+		SellerCustomerID:    r.LenderCustomerID,
+		SellerAccountID:     r.LenderAccountID,
+		BuyerCustomerID:     r.BorrowerCustomerID,
+		BuyerAccountID:      r.BorrowerAccountID,
+		Price:               r.RepurchasePrice,
+		CurrencyCode:        r.CurrencyCode,
+		SettlementReference: r.ID,
+	}
+}
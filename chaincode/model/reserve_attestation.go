@@ -0,0 +1,140 @@
+package model
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// ReserveAttestationObjectType blockchain object type
+	ReserveAttestationObjectType = "ReserveAttestation"
+	// ReserveObjectType blockchain object type
+	ReserveObjectType = "Reserve"
+)
+
+// AttestationDateFormat is the layout of attestation as-of dates
+const AttestationDateFormat = "2006-01-02"
+
+// ReserveAttestation is an auditor's signed statement of the off-chain
+// reserves backing an emitted currency as of a date
+type ReserveAttestation struct {
+	Entity
+	CurrencyCode string `json:"currency"`
+	AsOf         string `json:"as_of"`         // YYYY-MM-DD
+	ReserveTotal int64  `json:"reserve_total"` // amount in cents
+	ReportHash   string `json:"report_hash"`   // hex encoded SHA-256 of the audit report
+	Signature    string `json:"signature"`     // base64 encoded auditor signature over SignedMessage
+	Auditor      string `json:"auditor"`
+	AuditorMSP   string `json:"auditor_msp"`
+	Published    int64  `json:"published"` // unix timestamp
+}
+
+// ReserveStatus exposes the latest attestation alongside the circulating supply
+type ReserveStatus struct {
+	CurrencyCode      string              `json:"currency"`
+	CirculatingSupply int64               `json:"circulating_supply"`
+	Attestation       *ReserveAttestation `json:"attestation"`
+	Coverage          float64             `json:"coverage"` // reserve total over circulating supply
+}
+
+// Reserve is the issuer's record of the off-chain reserves currently backing
+// an emitted currency. It never exceeds the total of the auditor attestation
+// it cites, and mints may not take the supply of the currency above it.
+type Reserve struct {
+	Entity
+	CurrencyCode    string `json:"currency"`
+	Amount          int64  `json:"amount"`            // amount in cents
+	AttestationAsOf string `json:"attestation_as_of"` // as-of date of the attestation proving the amount
+	ReportHash      string `json:"report_hash"`       // audit report of the attestation
+	UpdatedBy       string `json:"updated_by"`
+	Updated         int64  `json:"updated"` // unix timestamp
+	TxID            string `json:"tx_id"`
+}
+
+// ReserveRatio compares the recorded reserves of a currency with its emitted supply
+type ReserveRatio struct {
+	CurrencyCode string   `json:"currency"`
+	Supply       int64    `json:"supply"` // amount in cents, minted less burned
+	Reserve      *Reserve `json:"reserve"`
+	Ratio        float64  `json:"ratio"`    // reserve amount over supply
+	Headroom     int64    `json:"headroom"` // amount in cents that may still be minted
+}
+
+// CreateReserveAttestation Factory function creates a new ReserveAttestation struct and returns a pointer to it
+func CreateReserveAttestation(attestationBytes []byte, tx *TxContext) (*ReserveAttestation, error) {
+	a := new(ReserveAttestation)
+	if err := json.Unmarshal(attestationBytes, a); err != nil {
+		return nil, err
+	}
+	a.ObjectType = ReserveAttestationObjectType
+	if a.CurrencyCode == "" {
+		return nil, errors.New("Missing required currency value")
+	}
+	if _, err := time.Parse(AttestationDateFormat, a.AsOf); err != nil {
+		return nil, fmt.Errorf("Invalid as-of date %s", a.AsOf)
+	}
+	if a.ReserveTotal < 0 {
+		return nil, fmt.Errorf("Invalid reserve total %d", a.ReserveTotal)
+	}
+	if hash, err := hex.DecodeString(a.ReportHash); err != nil || len(hash) != 32 {
+		return nil, errors.New("Invalid report_hash, expected hex encoded SHA-256")
+	}
+	if a.Signature == "" {
+		return nil, errors.New("Missing required signature")
+	}
+	a.Published = tx.Time.Unix()
+	return a, nil
+}
+
+// SignedMessage returns the bytes the auditor signs
+func (a *ReserveAttestation) SignedMessage() []byte {
+	return []byte(fmt.Sprintf("%s|%s|%d|%s", a.CurrencyCode, a.AsOf, a.ReserveTotal, a.ReportHash))
+}
+
+// CreateReserve a factory function for a reserve record of the amount proven
+// by an attestation. The amount may be lower than the attested total, e.g.
+// after redemptions, but never higher.
+func CreateReserve(a *ReserveAttestation, amount int64, updatedBy string, tx *TxContext) (*Reserve, error) {
+	if amount < 0 {
+		return nil, fmt.Errorf("Invalid reserve amount %d", amount)
+	}
+	if amount > a.ReserveTotal {
+		return nil, fmt.Errorf("Reserve amount %d exceeds the %d attested as of %s", amount, a.ReserveTotal, a.AsOf)
+	}
+	return &Reserve{
+		Entity:          Entity{ReserveObjectType},
+		CurrencyCode:    a.CurrencyCode,
+		Amount:          amount,
+		AttestationAsOf: a.AsOf,
+		ReportHash:      a.ReportHash,
+		UpdatedBy:       updatedBy,
+		Updated:         tx.Time.Unix(),
+		TxID:            tx.ID,
+	}, nil
+}
+
+// Backs returns an error if a supply of the given total is not covered by the reserve
+func (r *Reserve) Backs(supply int64) error {
+	if supply > r.Amount {
+		return fmt.Errorf("%s supply of %d would exceed recorded reserves of %d", r.CurrencyCode, supply, r.Amount)
+	}
+	return nil
+}
+
+// NewReserveRatio compares a reserve, if any, with the supply of its currency
+func NewReserveRatio(currency string, supply int64, r *Reserve) *ReserveRatio {
+	ratio := &ReserveRatio{CurrencyCode: currency, Supply: supply, Reserve: r}
+	if r == nil {
+		return ratio
+	}
+	if supply > 0 {
+		ratio.Ratio = float64(r.Amount) / float64(supply)
+	}
+	if r.Amount > supply {
+		ratio.Headroom = r.Amount - supply
+	}
+	return ratio
+}
@@ -0,0 +1,56 @@
+package model
+
+// RoleGrantObjectType blockchain object type
+const RoleGrantObjectType = "RoleGrant"
+
+// RoleGrant lists the roles granted on the ledger to a client identity, in
+// addition to the role carried in its certificate
+type RoleGrant struct {
+	Entity
+	Identity  string   `json:"identity"`
+	Roles     []string `json:"roles"`
+	UpdatedBy string   `json:"updated_by"`
+	Updated   int64    `json:"updated"` // unix timestamp
+}
+
+// CreateRoleGrant a factory function for the empty grant of an identity
+func CreateRoleGrant(identity string) *RoleGrant {
+	return &RoleGrant{Entity: Entity{RoleGrantObjectType}, Identity: identity, Roles: []string{}}
+}
+
+// Has returns true if the role is granted
+func (g *RoleGrant) Has(role string) bool {
+	for _, r := range g.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Grant adds a role, returning false if it was already granted
+func (g *RoleGrant) Grant(role string, by string, tx *TxContext) bool {
+	if g.Has(role) {
+		return false
+	}
+	g.Roles = append(g.Roles, role)
+	g.touch(by, tx)
+	return true
+}
+
+// Revoke removes a role, returning false if it was not granted
+func (g *RoleGrant) Revoke(role string, by string, tx *TxContext) bool {
+	for i, r := range g.Roles {
+		if r == role {
+			g.Roles = append(g.Roles[:i], g.Roles[i+1:]...)
+			g.touch(by, tx)
+			return true
+		}
+	}
+	return false
+}
+
+func (g *RoleGrant) touch(by string, tx *TxContext) {
+	g.UpdatedBy = by
+	g.Updated = tx.Time.Unix()
+}
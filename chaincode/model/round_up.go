@@ -0,0 +1,89 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// RoundUpRuleObjectType blockchain object type
+	RoundUpRuleObjectType = "RoundUpRule"
+	// RoundUpTotalObjectType blockchain object type
+	RoundUpTotalObjectType = "RoundUpTotal"
+)
+
+// RoundUpMonthFormat is the layout of the month round-up totals are kept by
+const RoundUpMonthFormat = "2006-01"
+
+// RoundUpRule rounds outgoing transfers of an account up to a whole unit and
+// donates the difference to a charity account
+type RoundUpRule struct {
+	Entity
+	CustomerID        string `json:"customer_id"`
+	AccountID         string `json:"account_id"`
+	CharityCustomerID string `json:"charity_customer"`
+	CharityAccountID  string `json:"charity_account"`
+	Unit              int64  `json:"unit"` // rounding unit in cents, e.g. 100
+	Created           int64  `json:"created"`
+}
+
+// RoundUpTotal holds the round-ups donated by an account in a month
+type RoundUpTotal struct {
+	Entity
+	CustomerID        string `json:"customer_id"`
+	AccountID         string `json:"account_id"`
+	Month             string `json:"month"` // YYYY-MM
+	CharityCustomerID string `json:"charity_customer"`
+	CharityAccountID  string `json:"charity_account"`
+	Total             int64  `json:"total"` // amount in cents
+	Count             int    `json:"count"`
+}
+
+// RoundUpTotalList holds the monthly round-up totals of an account
+type RoundUpTotalList struct {
+	Totals []*RoundUpTotal `json:"totals"`
+}
+
+// CreateRoundUpRule Factory function creates a new RoundUpRule struct and returns a pointer to it
+func CreateRoundUpRule(ruleBytes []byte, tx *TxContext) (*RoundUpRule, error) {
+	rule := new(RoundUpRule)
+	if err := json.Unmarshal(ruleBytes, rule); err != nil {
+		return nil, err
+	}
+	rule.ObjectType = RoundUpRuleObjectType
+	if rule.CustomerID == "" || rule.AccountID == "" {
+		return nil, errors.New("Missing required customer_id and / or account_id")
+	}
+	if rule.CharityCustomerID == "" || rule.CharityAccountID == "" {
+		return nil, errors.New("Missing required charity_customer and / or charity_account")
+	}
+	if rule.CharityCustomerID == rule.CustomerID && rule.CharityAccountID == rule.AccountID {
+		return nil, errors.New("Charity account must differ from the rounded account")
+	}
+	if rule.Unit == 0 {
+		rule.Unit = 100
+	}
+	if rule.Unit < 0 {
+		return nil, fmt.Errorf("Invalid rounding unit %d", rule.Unit)
+	}
+	rule.Created = tx.Time.Unix()
+	return rule, nil
+}
+
+// RoundUp returns the difference between amount and the next whole unit
+func (r *RoundUpRule) RoundUp(amount int64) int64 {
+	return (r.Unit - amount%r.Unit) % r.Unit
+}
+
+// CreateRoundUpTotal Factory function creates an empty RoundUpTotal for a rule and month
+func CreateRoundUpTotal(rule *RoundUpRule, month string) *RoundUpTotal {
+	return &RoundUpTotal{
+		Entity:            Entity{RoundUpTotalObjectType},
+		CustomerID:        rule.CustomerID,
+		AccountID:         rule.AccountID,
+		Month:             month,
+		CharityCustomerID: rule.CharityCustomerID,
+		CharityAccountID:  rule.CharityAccountID,
+	}
+}
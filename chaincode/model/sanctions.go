@@ -0,0 +1,138 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// BlockedPartyObjectType blockchain object type
+	BlockedPartyObjectType = "BlockedParty"
+	// ComplianceAlertObjectType blockchain object type
+	ComplianceAlertObjectType = "ComplianceAlert"
+)
+
+// BlockedPartyKind stores allowed values for what a blocklist entry matches.
+// Allowed values are "customer", "account", "country"
+type BlockedPartyKind string
+
+const (
+	// BlockedCustomer entry matches every account of a customer
+	BlockedCustomer BlockedPartyKind = "customer"
+	// BlockedAccount entry matches a single account
+	BlockedAccount BlockedPartyKind = "account"
+	// BlockedCountry entry matches every account domiciled in a country
+	BlockedCountry BlockedPartyKind = "country"
+)
+
+// ComplianceAlertStatus stores allowed values for a compliance alert's status.
+// Allowed values are "open"
+type ComplianceAlertStatus string
+
+const (
+	// AlertOpen alert awaits investigation
+	AlertOpen ComplianceAlertStatus = "open"
+)
+
+// BlockedParty is a sanctions or internal blocklist entry. Transfers from or to
+// a matching party fail with the sanctions_hit code.
+type BlockedParty struct {
+	Entity
+	Kind       BlockedPartyKind `json:"kind"`
+	CustomerID string           `json:"customer_id,omitempty"`
+	AccountID  string           `json:"account_id,omitempty"`
+	Country    string           `json:"country,omitempty"`
+	List       string           `json:"list"` // source list, e.g. the sanctions program
+	Reason     string           `json:"reason,omitempty"`
+	AddedBy    string           `json:"added_by"`
+	Added      int64            `json:"added"` // unix timestamp
+}
+
+// ComplianceAlert records a transfer stopped by sanctions screening for investigators
+type ComplianceAlert struct {
+	Entity
+	ID         string                `json:"id"`
+	Code       TxFailureCode         `json:"code"`
+	CustomerID string                `json:"customer_id"` // payer
+	AccountID  string                `json:"account_id"`
+	Transfer   *Transfer             `json:"transfer"`
+	Matches    []*BlockedParty       `json:"matches"`
+	Status     ComplianceAlertStatus `json:"status"`
+	Created    int64                 `json:"created"` // unix timestamp
+}
+
+// ComplianceAlertList holds a list of compliance alerts
+type ComplianceAlertList struct {
+	Alerts       []*ComplianceAlert `json:"alerts"`
+	NextBookmark string             `json:"next_bookmark,omitempty"` // empty on the last page
+	Truncated    bool               `json:"truncated,omitempty"`     // the page ended early at the response size limit
+}
+
+// BlockedCheck reports whether a party is blocked, with the matching entry
+type BlockedCheck struct {
+	Blocked bool          `json:"blocked"`
+	Party   *BlockedParty `json:"party,omitempty"`
+}
+
+// CreateBlockedParty Factory function creates a new BlockedParty struct and returns a pointer to it
+func CreateBlockedParty(partyBytes []byte, addedBy string, tx *TxContext) (*BlockedParty, error) {
+	party := new(BlockedParty)
+	if err := json.Unmarshal(partyBytes, party); err != nil {
+		return nil, err
+	}
+	party.ObjectType = BlockedPartyObjectType
+	if _, err := BlockedPartyKeys(party.Kind, party.CustomerID, party.AccountID, party.Country); err != nil {
+		return nil, err
+	}
+	if party.List == "" {
+		return nil, errors.New("Missing required list value")
+	}
+	party.AddedBy = addedBy
+	party.Added = tx.Time.Unix()
+	return party, nil
+}
+
+// BlockedPartyKeys returns the key attributes of a blocklist entry of the kind,
+// requiring the identifiers the kind matches on
+func BlockedPartyKeys(kind BlockedPartyKind, customerID string, accountID string, country string) ([]string, error) {
+	switch kind {
+	case BlockedCustomer:
+		if customerID == "" {
+			return nil, errors.New("Missing required customer_id value")
+		}
+		return []string{string(kind), customerID}, nil
+	case BlockedAccount:
+		if customerID == "" || accountID == "" {
+			return nil, errors.New("Missing required customer_id and / or account_id value")
+		}
+		return []string{string(kind), customerID, accountID}, nil
+	case BlockedCountry:
+		if country == "" {
+			return nil, errors.New("Missing required country value")
+		}
+		return []string{string(kind), country}, nil
+	}
+	return nil, fmt.Errorf("Invalid blocked party kind %s", kind)
+}
+
+// Keys returns the key attributes of the blocklist entry
+func (p *BlockedParty) Keys() []string {
+	keys, _ := BlockedPartyKeys(p.Kind, p.CustomerID, p.AccountID, p.Country)
+	return keys
+}
+
+// CreateComplianceAlert a factory function for an open alert on a transfer
+func CreateComplianceAlert(id string, code TxFailureCode, t *Transfer, matches []*BlockedParty, tx *TxContext) *ComplianceAlert {
+	return &ComplianceAlert{
+		Entity:     Entity{ComplianceAlertObjectType},
+		ID:         id,
+		Code:       code,
+		CustomerID: t.FromCustomerID,
+		AccountID:  t.FromAccountID,
+		Transfer:   t,
+		Matches:    matches,
+		Status:     AlertOpen,
+		Created:    tx.Time.Unix(),
+	}
+}
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StandingOrderObjectType blockchain object type
+const StandingOrderObjectType = "StandingOrder"
+
+// StandingOrderFrequency stores allowed values for how often a standing order pays.
+// Allowed values are "daily", "weekly", "monthly"
+type StandingOrderFrequency string
+
+// StandingOrderStatus stores allowed values for a standing order's status.
+// Allowed values are "active", "cancelled", "completed"
+type StandingOrderStatus string
+
+const (
+	// StandingOrderDaily pays every calendar day
+	StandingOrderDaily StandingOrderFrequency = "daily"
+	// StandingOrderWeekly pays every seven days
+	StandingOrderWeekly StandingOrderFrequency = "weekly"
+	// StandingOrderMonthly pays on the start day of every month, or the last day of shorter months
+	StandingOrderMonthly StandingOrderFrequency = "monthly"
+	// StandingOrderActive order pays on its run dates
+	StandingOrderActive StandingOrderStatus = "active"
+	// StandingOrderCancelled order was withdrawn by the customer
+	StandingOrderCancelled StandingOrderStatus = "cancelled"
+	// StandingOrderCompleted order has passed its end date
+	StandingOrderCompleted StandingOrderStatus = "completed"
+)
+
+// StandingOrder is a recurring transfer from an account, paid on every run
+// date from the start date until the optional end date
+type StandingOrder struct {
+	Entity
+	ID        string                 `json:"id"`
+	Transfer  Transfer               `json:"transfer"`
+	Frequency StandingOrderFrequency `json:"frequency"`
+	StartDate string                 `json:"start_date"`         // YYYY-MM-DD
+	EndDate   string                 `json:"end_date,omitempty"` // YYYY-MM-DD, open-ended if empty
+	Period    int                    `json:"period"`             // index of the next run date
+	NextRun   string                 `json:"next_run"`           // YYYY-MM-DD
+	Runs      int                    `json:"runs"`               // payments made
+	Missed    int                    `json:"missed"`             // run dates skipped as they passed unexecuted
+	LastError string                 `json:"last_error,omitempty"`
+	Status    StandingOrderStatus    `json:"status"`
+	Created   int64                  `json:"created"` // unix timestamp
+}
+
+// StandingOrderList holds a list of standing orders
+type StandingOrderList struct {
+	Orders []*StandingOrder `json:"orders"`
+}
+
+// StandingOrderResult records a single payment attempted by ExecuteDueStandingOrders
+type StandingOrderResult struct {
+	CustomerID string `json:"customer_id"`
+	AccountID  string `json:"account_id"`
+	OrderID    string `json:"order_id"`
+	RunDate    string `json:"run_date"`
+	Amount     int64  `json:"amount"`
+	Error      string `json:"error,omitempty"`
+}
+
+// StandingOrderReport lists the payments attempted by an ExecuteDueStandingOrders invocation
+type StandingOrderReport struct {
+	Date    string                 `json:"date"`
+	Results []*StandingOrderResult `json:"results"`
+}
+
+// CreateStandingOrder Factory function creates a new StandingOrder struct and returns a pointer to it
+func CreateStandingOrder(orderBytes []byte, tx *TxContext) (*StandingOrder, error) {
+	order := new(StandingOrder)
+	if err := json.Unmarshal(orderBytes, order); err != nil {
+		return nil, err
+	}
+	order.ObjectType = StandingOrderObjectType
+	if order.ID == "" {
+		return nil, errors.New("Missing required id value")
+	}
+	if err := order.Transfer.Validate(); err != nil {
+		return nil, err
+	}
+	switch order.Frequency {
+	case StandingOrderDaily, StandingOrderWeekly, StandingOrderMonthly:
+	default:
+		return nil, fmt.Errorf("Invalid standing order frequency %s", order.Frequency)
+	}
+	if _, err := time.Parse(PayDateFormat, order.StartDate); err != nil {
+		return nil, fmt.Errorf("Invalid start date %s", order.StartDate)
+	}
+	if order.EndDate != "" {
+		if _, err := time.Parse(PayDateFormat, order.EndDate); err != nil {
+			return nil, fmt.Errorf("Invalid end date %s", order.EndDate)
+		}
+		if order.EndDate < order.StartDate {
+			return nil, fmt.Errorf("End date %s is before start date %s", order.EndDate, order.StartDate)
+		}
+	}
+	order.Period = 0
+	order.NextRun = order.StartDate
+	order.Runs = 0
+	order.Missed = 0
+	order.LastError = ""
+	order.Status = StandingOrderActive
+	order.Created = tx.Time.Unix()
+	return order, nil
+}
+
+// RunDate returns the n-th run date of the order, counting from zero
+func (o *StandingOrder) RunDate(n int) string {
+	start, _ := time.Parse(PayDateFormat, o.StartDate)
+	switch o.Frequency {
+	case StandingOrderWeekly:
+		return start.AddDate(0, 0, 7*n).Format(PayDateFormat)
+	case StandingOrderMonthly:
+		// clamp to the last day of shorter months rather than rolling over
+		first := time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+		day := start.Day()
+		if last := first.AddDate(0, 1, -1).Day(); day > last {
+			day = last
+		}
+		return first.AddDate(0, 0, day-1).Format(PayDateFormat)
+	default:
+		return start.AddDate(0, 0, n).Format(PayDateFormat)
+	}
+}
+
+// Due returns true if the order is active and its next run date has been reached
+func (o *StandingOrder) Due(today string) bool {
+	return o.Status == StandingOrderActive && o.NextRun <= today
+}
+
+// Advance moves the order past today to its next run date, counting run dates
+// skipped along the way, and completes the order once past its end date
+func (o *StandingOrder) Advance(today string) {
+	o.Period++
+	for o.RunDate(o.Period) <= today {
+		o.Period++
+		o.Missed++
+	}
+	o.NextRun = o.RunDate(o.Period)
+	if o.EndDate != "" && o.NextRun > o.EndDate {
+		o.Status = StandingOrderCompleted
+	}
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// StatementDateFormat is the layout of statement period dates
+const StatementDateFormat = "2006-01-02"
+
+// FinalStatementObjectType blockchain object type
+const FinalStatementObjectType = "FinalStatement"
+
+// StatementLine is a transaction on a statement with the account balance after it
+type StatementLine struct {
+	Transaction *Transaction `json:"transaction"`
+	Change      int64        `json:"change"`  // signed change of the balance in cents
+	Balance     int64        `json:"balance"` // running balance in cents
+}
+
+// Statement lists the transactions of an account in a period, oldest first,
+// between the balances at its start and end
+type Statement struct {
+	CustomerID     string           `json:"customer_id"`
+	AccountID      string           `json:"account_id"`
+	CurrencyCode   string           `json:"currency"`
+	From           string           `json:"from"` // YYYY-MM-DD, inclusive
+	To             string           `json:"to"`   // YYYY-MM-DD, inclusive
+	OpeningBalance int64            `json:"opening_balance"`
+	ClosingBalance int64            `json:"closing_balance"`
+	Lines          []*StatementLine `json:"lines"`
+}
+
+// FinalStatement is the statement of a closed account from the day it was
+// opened to the day it was closed, generated and kept on the ledger at
+// closure. The closing balance is the balance before any closure sweep.
+type FinalStatement struct {
+	Entity
+	Statement
+	SweptAmount     int64  `json:"swept_amount,omitempty"` // closing balance moved out at closure, in cents
+	SweptCustomerID string `json:"swept_to_customer,omitempty"`
+	SweptAccountID  string `json:"swept_to_account,omitempty"`
+	ClosedBy        string `json:"closed_by"`
+	Closed          int64  `json:"closed"` // unix timestamp
+}
+
+// ParseStatementPeriod parses the first and last day of a statement period
+// into the UTC times the period starts and ends, the end exclusive
+func ParseStatementPeriod(from string, to string) (time.Time, time.Time, error) {
+	start, err := time.Parse(StatementDateFormat, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("Invalid statement start date %s, expected YYYY-MM-DD", from)
+	}
+	last, err := time.Parse(StatementDateFormat, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("Invalid statement end date %s, expected YYYY-MM-DD", to)
+	}
+	if last.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("Statement end date %s is before start date %s", to, from)
+	}
+	return start, last.AddDate(0, 0, 1), nil
+}
+
+// CreateStatement builds the statement of the account for the period from its
+// transactions. The opening balance is worked back from the current balance
+// through the changes of every transaction since the period start, so
+// transactions archived before the period do not affect it.
+func CreateStatement(a *Account, txns []*Transaction, start time.Time, end time.Time) *Statement {
+	return CreateStatementAt(a, txns, start, end, a.Balance, math.MaxInt64)
+}
+
+// CreateStatementAt builds the statement of the account for the period from
+// its transactions and its balance at a unix time, e.g. that of a balance
+// snapshot, reflecting the transactions created before that time. The closing
+// balance is worked forward or back from that balance through the
+// transactions between the time and the period end, and the opening balance
+// back from the closing balance through the transactions of the period.
+func CreateStatementAt(a *Account, txns []*Transaction, start time.Time, end time.Time, balance int64, at int64) *Statement {
+	s := &Statement{
+		CustomerID:   a.CustomerID,
+		AccountID:    a.ID,
+		CurrencyCode: a.CurrencyCode,
+		From:         start.Format(StatementDateFormat),
+		To:           end.AddDate(0, 0, -1).Format(StatementDateFormat),
+		Lines:        []*StatementLine{},
+	}
+	sort.Stable(ByCreated(txns))
+	s.ClosingBalance = balance
+	for _, txn := range txns {
+		if txn.Created >= end.Unix() && txn.Created < at {
+			s.ClosingBalance -= txn.BalanceChange()
+		} else if txn.Created >= at && txn.Created < end.Unix() {
+			s.ClosingBalance += txn.BalanceChange()
+		}
+	}
+	s.OpeningBalance = s.ClosingBalance
+	for _, txn := range txns {
+		if txn.Created >= start.Unix() && txn.Created < end.Unix() {
+			s.OpeningBalance -= txn.BalanceChange()
+		}
+	}
+	running := s.OpeningBalance
+	for _, txn := range txns {
+		if txn.Created < start.Unix() || txn.Created >= end.Unix() {
+			continue
+		}
+		change := txn.BalanceChange()
+		running += change
+		s.Lines = append(s.Lines, &StatementLine{Transaction: txn, Change: change, Balance: running})
+	}
+	return s
+}
+
+// BalanceChange returns the signed amount the transaction moved the balance
+// of its account by: a debit takes the amount and fee, a credit adds the
+// amount the account received after any withholding and conversion.
+func (t *Transaction) BalanceChange() int64 {
+	switch t.Status {
+	case Debited:
+		return -(t.Amount + t.Fee)
+	case Credited:
+		if w := t.Withholding; w != nil && w.TaxAuthorityCustomerID == t.CustomerID && w.TaxAuthorityAccountID == t.AccountID {
+			return w.TaxAmount
+		}
+		if t.Conversion != nil {
+			return t.Conversion.ConvertedAmount
+		}
+		if t.Withholding != nil {
+			return t.Withholding.NetAmount
+		}
+		return t.Amount
+	}
+	return 0
+}
+
+// CreateFinalStatement builds the final statement of an account closed in
+// this transaction from its transactions
+func CreateFinalStatement(a *Account, txns []*Transaction, closedBy string, tx *TxContext) *FinalStatement {
+	opened := time.Unix(a.Created, 0).UTC()
+	start := time.Date(opened.Year(), opened.Month(), opened.Day(), 0, 0, 0, 0, time.UTC)
+	closed := tx.Time.UTC()
+	end := time.Date(closed.Year(), closed.Month(), closed.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	return &FinalStatement{
+		Entity:    Entity{FinalStatementObjectType},
+		Statement: *CreateStatement(a, txns, start, end),
+		ClosedBy:  closedBy,
+		Closed:    tx.Time.Unix(),
+	}
+}
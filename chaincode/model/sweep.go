@@ -0,0 +1,106 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SweepRuleObjectType blockchain object type
+const SweepRuleObjectType = "SweepRule"
+
+// SweepSchedule stores allowed values for how often a sweep rule runs.
+// Allowed values are "daily", "weekly", "monthly"
+type SweepSchedule string
+
+const (
+	// SweepDaily runs once per calendar day
+	SweepDaily SweepSchedule = "daily"
+	// SweepWeekly runs once every seven days
+	SweepWeekly SweepSchedule = "weekly"
+	// SweepMonthly runs once per calendar month
+	SweepMonthly SweepSchedule = "monthly"
+)
+
+// SweepRule keeps an account at a target balance by moving the excess to, or
+// funding the deficit from, a concentration account on a schedule
+type SweepRule struct {
+	Entity
+	CustomerID              string        `json:"customer_id"`
+	AccountID               string        `json:"account_id"`
+	ConcentrationCustomerID string        `json:"concentration_customer"`
+	ConcentrationAccountID  string        `json:"concentration_account"`
+	TargetBalance           int64         `json:"target_balance"` // amount in cents
+	Schedule                SweepSchedule `json:"schedule"`
+	LastRun                 string        `json:"last_run,omitempty"` // YYYY-MM-DD
+	Created                 int64         `json:"created"`            // unix timestamp
+}
+
+// SweepResult records a single sweep made by RunSweeps
+type SweepResult struct {
+	CustomerID string `json:"customer_id"`
+	AccountID  string `json:"account_id"`
+	Amount     int64  `json:"amount"` // positive when swept to the concentration account, negative when funded from it
+	Error      string `json:"error,omitempty"`
+}
+
+// SweepReport lists the sweeps made by a RunSweeps invocation
+type SweepReport struct {
+	Date   string         `json:"date"`
+	Sweeps []*SweepResult `json:"sweeps"`
+}
+
+// CreateSweepRule Factory function creates a new SweepRule struct and returns a pointer to it
+func CreateSweepRule(ruleBytes []byte, tx *TxContext) (*SweepRule, error) {
+	rule := new(SweepRule)
+	if err := json.Unmarshal(ruleBytes, rule); err != nil {
+		return nil, err
+	}
+	rule.ObjectType = SweepRuleObjectType
+	if rule.CustomerID == "" || rule.AccountID == "" {
+		return nil, errors.New("Missing required customer_id and / or account_id")
+	}
+	if rule.ConcentrationCustomerID == "" || rule.ConcentrationAccountID == "" {
+		return nil, errors.New("Missing required concentration_customer and / or concentration_account")
+	}
+	if rule.CustomerID == rule.ConcentrationCustomerID && rule.AccountID == rule.ConcentrationAccountID {
+		return nil, errors.New("Concentration account must differ from the swept account")
+	}
+	if rule.TargetBalance < 0 {
+		return nil, fmt.Errorf("Invalid target balance %d", rule.TargetBalance)
+	}
+	switch rule.Schedule {
+	case SweepDaily, SweepWeekly, SweepMonthly:
+	default:
+		return nil, fmt.Errorf("Invalid sweep schedule %s", rule.Schedule)
+	}
+	rule.LastRun = ""
+	rule.Created = tx.Time.Unix()
+	return rule, nil
+}
+
+// Due returns true if the rule has not yet run in the current schedule period
+func (r *SweepRule) Due(now time.Time) bool {
+	if r.LastRun == "" {
+		return true
+	}
+	last, err := time.Parse(PayDateFormat, r.LastRun)
+	if err != nil {
+		return true
+	}
+	today := now.UTC()
+	switch r.Schedule {
+	case SweepWeekly:
+		return today.Sub(last) >= 7*24*time.Hour
+	case SweepMonthly:
+		return today.Year() != last.Year() || today.Month() != last.Month()
+	default:
+		return today.Format(PayDateFormat) != r.LastRun
+	}
+}
+
+// Excess returns the amount above the target balance, negative for a deficit
+func (r *SweepRule) Excess(balance int64) int64 {
+	return balance - r.TargetBalance
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	// TenantObjectType blockchain object type
+	TenantObjectType = "Tenant"
+	// TenancyConfigObjectType blockchain object type
+	TenancyConfigObjectType = "TenancyConfig"
+)
+
+var tenantPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,63}$`)
+
+// TenancyMode stores allowed values for the deployment's tenancy mode.
+// Allowed values are "single", "multi"
+type TenancyMode string
+
+const (
+	// SingleTenant all callers share one key space
+	SingleTenant TenancyMode = "single"
+	// MultiTenant keys are namespaced per tenant bank
+	MultiTenant TenancyMode = "multi"
+)
+
+// TenancyConfig holds the deployment's tenancy mode
+type TenancyConfig struct {
+	Entity
+	Mode TenancyMode `json:"mode"`
+}
+
+// Tenant assigns the callers of an MSP to a tenant namespace
+type Tenant struct {
+	Entity
+	MSPID    string `json:"msp_id"`
+	TenantID string `json:"tenant_id"`
+	Assigned string `json:"assigned_by"`
+}
+
+// SharedObjectTypes are consortium-level objects kept outside tenant namespaces
+var SharedObjectTypes = map[string]bool{
+	TenantObjectType:              true,
+	TenancyConfigObjectType:       true,
+	ConfigObjectType:              true,
+	BankObjectType:                true,
+	TreasuryObjectType:            true,
+	LiquidityPoolObjectType:       true,
+	CollateralObjectType:          true,
+	ExposureObjectType:            true,
+	InterbankPositionObjectType:   true,
+	InterbankObligationObjectType: true,
+	SettlementBatchObjectType:     true,
+	BenchmarkRateObjectType:       true,
+	RatesObjectType:               true,
+	ExchangeRateObjectType:        true,
+	RateAttestationObjectType:     true,
+	RateConfigObjectType:          true,
+	ReserveAttestationObjectType:  true,
+	ReserveObjectType:             true,
+	WithholdingRuleObjectType:     true,
+	CorridorBucketObjectType:      true,
+	ReportingThresholdObjectType:  true,
+	RegulatoryReportObjectType:    true,
+	AuditEntryObjectType:          true,
+	SupplyObjectType:              true,
+	EmissionRecordObjectType:      true,
+	EmissionPolicyObjectType:      true,
+	EmissionProposalObjectType:    true,
+	CirculationObjectType:         true,
+	RoleGrantObjectType:           true,
+	HolidayObjectType:             true,
+}
+
+// ValidateTenantID checks a tenant ID can be used as a key namespace
+func ValidateTenantID(tenantID string) error {
+	if tenantID == "" {
+		return errors.New("Missing required tenant ID")
+	}
+	if !tenantPattern.MatchString(tenantID) {
+		return fmt.Errorf("Invalid tenant ID %s", tenantID)
+	}
+	return nil
+}
+
+// CreateTenant Factory function creates a new Tenant assignment
+func CreateTenant(mspID string, tenantID string, assignedBy string) (*Tenant, error) {
+	if mspID == "" {
+		return nil, errors.New("Missing required MSP ID")
+	}
+	if err := ValidateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	return &Tenant{Entity: Entity{TenantObjectType}, MSPID: mspID, TenantID: tenantID, Assigned: assignedBy}, nil
+}
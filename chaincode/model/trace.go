@@ -0,0 +1,25 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TraceparentField is the JSON field carrying the W3C trace context of an
+// invocation in its response, events and audit entry
+const TraceparentField = "traceparent"
+
+// traceparentPattern matches a version 00 W3C trace context traceparent:
+// version, trace ID, parent ID and trace flags in lowercase hex
+var traceparentPattern = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// IsTraceparent reports whether the value is a valid W3C traceparent, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01. Version ff and
+// all-zero trace and parent IDs are invalid.
+func IsTraceparent(value string) bool {
+	if !traceparentPattern.MatchString(value) {
+		return false
+	}
+	parts := strings.Split(value, "-")
+	return parts[0] != "ff" && strings.Trim(parts[1], "0") != "" && strings.Trim(parts[2], "0") != ""
+}
@@ -7,9 +7,21 @@ import (
 	"time"
 )
 
-// TransactionObjectType blockchain object type
+// TransactionObjectType blockchain object type. Transactions are keyed by
+// customer ID, account ID, creation day (YYYYMMDD, see DayBucket) and
+// transaction ID, so the transactions of an account in a date range are a
+// range of keys.
 const TransactionObjectType = "Transaction"
 
+// TransactionIDObjectType blockchain object type of the entries locating a
+// transaction by customer ID, account ID and transaction ID, whose value is
+// the transaction key
+const TransactionIDObjectType = "TransactionID"
+
+// TransactionReferenceObjectType blockchain object type of the index entries
+// mapping an end-to-end reference to the transactions carrying it
+const TransactionReferenceObjectType = "TransactionReference"
+
 // TxDetails struct stores details of a transaction
 type TxDetails struct {
 	CustomerID   string            `json:"customer_id"`
@@ -18,14 +30,46 @@ type TxDetails struct {
 	Fee          int64             `json:"fee"`
 	CurrencyCode string            `json:"currency"`
 	Created      int64             `json:"created"` // unix time
+	TxID         string            `json:"tx_id"`   // ledger transaction that wrote the record
 	Description  string            `json:"description"`
+	PurposeCode  string            `json:"purpose_code,omitempty"`
+	InvoiceRef   string            `json:"invoice_ref,omitempty"`
+	EndToEndID   string            `json:"end_to_end_id,omitempty"`
+	Memo         string            `json:"memo,omitempty"`
+	Category     string            `json:"category,omitempty"`
 	Params       map[string]string `json:"params,omitempty"`
+	// Counterparty of the transfer: the payee on a debit, the payer on a credit
+	CounterpartyCustomerID string `json:"counterparty_customer,omitempty"`
+	CounterpartyAccountID  string `json:"counterparty_account,omitempty"`
+	// Withholding certificate of tax withheld from a cross-border transfer
+	Withholding *WithholdingCertificate `json:"withholding,omitempty"`
+	// Conversion of the credited amount of a cross-currency transfer
+	Conversion *FXConversion `json:"conversion,omitempty"`
+	// Overdraft flags a debit that took the account balance below zero
+	Overdraft bool `json:"overdraft,omitempty"`
+	// Type of the transaction, empty for transfers
+	Type TxType `json:"type,omitempty"`
+	// ValueDate is the business day the transfer takes value, YYYY-MM-DD
+	ValueDate string `json:"value_date,omitempty"`
 }
 
 // TxFailureCode stores allowed values for transaction failures
-// Allowed values are "insufficient_funds", "account_closed"
+// Allowed values are "insufficient_funds", "account_closed", "account_inactive", "exposure_limit_exceeded", "budget_exceeded", "limit_exceeded", "sanctions_hit", "invalid_amount", "concurrent_modification", "beneficiary_not_allowed", "quote_expired"
 type TxFailureCode string
 
+// TxType stores allowed values for a transaction's type.
+// Allowed values are "" for transfers, "interest", "chargeback", "opening_balance"
+type TxType string
+
+const (
+	// TxTypeInterest transaction type of interest credited to an account
+	TxTypeInterest TxType = "interest"
+	// TxTypeChargeback transaction type of a disputed transfer reversed to the payer
+	TxTypeChargeback TxType = "chargeback"
+	// TxTypeOpeningBalance transaction type of the balance an account was migrated with
+	TxTypeOpeningBalance TxType = "opening_balance"
+)
+
 // TxStatus stores allowed values for a transaction's status.
 // Allowed values are "debited", "credited", "failed"
 type TxStatus string
@@ -35,8 +79,26 @@ const (
 	TxFailureCodeNone TxFailureCode = ""
 	// InsufficientFunds transaction failure code
 	InsufficientFunds TxFailureCode = "insufficient_funds"
-	// AccountClosed transaction faiure code
-	AccountClosed TxFailureCode = "account_closed"
+	// ClosedAccount transaction failure code
+	ClosedAccount TxFailureCode = "account_closed"
+	// AccountInactive transaction failure code for frozen or dormant accounts
+	AccountInactive TxFailureCode = "account_inactive"
+	// ExposureLimitExceeded transaction failure code
+	ExposureLimitExceeded TxFailureCode = "exposure_limit_exceeded"
+	// BudgetExceeded transaction failure code
+	BudgetExceeded TxFailureCode = "budget_exceeded"
+	// LimitExceeded transaction failure code for transfers breaching AML limits
+	LimitExceeded TxFailureCode = "limit_exceeded"
+	// SanctionsHit transaction failure code for transfers stopped by sanctions screening
+	SanctionsHit TxFailureCode = "sanctions_hit"
+	// InvalidAmount failure code for zero, negative or excessive amounts
+	InvalidAmount TxFailureCode = "invalid_amount"
+	// ConcurrentlyModified failure code for writes asserting a stale record version
+	ConcurrentlyModified TxFailureCode = "concurrent_modification"
+	// BeneficiaryNotAllowed transaction failure code for transfers breaching a beneficiary policy
+	BeneficiaryNotAllowed TxFailureCode = "beneficiary_not_allowed"
+	// QuoteExpired transaction failure code for transfers referencing an expired or used quote
+	QuoteExpired TxFailureCode = "quote_expired"
 	// Debited transaction status
 	Debited TxStatus = "debited"
 	// Credited transaction status
@@ -45,7 +107,8 @@ const (
 	Failed TxStatus = "failed"
 )
 
-// Transaction data struct represents a money transfer (payer and payee sides)
+// Transaction data struct represents a money transfer (payer and payee sides).
+// Its amounts are whole minor units of CurrencyCode, see Money.
 type Transaction struct {
 	Entity
 	ID string `json:"id"`
@@ -87,32 +150,79 @@ func (t *Transaction) MarshalJSON() ([]byte, error) {
 }
 
 // CreateTransaction a factory function for creating new Transaction entities
-func CreateTransaction(customerID string, accountID string, t *Transfer, code TxFailureCode, status TxStatus) (*Transaction, error) {
+func CreateTransaction(customerID string, accountID string, t *Transfer, code TxFailureCode, status TxStatus, tx *TxContext) (*Transaction, error) {
 	txn := &Transaction{Entity: Entity{TransactionObjectType}, FailureCode: code, Status: status}
 	txn.TxDetails = TxDetails{
 		CustomerID:   customerID,
 		AccountID:    accountID,
-		Created:      time.Now().Unix(),
+		Created:      tx.Time.Unix(),
+		TxID:         tx.ID,
 		Amount:       t.Amount,
 		Fee:          t.Fee,
 		CurrencyCode: t.CurrencyCode,
 		Description:  t.Description,
+		PurposeCode:  t.PurposeCode,
+		InvoiceRef:   t.InvoiceRef,
+		EndToEndID:   t.EndToEndID,
+		Memo:         t.Memo,
+		Category:     t.Category,
 		Params:       t.Params,
+		Withholding:  t.Withholding,
+		Conversion:   t.Conversion,
+		Overdraft:    t.Overdraft && status == Debited,
+		Type:         t.Type,
+		ValueDate:    t.ValueDate,
+	}
+	if customerID == t.FromCustomerID && accountID == t.FromAccountID {
+		txn.CounterpartyCustomerID, txn.CounterpartyAccountID = t.ToCustomerID, t.ToAccountID
+	} else {
+		txn.CounterpartyCustomerID, txn.CounterpartyAccountID = t.FromCustomerID, t.FromAccountID
 	}
 	transferData, _ := json.Marshal(txn)
 	txn.ID = fmt.Sprintf("%x", newID(transferData))
 	return txn, nil
 }
 
+// Money returns the transaction amount
+func (t *Transaction) Money() Money {
+	return NewMoney(t.Amount, t.CurrencyCode)
+}
+
+// DayBucket returns the UTC day of a unix timestamp as YYYYMMDD, the day
+// component of transaction and index keys
+func DayBucket(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format("20060102")
+}
+
+// DayBucketOf returns the day bucket of a YYYY-MM-DD day, or the empty string
+// for an empty day
+func DayBucketOf(day string) (string, error) {
+	if day == "" {
+		return "", nil
+	}
+	t, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return "", fmt.Errorf("Invalid day %s, expected YYYY-MM-DD", day)
+	}
+	return t.Format("20060102"), nil
+}
+
 func newID(data []byte) []byte {
 	md5 := md5.New()
 	md5.Write(data)
 	return md5.Sum(nil)
 }
 
+// PrivateFields returns the transaction fields kept in the bank's private data collection
+func (t *Transaction) PrivateFields() []string {
+	return []string{"description", "purpose_code", "invoice_ref", "memo"}
+}
+
 // TransactionList stores a list of transactions
 type TransactionList struct {
 	Transactions []*Transaction `json:"transactions"`
+	NextBookmark string         `json:"next_bookmark,omitempty"` // empty on the last page
+	Truncated    bool           `json:"truncated,omitempty"`     // the page ended early at the response size limit
 }
 
 // ByCreated sorts a list of transaction by creation timestamp
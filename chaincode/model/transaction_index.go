@@ -0,0 +1,98 @@
+package model
+
+import "fmt"
+
+// TransactionIndexObjectType blockchain object type of the secondary index
+// entries of transactions, whose value is the transaction key
+const TransactionIndexObjectType = "TransactionIndex"
+
+// TransactionIndex names a secondary index of transactions. An index entry is
+// keyed by the index name, the attributes of the index, the creation day of
+// the transaction (YYYYMMDD) and the transaction's customer, account and ID,
+// so that transactions sharing the attributes are a range of keys ordered by
+// day.
+type TransactionIndex string
+
+const (
+	// IndexByCounterparty indexes transfers by counterparty customer,
+	// counterparty account and status; transactions without a counterparty,
+	// such as mints and burns, are not indexed
+	IndexByCounterparty TransactionIndex = "counterparty"
+	// IndexByStatus indexes transactions by status
+	IndexByStatus TransactionIndex = "status"
+	// IndexByCurrency indexes transactions by currency
+	IndexByCurrency TransactionIndex = "currency"
+	// IndexByDate indexes transactions by creation day only
+	IndexByDate TransactionIndex = "date"
+)
+
+// TransactionIndexes are the secondary indexes written with every transaction
+var TransactionIndexes = []TransactionIndex{IndexByCounterparty, IndexByStatus, IndexByCurrency, IndexByDate}
+
+// transactionIndexAttributes names the attributes of each index, in key order
+var transactionIndexAttributes = map[TransactionIndex][]string{
+	IndexByCounterparty: {"counterparty_customer", "counterparty_account", "status"},
+	IndexByStatus:       {"status"},
+	IndexByCurrency:     {"currency"},
+	IndexByDate:         {},
+}
+
+// IndexAttributes returns the attribute values of the transaction in the
+// index, and false if the index does not cover the transaction
+func (t *Transaction) IndexAttributes(index TransactionIndex) ([]string, bool) {
+	switch index {
+	case IndexByCounterparty:
+		if t.CounterpartyAccountID == "" {
+			return nil, false
+		}
+		return []string{t.CounterpartyCustomerID, t.CounterpartyAccountID, string(t.Status)}, true
+	case IndexByStatus:
+		return []string{string(t.Status)}, true
+	case IndexByCurrency:
+		return []string{t.CurrencyCode}, true
+	case IndexByDate:
+		return []string{}, true
+	}
+	return nil, false
+}
+
+// TransactionIndexQuery selects the transactions of an index range, e.g. the
+// failed transfers to an account in March:
+// {"index":"counterparty","values":["1234","1","failed"],"from":"2021-03-01","to":"2021-03-31"}
+type TransactionIndexQuery struct {
+	Index  TransactionIndex `json:"index"`
+	Values []string         `json:"values"`         // leading attribute values of the index, in key order
+	From   string           `json:"from,omitempty"` // first creation day, YYYY-MM-DD, inclusive
+	To     string           `json:"to,omitempty"`   // last creation day, YYYY-MM-DD, inclusive
+}
+
+// Validate checks the index exists, at most its attributes are given and a
+// day range only follows all of them, as only then are the days of the range
+// contiguous keys
+func (q *TransactionIndexQuery) Validate() error {
+	attributes, ok := transactionIndexAttributes[q.Index]
+	if !ok {
+		return fmt.Errorf("Unknown transaction index %q, expected one of %v", q.Index, TransactionIndexes)
+	}
+	if len(q.Values) > len(attributes) {
+		return fmt.Errorf("Index %s takes at most the values of %v", q.Index, attributes)
+	}
+	if (q.From != "" || q.To != "") && len(q.Values) != len(attributes) {
+		return fmt.Errorf("A day range of index %s requires the values of %v", q.Index, attributes)
+	}
+	for _, day := range []string{q.From, q.To} {
+		if _, err := DayBucketOf(day); err != nil {
+			return err
+		}
+	}
+	if q.From != "" && q.To != "" && q.From > q.To {
+		return fmt.Errorf("Day range %s to %s ends before it starts", q.From, q.To)
+	}
+	return nil
+}
+
+// TransactionIndexReport lists the outcome of a RebuildTransactionIndexes invocation
+type TransactionIndexReport struct {
+	Indexed      int    `json:"indexed"`                 // transactions whose index entries were written
+	NextBookmark string `json:"next_bookmark,omitempty"` // empty once every transaction has been indexed
+}
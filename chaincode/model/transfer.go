@@ -1,43 +1,98 @@
 package model
 
-import (
-	"errors"
-	"fmt"
-)
-
-// Transfer struct contains information about a money transfer
+// Transfer struct contains information about a money transfer. Amount and
+// Fee are whole minor units of CurrencyCode, see Money and Total.
 type Transfer struct {
-	FromCustomerID string            `json:"from_customer"`
-	FromAccountID  string            `json:"from_account"`
-	ToCustomerID   string            `json:"to_customer"`
-	ToAccountID    string            `json:"to_account"`
-	Amount         int64             `json:"amount"` // amount in cents
-	Fee            int64             `json:"fee"`
-	CurrencyCode   string            `json:"currency"`
-	Description    string            `json:"description"`
-	Params         map[string]string `json:"params,omitempty"`
+	FromCustomerID string            `json:"from_customer" validate:"required,max=64"`
+	FromAccountID  string            `json:"from_account" validate:"required,max=64"`
+	ToCustomerID   string            `json:"to_customer" validate:"required,max=64"`
+	ToAccountID    string            `json:"to_account" validate:"required,max=64"`
+	ToTenant       string            `json:"to_tenant,omitempty" validate:"max=64"` // payee tenant in multi-tenant mode, if not the payer's
+	Amount         int64             `json:"amount" validate:"amount"`              // amount in cents
+	Fee            int64             `json:"fee"`                                   // set server-side from the fee schedule, client values are ignored
+	CurrencyCode   string            `json:"currency" validate:"required,currency"`
+	Description    string            `json:"description" validate:"max=255"`
+	PurposeCode    string            `json:"purpose_code,omitempty" validate:"purpose"`           // one of PurposeCodes
+	InvoiceRef     string            `json:"invoice_ref,omitempty" validate:"max=35,reference"`   // invoice the transfer pays
+	EndToEndID     string            `json:"end_to_end_id,omitempty" validate:"max=35,reference"` // payer's reference, passed unchanged to the payee
+	Memo           string            `json:"memo,omitempty" validate:"max=140"`                   // unstructured remittance information
+	Category       string            `json:"category,omitempty" validate:"max=64"`                // spending category counted against budgets
+	Params         map[string]string `json:"params,omitempty" validate:"max=32"`
+	PayerVersion   int64             `json:"expected_version,omitempty" validate:"min=0"` // payer account version the client read, checked if set
+	Initiated      int64             `json:"initiated,omitempty"`                         // unix timestamp the transfer was submitted
+	QuoteID        string            `json:"quote_id,omitempty" validate:"max=64"`        // quote locking the fee and exchange rate, if any
+	// Withholding is computed server-side for cross-border transfers covered by a withholding rule
+	Withholding *WithholdingCertificate `json:"-"`
+	// Conversion is computed server-side when the payee account holds another currency
+	Conversion *FXConversion `json:"-"`
+	// Quote is set server-side from QuoteID when the transfer executes
+	Quote *Quote `json:"-"`
+	// ValueDate is set server-side when the transfer executes, see FirstValueDate
+	ValueDate string `json:"-"`
+	// Overdraft is set server-side when the debit takes the payer balance below zero
+	Overdraft bool `json:"-"`
+	// Type is set server-side for transfers that are not customer payments, e.g. interest
+	Type TxType `json:"-"`
+}
+
+// Money returns the transfer amount
+func (t *Transfer) Money() Money {
+	return NewMoney(t.Amount, t.CurrencyCode)
+}
+
+// Total returns the transfer amount plus the fee, as debited from the payer
+func (t *Transfer) Total() (Money, error) {
+	return t.Money().Add(NewMoney(t.Fee, t.CurrencyCode))
 }
 
-// Validate - checks that required are present in the transfer object
+// Validate - checks the transfer fields against their validate tags
 func (t *Transfer) Validate() error {
-	if t.FromCustomerID == "" {
-		return errors.New("Missing required from_customer value")
-	}
-	if t.FromAccountID == "" {
-		return errors.New("Missing required from_account value")
-	}
-	if t.ToCustomerID == "" {
-		return errors.New("Missing required to_customer value")
-	}
-	if t.ToAccountID == "" {
-		return errors.New("Missing required to_account value")
-	}
-	if t.Amount <= 0 {
-		return fmt.Errorf("Invalid transfer amount %d", t.Amount)
+	return ValidateFields(t)
+}
+
+// TransferResultStatus stores allowed values for the outcome of a transfer.
+// Allowed values are "completed", "failed"
+type TransferResultStatus string
+
+const (
+	// TransferCompleted transfer settled between the accounts
+	TransferCompleted TransferResultStatus = "completed"
+	// TransferFailed transfer was stopped and recorded as failed on the payer account
+	TransferFailed TransferResultStatus = "failed"
+)
+
+// TransferResult is the response of an executed transfer. It extends the
+// transfer event with the transaction records it created.
+type TransferResult struct {
+	TransferEvent
+	Status              TransferResultStatus `json:"status"`
+	TxID                string               `json:"tx_id"`                           // ledger transaction of the transfer
+	DebitTransactionID  string               `json:"debit_transaction_id,omitempty"`  // transaction record on the payer account
+	CreditTransactionID string               `json:"credit_transaction_id,omitempty"` // transaction record on the payee account
+	ValueDate           string               `json:"value_date,omitempty"`
+	Balance             *int64               `json:"balance,omitempty"`   // payer balance after the transfer, in cents
+	Available           *int64               `json:"available,omitempty"` // payer funds available after the transfer, in cents
+}
+
+// NewTransferResult creates the result of a transfer from the transaction
+// records written for it
+func NewTransferResult(t *Transfer, debit *Transaction, credit *Transaction, tx *TxContext) *TransferResult {
+	r := &TransferResult{TransferEvent: *NewTransferEvent(t), Status: TransferCompleted, TxID: tx.ID, ValueDate: t.ValueDate}
+	if debit != nil {
+		r.DebitTransactionID = debit.ID
+		if debit.Status == Failed {
+			r.Status = TransferFailed
+		}
 	}
-	if t.CurrencyCode == "" {
-		return errors.New("Missing required currency value")
+	if credit != nil {
+		r.CreditTransactionID = credit.ID
 	}
-	// TODO: check valid currency codes
-	return nil
+	return r
+}
+
+// SetPayerBalance adds the balance and available funds of the payer account
+// after the transfer to the result
+func (r *TransferResult) SetPayerBalance(a *Account) {
+	balance, available := a.Balance, a.Available()
+	r.Balance, r.Available = &balance, &available
 }
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// ApprovalPolicyObjectType blockchain object type
+	ApprovalPolicyObjectType = "ApprovalPolicy"
+	// TransferApprovalObjectType blockchain object type
+	TransferApprovalObjectType = "TransferApproval"
+)
+
+// ApprovalPolicy requires transfers in a currency above a threshold to be
+// approved by a number of distinct transfer approvers before they execute
+type ApprovalPolicy struct {
+	Entity
+	CurrencyCode      string `json:"currency"`
+	Threshold         int64  `json:"threshold"` // amount in cents above which approval is required
+	RequiredApprovals int    `json:"required_approvals"`
+	SetBy             string `json:"set_by"`
+	Updated           int64  `json:"updated"` // unix timestamp
+}
+
+// TransferApproval is a transfer held in the approval queue until enough
+// approvers approve it, or one rejects it
+type TransferApproval struct {
+	Entity
+	ID                string         `json:"id"`
+	Transfer          *Transfer      `json:"transfer"`
+	RequiredApprovals int            `json:"required_approvals"`
+	Approvals         []*Approval    `json:"approvals"`
+	Status            ApprovalStatus `json:"status"`
+	Reason            string         `json:"reason,omitempty"` // why the transfer was rejected
+	InitiatedBy       string         `json:"initiated_by"`
+	RejectedBy        string         `json:"rejected_by,omitempty"`
+	Created           int64          `json:"created"`            // unix timestamp
+	Resolved          int64          `json:"resolved,omitempty"` // unix timestamp
+}
+
+// TransferApprovalList holds a list of transfers in the approval queue
+type TransferApprovalList struct {
+	Approvals []*TransferApproval `json:"approvals"`
+}
+
+// CreateApprovalPolicy Factory function creates a new ApprovalPolicy struct and returns a pointer to it
+func CreateApprovalPolicy(policyBytes []byte, setBy string, tx *TxContext) (*ApprovalPolicy, error) {
+	policy := new(ApprovalPolicy)
+	if err := json.Unmarshal(policyBytes, policy); err != nil {
+		return nil, err
+	}
+	policy.ObjectType = ApprovalPolicyObjectType
+	if err := ValidateCurrency(policy.CurrencyCode); err != nil {
+		return nil, err
+	}
+	if policy.Threshold < 0 {
+		return nil, errors.New("Invalid negative threshold")
+	}
+	if policy.RequiredApprovals < 1 {
+		return nil, fmt.Errorf("Invalid required approvals %d, must be at least 1", policy.RequiredApprovals)
+	}
+	policy.SetBy = setBy
+	policy.Updated = tx.Time.Unix()
+	return policy, nil
+}
+
+// Requires returns true if the transfer needs approval under the policy
+func (p *ApprovalPolicy) Requires(t *Transfer) bool {
+	return t.CurrencyCode == p.CurrencyCode && t.Amount > p.Threshold
+}
+
+// CreateTransferApproval a factory function for queueing a transfer for approval
+func CreateTransferApproval(id string, t *Transfer, required int, initiatedBy string, tx *TxContext) *TransferApproval {
+	return &TransferApproval{
+		Entity:            Entity{TransferApprovalObjectType},
+		ID:                id,
+		Transfer:          t,
+		RequiredApprovals: required,
+		Approvals:         []*Approval{},
+		Status:            PendingApproval,
+		InitiatedBy:       initiatedBy,
+		Created:           tx.Time.Unix(),
+	}
+}
+
+// Approve records an approver's approval. The initiator may not approve their
+// own transfer and no approver may approve twice.
+func (a *TransferApproval) Approve(approver string, tx *TxContext) error {
+	if a.Status != PendingApproval {
+		return fmt.Errorf("Transfer %s is not pending approval", a.ID)
+	}
+	if approver == a.InitiatedBy {
+		return fmt.Errorf("Transfer %s cannot be approved by its initiator", a.ID)
+	}
+	for _, approval := range a.Approvals {
+		if approval.Signer == approver {
+			return fmt.Errorf("Transfer %s already approved by this approver", a.ID)
+		}
+	}
+	a.Approvals = append(a.Approvals, &Approval{Signer: approver, TxID: tx.ID, Approved: tx.Time.Unix()})
+	return nil
+}
+
+// Approved returns true once enough distinct approvers have approved
+func (a *TransferApproval) Approved() bool {
+	return len(a.Approvals) >= a.RequiredApprovals
+}
+
+// Settle records the execution of an approved transfer
+func (a *TransferApproval) Settle(tx *TxContext) {
+	a.Status = ApprovalSettled
+	a.Resolved = tx.Time.Unix()
+}
+
+// Reject records the rejection of a transfer pending approval
+func (a *TransferApproval) Reject(approver string, reason string, tx *TxContext) error {
+	if a.Status != PendingApproval {
+		return fmt.Errorf("Transfer %s is not pending approval", a.ID)
+	}
+	a.Status = ApprovalRejected
+	a.Reason = reason
+	a.RejectedBy = approver
+	a.Resolved = tx.Time.Unix()
+	return nil
+}
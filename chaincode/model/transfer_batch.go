@@ -0,0 +1,79 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MaxTransferBatchSize caps the transfers of a single TransferBatch invocation
+const MaxTransferBatchSize = 1000
+
+// TransferBatchMode stores allowed values for how a batch handles failing transfers.
+// Allowed values are "atomic", "partial"
+type TransferBatchMode string
+
+const (
+	// BatchAtomic applies every transfer of the batch or none of them
+	BatchAtomic TransferBatchMode = "atomic"
+	// BatchPartial applies the transfers that succeed and reports the ones that fail
+	BatchPartial TransferBatchMode = "partial"
+)
+
+// TransferBatch is a list of transfers submitted together, e.g. a payroll payout
+type TransferBatch struct {
+	Mode      TransferBatchMode `json:"mode"` // defaults to atomic
+	Transfers []*Transfer       `json:"transfers"`
+}
+
+// TransferBatchItem reports the outcome of a single transfer of a batch
+type TransferBatchItem struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"` // "settled" or "failed"
+	Fee    int64  `json:"fee"`
+	Error  string `json:"error,omitempty"`
+	// FailureCode of a failed transfer, when the failure has one
+	FailureCode TxFailureCode `json:"failure_code,omitempty"`
+}
+
+// TransferBatchResult summarizes the outcome of a TransferBatch invocation
+type TransferBatchResult struct {
+	Mode    TransferBatchMode    `json:"mode"`
+	Total   int                  `json:"total"`
+	Settled int                  `json:"settled"`
+	Failed  int                  `json:"failed"`
+	Amount  int64                `json:"amount"` // amount in cents of the settled transfers
+	Fees    int64                `json:"fees"`   // fees in cents of the settled transfers
+	Items   []*TransferBatchItem `json:"items"`
+}
+
+// CreateTransferBatch Factory function creates a new TransferBatch struct, validating
+// every transfer up front, and returns a pointer to it
+func CreateTransferBatch(batchBytes []byte) (*TransferBatch, error) {
+	batch := new(TransferBatch)
+	if err := json.Unmarshal(batchBytes, batch); err != nil {
+		return nil, err
+	}
+	switch batch.Mode {
+	case "":
+		batch.Mode = BatchAtomic
+	case BatchAtomic, BatchPartial:
+	default:
+		return nil, fmt.Errorf("Invalid batch mode %s", batch.Mode)
+	}
+	if len(batch.Transfers) == 0 {
+		return nil, errors.New("Missing required transfers")
+	}
+	if len(batch.Transfers) > MaxTransferBatchSize {
+		return nil, fmt.Errorf("Batch of %d transfers exceeds the maximum of %d", len(batch.Transfers), MaxTransferBatchSize)
+	}
+	for i, t := range batch.Transfers {
+		if t == nil {
+			return nil, fmt.Errorf("Invalid transfer %d. Missing transfer details", i)
+		}
+		if err := t.Validate(); err != nil {
+			return nil, fmt.Errorf("Invalid transfer %d. Error: %s", i, err)
+		}
+	}
+	return batch, nil
+}
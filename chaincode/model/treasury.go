@@ -0,0 +1,68 @@
+package model
+
+import (
+	"sort"
+)
+
+// TreasuryObjectType blockchain object type
+const TreasuryObjectType = "Treasury"
+
+// CurrencyPosition holds a bank's treasury position in one currency
+type CurrencyPosition struct {
+	CurrencyCode     string `json:"currency"`
+	NostroCustomerID string `json:"nostro_customer,omitempty"`
+	NostroAccountID  string `json:"nostro_account,omitempty"`
+	NostroBalance    int64  `json:"nostro_balance"` // read from the nostro account at query time
+	PendingIn        int64  `json:"pending_in"`     // unsettled amounts owed to the bank
+	PendingOut       int64  `json:"pending_out"`    // unsettled amounts the bank owes
+	PoolDrawn        int64  `json:"pool_drawn"`     // outstanding liquidity pool draws
+	NetPosition      int64  `json:"net_position"`   // nostro balance plus pending in less pending out
+	Updated          int64  `json:"updated"`        // unix timestamp
+}
+
+// Treasury tracks a bank's funding positions by currency
+type Treasury struct {
+	Entity
+	BankID    string                       `json:"bank_id"`
+	Positions map[string]*CurrencyPosition `json:"positions"`
+}
+
+// TreasuryView is the query representation of a treasury with ordered positions
+type TreasuryView struct {
+	BankID    string              `json:"bank_id"`
+	Positions []*CurrencyPosition `json:"positions"`
+}
+
+// CreateTreasury Factory function creates an empty Treasury for a bank
+func CreateTreasury(bankID string) *Treasury {
+	return &Treasury{
+		Entity:    Entity{TreasuryObjectType},
+		BankID:    bankID,
+		Positions: make(map[string]*CurrencyPosition),
+	}
+}
+
+// Position returns the position in a currency, creating it if needed
+func (t *Treasury) Position(currency string, tx *TxContext) *CurrencyPosition {
+	if t.Positions == nil {
+		t.Positions = make(map[string]*CurrencyPosition)
+	}
+	p, ok := t.Positions[currency]
+	if !ok {
+		p = &CurrencyPosition{CurrencyCode: currency}
+		t.Positions[currency] = p
+	}
+	p.Updated = tx.Time.Unix()
+	return p
+}
+
+// View returns the treasury with positions ordered by currency
+func (t *Treasury) View() *TreasuryView {
+	view := &TreasuryView{BankID: t.BankID}
+	for _, p := range t.Positions {
+		p.NetPosition = p.NostroBalance + p.PendingIn - p.PendingOut
+		view.Positions = append(view.Positions, p)
+	}
+	sort.Slice(view.Positions, func(i, j int) bool { return view.Positions[i].CurrencyCode < view.Positions[j].CurrencyCode })
+	return view
+}
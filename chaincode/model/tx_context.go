@@ -0,0 +1,30 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iShamSLam/chaincode/utils"
+)
+
+// TxContext identifies the ledger transaction writing records. Records are
+// stamped from it rather than from the endorsing peer's clock or random
+// numbers, so that every endorser of a transaction produces the same writes.
+type TxContext struct {
+	ID          string    // transaction ID
+	Time        time.Time // transaction timestamp, set by the client in the proposal
+	Traceparent string    // W3C trace context the client passed with the invocation, if any
+	ids         int       // IDs generated so far
+}
+
+// CreateTxContext Factory function creates a new TxContext struct and returns a pointer to it
+func CreateTxContext(txID string, timestamp time.Time) *TxContext {
+	return &TxContext{ID: txID, Time: timestamp.UTC()}
+}
+
+// NewID returns an ID of the given number of digits derived from the
+// transaction ID. Each call within the transaction returns a different ID.
+func (c *TxContext) NewID(length int) string {
+	c.ids++
+	return utils.GenerateID(fmt.Sprintf("%s-%d", c.ID, c.ids), length)
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes why a field of an inbound payload is invalid
+type FieldError struct {
+	Field   string `json:"field"` // JSON name of the field
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the invalid fields of an inbound payload
+type ValidationError struct {
+	Fields []*FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	fields, _ := json.Marshal(e.Fields)
+	return fmt.Sprintf("Invalid fields %s", fields)
+}
+
+// Unmarshal decodes an inbound JSON payload into v and checks the fields
+// against their validate struct tags. Type mismatches and rule violations are
+// reported together as a ValidationError.
+//
+// The rules of a validate tag are separated by commas:
+//
+//	required      the field must not be empty or zero
+//	max=N         strings at most N characters, slices at most N elements
+//	min=N         numbers at least N
+//	positive      numbers greater than zero
+//	amount        an amount of a money operation, see ValidateAmount
+//	oneof=a b c   strings one of the values, if set
+//	currency      an ISO 4217 currency code, if set
+//	country       an ISO 3166-1 alpha-2 country code, if set
+//	purpose       one of PurposeCodes, if set
+//	reference     letters, digits and / - ? : ( ) . , ' + and spaces, if set
+func Unmarshal(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok && typeErr.Field != "" {
+			return &ValidationError{Fields: []*FieldError{{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Message: fmt.Sprintf("expected a %s value, got %s", typeErr.Type, typeErr.Value),
+			}}}
+		}
+		return err
+	}
+	return ValidateFields(v)
+}
+
+// ValidateFields checks the fields of a struct against their validate struct tags
+func ValidateFields(v interface{}) error {
+	var fields []*FieldError
+	validateStruct(reflect.Indirect(reflect.ValueOf(v)), &fields)
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value, fields *[]*FieldError) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous {
+			validateStruct(reflect.Indirect(v.Field(i)), fields)
+			continue
+		}
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			if message := checkRule(rule, v.Field(i)); message != "" {
+				ruleName := strings.SplitN(rule, "=", 2)[0]
+				*fields = append(*fields, &FieldError{Field: name, Rule: ruleName, Message: message})
+				break
+			}
+		}
+	}
+}
+
+// checkRule returns why the value breaks the rule, or "" if it does not
+func checkRule(rule string, v reflect.Value) string {
+	name, param := rule, ""
+	if i := strings.Index(rule, "="); i >= 0 {
+		name, param = rule[:i], rule[i+1:]
+	}
+	switch name {
+	case "required":
+		if v.IsZero() {
+			return "is required"
+		}
+	case "max":
+		n, _ := strconv.Atoi(param)
+		switch v.Kind() {
+		case reflect.String:
+			if utf8.RuneCountInString(v.String()) > n {
+				return fmt.Sprintf("must be at most %d characters", n)
+			}
+		case reflect.Slice, reflect.Map:
+			if v.Len() > n {
+				return fmt.Sprintf("must have at most %d elements", n)
+			}
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			if v.Int() > int64(n) {
+				return fmt.Sprintf("must be at most %d", n)
+			}
+		}
+	case "min":
+		n, _ := strconv.ParseInt(param, 10, 64)
+		if v.Int() < n {
+			return fmt.Sprintf("must be at least %d", n)
+		}
+	case "positive":
+		if v.Int() <= 0 {
+			return "must be positive"
+		}
+	case "amount":
+		if err := ValidateAmount(v.Int()); err != nil {
+			return err.(*AmountError).Reason
+		}
+	case "oneof":
+		if s := v.String(); s != "" {
+			for _, allowed := range strings.Fields(param) {
+				if s == allowed {
+					return ""
+				}
+			}
+			return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(param), ", "))
+		}
+	case "currency":
+		if s := v.String(); s != "" {
+			if _, ok := iso4217[s]; !ok {
+				return "must be an ISO 4217 currency code"
+			}
+		}
+	case "country":
+		if s := v.String(); s != "" && !countryPattern.MatchString(s) {
+			return "must be an ISO 3166-1 alpha-2 country code"
+		}
+	case "purpose":
+		if s := v.String(); s != "" {
+			if _, ok := PurposeCodes[s]; !ok {
+				return "must be a supported ISO 20022 purpose code"
+			}
+		}
+	case "reference":
+		if s := v.String(); s != "" && !referencePattern.MatchString(s) {
+			return "may only contain letters, digits, spaces and / - ? : ( ) . , ' +"
+		}
+	default:
+		return fmt.Sprintf("has unknown validation rule %s", name)
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// WithholdingRuleObjectType blockchain object type
+const WithholdingRuleObjectType = "WithholdingRule"
+
+// WithholdingRule configures the tax withheld on cross-border transfers for a
+// corridor (from country to country) and, optionally, a purpose code
+type WithholdingRule struct {
+	Entity
+	FromCountry            string `json:"from_country"`
+	ToCountry              string `json:"to_country"`
+	PurposeCode            string `json:"purpose_code,omitempty"` // empty matches any purpose
+	Rate                   int64  `json:"rate"`                   // withholding rate in basis points
+	TaxAuthorityCustomerID string `json:"tax_authority_customer"`
+	TaxAuthorityAccountID  string `json:"tax_authority_account"`
+	Created                int64  `json:"created"` // unix timestamp
+}
+
+// WithholdingCertificate records the tax withheld from a transfer
+type WithholdingCertificate struct {
+	CertificateID          string `json:"certificate_id"`
+	FromCountry            string `json:"from_country"`
+	ToCountry              string `json:"to_country"`
+	PurposeCode            string `json:"purpose_code,omitempty"`
+	Rate                   int64  `json:"rate"`
+	GrossAmount            int64  `json:"gross_amount"`
+	TaxAmount              int64  `json:"tax_amount"`
+	NetAmount              int64  `json:"net_amount"`
+	TaxAuthorityCustomerID string `json:"tax_authority_customer"`
+	TaxAuthorityAccountID  string `json:"tax_authority_account"`
+	Issued                 int64  `json:"issued"` // unix timestamp
+}
+
+// CreateWithholdingRule Factory function creates a new WithholdingRule struct and returns a pointer to it
+func CreateWithholdingRule(ruleBytes []byte, tx *TxContext) (*WithholdingRule, error) {
+	rule := new(WithholdingRule)
+	if err := json.Unmarshal(ruleBytes, rule); err != nil {
+		return nil, err
+	}
+	rule.ObjectType = WithholdingRuleObjectType
+	if rule.FromCountry == "" || rule.ToCountry == "" {
+		return nil, errors.New("Missing required from_country and / or to_country")
+	}
+	if rule.FromCountry == rule.ToCountry {
+		return nil, errors.New("Withholding rules apply to cross-border corridors only")
+	}
+	if rule.Rate <= 0 || rule.Rate >= 10000 {
+		return nil, fmt.Errorf("Invalid withholding rate %d", rule.Rate)
+	}
+	if rule.TaxAuthorityCustomerID == "" || rule.TaxAuthorityAccountID == "" {
+		return nil, errors.New("Missing required tax_authority_customer and / or tax_authority_account")
+	}
+	rule.Created = tx.Time.Unix()
+	return rule, nil
+}
+
+// Withhold computes the withholding certificate for a gross transfer amount
+func (r *WithholdingRule) Withhold(gross int64, purposeCode string, certificateID string, tx *TxContext) *WithholdingCertificate {
+	tax := gross * r.Rate / 10000
+	return &WithholdingCertificate{
+		CertificateID:          certificateID,
+		FromCountry:            r.FromCountry,
+		ToCountry:              r.ToCountry,
+		PurposeCode:            purposeCode,
+		Rate:                   r.Rate,
+		GrossAmount:            gross,
+		TaxAmount:              tax,
+		NetAmount:              gross - tax,
+		TaxAuthorityCustomerID: r.TaxAuthorityCustomerID,
+		TaxAuthorityAccountID:  r.TaxAuthorityAccountID,
+		Issued:                 tx.Time.Unix(),
+	}
+}
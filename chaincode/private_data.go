@@ -0,0 +1,286 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// privateDataSaltKey is the transient data field carrying the client's secret
+// salt for the hashes of private fields. Transient data is not recorded on the
+// ledger, so unlike the transaction ID the salt cannot be read off the public
+// record to brute-force private fields of few possible values.
+const privateDataSaltKey = "private_data_salt"
+
+// minPrivateDataSaltLength is the least number of bytes of a private data salt
+const minPrivateDataSaltLength = 16
+
+// implicitCollectionPrefix prefixes the MSP ID in the name of an organization's
+// implicit private data collection, which only that organization's peers store
+const implicitCollectionPrefix = "_implicit_org_"
+
+// privateStub wraps the chaincode stub so that the private fields of models
+// declaring them (see model.PrivateModels) are written to the private data
+// collection of the bank involved and only a hash of them, salted with a secret
+// the client passes as transient data, to the public channel. Reads merge the private fields back when the caller's organization
+// holds the collection, so handlers are unaware of the split. Records read
+// without access keep their private data untouched when written back.
+type privateStub struct {
+	shim.ChaincodeStubInterface
+	cc     *Chaincode
+	opaque map[string]*model.PrivateDataRef // keys read without access to their private data
+}
+
+func newPrivateStub(cc *Chaincode, stub shim.ChaincodeStubInterface) *privateStub {
+	return &privateStub{ChaincodeStubInterface: stub, cc: cc, opaque: make(map[string]*model.PrivateDataRef)}
+}
+
+// GetState reads the public record and merges its private fields, if readable
+func (s *privateStub) GetState(key string) ([]byte, error) {
+	value, err := s.ChaincodeStubInterface.GetState(key)
+	if err != nil || value == nil {
+		return value, err
+	}
+	return s.reveal(key, value), nil
+}
+
+// PutState writes the public record, and its private fields to the private
+// data collections of the banks involved
+func (s *privateStub) PutState(key string, value []byte) error {
+	if ref, ok := s.opaque[key]; ok {
+		publicData, _, err := model.SplitPrivateData(value, "", nil)
+		if err != nil {
+			return err
+		}
+		if publicData, err = setPrivateDataRef(publicData, ref); err != nil {
+			return err
+		}
+		return s.ChaincodeStubInterface.PutState(key, publicData)
+	}
+	collections, err := s.cc.privateCollections(s, key, value)
+	if err != nil {
+		return err
+	}
+	if len(collections) == 0 {
+		return s.ChaincodeStubInterface.PutState(key, value)
+	}
+	salt, err := s.privateDataSalt(key)
+	if err != nil {
+		return err
+	}
+	publicData, privateData, err := model.SplitPrivateData(value, salt, collections)
+	if err != nil {
+		return err
+	}
+	if privateData != nil {
+		for _, collection := range collections {
+			if err := s.ChaincodeStubInterface.PutPrivateData(collection, key, privateData); err != nil {
+				return err
+			}
+		}
+	}
+	return s.ChaincodeStubInterface.PutState(key, publicData)
+}
+
+// DelState deletes the public record and its private data
+func (s *privateStub) DelState(key string) error {
+	value, err := s.ChaincodeStubInterface.GetState(key)
+	if err != nil {
+		return err
+	}
+	if ref := model.PrivateDataRefOf(value); ref != nil {
+		for _, collection := range ref.Collections {
+			if err := s.ChaincodeStubInterface.DelPrivateData(collection, key); err != nil {
+				return err
+			}
+		}
+	}
+	delete(s.opaque, key)
+	return s.ChaincodeStubInterface.DelState(key)
+}
+
+// GetStateByPartialCompositeKey scans the partial composite key, merging the
+// private fields of each record
+func (s *privateStub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	it, err := s.ChaincodeStubInterface.GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return &privateQueryIterator{StateQueryIteratorInterface: it, stub: s}, nil
+}
+
+// GetQueryResult runs the rich query, merging the private fields of each result.
+// Selectors only match public fields.
+func (s *privateStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	it, err := s.ChaincodeStubInterface.GetQueryResult(query)
+	if err != nil {
+		return nil, err
+	}
+	return &privateQueryIterator{StateQueryIteratorInterface: it, stub: s}, nil
+}
+
+// GetQueryResultWithPagination runs the paged rich query, merging the private
+// fields of each result
+func (s *privateStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	it, metadata, err := s.ChaincodeStubInterface.GetQueryResultWithPagination(query, pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &privateQueryIterator{StateQueryIteratorInterface: it, stub: s}, metadata, nil
+}
+
+// privateDataSalt returns the salt of a record's private data, derived from the
+// secret salt the client passes as transient data and the record key, so no
+// two records of a transaction share a salt
+func (s *privateStub) privateDataSalt(key string) (string, error) {
+	transient, err := s.GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("Error reading transient data. Error: %s", err)
+	}
+	secret := transient[privateDataSaltKey]
+	if len(secret) < minPrivateDataSaltLength {
+		return "", fmt.Errorf("Missing required %s transient data of at least %d bytes", privateDataSaltKey, minPrivateDataSaltLength)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(key))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// reveal merges the private fields into a public record from the first of its
+// collections the caller's organization can read. Without access the public
+// record is returned as is.
+func (s *privateStub) reveal(key string, value []byte) []byte {
+	ref := model.PrivateDataRefOf(value)
+	if ref == nil {
+		return value
+	}
+	for _, collection := range ref.Collections {
+		privateData, err := s.ChaincodeStubInterface.GetPrivateData(collection, key)
+		if err != nil || privateData == nil {
+			continue
+		}
+		merged, err := model.MergePrivateData(value, privateData)
+		if err != nil {
+			loggerFor(s).Warningf("Ignoring private data of %s in collection %s. Error: %s", key, collection, err)
+			continue
+		}
+		delete(s.opaque, key)
+		return merged
+	}
+	s.opaque[key] = ref
+	return value
+}
+
+// privateCollections returns the private data collections a record is written
+// to: that of the bank holding the account the record belongs to, or that of
+// the caller's organization when the bank is not registered
+func (cc *Chaincode) privateCollections(stub *privateStub, key string, value []byte) ([]string, error) {
+	namespace, objectType, attrs, err := splitScopedKey(stub, key)
+	if err != nil {
+		return nil, nil
+	}
+	if _, ok := model.PrivateModels[objectType]; !ok {
+		return nil, nil
+	}
+	bankName := ""
+	switch objectType {
+	case model.AccountObjectType:
+		account := new(model.Account)
+		if err := bytesToStruct(value, account); err != nil {
+			return nil, err
+		}
+		bankName = account.BankName
+	case model.TransactionObjectType:
+		if len(attrs) < 2 {
+			break
+		}
+		accountKey, err := cc.createCompositeKey(stub, model.AccountObjectType, attrs[:2])
+		if err != nil {
+			return nil, err
+		}
+		if namespace != "" {
+			accountKey = namespace + strings.TrimPrefix(accountKey, compositeKeyNamespace)
+		}
+		accountData, err := stub.ChaincodeStubInterface.GetState(accountKey)
+		if err != nil {
+			return nil, err
+		}
+		if accountData != nil {
+			account := new(model.Account)
+			if err := bytesToStruct(accountData, account); err != nil {
+				return nil, err
+			}
+			bankName = account.BankName
+		}
+	}
+	if bankName != "" {
+		bank, err := cc.getBank(stub, bankName)
+		if err != nil {
+			return nil, err
+		}
+		if bank != nil && bank.MSPID != "" {
+			return []string{implicitCollectionPrefix + bank.MSPID}, nil
+		}
+	}
+	mspID, err := callerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	return []string{implicitCollectionPrefix + mspID}, nil
+}
+
+// splitScopedKey splits a state key into the tenant namespace it is stored
+// under, if any, its object type and its attributes
+func splitScopedKey(stub shim.ChaincodeStubInterface, key string) (string, string, []string, error) {
+	objectType, attrs, err := stub.SplitCompositeKey(key)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if strings.HasPrefix(objectType, "@") && len(attrs) > 0 {
+		return tenantNamespace(strings.TrimPrefix(objectType, "@")), attrs[0], attrs[1:], nil
+	}
+	return "", objectType, attrs, nil
+}
+
+// setPrivateDataRef replaces the private data reference of a public record
+func setPrivateDataRef(record []byte, ref *model.PrivateDataRef) ([]byte, error) {
+	if ref == nil {
+		return record, nil
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(record, &fields); err != nil {
+		return nil, err
+	}
+	refData, err := json.Marshal(ref)
+	if err != nil {
+		return nil, err
+	}
+	fields[model.PrivateDataField] = refData
+	return json.Marshal(fields)
+}
+
+// privateQueryIterator merges the private fields of the range and rich query
+// results it returns
+type privateQueryIterator struct {
+	shim.StateQueryIteratorInterface
+	stub *privateStub
+}
+
+// Next returns the next result with its private fields, if readable
+func (it *privateQueryIterator) Next() (*queryresult.KV, error) {
+	kv, err := it.StateQueryIteratorInterface.Next()
+	if err != nil || kv == nil {
+		return kv, err
+	}
+	kv.Value = it.stub.reveal(kv.Key, kv.Value)
+	return kv, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+func TestPrivateDataHashIsSaltedWithTheTransientSecret(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	key, _ := stub.CreateCompositeKey(model.AccountObjectType, []string{"1001", "1"})
+	ref := model.PrivateDataRefOf(stub.State[key])
+	guess, _ := json.Marshal(map[string]string{"account_holder": "Customer 1001", "salt": stub.LastTxID()})
+	if ref == nil || ref.Hash == model.PrivateDataHash(guess) {
+		t.Errorf("Expected the public hash not to be salted with the transaction ID, got %+v", ref)
+	}
+	if strings.Contains(string(stub.State[key]), "Customer 1001") {
+		t.Errorf("Expected the account holder kept off the public record, got %s", stub.State[key])
+	}
+
+	_, err := stub.As(testsupport.Operator(t, RoleTeller)).Invoke(func(s shim.ChaincodeStubInterface) ([]byte, error) {
+		stub.TransientMap = nil
+		return testChaincode.handleInvocation(s, "OpenAccount", []string{testsupport.NewAccount("1001", "2").JSON()})
+	})
+	if err == nil || !strings.Contains(err.Error(), "Missing required private_data_salt transient data") {
+		t.Errorf("Expected a private record refused without the transient salt, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+// Package swift parses SWIFT MT messages sent by correspondent banks that do
+// not submit transfers as JSON, and maps them onto FinNet transfers.
+package swift
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+)
+
+const (
+	// ChargesOurs the ordering customer bears all charges
+	ChargesOurs = "OUR"
+	// ChargesShared charges are shared between the ordering customer and the beneficiary
+	ChargesShared = "SHA"
+	// ChargesBeneficiary the beneficiary bears all charges
+	ChargesBeneficiary = "BEN"
+)
+
+// ValueDateFormat is the layout of value dates in the transfer params
+const ValueDateFormat = "2006-01-02"
+
+var (
+	fieldTag    = regexp.MustCompile(`^:([^:]*):`)
+	validTag    = regexp.MustCompile(`^[0-9]{2}[A-Z]?$`)
+	field32A    = regexp.MustCompile(`^([0-9]{6})([A-Z]{3})([0-9]+,[0-9]*)$`)
+	partyLimits = map[string]int{"50K": 5, "59": 5, "70": 4}
+)
+
+// Party is the ordering customer (field 50K) or beneficiary (field 59) of an
+// MT103. FinNet accounts are identified as <customer ID>/<account ID> on the
+// account line.
+type Party struct {
+	CustomerID string
+	AccountID  string
+	Name       []string // name and address lines
+}
+
+// MT103 holds the fields of a single customer credit transfer that FinNet maps
+// onto a transfer. Other fields, e.g. 23B or 52A, are accepted and ignored.
+type MT103 struct {
+	Reference        string    // field 20, sender's reference
+	ValueDate        time.Time // field 32A
+	CurrencyCode     string    // field 32A
+	Amount           int64     // field 32A, in minor units of the currency
+	OrderingCustomer Party     // field 50K
+	Beneficiary      Party     // field 59
+	RemittanceInfo   []string  // field 70
+	DetailsOfCharges string    // field 71A
+}
+
+// ParseMT103 parses an MT103 message, either the whole message with its
+// header blocks or only the text block. A malformed or repeated field tag,
+// a missing mandatory field or an invalid field value is an error naming the
+// field.
+func ParseMT103(message string) (*MT103, error) {
+	text, err := textBlock(message)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string][]string)
+	var order []string
+	tag := ""
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, ":") {
+			m := fieldTag.FindStringSubmatch(line)
+			if m == nil || !validTag.MatchString(m[1]) {
+				return nil, fmt.Errorf("Malformed field tag at line %d: %q", i+1, line)
+			}
+			tag = m[1]
+			if _, ok := fields[tag]; ok {
+				return nil, fmt.Errorf("Field %s is repeated", tag)
+			}
+			fields[tag] = []string{line[len(m[0]):]}
+			order = append(order, tag)
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		if tag == "" {
+			return nil, fmt.Errorf("Text at line %d does not belong to a field: %q", i+1, line)
+		}
+		fields[tag] = append(fields[tag], line)
+	}
+	for _, tag := range []string{"20", "32A", "50K", "59", "71A"} {
+		if _, ok := fields[tag]; !ok {
+			return nil, fmt.Errorf("Missing mandatory field %s", tag)
+		}
+	}
+	msg := new(MT103)
+	for _, tag := range order {
+		lines := fields[tag]
+		if limit, ok := partyLimits[tag]; ok && len(lines) > limit {
+			return nil, fmt.Errorf("Field %s has %d lines, at most %d allowed", tag, len(lines), limit)
+		}
+		if err := msg.setField(tag, lines); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// textBlock returns the text block of a message, without its braces and
+// trailing "-"
+func textBlock(message string) (string, error) {
+	start := strings.Index(message, "{4:")
+	if start < 0 {
+		return strings.TrimSpace(message), nil
+	}
+	text := message[start+len("{4:"):]
+	end := strings.Index(text, "\n-}")
+	if end < 0 {
+		end = strings.Index(text, "-}")
+	}
+	if end < 0 {
+		return "", errors.New("Unterminated text block")
+	}
+	return strings.TrimSpace(text[:end]), nil
+}
+
+func (m *MT103) setField(tag string, lines []string) error {
+	var err error
+	switch tag {
+	case "20":
+		err = m.setReference(lines)
+	case "32A":
+		err = m.setValueDateAmount(lines)
+	case "50K":
+		m.OrderingCustomer, err = parseParty(tag, lines)
+	case "59":
+		m.Beneficiary, err = parseParty(tag, lines)
+	case "70":
+		m.RemittanceInfo = lines
+	case "71A":
+		err = m.setDetailsOfCharges(lines)
+	}
+	return err
+}
+
+func (m *MT103) setReference(lines []string) error {
+	ref := lines[0]
+	if len(lines) != 1 || ref == "" || len(ref) > 16 {
+		return errors.New("Field 20 must be a single line of 1 to 16 characters")
+	}
+	if strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") || strings.Contains(ref, "//") {
+		return fmt.Errorf("Field 20 reference %s must not start or end with / or contain //", ref)
+	}
+	m.Reference = ref
+	return nil
+}
+
+func (m *MT103) setValueDateAmount(lines []string) error {
+	parts := field32A.FindStringSubmatch(lines[0])
+	if len(lines) != 1 || parts == nil {
+		return fmt.Errorf("Field 32A %q must be YYMMDD, currency and amount, e.g. 261016AUD1250,00", strings.Join(lines, " "))
+	}
+	date, err := time.Parse("060102", parts[1])
+	if err != nil {
+		return fmt.Errorf("Field 32A value date %s is invalid", parts[1])
+	}
+	if err := model.ValidateCurrency(parts[2]); err != nil {
+		return fmt.Errorf("Field 32A: %s", err)
+	}
+	amount, err := minorUnits(parts[3], model.CurrencyMinorUnits(parts[2]))
+	if err != nil {
+		return fmt.Errorf("Field 32A: %s", err)
+	}
+	m.ValueDate, m.CurrencyCode, m.Amount = date, parts[2], amount
+	return nil
+}
+
+func (m *MT103) setDetailsOfCharges(lines []string) error {
+	if code := lines[0]; len(lines) == 1 && (code == ChargesOurs || code == ChargesShared || code == ChargesBeneficiary) {
+		m.DetailsOfCharges = code
+		return nil
+	}
+	return fmt.Errorf("Field 71A %q must be one of OUR, SHA or BEN", strings.Join(lines, " "))
+}
+
+// minorUnits converts an MT amount with a decimal comma into minor units of a
+// currency with the given number of decimal places
+func minorUnits(amount string, exponent int) (int64, error) {
+	whole, fraction := amount, ""
+	if i := strings.Index(amount, ","); i >= 0 {
+		whole, fraction = amount[:i], amount[i+1:]
+	}
+	if len(fraction) > exponent {
+		return 0, fmt.Errorf("Amount %s has more than %d decimal places", amount, exponent)
+	}
+	digits := whole + fraction + strings.Repeat("0", exponent-len(fraction))
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Amount %s is out of range", amount)
+	}
+	return value, nil
+}
+
+// parseParty reads the /<customer ID>/<account ID> account line and the name
+// and address lines of field 50K or 59
+func parseParty(tag string, lines []string) (Party, error) {
+	if !strings.HasPrefix(lines[0], "/") {
+		return Party{}, fmt.Errorf("Field %s must start with an /<customer ID>/<account ID> account line", tag)
+	}
+	ids := strings.Split(lines[0][1:], "/")
+	if len(ids) != 2 || ids[0] == "" || ids[1] == "" {
+		return Party{}, fmt.Errorf("Field %s account %s is not of the form /<customer ID>/<account ID>", tag, lines[0])
+	}
+	if len(lines) < 2 {
+		return Party{}, fmt.Errorf("Field %s is missing the name line", tag)
+	}
+	return Party{CustomerID: ids[0], AccountID: ids[1], Name: lines[1:]}, nil
+}
+
+// Transfer maps the message onto a FinNet transfer. The remittance information
+// becomes the description; the sender's reference, value date and details of
+// charges are kept in the transfer params.
+func (m *MT103) Transfer() *model.Transfer {
+	return &model.Transfer{
+		FromCustomerID: m.OrderingCustomer.CustomerID,
+		FromAccountID:  m.OrderingCustomer.AccountID,
+		ToCustomerID:   m.Beneficiary.CustomerID,
+		ToAccountID:    m.Beneficiary.AccountID,
+		Amount:         m.Amount,
+		CurrencyCode:   m.CurrencyCode,
+		Description:    strings.Join(m.RemittanceInfo, " "),
+		Params: map[string]string{
+			"mt103_reference": m.Reference,
+			"value_date":      m.ValueDate.Format(ValueDateFormat),
+			"charges":         m.DetailsOfCharges,
+		},
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// tenantStub wraps the chaincode stub so that every key of a tenant-scoped
+// object type is stored under the tenant's namespace. Keys of shared object
+// types (see model.SharedObjectTypes) pass through unchanged, and keys read
+// back from range queries have the namespace stripped, so handlers are
+// unaware of tenancy.
+type tenantStub struct {
+	shim.ChaincodeStubInterface
+	tenant string
+}
+
+// tenantNamespace returns the key prefix of a tenant. A scoped key is itself a
+// composite key whose object type is the tenant ID prefixed with "@"; object
+// types never start with "@", so namespaced keys cannot collide with unscoped ones.
+func tenantNamespace(tenant string) string {
+	return compositeKeyNamespace + "@" + tenant + compositeKeyNamespace
+}
+
+func (s *tenantStub) scopedKey(key string) string {
+	objectType, _, err := s.ChaincodeStubInterface.SplitCompositeKey(key)
+	if err == nil && model.SharedObjectTypes[objectType] {
+		return key
+	}
+	return tenantNamespace(s.tenant) + strings.TrimPrefix(key, compositeKeyNamespace)
+}
+
+// GetState reads the key from the tenant namespace
+func (s *tenantStub) GetState(key string) ([]byte, error) {
+	return s.ChaincodeStubInterface.GetState(s.scopedKey(key))
+}
+
+// PutState writes the key to the tenant namespace
+func (s *tenantStub) PutState(key string, value []byte) error {
+	return s.ChaincodeStubInterface.PutState(s.scopedKey(key), value)
+}
+
+// DelState deletes the key from the tenant namespace
+func (s *tenantStub) DelState(key string) error {
+	return s.ChaincodeStubInterface.DelState(s.scopedKey(key))
+}
+
+// GetStateByPartialCompositeKey scans the partial composite key within the
+// tenant namespace
+func (s *tenantStub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	if model.SharedObjectTypes[objectType] {
+		return s.ChaincodeStubInterface.GetStateByPartialCompositeKey(objectType, attributes)
+	}
+	iter, err := s.ChaincodeStubInterface.GetStateByPartialCompositeKey("@"+s.tenant, append([]string{objectType}, attributes...))
+	if err != nil {
+		return nil, err
+	}
+	return &tenantIterator{StateQueryIteratorInterface: iter, prefix: tenantNamespace(s.tenant)}, nil
+}
+
+// GetHistoryForKey reads the history of the key from the tenant namespace
+func (s *tenantStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return s.ChaincodeStubInterface.GetHistoryForKey(s.scopedKey(key))
+}
+
+// GetQueryResult runs a rich query and keeps only the results within the
+// tenant namespace and of shared object types
+func (s *tenantStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	iter, err := s.ChaincodeStubInterface.GetQueryResult(query)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantQueryIterator{StateQueryIteratorInterface: iter, stub: s}, nil
+}
+
+// GetQueryResultWithPagination runs a rich query and keeps only the results
+// within the tenant namespace and of shared object types. The state database
+// cannot be asked to filter on the key, so pages may hold fewer results than
+// the page size.
+func (s *tenantStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	iter, metadata, err := s.ChaincodeStubInterface.GetQueryResultWithPagination(query, pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &tenantQueryIterator{StateQueryIteratorInterface: iter, stub: s}, metadata, nil
+}
+
+// tenantQueryIterator skips rich query results of other tenants and strips the
+// tenant namespace from the keys it returns
+type tenantQueryIterator struct {
+	shim.StateQueryIteratorInterface
+	stub *tenantStub
+	next *queryresult.KV
+	err  error
+}
+
+// HasNext returns true if another result within the tenant namespace remains
+func (it *tenantQueryIterator) HasNext() bool {
+	prefix := tenantNamespace(it.stub.tenant)
+	for it.next == nil && it.err == nil && it.StateQueryIteratorInterface.HasNext() {
+		kv, err := it.StateQueryIteratorInterface.Next()
+		switch {
+		case err != nil:
+			it.err = err
+		case strings.HasPrefix(kv.Key, prefix):
+			kv.Key = compositeKeyNamespace + strings.TrimPrefix(kv.Key, prefix)
+			it.next = kv
+		case it.stub.scopedKey(kv.Key) == kv.Key:
+			it.next = kv
+		}
+	}
+	return it.next != nil || it.err != nil
+}
+
+// Next returns the next result within the tenant namespace
+func (it *tenantQueryIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, errors.New("No more query results")
+	}
+	kv, err := it.next, it.err
+	it.next, it.err = nil, nil
+	return kv, err
+}
+
+// tenantIterator strips the tenant namespace from the keys it returns
+type tenantIterator struct {
+	shim.StateQueryIteratorInterface
+	prefix string
+}
+
+// Next returns the next record without its tenant namespace
+func (it *tenantIterator) Next() (*queryresult.KV, error) {
+	kv, err := it.StateQueryIteratorInterface.Next()
+	if err != nil || kv == nil {
+		return kv, err
+	}
+	kv.Key = compositeKeyNamespace + strings.TrimPrefix(kv.Key, it.prefix)
+	return kv, nil
+}
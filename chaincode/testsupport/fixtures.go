@@ -0,0 +1,144 @@
+package testsupport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// CustomerFixture builds the customer data JSON passed to RegisterCustomer
+type CustomerFixture struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Country   string `json:"country"`
+	Residency string `json:"residency"`
+	RiskTier  string `json:"risk_tier"`
+}
+
+// NewCustomer returns a low risk customer resident in Australia
+func NewCustomer(id string) *CustomerFixture {
+	return &CustomerFixture{ID: id, Name: "Customer " + id, Country: "AU", Residency: "resident", RiskTier: "low"}
+}
+
+// JSON returns the customer data JSON
+func (c *CustomerFixture) JSON() string {
+	return marshal(c)
+}
+
+// KYC returns the KYC profile JSON passed to SubmitKYC for the customer
+func KYC(customerID string) string {
+	hash := sha256.Sum256([]byte("documents of customer " + customerID))
+	return marshal(map[string]string{"customer_id": customerID, "documents_hash": hex.EncodeToString(hash[:])})
+}
+
+// AccountFixture builds the account data JSON passed to OpenAccount
+type AccountFixture struct {
+	ID         string   `json:"id"`
+	CustomerID string   `json:"customer_id"`
+	Currency   string   `json:"currency"`
+	Holder     string   `json:"account_holder,omitempty"`
+	Bank       string   `json:"bank_name,omitempty"`
+	Country    string   `json:"country,omitempty"`
+	ProductID  string   `json:"product_id,omitempty"`
+	Type       string   `json:"account_type,omitempty"`
+	Signers    []string `json:"signers,omitempty"`
+	Quorum     int      `json:"required_signatures,omitempty"`
+}
+
+// NewAccount returns an AUD account of the customer held in Australia
+func NewAccount(customerID string, accountID string) *AccountFixture {
+	return &AccountFixture{ID: accountID, CustomerID: customerID, Currency: "AUD", Holder: "Customer " + customerID, Country: "AU"}
+}
+
+// InCurrency sets the currency of the account
+func (a *AccountFixture) InCurrency(currency string) *AccountFixture {
+	a.Currency = currency
+	return a
+}
+
+// AtBank sets the bank holding the account
+func (a *AccountFixture) AtBank(bank string) *AccountFixture {
+	a.Bank = bank
+	return a
+}
+
+// OfType sets the account type, which selects the interest paid
+func (a *AccountFixture) OfType(accountType string) *AccountFixture {
+	a.Type = accountType
+	return a
+}
+
+// InCountry sets the country of the account
+func (a *AccountFixture) InCountry(country string) *AccountFixture {
+	a.Country = country
+	return a
+}
+
+// WithProduct opens the account with a product of the catalog
+func (a *AccountFixture) WithProduct(productID string) *AccountFixture {
+	a.ProductID = productID
+	return a
+}
+
+// JSON returns the account data JSON
+func (a *AccountFixture) JSON() string {
+	return marshal(a)
+}
+
+// TransferFixture builds the transfer JSON passed to TransferMoney
+type TransferFixture struct {
+	FromCustomerID string            `json:"from_customer"`
+	FromAccountID  string            `json:"from_account"`
+	ToCustomerID   string            `json:"to_customer"`
+	ToAccountID    string            `json:"to_account"`
+	Amount         int64             `json:"amount"`
+	Currency       string            `json:"currency"`
+	Description    string            `json:"description"`
+	EndToEndID     string            `json:"end_to_end_id,omitempty"`
+	Category       string            `json:"category,omitempty"`
+	Params         map[string]string `json:"params,omitempty"`
+}
+
+// NewTransfer returns a transfer of the amount in cents of AUD between two accounts
+func NewTransfer(fromCustomerID, fromAccountID, toCustomerID, toAccountID string, amount int64) *TransferFixture {
+	return &TransferFixture{
+		FromCustomerID: fromCustomerID,
+		FromAccountID:  fromAccountID,
+		ToCustomerID:   toCustomerID,
+		ToAccountID:    toAccountID,
+		Amount:         amount,
+		Currency:       "AUD",
+		Description:    "Test transfer",
+	}
+}
+
+// InCurrency sets the currency of the transfer
+func (t *TransferFixture) InCurrency(currency string) *TransferFixture {
+	t.Currency = currency
+	return t
+}
+
+// WithReference sets the end to end reference of the transfer
+func (t *TransferFixture) WithReference(reference string) *TransferFixture {
+	t.EndToEndID = reference
+	return t
+}
+
+// InCategory sets the spending category of the transfer
+func (t *TransferFixture) InCategory(category string) *TransferFixture {
+	t.Category = category
+	return t
+}
+
+// JSON returns the transfer JSON
+func (t *TransferFixture) JSON() string {
+	return marshal(t)
+}
+
+func marshal(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,66 @@
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update rewrites the golden files with the outputs of the test run, e.g.
+// go test ./... -update
+var update = flag.Bool("update", false, "rewrite the golden files of handler outputs")
+
+// GoldenDir is the directory of the golden files, relative to the package under test
+var GoldenDir = filepath.Join("testdata", "golden")
+
+// AssertGolden compares a JSON handler output with the golden file of the
+// name. Outputs are compared as indented JSON with sorted object keys, so that
+// the golden files diff readably and do not depend on the field order of the
+// models.
+func AssertGolden(t testing.TB, name string, got []byte) {
+	t.Helper()
+	normalized, err := NormalizeJSON(got)
+	if err != nil {
+		t.Fatalf("Output for golden file %s is not JSON: %s. Error: %s", name, got, err)
+	}
+	path := filepath.Join(GoldenDir, name+".json")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, normalized, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("Missing golden file %s, run the test with -update to create it", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(normalized, want) {
+		t.Errorf("Output differs from golden file %s, run the test with -update to accept it\ngot:\n%s\nwant:\n%s", path, normalized, want)
+	}
+}
+
+// NormalizeJSON returns the JSON document indented, with sorted object keys
+// and a trailing newline
+func NormalizeJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	normalized, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(normalized, '\n'), nil
+}
@@ -0,0 +1,102 @@
+package testsupport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/auth"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// DefaultMSPID is the MSP of the identities created by Operator and Customer
+const DefaultMSPID = "Org1MSP"
+
+// attributesOID is the certificate extension in which the Fabric CA stores
+// the attributes of an enrolled identity
+var attributesOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// Identity is a client identity transactions are invoked as
+type Identity struct {
+	Name    string
+	MSPID   string
+	Creator []byte // serialized identity returned by GetCreator
+}
+
+// NewIdentity creates the identity of an MSP member whose self-signed
+// certificate carries the attributes, as enrolled with the Fabric CA
+func NewIdentity(mspID string, name string, attrs map[string]string) (*Identity, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name, Organization: []string{mspID}},
+		NotBefore:    Epoch.AddDate(-1, 0, 0),
+		NotAfter:     Epoch.AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if len(attrs) > 0 {
+		value, err := json.Marshal(map[string]interface{}{"attrs": attrs})
+		if err != nil {
+			return nil, err
+		}
+		template.ExtraExtensions = []pkix.Extension{{Id: attributesOID, Value: value}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{Name: name, MSPID: mspID, Creator: creator}, nil
+}
+
+// Operator returns an identity of DefaultMSPID holding the role
+func Operator(t testing.TB, role string) *Identity {
+	t.Helper()
+	id, err := NewIdentity(DefaultMSPID, role, map[string]string{auth.RoleAttribute: role})
+	if err != nil {
+		t.Fatalf("Error creating %s identity. Error: %s", role, err)
+	}
+	return id
+}
+
+// Customer returns an identity of DefaultMSPID holding the customer role and
+// acting for the customer
+func Customer(t testing.TB, customerID string) *Identity {
+	t.Helper()
+	id, err := NewIdentity(DefaultMSPID, "customer "+customerID, map[string]string{
+		auth.RoleAttribute:     "customer",
+		auth.CustomerAttribute: customerID,
+	})
+	if err != nil {
+		t.Fatalf("Error creating identity of customer %s. Error: %s", customerID, err)
+	}
+	return id
+}
+
+// Anonymous returns an identity of DefaultMSPID without FinNet attributes
+func Anonymous(t testing.TB) *Identity {
+	t.Helper()
+	id, err := NewIdentity(DefaultMSPID, "anonymous", nil)
+	if err != nil {
+		t.Fatalf("Error creating anonymous identity. Error: %s", err)
+	}
+	return id
+}
@@ -0,0 +1,139 @@
+// Package testsupport runs chaincode handlers against a shimtest MockStub
+// configured as a real peer would invoke them: as a client identity carrying
+// the FinNet certificate attributes, at a deterministic transaction time and
+// ID. It also builds the JSON arguments of common fixtures and compares
+// handler outputs with golden files.
+package testsupport
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Epoch is the time of the first transaction on a new stub
+var Epoch = time.Date(2021, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+// InvokeFunc invokes a chaincode function by name, as the chaincode's
+// dispatcher does for a contract transaction
+type InvokeFunc func(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error)
+
+// Stub is a MockStub that numbers its transactions tx1, tx2, ... and stamps
+// them with a clock advancing a second per transaction, so that handler
+// outputs are the same on every run
+type Stub struct {
+	*shimtest.MockStub
+	Now       time.Time            // time of the next transaction
+	Caller    *Identity            // identity invoking the next transaction, none if nil
+	Events    []*pb.ChaincodeEvent // events set by the last transaction
+	invoke    InvokeFunc
+	seq       int
+	customers map[string]bool // customers registered by OpenAccount
+}
+
+// NewStub creates a stub with an empty ledger at Epoch, calling functions
+// through the dispatcher
+func NewStub(invoke InvokeFunc) *Stub {
+	return &Stub{MockStub: shimtest.NewMockStub("finnet", nil), Now: Epoch, invoke: invoke, customers: make(map[string]bool)}
+}
+
+// As sets the identity invoking the following transactions
+func (s *Stub) As(caller *Identity) *Stub {
+	s.Caller = caller
+	return s
+}
+
+// Advance moves the clock of the following transactions forward
+func (s *Stub) Advance(d time.Duration) {
+	s.Now = s.Now.Add(d)
+}
+
+// LastTxID returns the ID of the last transaction invoked
+func (s *Stub) LastTxID() string {
+	return "tx" + strconv.Itoa(s.seq)
+}
+
+// Invoke runs fn in the next transaction and collects the events it set
+func (s *Stub) Invoke(fn func(stub shim.ChaincodeStubInterface) ([]byte, error)) ([]byte, error) {
+	s.seq++
+	txID := s.LastTxID()
+	s.MockTransactionStart(txID)
+	s.TxTimestamp = &timestamp.Timestamp{Seconds: s.Now.Unix(), Nanos: int32(s.Now.Nanosecond())}
+	s.Creator = nil
+	if s.Caller != nil {
+		s.Creator = s.Caller.Creator
+	}
+	// clients pass a secret salt for the hashes of private fields as transient data
+	s.TransientMap = map[string][]byte{"private_data_salt": []byte("private data salt of " + txID)}
+	defer func() {
+		s.MockTransactionEnd(txID)
+		s.Now = s.Now.Add(time.Second)
+	}()
+
+	res, err := fn(s)
+	s.Events = s.drainEvents()
+	return res, err
+}
+
+// Call invokes a chaincode function in the next transaction
+func (s *Stub) Call(function string, args ...string) ([]byte, error) {
+	return s.Invoke(func(stub shim.ChaincodeStubInterface) ([]byte, error) {
+		return s.invoke(stub, function, args)
+	})
+}
+
+// MustCall invokes a chaincode function that is expected to succeed
+func (s *Stub) MustCall(t testing.TB, function string, args ...string) []byte {
+	t.Helper()
+	res, err := s.Call(function, args...)
+	if err != nil {
+		t.Fatalf("Error invoking %s with args %v. Error: %s", function, args, err)
+	}
+	return res
+}
+
+// OpenAccount opens the account as a teller, registering its customer with an
+// approved KYC profile first unless the stub did already. It takes four
+// transactions for a new customer and one otherwise.
+func (s *Stub) OpenAccount(t testing.TB, account *AccountFixture) {
+	t.Helper()
+	caller := s.Caller
+	defer s.As(caller)
+
+	teller := Operator(t, "teller")
+	if !s.customers[account.CustomerID] {
+		s.As(teller).MustCall(t, "RegisterCustomer", NewCustomer(account.CustomerID).JSON())
+		s.MustCall(t, "SubmitKYC", KYC(account.CustomerID))
+		s.As(Operator(t, "compliance_officer")).MustCall(t, "ApproveKYC", account.CustomerID, "low", "2030-12-31")
+		s.customers[account.CustomerID] = true
+	}
+	s.As(teller).MustCall(t, "OpenAccount", account.JSON())
+}
+
+// Topup credits the amount in cents to an account as a teller
+func (s *Stub) Topup(t testing.TB, customerID string, accountID string, amount int64) {
+	t.Helper()
+	caller := s.Caller
+	defer s.As(caller)
+
+	s.As(Operator(t, "teller")).MustCall(t, "TopupAccount", customerID, accountID, strconv.FormatInt(amount, 10))
+}
+
+// drainEvents empties the buffered event channel of the MockStub, which
+// blocks further events once full
+func (s *Stub) drainEvents() []*pb.ChaincodeEvent {
+	var events []*pb.ChaincodeEvent
+	for {
+		select {
+		case event := <-s.ChaincodeEventsChannel:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Every handler function takes an optional W3C traceparent as its last
+// argument. The invocation's log lines, events and audit entry carry it, and
+// a JSON object response returns it in its traceparent field, so a payment
+// can be followed from the client's trace through the chaincode.
+
+// traceChaincode strips the traceparent off the arguments of invocations
+// before the contract parses them, so that the typed contract transactions
+// of fixed arity accept it as well
+type traceChaincode struct {
+	shim.Chaincode
+}
+
+// Invoke invokes the chaincode with the arguments without their traceparent
+func (c *traceChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return c.Chaincode.Invoke(newTraceStub(stub))
+}
+
+// traceStub presents the arguments of an invocation without a trailing
+// traceparent and keeps the traceparent for handleInvocation
+type traceStub struct {
+	shim.ChaincodeStubInterface
+	args        [][]byte
+	traceparent string
+}
+
+func newTraceStub(stub shim.ChaincodeStubInterface) *traceStub {
+	args := stub.GetArgs()
+	s := &traceStub{ChaincodeStubInterface: stub, args: args}
+	// the first argument is the function name
+	if n := len(args); n > 1 && model.IsTraceparent(string(args[n-1])) {
+		s.args, s.traceparent = args[:n-1], string(args[n-1])
+	}
+	return s
+}
+
+// GetArgs returns the arguments without the traceparent
+func (s *traceStub) GetArgs() [][]byte {
+	return s.args
+}
+
+// GetStringArgs returns the arguments without the traceparent as strings
+func (s *traceStub) GetStringArgs() []string {
+	args := make([]string, len(s.args))
+	for i, arg := range s.args {
+		args[i] = string(arg)
+	}
+	return args
+}
+
+// GetFunctionAndParameters returns the function name and the parameters
+// without the traceparent
+func (s *traceStub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+// GetArgsSlice returns the arguments without the traceparent as one slice
+func (s *traceStub) GetArgsSlice() ([]byte, error) {
+	return bytes.Join(s.args, nil), nil
+}
+
+// invocationTraceparent returns the arguments of an invocation without their
+// traceparent, and the traceparent: the last argument if it is one, else the
+// one the trace stub of the invocation stripped
+func invocationTraceparent(stub shim.ChaincodeStubInterface, args []string) ([]string, string) {
+	if n := len(args); n > 0 && model.IsTraceparent(args[n-1]) {
+		return args[:n-1], args[n-1]
+	}
+	if s, ok := stub.(*traceStub); ok {
+		return args, s.traceparent
+	}
+	return args, ""
+}
+
+// withTraceparent sets the traceparent field of a JSON object response,
+// replacing any the handler returned. The field is spliced in after the last
+// member so that the other members keep their order and formatting. Other
+// responses are returned as they are.
+func withTraceparent(res []byte, traceparent string) []byte {
+	body := bytes.TrimSpace(res)
+	if traceparent == "" || len(body) == 0 || body[0] != '{' || !json.Valid(body) {
+		return res
+	}
+	body = withoutMember(body, model.TraceparentField)
+	name, _ := json.Marshal(model.TraceparentField)
+	value, _ := json.Marshal(traceparent)
+	members := bytes.TrimRight(body[:len(body)-1], " \t\r\n")
+	traced := append([]byte{}, members...)
+	if len(members) > 1 {
+		traced = append(traced, ',')
+	}
+	traced = append(traced, name...)
+	traced = append(traced, ':')
+	traced = append(traced, value...)
+	return append(traced, body[len(members):]...)
+}
+
+// withoutMember removes the members of a valid JSON object with the name,
+// keeping the bytes of the other members as they are
+func withoutMember(object []byte, name string) []byte {
+	type member struct {
+		start, end int // from the end of the previous member, including its separator
+		removed    bool
+	}
+	dec := json.NewDecoder(bytes.NewReader(object))
+	if _, err := dec.Token(); err != nil {
+		return object
+	}
+	var members []member
+	removed := false
+	start := int(dec.InputOffset())
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return object
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return object
+		}
+		end := int(dec.InputOffset())
+		members = append(members, member{start: start, end: end, removed: key == name})
+		removed = removed || key == name
+		start = end
+	}
+	if !removed {
+		return object
+	}
+	kept := append([]byte{}, object[:members[0].start]...)
+	first := true
+	for i, m := range members {
+		if m.removed {
+			continue
+		}
+		text := object[m.start:m.end]
+		if first && i > 0 {
+			// the member follows a removed one, drop its separator
+			text = text[bytes.IndexByte(text, ',')+1:]
+		}
+		kept = append(kept, text...)
+		first = false
+	}
+	return append(kept, object[start:]...)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTraceparentAttachedToResponseEventsAndAudit(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+
+	stub.As(testsupport.Customer(t, "1001"))
+	result := new(struct {
+		model.TransferResult
+		Traceparent string `json:"traceparent"`
+	})
+	if err := json.Unmarshal(stub.MustCall(t, "TransferMoney", testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON(), testTraceparent), result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Traceparent != testTraceparent || result.Status != model.TransferCompleted {
+		t.Errorf("Expected the completed transfer returned with its traceparent, got %+v", result)
+	}
+	envelope := new(model.EventEnvelope)
+	if len(stub.Events) != 1 {
+		t.Fatalf("Expected the transfer event, got %d events", len(stub.Events))
+	}
+	if err := json.Unmarshal(stub.Events[0].Payload, envelope); err != nil || envelope.Traceparent != testTraceparent {
+		t.Errorf("Expected the event envelope to carry the traceparent, got %s", stub.Events[0].Payload)
+	}
+
+	log := new(model.AuditLog)
+	if err := json.Unmarshal(stub.As(testsupport.Operator(t, RoleAuditor)).MustCall(t, "QueryAuditLog", "", "TransferMoney"), log); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Entries) != 1 || log.Entries[0].Traceparent != testTraceparent {
+		t.Errorf("Expected the audit entry to carry the traceparent, got %d entries", len(log.Entries))
+	}
+	if res := stub.As(testsupport.Customer(t, "1001")).MustCall(t, "GetBalance", "1001", "1"); strings.Contains(string(res), model.TraceparentField) {
+		t.Errorf("Expected no traceparent in the response of an untraced invocation, got %s", res)
+	}
+}
+
+// argsStub presents the arguments of an invocation
+type argsStub struct {
+	shim.ChaincodeStubInterface
+	args [][]byte
+}
+
+func (s *argsStub) GetArgs() [][]byte {
+	return s.args
+}
+
+func TestTraceStubStripsTraceparent(t *testing.T) {
+	stub := newTraceStub(&argsStub{args: [][]byte{[]byte("GetAccount"), []byte("1001"), []byte("1"), []byte(testTraceparent)}})
+	function, params := stub.GetFunctionAndParameters()
+	if function != "GetAccount" || len(params) != 2 || stub.traceparent != testTraceparent {
+		t.Errorf("Expected GetAccount with 2 parameters and the traceparent, got %s %q %q", function, params, stub.traceparent)
+	}
+	if args, traceparent := invocationTraceparent(stub, params); len(args) != 2 || traceparent != testTraceparent {
+		t.Errorf("Expected the traceparent of the trace stub, got %q %q", args, traceparent)
+	}
+
+	for _, value := range []string{"GetAccount", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01"} {
+		stub := newTraceStub(&argsStub{args: [][]byte{[]byte("GetAccountList"), []byte(value)}})
+		if _, params := stub.GetFunctionAndParameters(); len(params) != 1 || stub.traceparent != "" {
+			t.Errorf("Expected %s kept as an argument, got %q", value, params)
+		}
+	}
+}
+
+func TestWithTraceparent(t *testing.T) {
+	for _, c := range []struct {
+		res, traced string
+	}{
+		{`{"id":"1"}`, `{"id":"1","traceparent":"` + testTraceparent + `"}`},
+		{"{}\n", `{"traceparent":"` + testTraceparent + `"}`},
+		{`{"traceparent":"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01","id":"1"}`, `{"id":"1","traceparent":"` + testTraceparent + `"}`},
+		{`{"to":"2","from":"1"}`, `{"to":"2","from":"1","traceparent":"` + testTraceparent + `"}`},
+		{`{"to":"2","traceparent":"","from":"1"}`, `{"to":"2","from":"1","traceparent":"` + testTraceparent + `"}`},
+		{"{\n  \"to\": \"2\"\n}\n", "{\n  \"to\": \"2\",\"traceparent\":\"" + testTraceparent + "\"\n}"},
+		{`{"id":"1"}}`, `{"id":"1"}}`},
+		{`{"id":`, `{"id":`},
+		{`[{"id":"1"}]`, `[{"id":"1"}]`},
+		{"", ""},
+	} {
+		if traced := string(withTraceparent([]byte(c.res), testTraceparent)); traced != c.traced {
+			t.Errorf("Expected %q traced as %q, got %q", c.res, c.traced, traced)
+		}
+	}
+}
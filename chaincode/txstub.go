@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iShamSLam/chaincode/model"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// txStub wraps the chaincode stub so that a handler's writes form a single
+// write set. Writes are buffered and only handed to the peer by flush once the
+// handler has succeeded, so a handler that fails midway leaves no partial
+// state behind. Reads observe the buffered writes, which the peer would not
+// otherwise expose to GetState within the same transaction. Private data
+// writes are buffered the same way, per collection. Events are buffered
+// alongside the writes and published together by publishEvents. Records are
+// stamped from the transaction context rather than the peer's clock.
+type txStub struct {
+	shim.ChaincodeStubInterface
+	writes        map[string][]byte            // pending value per key, nil for deleted keys
+	privateWrites map[string]map[string][]byte // pending private value per collection and key
+	events        []*model.Event
+	context       *model.TxContext
+	logger        *Logger // logger of the invocation, see loggerFor
+}
+
+func newTxStub(stub shim.ChaincodeStubInterface) (*txStub, error) {
+	context, err := newTxContext(stub)
+	if err != nil {
+		return nil, err
+	}
+	return &txStub{ChaincodeStubInterface: stub, writes: make(map[string][]byte), privateWrites: make(map[string]map[string][]byte), context: context}, nil
+}
+
+// newTxContext reads the transaction ID and the timestamp the client set in
+// the proposal, which every endorser sees alike
+func newTxContext(stub shim.ChaincodeStubInterface) (*model.TxContext, error) {
+	timestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading transaction timestamp. Error: %s", err)
+	}
+	return model.CreateTxContext(stub.GetTxID(), time.Unix(timestamp.GetSeconds(), int64(timestamp.GetNanos()))), nil
+}
+
+// txContext returns the context of the transaction the stub belongs to
+func txContext(stub shim.ChaincodeStubInterface) *model.TxContext {
+	if tx := unwrapTxStub(stub); tx != nil {
+		return tx.context
+	}
+	context, err := newTxContext(stub)
+	if err != nil {
+		loggerFor(stub).Errorf("%s", err)
+		return model.CreateTxContext(stub.GetTxID(), time.Unix(0, 0))
+	}
+	return context
+}
+
+// GetState returns the value written in this transaction, if any, or the committed value
+func (s *txStub) GetState(key string) ([]byte, error) {
+	if value, ok := s.writes[key]; ok {
+		return value, nil
+	}
+	return s.ChaincodeStubInterface.GetState(key)
+}
+
+// PutState buffers the value until the handler succeeds
+func (s *txStub) PutState(key string, value []byte) error {
+	s.writes[key] = value
+	return nil
+}
+
+// DelState buffers the deletion until the handler succeeds
+func (s *txStub) DelState(key string) error {
+	s.writes[key] = nil
+	return nil
+}
+
+// GetPrivateData returns the private value written in this transaction, if any,
+// or the committed value
+func (s *txStub) GetPrivateData(collection, key string) ([]byte, error) {
+	if value, ok := s.privateWrites[collection][key]; ok {
+		return value, nil
+	}
+	return s.ChaincodeStubInterface.GetPrivateData(collection, key)
+}
+
+// PutPrivateData buffers the private value until the handler succeeds
+func (s *txStub) PutPrivateData(collection string, key string, value []byte) error {
+	if s.privateWrites[collection] == nil {
+		s.privateWrites[collection] = make(map[string][]byte)
+	}
+	s.privateWrites[collection][key] = value
+	return nil
+}
+
+// DelPrivateData buffers the private deletion until the handler succeeds
+func (s *txStub) DelPrivateData(collection, key string) error {
+	return s.PutPrivateData(collection, key, nil)
+}
+
+// flush hands the buffered writes to the peer write set in key order
+func (s *txStub) flush() error {
+	keys := make([]string, 0, len(s.writes))
+	for key := range s.writes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var err error
+		if value := s.writes[key]; value == nil {
+			err = s.ChaincodeStubInterface.DelState(key)
+		} else {
+			err = s.ChaincodeStubInterface.PutState(key, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	collections := make([]string, 0, len(s.privateWrites))
+	for collection := range s.privateWrites {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	for _, collection := range collections {
+		keys := make([]string, 0, len(s.privateWrites[collection]))
+		for key := range s.privateWrites[collection] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var err error
+			if value := s.privateWrites[collection][key]; value == nil {
+				err = s.ChaincodeStubInterface.DelPrivateData(collection, key)
+			} else {
+				err = s.ChaincodeStubInterface.PutPrivateData(collection, key, value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	s.writes = make(map[string][]byte)
+	s.privateWrites = make(map[string]map[string][]byte)
+	return nil
+}
+
+// atomically runs fn and discards the writes it buffered if it fails, so that a
+// handler working through a batch can skip a failing item without keeping the
+// partial writes or events it made. Without a txStub underneath, fn simply runs.
+func atomically(stub shim.ChaincodeStubInterface, fn func() error) error {
+	tx := unwrapTxStub(stub)
+	if tx == nil {
+		return fn()
+	}
+	saved := make(map[string][]byte, len(tx.writes))
+	for key, value := range tx.writes {
+		saved[key] = value
+	}
+	savedPrivate := make(map[string]map[string][]byte, len(tx.privateWrites))
+	for collection, writes := range tx.privateWrites {
+		savedPrivate[collection] = make(map[string][]byte, len(writes))
+		for key, value := range writes {
+			savedPrivate[collection][key] = value
+		}
+	}
+	events := len(tx.events)
+	if err := fn(); err != nil {
+		tx.writes = saved
+		tx.privateWrites = savedPrivate
+		tx.events = tx.events[:events]
+		return err
+	}
+	return nil
+}
+
+// unwrapTxStub returns the txStub buffering the writes of the stub, if any
+func unwrapTxStub(stub shim.ChaincodeStubInterface) *txStub {
+	switch s := stub.(type) {
+	case *txStub:
+		return s
+	case *tenantStub:
+		return unwrapTxStub(s.ChaincodeStubInterface)
+	case *privateStub:
+		return unwrapTxStub(s.ChaincodeStubInterface)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iShamSLam/chaincode/model"
+	"github.com/iShamSLam/chaincode/testsupport"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// committedState copies the state the stub committed
+func committedState(stub *testsupport.Stub) map[string]string {
+	state := make(map[string]string, len(stub.State))
+	for key, value := range stub.State {
+		state[key] = string(value)
+	}
+	return state
+}
+
+// balanceOf returns the balance of an account, read as a teller
+func balanceOf(t *testing.T, stub *testsupport.Stub, customerID string, accountID string) int64 {
+	t.Helper()
+	caller := stub.Caller
+	defer stub.As(caller)
+
+	balance := new(model.Balance)
+	if err := json.Unmarshal(stub.As(testsupport.Operator(t, RoleTeller)).MustCall(t, "GetBalance", customerID, accountID), balance); err != nil {
+		t.Fatal(err)
+	}
+	return balance.Balance
+}
+
+// transferStep executes the transfer JSON without the checks of TransferMoney
+func transferStep(stub shim.ChaincodeStubInterface, transferJSON string) error {
+	transfer := new(model.Transfer)
+	if err := model.Unmarshal([]byte(transferJSON), transfer); err != nil {
+		return err
+	}
+	_, err := testChaincode.executeTransfer(stub, transfer)
+	return err
+}
+
+func TestFailedHandlerLeavesNoPartialState(t *testing.T) {
+	handlerMap.Add("TestTransferThenFail", func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		if err := transferStep(stub, args[0]); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("Failed after the transfer")
+	})
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+
+	before := committedState(stub)
+	_, err := stub.As(testsupport.Operator(t, RoleTeller)).Call("TestTransferThenFail", testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON())
+	if err == nil || !strings.Contains(err.Error(), "Failed after the transfer") {
+		t.Fatalf("Expected the handler to fail after the transfer, got %v", err)
+	}
+	if !reflect.DeepEqual(committedState(stub), before) || len(stub.Events) > 0 {
+		t.Errorf("Expected no writes or events of the failed handler, got %d events", len(stub.Events))
+	}
+	if payer, payee := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); payer != 100000 || payee != 0 {
+		t.Errorf("Expected the balances untouched, got %d and %d", payer, payee)
+	}
+}
+
+func TestAtomicallyDiscardsFailedStep(t *testing.T) {
+	handlerMap.Add("TestAtomicTransfers", func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		err := atomically(stub, func() error {
+			if err := transferStep(stub, args[0]); err != nil {
+				return err
+			}
+			return errors.New("Failed after the transfer")
+		})
+		if err == nil {
+			return nil, errors.New("Expected the first step to fail")
+		}
+		return nil, atomically(stub, func() error {
+			return transferStep(stub, args[1])
+		})
+	})
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.OpenAccount(t, testsupport.NewAccount("1002", "1"))
+	stub.Topup(t, "1001", "1", 100000)
+
+	stub.As(testsupport.Operator(t, RoleTeller)).MustCall(t, "TestAtomicTransfers",
+		testsupport.NewTransfer("1001", "1", "1002", "1", 1000).JSON(),
+		testsupport.NewTransfer("1001", "1", "1002", "1", 2500).JSON())
+	if len(stub.Events) != 1 || !strings.Contains(string(stub.Events[0].Payload), `"amount":2500`) {
+		t.Errorf("Expected only the event of the second transfer, got %d events", len(stub.Events))
+	}
+	if payer, payee := balanceOf(t, stub, "1001", "1"), balanceOf(t, stub, "1002", "1"); payer != 97500 || payee != 2500 {
+		t.Errorf("Expected only the second transfer applied, got %d and %d", payer, payee)
+	}
+}
+
+func TestTransferRejectsSameAccount(t *testing.T) {
+	stub := newTestStub()
+	stub.OpenAccount(t, testsupport.NewAccount("1001", "1"))
+	stub.Topup(t, "1001", "1", 1000)
+
+	before := committedState(stub)
+	_, err := stub.As(testsupport.Customer(t, "1001")).Call("TransferMoney", testsupport.NewTransfer("1001", "1", "1001", "1", 500).JSON())
+	if err == nil || !strings.Contains(err.Error(), "to itself") {
+		t.Fatalf("Expected the transfer to the payer itself rejected, got %v", err)
+	}
+	if !reflect.DeepEqual(committedState(stub), before) {
+		t.Errorf("Expected no writes of the rejected transfer")
+	}
+	if balance := balanceOf(t, stub, "1001", "1"); balance != 1000 {
+		t.Errorf("Expected the balance untouched, got %d", balance)
+	}
+}
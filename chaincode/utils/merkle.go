@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// MerkleRoot returns the hex encoded SHA-256 Merkle root over the given leaves.
+// Leaves are hashed first; an odd node at any level is paired with itself.
+func MerkleRoot(leaves [][]byte) string {
+	if len(leaves) == 0 {
+		return ""
+	}
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		sum := sha256.Sum256(leaf)
+		level[i] = sum[:]
+	}
+	for len(level) > 1 {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			sum := sha256.Sum256(append(append([]byte{}, level[i]...), right...))
+			next = append(next, sum[:])
+		}
+		level = next
+	}
+	return hex.EncodeToString(level[0])
+}
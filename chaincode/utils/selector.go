@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchSelector reports whether a decoded JSON document satisfies a CouchDB
+// Mango selector. It supports the subset of the selector syntax needed where
+// no CouchDB state database is available: implicit and $eq equality, $ne, $gt,
+// $gte, $lt, $lte, $in, $nin, $exists, $and, $or, $not and nested fields given
+// as objects or dotted paths. Any other operator is an error, so that a query
+// never silently returns a different result than CouchDB would.
+func MatchSelector(doc map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, condition := range selector {
+		var ok bool
+		var err error
+		switch field {
+		case "$and":
+			ok, err = matchAll(doc, condition, true)
+		case "$or":
+			ok, err = matchAll(doc, condition, false)
+		case "$not":
+			sub, isMap := condition.(map[string]interface{})
+			if !isMap {
+				return false, fmt.Errorf("Operator $not takes a selector")
+			}
+			ok, err = MatchSelector(doc, sub)
+			ok = !ok
+		default:
+			if strings.HasPrefix(field, "$") {
+				return false, fmt.Errorf("Unsupported selector operator %s", field)
+			}
+			value, found := lookupField(doc, field)
+			ok, err = matchCondition(value, found, condition)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchAll(doc map[string]interface{}, condition interface{}, all bool) (bool, error) {
+	selectors, isList := condition.([]interface{})
+	if !isList {
+		return false, fmt.Errorf("Operators $and and $or take a list of selectors")
+	}
+	for _, s := range selectors {
+		sub, isMap := s.(map[string]interface{})
+		if !isMap {
+			return false, fmt.Errorf("Operators $and and $or take a list of selectors")
+		}
+		ok, err := MatchSelector(doc, sub)
+		if err != nil {
+			return false, err
+		}
+		if ok != all {
+			return ok, nil
+		}
+	}
+	return all, nil
+}
+
+// lookupField resolves a dotted field path in a document
+func lookupField(doc map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, name := range strings.Split(path, ".") {
+		object, isMap := value.(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+		field, found := object[name]
+		if !found {
+			return nil, false
+		}
+		value = field
+	}
+	return value, true
+}
+
+func matchCondition(value interface{}, found bool, condition interface{}) (bool, error) {
+	operators, isMap := condition.(map[string]interface{})
+	if !isMap {
+		return found && compare(value, condition) == 0, nil
+	}
+	for op, operand := range operators {
+		var ok bool
+		switch op {
+		case "$eq":
+			ok = found && compare(value, operand) == 0
+		case "$ne":
+			ok = !found || compare(value, operand) != 0
+		case "$gt":
+			ok = found && comparable(value, operand) && compare(value, operand) > 0
+		case "$gte":
+			ok = found && comparable(value, operand) && compare(value, operand) >= 0
+		case "$lt":
+			ok = found && comparable(value, operand) && compare(value, operand) < 0
+		case "$lte":
+			ok = found && comparable(value, operand) && compare(value, operand) <= 0
+		case "$in", "$nin":
+			list, isList := operand.([]interface{})
+			if !isList {
+				return false, fmt.Errorf("Operator %s takes a list", op)
+			}
+			in := false
+			for _, item := range list {
+				if found && compare(value, item) == 0 {
+					in = true
+					break
+				}
+			}
+			ok = in == (op == "$in")
+		case "$exists":
+			want, isBool := operand.(bool)
+			if !isBool {
+				return false, fmt.Errorf("Operator $exists takes a boolean")
+			}
+			ok = found == want
+		default:
+			if strings.HasPrefix(op, "$") {
+				return false, fmt.Errorf("Unsupported selector operator %s", op)
+			}
+			// a nested selector on a sub-document
+			sub, _ := value.(map[string]interface{})
+			nested, err := MatchSelector(sub, map[string]interface{}{op: operand})
+			if err != nil {
+				return false, err
+			}
+			ok = nested
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// comparable reports whether two JSON values are both numbers or both strings
+func comparable(a interface{}, b interface{}) bool {
+	switch a.(type) {
+	case float64:
+		_, ok := b.(float64)
+		return ok
+	case string:
+		_, ok := b.(string)
+		return ok
+	}
+	return false
+}
+
+// compare orders two JSON values, returning 0 if they are equal. Values of
+// different types are never equal.
+func compare(a interface{}, b interface{}) int {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok && x == y {
+			return 0
+		}
+	case nil:
+		if b == nil {
+			return 0
+		}
+	}
+	return 2
+}
@@ -1,17 +1,19 @@
 package utils
 
 import (
-	"math/rand"
-	"time"
+	"crypto/sha256"
 )
 
-// GenerateID generates a fixed length string of random digits
-func GenerateID(length int) string {
-	rand.Seed(time.Now().UnixNano())
-	r := []rune("1234567890")
-	b := make([]rune, length)
+// GenerateID generates a fixed length string of digits derived from the seed,
+// so that the same seed always yields the same ID
+func GenerateID(seed string, length int) string {
+	b := make([]byte, length)
+	hash := sha256.Sum256([]byte(seed))
 	for i := range b {
-		b[i] = r[rand.Intn(len(r))]
+		if i > 0 && i%len(hash) == 0 {
+			hash = sha256.Sum256(hash[:])
+		}
+		b[i] = '0' + hash[i%len(hash)]%10
 	}
 	return string(b)
 }